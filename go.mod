@@ -2,4 +2,16 @@ module github.com/jc/gdpr-mcp
 
 go 1.21
 
-require github.com/mattn/go-sqlite3 v1.14.22
+require (
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
+	github.com/mattn/go-sqlite3 v1.14.22
+	github.com/pkoukk/tiktoken-go v0.1.8
+	github.com/pkoukk/tiktoken-go-loader v0.0.2
+	github.com/yalue/onnxruntime_go v1.12.0
+	golang.org/x/text v0.14.0
+)
+
+require (
+	github.com/dlclark/regexp2 v1.10.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+)
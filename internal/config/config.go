@@ -0,0 +1,258 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+// Source types with their own chunking settings. Any other name may be used
+// in the config file and selected when ingesting.
+const (
+	SourceRegulation = "regulation"
+	SourceGuidelines = "guidelines"
+	SourcePolicy     = "policy"
+)
+
+// DefaultSourceType names the chunking entry every source type inherits from
+const DefaultSourceType = "default"
+
+// File is the gdpr-mcp configuration file
+type File struct {
+	// Chunking holds chunking settings by source type. Unset fields fall
+	// back to the "default" entry, then to the built-in defaults.
+	Chunking map[string]Chunking `json:"chunking,omitempty"`
+
+	// Search holds the server's default search settings
+	Search Search `json:"search"`
+}
+
+// Chunking configures how sources of one type are split into chunks
+type Chunking struct {
+	ChunkSize    int    `json:"chunk_size,omitempty"`
+	ChunkOverlap *int   `json:"chunk_overlap,omitempty"` // nil inherits; 0 disables overlap
+	Strategy     string `json:"strategy,omitempty"`      // "window", "sentence", "recursive", "semantic" or "structure"
+	Tokenizer    string `json:"tokenizer,omitempty"`     // tiktoken encoding or model name
+
+	// SemanticPercentile tunes the "semantic" strategy: lower values start
+	// new chunks at smaller topic shifts
+	SemanticPercentile float64 `json:"semantic_percentile,omitempty"`
+}
+
+// Search configures how gdpr_search ranks results by default
+type Search struct {
+	Fusion        string   `json:"fusion,omitempty"`         // "rrf" or "linear"
+	RRFK          float64  `json:"rrf_k,omitempty"`          // reciprocal rank fusion constant
+	KeywordWeight *float64 `json:"keyword_weight,omitempty"` // weight of the trigram ranking
+	VectorWeight  *float64 `json:"vector_weight,omitempty"`  // weight of the vector ranking
+	Alpha         *float64 `json:"alpha,omitempty"`          // share of the vector ranking, instead of the weights
+	Diversity     float64  `json:"diversity,omitempty"`      // MMR diversification strength, 0 (off) to 1
+}
+
+// DefaultPath returns the config file location: $GDPR_MCP_CONFIG, or
+// config.json in the gdpr-mcp user config directory
+func DefaultPath() (string, error) {
+	if path := os.Getenv("GDPR_MCP_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "gdpr-mcp", "config.json"), nil
+}
+
+// DefaultDBPath returns the database location, $GDPR_MCP_DB or gdpr.db in the
+// gdpr-mcp user data directory, and creates its parent directories
+func DefaultDBPath() (string, error) {
+	path := os.Getenv("GDPR_MCP_DB")
+	if path == "" {
+		dir, err := userDataDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate data directory: %w", err)
+		}
+		path = filepath.Join(dir, "gdpr-mcp", "gdpr.db")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create database directory: %w", err)
+	}
+	return path, nil
+}
+
+// userDataDir returns the directory for user data: $XDG_DATA_HOME or
+// ~/.local/share on Unix, ~/Library/Application Support on macOS and
+// %LocalAppData% on Windows
+func userDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return dir, nil
+		}
+		return "", errors.New("%LocalAppData% is not defined")
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library", "Application Support"), nil
+	}
+	// Relative paths are invalid per the XDG Base Directory specification
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// Load reads and validates the config file at path
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// LoadDefault loads the config file at DefaultPath. A missing file is not an
+// error and yields an empty configuration.
+func LoadDefault() (*File, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	return f, err
+}
+
+// Validate checks every chunking entry and the search settings, reporting
+// all problems at once
+func (f *File) Validate() error {
+	names := make([]string, 0, len(f.Chunking))
+	for name := range f.Chunking {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		c := f.Chunking[name]
+		if c.ChunkSize < 0 {
+			errs = append(errs, fmt.Errorf("chunking.%s: chunk_size must not be negative", name))
+		}
+		if c.ChunkOverlap != nil && *c.ChunkOverlap < 0 {
+			errs = append(errs, fmt.Errorf("chunking.%s: chunk_overlap must not be negative", name))
+		}
+		if c.SemanticPercentile < 0 || c.SemanticPercentile > 100 {
+			errs = append(errs, fmt.Errorf("chunking.%s: semantic_percentile must be between 0 and 100", name))
+		}
+		if c.Strategy != "" && !known(ingest.Chunkers(), c.Strategy) {
+			errs = append(errs, fmt.Errorf("chunking.%s: unknown strategy %q", name, c.Strategy))
+		}
+	}
+	for _, name := range names {
+		if c := f.resolve(name, ingest.DefaultConfig()); c.ChunkOverlap >= c.ChunkSize {
+			errs = append(errs, fmt.Errorf("chunking.%s: chunk_overlap %d must be smaller than chunk_size %d", name, c.ChunkOverlap, c.ChunkSize))
+		}
+	}
+	fusion, err := f.Fusion()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("search: %w", err))
+	} else if err := fusion.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("search: %w", err))
+	} else if fusion.KeywordWeight == 0 && fusion.VectorWeight == 0 {
+		errs = append(errs, fmt.Errorf("search: keyword_weight and vector_weight must not both be 0"))
+	}
+	if f.Search.Diversity < 0 || f.Search.Diversity > 1 {
+		errs = append(errs, fmt.Errorf("search: diversity must be between 0 and 1, got %v", f.Search.Diversity))
+	}
+	return errors.Join(errs...)
+}
+
+// Fusion returns the configured fusion of keyword and vector rankings, with
+// defaults for unset fields
+func (f *File) Fusion() (db.Fusion, error) {
+	fusion := db.Fusion{Method: f.Search.Fusion, K: f.Search.RRFK}.WithDefaults()
+	if f.Search.KeywordWeight != nil {
+		fusion.KeywordWeight = *f.Search.KeywordWeight
+	}
+	if f.Search.VectorWeight != nil {
+		fusion.VectorWeight = *f.Search.VectorWeight
+	}
+	if f.Search.Alpha != nil {
+		if f.Search.KeywordWeight != nil || f.Search.VectorWeight != nil {
+			return fusion, fmt.Errorf("alpha cannot be combined with keyword_weight or vector_weight")
+		}
+		return fusion.WithAlpha(*f.Search.Alpha)
+	}
+	return fusion, nil
+}
+
+// IngestConfig returns base with the chunking settings for sourceType
+// applied. An empty sourceType uses the "default" entry only.
+func (f *File) IngestConfig(base ingest.Config, sourceType string) (ingest.Config, error) {
+	if sourceType != "" && sourceType != DefaultSourceType {
+		if _, ok := f.Chunking[sourceType]; !ok {
+			return base, fmt.Errorf("no chunking configured for source type %q", sourceType)
+		}
+	}
+	c := f.resolve(sourceType, base)
+	if c.ChunkOverlap >= c.ChunkSize {
+		return base, fmt.Errorf("chunk_overlap %d must be smaller than chunk_size %d", c.ChunkOverlap, c.ChunkSize)
+	}
+	return c, nil
+}
+
+// resolve layers the default entry and then the sourceType entry on base
+func (f *File) resolve(sourceType string, base ingest.Config) ingest.Config {
+	for _, name := range []string{DefaultSourceType, sourceType} {
+		c, ok := f.Chunking[name]
+		if !ok {
+			continue
+		}
+		if c.ChunkSize > 0 {
+			base.ChunkSize = c.ChunkSize
+		}
+		if c.ChunkOverlap != nil {
+			base.ChunkOverlap = *c.ChunkOverlap
+		}
+		if c.Strategy != "" {
+			base.Strategy = c.Strategy
+		}
+		if c.Tokenizer != "" {
+			base.Tokenizer = c.Tokenizer
+		}
+		if c.SemanticPercentile != 0 {
+			base.SemanticPercentile = c.SemanticPercentile
+		}
+	}
+	return base
+}
+
+func known(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
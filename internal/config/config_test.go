@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestIngestConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"chunking": {
+			"default": {"chunk_size": 800, "chunk_overlap": 80},
+			"regulation": {"strategy": "structure", "chunk_size": 1500},
+			"guidelines": {"chunk_overlap": 0},
+			"policy": {"chunk_size": 400, "tokenizer": "cl100k_base"},
+			"contracts": {"strategy": "semantic", "semantic_percentile": 90}
+		}
+	}`)
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		sourceType string
+		size       int
+		overlap    int
+		strategy   string
+		tokenizer  string
+	}{
+		{"", 800, 80, ingest.StrategyWindow, ""},
+		{SourceRegulation, 1500, 80, ingest.StrategyStructure, ""},
+		{SourceGuidelines, 800, 0, ingest.StrategyWindow, ""},
+		{SourcePolicy, 400, 80, ingest.StrategyWindow, "cl100k_base"},
+	}
+	for _, tt := range tests {
+		c, err := f.IngestConfig(ingest.DefaultConfig(), tt.sourceType)
+		if err != nil {
+			t.Fatalf("IngestConfig(%q) failed: %v", tt.sourceType, err)
+		}
+		if c.ChunkSize != tt.size || c.ChunkOverlap != tt.overlap || c.Strategy != tt.strategy || c.Tokenizer != tt.tokenizer {
+			t.Errorf("IngestConfig(%q) = size %d, overlap %d, strategy %q, tokenizer %q", tt.sourceType,
+				c.ChunkSize, c.ChunkOverlap, c.Strategy, c.Tokenizer)
+		}
+	}
+
+	c, err := f.IngestConfig(ingest.DefaultConfig(), "contracts")
+	if err != nil || c.Strategy != ingest.StrategySemantic || c.SemanticPercentile != 90 {
+		t.Errorf("IngestConfig(contracts) = %q, %v, %v", c.Strategy, c.SemanticPercentile, err)
+	}
+
+	if _, err := f.IngestConfig(ingest.DefaultConfig(), "minutes"); err == nil {
+		t.Error("Expected an unconfigured source type to be rejected")
+	}
+}
+
+func TestEmptyConfigKeepsDefaults(t *testing.T) {
+	base := ingest.DefaultConfig()
+	c, err := (&File{}).IngestConfig(base, "")
+	if err != nil {
+		t.Fatalf("IngestConfig failed: %v", err)
+	}
+	if c.ChunkSize != base.ChunkSize || c.ChunkOverlap != base.ChunkOverlap || c.Strategy != base.Strategy {
+		t.Errorf("Expected the built-in defaults, got %+v", c)
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	path := writeConfig(t, `{"chunking": {"policy": {"chunk_size": 50, "chunk_overlap": 100}, "regulation": {"strategy": "paragraphs"}}}`)
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Expected invalid config to be rejected")
+	}
+	for _, want := range []string{"chunking.policy: chunk_overlap 100", `chunking.regulation: unknown strategy "paragraphs"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestSearchFusion(t *testing.T) {
+	if fusion, err := (&File{}).Fusion(); err != nil || fusion != (db.Fusion{Method: db.FusionRRF, K: db.DefaultRRFK, KeywordWeight: 1, VectorWeight: 1}) {
+		t.Errorf("Expected the default fusion, got %+v, %v", fusion, err)
+	}
+
+	f, err := Load(writeConfig(t, `{"search": {"rrf_k": 20, "vector_weight": 0}}`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if fusion, _ := f.Fusion(); fusion != (db.Fusion{Method: db.FusionRRF, K: 20, KeywordWeight: 1, VectorWeight: 0}) {
+		t.Errorf("Expected keyword-only fusion with k=20, got %+v", fusion)
+	}
+
+	f, err = Load(writeConfig(t, `{"search": {"fusion": "linear", "alpha": 0.25}}`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if fusion, _ := f.Fusion(); fusion.Method != db.FusionLinear || fusion.KeywordWeight != 0.75 || fusion.VectorWeight != 0.25 {
+		t.Errorf("Expected linear fusion with alpha 0.25, got %+v", fusion)
+	}
+
+	for _, content := range []string{
+		`{"search": {"rrf_k": -1}}`,
+		`{"search": {"keyword_weight": 0, "vector_weight": 0}}`,
+		`{"search": {"fusion": "borda"}}`,
+		`{"search": {"alpha": 1.5}}`,
+		`{"search": {"alpha": 0.5, "vector_weight": 2}}`,
+		`{"search": {"diversity": 2}}`,
+	} {
+		if _, err := Load(writeConfig(t, content)); err == nil || !strings.Contains(err.Error(), "search: ") {
+			t.Errorf("Expected %s to be rejected, got %v", content, err)
+		}
+	}
+}
+
+func TestLoadDefault(t *testing.T) {
+	t.Setenv("GDPR_MCP_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+	f, err := LoadDefault()
+	if err != nil || f == nil || len(f.Chunking) != 0 {
+		t.Fatalf("LoadDefault = %+v, %v, want an empty config", f, err)
+	}
+
+	t.Setenv("GDPR_MCP_CONFIG", writeConfig(t, `{"chunking": {"default": {"chunk_size": 500}}}`))
+	f, err = LoadDefault()
+	if err != nil || f.Chunking[DefaultSourceType].ChunkSize != 500 {
+		t.Fatalf("LoadDefault = %+v, %v", f, err)
+	}
+}
+
+func TestDefaultDBPath(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		t.Skip("XDG_DATA_HOME only applies on Unix")
+	}
+	t.Setenv("GDPR_MCP_DB", "")
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	path, err := DefaultDBPath()
+	if err != nil {
+		t.Fatalf("DefaultDBPath: %v", err)
+	}
+	if want := filepath.Join(dataHome, "gdpr-mcp", "gdpr.db"); path != want {
+		t.Errorf("DefaultDBPath = %q, want %q", path, want)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		t.Errorf("database directory not created: %v", err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "relative/share")
+	path, err = DefaultDBPath()
+	if err != nil || path != filepath.Join(home, ".local", "share", "gdpr-mcp", "gdpr.db") {
+		t.Errorf("DefaultDBPath = %q, %v, want the ~/.local/share fallback", path, err)
+	}
+
+	custom := filepath.Join(t.TempDir(), "nested", "custom.db")
+	t.Setenv("GDPR_MCP_DB", custom)
+	path, err = DefaultDBPath()
+	if err != nil || path != custom {
+		t.Fatalf("DefaultDBPath = %q, %v, want %q", path, err, custom)
+	}
+	if _, err := os.Stat(filepath.Dir(custom)); err != nil {
+		t.Errorf("parent of GDPR_MCP_DB not created: %v", err)
+	}
+}
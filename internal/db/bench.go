@@ -0,0 +1,122 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyStats summarises the latencies of repeated operations
+type LatencyStats struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// Latencies summarises samples, using the nearest-rank percentile
+func Latencies(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p int) time.Duration {
+		rank := (p*len(sorted) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+	return LatencyStats{
+		Count: len(sorted),
+		Mean:  total / time.Duration(len(sorted)),
+		P50:   percentile(50),
+		P95:   percentile(95),
+		P99:   percentile(99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// SearchBenchmark is the outcome of BenchmarkSearch
+type SearchBenchmark struct {
+	Concurrency int           `json:"concurrency"`
+	Latency     LatencyStats  `json:"latency"`
+	Elapsed     time.Duration `json:"elapsed"`
+	QPS         float64       `json:"qps"` // searches completed per second
+}
+
+// BenchmarkSearch runs every query rounds times through HybridSearch from
+// concurrency goroutines and measures the latency of each search. embed
+// returns the embedding of a query and may be nil for keyword-only
+// searches; its time is not counted, so the figures are those of the
+// database alone.
+func (db *DB) BenchmarkSearch(queries []string, embed func(string) []float32, limit, concurrency, rounds int, opts SearchOptions) (*SearchBenchmark, error) {
+	if len(queries) == 0 {
+		return nil, errors.New("no benchmark queries")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if rounds < 1 {
+		rounds = 1
+	}
+
+	embeddings := make([][]float32, len(queries))
+	if embed != nil {
+		for i, q := range queries {
+			embeddings[i] = embed(q)
+		}
+	}
+
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var samples []time.Duration
+	var firstErr error
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				began := time.Now()
+				_, err := db.HybridSearch(queries[i], embeddings[i], limit, opts)
+				took := time.Since(began)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				samples = append(samples, took)
+				mu.Unlock()
+			}
+		}()
+	}
+	for r := 0; r < rounds; r++ {
+		for i := range queries {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return &SearchBenchmark{
+		Concurrency: concurrency,
+		Latency:     Latencies(samples),
+		Elapsed:     elapsed,
+		QPS:         float64(len(samples)) / elapsed.Seconds(),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencies(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	stats := Latencies(samples)
+	want := LatencyStats{
+		Count: 100,
+		Mean:  50500 * time.Microsecond,
+		P50:   50 * time.Millisecond,
+		P95:   95 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}
+	if stats != want {
+		t.Errorf("Expected %+v, got %+v", want, stats)
+	}
+	if samples[0] != 100*time.Millisecond {
+		t.Error("Expected the samples not to be reordered")
+	}
+
+	if one := Latencies([]time.Duration{time.Second}); one.P50 != time.Second || one.P99 != time.Second {
+		t.Errorf("Expected every percentile of a single sample to be that sample, got %+v", one)
+	}
+	if empty := Latencies(nil); empty != (LatencyStats{}) {
+		t.Errorf("Expected zero stats without samples, got %+v", empty)
+	}
+}
+
+func TestBenchmarkSearch(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertSearchable(t, database, "Right to erasure of personal data")
+	insertSearchable(t, database, "Right to data portability")
+
+	embed := func(string) []float32 { return []float32{1, 0, 0} }
+	bench, err := database.BenchmarkSearch([]string{"erasure", "portability", "personal data"}, embed, 10, 4, 5, SearchOptions{})
+	if err != nil {
+		t.Fatalf("BenchmarkSearch failed: %v", err)
+	}
+	if bench.Latency.Count != 15 || bench.Concurrency != 4 {
+		t.Errorf("Expected 15 searches from 4 workers, got %+v", bench)
+	}
+	if bench.QPS <= 0 || bench.Latency.P50 > bench.Latency.P99 {
+		t.Errorf("Unexpected figures %+v", bench)
+	}
+
+	if _, err := database.BenchmarkSearch(nil, nil, 10, 1, 1, SearchOptions{}); err == nil {
+		t.Error("Expected an error without queries")
+	}
+}
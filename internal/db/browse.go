@@ -0,0 +1,54 @@
+package db
+
+import "fmt"
+
+// BrowseChunk is a chunk listed in reading order
+type BrowseChunk struct {
+	ID         int64             `json:"id"`
+	ChunkIndex int               `json:"chunk_index"`
+	Source     string            `json:"source,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Text       string            `json:"text"`
+}
+
+// Browse returns up to limit live chunks of a collection in reading order,
+// by source and then chunk index, skipping the first offset, and how many
+// chunks there are in all. A chapter, as stored in chunk metadata, limits
+// the chunks to those of that chapter.
+func (db *DB) Browse(collection, chapter string, offset, limit int) ([]BrowseChunk, int, error) {
+	filter := "deleted_at IS NULL AND collection = ?"
+	args := []interface{}{collection}
+	if chapter != "" {
+		filter += " AND json_extract(metadata, '$.chapter') = ?"
+		args = append(args, chapter)
+	}
+
+	var total int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM documents WHERE "+filter, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count chunks: %w", err)
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT id, chunk_index, source, metadata, chunk FROM documents
+		WHERE `+filter+`
+		ORDER BY source, chunk_index, id
+		LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to browse chunks: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := []BrowseChunk{}
+	for rows.Next() {
+		var c BrowseChunk
+		var metadata string
+		if err := rows.Scan(&c.ID, &c.ChunkIndex, &c.Source, &metadata, &c.Text); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		if c.Metadata, err = decodeMetadata(metadata); err != nil {
+			return nil, 0, err
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks, total, rows.Err()
+}
@@ -0,0 +1,61 @@
+package db
+
+import "testing"
+
+func TestBrowse(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []Document{
+		{Chunk: "Article 2", ChunkIndex: 1, Source: "gdpr.txt", Metadata: map[string]string{"chapter": "I", "article": "2"}},
+		{Chunk: "Article 1", ChunkIndex: 0, Source: "gdpr.txt", Metadata: map[string]string{"chapter": "I", "article": "1"}},
+		{Chunk: "Article 12", ChunkIndex: 2, Source: "gdpr.txt", Metadata: map[string]string{"chapter": "III", "article": "12"}},
+		{Chunk: "Deleted", ChunkIndex: 3, Source: "gdpr.txt"},
+		{Chunk: "Artikel 1", ChunkIndex: 0, Source: "dsgvo.txt", Collection: "gdpr-de"},
+	} {
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := database.SoftDelete(ids[3]); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+
+	chunks, total, err := database.Browse("", "", 0, 2)
+	if err != nil {
+		t.Fatalf("Browse failed: %v", err)
+	}
+	if total != 3 || len(chunks) != 2 || chunks[0].Text != "Article 1" || chunks[1].Text != "Article 2" {
+		t.Errorf("Expected the first two of 3 chunks in order, got %d and %+v", total, chunks)
+	}
+	if chunks[0].Metadata["article"] != "1" || chunks[0].Source != "gdpr.txt" {
+		t.Errorf("Expected the chunk's source and metadata, got %+v", chunks[0])
+	}
+
+	chunks, _, err = database.Browse("", "", 2, 2)
+	if err != nil {
+		t.Fatalf("Browse failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Text != "Article 12" {
+		t.Errorf("Expected the last chunk, got %+v", chunks)
+	}
+
+	chunks, total, err = database.Browse("", "III", 0, 10)
+	if err != nil {
+		t.Fatalf("Browse failed: %v", err)
+	}
+	if total != 1 || len(chunks) != 1 || chunks[0].ID != ids[2] {
+		t.Errorf("Expected the chunk of Chapter III, got %d and %+v", total, chunks)
+	}
+
+	chunks, total, err = database.Browse("gdpr-de", "", 0, 10)
+	if err != nil {
+		t.Fatalf("Browse failed: %v", err)
+	}
+	if total != 1 || chunks[0].Text != "Artikel 1" {
+		t.Errorf("Expected the chunk of the gdpr-de collection, got %+v", chunks)
+	}
+}
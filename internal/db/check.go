@@ -0,0 +1,247 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem is an issue found by a health check, with what to do about it
+type Problem struct {
+	Check   string `json:"check"` // the check that found it, e.g. CheckIntegrity
+	Message string `json:"message"`
+	Fix     string `json:"fix"`
+}
+
+// Health checks run by Check
+const (
+	CheckIntegrity  = "integrity"  // SQLite's own consistency check
+	CheckSchema     = "schema"     // tables and columns of the current version
+	CheckIndex      = "index"      // trigram posting lists match the chunks
+	CheckEmbeddings = "embeddings" // every chunk has an embedding of one dimension
+	CheckIngestion  = "ingestion"  // no ingestion was left unfinished
+)
+
+// schemaTables are the tables the current schema creates
+var schemaTables = []string{
+	"documents", "trigram_postings", "embeddings", "keyphrases", "metadata",
+	"checkpoints", "source_files", "synonyms", "term_map", "article_recitals",
+}
+
+// Check runs the health checks on the database and returns the problems
+// found, none for a healthy database. It only reads, so it can be run
+// before Migrate to find out whether a database needs migrating.
+func (db *DB) Check() ([]Problem, error) {
+	var problems []Problem
+	for _, check := range []func() ([]Problem, error){
+		db.checkIntegrity,
+		db.checkSchema,
+	} {
+		found, err := check()
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+	// The remaining checks query the current schema
+	if len(problems) > 0 {
+		return problems, nil
+	}
+	for _, check := range []func() ([]Problem, error){
+		db.checkIndex,
+		db.checkEmbeddings,
+		db.checkIngestion,
+	} {
+		found, err := check()
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+	return problems, nil
+}
+
+func (db *DB) checkIntegrity() ([]Problem, error) {
+	rows, err := db.conn.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []string
+	for rows.Next() {
+		var message string
+		if err := rows.Scan(&message); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if message != "ok" {
+			messages = append(messages, message)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	return []Problem{{
+		Check:   CheckIntegrity,
+		Message: "The database file is corrupt: " + strings.Join(messages, "; "),
+		Fix:     "Restore the database from a backup, or delete it and ingest the sources again",
+	}}, nil
+}
+
+func (db *DB) checkSchema() ([]Problem, error) {
+	var missing []string
+	for _, table := range schemaTables {
+		columns, err := db.tableColumns(table)
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			missing = append(missing, "table "+table)
+		}
+	}
+	for _, u := range columnUpgrades {
+		columns, err := db.tableColumns(u.table)
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) > 0 && !columns[u.column] {
+			missing = append(missing, "column "+u.table+"."+u.column)
+		}
+	}
+	legacy, err := db.tableColumns("trigrams")
+	if err != nil {
+		return nil, err
+	}
+	if len(legacy) > 0 {
+		missing = append(missing, "compressed trigram posting lists")
+	}
+	stored, err := db.StoredSchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if stored > SchemaVersion {
+		return []Problem{{
+			Check:   CheckSchema,
+			Message: fmt.Sprintf("The database has schema version %d, newer than the version %d this binary supports", stored, SchemaVersion),
+			Fix:     "Upgrade gdpr-mcp to the version that last wrote the database",
+		}}, nil
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	return []Problem{{
+		Check:   CheckSchema,
+		Message: "The database was created by an older version and lacks " + strings.Join(missing, ", "),
+		Fix:     "Run any command that opens the database for writing, such as ingest, to migrate it",
+	}}, nil
+}
+
+func (db *DB) checkIndex() ([]Problem, error) {
+	indexed := make(map[int64]bool)
+	rows, err := db.conn.Query("SELECT trigram, postings FROM trigram_postings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posting lists: %w", err)
+	}
+	defer rows.Close()
+	var corrupt []string
+	for rows.Next() {
+		var trigram string
+		var postings []byte
+		if err := rows.Scan(&trigram, &postings); err != nil {
+			return nil, fmt.Errorf("failed to scan posting list: %w", err)
+		}
+		ids, err := decodePostings(postings)
+		if err != nil {
+			corrupt = append(corrupt, trigram)
+			continue
+		}
+		for _, id := range ids {
+			indexed[id] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var unindexed, stored int
+	docs, err := db.conn.Query("SELECT id FROM documents")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer docs.Close()
+	for docs.Next() {
+		var id int64
+		if err := docs.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		stored++
+		if !indexed[id] {
+			unindexed++
+		}
+		delete(indexed, id)
+	}
+	if err := docs.Err(); err != nil {
+		return nil, err
+	}
+
+	const fix = "Rebuild the keyword index from the stored chunks with reindex"
+	var problems []Problem
+	if len(corrupt) > 0 {
+		problems = append(problems, Problem{CheckIndex, fmt.Sprintf("%d trigram posting lists cannot be decoded", len(corrupt)), fix})
+	}
+	if unindexed > 0 {
+		problems = append(problems, Problem{CheckIndex, fmt.Sprintf("%d of %d chunks are missing from the keyword index and cannot be found by keyword", unindexed, stored), fix})
+	}
+	if len(indexed) > 0 {
+		problems = append(problems, Problem{CheckIndex, fmt.Sprintf("The keyword index refers to %d chunks that no longer exist", len(indexed)), fix})
+	}
+	return problems, nil
+}
+
+func (db *DB) checkEmbeddings() ([]Problem, error) {
+	stats, err := db.Stats()
+	if err != nil {
+		return nil, err
+	}
+	var problems []Problem
+	if missing := stats.Chunks - stats.EmbeddedChunks; missing > 0 && stats.Chunks > 0 {
+		problems = append(problems, Problem{
+			Check:   CheckEmbeddings,
+			Message: fmt.Sprintf("%d of %d chunks have no embedding and are only found by keyword", missing, stats.Chunks),
+			Fix:     "Recompute the embeddings of the stored chunks with reindex",
+		})
+	}
+	if len(stats.Dimensions) > 1 {
+		problems = append(problems, Problem{
+			Check:   CheckEmbeddings,
+			Message: fmt.Sprintf("Embeddings of %v dimensions are mixed, so chunks were embedded by different models and vector search compares them wrongly", stats.Dimensions),
+			Fix:     "Recompute all embeddings with a single embedder with reindex",
+		})
+	}
+	return problems, nil
+}
+
+func (db *DB) checkIngestion() ([]Problem, error) {
+	rows, err := db.conn.Query("SELECT source, next_chunk, total_chunks FROM checkpoints WHERE next_chunk < total_chunks ORDER BY source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []Problem
+	for rows.Next() {
+		var source string
+		var next, total int
+		if err := rows.Scan(&source, &next, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan checkpoint: %w", err)
+		}
+		problems = append(problems, Problem{
+			Check:   CheckIngestion,
+			Message: fmt.Sprintf("Ingestion of %s stopped after %d of %d chunks", source, next, total),
+			Fix:     fmt.Sprintf("Ingest %s again to resume where it stopped", source),
+		})
+	}
+	return problems, rows.Err()
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insertSearchable(t, database, "Right to erasure of personal data")
+	problems, err := database.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("Expected a healthy database, got %+v", problems)
+	}
+
+	// A chunk without trigrams or embedding, an embedding of another
+	// dimension and an interrupted ingestion are all reported
+	if _, err := database.InsertChunk("Data protection officer", 0); err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+	other := insertSearchable(t, database, "Right to data portability")
+	if err := database.InsertEmbedding(other, []float32{1, 0, 0, 0}); err != nil {
+		t.Fatalf("InsertEmbedding failed: %v", err)
+	}
+	if err := database.SaveCheckpoint(Checkpoint{Source: "gdpr.pdf", Fingerprint: "abc", NextChunk: 64, TotalChunks: 100}); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	problems, err = database.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	found := make(map[string]int)
+	for _, p := range problems {
+		found[p.Check]++
+		if p.Message == "" || p.Fix == "" {
+			t.Errorf("Expected a message and a fix, got %+v", p)
+		}
+	}
+	if found[CheckIndex] != 1 || found[CheckEmbeddings] != 2 || found[CheckIngestion] != 1 {
+		t.Errorf("Expected index, 2 embedding and ingestion problems, got %+v", problems)
+	}
+
+	if _, err := database.Reindex(); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	problems, err = database.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	for _, p := range problems {
+		if p.Check == CheckIndex {
+			t.Errorf("Expected reindexing to fix the index, got %+v", p)
+		}
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := database.conn.Exec("DROP TABLE keyphrases"); err != nil {
+		t.Fatalf("Failed to drop table: %v", err)
+	}
+	problems, err := database.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Check != CheckSchema || !strings.Contains(problems[0].Message, "keyphrases") {
+		t.Errorf("Expected the missing table to be reported, got %+v", problems)
+	}
+}
+
+func TestCheckNewerSchema(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	version, err := database.StoredSchemaVersion()
+	if err != nil {
+		t.Fatalf("StoredSchemaVersion failed: %v", err)
+	}
+	if version != SchemaVersion {
+		t.Errorf("Expected Migrate to record schema version %d, got %d", SchemaVersion, version)
+	}
+
+	if _, err := database.conn.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion+1)); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	problems, err := database.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Check != CheckSchema || !strings.Contains(problems[0].Message, "newer") {
+		t.Errorf("Expected the newer schema to be reported, got %+v", problems)
+	}
+
+	// Migrating refuses to touch it rather than lower its version
+	if err := database.Migrate(); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected Migrate to reject the newer schema, got %v", err)
+	}
+	if version, _ := database.StoredSchemaVersion(); version != SchemaVersion+1 {
+		t.Errorf("Expected the schema version to be kept, got %d", version)
+	}
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Checkpoint records how far the ingestion of a source has got
+type Checkpoint struct {
+	Source      string
+	Fingerprint string // identifies the chunks being ingested
+	NextChunk   int    // index of the first chunk not yet stored
+	TotalChunks int
+	UpdatedAt   time.Time
+}
+
+// Complete reports whether every chunk of the source has been stored
+func (c Checkpoint) Complete() bool {
+	return c.NextChunk >= c.TotalChunks
+}
+
+// GetCheckpoint returns the checkpoint of a source, or nil if it has never
+// been ingested
+func (db *DB) GetCheckpoint(source string) (*Checkpoint, error) {
+	var cp Checkpoint
+	err := db.conn.QueryRow(
+		"SELECT source, fingerprint, next_chunk, total_chunks, updated_at FROM checkpoints WHERE source = ?",
+		source,
+	).Scan(&cp.Source, &cp.Fingerprint, &cp.NextChunk, &cp.TotalChunks, &cp.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// SaveCheckpoint stores the progress of a source, replacing any earlier
+// checkpoint
+func (db *DB) SaveCheckpoint(cp Checkpoint) error {
+	_, err := db.conn.Exec(
+		"INSERT OR REPLACE INTO checkpoints (source, fingerprint, next_chunk, total_chunks, updated_at) VALUES (?, ?, ?, ?, ?)",
+		cp.Source, cp.Fingerprint, cp.NextChunk, cp.TotalChunks, time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteSourceChunks hard-deletes the chunks of a source from the given
+// chunk index on, such as those left behind by an interrupted ingestion.
+// It returns the number removed.
+func (db *DB) DeleteSourceChunks(source string, fromIndex int) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		"SELECT id, chunk, language FROM documents WHERE source = ? AND chunk_index >= ?",
+		source, fromIndex,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query source chunks: %w", err)
+	}
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		if err := rows.Scan(&doc.ID, &doc.Chunk, &doc.Language); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, doc := range docs {
+		if err := deleteDocument(tx, doc); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit delete: %w", err)
+	}
+	return len(docs), nil
+}
+
+// DeleteCheckpoint forgets the progress of a source
+func (db *DB) DeleteCheckpoint(source string) error {
+	if _, err := db.conn.Exec("DELETE FROM checkpoints WHERE source = ?", source); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import "testing"
+
+func TestCheckpoint(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cp, err := database.GetCheckpoint("gdpr.txt")
+	if err != nil {
+		t.Fatalf("GetCheckpoint failed: %v", err)
+	}
+	if cp != nil {
+		t.Fatalf("Expected no checkpoint, got %+v", cp)
+	}
+
+	if err := database.SaveCheckpoint(Checkpoint{Source: "gdpr.txt", Fingerprint: "abc", NextChunk: 2, TotalChunks: 5}); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+	if err := database.SaveCheckpoint(Checkpoint{Source: "gdpr.txt", Fingerprint: "abc", NextChunk: 4, TotalChunks: 5}); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	cp, err = database.GetCheckpoint("gdpr.txt")
+	if err != nil || cp == nil {
+		t.Fatalf("GetCheckpoint = %v, %v", cp, err)
+	}
+	if cp.Fingerprint != "abc" || cp.NextChunk != 4 || cp.TotalChunks != 5 || cp.Complete() {
+		t.Errorf("Unexpected checkpoint %+v", cp)
+	}
+	if cp.UpdatedAt.IsZero() {
+		t.Error("Expected UpdatedAt to be set")
+	}
+}
+
+func TestDeleteSourceChunks(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for i, chunk := range []string{"Lawfulness of processing", "Conditions for consent", "Right to erasure"} {
+		id, err := database.InsertDocument(Document{Chunk: chunk, ChunkIndex: i, Source: "gdpr.txt"})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	other := insertSearchable(t, database, "Right to erasure of personal data")
+
+	removed, err := database.DeleteSourceChunks("gdpr.txt", 1)
+	if err != nil {
+		t.Fatalf("DeleteSourceChunks failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 chunks removed, got %d", removed)
+	}
+
+	if doc, _ := database.GetDocument(ids[0]); doc == nil || doc.Source != "gdpr.txt" {
+		t.Errorf("Expected chunk 0 to be kept with its source, got %+v", doc)
+	}
+	if doc, _ := database.GetDocument(ids[2]); doc != nil {
+		t.Errorf("Expected chunk 2 to be removed, got %+v", doc)
+	}
+
+	results, err := database.SearchTrigrams("erasure", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != other {
+		t.Errorf("Expected only the unrelated chunk to match, got %+v", results)
+	}
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Clause is the text of a clause or annex of the standard contractual
+// clauses, reassembled from its chunks
+type Clause struct {
+	Clause     string  `json:"clause"`          // e.g. "8" or "Annex II"
+	Title      string  `json:"title,omitempty"` // e.g. "Data protection safeguards"
+	Module     string  `json:"module,omitempty"`
+	Collection string  `json:"collection,omitempty"`
+	ChunkIDs   []int64 `json:"chunk_ids"`
+	Text       string  `json:"text"`
+}
+
+// Clause returns a clause of the standard contractual clauses as ingested
+// into a collection with the scc profile, or nil if it was not ingested.
+// With a module ("1" to "4"), text specific to other modules is left out.
+// The clause is taken from the source the first of its chunks was ingested
+// from.
+func (db *DB) Clause(number, module, collection string) (*Clause, error) {
+	var source, metadata string
+	err := db.conn.QueryRow(`
+		SELECT source, metadata FROM documents
+		WHERE deleted_at IS NULL AND collection = ? AND json_extract(metadata, '$.scc_clause') = ?
+		ORDER BY id LIMIT 1`, collection, number).Scan(&source, &metadata)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find clause: %w", err)
+	}
+	meta, err := decodeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// scc_modules is a comma-separated list such as "2,3", absent for text
+	// common to all modules
+	rows, err := db.conn.Query(`
+		SELECT id, chunk FROM documents
+		WHERE deleted_at IS NULL AND source = ? AND collection = ? AND json_extract(metadata, '$.scc_clause') = ?
+		AND (? = '' OR json_extract(metadata, '$.scc_modules') IS NULL OR ',' || json_extract(metadata, '$.scc_modules') || ',' LIKE ?)
+		ORDER BY chunk_index, id`, source, collection, number, module, "%,"+module+",%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clause chunks: %w", err)
+	}
+	text, ids, err := joinChunks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &Clause{
+		Clause:     number,
+		Title:      meta["scc_clause_title"],
+		Module:     module,
+		Collection: collection,
+		ChunkIDs:   ids,
+		Text:       text,
+	}, nil
+}
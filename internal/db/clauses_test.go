@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestClause(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i, d := range []Document{
+		{Chunk: "Clause 8\nData protection safeguards\nThe data exporter warrants that it has used reasonable efforts.", Metadata: map[string]string{"scc_clause": "8", "scc_clause_title": "Data protection safeguards"}},
+		{Chunk: "MODULE ONE: Transfer controller to controller\n8.1 Purpose limitation", Metadata: map[string]string{"scc_clause": "8", "scc_modules": "1"}},
+		{Chunk: "MODULE TWO: Transfer controller to processor\nMODULE THREE: Transfer processor to processor\n8.1 Instructions", Metadata: map[string]string{"scc_clause": "8", "scc_modules": "2,3"}},
+		{Chunk: "Clause 9\nUse of sub-processors", Metadata: map[string]string{"scc_clause": "9", "scc_modules": "2,3"}},
+	} {
+		d.ChunkIndex = i
+		d.Source = "scc.txt"
+		d.Collection = "scc"
+		if _, err := database.InsertDocument(d); err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+	}
+
+	clause, err := database.Clause("8", "", "scc")
+	if err != nil {
+		t.Fatalf("Clause failed: %v", err)
+	}
+	if clause == nil || clause.Title != "Data protection safeguards" || len(clause.ChunkIDs) != 3 {
+		t.Fatalf("Expected all of clause 8, got %+v", clause)
+	}
+
+	clause, _ = database.Clause("8", "3", "scc")
+	if clause == nil || len(clause.ChunkIDs) != 2 ||
+		clause.Text != "Clause 8\nData protection safeguards\nThe data exporter warrants that it has used reasonable efforts.\nMODULE TWO: Transfer controller to processor\nMODULE THREE: Transfer processor to processor\n8.1 Instructions" {
+		t.Errorf("Expected the common text and Module Three of clause 8, got %+v", clause)
+	}
+	if clause, _ := database.Clause("9", "1", "scc"); clause == nil || len(clause.ChunkIDs) != 0 {
+		t.Errorf("Expected no text of clause 9 for Module One, got %+v", clause)
+	}
+	if clause, err := database.Clause("10", "", "scc"); err != nil || clause != nil {
+		t.Errorf("Expected no clause 10, got %+v, %v", clause, err)
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Collection summarises a named corpus of chunks
+type Collection struct {
+	Name     string `json:"name"`
+	Language string `json:"language"` // most common chunk language
+	Chunks   int    `json:"chunks"`
+}
+
+// Collections lists the collections with live chunks in name order. Chunks
+// ingested without a collection are reported under the empty name.
+func (db *DB) Collections() ([]Collection, error) {
+	rows, err := db.conn.Query(`
+		SELECT collection, language, COUNT(*)
+		FROM documents
+		WHERE deleted_at IS NULL
+		GROUP BY collection, language
+		ORDER BY collection, COUNT(*) DESC, language
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []Collection
+	for rows.Next() {
+		var name, language string
+		var count int
+		if err := rows.Scan(&name, &language, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		// Rows of a collection arrive most common language first
+		if n := len(collections); n > 0 && collections[n-1].Name == name {
+			collections[n-1].Chunks += count
+			continue
+		}
+		collections = append(collections, Collection{Name: name, Language: language, Chunks: count})
+	}
+	return collections, rows.Err()
+}
+
+// DeleteCollection permanently removes every chunk of a collection, live or
+// soft-deleted, with its trigrams, keyphrases and embeddings, in a single
+// transaction. Sources left without chunks lose their checkpoint and file
+// record, so ingesting them again starts afresh. It returns the number of
+// chunks removed.
+func (db *DB) DeleteCollection(name string) (int, error) {
+	if name == "" {
+		return 0, errors.New("collection name is required")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, chunk, language, source FROM documents WHERE collection = ?", name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query collection: %w", err)
+	}
+	var docs []Document
+	sources := make(map[string]bool)
+	for rows.Next() {
+		var doc Document
+		if err := rows.Scan(&doc.ID, &doc.Chunk, &doc.Language, &doc.Source); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		docs = append(docs, doc)
+		if doc.Source != "" {
+			sources[doc.Source] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(docs) == 0 {
+		return 0, fmt.Errorf("collection %q not found", name)
+	}
+
+	for _, doc := range docs {
+		if err := deleteDocument(tx, doc); err != nil {
+			return 0, err
+		}
+	}
+	for source := range sources {
+		var remaining int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM documents WHERE source = ?", source).Scan(&remaining); err != nil {
+			return 0, fmt.Errorf("failed to count chunks of %s: %w", source, err)
+		}
+		if remaining > 0 {
+			continue
+		}
+		for _, table := range []string{"checkpoints", "source_files"} {
+			if _, err := tx.Exec("DELETE FROM "+table+" WHERE source = ?", source); err != nil {
+				return 0, fmt.Errorf("failed to forget source %s: %w", source, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit deletion: %w", err)
+	}
+	return len(docs), nil
+}
+
+// weighCollections multiplies the scores of results by the weight of their
+// collection and sorts them again
+func (opts SearchOptions) weighCollections(results []SearchResult) {
+	if len(opts.CollectionWeights) == 0 {
+		return
+	}
+	for i := range results {
+		weight, ok := opts.CollectionWeights[results[i].Collection]
+		if !ok {
+			continue
+		}
+		results[i].Score *= weight
+		if x := results[i].Explain; x != nil {
+			x.CollectionWeight = weight
+		}
+	}
+	sortResults(results)
+}
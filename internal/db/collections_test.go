@@ -0,0 +1,206 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCollections(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs := []Document{
+		{Chunk: "Recht auf Löschung", Language: "de", Collection: "gdpr-de"},
+		{Chunk: "Recht auf Datenübertragbarkeit", Language: "de", Collection: "gdpr-de"},
+		{Chunk: "Article 17", Language: "", Collection: "gdpr-de"},
+		{Chunk: "Droit à l'effacement", Language: "fr", Collection: "gdpr-fr"},
+		{Chunk: "Right to erasure", Language: "en"},
+	}
+	var ids []int64
+	for i, d := range docs {
+		d.ChunkIndex = i
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := database.SoftDelete(ids[3]); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+
+	collections, err := database.Collections()
+	if err != nil {
+		t.Fatalf("Collections failed: %v", err)
+	}
+	want := []Collection{
+		{Name: "", Language: "en", Chunks: 1},
+		{Name: "gdpr-de", Language: "de", Chunks: 3},
+	}
+	if len(collections) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, collections)
+	}
+	for i := range want {
+		if collections[i] != want[i] {
+			t.Errorf("Collection %d = %+v, want %+v", i, collections[i], want[i])
+		}
+	}
+
+	doc, _ := database.GetDocument(ids[0])
+	if doc.Collection != "gdpr-de" {
+		t.Errorf("Expected collection to round-trip, got %q", doc.Collection)
+	}
+}
+
+func TestCollectionFilter(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i, d := range []Document{
+		{Chunk: "Article 17 personal data erasure", Collection: "gdpr-en"},
+		{Chunk: "Article 17 personal data guidance", Collection: "edpb"},
+	} {
+		d.ChunkIndex = i
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		if err := database.InsertEmbedding(id, []float32{1, 0}); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+	}
+
+	results, err := database.HybridSearch("personal data", []float32{1, 0}, 10, SearchOptions{Collection: "edpb"})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 2 {
+		t.Errorf("Expected only the edpb chunk, got %+v", results)
+	}
+}
+
+func TestSearchCollectionsWeighted(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs := []Document{
+		{Chunk: "Right to erasure of personal data", Collection: "gdpr"},
+		{Chunk: "Guidelines on the right to erasure", Collection: "edpb"},
+		{Chunk: "Right to erasure under national law", Collection: "national"},
+	}
+	for i, d := range docs {
+		d.ChunkIndex = i
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+	}
+
+	opts := SearchOptions{Collections: []string{"gdpr", "edpb"}, Explain: true}
+	results, err := database.HybridSearch("right to erasure", nil, 10, opts)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected results of the two collections only, got %+v", results)
+	}
+	for _, r := range results {
+		if r.Collection != "gdpr" && r.Collection != "edpb" {
+			t.Errorf("Expected the collection in each result, got %+v", r)
+		}
+	}
+
+	// Weighting the guidelines up puts them first
+	opts.CollectionWeights = map[string]float64{"edpb": 3}
+	weighted, err := database.HybridSearch("right to erasure", nil, 10, opts)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if weighted[0].Collection != "edpb" || weighted[0].Explain.CollectionWeight != 3 {
+		t.Errorf("Expected the weighted collection first, got %+v", weighted)
+	}
+	for _, r := range results {
+		if r.Collection == "edpb" && weighted[0].Score != 3*r.Score {
+			t.Errorf("Expected the score tripled from %v, got %v", r.Score, weighted[0].Score)
+		}
+	}
+
+	opts.CollectionWeights = map[string]float64{"edpb": -1}
+	if _, err := database.HybridSearch("right to erasure", nil, 10, opts); err == nil {
+		t.Error("Expected a negative collection weight to be rejected")
+	}
+}
+
+func TestDeleteCollection(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []Document{
+		{Chunk: "Guidelines on consent", Source: "edpb.pdf", Collection: "edpb"},
+		{Chunk: "Guidelines on transparency", Source: "edpb.pdf", Collection: "edpb", ChunkIndex: 1},
+		{Chunk: "Right to erasure of personal data", Source: "gdpr.txt"},
+	} {
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		if err := database.InsertEmbedding(id, []float32{1, 0, 0}); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := database.SoftDelete(ids[1]); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	for _, source := range []string{"edpb.pdf", "gdpr.txt"} {
+		if err := database.SaveSourceFile(SourceFile{Source: source, Hash: "abc", Size: 3, ModTime: time.Now()}); err != nil {
+			t.Fatalf("SaveSourceFile failed: %v", err)
+		}
+	}
+
+	n, err := database.DeleteCollection("edpb")
+	if err != nil {
+		t.Fatalf("DeleteCollection failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected both chunks removed, the soft-deleted one included, got %d", n)
+	}
+
+	stats, err := database.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Chunks != 1 || stats.DeletedChunks != 0 || stats.EmbeddedChunks != 1 || len(stats.Collections) != 1 {
+		t.Errorf("Expected only the chunk outside the collection to remain, got %+v", stats)
+	}
+	if f, err := database.GetSourceFile("edpb.pdf"); err != nil || f != nil {
+		t.Errorf("Expected the file record of the emptied source to be removed, got %+v (%v)", f, err)
+	}
+	if f, err := database.GetSourceFile("gdpr.txt"); err != nil || f == nil {
+		t.Errorf("Expected the other file record to be kept, got %+v (%v)", f, err)
+	}
+	problems, err := database.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected the index to stay consistent, got %+v", problems)
+	}
+
+	if _, err := database.DeleteCollection("edpb"); err == nil {
+		t.Error("Expected an error for a collection that does not exist")
+	}
+	if _, err := database.DeleteCollection(""); err == nil {
+		t.Error("Expected an error for an empty collection name")
+	}
+}
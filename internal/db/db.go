@@ -3,10 +3,14 @@ package db
 import (
 	"database/sql"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"math"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	_ "embed"
 
@@ -16,9 +20,16 @@ import (
 //go:embed schema.sql
 var schemaSQL string
 
+//go:embed seed.sql
+var seedSQL string
+
 // DB wraps the SQLite database connection
 type DB struct {
 	conn *sql.DB
+	path string
+
+	vocabMu sync.Mutex
+	vocab   *vocabulary // words of the chunks, for spelling correction
 }
 
 // Document represents a text chunk
@@ -26,13 +37,34 @@ type Document struct {
 	ID         int64
 	Chunk      string
 	ChunkIndex int
+	Language   string
+	Metadata   map[string]string // structured chunk attributes such as "page"
+	Source     string            // file the chunk was ingested from, if any
+	Collection string            // named corpus the chunk belongs to, if any
+	DeletedAt  *time.Time        // set when the chunk has been soft-deleted
 }
 
 // SearchResult represents a search result with score
 type SearchResult struct {
-	ID      int64   `json:"id"`
-	Score   float64 `json:"score"`
-	Snippet string  `json:"snippet"`
+	ID         int64   `json:"id"`
+	Score      float64 `json:"score"`
+	Snippet    string  `json:"snippet"`
+	Collection string  `json:"collection,omitempty"` // collection of the chunk, if any
+
+	// RawScore is the score the result was ranked by, once Score holds its
+	// relevance from NormalizeScores
+	RawScore float64 `json:"raw_score,omitempty"`
+
+	// Highlights locates the query words in the snippet when requested
+	// with SearchOptions.Highlight
+	Highlights []Highlight `json:"highlights,omitempty"`
+
+	// Explain breaks the score down when requested with
+	// SearchOptions.Explain
+	Explain *Explanation `json:"explain,omitempty"`
+
+	chunk     string  // full chunk text, kept for re-snippeting fused results
+	relevance float64 // relevance between 0 and 1, see setRelevance
 }
 
 // Open opens or creates the database at the given path
@@ -48,7 +80,7 @@ func Open(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{conn: conn}, nil
+	return &DB{conn: conn, path: dbPath}, nil
 }
 
 // Close closes the database connection
@@ -56,20 +88,281 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// Migrate applies the schema to the database
+// SearchOptions restricts which documents a search considers and shapes
+// the returned snippets
+type SearchOptions struct {
+	Language      string // only match chunks tagged with this language
+	Collection    string // only match chunks in this collection
+	ProvisionType string // only match chunks whose provision_type metadata is "recital", "article" or "annex"
+	// Collections only matches chunks in any of these collections, and
+	// CollectionWeights multiplies the scores of a collection's results,
+	// 1 for collections without a weight
+	Collections       []string
+	CollectionWeights map[string]float64
+	// ExcludeProvisionTypes drops chunks of these provision types, keeping
+	// chunks without one such as guidelines and case law
+	ExcludeProvisionTypes []string
+	Article               string   // only match chunks of this article, e.g. "17" or "Article 17"
+	Chapter               string   // only match chunks of this chapter, e.g. "IV", "4" or "Chapter IV"
+	Recital               string   // only match this recital, e.g. "26"
+	Articles              []string // only match chunks of any of these articles
+	Recitals              []string // only match any of these recitals
+	Country               string   // only match enforcement decisions of an authority of this country, e.g. "IE"
+	ViolatedArticle       string   // only match enforcement decisions finding this article violated, e.g. "32"
+	Guideline             string   // only match chunks of this EDPB or WP29 document, e.g. "Guidelines 05/2020"; "WP248" matches every revision
+	Case                  string   // only match chunks of this judgment, by case number ("C-311/18") or part of its name or alias ("Schrems")
+	SCCModule             string   // only match chunks of the standard contractual clauses applying to this module, "1" to "4"
+	Phrases               []string // only match chunks containing each phrase; quoted phrases in a query are added
+	SnippetLength         int      // maximum snippet length in characters (default 200)
+	SnippetContext        int      // characters kept around the best match (default 80)
+	Fusion                Fusion   // how HybridSearch combines keyword and vector rankings
+
+	// Highlight reports where the query words match in each snippet.
+	// Setting HighlightPre or HighlightPost instead wraps the matches in the
+	// snippet with these markers, e.g. "<em>" and "</em>".
+	Highlight     bool
+	HighlightPre  string
+	HighlightPost string
+
+	Explain bool // attach an Explanation of its score to each result
+
+	condition queryExpr // boolean condition from AND, OR and NOT in the query
+}
+
+// snippet builds the snippet for a chunk according to the options
+func (opts SearchOptions) snippet(chunk, query string) string {
+	context := opts.SnippetContext
+	if context == 0 {
+		context = DefaultSnippetContext
+	}
+	return makeSnippet(chunk, query, opts.SnippetLength, context)
+}
+
+// conditions returns extra SQL conditions (and their arguments) for the
+// documents table aliased as d
+func (opts SearchOptions) conditions() (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	sb.WriteString(" AND d.deleted_at IS NULL")
+	if opts.Language != "" {
+		sb.WriteString(" AND d.language = ?")
+		args = append(args, strings.ToLower(opts.Language))
+	}
+	if opts.Collection != "" {
+		sb.WriteString(" AND d.collection = ?")
+		args = append(args, opts.Collection)
+	}
+	if len(opts.Collections) > 0 {
+		placeholders := make([]string, len(opts.Collections))
+		for i, c := range opts.Collections {
+			placeholders[i] = "?"
+			args = append(args, c)
+		}
+		sb.WriteString(" AND d.collection IN (" + strings.Join(placeholders, ",") + ")")
+	}
+	if opts.ProvisionType != "" {
+		sb.WriteString(" AND json_extract(d.metadata, '$.provision_type') = ?")
+		args = append(args, strings.ToLower(opts.ProvisionType))
+	}
+	if len(opts.ExcludeProvisionTypes) > 0 {
+		placeholders := make([]string, len(opts.ExcludeProvisionTypes))
+		for i, t := range opts.ExcludeProvisionTypes {
+			placeholders[i] = "?"
+			args = append(args, strings.ToLower(t))
+		}
+		sb.WriteString(" AND COALESCE(json_extract(d.metadata, '$.provision_type'), '') NOT IN (" + strings.Join(placeholders, ",") + ")")
+	}
+	if opts.Article != "" {
+		article, ok := ArticleNumber(opts.Article)
+		if !ok {
+			article = opts.Article
+		}
+		sb.WriteString(" AND json_extract(d.metadata, '$.article') = ?")
+		args = append(args, article)
+	}
+	if opts.Chapter != "" {
+		// Chapters are numbered in Roman numerals, but some sources use digits
+		chapter, ok := ChapterNumber(opts.Chapter)
+		if !ok {
+			chapter = opts.Chapter
+		}
+		sb.WriteString(" AND json_extract(d.metadata, '$.chapter') IN (?, ?)")
+		args = append(args, chapter, strconv.Itoa(fromRoman(chapter)))
+	}
+	if opts.Recital != "" {
+		recital, ok := RecitalNumber(opts.Recital)
+		if !ok {
+			recital = opts.Recital
+		}
+		sb.WriteString(" AND json_extract(d.metadata, '$.recital') = ?")
+		args = append(args, recital)
+	}
+	for _, f := range []struct {
+		key     string
+		numbers []string
+		parse   func(string) (string, bool)
+	}{
+		{"article", opts.Articles, ArticleNumber},
+		{"recital", opts.Recitals, RecitalNumber},
+	} {
+		if len(f.numbers) == 0 {
+			continue
+		}
+		placeholders := make([]string, len(f.numbers))
+		for i, n := range f.numbers {
+			if number, ok := f.parse(n); ok {
+				n = number
+			}
+			placeholders[i] = "?"
+			args = append(args, n)
+		}
+		sb.WriteString(" AND json_extract(d.metadata, '$." + f.key + "') IN (" + strings.Join(placeholders, ",") + ")")
+	}
+	if opts.Country != "" {
+		sb.WriteString(" AND json_extract(d.metadata, '$.country') = ?")
+		args = append(args, strings.ToUpper(opts.Country))
+	}
+	if opts.ViolatedArticle != "" {
+		article, ok := ArticleNumber(opts.ViolatedArticle)
+		if !ok {
+			article = opts.ViolatedArticle
+		}
+		// violated_articles is a comma-separated list such as "5,6,13"
+		sb.WriteString(" AND ',' || json_extract(d.metadata, '$.violated_articles') || ',' LIKE ?")
+		args = append(args, "%,"+article+",%")
+	}
+	if opts.Guideline != "" {
+		sb.WriteString(" AND (json_extract(d.metadata, '$.guideline') = ? COLLATE NOCASE OR json_extract(d.metadata, '$.guideline') LIKE ?)")
+		args = append(args, opts.Guideline, opts.Guideline+" rev%")
+	}
+	if opts.Case != "" {
+		sb.WriteString(" AND (json_extract(d.metadata, '$.case_number') = ? OR json_extract(d.metadata, '$.case_alias') LIKE ? OR json_extract(d.metadata, '$.case_name') LIKE ?)")
+		args = append(args, strings.ToUpper(opts.Case), "%"+opts.Case+"%", "%"+opts.Case+"%")
+	}
+	if opts.SCCModule != "" {
+		// scc_modules is a comma-separated list such as "2,3", absent for
+		// text common to all modules
+		sb.WriteString(" AND (json_extract(d.metadata, '$.scc_modules') IS NULL OR ',' || json_extract(d.metadata, '$.scc_modules') || ',' LIKE ?)")
+		args = append(args, "%,"+opts.SCCModule+",%")
+	}
+	return sb.String(), args
+}
+
+// columnUpgrades lists columns added after a table was first released, so
+// databases created by older versions can be brought up to date
+var columnUpgrades = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"documents", "language", "TEXT NOT NULL DEFAULT ''"},
+	{"documents", "deleted_at", "DATETIME"},
+	{"documents", "metadata", "TEXT NOT NULL DEFAULT '{}'"},
+	{"documents", "source", "TEXT NOT NULL DEFAULT ''"},
+	{"documents", "collection", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// SchemaVersion is the version of the schema Migrate applies, recorded in
+// the database's user_version. It is raised whenever tables or columns are
+// added or the seed data changes.
+const SchemaVersion = 2
+
+// Migrate applies the schema to the database. A database with a schema
+// newer than SchemaVersion is left untouched and an error returned.
 func (db *DB) Migrate() error {
-	_, err := db.conn.Exec(schemaSQL)
+	stored, err := db.StoredSchemaVersion()
 	if err != nil {
+		return err
+	}
+	if stored > SchemaVersion {
+		return fmt.Errorf("database has schema version %d, newer than the version %d this binary supports", stored, SchemaVersion)
+	}
+
+	if err := db.upgradeSchema(); err != nil {
+		return err
+	}
+	if _, err := db.conn.Exec(schemaSQL); err != nil {
 		return fmt.Errorf("failed to apply schema: %w", err)
 	}
+	if err := db.migrateLegacyTrigrams(); err != nil {
+		return err
+	}
+	// Seeding on every start would restore entries deleted since
+	if stored < SchemaVersion {
+		if _, err := db.conn.Exec(seedSQL); err != nil {
+			return fmt.Errorf("failed to seed database: %w", err)
+		}
+	}
+	if _, err := db.conn.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
 	return nil
 }
 
+// StoredSchemaVersion returns the schema version recorded in the database,
+// 0 for a database never migrated or migrated before versions were recorded
+func (db *DB) StoredSchemaVersion() (int, error) {
+	var version int
+	if err := db.conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// upgradeSchema adds columns missing from tables created by older versions
+func (db *DB) upgradeSchema() error {
+	for _, u := range columnUpgrades {
+		columns, err := db.tableColumns(u.table)
+		if err != nil {
+			return err
+		}
+		// Missing tables are created from scratch by the schema
+		if len(columns) == 0 || columns[u.column] {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", u.table, u.column, u.definition)
+		if _, err := db.conn.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", u.table, u.column, err)
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the set of column names of a table
+func (db *DB) tableColumns(table string) (map[string]bool, error) {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan column info: %w", err)
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
 // InsertChunk inserts a document chunk and returns its ID
 func (db *DB) InsertChunk(chunk string, chunkIndex int) (int64, error) {
+	return db.InsertDocument(Document{Chunk: chunk, ChunkIndex: chunkIndex})
+}
+
+// InsertDocument inserts a document chunk with its attributes and returns its ID
+func (db *DB) InsertDocument(doc Document) (int64, error) {
+	metadata, err := encodeMetadata(doc.Metadata)
+	if err != nil {
+		return 0, err
+	}
 	result, err := db.conn.Exec(
-		"INSERT INTO documents (chunk, chunk_index) VALUES (?, ?)",
-		chunk, chunkIndex,
+		"INSERT INTO documents (chunk, chunk_index, language, metadata, source, collection) VALUES (?, ?, ?, ?, ?, ?)",
+		doc.Chunk, doc.ChunkIndex, strings.ToLower(doc.Language), metadata, doc.Source, doc.Collection,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert chunk: %w", err)
@@ -77,7 +370,7 @@ func (db *DB) InsertChunk(chunk string, chunkIndex int) (int64, error) {
 	return result.LastInsertId()
 }
 
-// InsertTrigrams inserts trigrams for a document
+// InsertTrigrams adds a document to the posting lists of its trigrams
 func (db *DB) InsertTrigrams(docID int64, trigrams []string) error {
 	tx, err := db.conn.Begin()
 	if err != nil {
@@ -85,16 +378,30 @@ func (db *DB) InsertTrigrams(docID int64, trigrams []string) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare("INSERT INTO trigrams (trigram, doc_id) VALUES (?, ?)")
+	batch := make(postingBatch)
+	batch.add(docID, trigrams)
+	if err := batch.flush(tx); err != nil {
+		return fmt.Errorf("failed to insert trigram: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// InsertTrigramsBatch inserts the trigrams of several documents, keyed by
+// document ID, writing each posting list once
+func (db *DB) InsertTrigramsBatch(trigrams map[int64][]string) error {
+	tx, err := db.conn.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	for _, trigram := range trigrams {
-		if _, err := stmt.Exec(trigram, docID); err != nil {
-			return fmt.Errorf("failed to insert trigram: %w", err)
-		}
+	batch := make(postingBatch)
+	for docID, t := range trigrams {
+		batch.add(docID, t)
+	}
+	if err := batch.flush(tx); err != nil {
+		return fmt.Errorf("failed to insert trigrams: %w", err)
 	}
 
 	return tx.Commit()
@@ -116,101 +423,204 @@ func (db *DB) InsertEmbedding(docID int64, embedding []float32) error {
 // GetDocument retrieves a document by ID
 func (db *DB) GetDocument(id int64) (*Document, error) {
 	row := db.conn.QueryRow(
-		"SELECT id, chunk, chunk_index FROM documents WHERE id = ?",
+		"SELECT id, chunk, chunk_index, language, metadata, source, collection, deleted_at FROM documents WHERE id = ?",
 		id,
 	)
 
 	var doc Document
-	err := row.Scan(&doc.ID, &doc.Chunk, &doc.ChunkIndex)
+	var metadata string
+	var deletedAt sql.NullTime
+	err := row.Scan(&doc.ID, &doc.Chunk, &doc.ChunkIndex, &doc.Language, &metadata, &doc.Source, &doc.Collection, &deletedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get document: %w", err)
 	}
+	if doc.Metadata, err = decodeMetadata(metadata); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		doc.DeletedAt = &deletedAt.Time
+	}
 	return &doc, nil
 }
 
-// SearchTrigrams searches documents by trigram similarity
-func (db *DB) SearchTrigrams(query string, limit int) ([]SearchResult, error) {
-	queryTrigrams := GenerateTrigrams(strings.ToLower(query))
-	if len(queryTrigrams) == 0 {
-		return nil, nil
-	}
-
-	// Build query with placeholders
-	placeholders := make([]string, len(queryTrigrams))
-	args := make([]interface{}, len(queryTrigrams))
-	for i, t := range queryTrigrams {
-		placeholders[i] = "?"
-		args[i] = t
+// SearchTrigrams searches documents by trigram similarity.
+// The query is expanded with any synonyms found in the synonyms table and
+// the term map, and chunks of the articles its terms map to or with a
+// keyphrase in the query are boosted. Phrases quoted in the query must
+// occur in every result, and AND, OR and NOT in the query filter the
+// results.
+func (db *DB) SearchTrigrams(query string, limit int, opts SearchOptions) ([]SearchResult, error) {
+	query, opts = opts.withQuery(query)
+	expansions, articles, err := db.expandQuery(query)
+	if err != nil {
+		return nil, err
 	}
 
-	// Count matching trigrams per document
-	sqlQuery := fmt.Sprintf(`
-		SELECT d.id, d.chunk, COUNT(DISTINCT t.trigram) as match_count
-		FROM documents d
-		JOIN trigrams t ON d.id = t.doc_id
-		WHERE t.trigram IN (%s)
-		GROUP BY d.id
-		ORDER BY match_count DESC
-		LIMIT ?
-	`, strings.Join(placeholders, ","))
-
-	args = append(args, limit)
+	// Chunks are indexed under the normalization of their language, so try
+	// each normalization of the query and keep a document's best score
+	scores := make(map[int64]float64)
+	matches := make(map[int64][2]int) // matched and total query trigrams behind each score
+	for _, n := range queryNormalizations(query, opts.Language) {
+		queryTrigrams := GenerateTrigrams(normalize(strings.ToLower(query), n))
+		if len(queryTrigrams) == 0 {
+			continue
+		}
+		normalized := make([]string, len(expansions))
+		for i, e := range expansions {
+			normalized[i] = normalize(strings.ToLower(e), n)
+		}
+		queryTrigrams = mergeTrigrams(queryTrigrams, normalized)
 
-	rows, err := db.conn.Query(sqlQuery, args...)
+		counts, err := db.lookupPostings(queryTrigrams)
+		if err != nil {
+			return nil, err
+		}
+		// Jaccard-like similarity: share of query trigrams matched
+		for id, count := range counts {
+			if score := float64(count) / float64(len(queryTrigrams)); score > scores[id] {
+				scores[id] = score
+				matches[id] = [2]int{count, len(queryTrigrams)}
+			}
+		}
+	}
+	boosted, err := db.articleDocuments(articles)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search trigrams: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
+	isBoosted := make(map[int64]bool, len(boosted))
+	for _, id := range boosted {
+		scores[id] += termArticleBoost
+		isBoosted[id] = true
+	}
+	keyphrased, err := db.keyphraseDocuments(query)
+	if err != nil {
+		return nil, err
+	}
+	hasKeyphrase := make(map[int64]bool, len(keyphrased))
+	for _, id := range keyphrased {
+		scores[id] += keyphraseBoost
+		hasKeyphrase[id] = true
+	}
+	if len(scores) == 0 {
+		return nil, nil
+	}
+
+	// Rank candidates by score
+	candidates := make([]int64, 0, len(scores))
+	for id := range scores {
+		candidates = append(candidates, id)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		si, sj := scores[candidates[i]], scores[candidates[j]]
+		if si != sj {
+			return si > sj
+		}
+		return candidates[i] < candidates[j]
+	})
 
+	// Load chunks in rank order, dropping documents excluded by the
+	// filters (or no longer present) until the limit is reached
+	filter, filterArgs := opts.conditions()
 	var results []SearchResult
-	queryTrigramCount := float64(len(queryTrigrams))
 
-	for rows.Next() {
-		var id int64
-		var chunk string
-		var matchCount int
-		if err := rows.Scan(&id, &chunk, &matchCount); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+	for len(candidates) > 0 && len(results) < limit {
+		batch := candidates
+		if len(batch) > limit {
+			batch = batch[:limit]
 		}
+		candidates = candidates[len(batch):]
 
-		// Calculate Jaccard-like similarity score
-		score := float64(matchCount) / queryTrigramCount
+		chunks, err := db.loadChunks(batch, filter, filterArgs)
+		if err != nil {
+			return nil, err
+		}
 
-		// Create snippet (first 200 chars)
-		snippet := chunk
-		if len(snippet) > 200 {
-			snippet = snippet[:200] + "..."
+		for _, id := range batch {
+			result, ok := chunks[id]
+			if !ok || !opts.matches(result.chunk) {
+				continue
+			}
+
+			result.Score = scores[id]
+			opts.annotate(&result, query)
+			if opts.Explain {
+				keyword := &KeywordExplanation{
+					Rank:           len(results) + 1,
+					Score:          scores[id],
+					TrigramMatches: matches[id][0],
+					QueryTrigrams:  matches[id][1],
+				}
+				if isBoosted[id] {
+					keyword.ArticleBoost = termArticleBoost
+				}
+				if hasKeyphrase[id] {
+					keyword.KeyphraseBoost = keyphraseBoost
+				}
+				result.Explain = &Explanation{Paths: []string{PathKeyword}, FusedScore: scores[id], Keyword: keyword}
+			}
+			results = append(results, result)
+			if len(results) == limit {
+				break
+			}
 		}
+	}
 
-		results = append(results, SearchResult{
-			ID:      id,
-			Score:   score,
-			Snippet: snippet,
-		})
+	return results, nil
+}
+
+// loadChunks returns the chunk text and collection of the given documents
+// that satisfy the extra SQL conditions as unscored results, keyed by
+// document ID
+func (db *DB) loadChunks(ids []int64, filter string, filterArgs []interface{}) (map[int64]SearchResult, error) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, 0, len(ids)+len(filterArgs))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
 	}
+	args = append(args, filterArgs...)
 
-	return results, rows.Err()
+	rows, err := db.conn.Query(
+		"SELECT d.id, d.chunk, d.collection FROM documents d WHERE d.id IN ("+strings.Join(placeholders, ",")+")"+filter,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunks: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := make(map[int64]SearchResult, len(ids))
+	for rows.Next() {
+		var r SearchResult
+		if err := rows.Scan(&r.ID, &r.chunk, &r.Collection); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		chunks[r.ID] = r
+	}
+	return chunks, rows.Err()
 }
 
 // SearchVectors searches documents by vector similarity
-func (db *DB) SearchVectors(queryEmbedding []float32, limit int) ([]SearchResult, error) {
+func (db *DB) SearchVectors(queryEmbedding []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	filter, filterArgs := opts.conditions()
 	rows, err := db.conn.Query(`
-		SELECT e.doc_id, e.embedding, d.chunk
+		SELECT e.doc_id, e.embedding, d.chunk, d.collection
 		FROM embeddings e
 		JOIN documents d ON e.doc_id = d.id
-	`)
+		WHERE 1 = 1`+filter, filterArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query embeddings: %w", err)
 	}
 	defer rows.Close()
 
 	type scored struct {
-		id      int64
-		score   float64
-		snippet string
+		id         int64
+		score      float64
+		chunk      string
+		collection string
 	}
 
 	var scoredDocs []scored
@@ -218,23 +628,22 @@ func (db *DB) SearchVectors(queryEmbedding []float32, limit int) ([]SearchResult
 	for rows.Next() {
 		var docID int64
 		var embeddingBlob []byte
-		var chunk string
-		if err := rows.Scan(&docID, &embeddingBlob, &chunk); err != nil {
+		var chunk, collection string
+		if err := rows.Scan(&docID, &embeddingBlob, &chunk, &collection); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
+		if !opts.matches(chunk) {
+			continue
+		}
 
 		embedding := bytesToFloat32Slice(embeddingBlob)
-		similarity := cosineSimilarity(queryEmbedding, embedding)
-
-		snippet := chunk
-		if len(snippet) > 200 {
-			snippet = snippet[:200] + "..."
-		}
+		similarity := CosineSimilarity(queryEmbedding, embedding)
 
 		scoredDocs = append(scoredDocs, scored{
-			id:      docID,
-			score:   similarity,
-			snippet: snippet,
+			id:         docID,
+			score:      similarity,
+			chunk:      chunk,
+			collection: collection,
 		})
 	}
 
@@ -242,9 +651,12 @@ func (db *DB) SearchVectors(queryEmbedding []float32, limit int) ([]SearchResult
 		return nil, err
 	}
 
-	// Sort by score descending
+	// Sort by score descending, ties by ID
 	sort.Slice(scoredDocs, func(i, j int) bool {
-		return scoredDocs[i].score > scoredDocs[j].score
+		if scoredDocs[i].score != scoredDocs[j].score {
+			return scoredDocs[i].score > scoredDocs[j].score
+		}
+		return scoredDocs[i].id < scoredDocs[j].id
 	})
 
 	// Limit results
@@ -254,83 +666,156 @@ func (db *DB) SearchVectors(queryEmbedding []float32, limit int) ([]SearchResult
 
 	results := make([]SearchResult, len(scoredDocs))
 	for i, s := range scoredDocs {
-		results[i] = SearchResult{
-			ID:      s.id,
-			Score:   s.score,
-			Snippet: s.snippet,
+		results[i] = SearchResult{ID: s.id, Score: s.score, Collection: s.collection, chunk: s.chunk}
+		opts.annotate(&results[i], "")
+		if opts.Explain {
+			results[i].Explain = &Explanation{
+				Paths:      []string{PathVector},
+				FusedScore: s.score,
+				Vector:     &VectorExplanation{Rank: i + 1, Similarity: s.score},
+			}
 		}
 	}
 
 	return results, nil
 }
 
-// HybridSearch performs a combined trigram and vector search
-func (db *DB) HybridSearch(query string, queryEmbedding []float32, limit int) ([]SearchResult, error) {
-	// Get trigram results
-	trigramResults, err := db.SearchTrigrams(query, limit*2)
-	if err != nil {
+// HybridSearch performs a combined trigram and vector search, fusing the
+// two rankings as configured by opts.Fusion. A ranking with weight 0 is not
+// searched at all, unless there is no query embedding to fall back on.
+// Quoted phrases and boolean operators in the query filter both rankings.
+func (db *DB) HybridSearch(query string, queryEmbedding []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	query, opts = opts.withQuery(query)
+	if err := opts.Fusion.Validate(); err != nil {
 		return nil, err
 	}
+	for collection, weight := range opts.CollectionWeights {
+		if weight < 0 {
+			return nil, fmt.Errorf("weight of collection %q must not be negative, got %v", collection, weight)
+		}
+	}
+	fusion := opts.Fusion.WithDefaults()
+
+	// Get trigram results
+	var trigramResults []SearchResult
+	if fusion.KeywordWeight > 0 || queryEmbedding == nil {
+		var err error
+		trigramResults, err = db.SearchTrigrams(query, limit*2, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// If no embedding provided, return trigram results only
 	if queryEmbedding == nil {
+		opts.weighCollections(trigramResults)
 		if len(trigramResults) > limit {
 			trigramResults = trigramResults[:limit]
 		}
+		if err := db.setRelevance(trigramResults, query, nil, fusion, opts); err != nil {
+			return nil, err
+		}
 		return trigramResults, nil
 	}
 
 	// Get vector results
-	vectorResults, err := db.SearchVectors(queryEmbedding, limit*2)
-	if err != nil {
+	var vectorResults []SearchResult
+	if fusion.VectorWeight > 0 {
+		var err error
+		vectorResults, err = db.SearchVectors(queryEmbedding, limit*2, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := fusion.fuse(trigramResults, vectorResults)
+	opts.weighCollections(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	for i := range results {
+		opts.annotate(&results[i], query)
+	}
+	if err := db.setRelevance(results, query, queryEmbedding, fusion, opts); err != nil {
 		return nil, err
 	}
+	return results, nil
+}
+
+// AddSynonym registers a synonym pair used for query expansion
+func (db *DB) AddSynonym(term, synonym string) error {
+	_, err := db.conn.Exec(
+		"INSERT OR IGNORE INTO synonyms (term, synonym) VALUES (?, ?)",
+		strings.ToLower(strings.TrimSpace(term)), strings.ToLower(strings.TrimSpace(synonym)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add synonym: %w", err)
+	}
+	return nil
+}
 
-	// Merge results using reciprocal rank fusion
-	scores := make(map[int64]float64)
-	snippets := make(map[int64]string)
+// ExpandQuery returns the synonyms of every term that occurs in the query,
+// followed by the expansions of the term map. Synonym pairs match in both
+// directions, term map entries one way, and both only on whole words.
+func (db *DB) ExpandQuery(query string) ([]string, error) {
+	expansions, _, err := db.expandQuery(query)
+	return expansions, err
+}
 
-	const k = 60.0 // RRF constant
+// expandQuery returns the expansions of the query and the GDPR articles its
+// terms map to
+func (db *DB) expandQuery(query string) ([]string, []string, error) {
+	query = strings.ToLower(query)
+	seen := make(map[string]bool)
+	var expansions []string
 
-	for i, r := range trigramResults {
-		scores[r.ID] += 1.0 / (k + float64(i+1))
-		snippets[r.ID] = r.Snippet
+	add := func(s string) {
+		if !seen[s] && !containsTerm(query, s) {
+			seen[s] = true
+			expansions = append(expansions, s)
+		}
 	}
 
-	for i, r := range vectorResults {
-		scores[r.ID] += 1.0 / (k + float64(i+1))
-		if _, exists := snippets[r.ID]; !exists {
-			snippets[r.ID] = r.Snippet
+	synonyms, err := db.synonyms()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pair := range synonyms {
+		if containsTerm(query, pair[0]) {
+			add(pair[1])
+		}
+		if containsTerm(query, pair[1]) {
+			add(pair[0])
 		}
 	}
 
-	// Convert to sorted results
-	type scoredDoc struct {
-		id    int64
-		score float64
+	terms, articles, err := db.matchTerms(query)
+	if err != nil {
+		return nil, nil, err
 	}
-	var sorted []scoredDoc
-	for id, score := range scores {
-		sorted = append(sorted, scoredDoc{id, score})
+	for _, t := range terms {
+		add(t)
 	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].score > sorted[j].score
-	})
+	return expansions, articles, nil
+}
 
-	if len(sorted) > limit {
-		sorted = sorted[:limit]
+// synonyms returns all synonym pairs
+func (db *DB) synonyms() ([][2]string, error) {
+	rows, err := db.conn.Query("SELECT term, synonym FROM synonyms")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query synonyms: %w", err)
 	}
+	defer rows.Close()
 
-	results := make([]SearchResult, len(sorted))
-	for i, s := range sorted {
-		results[i] = SearchResult{
-			ID:      s.id,
-			Score:   s.score,
-			Snippet: snippets[s.id],
+	var pairs [][2]string
+	for rows.Next() {
+		var pair [2]string
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, fmt.Errorf("failed to scan synonym: %w", err)
 		}
+		pairs = append(pairs, pair)
 	}
-
-	return results, nil
+	return pairs, rows.Err()
 }
 
 // SetMetadata sets a metadata key-value pair
@@ -379,6 +864,74 @@ func GenerateTrigrams(s string) []string {
 	return trigrams
 }
 
+// encodeMetadata serializes chunk metadata for the metadata column
+func encodeMetadata(metadata map[string]string) (string, error) {
+	if len(metadata) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeMetadata parses the metadata column; empty objects decode to nil
+func decodeMetadata(data string) (map[string]string, error) {
+	var metadata map[string]string
+	if err := json.Unmarshal([]byte(data), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
+// mergeTrigrams appends the trigrams of each phrase that are not already present
+func mergeTrigrams(trigrams []string, phrases []string) []string {
+	seen := make(map[string]bool, len(trigrams))
+	for _, t := range trigrams {
+		seen[t] = true
+	}
+	for _, p := range phrases {
+		for _, t := range GenerateTrigrams(p) {
+			if !seen[t] {
+				seen[t] = true
+				trigrams = append(trigrams, t)
+			}
+		}
+	}
+	return trigrams
+}
+
+// containsTerm reports whether term occurs in text as a whole word or phrase
+func containsTerm(text, term string) bool {
+	if term == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(term)
+		if !isWordByteAt(text, start-1) && !isWordByteAt(text, end) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordByteAt(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
 // Helper functions for embedding serialization
 
 func float32SliceToBytes(floats []float32) []byte {
@@ -398,7 +951,9 @@ func bytesToFloat32Slice(bytes []byte) []float32 {
 	return floats
 }
 
-func cosineSimilarity(a, b []float32) float64 {
+// CosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when they differ in length or either is zero
+func CosineSimilarity(a, b []float32) float64 {
 	if len(a) != len(b) || len(a) == 0 {
 		return 0
 	}
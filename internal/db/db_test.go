@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -144,7 +145,7 @@ func TestInsertTrigrams(t *testing.T) {
 	}
 
 	// Search should find the document
-	results, err := database.SearchTrigrams("article", 10)
+	results, err := database.SearchTrigrams("article", 10, SearchOptions{})
 	if err != nil {
 		t.Fatalf("SearchTrigrams failed: %v", err)
 	}
@@ -202,7 +203,7 @@ func TestInsertAndSearchEmbeddings(t *testing.T) {
 
 	// Search with query embedding similar to first two chunks
 	queryEmbedding := []float32{0.95, 0.05, 0.0, 0.0}
-	results, err := database.SearchVectors(queryEmbedding, 10)
+	results, err := database.SearchVectors(queryEmbedding, 10, SearchOptions{})
 	if err != nil {
 		t.Fatalf("SearchVectors failed: %v", err)
 	}
@@ -258,7 +259,7 @@ func TestHybridSearch(t *testing.T) {
 
 	// Test hybrid search
 	queryEmbedding := []float32{0.9, 0.5, 0.0}
-	results, err := database.HybridSearch("right of access", queryEmbedding, 10)
+	results, err := database.HybridSearch("right of access", queryEmbedding, 10, SearchOptions{})
 	if err != nil {
 		t.Fatalf("HybridSearch failed: %v", err)
 	}
@@ -375,9 +376,9 @@ func TestCosineSimilarity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := cosineSimilarity(tt.a, tt.b)
+			result := CosineSimilarity(tt.a, tt.b)
 			if result < tt.expected-0.001 || result > tt.expected+0.001 {
-				t.Errorf("cosineSimilarity(%v, %v) = %f, want %f", tt.a, tt.b, result, tt.expected)
+				t.Errorf("CosineSimilarity(%v, %v) = %f, want %f", tt.a, tt.b, result, tt.expected)
 			}
 		})
 	}
@@ -399,3 +400,418 @@ func TestFloat32Serialization(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandQuery(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	expansions, err := database.ExpandQuery("Who must appoint a DPO?")
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	if !reflect.DeepEqual(expansions, []string{"data protection officer"}) {
+		t.Errorf("Expected DPO expansion, got %v", expansions)
+	}
+
+	// Synonyms match in both directions
+	expansions, err = database.ExpandQuery("right to erasure")
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	if !reflect.DeepEqual(expansions, []string{"right to be forgotten"}) {
+		t.Errorf("Expected reverse expansion, got %v", expansions)
+	}
+
+	// Terms must match whole words
+	expansions, err = database.ExpandQuery("adpositions")
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	if len(expansions) != 0 {
+		t.Errorf("Expected no expansions, got %v", expansions)
+	}
+
+	if err := database.AddSynonym("GDPR", "General Data Protection Regulation"); err != nil {
+		t.Fatalf("AddSynonym failed: %v", err)
+	}
+	expansions, err = database.ExpandQuery("scope of the gdpr")
+	if err != nil {
+		t.Fatalf("ExpandQuery failed: %v", err)
+	}
+	if !reflect.DeepEqual(expansions, []string{"general data protection regulation"}) {
+		t.Errorf("Expected custom expansion, got %v", expansions)
+	}
+}
+
+func TestSearchTrigramsUsesSynonyms(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chunk := "The controller shall designate a data protection officer."
+	docID, err := database.InsertChunk(chunk, 0)
+	if err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+	if err := database.InsertTrigrams(docID, GenerateTrigrams(chunk)); err != nil {
+		t.Fatalf("InsertTrigrams failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("DPO", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != docID {
+		t.Errorf("Expected synonym expansion to find document, got %+v", results)
+	}
+}
+
+func TestLanguageFilter(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs := []Document{
+		{Chunk: "Right to erasure of personal data", Language: "en"},
+		{Chunk: "Recht auf Löschung personal data", Language: "de"},
+	}
+	for i, d := range docs {
+		d.ChunkIndex = i
+		docID, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		if err := database.InsertEmbedding(docID, []float32{1, 0}); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+	}
+
+	for _, lang := range []string{"en", "DE"} {
+		results, err := database.HybridSearch("personal data", []float32{1, 0}, 10, SearchOptions{Language: lang})
+		if err != nil {
+			t.Fatalf("HybridSearch failed: %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result for %s, got %d", lang, len(results))
+		}
+		doc, err := database.GetDocument(results[0].ID)
+		if err != nil {
+			t.Fatalf("GetDocument failed: %v", err)
+		}
+		if doc.Language != strings.ToLower(lang) {
+			t.Errorf("Expected language %s, got %s", lang, doc.Language)
+		}
+	}
+}
+
+func TestMigrateUpgradesOldSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := Open(filepath.Join(tmpDir, "old.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	// Schema as shipped before documents carried a language column
+	_, err = database.conn.Exec(`
+		CREATE TABLE documents (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			chunk TEXT NOT NULL,
+			chunk_index INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO documents (chunk, chunk_index) VALUES ('old chunk', 0);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	doc, err := database.GetDocument(1)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc == nil || doc.Chunk != "old chunk" || doc.Language != "" {
+		t.Errorf("Unexpected document after upgrade: %+v", doc)
+	}
+}
+
+func TestMigrateKeepsDeletedSeeds(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	count := func(query string) int {
+		t.Helper()
+		var n int
+		if err := database.conn.QueryRow(query).Scan(&n); err != nil {
+			t.Fatalf("Count failed: %v", err)
+		}
+		return n
+	}
+	if count("SELECT COUNT(*) FROM synonyms WHERE term = 'dpo'") != 1 {
+		t.Fatal("Expected the seed synonyms on a new database")
+	}
+	if count("SELECT COUNT(*) FROM synonyms WHERE term = 'dpa'") != 0 {
+		t.Error("Expected no synonym for the ambiguous dpa")
+	}
+
+	for _, stmt := range []string{
+		"DELETE FROM synonyms WHERE term = 'dpo'",
+		"DELETE FROM term_map WHERE term = 'sar'",
+		"DELETE FROM article_recitals WHERE article = '1'",
+	} {
+		if _, err := database.conn.Exec(stmt); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if n := count("SELECT COUNT(*) FROM synonyms WHERE term = 'dpo'") +
+		count("SELECT COUNT(*) FROM term_map WHERE term = 'sar'") +
+		count("SELECT COUNT(*) FROM article_recitals WHERE article = '1'"); n != 0 {
+		t.Errorf("Expected deleted seeds to stay deleted, %d came back", n)
+	}
+
+	// An older database is seeded again on upgrade
+	if _, err := database.conn.Exec("PRAGMA user_version = 1"); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if count("SELECT COUNT(*) FROM synonyms WHERE term = 'dpo'") != 1 {
+		t.Error("Expected the seeds to be restored when the schema version changes")
+	}
+}
+
+func TestInsertDocumentMetadata(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docID, err := database.InsertDocument(Document{
+		Chunk:    "Guidelines on consent",
+		Metadata: map[string]string{"page": "4"},
+	})
+	if err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+
+	doc, err := database.GetDocument(docID)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if !reflect.DeepEqual(doc.Metadata, map[string]string{"page": "4"}) {
+		t.Errorf("Metadata mismatch: got %v", doc.Metadata)
+	}
+}
+
+func TestSearchArticlesAndRecitals(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, metadata := range []map[string]string{
+		{"article": "6"}, {"article": "7"}, {"article": "9"}, {"recital": "40"}, {"recital": "26"},
+	} {
+		chunk := "Processing shall be lawful only if the data subject has given consent."
+		id, err := database.InsertDocument(Document{Chunk: chunk, Metadata: metadata})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	found := func(opts SearchOptions) []int64 {
+		t.Helper()
+		results, err := database.SearchTrigrams("lawful consent", 10, opts)
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		var got []int64
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		return got
+	}
+	if got := found(SearchOptions{Articles: []string{"6", "Art. 7"}}); !reflect.DeepEqual(got, ids[:2]) {
+		t.Errorf("Expected the chunks of Articles 6 and 7, got %v", got)
+	}
+	if got := found(SearchOptions{Recitals: []string{"(40)", "41"}}); !reflect.DeepEqual(got, ids[3:4]) {
+		t.Errorf("Expected recital 40, got %v", got)
+	}
+}
+
+func TestSearchEnforcementFilters(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, metadata := range []map[string]string{
+		{"country": "IE", "violated_articles": "5,6,13"},
+		{"country": "FR", "violated_articles": "6,7"},
+		{"country": "IE", "violated_articles": "46"},
+	} {
+		chunk := "The supervisory authority imposed an administrative fine on the controller."
+		id, err := database.InsertDocument(Document{Chunk: chunk, Metadata: metadata})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	found := func(opts SearchOptions) []int64 {
+		t.Helper()
+		results, err := database.SearchTrigrams("administrative fine", 10, opts)
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		var got []int64
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		return got
+	}
+	if got := found(SearchOptions{Country: "ie"}); !reflect.DeepEqual(got, []int64{ids[0], ids[2]}) {
+		t.Errorf("Expected the Irish decisions, got %v", got)
+	}
+	if got := found(SearchOptions{ViolatedArticle: "Art. 6"}); !reflect.DeepEqual(got, ids[:2]) {
+		t.Errorf("Expected the decisions on Article 6, got %v", got)
+	}
+	// Article 4 is not a prefix match of 46
+	if got := found(SearchOptions{ViolatedArticle: "4", Country: "IE"}); got != nil {
+		t.Errorf("Expected no decisions on Article 4, got %v", got)
+	}
+}
+
+func TestSearchGuideline(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, guideline := range []string{"Guidelines 05/2020", "WP248 rev.01", "WP24"} {
+		chunk := "Consent should be freely given and the controller must assess the risk."
+		id, err := database.InsertDocument(Document{Chunk: chunk, Metadata: map[string]string{"guideline": guideline}})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	for guideline, want := range map[string][]int64{
+		"guidelines 05/2020": ids[:1],
+		"WP248":              ids[1:2],
+		"WP248 rev.01":       ids[1:2],
+		"WP24":               ids[2:],
+	} {
+		results, err := database.SearchTrigrams("freely given consent", 10, SearchOptions{Guideline: guideline})
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		var got []int64
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Guideline %q: got %v, want %v", guideline, got, want)
+		}
+	}
+}
+
+func TestSearchCase(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, metadata := range []map[string]string{
+		{"case_number": "C-311/18", "case_alias": "Schrems II", "case_name": "Data Protection Commissioner v Facebook Ireland Ltd"},
+		{"case_number": "C-131/12", "case_alias": "Google Spain", "case_name": "Google Spain SL v Agencia Española de Protección de Datos"},
+	} {
+		chunk := "The transfer of personal data to a third country requires an adequate level of protection."
+		id, err := database.InsertDocument(Document{Chunk: chunk, Metadata: metadata})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	for c, want := range map[string][]int64{
+		"c-311/18": ids[:1],
+		"schrems":  ids[:1],
+		"Agencia":  ids[1:],
+		"C-311":    nil,
+	} {
+		results, err := database.SearchTrigrams("adequate protection", 10, SearchOptions{Case: c})
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		var got []int64
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Case %q: got %v, want %v", c, got, want)
+		}
+	}
+}
+
+func TestSearchSCCModule(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, metadata := range []map[string]string{
+		{"scc_clause": "8"},
+		{"scc_clause": "8", "scc_modules": "1"},
+		{"scc_clause": "8", "scc_modules": "2,3"},
+	} {
+		chunk := "The data importer shall process the personal data only for the specific purposes of the transfer."
+		id, err := database.InsertDocument(Document{Chunk: chunk, Metadata: metadata})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	for module, want := range map[string][]int64{
+		"1": {ids[0], ids[1]},
+		"3": {ids[0], ids[2]},
+		"4": {ids[0]},
+	} {
+		results, err := database.SearchTrigrams("specific purposes of the transfer", 10, SearchOptions{SCCModule: module})
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		got := make(map[int64]bool)
+		for _, r := range results {
+			got[r.ID] = true
+		}
+		if len(got) != len(want) {
+			t.Errorf("Module %s: got %v, want %v", module, got, want)
+		}
+		for _, id := range want {
+			if !got[id] {
+				t.Errorf("Module %s: expected %d in %v", module, id, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// definitionRe matches the start of a numbered point of Article 4, as in
+// "(1) 'personal data' means"
+var definitionRe = regexp.MustCompile(`\((\d+)\)\s*['‘"“]([^'’"”]{2,80})['’"”]\s+means\b`)
+
+// maxDefinitionMatches caps the definitions MatchDefinitions returns
+const maxDefinitionMatches = 5
+
+// Definition is a term defined in Article 4
+type Definition struct {
+	Point string `json:"point"` // point of Article 4, e.g. "1"
+	Term  string `json:"term"`
+	Text  string `json:"text"` // the definition, e.g. "'personal data' means any information ..."
+}
+
+// ParseDefinitions splits the text of Article 4 into its definitions, each
+// running from its "(N) 'term' means" up to the next one or to a chapter,
+// section or article heading line, whichever comes first
+func ParseDefinitions(text string) []Definition {
+	matches := definitionRe.FindAllStringSubmatchIndex(text, -1)
+	definitions := make([]Definition, 0, len(matches))
+	for i, m := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		if _, next := lineAt(text, m[1]); next < end {
+			end = min(end, provisionEnd(text, "article", "4", next))
+		}
+		definitions = append(definitions, Definition{
+			Point: text[m[2]:m[3]],
+			Term:  strings.Join(strings.Fields(text[m[4]:m[5]]), " "),
+			Text:  strings.TrimRight(strings.Join(strings.Fields(text[m[3]+1:end]), " "), " ;"), // after "(N)"
+		})
+	}
+	return definitions
+}
+
+// Definitions returns the definitions of Article 4 as ingested into a
+// collection, taken from the source the first of its chunks was ingested
+// from. It returns none if Article 4 was not ingested.
+func (db *DB) Definitions(collection string) ([]Definition, error) {
+	article, err := db.Article("4", collection)
+	if err != nil || article == nil {
+		return nil, err
+	}
+	return ParseDefinitions(article.Text), nil
+}
+
+// MatchDefinitions returns the definitions whose term best matches term,
+// best first: an exact match, terms within a few typos ("proccessor",
+// "pseudonymization"), then terms containing the words of term or
+// contained in it ("breach" finds "personal data breach"). Case, accents
+// and spacing are ignored.
+func MatchDefinitions(definitions []Definition, term string) []Definition {
+	query := definitionKey(term)
+	if query == "" {
+		return nil
+	}
+
+	type match struct {
+		definition Definition
+		rank       [2]int // kind of match, then distance within it
+	}
+	var matches []match
+	for _, d := range definitions {
+		key := definitionKey(d.Term)
+		distance := editDistance(key, query)
+		switch {
+		case distance == 0:
+			matches = append(matches, match{d, [2]int{0, 0}})
+		case distance <= maxTypos(key):
+			matches = append(matches, match{d, [2]int{1, distance}})
+		case containsTerm(key, query) || containsTerm(query, key):
+			matches = append(matches, match{d, [2]int{2, max(len(key)-len(query), len(query)-len(key))}})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank[0] != matches[j].rank[0] {
+			return matches[i].rank[0] < matches[j].rank[0]
+		}
+		return matches[i].rank[1] < matches[j].rank[1]
+	})
+
+	var best []Definition
+	for _, m := range matches {
+		if len(best) == maxDefinitionMatches {
+			break
+		}
+		best = append(best, m.definition)
+	}
+	return best
+}
+
+// definitionKey normalizes a term for matching
+func definitionKey(term string) string {
+	return strings.Join(strings.Fields(stripAccents(strings.ToLower(term))), " ")
+}
+
+// maxTypos is how many edits a term of the given length may be misspelt by
+// and still match
+func maxTypos(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
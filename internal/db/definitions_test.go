@@ -0,0 +1,117 @@
+package db
+
+import "testing"
+
+const article4 = `Article 4 Definitions
+For the purposes of this Regulation:
+(1) 'personal data' means any information relating to an identified or identifiable natural person ('data subject');
+(2) ‘processing’ means any operation or set of operations which is performed on personal data;
+(4) 'profiling' means any form of automated processing of personal data;
+(5) 'pseudonymisation' means the processing of personal data in such a manner that the data can no longer be attributed;
+(7) 'controller' means the natural or legal person which determines the purposes and means of the processing;
+(8) 'processor' means a natural or legal person which processes personal data on behalf of the controller;
+(12) 'personal data breach' means a breach of security leading to the accidental or unlawful destruction of personal data;`
+
+func TestParseDefinitions(t *testing.T) {
+	definitions := ParseDefinitions(article4)
+	if len(definitions) != 7 {
+		t.Fatalf("Expected 7 definitions, got %+v", definitions)
+	}
+	first := definitions[0]
+	if first.Point != "1" || first.Term != "personal data" ||
+		first.Text != "'personal data' means any information relating to an identified or identifiable natural person ('data subject')" {
+		t.Errorf("Unexpected first definition %+v", first)
+	}
+	if definitions[1].Term != "processing" || definitions[6].Point != "12" {
+		t.Errorf("Expected typographic quotes and point numbers parsed, got %+v", definitions)
+	}
+}
+
+func TestParseDefinitionsEndsAtHeadings(t *testing.T) {
+	text := "(7) 'controller' means the natural or legal person which determines the purposes of the processing;\n" +
+		"CHAPTER III\nRights of the data subject\nSection 3\nRectification and erasure\nArticle 17\nRight to erasure"
+	definitions := ParseDefinitions(text)
+	if len(definitions) != 1 ||
+		definitions[0].Text != "'controller' means the natural or legal person which determines the purposes of the processing" {
+		t.Errorf("Expected the definition to end before the chapter heading, got %+v", definitions)
+	}
+}
+
+func TestDefinitionsWindowChunks(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	text := "Article 3\nTerritorial scope\nThis Regulation applies to the processing of personal data.\n" +
+		"Article 4\nDefinitions\nFor the purposes of this Regulation:\n" +
+		"(1) 'personal data' means any information relating to an identified or identifiable natural person;\n" +
+		"(2) 'controller' means the natural or legal person which determines the purposes of the processing;\n" +
+		"CHAPTER III\nRights of the data subject\nArticle 17\nRight to erasure"
+	insertWindowed(t, database, "gdpr.txt", text, 80, 30, map[string]map[string]string{
+		"Article 3":   {"article": "3"},
+		"Article 4":   {"article": "4"},
+		"CHAPTER III": nil,
+		"Article 17":  {"article": "17"},
+	})
+
+	definitions, err := database.Definitions("")
+	if err != nil {
+		t.Fatalf("Definitions failed: %v", err)
+	}
+	if len(definitions) != 2 || definitions[0].Point != "1" ||
+		definitions[1].Text != "'controller' means the natural or legal person which determines the purposes of the processing" {
+		t.Errorf("Expected both definitions, the last ending at Article 4, got %+v", definitions)
+	}
+}
+
+func TestMatchDefinitions(t *testing.T) {
+	definitions := ParseDefinitions(article4)
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"Controller", []string{"controller"}},
+		{"proccessor", []string{"processor"}},
+		{"pseudonymization", []string{"pseudonymisation"}},
+		{"breach", []string{"personal data breach"}},
+		{"personal data", []string{"personal data", "personal data breach"}},
+		{"what is profiling", []string{"profiling"}},
+		{"anonymisation", nil},
+	}
+	for _, tt := range tests {
+		matches := MatchDefinitions(definitions, tt.term)
+		var got []string
+		for _, m := range matches {
+			got = append(got, m.Term)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("MatchDefinitions(%q) = %v, want %v", tt.term, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("MatchDefinitions(%q) = %v, want %v", tt.term, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if definitions, err := database.Definitions(""); err != nil || len(definitions) != 0 {
+		t.Errorf("Expected no definitions before ingestion, got %+v, %v", definitions, err)
+	}
+	if _, err := database.InsertDocument(Document{Chunk: article4, Source: "gdpr.txt", Metadata: map[string]string{"article": "4"}}); err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+	definitions, err := database.Definitions("")
+	if err != nil {
+		t.Fatalf("Definitions failed: %v", err)
+	}
+	if len(definitions) != 7 {
+		t.Errorf("Expected the definitions of Article 4, got %+v", definitions)
+	}
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SoftDelete marks a document as deleted. Tombstoned documents are excluded
+// from search but can still be fetched by ID for audit until purged.
+func (db *DB) SoftDelete(id int64) error {
+	result, err := db.conn.Exec(
+		"UPDATE documents SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL",
+		time.Now().UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("document %d not found or already deleted", id)
+	}
+	return nil
+}
+
+// Restore clears the tombstone of a soft-deleted document
+func (db *DB) Restore(id int64) error {
+	result, err := db.conn.Exec(
+		"UPDATE documents SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore document: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("document %d not found or not deleted", id)
+	}
+	return nil
+}
+
+// Purge permanently removes documents soft-deleted before the given time,
+// along with their trigrams and embeddings. It returns the number removed.
+func (db *DB) Purge(before time.Time) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		"SELECT id, chunk, language FROM documents WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		before.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query deleted documents: %w", err)
+	}
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		if err := rows.Scan(&doc.ID, &doc.Chunk, &doc.Language); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, doc := range docs {
+		if err := deleteDocument(tx, doc); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+	return len(docs), nil
+}
+
+// deleteDocument hard-deletes a document and removes it from the posting
+// lists of its trigrams; embeddings are removed by the foreign key cascade.
+// Unnormalized trigrams are removed too, in case the chunk was indexed
+// before its language had a normalization.
+func deleteDocument(tx *sql.Tx, doc Document) error {
+	for _, trigram := range mergeTrigrams(LanguageTrigrams(doc.Chunk, doc.Language), []string{doc.Chunk}) {
+		if err := removePosting(tx, trigram, doc.ID); err != nil {
+			return fmt.Errorf("failed to remove trigram: %w", err)
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM documents WHERE id = ?", doc.ID); err != nil {
+		return fmt.Errorf("failed to delete document %d: %w", doc.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func insertSearchable(t *testing.T, database *DB, chunk string) int64 {
+	t.Helper()
+
+	docID, err := database.InsertChunk(chunk, 0)
+	if err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+	if err := database.InsertTrigrams(docID, GenerateTrigrams(chunk)); err != nil {
+		t.Fatalf("InsertTrigrams failed: %v", err)
+	}
+	if err := database.InsertEmbedding(docID, []float32{1, 0, 0}); err != nil {
+		t.Fatalf("InsertEmbedding failed: %v", err)
+	}
+	return docID
+}
+
+func TestSoftDelete(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	kept := insertSearchable(t, database, "Right to erasure of personal data")
+	deleted := insertSearchable(t, database, "Right to erasure without undue delay")
+
+	if err := database.SoftDelete(deleted); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	if err := database.SoftDelete(deleted); err == nil {
+		t.Error("Expected error when deleting twice")
+	}
+
+	results, err := database.HybridSearch("erasure", []float32{1, 0, 0}, 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != kept {
+		t.Errorf("Expected only the live document, got %+v", results)
+	}
+
+	// Tombstoned documents remain retrievable for audit
+	doc, err := database.GetDocument(deleted)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc == nil || doc.DeletedAt == nil {
+		t.Fatalf("Expected tombstoned document, got %+v", doc)
+	}
+
+	if err := database.Restore(deleted); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	results, err = database.SearchTrigrams("erasure", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected restored document in results, got %d", len(results))
+	}
+}
+
+func TestPurge(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	kept := insertSearchable(t, database, "Right to data portability")
+	purged := insertSearchable(t, database, "Right to erasure")
+
+	if err := database.SoftDelete(purged); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+
+	// Documents deleted after the cutoff are retained
+	n, err := database.Purge(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("Expected nothing purged before cutoff, got %d", n)
+	}
+
+	n, err = database.Purge(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 document purged, got %d", n)
+	}
+
+	doc, err := database.GetDocument(purged)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc != nil {
+		t.Error("Expected purged document to be gone")
+	}
+
+	var postings int
+	if err := database.conn.QueryRow("SELECT COUNT(*) FROM trigram_postings WHERE trigram = 'era'").Scan(&postings); err != nil {
+		t.Fatalf("Failed to count postings: %v", err)
+	}
+	if postings != 0 {
+		t.Error("Expected trigrams unique to the purged document to be removed")
+	}
+
+	counts, err := database.lookupPostings([]string{"rig"})
+	if err != nil {
+		t.Fatalf("lookupPostings failed: %v", err)
+	}
+	if len(counts) != 1 || counts[kept] != 1 {
+		t.Errorf("Expected shared trigram to keep only the live document, got %v", counts)
+	}
+}
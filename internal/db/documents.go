@@ -0,0 +1,97 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DocumentFilter selects chunks by where they come from
+type DocumentFilter struct {
+	Collection string // only chunks of this collection
+	Source     string // only chunks ingested from this source
+	Article    string // only chunks of this article, e.g. "17" or "Article 17"
+}
+
+// conditions returns SQL conditions (and their arguments) for the
+// documents table
+func (f DocumentFilter) conditions() (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	if f.Collection != "" {
+		sb.WriteString(" AND collection = ?")
+		args = append(args, f.Collection)
+	}
+	if f.Source != "" {
+		sb.WriteString(" AND source = ?")
+		args = append(args, f.Source)
+	}
+	if f.Article != "" {
+		article, ok := ArticleNumber(f.Article)
+		if !ok {
+			article = f.Article
+		}
+		sb.WriteString(" AND json_extract(metadata, '$.article') = ?")
+		args = append(args, article)
+	}
+	return sb.String(), args
+}
+
+// Documents returns the live chunks selected by filter in ID order, every
+// live chunk for an empty filter
+func (db *DB) Documents(filter DocumentFilter) ([]Document, error) {
+	conditions, args := filter.conditions()
+	rows, err := db.conn.Query(`SELECT id, chunk, chunk_index, language, metadata, source, collection
+		FROM documents WHERE deleted_at IS NULL`+conditions+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		var metadata string
+		if err := rows.Scan(&doc.ID, &doc.Chunk, &doc.ChunkIndex, &doc.Language, &metadata, &doc.Source, &doc.Collection); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		if doc.Metadata, err = decodeMetadata(metadata); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// DuplicateChunks returns the IDs of live chunks with identical text, one
+// ascending group per text, such as a file ingested twice under different
+// names
+func (db *DB) DuplicateChunks() ([][]int64, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, chunk FROM documents
+		WHERE deleted_at IS NULL AND chunk IN (
+			SELECT chunk FROM documents WHERE deleted_at IS NULL
+			GROUP BY chunk HAVING COUNT(*) > 1)
+		ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query duplicate chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var groups [][]int64
+	index := make(map[string]int)
+	for rows.Next() {
+		var id int64
+		var chunk string
+		if err := rows.Scan(&id, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		i, ok := index[chunk]
+		if !ok {
+			i = len(groups)
+			index[chunk] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], id)
+	}
+	return groups, rows.Err()
+}
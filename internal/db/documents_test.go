@@ -0,0 +1,67 @@
+package db
+
+import "testing"
+
+func TestDocuments(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []Document{
+		{Chunk: "Article 17 Right to erasure", Source: "gdpr.txt", Collection: "gdpr-en", Metadata: map[string]string{"article": "17"}},
+		{Chunk: "Article 18 Right to restriction", Source: "gdpr.txt", Collection: "gdpr-en", ChunkIndex: 1, Metadata: map[string]string{"article": "18"}},
+		{Chunk: "Article 17 Right to erasure", Source: "gdpr-copy.txt", Collection: "gdpr-en", Metadata: map[string]string{"article": "17"}},
+		{Chunk: "Guidelines on consent", Source: "edpb.pdf", Collection: "edpb"},
+	} {
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	for _, tc := range []struct {
+		filter DocumentFilter
+		want   []int64
+	}{
+		{DocumentFilter{}, ids},
+		{DocumentFilter{Collection: "edpb"}, ids[3:]},
+		{DocumentFilter{Article: "Article 17"}, []int64{ids[0], ids[2]}},
+		{DocumentFilter{Source: "gdpr.txt", Article: "17"}, ids[:1]},
+	} {
+		docs, err := database.Documents(tc.filter)
+		if err != nil {
+			t.Fatalf("Documents failed: %v", err)
+		}
+		var got []int64
+		for _, d := range docs {
+			got = append(got, d.ID)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("Documents(%+v) = %v, want %v", tc.filter, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("Documents(%+v) = %v, want %v", tc.filter, got, tc.want)
+				break
+			}
+		}
+	}
+
+	groups, err := database.DuplicateChunks()
+	if err != nil {
+		t.Fatalf("DuplicateChunks failed: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0]) != 2 || groups[0][0] != ids[0] || groups[0][1] != ids[2] {
+		t.Errorf("Expected chunks %d and %d as duplicates, got %v", ids[0], ids[2], groups)
+	}
+
+	// A soft-deleted copy is not a duplicate
+	if err := database.SoftDelete(ids[2]); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	if groups, err := database.DuplicateChunks(); err != nil || len(groups) != 0 {
+		t.Errorf("Expected no duplicates, got %v (%v)", groups, err)
+	}
+}
@@ -0,0 +1,74 @@
+package db
+
+// Ranking paths a result can be found by
+const (
+	PathKeyword = "keyword"
+	PathVector  = "vector"
+)
+
+// Explanation breaks a result's score down into the rankings it was found
+// in, for tuning relevance. It is set when SearchOptions.Explain is.
+type Explanation struct {
+	Paths      []string            `json:"paths"`            // rankings the result was found in: "keyword", "vector" or both
+	Fusion     string              `json:"fusion,omitempty"` // fusion method, when both rankings were searched
+	FusedScore float64             `json:"fused_score"`      // score after fusing the rankings
+	Keyword    *KeywordExplanation `json:"keyword,omitempty"`
+	Vector     *VectorExplanation  `json:"vector,omitempty"`
+
+	// CollectionWeight is the weight of the result's collection the fused
+	// score was multiplied by, when collections were weighted
+	CollectionWeight float64 `json:"collection_weight,omitempty"`
+
+	// Queries counts the formulations of a question that found the result
+	// when their results were fused with FuseQueries
+	Queries int `json:"queries,omitempty"`
+	// RerankScore is the reranker's score, when the results were reranked
+	RerankScore *float64 `json:"rerank_score,omitempty"`
+}
+
+// KeywordExplanation describes a result's place in the trigram ranking
+type KeywordExplanation struct {
+	Rank           int     `json:"rank"`                      // position in the keyword ranking, from 1
+	Score          float64 `json:"score"`                     // share of query trigrams matched, plus any boosts
+	TrigramMatches int     `json:"trigram_matches"`           // query and expansion trigrams found in the chunk
+	QueryTrigrams  int     `json:"query_trigrams"`            // trigrams of the query and its expansions
+	ArticleBoost   float64 `json:"article_boost,omitempty"`   // boost for an article the query's terms map to
+	KeyphraseBoost float64 `json:"keyphrase_boost,omitempty"` // boost for a stored keyphrase of the chunk in the query
+	Contribution   float64 `json:"contribution,omitempty"`    // share of the fused score from this ranking
+}
+
+// VectorExplanation describes a result's place in the vector ranking
+type VectorExplanation struct {
+	Rank         int     `json:"rank"`                   // position in the vector ranking, from 1
+	Similarity   float64 `json:"similarity"`             // cosine similarity to the query embedding
+	Contribution float64 `json:"contribution,omitempty"` // share of the fused score from this ranking
+}
+
+// fusedExplanations collects the explanations of results being fused,
+// keyed by document ID
+type fusedExplanations map[int64]*Explanation
+
+// add records that a result contributed to its fused score from the ranking
+// it was found in
+func (e fusedExplanations) add(r SearchResult, contribution float64) {
+	if r.Explain == nil {
+		return
+	}
+	x, ok := e[r.ID]
+	if !ok {
+		x = &Explanation{}
+		e[r.ID] = x
+	}
+	if k := r.Explain.Keyword; k != nil {
+		keyword := *k
+		keyword.Contribution = contribution
+		x.Keyword = &keyword
+		x.Paths = append(x.Paths, PathKeyword)
+	}
+	if v := r.Explain.Vector; v != nil {
+		vector := *v
+		vector.Contribution = contribution
+		x.Vector = &vector
+		x.Paths = append(x.Paths, PathVector)
+	}
+}
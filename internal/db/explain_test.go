@@ -0,0 +1,104 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHybridSearchExplain(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs := []struct {
+		text      string
+		embedding []float32
+	}{
+		{"Article 15 - Right of access by the data subject", []float32{1.0, 0.0, 0.0}},
+		{"Article 17 - Right to erasure (right to be forgotten)", []float32{0.0, 1.0, 0.0}},
+	}
+	for i, d := range docs {
+		docID, err := database.InsertChunk(d.text, i)
+		if err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, GenerateTrigrams(d.text)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		if err := database.InsertEmbedding(docID, d.embedding); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+	}
+
+	results, err := database.HybridSearch("right of access", []float32{1.0, 0.0, 0.0}, 10, SearchOptions{Explain: true})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) == 0 || results[0].ID != 1 {
+		t.Fatalf("Expected Article 15 first, got %+v", results)
+	}
+
+	x := results[0].Explain
+	if x == nil {
+		t.Fatal("Expected an explanation")
+	}
+	if len(x.Paths) != 2 || x.Paths[0] != PathKeyword || x.Paths[1] != PathVector {
+		t.Errorf("Expected both paths, got %v", x.Paths)
+	}
+	if x.Fusion != FusionRRF || x.FusedScore != results[0].Score {
+		t.Errorf("Expected the RRF fused score %v, got %+v", results[0].Score, x)
+	}
+	if x.Keyword == nil || x.Keyword.Rank != 1 || x.Keyword.TrigramMatches == 0 ||
+		x.Keyword.TrigramMatches != x.Keyword.QueryTrigrams {
+		t.Errorf("Expected every query trigram matched at keyword rank 1, got %+v", x.Keyword)
+	}
+	if x.Vector == nil || x.Vector.Rank != 1 || math.Abs(x.Vector.Similarity-1) > 1e-6 {
+		t.Errorf("Expected vector rank 1 with similarity 1, got %+v", x.Vector)
+	}
+	if sum := x.Keyword.Contribution + x.Vector.Contribution; math.Abs(sum-x.FusedScore) > 1e-12 {
+		t.Errorf("Expected contributions to add up to %v, got %v", x.FusedScore, sum)
+	}
+
+	// Without the option there is nothing to explain
+	results, err = database.HybridSearch("right of access", []float32{1.0, 0.0, 0.0}, 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Explain != nil {
+			t.Errorf("Expected no explanation, got %+v", r.Explain)
+		}
+	}
+
+	// A keyword-only search is explained by the keyword ranking alone
+	results, err = database.HybridSearch("erasure", nil, 10, SearchOptions{Explain: true})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) == 0 || results[0].Explain == nil || results[0].Explain.Vector != nil ||
+		results[0].Explain.Fusion != "" || results[0].Explain.FusedScore != results[0].Score {
+		t.Errorf("Expected a keyword-only explanation, got %+v", results)
+	}
+}
+
+func TestRerankAndFuseQueriesExplain(t *testing.T) {
+	results := []SearchResult{
+		{ID: 1, Explain: &Explanation{Paths: []string{PathKeyword}}, chunk: "Right to erasure"},
+		{ID: 2, Explain: &Explanation{Paths: []string{PathKeyword}}, chunk: "Erasure"},
+	}
+
+	fused := FuseQueries([][]SearchResult{results, results[1:]})
+	if fused[0].ID != 2 || fused[0].Explain.Queries != 2 || fused[1].Explain.Queries != 1 {
+		t.Errorf("Expected the number of formulations finding each result, got %+v %+v", fused[0].Explain, fused[1].Explain)
+	}
+	if results[1].Explain.Queries != 0 {
+		t.Error("Expected the input explanations to be left alone")
+	}
+
+	reranked, err := Rerank(lengthReranker{}, "query", results)
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if rs := reranked[0].Explain.RerankScore; rs == nil || *rs != reranked[0].Score {
+		t.Errorf("Expected the rerank score in the explanation, got %+v", reranked[0].Explain)
+	}
+}
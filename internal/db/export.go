@@ -0,0 +1,152 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Export formats
+const (
+	ExportJSONLFormat    = "jsonl" // records of ExportJSONL, which ImportJSONL reads back
+	ExportJSONFormat     = "json"  // a JSON array of the live chunks
+	ExportMarkdownFormat = "md"    // the live chunks as Markdown, grouped by source, for review and diffing
+)
+
+// ExportOptions selects the format and the chunks of an export
+type ExportOptions struct {
+	Format     string // ExportJSONLFormat (default), ExportJSONFormat or ExportMarkdownFormat
+	Collection string // only export chunks of this collection
+	Article    string // only export chunks of this article, e.g. "17" or "Article 17"
+}
+
+// ExportedChunk is a chunk as written by a JSON export
+type ExportedChunk struct {
+	ID         int64             `json:"id"`
+	Chunk      string            `json:"chunk"`
+	ChunkIndex int               `json:"chunk_index"`
+	Language   string            `json:"language,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	Collection string            `json:"collection,omitempty"`
+}
+
+// conditions returns SQL conditions (and their arguments) selecting the
+// exported chunks from the documents table aliased as d
+func (opts ExportOptions) conditions() (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	if opts.Collection != "" {
+		sb.WriteString(" AND d.collection = ?")
+		args = append(args, opts.Collection)
+	}
+	if opts.Article != "" {
+		article, ok := ArticleNumber(opts.Article)
+		if !ok {
+			article = opts.Article
+		}
+		sb.WriteString(" AND json_extract(d.metadata, '$.article') = ?")
+		args = append(args, article)
+	}
+	return sb.String(), args
+}
+
+// Export writes the corpus, or the collection or article selected by opts,
+// to w. JSON Lines exports carry embeddings and soft-deleted chunks so they
+// can be imported again; JSON and Markdown exports hold the live chunk text
+// and metadata only.
+func (db *DB) Export(w io.Writer, opts ExportOptions) error {
+	switch opts.Format {
+	case "", ExportJSONLFormat:
+		return db.exportJSONL(w, opts)
+	case ExportJSONFormat, ExportMarkdownFormat:
+	default:
+		return fmt.Errorf("unknown export format %q", opts.Format)
+	}
+
+	chunks, err := db.exportedChunks(opts)
+	if err != nil {
+		return err
+	}
+	if opts.Format == ExportJSONFormat {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(chunks); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		return nil
+	}
+	return writeMarkdown(w, chunks)
+}
+
+// exportedChunks returns the live chunks selected by opts in reading order
+func (db *DB) exportedChunks(opts ExportOptions) ([]ExportedChunk, error) {
+	filter, args := opts.conditions()
+	rows, err := db.conn.Query(`
+		SELECT d.id, d.chunk, d.chunk_index, d.language, d.metadata, d.source, d.collection
+		FROM documents d
+		WHERE d.deleted_at IS NULL`+filter+`
+		ORDER BY d.collection, d.source, d.chunk_index, d.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := []ExportedChunk{}
+	for rows.Next() {
+		var c ExportedChunk
+		var metadata string
+		if err := rows.Scan(&c.ID, &c.Chunk, &c.ChunkIndex, &c.Language, &metadata, &c.Source, &c.Collection); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		if c.Metadata, err = decodeMetadata(metadata); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
+
+// writeMarkdown writes chunks under a heading per source and collection.
+// Chunk IDs and metadata go in HTML comments, so the rendered document
+// reads as the text itself while a diff still shows which chunk changed.
+func writeMarkdown(w io.Writer, chunks []ExportedChunk) error {
+	var sb strings.Builder
+	for i, c := range chunks {
+		if i == 0 || c.Source != chunks[i-1].Source || c.Collection != chunks[i-1].Collection {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			heading := c.Source
+			if heading == "" {
+				heading = "(no source)"
+			}
+			if c.Collection != "" {
+				heading += " (collection " + c.Collection + ")"
+			}
+			sb.WriteString("# " + heading + "\n\n")
+		}
+
+		fmt.Fprintf(&sb, "<!-- chunk %d, index %d", c.ID, c.ChunkIndex)
+		if c.Language != "" {
+			sb.WriteString(", language " + c.Language)
+		}
+		keys := make([]string, 0, len(c.Metadata))
+		for key := range c.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			// "--" would end the comment early
+			fmt.Fprintf(&sb, ", %s=%s", key, strings.ReplaceAll(c.Metadata[key], "--", "- -"))
+		}
+		sb.WriteString(" -->\n\n")
+		sb.WriteString(strings.TrimSpace(c.Chunk) + "\n\n")
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs := []Document{
+		{Chunk: "Article 17 Right to erasure", Source: "gdpr.txt", Collection: "gdpr-en", Metadata: map[string]string{"article": "17"}},
+		{Chunk: "Article 18 Right to restriction", Source: "gdpr.txt", Collection: "gdpr-en", ChunkIndex: 1, Metadata: map[string]string{"article": "18"}},
+		{Chunk: "Guidelines on consent", Source: "edpb.pdf", Collection: "edpb"},
+	}
+	for _, d := range docs {
+		if _, err := database.InsertDocument(d); err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := database.Export(&buf, ExportOptions{Format: ExportJSONFormat, Collection: "gdpr-en"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var chunks []ExportedChunk
+	if err := json.Unmarshal(buf.Bytes(), &chunks); err != nil {
+		t.Fatalf("Invalid JSON export: %v\n%s", err, buf.String())
+	}
+	if len(chunks) != 2 || chunks[0].Chunk != docs[0].Chunk || chunks[1].Metadata["article"] != "18" {
+		t.Errorf("Expected the two chunks of the collection in order, got %+v", chunks)
+	}
+
+	buf.Reset()
+	if err := database.Export(&buf, ExportOptions{Format: ExportMarkdownFormat, Article: "Article 17"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	md := buf.String()
+	if !strings.HasPrefix(md, "# gdpr.txt (collection gdpr-en)\n") || !strings.Contains(md, "article=17 -->") || !strings.Contains(md, "Right to erasure") {
+		t.Errorf("Unexpected Markdown export:\n%s", md)
+	}
+	if strings.Contains(md, "Right to restriction") || strings.Contains(md, "consent") {
+		t.Errorf("Expected only Article 17 in the export:\n%s", md)
+	}
+
+	// Checkpoints are left out, as the export lacks the other chunks
+	if err := database.SaveCheckpoint(Checkpoint{Source: "gdpr.txt", Fingerprint: "abc:def", NextChunk: 2, TotalChunks: 2}); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+	buf.Reset()
+	if err := database.Export(&buf, ExportOptions{Collection: "edpb"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 {
+		t.Errorf("Expected a header and one chunk in the JSON Lines export, got:\n%s", buf.String())
+	}
+
+	if err := database.Export(&buf, ExportOptions{Format: "xml"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
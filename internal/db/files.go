@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SourceFile records the state of an ingested file when it was ingested
+type SourceFile struct {
+	Source     string
+	Hash       string // SHA-256 of the content, hex encoded
+	Size       int64
+	ModTime    time.Time
+	IngestedAt time.Time
+}
+
+// GetSourceFile returns the record of an ingested file, or nil if the file
+// has not been ingested
+func (db *DB) GetSourceFile(source string) (*SourceFile, error) {
+	var f SourceFile
+	err := db.conn.QueryRow(
+		"SELECT source, hash, size, mod_time, ingested_at FROM source_files WHERE source = ?",
+		source,
+	).Scan(&f.Source, &f.Hash, &f.Size, &f.ModTime, &f.IngestedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source file: %w", err)
+	}
+	return &f, nil
+}
+
+// SaveSourceFile records the state of an ingested file
+func (db *DB) SaveSourceFile(f SourceFile) error {
+	_, err := db.conn.Exec(
+		"INSERT OR REPLACE INTO source_files (source, hash, size, mod_time, ingested_at) VALUES (?, ?, ?, ?, ?)",
+		f.Source, f.Hash, f.Size, f.ModTime.UTC(), time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save source file: %w", err)
+	}
+	return nil
+}
+
+// SourceFiles returns the records of all ingested files in source order
+func (db *DB) SourceFiles() ([]SourceFile, error) {
+	rows, err := db.conn.Query("SELECT source, hash, size, mod_time, ingested_at FROM source_files ORDER BY source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []SourceFile
+	for rows.Next() {
+		var f SourceFile
+		if err := rows.Scan(&f.Source, &f.Hash, &f.Size, &f.ModTime, &f.IngestedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan source file: %w", err)
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// RemoveSource deletes every chunk of a source along with its checkpoint
+// and file record. It returns the number of chunks removed.
+func (db *DB) RemoveSource(source string) (int, error) {
+	removed, err := db.DeleteSourceChunks(source, 0)
+	if err != nil {
+		return 0, err
+	}
+	if err := db.DeleteCheckpoint(source); err != nil {
+		return removed, err
+	}
+	if _, err := db.conn.Exec("DELETE FROM source_files WHERE source = ?", source); err != nil {
+		return removed, fmt.Errorf("failed to delete source file: %w", err)
+	}
+	return removed, nil
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSourceFiles(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	f, err := database.GetSourceFile("/docs/gdpr.txt")
+	if err != nil || f != nil {
+		t.Fatalf("GetSourceFile = %v, %v, want nothing", f, err)
+	}
+
+	modTime := time.Date(2024, 5, 25, 10, 30, 0, 123456789, time.FixedZone("CEST", 2*3600))
+	for _, source := range []string{"/docs/gdpr.txt", "/docs/edpb.md"} {
+		if err := database.SaveSourceFile(SourceFile{Source: source, Hash: "abc", Size: 42, ModTime: modTime}); err != nil {
+			t.Fatalf("SaveSourceFile failed: %v", err)
+		}
+		if _, err := database.InsertDocument(Document{Chunk: "Right to erasure", Source: source}); err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+	}
+	if err := database.SaveCheckpoint(Checkpoint{Source: "/docs/gdpr.txt", Fingerprint: "abc", NextChunk: 1, TotalChunks: 1}); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+
+	f, err = database.GetSourceFile("/docs/gdpr.txt")
+	if err != nil || f == nil {
+		t.Fatalf("GetSourceFile = %v, %v", f, err)
+	}
+	if f.Hash != "abc" || f.Size != 42 || !f.ModTime.Equal(modTime) {
+		t.Errorf("Unexpected source file %+v", f)
+	}
+
+	removed, err := database.RemoveSource("/docs/gdpr.txt")
+	if err != nil {
+		t.Fatalf("RemoveSource failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 chunk removed, got %d", removed)
+	}
+	if cp, _ := database.GetCheckpoint("/docs/gdpr.txt"); cp != nil {
+		t.Errorf("Expected the checkpoint to be removed, got %+v", cp)
+	}
+
+	files, err := database.SourceFiles()
+	if err != nil {
+		t.Fatalf("SourceFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Source != "/docs/edpb.md" {
+		t.Errorf("Expected only the remaining file, got %+v", files)
+	}
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultRRFK is the reciprocal rank fusion constant used when none is set
+const DefaultRRFK = 60
+
+// Fusion methods selectable through Fusion.Method
+const (
+	// FusionRRF scores each result weight / (K + rank) in every ranking it
+	// appears in, ignoring the scores themselves
+	FusionRRF = "rrf"
+	// FusionLinear min-max normalizes the keyword and vector scores and
+	// averages them by weight, keeping how far apart results score
+	FusionLinear = "linear"
+)
+
+// Fusion controls how HybridSearch combines the keyword and vector rankings.
+// The zero value fuses by reciprocal rank with DefaultRRFK and weighs both
+// rankings equally.
+type Fusion struct {
+	Method        string  // FusionRRF (default) or FusionLinear
+	K             float64 // RRF only: larger values flatten the difference between ranks; 0 uses DefaultRRFK
+	KeywordWeight float64 // weight of the trigram ranking
+	VectorWeight  float64 // weight of the vector ranking; when both weights are 0 they count equally
+}
+
+// Validate reports parameters HybridSearch cannot use
+func (f Fusion) Validate() error {
+	switch f.Method {
+	case "", FusionRRF, FusionLinear:
+	default:
+		return fmt.Errorf("unknown fusion method %q (available: %s, %s)", f.Method, FusionRRF, FusionLinear)
+	}
+	if f.K < 0 {
+		return fmt.Errorf("RRF k must not be negative, got %v", f.K)
+	}
+	if f.KeywordWeight < 0 || f.VectorWeight < 0 {
+		return fmt.Errorf("fusion weights must not be negative")
+	}
+	return nil
+}
+
+// WithDefaults fills in the defaults for unset parameters
+func (f Fusion) WithDefaults() Fusion {
+	if f.Method == "" {
+		f.Method = FusionRRF
+	}
+	if f.K == 0 {
+		f.K = DefaultRRFK
+	}
+	if f.KeywordWeight == 0 && f.VectorWeight == 0 {
+		f.KeywordWeight, f.VectorWeight = 1, 1
+	}
+	return f
+}
+
+// WithAlpha sets the weights from alpha, the share of the vector ranking:
+// 0 ranks by keywords alone, 1 by vectors alone and 0.5 weighs both equally
+func (f Fusion) WithAlpha(alpha float64) (Fusion, error) {
+	if alpha < 0 || alpha > 1 {
+		return f, fmt.Errorf("alpha must be between 0 and 1, got %v", alpha)
+	}
+	f.KeywordWeight, f.VectorWeight = 1-alpha, alpha
+	return f, nil
+}
+
+// fuse merges the keyword and vector results with the configured method
+func (f Fusion) fuse(keyword, vector []SearchResult) []SearchResult {
+	var fused []SearchResult
+	if f.Method == FusionLinear {
+		fused = f.linear(keyword, vector)
+	} else {
+		fused = f.rrf(keyword, vector)
+	}
+	for i := range fused {
+		if x := fused[i].Explain; x != nil {
+			x.Fusion = f.Method
+			x.FusedScore = fused[i].Score
+		}
+	}
+	return fused
+}
+
+// rrf merges the keyword and vector rankings by weighted reciprocal rank
+func (f Fusion) rrf(keyword, vector []SearchResult) []SearchResult {
+	scores := make(map[int64]float64)
+	found := make(map[int64]SearchResult)
+	explanations := make(fusedExplanations)
+
+	add := func(results []SearchResult, weight float64) {
+		for i, r := range results {
+			contribution := weight / (f.K + float64(i+1))
+			scores[r.ID] += contribution
+			found[r.ID] = r
+			explanations.add(r, contribution)
+		}
+	}
+	add(keyword, f.KeywordWeight)
+	add(vector, f.VectorWeight)
+
+	return sortFused(scores, found, explanations)
+}
+
+// linear merges the keyword and vector results by the weighted average of
+// their min-max normalized scores, so fused scores lie between 0 and 1. A
+// result missing from one list scores 0 there.
+func (f Fusion) linear(keyword, vector []SearchResult) []SearchResult {
+	scores := make(map[int64]float64)
+	found := make(map[int64]SearchResult)
+	explanations := make(fusedExplanations)
+	total := f.KeywordWeight + f.VectorWeight
+
+	add := func(results []SearchResult, weight float64) {
+		if len(results) == 0 {
+			return
+		}
+		lo, hi := results[0].Score, results[0].Score
+		for _, r := range results {
+			if r.Score < lo {
+				lo = r.Score
+			}
+			if r.Score > hi {
+				hi = r.Score
+			}
+		}
+		for _, r := range results {
+			// Equal scores carry no ranking information; count them all fully
+			normalized := 1.0
+			if hi > lo {
+				normalized = (r.Score - lo) / (hi - lo)
+			}
+			contribution := weight / total * normalized
+			scores[r.ID] += contribution
+			found[r.ID] = r
+			explanations.add(r, contribution)
+		}
+	}
+	add(keyword, f.KeywordWeight)
+	add(vector, f.VectorWeight)
+
+	return sortFused(scores, found, explanations)
+}
+
+// sortFused turns fused scores into results, best first
+func sortFused(scores map[int64]float64, found map[int64]SearchResult, explanations fusedExplanations) []SearchResult {
+	fused := make([]SearchResult, 0, len(scores))
+	for id, score := range scores {
+		r := found[id]
+		fused = append(fused, SearchResult{ID: id, Score: score, Collection: r.Collection, Explain: explanations[id], chunk: r.chunk})
+	}
+	sortResults(fused)
+	return fused
+}
+
+// sortResults sorts results best first, breaking ties by ascending ID so
+// the same search always returns the same order
+func sortResults(results []SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+}
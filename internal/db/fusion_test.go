@@ -0,0 +1,163 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFusionRRF(t *testing.T) {
+	keyword := []SearchResult{{ID: 1}, {ID: 2}}
+	vector := []SearchResult{{ID: 2}, {ID: 3}}
+
+	fused := Fusion{}.WithDefaults().rrf(keyword, vector)
+	if len(fused) != 3 || fused[0].ID != 2 {
+		t.Fatalf("Expected the result found by both rankings first, got %+v", fused)
+	}
+	if want := 1.0/62 + 1.0/61; math.Abs(fused[0].Score-want) > 1e-12 {
+		t.Errorf("Expected score %v with k=60, got %v", want, fused[0].Score)
+	}
+
+	// A heavier keyword ranking puts its top result first
+	fused = Fusion{K: 1, KeywordWeight: 3, VectorWeight: 1}.WithDefaults().rrf(keyword, vector)
+	if fused[0].ID != 1 {
+		t.Errorf("Expected the top keyword result first, got %+v", fused)
+	}
+	if want := 3.0 / 2; fused[0].Score != want {
+		t.Errorf("Expected score %v, got %v", want, fused[0].Score)
+	}
+}
+
+func TestFusionLinear(t *testing.T) {
+	keyword := []SearchResult{{ID: 1, Score: 0.9}, {ID: 2, Score: 0.5}, {ID: 3, Score: 0.1}}
+	vector := []SearchResult{{ID: 3, Score: 0.80}, {ID: 2, Score: 0.79}, {ID: 1, Score: 0.40}}
+
+	// Result 2 is a close second by vector, so it beats 1 and 3, which are
+	// each last in one ranking; RRF would tie all three
+	fused := Fusion{Method: FusionLinear}.WithDefaults().fuse(keyword, vector)
+	if len(fused) != 3 || fused[0].ID != 2 {
+		t.Fatalf("Expected result 2 first, got %+v", fused)
+	}
+	for id, want := range map[int64]float64{1: 0.5, 2: (0.5 + 0.975) / 2, 3: 0.5} {
+		for _, r := range fused {
+			if r.ID == id && math.Abs(r.Score-want) > 1e-9 {
+				t.Errorf("Expected result %d to score %v, got %v", id, want, r.Score)
+			}
+		}
+	}
+
+	// Alpha shifts the balance; a result in one list only scores 0 in the other
+	f, err := Fusion{Method: FusionLinear}.WithAlpha(0.8)
+	if err != nil {
+		t.Fatalf("WithAlpha failed: %v", err)
+	}
+	fused = f.fuse(keyword, vector[:1])
+	if fused[0].ID != 3 || math.Abs(fused[0].Score-0.8) > 1e-9 {
+		t.Errorf("Expected result 3 first with score 0.8, got %+v", fused)
+	}
+	if _, err := (Fusion{}).WithAlpha(1.2); err == nil {
+		t.Error("Expected alpha above 1 to be rejected")
+	}
+}
+
+func TestFusionValidate(t *testing.T) {
+	for _, f := range []Fusion{{K: -1}, {KeywordWeight: -1}, {VectorWeight: -0.5}, {Method: "borda"}} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", f)
+		}
+	}
+	if err := (Fusion{K: 10, VectorWeight: 2}).Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHybridSearchFusionWeights(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// The first chunk matches the query words, the second its embedding
+	var ids []int64
+	for _, d := range []struct {
+		text      string
+		embedding []float32
+	}{
+		{"Right to erasure of personal data", []float32{0, 1}},
+		{"Deletion of records held by the controller", []float32{1, 0}},
+	} {
+		id, err := database.InsertChunk(d.text, 0)
+		if err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(d.text)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		if err := database.InsertEmbedding(id, d.embedding); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	search := func(f Fusion) []SearchResult {
+		t.Helper()
+		results, err := database.HybridSearch("right to erasure", []float32{1, 0}, 10, SearchOptions{Fusion: f})
+		if err != nil {
+			t.Fatalf("HybridSearch failed: %v", err)
+		}
+		return results
+	}
+
+	if results := search(Fusion{K: 1, KeywordWeight: 2, VectorWeight: 1}); results[0].ID != ids[0] {
+		t.Errorf("Expected the keyword match first, got %+v", results)
+	}
+	if results := search(Fusion{K: 1, KeywordWeight: 1, VectorWeight: 4}); results[0].ID != ids[1] {
+		t.Errorf("Expected the vector match first, got %+v", results)
+	}
+
+	// A zero weight leaves that ranking out
+	results := search(Fusion{VectorWeight: 1})
+	if len(results) != 2 || results[0].ID != ids[1] {
+		t.Errorf("Expected the vector ranking alone, got %+v", results)
+	}
+
+	if _, err := database.HybridSearch("erasure", []float32{1, 0}, 10, SearchOptions{Fusion: Fusion{K: -1}}); err == nil {
+		t.Error("Expected invalid fusion parameters to be rejected")
+	}
+}
+
+func TestFusionBreaksTiesByID(t *testing.T) {
+	// Both results score the same in either fusion; map order must not matter
+	keyword := []SearchResult{{ID: 7, Score: 1}, {ID: 3, Score: 0}}
+	vector := []SearchResult{{ID: 3, Score: 1}, {ID: 7, Score: 0}}
+	for _, method := range []string{FusionRRF, FusionLinear} {
+		for i := 0; i < 20; i++ {
+			fused := Fusion{Method: method}.WithDefaults().fuse(keyword, vector)
+			if len(fused) != 2 || fused[0].ID != 3 || fused[1].ID != 7 {
+				t.Fatalf("Expected %s ties in ID order, got %+v", method, fused)
+			}
+		}
+	}
+}
+
+func TestSearchVectorsBreaksTiesByID(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < 5; i++ {
+		id, err := database.InsertChunk("Right to erasure", i)
+		if err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+		if err := database.InsertEmbedding(id, []float32{1, 0, 0}); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+	}
+
+	results, err := database.SearchVectors([]float32{1, 0, 0}, 5, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchVectors failed: %v", err)
+	}
+	for i, r := range results {
+		if r.ID != int64(i+1) {
+			t.Fatalf("Expected equally similar chunks in ID order, got %+v", results)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kinds of heading lines that delimit provisions in regulation text
+const (
+	headingArticle  = "article"  // "Article 17", "## Article 17 – Right to erasure"
+	headingRecital  = "recital"  // "Recital 26"
+	headingNumbered = "numbered" // "(26) The principles ...", a recital before the articles
+	headingDivision = "division" // "CHAPTER III", "Section 2"
+	headingAnnex    = "annex"    // "ANNEX", "Annex II"
+	headingUpper    = "upper"    // "HAVE ADOPTED THIS REGULATION:"
+)
+
+var (
+	// An article heading with an optional short title, but not a sentence
+	// starting with an article reference
+	articleHeadingRe  = regexp.MustCompile(`^#*\s*Article\s+(\d+)(?:\s*[-–—:]?\s+[^.;:]{1,100})?$`)
+	recitalHeadingRe  = regexp.MustCompile(`^#*\s*Recital\s+\(?(\d+)\)?\s*$`)
+	numberedHeadingRe = regexp.MustCompile(`^\((\d+)\)(?:\s|$)`)
+	divisionHeadingRe = regexp.MustCompile(`^#*\s*(?:CHAPTER|Chapter|SECTION|Section)\s+(?:[IVXLC]+|\d+)\b`)
+	annexHeadingRe    = regexp.MustCompile(`^#*\s*(?:ANNEX|Annex)(?:\s+(?:[IVXLC]+|\d+))?\s*$`)
+)
+
+// headingKind classifies a line as a provision heading, returning its kind
+// and, for articles and recitals, its number
+func headingKind(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if m := articleHeadingRe.FindStringSubmatch(line); m != nil {
+		return headingArticle, m[1]
+	}
+	if m := recitalHeadingRe.FindStringSubmatch(line); m != nil {
+		return headingRecital, m[1]
+	}
+	if m := numberedHeadingRe.FindStringSubmatch(line); m != nil {
+		return headingNumbered, m[1]
+	}
+	if divisionHeadingRe.MatchString(line) {
+		return headingDivision, ""
+	}
+	if annexHeadingRe.MatchString(line) {
+		return headingAnnex, ""
+	}
+	if isUpperLine(line) {
+		return headingUpper, ""
+	}
+	return "", ""
+}
+
+// endsProvision reports whether a heading of kind ends a provision of the
+// given metadata key. Numbered points and capitals only end recitals, as
+// articles have numbered definitions and may quote capitalised text.
+func endsProvision(key, kind string) bool {
+	switch kind {
+	case "":
+		return false
+	case headingNumbered, headingUpper:
+		return key == "recital"
+	}
+	return true
+}
+
+// startsProvision reports whether a heading of kind and number opens the
+// provision whose metadata key has value
+func startsProvision(key, value, kind, number string) bool {
+	switch key {
+	case "article":
+		return kind == headingArticle && number == value
+	case "recital":
+		return (kind == headingRecital || kind == headingNumbered) && number == value
+	}
+	return false
+}
+
+// provisionBounds returns the span of text from the heading of the
+// provision whose metadata key has value up to the next heading ending it.
+// It reports false when the heading is not in text.
+func provisionBounds(text, key, value string) (int, int, bool) {
+	for offset := 0; offset < len(text); {
+		line, next := lineAt(text, offset)
+		if kind, number := headingKind(line); startsProvision(key, value, kind, number) {
+			return offset, provisionEnd(text, key, value, next), true
+		}
+		offset = next
+	}
+	return 0, 0, false
+}
+
+// provisionEnd returns the offset of the first line at or after offset
+// that ends the provision whose metadata key has value, or the length of
+// text. Its own heading, repeated by chunks joined without detecting their
+// overlap, does not end it.
+func provisionEnd(text, key, value string, offset int) int {
+	for offset < len(text) {
+		line, next := lineAt(text, offset)
+		if kind, number := headingKind(line); endsProvision(key, kind) && !startsProvision(key, value, kind, number) {
+			return offset
+		}
+		offset = next
+	}
+	return len(text)
+}
+
+// lineAt returns the line of text starting at offset and the offset of the
+// line after it
+func lineAt(text string, offset int) (string, int) {
+	end := strings.IndexByte(text[offset:], '\n')
+	if end < 0 {
+		return text[offset:], len(text)
+	}
+	return text[offset : offset+end], offset + end + 1
+}
+
+// isUpperLine reports whether line has letters and all of them are upper case
+func isUpperLine(line string) bool {
+	hasLetter := false
+	for _, r := range line {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"sort"
+	"strings"
+)
+
+// Highlight is a span of a snippet matching the query, in characters
+// (Unicode code points) from the start of the snippet
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// findHighlights returns the spans of text where query words match, merged
+// and in order. A query word matches at the start of a word of text and the
+// highlight extends to the end of that word, so "erasure" marks "erasures"
+// but not part of an unrelated longer word.
+func findHighlights(text string, words []string) []Highlight {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Case folding changed the length; fall back to matching as-is
+		lower = runes
+	}
+
+	var spans []Highlight
+	for _, w := range words {
+		wr := []rune(w)
+		for i := 0; i+len(wr) <= len(lower); i++ {
+			if (i > 0 && !isNotWordRune(lower[i-1])) || string(lower[i:i+len(wr)]) != w {
+				continue
+			}
+			end := i + len(wr)
+			for end < len(lower) && !isNotWordRune(lower[end]) {
+				end++
+			}
+			spans = append(spans, Highlight{Start: i, End: end})
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Start < spans[j].Start
+	})
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.Start <= last.End {
+			if s.End > last.End {
+				last.End = s.End
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// markHighlights wraps each highlighted span of text in pre and post
+func markHighlights(text string, highlights []Highlight, pre, post string) string {
+	runes := []rune(text)
+	var sb strings.Builder
+	last := 0
+	for _, h := range highlights {
+		sb.WriteString(string(runes[last:h.Start]))
+		sb.WriteString(pre)
+		sb.WriteString(string(runes[h.Start:h.End]))
+		sb.WriteString(post)
+		last = h.End
+	}
+	sb.WriteString(string(runes[last:]))
+	return sb.String()
+}
+
+// annotate sets a result's snippet for query and, when asked for, the
+// highlights of the query words in it
+func (opts SearchOptions) annotate(r *SearchResult, query string) {
+	r.Snippet = opts.snippet(r.chunk, query)
+	r.Highlights = nil
+	if !opts.Highlight && opts.HighlightPre == "" && opts.HighlightPost == "" {
+		return
+	}
+
+	highlights := findHighlights(r.Snippet, queryWords(query))
+	if opts.HighlightPre != "" || opts.HighlightPost != "" {
+		r.Snippet = markHighlights(r.Snippet, highlights, opts.HighlightPre, opts.HighlightPost)
+		return
+	}
+	r.Highlights = highlights
+}
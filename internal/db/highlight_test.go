@@ -0,0 +1,57 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindHighlights(t *testing.T) {
+	text := "Erasures: the data subject's right to erasure, in particular of personal data."
+	got := findHighlights(text, queryWords("erasure art data"))
+	want := []Highlight{{0, 8}, {14, 18}, {38, 45}, {73, 77}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findHighlights = %v, want %v", got, want)
+	}
+
+	if got := findHighlights("Löschung der Daten", []string{"löschung"}); !reflect.DeepEqual(got, []Highlight{{0, 8}}) {
+		t.Errorf("Expected offsets in characters, got %v", got)
+	}
+	if got := findHighlights(text, nil); got != nil {
+		t.Errorf("Expected no highlights without query words, got %v", got)
+	}
+}
+
+func TestSearchHighlight(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chunk := "The right to erasure applies to personal data."
+	id, err := database.InsertChunk(chunk, 0)
+	if err != nil {
+		t.Fatalf("Failed to insert chunk: %v", err)
+	}
+	if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+		t.Fatalf("Failed to insert trigrams: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("erasure data", 10, SearchOptions{Highlight: true})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("SearchTrigrams = %+v, %v", results, err)
+	}
+	if want := []Highlight{{13, 20}, {41, 45}}; !reflect.DeepEqual(results[0].Highlights, want) || results[0].Snippet != chunk {
+		t.Errorf("Expected highlights %v in the plain snippet, got %+v", want, results[0])
+	}
+
+	results, err = database.SearchTrigrams("erasure data", 10, SearchOptions{HighlightPre: "<em>", HighlightPost: "</em>"})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("SearchTrigrams = %+v, %v", results, err)
+	}
+	if want := "The right to <em>erasure</em> applies to personal <em>data</em>."; results[0].Snippet != want || results[0].Highlights != nil {
+		t.Errorf("Expected the marked-up snippet %q, got %+v", want, results[0])
+	}
+
+	results, err = database.SearchTrigrams("erasure data", 10, SearchOptions{})
+	if err != nil || len(results) != 1 || results[0].Highlights != nil {
+		t.Errorf("Expected no highlights unless requested, got %+v, %v", results, err)
+	}
+}
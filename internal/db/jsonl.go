@@ -0,0 +1,313 @@
+package db
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JSONLFormat identifies corpus exports written by ExportJSONL
+const JSONLFormat = "gdpr-mcp-jsonl"
+
+// JSONLVersion is the version of the export record layout
+const JSONLVersion = 2
+
+// jsonlRecord is one line of a JSONL corpus export. The Type field selects
+// which of the other fields are meaningful.
+type jsonlRecord struct {
+	Type string `json:"type"` // "header", "metadata", "chunk", "checkpoint" or "source_file"
+
+	// header
+	Format  string `json:"format,omitempty"`
+	Version int    `json:"version,omitempty"`
+
+	// metadata
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+
+	// chunk
+	ID         int64             `json:"id,omitempty"`
+	Chunk      string            `json:"chunk,omitempty"`
+	ChunkIndex int               `json:"chunk_index,omitempty"`
+	Language   string            `json:"language,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	Collection string            `json:"collection,omitempty"`
+	DeletedAt  *time.Time        `json:"deleted_at,omitempty"`
+	Embedding  string            `json:"embedding,omitempty"` // base64 little-endian float32
+
+	// checkpoint, with Source
+	Fingerprint string     `json:"fingerprint,omitempty"`
+	NextChunk   int        `json:"next_chunk,omitempty"`
+	TotalChunks int        `json:"total_chunks,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+
+	// source_file, with Source
+	Hash       string     `json:"hash,omitempty"`
+	Size       int64      `json:"size,omitempty"`
+	ModTime    *time.Time `json:"mod_time,omitempty"`
+	IngestedAt *time.Time `json:"ingested_at,omitempty"`
+}
+
+// ExportJSONL writes the corpus (metadata, chunks and embeddings, and the
+// checkpoints and file records that spare a restored database from
+// ingesting its sources again) to w as JSON Lines, one record per line,
+// starting with a format header
+func (db *DB) ExportJSONL(w io.Writer) error {
+	return db.exportJSONL(w, ExportOptions{})
+}
+
+// exportJSONL writes the metadata and the chunks selected by opts, soft
+// deleted ones included, as JSON Lines. Checkpoints and file records are
+// only written with the whole corpus, as they would mark sources whose
+// chunks were left out as ingested.
+func (db *DB) exportJSONL(w io.Writer, opts ExportOptions) error {
+	enc := json.NewEncoder(w)
+
+	if err := enc.Encode(jsonlRecord{Type: "header", Format: JSONLFormat, Version: JSONLVersion}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	rows, err := db.conn.Query("SELECT key, value FROM metadata ORDER BY key")
+	if err != nil {
+		return fmt.Errorf("failed to query metadata: %w", err)
+	}
+	for rows.Next() {
+		rec := jsonlRecord{Type: "metadata"}
+		if err := rows.Scan(&rec.Key, &rec.Value); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan metadata: %w", err)
+		}
+		if err := enc.Encode(rec); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to write metadata: %w", err)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	filter, args := opts.conditions()
+	rows, err = db.conn.Query(`
+		SELECT d.id, d.chunk, d.chunk_index, d.language, d.metadata, d.source, d.collection, d.deleted_at, e.embedding
+		FROM documents d
+		LEFT JOIN embeddings e ON e.doc_id = d.id
+		WHERE 1 = 1`+filter+`
+		ORDER BY d.id
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rec := jsonlRecord{Type: "chunk"}
+		var metadata string
+		var deletedAt sql.NullTime
+		var embedding []byte
+		if err := rows.Scan(&rec.ID, &rec.Chunk, &rec.ChunkIndex, &rec.Language, &metadata, &rec.Source, &rec.Collection, &deletedAt, &embedding); err != nil {
+			return fmt.Errorf("failed to scan document: %w", err)
+		}
+		if rec.Metadata, err = decodeMetadata(metadata); err != nil {
+			return err
+		}
+		if deletedAt.Valid {
+			rec.DeletedAt = &deletedAt.Time
+		}
+		if len(embedding) > 0 {
+			rec.Embedding = base64.StdEncoding.EncodeToString(embedding)
+		}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write document %d: %w", rec.ID, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if filter != "" {
+		return nil
+	}
+	return db.exportIngestState(enc)
+}
+
+// exportIngestState writes the checkpoint and file record of every
+// ingested source
+func (db *DB) exportIngestState(enc *json.Encoder) error {
+	rows, err := db.conn.Query("SELECT source, fingerprint, next_chunk, total_chunks, updated_at FROM checkpoints ORDER BY source")
+	if err != nil {
+		return fmt.Errorf("failed to query checkpoints: %w", err)
+	}
+	for rows.Next() {
+		rec := jsonlRecord{Type: "checkpoint"}
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&rec.Source, &rec.Fingerprint, &rec.NextChunk, &rec.TotalChunks, &updatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan checkpoint: %w", err)
+		}
+		if updatedAt.Valid {
+			rec.UpdatedAt = &updatedAt.Time
+		}
+		if err := enc.Encode(rec); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	files, err := db.SourceFiles()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		rec := jsonlRecord{Type: "source_file", Source: f.Source, Hash: f.Hash, Size: f.Size, ModTime: &f.ModTime, IngestedAt: &f.IngestedAt}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to write source file: %w", err)
+		}
+	}
+	return nil
+}
+
+// ImportJSONL loads a corpus written by ExportJSONL in a single transaction.
+// Document IDs are preserved and trigrams are rebuilt from the chunk text,
+// so embeddings never have to be recomputed. Chunks without an ID are
+// rejected. It returns the number of
+// chunks imported.
+func (db *DB) ImportJSONL(r io.Reader) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	imported := 0
+	line := 0
+	postings := make(postingBatch)
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec jsonlRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return 0, fmt.Errorf("line %d: invalid record: %w", line, err)
+		}
+
+		switch rec.Type {
+		case "header":
+			if rec.Format != JSONLFormat {
+				return 0, fmt.Errorf("line %d: unsupported format %q", line, rec.Format)
+			}
+			if rec.Version > JSONLVersion {
+				return 0, fmt.Errorf("line %d: unsupported version %d", line, rec.Version)
+			}
+		case "metadata":
+			if _, err := tx.Exec(
+				"INSERT OR REPLACE INTO metadata (key, value) VALUES (?, ?)",
+				rec.Key, rec.Value,
+			); err != nil {
+				return 0, fmt.Errorf("line %d: failed to import metadata: %w", line, err)
+			}
+		case "chunk":
+			if rec.ID <= 0 {
+				return 0, fmt.Errorf("line %d: chunk without an id", line)
+			}
+			if err := importChunk(tx, rec, postings); err != nil {
+				return 0, fmt.Errorf("line %d: %w", line, err)
+			}
+			imported++
+		case "checkpoint":
+			if _, err := tx.Exec(
+				"INSERT OR REPLACE INTO checkpoints (source, fingerprint, next_chunk, total_chunks, updated_at) VALUES (?, ?, ?, ?, ?)",
+				rec.Source, rec.Fingerprint, rec.NextChunk, rec.TotalChunks, utcTime(rec.UpdatedAt),
+			); err != nil {
+				return 0, fmt.Errorf("line %d: failed to import checkpoint: %w", line, err)
+			}
+		case "source_file":
+			if _, err := tx.Exec(
+				"INSERT OR REPLACE INTO source_files (source, hash, size, mod_time, ingested_at) VALUES (?, ?, ?, ?, ?)",
+				rec.Source, rec.Hash, rec.Size, utcTime(rec.ModTime), utcTime(rec.IngestedAt),
+			); err != nil {
+				return 0, fmt.Errorf("line %d: failed to import source file: %w", line, err)
+			}
+		default:
+			return 0, fmt.Errorf("line %d: unknown record type %q", line, rec.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read import: %w", err)
+	}
+	if err := postings.flush(tx); err != nil {
+		return 0, fmt.Errorf("failed to index import: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return imported, nil
+}
+
+// utcTime returns t in UTC, or the current time when t is missing
+func utcTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Now().UTC()
+	}
+	return t.UTC()
+}
+
+// importChunk inserts one exported chunk with its keyphrases and
+// embedding, collecting its trigrams in postings
+func importChunk(tx *sql.Tx, rec jsonlRecord, postings postingBatch) error {
+	var deletedAt interface{}
+	if rec.DeletedAt != nil {
+		deletedAt = rec.DeletedAt.UTC()
+	}
+
+	metadata, err := encodeMetadata(rec.Metadata)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO documents (id, chunk, chunk_index, language, metadata, source, collection, deleted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		rec.ID, rec.Chunk, rec.ChunkIndex, rec.Language, metadata, rec.Source, rec.Collection, deletedAt,
+	); err != nil {
+		return fmt.Errorf("failed to import document %d: %w", rec.ID, err)
+	}
+
+	postings.add(rec.ID, LanguageTrigrams(rec.Chunk, rec.Language))
+	if err := addKeyphrases(tx, rec.ID, ExtractKeyphrases(rec.Chunk)); err != nil {
+		return fmt.Errorf("failed to index document %d: %w", rec.ID, err)
+	}
+
+	if rec.Embedding != "" {
+		blob, err := base64.StdEncoding.DecodeString(rec.Embedding)
+		if err != nil {
+			return fmt.Errorf("invalid embedding for document %d: %w", rec.ID, err)
+		}
+		if len(blob)%4 != 0 {
+			return fmt.Errorf("invalid embedding length for document %d", rec.ID)
+		}
+		if _, err := tx.Exec(
+			"INSERT OR REPLACE INTO embeddings (doc_id, embedding) VALUES (?, ?)",
+			rec.ID, blob,
+		); err != nil {
+			return fmt.Errorf("failed to import embedding for document %d: %w", rec.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImportJSONL(t *testing.T) {
+	source, cleanupSource := setupTestDB(t)
+	defer cleanupSource()
+
+	keep := insertSearchable(t, source, "Right to erasure of personal data")
+	gone := insertSearchable(t, source, "Right to data portability")
+	if err := source.SoftDelete(gone); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	if err := source.SetMetadata("chunk_count", "2"); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	checkpoint := Checkpoint{Source: "/docs/gdpr.txt", Fingerprint: "abc:def", NextChunk: 2, TotalChunks: 2}
+	if err := source.SaveCheckpoint(checkpoint); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+	modTime := time.Date(2024, 5, 25, 12, 0, 0, 0, time.UTC)
+	if err := source.SaveSourceFile(SourceFile{Source: "/docs/gdpr.txt", Hash: "0123", Size: 42, ModTime: modTime}); err != nil {
+		t.Fatalf("SaveSourceFile failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := source.ExportJSONL(&buf); err != nil {
+		t.Fatalf("ExportJSONL failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expected header, metadata, 2 chunks, a checkpoint and a file, got %d lines:\n%s", len(lines), buf.String())
+	}
+
+	target, cleanupTarget := setupTestDB(t)
+	defer cleanupTarget()
+
+	n, err := target.ImportJSONL(&buf)
+	if err != nil {
+		t.Fatalf("ImportJSONL failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 chunks imported, got %d", n)
+	}
+
+	count, err := target.GetMetadata("chunk_count")
+	if err != nil || count != "2" {
+		t.Errorf("Expected metadata to be imported, got %q (%v)", count, err)
+	}
+
+	// Ingestion state survives, so the sources are not ingested again
+	cp, err := target.GetCheckpoint(checkpoint.Source)
+	if err != nil || cp == nil || cp.Fingerprint != checkpoint.Fingerprint || !cp.Complete() {
+		t.Errorf("Expected the checkpoint to be imported, got %+v (%v)", cp, err)
+	}
+	file, err := target.GetSourceFile("/docs/gdpr.txt")
+	if err != nil || file == nil || file.Hash != "0123" || file.Size != 42 || !file.ModTime.Equal(modTime) {
+		t.Errorf("Expected the file record to be imported, got %+v (%v)", file, err)
+	}
+
+	doc, err := target.GetDocument(gone)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc == nil || doc.DeletedAt == nil {
+		t.Errorf("Expected tombstone to survive import, got %+v", doc)
+	}
+
+	// Trigrams are rebuilt and embeddings carried over unchanged
+	results, err := target.HybridSearch("erasure", []float32{1, 0, 0}, 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != keep {
+		t.Errorf("Expected imported document in results, got %+v", results)
+	}
+
+	vectors, err := target.SearchVectors([]float32{1, 0, 0}, 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchVectors failed: %v", err)
+	}
+	if len(vectors) != 1 || vectors[0].Score < 0.999 {
+		t.Errorf("Expected embedding to round-trip, got %+v", vectors)
+	}
+}
+
+func TestImportJSONLRejectsBadInput(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	inputs := map[string]string{
+		"wrong format": `{"type":"header","format":"other","version":1}`,
+		"unknown type": `{"type":"bogus"}`,
+		"bad json":     `{"type":`,
+		"bad base64":   `{"type":"chunk","id":1,"chunk":"text","embedding":"!!"}`,
+		"missing id":   `{"type":"chunk","chunk":"text"}`,
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := database.ImportJSONL(strings.NewReader(input)); err == nil {
+				t.Error("Expected import error")
+			}
+		})
+	}
+
+	// A failed import leaves the database untouched
+	doc, err := database.GetDocument(1)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc != nil {
+		t.Errorf("Expected failed import to roll back, got %+v", doc)
+	}
+}
+
+func TestJSONLRecordOmitsUnusedFields(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	if err := database.ExportJSONL(&buf); err != nil {
+		t.Fatalf("ExportJSONL failed: %v", err)
+	}
+	expected := `{"type":"header","format":"gdpr-mcp-jsonl","version":2}`
+	if got := strings.TrimSpace(buf.String()); got != expected {
+		t.Errorf("Empty export = %s, want %s", got, expected)
+	}
+}
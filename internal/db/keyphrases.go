@@ -0,0 +1,213 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Keyphrase extraction limits
+const (
+	maxKeyphrases      = 8   // keyphrases stored per chunk
+	maxKeyphraseWords  = 4   // longest keyphrase in words
+	maxKeyphraseLookup = 200 // most query n-grams looked up per search
+)
+
+// keyphraseBoost is added to the keyword score of chunks with a stored
+// keyphrase in the query, so that "what is personal data" ranks the chunk
+// defining it above the many that merely mention it
+const keyphraseBoost = 0.2
+
+// definedTermRe matches terms defined the way Article 4 does, as in
+// "'personal data' means"
+var definedTermRe = regexp.MustCompile(`(?i)['‘"“]([^'’"”]{3,80})['’"”]\s+means\b`)
+
+// phraseStopwords end a candidate keyphrase: function words, and words that
+// are frequent in legal text without saying what a provision is about
+var phraseStopwords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "an": true, "and": true,
+	"any": true, "are": true, "as": true, "at": true, "be": true, "been": true, "before": true,
+	"being": true, "between": true, "both": true, "but": true, "by": true, "can": true,
+	"concerned": true, "could": true, "do": true, "does": true, "each": true, "either": true,
+	"for": true, "from": true, "has": true, "have": true, "however": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "its": true, "may": true, "more": true, "must": true,
+	"no": true, "not": true, "of": true, "on": true, "or": true, "other": true, "out": true,
+	"paragraph": true, "point": true, "points": true, "pursuant": true, "referred": true,
+	"regard": true, "regulation": true, "relevant": true, "same": true, "set": true, "shall": true,
+	"should": true, "so": true, "such": true, "than": true, "that": true, "the": true, "their": true,
+	"them": true, "then": true, "there": true, "these": true, "they": true, "this": true,
+	"those": true, "through": true, "to": true, "under": true, "unless": true, "up": true,
+	"upon": true, "was": true, "were": true, "what": true, "when": true, "where": true,
+	"whether": true, "which": true, "while": true, "who": true, "whom": true, "will": true,
+	"with": true, "within": true, "without": true, "would": true,
+	"article": true, "articles": true, "chapter": true, "section": true, "recital": true,
+}
+
+// ExtractKeyphrases returns up to maxKeyphrases salient phrases of a chunk,
+// lower-cased: terms it defines ("'personal data' means") first, then the
+// runs of two to four content words between stopwords and punctuation that
+// score highest by RAKE, which favours phrases of words that mostly occur
+// within longer phrases
+func ExtractKeyphrases(text string) []string {
+	var phrases []string
+	seen := make(map[string]bool)
+	add := func(phrase string) {
+		if !seen[phrase] && len(phrases) < maxKeyphrases {
+			seen[phrase] = true
+			phrases = append(phrases, phrase)
+		}
+	}
+
+	for _, m := range definedTermRe.FindAllStringSubmatch(text, -1) {
+		if term := strings.Join(strings.Fields(strings.ToLower(m[1])), " "); term != "" {
+			add(term)
+		}
+	}
+
+	// Split into candidate phrases at stopwords, numbers and punctuation
+	var candidates [][]string
+	var current []string
+	flush := func() {
+		if len(current) >= 2 && len(current) <= maxKeyphraseWords {
+			candidates = append(candidates, current)
+		}
+		current = nil
+	}
+	for _, token := range strings.FieldsFunc(strings.ToLower(text), unicode.IsSpace) {
+		word := strings.TrimFunc(token, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if word == "" || phraseStopwords[word] || !strings.ContainsFunc(word, unicode.IsLetter) || len([]rune(word)) < 3 {
+			flush()
+			continue
+		}
+		if strings.IndexFunc(token, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) == 0 {
+			flush()
+		}
+		current = append(current, word)
+		if last, _ := utf8.DecodeLastRuneInString(token); !unicode.IsLetter(last) && !unicode.IsDigit(last) && last != '-' {
+			flush()
+		}
+	}
+	flush()
+
+	// RAKE: a word scores its degree (the words it co-occurs with in
+	// candidates, itself included) over its frequency; a phrase the sum
+	frequency := make(map[string]int)
+	degree := make(map[string]int)
+	for _, c := range candidates {
+		for _, w := range c {
+			frequency[w]++
+			degree[w] += len(c)
+		}
+	}
+	scores := make(map[string]float64)
+	for _, c := range candidates {
+		phrase := strings.Join(c, " ")
+		if _, ok := scores[phrase]; ok {
+			continue
+		}
+		for _, w := range c {
+			scores[phrase] += float64(degree[w]) / float64(frequency[w])
+		}
+	}
+	ranked := make([]string, 0, len(scores))
+	for phrase := range scores {
+		ranked = append(ranked, phrase)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	for _, phrase := range ranked {
+		add(phrase)
+	}
+	return phrases
+}
+
+// InsertKeyphrases stores the keyphrases of a document for boosting
+func (db *DB) InsertKeyphrases(docID int64, phrases []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := addKeyphrases(tx, docID, phrases); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func addKeyphrases(tx *sql.Tx, docID int64, phrases []string) error {
+	for _, phrase := range phrases {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO keyphrases (doc_id, phrase) VALUES (?, ?)",
+			docID, strings.ToLower(strings.TrimSpace(phrase)),
+		); err != nil {
+			return fmt.Errorf("failed to insert keyphrase: %w", err)
+		}
+	}
+	return nil
+}
+
+// Keyphrases returns the stored keyphrases of a document
+func (db *DB) Keyphrases(docID int64) ([]string, error) {
+	rows, err := db.conn.Query("SELECT phrase FROM keyphrases WHERE doc_id = ? ORDER BY rowid", docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keyphrases: %w", err)
+	}
+	defer rows.Close()
+
+	var phrases []string
+	for rows.Next() {
+		var phrase string
+		if err := rows.Scan(&phrase); err != nil {
+			return nil, fmt.Errorf("failed to scan keyphrase: %w", err)
+		}
+		phrases = append(phrases, phrase)
+	}
+	return phrases, rows.Err()
+}
+
+// keyphraseDocuments returns the IDs of the live chunks with a keyphrase
+// that occurs in the query as whole words
+func (db *DB) keyphraseDocuments(query string) ([]int64, error) {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	var ngrams []interface{}
+	for n := 1; n <= maxKeyphraseWords; n++ {
+		for i := 0; i+n <= len(words) && len(ngrams) < maxKeyphraseLookup; i++ {
+			ngrams = append(ngrams, strings.Join(words[i:i+n], " "))
+		}
+	}
+	if len(ngrams) == 0 {
+		return nil, nil
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT DISTINCT k.doc_id FROM keyphrases k
+		JOIN documents d ON d.id = k.doc_id
+		WHERE d.deleted_at IS NULL AND k.phrase IN (?`+strings.Repeat(",?", len(ngrams)-1)+`)`,
+		ngrams...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find keyphrase matches: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractKeyphrases(t *testing.T) {
+	definition := "‘personal data’ means any information relating to an identified or identifiable natural person ('data subject'); " +
+		"'processing' means any operation which is performed on personal data."
+	phrases := ExtractKeyphrases(definition)
+	if len(phrases) < 2 || phrases[0] != "personal data" || phrases[1] != "processing" {
+		t.Errorf("Expected the defined terms first, got %v", phrases)
+	}
+	want := map[string]bool{"identifiable natural person": true, "operation": false, "relating": false}
+	found := make(map[string]bool)
+	for _, p := range phrases {
+		found[p] = true
+	}
+	for phrase, expected := range want {
+		if found[phrase] != expected {
+			t.Errorf("Expected %q extracted to be %v, got %v", phrase, expected, phrases)
+		}
+	}
+
+	// Phrases break at punctuation and numbers, and are capped
+	phrases = ExtractKeyphrases("Data protection officer. Supervisory authority 2016 lead authority, " +
+		"one two three four five six seven eight nine ten eleven twelve")
+	for _, p := range phrases {
+		if p == "officer supervisory" || p == "authority lead" {
+			t.Errorf("Expected no phrase across punctuation or numbers, got %v", phrases)
+		}
+	}
+	if len(ExtractKeyphrases(definition+" "+definition)) > maxKeyphrases {
+		t.Errorf("Expected at most %d keyphrases", maxKeyphrases)
+	}
+	if phrases := ExtractKeyphrases("the and of it"); len(phrases) != 0 {
+		t.Errorf("Expected no keyphrases from stopwords, got %v", phrases)
+	}
+
+	// Extraction is deterministic
+	if a, b := ExtractKeyphrases(definition), ExtractKeyphrases(definition); !reflect.DeepEqual(a, b) {
+		t.Errorf("Expected the same keyphrases twice, got %v and %v", a, b)
+	}
+}
+
+func TestInsertKeyphrases(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	id, err := database.InsertDocument(Document{Chunk: "'controller' means the body which determines the purposes", Metadata: map[string]string{}})
+	if err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+	if err := database.InsertKeyphrases(id, []string{"Controller", "controller", " purposes of processing "}); err != nil {
+		t.Fatalf("InsertKeyphrases failed: %v", err)
+	}
+	phrases, err := database.Keyphrases(id)
+	if err != nil {
+		t.Fatalf("Keyphrases failed: %v", err)
+	}
+	if !reflect.DeepEqual(phrases, []string{"controller", "purposes of processing"}) {
+		t.Errorf("Expected the keyphrases lower-cased once each, got %v", phrases)
+	}
+
+	// Keyphrases go with their chunk
+	if _, err := database.conn.Exec("DELETE FROM documents WHERE id = ?", id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if phrases, _ := database.Keyphrases(id); len(phrases) != 0 {
+		t.Errorf("Expected the keyphrases deleted with the chunk, got %v", phrases)
+	}
+}
+
+func TestSearchTrigramsBoostsKeyphrases(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, chunk := range []string{
+		"Where the controller processes personal data of a data subject, the controller shall inform the data subject.",
+		"'personal data' means any information relating to an identified or identifiable natural person.",
+	} {
+		id, err := database.InsertDocument(Document{Chunk: chunk, Metadata: map[string]string{}})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		if err := database.InsertKeyphrases(id, ExtractKeyphrases(chunk)); err != nil {
+			t.Fatalf("InsertKeyphrases failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	results, err := database.SearchTrigrams("What is personal data?", 10, SearchOptions{Explain: true})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != ids[1] {
+		t.Fatalf("Expected the defining chunk first, got %+v", results)
+	}
+	if k := results[0].Explain.Keyword; k.KeyphraseBoost != keyphraseBoost {
+		t.Errorf("Expected the keyphrase boost explained, got %+v", k)
+	}
+	if k := results[1].Explain.Keyword; k.KeyphraseBoost != 0 {
+		t.Errorf("Expected no keyphrase boost for the other chunk, got %+v", k)
+	}
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diversify reorders results by maximal marginal relevance and returns the
+// first limit: each pick maximizes (1-diversity) * relevance minus
+// diversity * its highest similarity to the results already picked, so a
+// broad question is not answered by several overlapping chunks of the same
+// article. Relevance is the result score scaled to 0..1 across results.
+// Similarity is the cosine of the stored embeddings, or trigram overlap when
+// a result has none. Scores are kept; a diversity of 0 keeps the order.
+func (db *DB) Diversify(results []SearchResult, limit int, diversity float64) ([]SearchResult, error) {
+	if diversity < 0 || diversity > 1 {
+		return nil, fmt.Errorf("diversity must be between 0 and 1, got %v", diversity)
+	}
+	if limit > len(results) {
+		limit = len(results)
+	}
+	if diversity == 0 || len(results) < 2 {
+		return results[:limit], nil
+	}
+
+	ids := make([]int64, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	embeddings, err := db.loadEmbeddings(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lo, hi := results[0].Score, results[0].Score
+	for _, r := range results {
+		if r.Score < lo {
+			lo = r.Score
+		}
+		if r.Score > hi {
+			hi = r.Score
+		}
+	}
+	relevance := make([]float64, len(results))
+	for i, r := range results {
+		relevance[i] = 1
+		if hi > lo {
+			relevance[i] = (r.Score - lo) / (hi - lo)
+		}
+	}
+
+	// Embeddings are compared only when every result has one, as cosine
+	// and trigram similarities are not on the same scale
+	similarity := func(i, j int) float64 {
+		return CosineSimilarity(embeddings[results[i].ID], embeddings[results[j].ID])
+	}
+	if len(embeddings) < len(results) {
+		trigrams := make([]map[string]bool, len(results))
+		for i, r := range results {
+			trigrams[i] = trigramSet(r.chunk)
+		}
+		similarity = func(i, j int) float64 {
+			return jaccard(trigrams[i], trigrams[j])
+		}
+	}
+
+	// maxSim[i] is the highest similarity of candidate i to the picks
+	maxSim := make([]float64, len(results))
+	picked := make([]bool, len(results))
+	diversified := make([]SearchResult, 0, limit)
+	for len(diversified) < limit {
+		best, bestValue := -1, 0.0
+		for i := range results {
+			if picked[i] {
+				continue
+			}
+			value := (1-diversity)*relevance[i] - diversity*maxSim[i]
+			if best < 0 || value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+		picked[best] = true
+		diversified = append(diversified, results[best])
+		for i := range results {
+			if !picked[i] {
+				if sim := similarity(i, best); sim > maxSim[i] {
+					maxSim[i] = sim
+				}
+			}
+		}
+	}
+	return diversified, nil
+}
+
+// loadEmbeddings returns the stored embeddings of the given documents
+func (db *DB) loadEmbeddings(ids []int64) (map[int64][]float32, error) {
+	embeddings := make(map[int64][]float32, len(ids))
+	if len(ids) == 0 {
+		return embeddings, nil
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	rows, err := db.conn.Query("SELECT doc_id, embedding FROM embeddings WHERE doc_id IN ("+strings.Join(placeholders, ",")+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			return nil, fmt.Errorf("failed to scan embedding: %w", err)
+		}
+		embeddings[id] = bytesToFloat32Slice(blob)
+	}
+	return embeddings, rows.Err()
+}
+
+// trigramSet returns the trigrams of a chunk as a set
+func trigramSet(chunk string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range GenerateTrigrams(chunk) {
+		set[t] = true
+	}
+	return set
+}
+
+// jaccard returns the share of trigrams two sets have in common
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,63 @@
+package db
+
+import "testing"
+
+func TestDiversify(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chunks := []string{
+		"The data subject shall have the right to obtain erasure of personal data",
+		"The data subject shall have the right to obtain erasure of personal data without delay",
+		"The controller shall notify a personal data breach to the supervisory authority",
+	}
+	embeddings := [][]float32{{1, 0}, {0.99, 0.1}, {0, 1}}
+	results := make([]SearchResult, len(chunks))
+	for i, chunk := range chunks {
+		id, err := database.InsertChunk(chunk, i)
+		if err != nil {
+			t.Fatalf("Failed to insert chunk: %v", err)
+		}
+		if err := database.InsertEmbedding(id, embeddings[i]); err != nil {
+			t.Fatalf("Failed to insert embedding: %v", err)
+		}
+		results[i] = SearchResult{ID: id, Score: 1 - float64(i)*0.1, chunk: chunk}
+	}
+
+	kept, err := database.Diversify(results, 2, 0)
+	if err != nil {
+		t.Fatalf("Diversify failed: %v", err)
+	}
+	if len(kept) != 2 || kept[0].ID != results[0].ID || kept[1].ID != results[1].ID {
+		t.Errorf("Expected diversity 0 to keep the order, got %+v", kept)
+	}
+
+	diversified, err := database.Diversify(results, 2, 0.5)
+	if err != nil {
+		t.Fatalf("Diversify failed: %v", err)
+	}
+	if len(diversified) != 2 || diversified[0].ID != results[0].ID || diversified[1].ID != results[2].ID {
+		t.Errorf("Expected the near duplicate to be skipped, got %+v", diversified)
+	}
+	if diversified[1].Score != results[2].Score {
+		t.Errorf("Expected scores to be kept, got %v", diversified[1].Score)
+	}
+
+	// Without embeddings the chunks' trigram overlap is the similarity
+	plain := []SearchResult{
+		{ID: -1, Score: 3, chunk: chunks[0]},
+		{ID: -2, Score: 2, chunk: chunks[1]},
+		{ID: -3, Score: 1, chunk: chunks[2]},
+	}
+	diversified, err = database.Diversify(plain, 2, 0.5)
+	if err != nil {
+		t.Fatalf("Diversify failed: %v", err)
+	}
+	if diversified[1].ID != -3 {
+		t.Errorf("Expected trigram overlap to skip the near duplicate, got %+v", diversified)
+	}
+
+	if _, err := database.Diversify(results, 2, 1.5); err == nil {
+		t.Error("Expected a diversity above 1 to be rejected")
+	}
+}
@@ -0,0 +1,36 @@
+package db
+
+import "sort"
+
+// FuseQueries merges the results of several formulations of one question by
+// reciprocal rank fusion with the default constant, so passages found by
+// many formulations rise. A result keeps the snippet of the first list it
+// appears in, which should be that of the original query. Results scoring
+// the same keep the order in which they were first found.
+func FuseQueries(lists [][]SearchResult) []SearchResult {
+	scores := make(map[int64]float64)
+	found := make(map[int64]int)
+	var fused []SearchResult
+	for _, results := range lists {
+		for i, r := range results {
+			if _, ok := scores[r.ID]; !ok {
+				fused = append(fused, r)
+			}
+			scores[r.ID] += 1 / (DefaultRRFK + float64(i+1))
+			found[r.ID]++
+		}
+	}
+
+	for i := range fused {
+		fused[i].Score = scores[fused[i].ID]
+		if x := fused[i].Explain; x != nil {
+			explain := *x
+			explain.Queries = found[fused[i].ID]
+			fused[i].Explain = &explain
+		}
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+	return fused
+}
@@ -0,0 +1,24 @@
+package db
+
+import "testing"
+
+func TestFuseQueries(t *testing.T) {
+	original := []SearchResult{{ID: 1, Snippet: "one"}, {ID: 2, Snippet: "two"}}
+	rewrite := []SearchResult{{ID: 3, Snippet: "three"}, {ID: 2, Snippet: "two again"}}
+	another := []SearchResult{{ID: 2, Snippet: "two once more"}}
+
+	fused := FuseQueries([][]SearchResult{original, rewrite, another})
+	if len(fused) != 3 || fused[0].ID != 2 {
+		t.Fatalf("Expected the result found by every query first, got %+v", fused)
+	}
+	if fused[0].Snippet != "two" {
+		t.Errorf("Expected the snippet of the original query, got %q", fused[0].Snippet)
+	}
+	if want := 2/(DefaultRRFK+2.0) + 1/(DefaultRRFK+1.0); fused[0].Score != want {
+		t.Errorf("Expected score %v, got %v", want, fused[0].Score)
+	}
+	// Equal scores keep the order they were found in
+	if fused[1].ID != 1 || fused[2].ID != 3 {
+		t.Errorf("Expected the original's result before the rewrite's, got %+v", fused)
+	}
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Trigram normalizations. Chunks are indexed under the normalization of
+// their language so that spelling variants users commonly type match: German
+// umlauts and ß are transliterated ("Löschung" and "Loeschung" share
+// trigrams), Romance-language and Greek accents are dropped.
+const (
+	normalizeNone    = ""
+	normalizeGerman  = "de"
+	normalizeAccents = "accents"
+	normalizeGreek   = "el"
+)
+
+// languageNormalization maps ISO 639-1 codes to the trigram normalization of
+// the language; unlisted languages are only lower-cased
+var languageNormalization = map[string]string{
+	"de": normalizeGerman,
+	"fr": normalizeAccents,
+	"es": normalizeAccents,
+	"it": normalizeAccents,
+	"pt": normalizeAccents,
+	"nl": normalizeAccents,
+	"ro": normalizeAccents,
+	"el": normalizeGreek,
+}
+
+var germanReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
+// NormalizeText lower-cases text and applies the trigram normalization of
+// lang
+func NormalizeText(text, lang string) string {
+	return normalize(strings.ToLower(text), languageNormalization[strings.ToLower(lang)])
+}
+
+func normalize(text, normalization string) string {
+	switch normalization {
+	case normalizeGerman:
+		return germanReplacer.Replace(text)
+	case normalizeAccents:
+		return stripAccents(text)
+	case normalizeGreek:
+		return strings.ReplaceAll(stripAccents(text), "ς", "σ")
+	default:
+		return text
+	}
+}
+
+// stripAccents removes combining marks, turning "é" into "e"
+func stripAccents(text string) string {
+	decomposed := norm.NFD.String(text)
+	var sb strings.Builder
+	sb.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if !unicode.Is(unicode.Mn, r) {
+			sb.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(sb.String())
+}
+
+// LanguageTrigrams generates the trigrams a chunk in lang is indexed under
+func LanguageTrigrams(text, lang string) []string {
+	return GenerateTrigrams(NormalizeText(text, lang))
+}
+
+// queryNormalizations returns the normalizations a query is searched under
+// to match chunks however they were indexed: none, plus that of lang when a
+// language filter is set or of every language otherwise. Normalizations
+// leaving the query unchanged are skipped.
+func queryNormalizations(query, lang string) []string {
+	query = strings.ToLower(query)
+	candidates := []string{normalizeGerman, normalizeAccents, normalizeGreek}
+	if lang != "" {
+		candidates = []string{languageNormalization[strings.ToLower(lang)]}
+	}
+
+	normalizations := []string{normalizeNone}
+	seen := map[string]bool{query: true}
+	for _, n := range candidates {
+		if v := normalize(query, n); !seen[v] {
+			seen[v] = true
+			normalizations = append(normalizations, n)
+		}
+	}
+	return normalizations
+}
+
+// chunkNormalizations returns the normalizations a chunk may have been
+// indexed under, to compare it with a query the same way: none, plus that of
+// lang or, without a language, of every language
+func chunkNormalizations(lang string) []string {
+	if lang != "" {
+		return []string{normalizeNone, languageNormalization[strings.ToLower(lang)]}
+	}
+	return []string{normalizeNone, normalizeGerman, normalizeAccents, normalizeGreek}
+}
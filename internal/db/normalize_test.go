@@ -0,0 +1,97 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		text, lang, want string
+	}{
+		{"Recht auf Löschung", "de", "recht auf loeschung"},
+		{"Straße", "DE", "strasse"},
+		{"Droit à l'effacement", "fr", "droit a l'effacement"},
+		{"Protección de datos", "es", "proteccion de datos"},
+		{"Διαγραφής", "el", "διαγραφησ"},
+		{"Right to Erasure", "en", "right to erasure"},
+		{"Löschung", "", "löschung"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeText(tt.text, tt.lang); got != tt.want {
+			t.Errorf("NormalizeText(%q, %q) = %q, want %q", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestQueryNormalizations(t *testing.T) {
+	if got := queryNormalizations("right to erasure", ""); len(got) != 1 {
+		t.Errorf("Expected plain ASCII queries to be searched once, got %v", got)
+	}
+	if got := queryNormalizations("Löschung", ""); len(got) != 3 {
+		t.Errorf("Expected raw, German and accent-free variants, got %v", got)
+	}
+	if got := queryNormalizations("Löschung", "de"); len(got) != 2 || got[1] != normalizeGerman {
+		t.Errorf("Expected raw and German variants under a language filter, got %v", got)
+	}
+}
+
+func TestSearchTrigramsLanguageNormalization(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docs := []Document{
+		{Chunk: "Recht auf Löschung personenbezogener Daten", Language: "de"},
+		{Chunk: "Droit à l'effacement des données", Language: "fr"},
+	}
+	for i, d := range docs {
+		d.ChunkIndex = i
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, LanguageTrigrams(d.Chunk, d.Language)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		lang  string
+		want  int64
+	}{
+		{"Löschung", "", 1},
+		{"Loeschung", "", 1},
+		{"Löschung", "de", 1},
+		{"donnees", "", 2},
+		{"données", "fr", 2},
+	}
+	for _, tt := range tests {
+		results, err := database.SearchTrigrams(tt.query, 1, SearchOptions{Language: tt.lang})
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		if len(results) != 1 || results[0].ID != tt.want {
+			t.Errorf("SearchTrigrams(%q, lang=%q) = %+v, want document %d", tt.query, tt.lang, results, tt.want)
+			continue
+		}
+		if results[0].Score != 1 {
+			t.Errorf("SearchTrigrams(%q, lang=%q) score = %v, want a full match", tt.query, tt.lang, results[0].Score)
+		}
+	}
+
+	// Purging removes the normalized trigrams as well
+	if err := database.SoftDelete(1); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	if _, err := database.Purge(time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	counts, err := database.lookupPostings(LanguageTrigrams("Löschung", "de"))
+	if err != nil {
+		t.Fatalf("lookupPostings failed: %v", err)
+	}
+	if len(counts) != 0 {
+		t.Errorf("Expected purged document to leave no postings, got %v", counts)
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SizeReport describes the disk usage of a database
+type SizeReport struct {
+	FileBytes int64 `json:"file_bytes"` // size of the database file
+	FreeBytes int64 `json:"free_bytes"` // unused pages of the file, reclaimed by Optimize
+	WALBytes  int64 `json:"wal_bytes"`  // size of the write-ahead log, emptied by CheckpointWAL
+}
+
+// SizeReport returns the disk usage of the database without changing it,
+// e.g. to see what Optimize would reclaim
+func (db *DB) SizeReport() (*SizeReport, error) {
+	var report SizeReport
+	var err error
+	if report.FileBytes, err = db.size(); err != nil {
+		return nil, err
+	}
+	var free, pageSize int64
+	if err := db.conn.QueryRow("PRAGMA freelist_count").Scan(&free); err != nil {
+		return nil, fmt.Errorf("failed to get free page count: %w", err)
+	}
+	if err := db.conn.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return nil, fmt.Errorf("failed to get page size: %w", err)
+	}
+	report.FreeBytes = free * pageSize
+
+	info, err := os.Stat(db.path + "-wal")
+	switch {
+	case err == nil:
+		report.WALBytes = info.Size()
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to inspect write-ahead log: %w", err)
+	}
+	return &report, nil
+}
+
+// CheckpointWAL copies the write-ahead log into the database file and
+// truncates it. It fails if other connections keep it in use.
+func (db *DB) CheckpointWAL() error {
+	var busy, logFrames, checkpointed int
+	if err := db.conn.QueryRow("PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &logFrames, &checkpointed); err != nil {
+		return fmt.Errorf("failed to checkpoint write-ahead log: %w", err)
+	}
+	if busy != 0 {
+		return fmt.Errorf("failed to checkpoint write-ahead log: database is in use")
+	}
+	return nil
+}
+
+// Optimize checkpoints the write-ahead log, rebuilds the database file to
+// reclaim the space of deleted chunks and refreshes the statistics the
+// query planner uses. It returns the disk usage before and after.
+func (db *DB) Optimize() (before, after *SizeReport, err error) {
+	if before, err = db.SizeReport(); err != nil {
+		return nil, nil, err
+	}
+	if err := db.CheckpointWAL(); err != nil {
+		return before, nil, err
+	}
+	if _, err := db.conn.Exec("VACUUM"); err != nil {
+		return before, nil, fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := db.conn.Exec("ANALYZE"); err != nil {
+		return before, nil, fmt.Errorf("failed to analyze database: %w", err)
+	}
+	// VACUUM goes through the write-ahead log too
+	if err := db.CheckpointWAL(); err != nil {
+		return before, nil, err
+	}
+	if after, err = db.SizeReport(); err != nil {
+		return before, nil, err
+	}
+	return before, after, nil
+}
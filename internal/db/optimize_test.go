@@ -0,0 +1,53 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptimize(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < 200; i++ {
+		insertSearchable(t, database, strings.Repeat("Right to erasure of personal data. ", 20))
+	}
+	if _, err := database.conn.Exec("UPDATE documents SET deleted_at = ?", time.Now().Add(-time.Hour).UTC()); err != nil {
+		t.Fatalf("Failed to delete documents: %v", err)
+	}
+	if _, err := database.Purge(time.Now()); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+
+	report, err := database.SizeReport()
+	if err != nil {
+		t.Fatalf("SizeReport failed: %v", err)
+	}
+	if report.WALBytes == 0 {
+		t.Errorf("Expected writes to be pending in the write-ahead log, got %+v", report)
+	}
+
+	before, after, err := database.Optimize()
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	if *before != *report {
+		t.Errorf("Expected the size before to match the report, got %+v and %+v", before, report)
+	}
+	if after.WALBytes != 0 || after.FreeBytes != 0 {
+		t.Errorf("Expected an empty log and no free pages after optimizing, got %+v", after)
+	}
+	if after.FileBytes >= before.FileBytes+before.WALBytes {
+		t.Errorf("Expected optimizing to shrink the database, got %+v before and %+v after", before, after)
+	}
+
+	// Optimizing again changes nothing
+	_, again, err := database.Optimize()
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	if *again != *after {
+		t.Errorf("Expected %+v after optimizing again, got %+v", after, again)
+	}
+}
@@ -0,0 +1,245 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// minChunkOverlap is the shortest text shared by the end of one chunk and
+// the start of the next that is taken as chunking overlap when rejoining
+// chunks
+const minChunkOverlap = 20
+
+// ArticleResult is a search result widened to the whole article its
+// matching chunks belong to. A matching chunk outside any article stands
+// for itself.
+type ArticleResult struct {
+	Article    string  `json:"article,omitempty"`
+	Title      string  `json:"title,omitempty"`
+	Collection string  `json:"collection,omitempty"`
+	Score      float64 `json:"score"`               // score of the best matching chunk
+	RawScore   float64 `json:"raw_score,omitempty"` // raw score of the best matching chunk
+	ChunkIDs   []int64 `json:"chunk_ids"`           // the matching chunks in text order
+	Text       string  `json:"text"`                // full text of the article
+}
+
+// ParentArticles maps results to the articles they belong to, in the order
+// of each article's best result, and returns every article once with its
+// complete text reassembled from its chunks. Chunks of the same article in
+// different collections or sources are different articles.
+func (db *DB) ParentArticles(results []SearchResult) ([]ArticleResult, error) {
+	type parentKey struct{ source, collection, article string }
+	var articles []ArticleResult
+	index := make(map[parentKey]int)
+	chunkIndex := make(map[int64]int)
+
+	for _, r := range results {
+		doc, err := db.GetDocument(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		article := doc.Metadata["article"]
+		if article == "" {
+			articles = append(articles, ArticleResult{
+				Collection: doc.Collection,
+				Score:      r.Score,
+				RawScore:   r.RawScore,
+				ChunkIDs:   []int64{r.ID},
+				Text:       doc.Chunk,
+			})
+			continue
+		}
+
+		chunkIndex[r.ID] = doc.ChunkIndex
+		key := parentKey{doc.Source, doc.Collection, article}
+		if i, ok := index[key]; ok {
+			articles[i].ChunkIDs = append(articles[i].ChunkIDs, r.ID)
+			continue
+		}
+		text, err := db.articleText(key.source, key.collection, key.article)
+		if err != nil {
+			return nil, err
+		}
+		index[key] = len(articles)
+		articles = append(articles, ArticleResult{
+			Article:    article,
+			Title:      doc.Metadata["article_title"],
+			Collection: doc.Collection,
+			Score:      r.Score,
+			RawScore:   r.RawScore,
+			ChunkIDs:   []int64{r.ID},
+			Text:       text,
+		})
+	}
+
+	for _, a := range articles {
+		sort.SliceStable(a.ChunkIDs, func(i, j int) bool {
+			return chunkIndex[a.ChunkIDs[i]] < chunkIndex[a.ChunkIDs[j]]
+		})
+	}
+	return articles, nil
+}
+
+// Article returns the full text of an article as ingested into a
+// collection, or nil if it was not ingested. The article is taken from the
+// source the first of its chunks was ingested from.
+func (db *DB) Article(number, collection string) (*ArticleResult, error) {
+	var source, metadata string
+	err := db.conn.QueryRow(`
+		SELECT source, metadata FROM documents
+		WHERE deleted_at IS NULL AND collection = ? AND json_extract(metadata, '$.article') = ?
+		ORDER BY id LIMIT 1`, collection, number).Scan(&source, &metadata)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find article: %w", err)
+	}
+	meta, err := decodeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	text, ids, err := db.provisionText(source, collection, "article", number)
+	if err != nil {
+		return nil, err
+	}
+	return &ArticleResult{
+		Article:    number,
+		Title:      meta["article_title"],
+		Collection: collection,
+		ChunkIDs:   ids,
+		Text:       text,
+	}, nil
+}
+
+// articleText joins the live chunks of an article in order, dropping the
+// text each chunk repeats from the one before
+func (db *DB) articleText(source, collection, article string) (string, error) {
+	text, _, err := db.provisionText(source, collection, "article", article)
+	return text, err
+}
+
+// provisionText returns the text of the provision of a source whose
+// metadata key has the given value, and the IDs of the chunks it was taken
+// from. Chunks are tagged with the provision their start belongs to, so the
+// provision may begin in the chunk before the first tagged one and end in
+// the middle of the last one: the chunks are joined in order without the
+// text each repeats from the one before, then cut from the provision's
+// heading to the next heading.
+func (db *DB) provisionText(source, collection, key, value string) (string, []int64, error) {
+	path := "$." + key
+	rows, err := db.conn.Query(`
+		SELECT id, chunk, COALESCE(json_extract(metadata, ?) = ?, 0) FROM documents
+		WHERE deleted_at IS NULL AND source = ? AND collection = ? AND (json_extract(metadata, ?) = ? OR chunk_index = (
+			SELECT MIN(chunk_index) - 1 FROM documents
+			WHERE deleted_at IS NULL AND source = ? AND collection = ? AND json_extract(metadata, ?) = ?))
+		ORDER BY chunk_index, id`,
+		path, value, source, collection, path, value, source, collection, path, value)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load %s chunks: %w", key, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var chunks []string
+	lead := 0 // chunks before the first tagged one
+	for rows.Next() {
+		var id int64
+		var chunk string
+		var tagged bool
+		if err := rows.Scan(&id, &chunk, &tagged); err != nil {
+			return "", nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if !tagged && lead == len(ids) {
+			lead++
+		}
+		ids = append(ids, id)
+		chunks = append(chunks, chunk)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, err
+	}
+	if lead == len(ids) {
+		return "", nil, nil
+	}
+
+	text, offsets := joinTexts(chunks)
+	start, end, ok := provisionBounds(text, key, value)
+	if !ok {
+		// Without its heading the provision starts with its first tagged chunk
+		ids, chunks = ids[lead:], chunks[lead:]
+		text, offsets = joinTexts(chunks)
+		start, end = 0, provisionEnd(text, key, value, 0)
+	}
+
+	// Keep the chunks contributing to the cut text
+	var kept []int64
+	for i, id := range ids {
+		next := len(text)
+		if i+1 < len(offsets) {
+			next = offsets[i+1]
+		}
+		if offsets[i] < end && next > start {
+			kept = append(kept, id)
+		}
+	}
+	return strings.TrimSpace(text[start:end]), kept, nil
+}
+
+// joinChunks joins the chunks of rows of id and chunk in order, dropping the
+// text each chunk repeats from the one before, and closes the rows
+func joinChunks(rows *sql.Rows) (string, []int64, error) {
+	defer rows.Close()
+
+	var ids []int64
+	var chunks []string
+	for rows.Next() {
+		var id int64
+		var chunk string
+		if err := rows.Scan(&id, &chunk); err != nil {
+			return "", nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+		chunks = append(chunks, chunk)
+	}
+	text, _ := joinTexts(chunks)
+	return text, ids, rows.Err()
+}
+
+// joinTexts joins chunks in order, dropping the text each chunk repeats from
+// the one before, and returns the offset in the joined text where the text
+// added for each chunk starts
+func joinTexts(chunks []string) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, 0, len(chunks))
+	for i, chunk := range chunks {
+		text := chunk
+		if i > 0 {
+			if n := chunkOverlap(chunks[i-1], chunk); n > 0 {
+				text = chunk[n:]
+			} else {
+				sb.WriteString("\n")
+			}
+		}
+		offsets = append(offsets, sb.Len())
+		sb.WriteString(text)
+	}
+	return sb.String(), offsets
+}
+
+// chunkOverlap returns the length of the longest start of next that ends
+// prev, or 0 when they share less than minChunkOverlap bytes
+func chunkOverlap(prev, next string) int {
+	for n := min(len(prev), len(next)); n >= minChunkOverlap; n-- {
+		if strings.HasSuffix(prev, next[:n]) {
+			return n
+		}
+	}
+	return 0
+}
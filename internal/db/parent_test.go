@@ -0,0 +1,165 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParentArticles(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insert := func(doc Document) int64 {
+		t.Helper()
+		id, err := database.InsertDocument(doc)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		return id
+	}
+	article17 := map[string]string{"article": "17", "article_title": "Right to erasure"}
+	// The second chunk repeats the end of the first, as overlapping chunks do
+	first := insert(Document{Chunk: "Article 17 Right to erasure. 1. The data subject shall have the right", ChunkIndex: 0, Source: "gdpr.txt", Metadata: article17})
+	second := insert(Document{Chunk: "shall have the right to obtain the erasure of personal data.", ChunkIndex: 1, Source: "gdpr.txt", Metadata: article17})
+	insert(Document{Chunk: "2. Where the controller has made the personal data public", ChunkIndex: 2, Source: "gdpr.txt", Metadata: article17})
+	other := insert(Document{Chunk: "Article 17 in another language version", ChunkIndex: 0, Source: "gdpr-de.txt", Metadata: article17})
+	loose := insert(Document{Chunk: "Guidance on erasure requests", ChunkIndex: 0, Source: "guidelines.txt"})
+	deleted := insert(Document{Chunk: "Deleted part of Article 17", ChunkIndex: 3, Source: "gdpr.txt", Metadata: article17})
+	if err := database.SoftDelete(deleted); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+
+	articles, err := database.ParentArticles([]SearchResult{
+		{ID: second, Score: 0.9}, {ID: loose, Score: 0.8}, {ID: first, Score: 0.7}, {ID: other, Score: 0.6},
+	})
+	if err != nil {
+		t.Fatalf("ParentArticles failed: %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("Expected 3 deduplicated results, got %+v", articles)
+	}
+
+	a := articles[0]
+	want := "Article 17 Right to erasure. 1. The data subject shall have the right to obtain the erasure of personal data.\n" +
+		"2. Where the controller has made the personal data public"
+	if a.Article != "17" || a.Title != "Right to erasure" || a.Score != 0.9 || a.Text != want {
+		t.Errorf("Expected the whole of Article 17 without overlap, got %+v", a)
+	}
+	if len(a.ChunkIDs) != 2 || a.ChunkIDs[0] != first || a.ChunkIDs[1] != second {
+		t.Errorf("Expected both matching chunks in text order, got %v", a.ChunkIDs)
+	}
+	if articles[1].Article != "" || articles[1].Text != "Guidance on erasure requests" {
+		t.Errorf("Expected a chunk outside any article to stand for itself, got %+v", articles[1])
+	}
+	if articles[2].Text != "Article 17 in another language version" {
+		t.Errorf("Expected the same article from another source separately, got %+v", articles[2])
+	}
+}
+
+func TestArticle(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	article35 := map[string]string{"article": "35", "article_title": "Data protection impact assessment"}
+	var ids []int64
+	for i, chunk := range []string{"Article 35 Data protection impact assessment", "1. Where a type of processing is likely to result in a high risk"} {
+		id, err := database.InsertDocument(Document{Chunk: chunk, ChunkIndex: i, Source: "gdpr.txt", Metadata: article35})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	article, err := database.Article("35", "")
+	if err != nil {
+		t.Fatalf("Article failed: %v", err)
+	}
+	if article == nil || article.Title != "Data protection impact assessment" || len(article.ChunkIDs) != 2 || article.ChunkIDs[0] != ids[0] ||
+		article.Text != "Article 35 Data protection impact assessment\n1. Where a type of processing is likely to result in a high risk" {
+		t.Errorf("Expected Article 35 in full, got %+v", article)
+	}
+	if article, err := database.Article("35", "gdpr-de"); err != nil || article != nil {
+		t.Errorf("Expected no Article 35 in another collection, got %+v, %v", article, err)
+	}
+}
+
+func TestArticleWindowChunks(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	article4 := "Article 4\nDefinitions\nFor the purposes of this Regulation:\n" +
+		"(1) 'personal data' means any information relating to an identified or identifiable natural person;\n" +
+		"(2) 'controller' means the natural or legal person which determines the purposes and means of the processing;"
+	text := "Article 3\nTerritorial scope\n1. This Regulation applies to the processing of personal data in the Union.\n" +
+		article4 + "\nCHAPTER III\nRights of the data subject\nSection 3\nRectification and erasure\nArticle 17\nRight to erasure"
+	ids := insertWindowed(t, database, "gdpr.txt", text, 80, 30, map[string]map[string]string{
+		"Article 3":   {"article": "3"},
+		"Article 4":   {"article": "4", "article_title": "Definitions"},
+		"CHAPTER III": nil,
+		"Article 17":  {"article": "17"},
+	})
+
+	article, err := database.Article("4", "")
+	if err != nil {
+		t.Fatalf("Article failed: %v", err)
+	}
+	if article == nil || article.Text != article4 {
+		t.Fatalf("Expected Article 4 from its heading to the next chapter, got %+v", article)
+	}
+	if article.ChunkIDs[0] >= article.ChunkIDs[len(article.ChunkIDs)-1] {
+		t.Errorf("Expected chunk IDs in text order, got %v", article.ChunkIDs)
+	}
+
+	// Matching chunks of an article are listed in text order, whatever their scores
+	last := article.ChunkIDs[len(article.ChunkIDs)-1]
+	articles, err := database.ParentArticles([]SearchResult{{ID: last, Score: 0.9}, {ID: ids[3], Score: 0.5}})
+	if err != nil {
+		t.Fatalf("ParentArticles failed: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Text != article4 || articles[0].ChunkIDs[0] != ids[3] || articles[0].ChunkIDs[1] != last {
+		t.Errorf("Expected Article 4 with its chunks in text order, got %+v", articles)
+	}
+}
+
+func TestChunkOverlap(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		want       int
+	}{
+		{"the data subject shall have the right", "shall have the right to obtain", 20},
+		{"no shared text here at all", "something else entirely", 0},
+		{"ends with the", "the start", 0}, // too short to be chunking overlap
+	}
+	for _, tt := range tests {
+		if got := chunkOverlap(tt.prev, tt.next); got != tt.want {
+			t.Errorf("chunkOverlap(%q, %q) = %d, want %d", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+// insertWindowed stores text as overlapping chunks of size bytes, as the
+// window strategy cuts them, each tagged with the metadata of the last
+// marker starting at or before the chunk's start
+func insertWindowed(t *testing.T, db *DB, source, text string, size, overlap int, markers map[string]map[string]string) []int64 {
+	t.Helper()
+	var ids []int64
+	for start, i := 0, 0; start < len(text); start, i = start+size-overlap, i+1 {
+		end := min(start+size, len(text))
+		var meta map[string]string
+		best := -1
+		for marker, m := range markers {
+			if at := strings.Index(text, marker); at >= 0 && at <= start && at > best {
+				best, meta = at, m
+			}
+		}
+		id, err := db.InsertDocument(Document{Chunk: text[start:end], ChunkIndex: i, Source: source, Metadata: meta})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		ids = append(ids, id)
+		if end == len(text) {
+			break
+		}
+	}
+	return ids
+}
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Posting lists store the IDs of the documents containing a trigram in
+// ascending order. Each ID is written as the uvarint-encoded difference to
+// its predecessor, so dense lists cost little more than a byte per entry.
+
+// encodePostings encodes ascending document IDs as a delta posting list
+func encodePostings(ids []int64) []byte {
+	buf := make([]byte, 0, len(ids)*2)
+	var prev int64
+	for _, id := range ids {
+		buf = binary.AppendUvarint(buf, uint64(id-prev))
+		prev = id
+	}
+	return buf
+}
+
+// decodePostings decodes a delta posting list into document IDs
+func decodePostings(data []byte) ([]int64, error) {
+	var ids []int64
+	var prev int64
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt posting list")
+		}
+		prev += int64(delta)
+		ids = append(ids, prev)
+		data = data[n:]
+	}
+	return ids, nil
+}
+
+// postingBatch collects the postings of documents being indexed, so that
+// each posting list is written once per batch rather than once per
+// document: rewriting the list of a common trigram for every chunk would
+// make indexing quadratic in the size of the corpus
+type postingBatch map[string][]int64
+
+// add records that docID contains trigrams
+func (b postingBatch) add(docID int64, trigrams []string) {
+	for _, trigram := range trigrams {
+		b[trigram] = append(b[trigram], docID)
+	}
+}
+
+// flush writes the collected postings within tx, in trigram order
+func (b postingBatch) flush(tx *sql.Tx) error {
+	trigrams := make([]string, 0, len(b))
+	for trigram := range b {
+		trigrams = append(trigrams, trigram)
+	}
+	sort.Strings(trigrams)
+	for _, trigram := range trigrams {
+		if err := addPostings(tx, trigram, b[trigram]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPostings adds document IDs to the posting list of trigram within tx
+func addPostings(tx *sql.Tx, trigram string, ids []int64) error {
+	ids = uniqueIDs(ids)
+	var docCount int
+	var lastDocID int64
+	err := tx.QueryRow(
+		"SELECT doc_count, last_doc_id FROM trigram_postings WHERE trigram = ?",
+		trigram,
+	).Scan(&docCount, &lastDocID)
+
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.Exec(
+			"INSERT INTO trigram_postings (trigram, doc_count, last_doc_id, postings) VALUES (?, ?, ?, ?)",
+			trigram, len(ids), ids[len(ids)-1], encodePostings(ids),
+		)
+		return err
+	case err != nil:
+		return err
+	case ids[0] > lastDocID:
+		// Fast path: IDs are assigned in ascending order during ingestion,
+		// so the new deltas are appended without reading the list
+		var tail []byte
+		prev := lastDocID
+		for _, id := range ids {
+			tail = binary.AppendUvarint(tail, uint64(id-prev))
+			prev = id
+		}
+		_, err = tx.Exec(
+			"UPDATE trigram_postings SET doc_count = doc_count + ?, last_doc_id = ?, postings = postings || ? WHERE trigram = ?",
+			len(ids), prev, tail, trigram,
+		)
+		return err
+	}
+
+	var postings []byte
+	if err := tx.QueryRow("SELECT postings FROM trigram_postings WHERE trigram = ?", trigram).Scan(&postings); err != nil {
+		return err
+	}
+	existing, err := decodePostings(postings)
+	if err != nil {
+		return fmt.Errorf("trigram %q: %w", trigram, err)
+	}
+	merged := uniqueIDs(append(existing, ids...))
+	_, err = tx.Exec(
+		"UPDATE trigram_postings SET doc_count = ?, last_doc_id = ?, postings = ? WHERE trigram = ?",
+		len(merged), merged[len(merged)-1], encodePostings(merged), trigram,
+	)
+	return err
+}
+
+// uniqueIDs sorts ids in ascending order and drops duplicates in place
+func uniqueIDs(ids []int64) []int64 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	out := ids[:0]
+	for i, id := range ids {
+		if i == 0 || id != ids[i-1] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// lookupPostings counts, per document, how many of the given trigrams it contains
+func (db *DB) lookupPostings(trigrams []string) (map[int64]int, error) {
+	placeholders := make([]string, len(trigrams))
+	args := make([]interface{}, len(trigrams))
+	for i, t := range trigrams {
+		placeholders[i] = "?"
+		args[i] = t
+	}
+
+	rows, err := db.conn.Query(
+		"SELECT trigram, postings FROM trigram_postings WHERE trigram IN ("+strings.Join(placeholders, ",")+")",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search trigrams: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var trigram string
+		var postings []byte
+		if err := rows.Scan(&trigram, &postings); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids, err := decodePostings(postings)
+		if err != nil {
+			return nil, fmt.Errorf("trigram %q: %w", trigram, err)
+		}
+		for _, id := range ids {
+			counts[id]++
+		}
+	}
+	return counts, rows.Err()
+}
+
+// migrateLegacyTrigrams converts the original one-row-per-(trigram, doc)
+// trigrams table into posting lists and drops it
+func (db *DB) migrateLegacyTrigrams() error {
+	columns, err := db.tableColumns("trigrams")
+	if err != nil {
+		return err
+	}
+	if !columns["doc_id"] {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT DISTINCT trigram, doc_id FROM trigrams ORDER BY trigram, doc_id")
+	if err != nil {
+		return fmt.Errorf("failed to read legacy trigrams: %w", err)
+	}
+
+	type posting struct {
+		trigram string
+		ids     []int64
+	}
+	var lists []posting
+	for rows.Next() {
+		var trigram string
+		var docID int64
+		if err := rows.Scan(&trigram, &docID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan legacy trigram: %w", err)
+		}
+		if len(lists) == 0 || lists[len(lists)-1].trigram != trigram {
+			lists = append(lists, posting{trigram: trigram})
+		}
+		last := &lists[len(lists)-1]
+		last.ids = append(last.ids, docID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range lists {
+		_, err := tx.Exec(
+			"INSERT OR REPLACE INTO trigram_postings (trigram, doc_count, last_doc_id, postings) VALUES (?, ?, ?, ?)",
+			p.trigram, len(p.ids), p.ids[len(p.ids)-1], encodePostings(p.ids),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to write posting list: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec("DROP TABLE trigrams"); err != nil {
+		return fmt.Errorf("failed to drop legacy trigrams: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// removePosting removes docID from the posting list of trigram within tx,
+// dropping the row once the list is empty
+func removePosting(tx *sql.Tx, trigram string, docID int64) error {
+	var postings []byte
+	err := tx.QueryRow(
+		"SELECT postings FROM trigram_postings WHERE trigram = ?",
+		trigram,
+	).Scan(&postings)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ids, err := decodePostings(postings)
+	if err != nil {
+		return fmt.Errorf("trigram %q: %w", trigram, err)
+	}
+	i := sort.Search(len(ids), func(i int) bool { return ids[i] >= docID })
+	if i == len(ids) || ids[i] != docID {
+		return nil
+	}
+	ids = append(ids[:i], ids[i+1:]...)
+
+	if len(ids) == 0 {
+		_, err = tx.Exec("DELETE FROM trigram_postings WHERE trigram = ?", trigram)
+		return err
+	}
+	_, err = tx.Exec(
+		"UPDATE trigram_postings SET doc_count = ?, last_doc_id = ?, postings = ? WHERE trigram = ?",
+		len(ids), ids[len(ids)-1], encodePostings(ids), trigram,
+	)
+	return err
+}
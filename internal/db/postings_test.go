@@ -0,0 +1,152 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPostingsRoundTrip(t *testing.T) {
+	ids := []int64{1, 2, 3, 130, 131, 100000}
+
+	encoded := encodePostings(ids)
+	if len(encoded) >= len(ids)*8 {
+		t.Errorf("Expected compressed postings, got %d bytes for %d IDs", len(encoded), len(ids))
+	}
+
+	decoded, err := decodePostings(encoded)
+	if err != nil {
+		t.Fatalf("decodePostings failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, ids) {
+		t.Errorf("Round trip mismatch: got %v, want %v", decoded, ids)
+	}
+
+	if _, err := decodePostings([]byte{0xff}); err == nil {
+		t.Error("Expected error for corrupt posting list")
+	}
+}
+
+func TestInsertTrigramsOutOfOrder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, docID := range []int64{5, 9, 2, 9, 7} {
+		if err := database.InsertTrigrams(docID, []string{"abc"}); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+	}
+
+	var docCount int
+	var postings []byte
+	err := database.conn.QueryRow(
+		"SELECT doc_count, postings FROM trigram_postings WHERE trigram = 'abc'",
+	).Scan(&docCount, &postings)
+	if err != nil {
+		t.Fatalf("Failed to read postings: %v", err)
+	}
+
+	ids, err := decodePostings(postings)
+	if err != nil {
+		t.Fatalf("decodePostings failed: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int64{2, 5, 7, 9}) || docCount != 4 {
+		t.Errorf("Unexpected posting list %v (doc_count %d)", ids, docCount)
+	}
+}
+
+func TestInsertTrigramsBatch(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	postings := func(trigram string) ([]int64, int) {
+		t.Helper()
+		var docCount int
+		var data []byte
+		if err := database.conn.QueryRow(
+			"SELECT doc_count, postings FROM trigram_postings WHERE trigram = ?", trigram,
+		).Scan(&docCount, &data); err != nil {
+			t.Fatalf("Failed to read postings: %v", err)
+		}
+		ids, err := decodePostings(data)
+		if err != nil {
+			t.Fatalf("decodePostings failed: %v", err)
+		}
+		return ids, docCount
+	}
+
+	if err := database.InsertTrigrams(3, []string{"abc"}); err != nil {
+		t.Fatalf("InsertTrigrams failed: %v", err)
+	}
+	// Appended after the existing list, repeated trigrams counted once
+	if err := database.InsertTrigramsBatch(map[int64][]string{
+		10: {"abc", "bcd", "abc"},
+		4:  {"abc"},
+	}); err != nil {
+		t.Fatalf("InsertTrigramsBatch failed: %v", err)
+	}
+	if ids, n := postings("abc"); !reflect.DeepEqual(ids, []int64{3, 4, 10}) || n != 3 {
+		t.Errorf("Unexpected posting list %v (doc_count %d)", ids, n)
+	}
+	if ids, n := postings("bcd"); !reflect.DeepEqual(ids, []int64{10}) || n != 1 {
+		t.Errorf("Unexpected posting list %v (doc_count %d)", ids, n)
+	}
+
+	// Earlier IDs are merged into the list
+	if err := database.InsertTrigramsBatch(map[int64][]string{1: {"abc"}, 12: {"abc"}}); err != nil {
+		t.Fatalf("InsertTrigramsBatch failed: %v", err)
+	}
+	if ids, n := postings("abc"); !reflect.DeepEqual(ids, []int64{1, 3, 4, 10, 12}) || n != 5 {
+		t.Errorf("Unexpected merged posting list %v (doc_count %d)", ids, n)
+	}
+}
+
+func TestMigrateConvertsLegacyTrigrams(t *testing.T) {
+	database, err := Open(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	// Layout used before posting lists: one row per (trigram, doc)
+	_, err = database.conn.Exec(`
+		CREATE TABLE documents (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			chunk TEXT NOT NULL,
+			chunk_index INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE trigrams (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trigram TEXT NOT NULL,
+			doc_id INTEGER NOT NULL
+		);
+		INSERT INTO documents (chunk, chunk_index) VALUES ('erasure', 0), ('erase', 1);
+		INSERT INTO trigrams (trigram, doc_id) VALUES
+			('era', 1), ('ras', 1), ('asu', 1), ('sur', 1), ('ure', 1),
+			('era', 2), ('ras', 2), ('ase', 2);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	columns, err := database.tableColumns("trigrams")
+	if err != nil {
+		t.Fatalf("tableColumns failed: %v", err)
+	}
+	if len(columns) != 0 {
+		t.Error("Expected legacy trigrams table to be dropped")
+	}
+
+	results, err := database.SearchTrigrams("erasure", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != 1 || results[1].ID != 2 {
+		t.Errorf("Unexpected results after migration: %+v", results)
+	}
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// "17", "Article 17", "Art. 17" or "art 17"
+	articleFilterRe = regexp.MustCompile(`(?i)^(?:art(?:icle|\.)?\s*)?(\d+)$`)
+	// "IV", "4" or "Chapter IV"
+	chapterFilterRe = regexp.MustCompile(`(?i)^(?:chapter\s+)?([IVXLC]+|\d+)$`)
+	// "26", "(26)" or "Recital 26"
+	recitalFilterRe = regexp.MustCompile(`(?i)^(?:recital\s*)?\(?(\d+)\)?$`)
+)
+
+// ArticleNumber parses an article reference such as "17", "Article 17" or
+// "Art. 17" into the article number stored in chunk metadata
+func ArticleNumber(s string) (string, bool) {
+	m := articleFilterRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", false
+	}
+	return positiveNumber(m[1])
+}
+
+// ChapterNumber parses a chapter reference such as "IV", "4" or
+// "Chapter IV" into the Roman numeral stored in chunk metadata
+func ChapterNumber(s string) (string, bool) {
+	m := chapterFilterRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", false
+	}
+	if n, err := strconv.Atoi(m[1]); err == nil {
+		if n < 1 || n >= 400 {
+			return "", false
+		}
+		return toRoman(n), true
+	}
+	numeral := strings.ToUpper(m[1])
+	if n := fromRoman(numeral); n == 0 || toRoman(n) != numeral {
+		return "", false
+	}
+	return numeral, true
+}
+
+// RecitalNumber parses a recital reference such as "26", "(26)" or
+// "Recital 26" into the recital number stored in chunk metadata
+func RecitalNumber(s string) (string, bool) {
+	m := recitalFilterRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", false
+	}
+	return positiveNumber(m[1])
+}
+
+// positiveNumber strips leading zeros from digits, rejecting zero
+func positiveNumber(digits string) (string, bool) {
+	n := strings.TrimLeft(digits, "0")
+	return n, n != ""
+}
+
+var romanNumerals = []struct {
+	value   int
+	numeral string
+}{
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// toRoman writes n, between 1 and 399, as a Roman numeral
+func toRoman(n int) string {
+	var sb strings.Builder
+	for _, r := range romanNumerals {
+		for ; n >= r.value; n -= r.value {
+			sb.WriteString(r.numeral)
+		}
+	}
+	return sb.String()
+}
+
+// fromRoman reads a Roman numeral of the letters I, V, X, L and C, returning
+// 0 for anything else
+func fromRoman(numeral string) int {
+	values := map[byte]int{'I': 1, 'V': 5, 'X': 10, 'L': 50, 'C': 100}
+	n := 0
+	for i := 0; i < len(numeral); i++ {
+		v, ok := values[numeral[i]]
+		if !ok {
+			return 0
+		}
+		if i+1 < len(numeral) && values[numeral[i+1]] > v {
+			n -= v
+		} else {
+			n += v
+		}
+	}
+	return n
+}
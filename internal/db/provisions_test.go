@@ -0,0 +1,34 @@
+package db
+
+import "testing"
+
+func TestProvisionNumbers(t *testing.T) {
+	tests := []struct {
+		parse func(string) (string, bool)
+		input string
+		want  string
+		ok    bool
+	}{
+		{ArticleNumber, "17", "17", true},
+		{ArticleNumber, "Article 17", "17", true},
+		{ArticleNumber, "Art. 6", "6", true},
+		{ArticleNumber, "art 06", "6", true},
+		{ArticleNumber, "0", "", false},
+		{ArticleNumber, "Article IV", "", false},
+		{ChapterNumber, "IV", "IV", true},
+		{ChapterNumber, "chapter iv", "IV", true},
+		{ChapterNumber, "4", "IV", true},
+		{ChapterNumber, "Chapter 11", "XI", true},
+		{ChapterNumber, "IIII", "", false},
+		{ChapterNumber, "0", "", false},
+		{RecitalNumber, "26", "26", true},
+		{RecitalNumber, "(26)", "26", true},
+		{RecitalNumber, "Recital 26", "26", true},
+		{RecitalNumber, "twenty-six", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := tt.parse(tt.input); got != tt.want || ok != tt.ok {
+			t.Errorf("parse(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package db
+
+import "strings"
+
+// Boolean operators recognized in queries. They are only operators when
+// written in capitals, so "terms and conditions" stays a plain query.
+const (
+	opAnd = "AND"
+	opOr  = "OR"
+	opNot = "NOT"
+)
+
+// isQuote matches the straight and typographic double quotes users type or
+// paste around phrases
+func isQuote(r rune) bool {
+	switch r {
+	case '"', '“', '”', '„', '«', '»':
+		return true
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenPhrase
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+}
+
+// tokenizeQuery splits a query into words, quoted phrases, operators and
+// parentheses. Parentheses only count as grouping at the edges of a word
+// with unbalanced parentheses, so "Article 6(1)(a)" stays one word. An
+// unmatched quote is ignored.
+func tokenizeQuery(query string) []queryToken {
+	var tokens []queryToken
+	addWord := func(word string) {
+		for strings.HasPrefix(word, "(") && strings.Count(word, "(") > strings.Count(word, ")") {
+			tokens = append(tokens, queryToken{kind: tokenOpen})
+			word = word[1:]
+		}
+		closing := 0
+		for strings.HasSuffix(word, ")") && strings.Count(word, ")") > strings.Count(word, "(") {
+			closing++
+			word = word[:len(word)-1]
+		}
+		switch {
+		case word == opAnd || word == opOr || word == opNot:
+			tokens = append(tokens, queryToken{kind: tokenOperator, text: word})
+		case word != "":
+			tokens = append(tokens, queryToken{kind: tokenWord, text: word})
+		}
+		for ; closing > 0; closing-- {
+			tokens = append(tokens, queryToken{kind: tokenClose})
+		}
+	}
+
+	var word strings.Builder
+	flush := func() {
+		for _, w := range strings.Fields(word.String()) {
+			addWord(w)
+		}
+		word.Reset()
+	}
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		if !isQuote(runes[i]) {
+			word.WriteRune(runes[i])
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && !isQuote(runes[end]) {
+			end++
+		}
+		if end == len(runes) {
+			word.WriteRune(' ')
+			continue
+		}
+		flush()
+		if phrase := strings.Join(strings.Fields(string(runes[i+1:end])), " "); phrase != "" {
+			tokens = append(tokens, queryToken{kind: tokenPhrase, text: phrase})
+		}
+		i = end
+	}
+	flush()
+	return tokens
+}
+
+// parseQuery splits a query into the text that gets ranked, the quoted
+// phrases every result must contain, and a boolean condition on results.
+// Without operators, words only rank and quoted phrases are required. With
+// AND, OR or NOT, every word and phrase is a term of the condition, adjacent
+// terms are joined by AND, and the text is the terms not under NOT.
+func parseQuery(query string) (text string, phrases []string, expr queryExpr) {
+	tokens := tokenizeQuery(query)
+	boolean := false
+	for _, t := range tokens {
+		boolean = boolean || t.kind == tokenOperator
+	}
+
+	var words []string
+	if !boolean {
+		for _, t := range tokens {
+			switch t.kind {
+			case tokenWord:
+				words = append(words, t.text)
+			case tokenPhrase:
+				words = append(words, t.text)
+				phrases = append(phrases, t.text)
+			}
+		}
+		return strings.Join(words, " "), phrases, nil
+	}
+
+	p := &queryParser{tokens: tokens}
+	expr = p.parseOr()
+	// Stray closing parentheses end a group early; parse on after them
+	for p.pos < len(p.tokens) {
+		p.pos++
+		if rest := p.parseOr(); rest != nil {
+			expr = joinExprs(andExpr{}, expr, rest)
+		}
+	}
+	if expr != nil {
+		words = expr.positiveTerms(words)
+	}
+	return strings.Join(words, " "), nil, expr
+}
+
+// queryParser parses tokens by the grammar
+//
+//	or   = and { "OR" and }
+//	and  = not { ["AND"] not }
+//	not  = "NOT" not | "(" or ")" | term
+//
+// leniently: dangling operators and unclosed groups are ignored
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos], true
+	}
+	return queryToken{}, false
+}
+
+func (p *queryParser) parseOr() queryExpr {
+	expr := p.parseAnd()
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokenOperator || t.text != opOr {
+			return expr
+		}
+		p.pos++
+		expr = joinExprs(orExpr{}, expr, p.parseAnd())
+	}
+}
+
+func (p *queryParser) parseAnd() queryExpr {
+	expr := p.parseNot()
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokenClose || (t.kind == tokenOperator && t.text == opOr) {
+			return expr
+		}
+		if t.kind == tokenOperator && t.text == opAnd {
+			p.pos++
+		}
+		expr = joinExprs(andExpr{}, expr, p.parseNot())
+	}
+}
+
+func (p *queryParser) parseNot() queryExpr {
+	t, ok := p.peek()
+	if !ok {
+		return nil
+	}
+	switch t.kind {
+	case tokenOperator:
+		if t.text != opNot {
+			// AND or OR without a left operand
+			return nil
+		}
+		p.pos++
+		if x := p.parseNot(); x != nil {
+			return notExpr{x}
+		}
+		return nil
+	case tokenOpen:
+		p.pos++
+		expr := p.parseOr()
+		if t, ok := p.peek(); ok && t.kind == tokenClose {
+			p.pos++
+		}
+		return expr
+	case tokenClose:
+		return nil
+	default:
+		p.pos++
+		return termExpr(t.text)
+	}
+}
+
+// joinExprs combines two expressions with an AND or OR, flattening nested
+// ones of the same kind and dropping missing operands
+func joinExprs(kind queryExpr, a, b queryExpr) queryExpr {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	switch kind.(type) {
+	case orExpr:
+		var or orExpr
+		for _, x := range []queryExpr{a, b} {
+			if o, ok := x.(orExpr); ok {
+				or = append(or, o...)
+			} else {
+				or = append(or, x)
+			}
+		}
+		return or
+	default:
+		var and andExpr
+		for _, x := range []queryExpr{a, b} {
+			if o, ok := x.(andExpr); ok {
+				and = append(and, o...)
+			} else {
+				and = append(and, x)
+			}
+		}
+		return and
+	}
+}
+
+// queryExpr is a boolean condition on a chunk's text
+type queryExpr interface {
+	matches(m *textMatcher) bool
+	// positiveTerms appends the terms not under a NOT
+	positiveTerms(terms []string) []string
+}
+
+type termExpr string
+
+func (e termExpr) matches(m *textMatcher) bool           { return m.contains(string(e)) }
+func (e termExpr) positiveTerms(terms []string) []string { return append(terms, string(e)) }
+
+type notExpr struct{ x queryExpr }
+
+func (e notExpr) matches(m *textMatcher) bool           { return !e.x.matches(m) }
+func (e notExpr) positiveTerms(terms []string) []string { return terms }
+
+type andExpr []queryExpr
+
+func (e andExpr) matches(m *textMatcher) bool {
+	for _, x := range e {
+		if !x.matches(m) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e andExpr) positiveTerms(terms []string) []string {
+	for _, x := range e {
+		terms = x.positiveTerms(terms)
+	}
+	return terms
+}
+
+type orExpr []queryExpr
+
+func (e orExpr) matches(m *textMatcher) bool {
+	for _, x := range e {
+		if x.matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e orExpr) positiveTerms(terms []string) []string {
+	for _, x := range e {
+		terms = x.positiveTerms(terms)
+	}
+	return terms
+}
+
+// withQuery parses query, adding its required phrases and boolean
+// condition to the options, and returns the query text to rank by
+func (opts SearchOptions) withQuery(query string) (string, SearchOptions) {
+	text, phrases, expr := parseQuery(query)
+	if len(phrases) > 0 {
+		opts.Phrases = append(append([]string(nil), opts.Phrases...), phrases...)
+	}
+	if expr != nil {
+		opts.condition = joinExprs(andExpr{}, opts.condition, expr)
+	}
+	return text, opts
+}
+
+// matches reports whether chunk contains every required phrase and
+// satisfies the query's boolean condition
+func (opts SearchOptions) matches(chunk string) bool {
+	if len(opts.Phrases) == 0 && opts.condition == nil {
+		return true
+	}
+	m := newTextMatcher(chunk, opts.Language)
+	for _, phrase := range opts.Phrases {
+		if !m.contains(phrase) {
+			return false
+		}
+	}
+	return opts.condition == nil || opts.condition.matches(m)
+}
+
+// textMatcher finds words and phrases in a chunk as whole words. Case, runs
+// of whitespace and, under any normalization the search considers, accents
+// and umlaut spellings are ignored.
+type textMatcher struct {
+	chunk          string
+	normalizations []string
+	normalized     map[string]string
+}
+
+func newTextMatcher(chunk, lang string) *textMatcher {
+	normalizations := chunkNormalizations(lang)
+	return &textMatcher{
+		chunk:          strings.Join(strings.Fields(strings.ToLower(chunk)), " "),
+		normalizations: normalizations,
+		normalized:     make(map[string]string, len(normalizations)),
+	}
+}
+
+func (m *textMatcher) contains(phrase string) bool {
+	phrase = strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	for _, n := range m.normalizations {
+		text, ok := m.normalized[n]
+		if !ok {
+			text = normalize(m.chunk, n)
+			m.normalized[n] = text
+		}
+		if containsTerm(text, normalize(phrase, n)) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryText returns what a query searches for without its search syntax:
+// quotes, operators and terms under NOT removed. It is the text to embed
+// or rerank by.
+func QueryText(query string) string {
+	text, _, _ := parseQuery(query)
+	return text
+}
+
+// HasSearchSyntax reports whether a query uses quoted phrases or boolean
+// operators, asking for exact matches rather than anything related
+func HasSearchSyntax(query string) bool {
+	for _, t := range tokenizeQuery(query) {
+		if t.kind != tokenWord {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQueryPhrases(t *testing.T) {
+	tests := []struct {
+		query   string
+		text    string
+		phrases []string
+	}{
+		{"right to erasure", "right to erasure", nil},
+		{`"right to erasure" children`, "right to erasure children", []string{"right to erasure"}},
+		{`“data  protection officer” and „Recht auf Löschung“`, "data protection officer and Recht auf Löschung", []string{"data protection officer", "Recht auf Löschung"}},
+		{`consent "" withdrawal`, "consent withdrawal", nil},
+		{`unmatched "quote`, "unmatched quote", nil},
+	}
+	for _, tt := range tests {
+		text, phrases, expr := parseQuery(tt.query)
+		if text != tt.text || !reflect.DeepEqual(phrases, tt.phrases) || expr != nil {
+			t.Errorf("parseQuery(%q) = %q, %q, want %q, %q", tt.query, text, phrases, tt.text, tt.phrases)
+		}
+	}
+}
+
+func TestSearchPhrases(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chunks := []string{
+		"The data subject shall have the right to obtain from the controller the erasure of personal data.",
+		"Article 17 grants a right to\nerasure ('right to be forgotten').",
+		"Die betroffene Person hat das Recht auf Löschung.",
+	}
+	ids := make([]int64, len(chunks))
+	for i, chunk := range chunks {
+		id, err := database.InsertChunk(chunk, i)
+		if err != nil {
+			t.Fatalf("Failed to insert chunk: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+		if err := database.InsertEmbedding(id, []float32{1, float32(i)}); err != nil {
+			t.Fatalf("Failed to insert embedding: %v", err)
+		}
+		ids[i] = id
+	}
+
+	// Both chunks share the words, only one has them as a phrase
+	results, err := database.SearchTrigrams(`"right to erasure"`, 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[1] {
+		t.Errorf("Expected only the chunk with the phrase, got %+v", results)
+	}
+
+	results, err = database.HybridSearch(`"right to erasure"`, []float32{1, 0}, 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[1] {
+		t.Errorf("Expected vector results without the phrase to be dropped, got %+v", results)
+	}
+
+	results, err = database.SearchTrigrams(`"recht auf loeschung"`, 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[2] {
+		t.Errorf("Expected the umlaut spelling to match the phrase, got %+v", results)
+	}
+
+	results, err = database.SearchTrigrams("erasure", 10, SearchOptions{Phrases: []string{"right to erasure"}})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[1] {
+		t.Errorf("Expected the Phrases option to filter like a quoted phrase, got %+v", results)
+	}
+}
+
+func TestParseQueryBoolean(t *testing.T) {
+	texts := map[string]string{
+		"children":  "Consent of children in relation to information society services.",
+		"marketing": "Consent for direct marketing to children may be withdrawn.",
+		"erasure":   "The right to erasure applies where consent is withdrawn under Article 6(1)(a).",
+		"breach":    "The controller shall notify a personal data breach.",
+	}
+	tests := []struct {
+		query   string
+		text    string
+		matches []string
+	}{
+		{"consent AND children NOT marketing", "consent children", []string{"children"}},
+		{"consent children AND NOT marketing", "consent children", []string{"children"}},
+		{"breach OR erasure", "breach erasure", []string{"erasure", "breach"}},
+		{"(breach OR erasure) AND consent", "breach erasure consent", []string{"erasure"}},
+		{`"right to erasure" OR "data breach"`, "right to erasure data breach", []string{"erasure", "breach"}},
+		{"Article 6(1)(a) AND consent", "Article 6(1)(a) consent", []string{"erasure"}},
+		{"NOT consent", "", []string{"breach"}},
+		{"(consent AND NOT children) OR breach)", "consent breach", []string{"erasure", "breach"}},
+		{"consent AND", "consent", []string{"children", "marketing", "erasure"}},
+	}
+	for _, tt := range tests {
+		text, phrases, expr := parseQuery(tt.query)
+		if text != tt.text || phrases != nil || expr == nil {
+			t.Errorf("parseQuery(%q) = %q, %q, %v, want text %q and a condition", tt.query, text, phrases, expr, tt.text)
+			continue
+		}
+		var matches []string
+		for _, name := range []string{"children", "marketing", "erasure", "breach"} {
+			if expr.matches(newTextMatcher(texts[name], "")) {
+				matches = append(matches, name)
+			}
+		}
+		if !reflect.DeepEqual(matches, tt.matches) {
+			t.Errorf("%q matches %v, want %v", tt.query, matches, tt.matches)
+		}
+	}
+
+	if text := QueryText(`"right to erasure" AND NOT marketing`); text != "right to erasure" {
+		t.Errorf("QueryText = %q, want the terms without syntax", text)
+	}
+
+	for query, want := range map[string]bool{
+		"can we keep emails forever?":   false,
+		"Article 6(1)(a)":               false,
+		`"right to erasure"`:            true,
+		"consent NOT marketing":         true,
+		"(consent OR children) records": true,
+	} {
+		if got := HasSearchSyntax(query); got != want {
+			t.Errorf("HasSearchSyntax(%q) = %v, want %v", query, got, want)
+		}
+	}
+
+	// Lower-case operators are ordinary words
+	if _, _, expr := parseQuery("terms and conditions or consent"); expr != nil {
+		t.Errorf("Expected no condition for lower-case operators, got %v", expr)
+	}
+}
+
+func TestSearchBoolean(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chunks := []string{
+		"Where consent is given by children, the processing shall be lawful.",
+		"Consent of children to direct marketing shall be verified.",
+		"Consent shall be freely given.",
+	}
+	ids := make([]int64, len(chunks))
+	for i, chunk := range chunks {
+		id, err := database.InsertChunk(chunk, i)
+		if err != nil {
+			t.Fatalf("Failed to insert chunk: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+		if err := database.InsertEmbedding(id, []float32{1, float32(i)}); err != nil {
+			t.Fatalf("Failed to insert embedding: %v", err)
+		}
+		ids[i] = id
+	}
+
+	for _, search := range []func() ([]SearchResult, error){
+		func() ([]SearchResult, error) {
+			return database.SearchTrigrams("consent AND children NOT marketing", 10, SearchOptions{})
+		},
+		func() ([]SearchResult, error) {
+			return database.HybridSearch("consent AND children NOT marketing", []float32{1, 1}, 10, SearchOptions{})
+		},
+	} {
+		results, err := search()
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].ID != ids[0] {
+			t.Errorf("Expected only the chunk about children without marketing, got %+v", results)
+		}
+	}
+}
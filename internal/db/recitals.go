@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Recital is the full text of a recital, reassembled from its chunks
+type Recital struct {
+	Number     string  `json:"recital"`
+	Collection string  `json:"collection,omitempty"`
+	ChunkIDs   []int64 `json:"chunk_ids"`
+	Text       string  `json:"text"`
+}
+
+// Recital returns the recital with the given number from a collection, or
+// nil if it was not ingested. The recital is taken from the source the
+// first of its chunks was ingested from.
+func (db *DB) Recital(number, collection string) (*Recital, error) {
+	var source string
+	err := db.conn.QueryRow(`
+		SELECT source FROM documents
+		WHERE deleted_at IS NULL AND collection = ? AND json_extract(metadata, '$.recital') = ?
+		ORDER BY id LIMIT 1`, collection, number).Scan(&source)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recital: %w", err)
+	}
+
+	text, ids, err := db.provisionText(source, collection, "recital", number)
+	if err != nil {
+		return nil, err
+	}
+	return &Recital{Number: number, Collection: collection, ChunkIDs: ids, Text: text}, nil
+}
+
+// ArticleRecitals returns the numbers of the recitals associated with an
+// article in the article_recitals table, in ascending order
+func (db *DB) ArticleRecitals(article string) ([]string, error) {
+	rows, err := db.conn.Query(
+		"SELECT recital FROM article_recitals WHERE article = ? ORDER BY CAST(recital AS INTEGER)",
+		article,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query article recitals: %w", err)
+	}
+	defer rows.Close()
+
+	var recitals []string
+	for rows.Next() {
+		var recital string
+		if err := rows.Scan(&recital); err != nil {
+			return nil, fmt.Errorf("failed to scan recital: %w", err)
+		}
+		recitals = append(recitals, recital)
+	}
+	return recitals, rows.Err()
+}
+
+// AddArticleRecital associates a recital with an article, so that it is
+// returned among the article's recitals
+func (db *DB) AddArticleRecital(article, recital string) error {
+	number, ok := ArticleNumber(article)
+	if !ok {
+		return fmt.Errorf("invalid article %q", article)
+	}
+	recitalNumber, ok := RecitalNumber(recital)
+	if !ok {
+		return fmt.Errorf("invalid recital %q", recital)
+	}
+	if _, err := db.conn.Exec(
+		"INSERT OR IGNORE INTO article_recitals (article, recital) VALUES (?, ?)",
+		number, recitalNumber,
+	); err != nil {
+		return fmt.Errorf("failed to add article recital: %w", err)
+	}
+	return nil
+}
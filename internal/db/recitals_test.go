@@ -0,0 +1,88 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecital(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	recital26 := map[string]string{"recital": "26"}
+	for i, d := range []Document{
+		{Chunk: "(26) The principles of data protection should apply to any information concerning an identified person.", Source: "gdpr.txt", Metadata: recital26},
+		{Chunk: "The principles of data protection should therefore not apply to anonymous information.", Source: "gdpr.txt", Metadata: recital26},
+		{Chunk: "(26) Die Grundsätze des Datenschutzes sollten für alle Informationen gelten.", Source: "dsgvo.txt", Collection: "de", Metadata: recital26},
+	} {
+		d.ChunkIndex = i
+		if _, err := database.InsertDocument(d); err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+	}
+
+	recital, err := database.Recital("26", "")
+	if err != nil {
+		t.Fatalf("Recital failed: %v", err)
+	}
+	if recital == nil || len(recital.ChunkIDs) != 2 ||
+		recital.Text != "(26) The principles of data protection should apply to any information concerning an identified person.\nThe principles of data protection should therefore not apply to anonymous information." {
+		t.Errorf("Expected recital 26 joined from both chunks, got %+v", recital)
+	}
+	if recital, _ := database.Recital("26", "de"); recital == nil || recital.Collection != "de" || len(recital.ChunkIDs) != 1 {
+		t.Errorf("Expected recital 26 of the de collection, got %+v", recital)
+	}
+	if recital, err := database.Recital("27", ""); err != nil || recital != nil {
+		t.Errorf("Expected no recital 27, got %+v, %v", recital, err)
+	}
+}
+
+func TestArticleRecitals(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	recitals, err := database.ArticleRecitals("17")
+	if err != nil {
+		t.Fatalf("ArticleRecitals failed: %v", err)
+	}
+	if !reflect.DeepEqual(recitals, []string{"65", "66"}) {
+		t.Errorf("Expected recitals 65 and 66 for Article 17, got %v", recitals)
+	}
+
+	if err := database.AddArticleRecital("Art. 17", "(156)"); err != nil {
+		t.Fatalf("AddArticleRecital failed: %v", err)
+	}
+	if recitals, _ := database.ArticleRecitals("17"); !reflect.DeepEqual(recitals, []string{"65", "66", "156"}) {
+		t.Errorf("Expected recitals in numeric order, got %v", recitals)
+	}
+	if err := database.AddArticleRecital("17", "recital"); err == nil {
+		t.Error("Expected an invalid recital to be rejected")
+	}
+}
+
+func TestRecitalWindowChunks(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	recital38 := "(38) Children merit specific protection with regard to their personal data, as they may be less aware of the risks."
+	text := "(37) A group of undertakings should cover a controlling undertaking and its controlled undertakings.\n" +
+		recital38 + "\nHAVE ADOPTED THIS REGULATION:\nCHAPTER I\nGeneral provisions\nArticle 1\nSubject-matter and objectives"
+	insertWindowed(t, database, "gdpr.txt", text, 70, 25, map[string]map[string]string{
+		"(37)":      {"recital": "37"},
+		"(38)":      {"recital": "38"},
+		"HAVE":      nil,
+		"Article 1": {"article": "1"},
+	})
+
+	recital, err := database.Recital("38", "")
+	if err != nil {
+		t.Fatalf("Recital failed: %v", err)
+	}
+	if recital == nil || recital.Text != recital38 {
+		t.Fatalf("Expected recital 38 from its heading to the next one, got %+v", recital)
+	}
+	if recital37, _ := database.Recital("37", ""); recital37 == nil || !strings.HasPrefix(recital37.Text, "(37)") || strings.Contains(recital37.Text, "(38)") {
+		t.Errorf("Expected recital 37 alone, got %+v", recital37)
+	}
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Reindex rebuilds the trigram posting lists and keyphrases of every chunk
+// from its stored text in one transaction, for when trigram normalization
+// or keyphrase extraction has changed since the chunks were ingested.
+// Soft-deleted chunks stay indexed until purged, as after ingestion. It
+// returns the number of chunks reindexed.
+func (db *DB) Reindex() (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, chunk, language FROM documents ORDER BY id")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query documents: %w", err)
+	}
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		if err := rows.Scan(&doc.ID, &doc.Chunk, &doc.Language); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, stmt := range []string{"DELETE FROM trigram_postings", "DELETE FROM keyphrases"} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return 0, fmt.Errorf("failed to clear index: %w", err)
+		}
+	}
+
+	// Documents are read in ID order, so every posting list is built in
+	// the ascending order encodePostings expects
+	postings := make(map[string][]int64)
+	for _, doc := range docs {
+		for _, trigram := range LanguageTrigrams(doc.Chunk, doc.Language) {
+			ids := postings[trigram]
+			if len(ids) == 0 || ids[len(ids)-1] != doc.ID {
+				postings[trigram] = append(ids, doc.ID)
+			}
+		}
+		if err := addKeyphrases(tx, doc.ID, ExtractKeyphrases(doc.Chunk)); err != nil {
+			return 0, fmt.Errorf("failed to index document %d: %w", doc.ID, err)
+		}
+	}
+	trigrams := make([]string, 0, len(postings))
+	for trigram := range postings {
+		trigrams = append(trigrams, trigram)
+	}
+	sort.Strings(trigrams)
+	for _, trigram := range trigrams {
+		ids := postings[trigram]
+		if _, err := tx.Exec(
+			"INSERT INTO trigram_postings (trigram, doc_count, last_doc_id, postings) VALUES (?, ?, ?, ?)",
+			trigram, len(ids), ids[len(ids)-1], encodePostings(ids),
+		); err != nil {
+			return 0, fmt.Errorf("failed to write posting list: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit reindex: %w", err)
+	}
+	return len(docs), nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import "testing"
+
+func TestReindex(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// A chunk inserted without an index is only found after reindexing
+	unindexed, err := database.InsertChunk("The controller shall designate a data protection officer", 0)
+	if err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+	indexed := insertSearchable(t, database, "Right to erasure of personal data")
+
+	results, err := database.SearchTrigrams("data protection officer", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	for _, r := range results {
+		if r.ID == unindexed {
+			t.Fatal("Expected the unindexed chunk not to be found before reindexing")
+		}
+	}
+
+	n, err := database.Reindex()
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 chunks reindexed, got %d", n)
+	}
+
+	for query, want := range map[string]int64{"data protection officer": unindexed, "erasure": indexed} {
+		results, err := database.SearchTrigrams(query, 10, SearchOptions{})
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		if len(results) == 0 || results[0].ID != want {
+			t.Errorf("Expected chunk %d first for %q after reindexing, got %+v", want, query, results)
+		}
+	}
+	phrases, err := database.Keyphrases(unindexed)
+	if err != nil {
+		t.Fatalf("Keyphrases failed: %v", err)
+	}
+	if len(phrases) == 0 {
+		t.Error("Expected keyphrases to be extracted when reindexing")
+	}
+
+	// Reindexing again leaves the same index
+	before, err := database.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if _, err := database.Reindex(); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	after, err := database.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if before.Trigrams != after.Trigrams {
+		t.Errorf("Expected %d trigrams after reindexing again, got %d", before.Trigrams, after.Trigrams)
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoEmbedding is returned when related chunks are sought for chunks
+// without embeddings
+var ErrNoEmbedding = errors.New("no embedding stored")
+
+// Related returns the live chunks most similar to the given chunks by
+// cosine similarity to the mean of their embeddings, leaving out the chunks
+// themselves, for exploring connected provisions. Chunks without an
+// embedding are ignored; if none has one, Related returns ErrNoEmbedding.
+func (db *DB) Related(ids []int64, limit int, opts SearchOptions) ([]SearchResult, error) {
+	embeddings, err := db.loadEmbeddings(ids)
+	if err != nil {
+		return nil, err
+	}
+	var centroid []float32
+	for _, id := range ids {
+		embedding, ok := embeddings[id]
+		if !ok {
+			continue
+		}
+		if centroid == nil {
+			centroid = make([]float32, len(embedding))
+		}
+		if len(embedding) != len(centroid) {
+			return nil, fmt.Errorf("embedding of document %d has %d dimensions, expected %d", id, len(embedding), len(centroid))
+		}
+		for i, v := range embedding {
+			centroid[i] += v
+		}
+	}
+	// The sum points the same way as the mean, which is all cosine
+	// similarity looks at
+	if centroid == nil {
+		return nil, ErrNoEmbedding
+	}
+
+	exclude := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		exclude[id] = true
+	}
+	candidates, err := db.SearchVectors(centroid, limit+len(exclude), opts)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, limit)
+	for _, r := range candidates {
+		if exclude[r.ID] {
+			continue
+		}
+		if len(results) == limit {
+			break
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// ArticleChunkIDs returns the IDs of the live chunks of an article in a
+// collection, in order
+func (db *DB) ArticleChunkIDs(article, collection string) ([]int64, error) {
+	rows, err := db.conn.Query(`
+		SELECT id FROM documents
+		WHERE deleted_at IS NULL AND collection = ? AND json_extract(metadata, '$.article') = ?
+		ORDER BY source, chunk_index, id`, collection, article)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query article chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRelated(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for i, d := range []struct {
+		article   string
+		embedding []float32
+	}{
+		{"5", []float32{1, 0, 0}},
+		{"5", []float32{0, 1, 0}},
+		{"6", []float32{0.9, 0.1, 0}},
+		{"7", []float32{0.7, 0.7, 0}},
+		{"8", []float32{0, 0, 1}},
+	} {
+		id, err := database.InsertDocument(Document{Chunk: "chunk", ChunkIndex: i, Metadata: map[string]string{"article": d.article}})
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertEmbedding(id, d.embedding); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	resultIDs := func(results []SearchResult) []int64 {
+		var got []int64
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		return got
+	}
+
+	results, err := database.Related([]int64{ids[0]}, 2, SearchOptions{})
+	if err != nil {
+		t.Fatalf("Related failed: %v", err)
+	}
+	if got := resultIDs(results); !reflect.DeepEqual(got, []int64{ids[2], ids[3]}) {
+		t.Errorf("Expected the chunks closest to the first, without it, got %v", got)
+	}
+
+	article, err := database.ArticleChunkIDs("5", "")
+	if err != nil {
+		t.Fatalf("ArticleChunkIDs failed: %v", err)
+	}
+	if !reflect.DeepEqual(article, ids[:2]) {
+		t.Fatalf("Expected both chunks of Article 5, got %v", article)
+	}
+	results, err = database.Related(article, 1, SearchOptions{})
+	if err != nil {
+		t.Fatalf("Related failed: %v", err)
+	}
+	if got := resultIDs(results); !reflect.DeepEqual(got, []int64{ids[3]}) {
+		t.Errorf("Expected the chunk closest to the whole article, got %v", got)
+	}
+
+	// Chunks without embeddings have no related chunks
+	id, err := database.InsertDocument(Document{Chunk: "no embedding", Metadata: map[string]string{}})
+	if err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+	if results, err := database.Related([]int64{id}, 5, SearchOptions{}); !errors.Is(err, ErrNoEmbedding) {
+		t.Errorf("Expected ErrNoEmbedding, got %v, %v", results, err)
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import "strings"
+
+// setRelevance gives each result its relevance between 0 and 1: the share
+// of the query's trigrams that occur in the chunk and the chunk's cosine
+// similarity to the query embedding (negative counting as 0), averaged by
+// the fusion weights. Unlike fused scores, which depend on the rank among
+// the other results, relevance only depends on the query and the chunk, so
+// thresholds on it keep their meaning as the corpus grows. Without a query
+// embedding relevance is the trigram share alone.
+func (db *DB) setRelevance(results []SearchResult, query string, queryEmbedding []float32, fusion Fusion, opts SearchOptions) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var embeddings map[int64][]float32
+	if queryEmbedding != nil && fusion.VectorWeight > 0 {
+		ids := make([]int64, len(results))
+		for i, r := range results {
+			ids[i] = r.ID
+		}
+		var err error
+		if embeddings, err = db.loadEmbeddings(ids); err != nil {
+			return err
+		}
+	}
+
+	normalizations := chunkNormalizations(opts.Language)
+	for i := range results {
+		keyword := trigramShare(query, results[i].chunk, normalizations)
+		if embeddings == nil {
+			results[i].relevance = keyword
+			continue
+		}
+		similarity := 0.0
+		if embedding, ok := embeddings[results[i].ID]; ok {
+			similarity = max(CosineSimilarity(queryEmbedding, embedding), 0)
+		}
+		results[i].relevance = (fusion.KeywordWeight*keyword + fusion.VectorWeight*similarity) /
+			(fusion.KeywordWeight + fusion.VectorWeight)
+	}
+	return nil
+}
+
+// trigramShare returns the share of the query's trigrams found in chunk,
+// normalizing both alike under the normalization that finds the most
+func trigramShare(query, chunk string, normalizations []string) float64 {
+	best := 0.0
+	for _, n := range normalizations {
+		queryTrigrams := GenerateTrigrams(normalize(strings.ToLower(query), n))
+		if len(queryTrigrams) == 0 {
+			continue
+		}
+		chunkTrigrams := trigramSet(normalize(strings.ToLower(chunk), n))
+		found := 0
+		for _, t := range queryTrigrams {
+			if chunkTrigrams[t] {
+				found++
+			}
+		}
+		best = max(best, float64(found)/float64(len(queryTrigrams)))
+	}
+	return best
+}
+
+// NormalizeScores returns copies of results scored by their relevance
+// between 0 and 1, as set by HybridSearch, with the score they were ranked
+// by kept as RawScore. The order is unchanged.
+func NormalizeScores(results []SearchResult) []SearchResult {
+	normalized := make([]SearchResult, len(results))
+	for i, r := range results {
+		r.RawScore = r.Score
+		r.Score = r.relevance
+		normalized[i] = r
+	}
+	return normalized
+}
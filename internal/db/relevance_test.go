@@ -0,0 +1,81 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigramShare(t *testing.T) {
+	none := []string{normalizeNone}
+	if got := trigramShare("erasure", "Right to erasure", none); got != 1 {
+		t.Errorf("Expected every trigram found, got %v", got)
+	}
+	if got := trigramShare("erasure", "Right of access", none); got != 0 {
+		t.Errorf("Expected no trigram found, got %v", got)
+	}
+	// "löschung" written without the umlaut still matches under the German
+	// normalization
+	if got := trigramShare("loeschung", "Recht auf Löschung", chunkNormalizations("")); got != 1 {
+		t.Errorf("Expected the German normalization to match, got %v", got)
+	}
+}
+
+func TestHybridSearchRelevance(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	insert := func(text string, embedding []float32) int64 {
+		t.Helper()
+		id, err := database.InsertChunk(text, 0)
+		if err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(text)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		if err := database.InsertEmbedding(id, embedding); err != nil {
+			t.Fatalf("InsertEmbedding failed: %v", err)
+		}
+		return id
+	}
+	erasure := insert("Right to erasure", []float32{1, 0, 0})
+	access := insert("Right of access", []float32{0, 1, 0})
+
+	relevance := func() map[int64]SearchResult {
+		t.Helper()
+		results, err := database.HybridSearch("right to erasure", []float32{1, 0, 0}, 10, SearchOptions{})
+		if err != nil {
+			t.Fatalf("HybridSearch failed: %v", err)
+		}
+		normalized := NormalizeScores(results)
+		byID := make(map[int64]SearchResult)
+		for i, r := range normalized {
+			if r.RawScore != results[i].Score || r.ID != results[i].ID {
+				t.Fatalf("Expected the ranking score kept as raw score in order, got %+v", normalized)
+			}
+			if r.Score < 0 || r.Score > 1 {
+				t.Errorf("Expected relevance between 0 and 1, got %v", r.Score)
+			}
+			byID[r.ID] = r
+		}
+		return byID
+	}
+
+	before := relevance()
+	if r := before[erasure]; math.Abs(r.Score-1) > 1e-9 {
+		t.Errorf("Expected an exact keyword and vector match to score 1, got %+v", r)
+	}
+
+	// Better matches added later push a result down and change its fused
+	// score, but not its relevance
+	for i := 0; i < 5; i++ {
+		insert("Right to erasure and rectification", []float32{0.9, 0.1, 0})
+	}
+	after := relevance()
+	if after[access].RawScore == before[access].RawScore {
+		t.Fatalf("Expected the fused score to change with the corpus")
+	}
+	if after[access].Score != before[access].Score {
+		t.Errorf("Expected relevance %v to survive corpus growth, got %v", before[access].Score, after[access].Score)
+	}
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Reranker scores documents by their relevance to a query, typically with a
+// cross-encoder reading the query and each document together. Scores are
+// returned in document order; higher is more relevant.
+type Reranker interface {
+	Rerank(query string, documents []string) ([]float64, error)
+}
+
+// Rerank re-scores search results with reranker and returns them sorted by
+// the new scores. Results the reranker scores equally keep their order.
+func Rerank(reranker Reranker, query string, results []SearchResult) ([]SearchResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	documents := make([]string, len(results))
+	for i, r := range results {
+		documents[i] = r.chunk
+		if documents[i] == "" {
+			documents[i] = r.Snippet
+		}
+	}
+	scores, err := reranker.Rerank(query, documents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rerank results: %w", err)
+	}
+	if len(scores) != len(results) {
+		return nil, fmt.Errorf("reranker returned %d scores for %d results", len(scores), len(results))
+	}
+
+	reranked := make([]SearchResult, len(results))
+	copy(reranked, results)
+	for i := range reranked {
+		reranked[i].Score = scores[i]
+		if x := reranked[i].Explain; x != nil {
+			explain := *x
+			explain.RerankScore = &scores[i]
+			reranked[i].Explain = &explain
+		}
+	}
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].Score > reranked[j].Score
+	})
+	return reranked, nil
+}
+
+// RerankTop reranks the first n results with reranker and ranks the rest
+// after them in their order. The rest are scored just below the lowest
+// reranked score, so that scores keep following the ranking.
+func RerankTop(reranker Reranker, query string, results []SearchResult, n int) ([]SearchResult, error) {
+	n = min(n, len(results))
+	reranked, err := Rerank(reranker, query, results[:n])
+	if err != nil || n == len(results) {
+		return reranked, err
+	}
+
+	lowest := 0.0
+	if n > 0 {
+		lowest = reranked[n-1].Score
+	}
+	for i, r := range results[n:] {
+		r.Score = lowest - float64(i+1)*rerankTailStep
+		reranked = append(reranked, r)
+	}
+	return reranked, nil
+}
+
+// rerankTailStep separates the scores of results ranked after the reranked
+// ones
+const rerankTailStep = 1e-6
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// lengthReranker scores shorter documents higher
+type lengthReranker struct{ err error }
+
+func (l lengthReranker) Rerank(query string, documents []string) ([]float64, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	scores := make([]float64, len(documents))
+	for i, d := range documents {
+		scores[i] = 1 / float64(len(d))
+	}
+	return scores, nil
+}
+
+func TestRerank(t *testing.T) {
+	results := []SearchResult{
+		{ID: 1, Score: 0.9, chunk: "Article 17 Right to erasure ('right to be forgotten')"},
+		{ID: 2, Score: 0.5, chunk: "Right to erasure"},
+		{ID: 3, Score: 0.1, chunk: "Erasure of personal data"},
+	}
+
+	reranked, err := Rerank(lengthReranker{}, "erasure", results)
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if reranked[0].ID != 2 || reranked[1].ID != 3 || reranked[2].ID != 1 {
+		t.Errorf("Expected results reordered by reranker score, got %+v", reranked)
+	}
+	if reranked[0].Score != 1.0/16 {
+		t.Errorf("Expected the reranker score, got %v", reranked[0].Score)
+	}
+	if results[0].ID != 1 || results[0].Score != 0.9 {
+		t.Errorf("Rerank modified its input: %+v", results)
+	}
+
+	_, err = Rerank(lengthReranker{err: errors.New("model not loaded")}, "erasure", results)
+	if err == nil || !strings.Contains(err.Error(), "model not loaded") {
+		t.Errorf("Expected the reranker error, got %v", err)
+	}
+}
+
+func TestRerankTop(t *testing.T) {
+	results := []SearchResult{
+		{ID: 1, Score: 0.9, chunk: "Article 17 Right to erasure ('right to be forgotten')"},
+		{ID: 2, Score: 0.5, chunk: "Right to erasure"},
+		{ID: 3, Score: 0.4, chunk: "Erasure"},
+		{ID: 4, Score: 0.1, chunk: "Erasure of data"},
+	}
+
+	reranked, err := RerankTop(lengthReranker{}, "erasure", results, 2)
+	if err != nil {
+		t.Fatalf("RerankTop failed: %v", err)
+	}
+	var ids []int64
+	for _, r := range reranked {
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 4 || ids[0] != 2 || ids[1] != 1 || ids[2] != 3 || ids[3] != 4 {
+		t.Errorf("Expected the top 2 reranked and the rest after them in order, got %v", ids)
+	}
+	for i := 1; i < len(reranked); i++ {
+		if reranked[i].Score >= reranked[i-1].Score {
+			t.Errorf("Expected decreasing scores, got %+v", reranked)
+		}
+	}
+}
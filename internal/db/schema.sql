@@ -6,20 +6,26 @@ CREATE TABLE IF NOT EXISTS documents (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     chunk TEXT NOT NULL,
     chunk_index INTEGER NOT NULL,
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    language TEXT NOT NULL DEFAULT '',
+    metadata TEXT NOT NULL DEFAULT '{}', -- JSON object of string attributes (page, heading, ...)
+    source TEXT NOT NULL DEFAULT '', -- file the chunk was ingested from, if any
+    collection TEXT NOT NULL DEFAULT '', -- named corpus such as one language version of the regulation
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME -- tombstone: excluded from search until purged
 );
 
--- Trigram index for text search
-CREATE TABLE IF NOT EXISTS trigrams (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    trigram TEXT NOT NULL,
-    doc_id INTEGER NOT NULL,
-    FOREIGN KEY (doc_id) REFERENCES documents(id) ON DELETE CASCADE
-);
+CREATE INDEX IF NOT EXISTS idx_documents_language ON documents(language);
+CREATE INDEX IF NOT EXISTS idx_documents_source ON documents(source, chunk_index);
+CREATE INDEX IF NOT EXISTS idx_documents_collection ON documents(collection);
 
--- Index for faster trigram lookups
-CREATE INDEX IF NOT EXISTS idx_trigrams_trigram ON trigrams(trigram);
-CREATE INDEX IF NOT EXISTS idx_trigrams_doc_id ON trigrams(doc_id);
+-- Trigram index for text search: one row per trigram with a compressed
+-- posting list of ascending document IDs (delta + uvarint encoded)
+CREATE TABLE IF NOT EXISTS trigram_postings (
+    trigram TEXT PRIMARY KEY,
+    doc_count INTEGER NOT NULL,
+    last_doc_id INTEGER NOT NULL,
+    postings BLOB NOT NULL
+) WITHOUT ROWID;
 
 -- Vector embeddings table (stores as JSON float array or blob)
 CREATE TABLE IF NOT EXISTS embeddings (
@@ -28,8 +34,63 @@ CREATE TABLE IF NOT EXISTS embeddings (
     FOREIGN KEY (doc_id) REFERENCES documents(id) ON DELETE CASCADE
 );
 
+-- Salient phrases extracted from each chunk at ingest, for boosting
+-- results whose keyphrases occur in the query
+CREATE TABLE IF NOT EXISTS keyphrases (
+    doc_id INTEGER NOT NULL,
+    phrase TEXT NOT NULL,
+    PRIMARY KEY (doc_id, phrase),
+    FOREIGN KEY (doc_id) REFERENCES documents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_keyphrases_phrase ON keyphrases(phrase);
+
 -- Metadata table for tracking ingestion state
 CREATE TABLE IF NOT EXISTS metadata (
     key TEXT PRIMARY KEY,
     value TEXT NOT NULL
 );
+
+-- Ingestion progress per source, so an interrupted ingestion can resume
+CREATE TABLE IF NOT EXISTS checkpoints (
+    source TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL, -- hash of the chunks being ingested and their settings
+    next_chunk INTEGER NOT NULL, -- index of the first chunk not yet stored
+    total_chunks INTEGER NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Files ingested from directories, so unchanged files can be skipped and
+-- chunks of deleted files removed
+CREATE TABLE IF NOT EXISTS source_files (
+    source TEXT PRIMARY KEY,
+    hash TEXT NOT NULL, -- SHA-256 of the file content
+    size INTEGER NOT NULL,
+    mod_time DATETIME NOT NULL,
+    ingested_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Synonyms used to expand search queries (matched in both directions)
+CREATE TABLE IF NOT EXISTS synonyms (
+    term TEXT NOT NULL,
+    synonym TEXT NOT NULL,
+    PRIMARY KEY (term, synonym)
+);
+
+-- Practitioner shorthand mapped to the wording of the GDPR and the article
+-- it is about, applied one way before keyword search. Either the expansion
+-- or the article may be empty.
+CREATE TABLE IF NOT EXISTS term_map (
+    term TEXT NOT NULL,
+    expansion TEXT NOT NULL DEFAULT '',
+    article TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (term, expansion, article)
+);
+
+-- Recitals that explain each article, for reading an article in its
+-- interpretive context. Both are stored as numbers without prefix.
+CREATE TABLE IF NOT EXISTS article_recitals (
+    article TEXT NOT NULL,
+    recital TEXT NOT NULL,
+    PRIMARY KEY (article, recital)
+);
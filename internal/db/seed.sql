@@ -0,0 +1,122 @@
+-- GDPR MCP Server Seed Data
+-- Curated entries inserted by Migrate only when the schema version changes,
+-- so entries deleted afterwards stay deleted
+
+-- Retired seeds: "dpa" also abbreviates "data processing agreement"
+DELETE FROM synonyms WHERE term = 'dpa' AND synonym = 'supervisory authority';
+
+-- GDPR-specific seed synonyms
+INSERT OR IGNORE INTO synonyms (term, synonym) VALUES
+    ('right to be forgotten', 'erasure'),
+    ('dpo', 'data protection officer'),
+    ('dpia', 'data protection impact assessment'),
+    ('data protection authority', 'supervisory authority'),
+    ('data breach', 'personal data breach'),
+    ('sensitive data', 'special categories of personal data'),
+    ('bcr', 'binding corporate rules'),
+    ('scc', 'standard contractual clauses'),
+    ('eea', 'european economic area'),
+    ('opt out', 'right to object'),
+    ('pseudonymization', 'pseudonymisation'),
+    ('anonymization', 'anonymisation');
+
+-- Curated GDPR term map
+INSERT OR IGNORE INTO term_map (term, expansion, article) VALUES
+    ('sar', 'subject access request', '15'),
+    ('sar', 'right of access', '15'),
+    ('dsar', 'subject access request', '15'),
+    ('dsar', 'right of access', '15'),
+    ('subject access request', 'right of access', '15'),
+    ('access request', 'right of access', '15'),
+    ('rectification', '', '16'),
+    ('right to be forgotten', '', '17'),
+    ('erasure', '', '17'),
+    ('restriction of processing', '', '18'),
+    ('portability', '', '20'),
+    ('right to object', '', '21'),
+    ('opt out', '', '21'),
+    ('profiling', 'automated individual decision-making', '22'),
+    ('automated decision', 'automated individual decision-making', '22'),
+    ('lawful basis', 'lawfulness of processing', '6'),
+    ('legal basis', 'lawfulness of processing', '6'),
+    ('legitimate interest', 'legitimate interests', '6'),
+    ('lia', 'legitimate interests assessment', '6'),
+    ('lia', 'legitimate interests', '6'),
+    ('cookie consent', 'consent', '7'),
+    ('cookie consent', 'eprivacy', ''),
+    ('cookie consent', 'terminal equipment', ''),
+    ('cookie banner', 'consent', '7'),
+    ('cookies', 'terminal equipment', ''),
+    ('parental consent', 'consent of a child', '8'),
+    ('child consent', 'consent of a child', '8'),
+    ('sensitive data', '', '9'),
+    ('criminal records', 'criminal convictions and offences', '10'),
+    ('privacy notice', 'information to be provided', '13'),
+    ('privacy notice', 'transparent information', '12'),
+    ('privacy policy', 'information to be provided', '13'),
+    ('privacy by design', 'data protection by design', '25'),
+    ('privacy by default', 'data protection by default', '25'),
+    ('joint controllers', '', '26'),
+    ('eu representative', 'representatives of controllers or processors', '27'),
+    ('data processing agreement', 'processor', '28'),
+    ('processor agreement', 'processor', '28'),
+    ('subprocessor', 'another processor', '28'),
+    ('ropa', 'records of processing activities', '30'),
+    ('records of processing', '', '30'),
+    ('toms', 'technical and organisational measures', '32'),
+    ('security measures', 'security of processing', '32'),
+    ('breach notification', 'notification of a personal data breach', '33'),
+    ('72 hours', 'notification of a personal data breach', '33'),
+    ('data breach', '', '33'),
+    ('dpia', '', '35'),
+    ('dpo', '', '37'),
+    ('codes of conduct', '', '40'),
+    ('certification', '', '42'),
+    ('international transfer', 'transfers of personal data to third countries', '44'),
+    ('third country transfer', 'transfers of personal data to third countries', '44'),
+    ('adequacy', 'adequacy decision', '45'),
+    ('scc', 'appropriate safeguards', '46'),
+    ('bcr', '', '47'),
+    ('one stop shop', 'lead supervisory authority', '56'),
+    ('lead authority', 'lead supervisory authority', '56'),
+    ('compensation', 'right to compensation and liability', '82'),
+    ('damages', 'right to compensation and liability', '82'),
+    ('fines', 'administrative fines', '83'),
+    ('penalties', 'administrative fines', '83');
+
+-- Curated GDPR article-recital associations
+INSERT OR IGNORE INTO article_recitals (article, recital) VALUES
+    ('1', '1'), ('1', '2'), ('1', '3'), ('1', '4'), ('1', '5'), ('1', '6'),
+    ('1', '7'), ('1', '8'), ('1', '9'), ('1', '10'), ('1', '11'), ('1', '12'),
+    ('1', '13'), ('1', '14'), ('2', '14'), ('2', '15'), ('2', '16'),
+    ('2', '17'), ('2', '18'), ('2', '19'), ('2', '20'), ('2', '21'),
+    ('3', '22'), ('3', '23'), ('3', '24'), ('3', '25'), ('4', '26'),
+    ('4', '27'), ('4', '28'), ('4', '29'), ('4', '30'), ('4', '31'),
+    ('4', '32'), ('4', '33'), ('4', '34'), ('4', '35'), ('4', '36'),
+    ('4', '37'), ('5', '39'), ('5', '74'), ('6', '39'), ('6', '40'),
+    ('6', '41'), ('6', '42'), ('6', '43'), ('6', '44'), ('6', '45'),
+    ('6', '46'), ('6', '47'), ('6', '48'), ('6', '49'), ('6', '50'),
+    ('7', '32'), ('7', '33'), ('7', '42'), ('7', '43'), ('8', '38'),
+    ('9', '46'), ('9', '51'), ('9', '52'), ('9', '53'), ('9', '54'),
+    ('9', '55'), ('9', '56'), ('10', '19'), ('10', '50'), ('11', '57'),
+    ('11', '64'), ('12', '11'), ('12', '58'), ('12', '59'), ('12', '60'),
+    ('12', '73'), ('13', '60'), ('13', '61'), ('13', '62'), ('14', '60'),
+    ('14', '61'), ('14', '62'), ('15', '63'), ('15', '64'), ('16', '65'),
+    ('17', '65'), ('17', '66'), ('18', '67'), ('20', '68'), ('21', '69'),
+    ('21', '70'), ('22', '71'), ('22', '72'), ('22', '91'), ('23', '73'),
+    ('24', '74'), ('24', '75'), ('24', '76'), ('24', '77'), ('24', '83'),
+    ('25', '78'), ('26', '79'), ('27', '80'), ('28', '81'), ('30', '82'),
+    ('32', '83'), ('33', '85'), ('33', '87'), ('33', '88'), ('34', '86'),
+    ('34', '87'), ('34', '88'), ('35', '75'), ('35', '84'), ('35', '89'),
+    ('35', '90'), ('35', '91'), ('35', '92'), ('35', '93'), ('36', '94'),
+    ('36', '95'), ('36', '96'), ('37', '97'), ('38', '97'), ('39', '97'),
+    ('40', '98'), ('40', '99'), ('42', '100'), ('44', '101'), ('44', '102'),
+    ('45', '103'), ('45', '104'), ('45', '105'), ('45', '106'), ('45', '107'),
+    ('46', '108'), ('46', '109'), ('47', '110'), ('49', '111'), ('49', '112'),
+    ('49', '113'), ('49', '114'), ('49', '115'), ('51', '117'), ('51', '118'),
+    ('51', '119'), ('51', '120'), ('51', '121'), ('51', '122'), ('51', '123'),
+    ('77', '141'), ('78', '143'), ('79', '145'), ('79', '146'), ('79', '147'),
+    ('80', '142'), ('82', '146'), ('82', '147'), ('83', '148'), ('83', '149'),
+    ('83', '150'), ('83', '151'), ('83', '152'), ('85', '153'), ('88', '155'),
+    ('89', '156'), ('89', '157'), ('89', '158'), ('89', '159'), ('89', '160'),
+    ('89', '161'), ('89', '162'), ('89', '163');
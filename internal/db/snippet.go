@@ -0,0 +1,145 @@
+package db
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Default snippet shaping used when SearchOptions leaves them unset
+const (
+	DefaultSnippetLength  = 200
+	DefaultSnippetContext = 80
+)
+
+// makeSnippet extracts the part of chunk that best matches query.
+// The best matching region is the span covering the most distinct query
+// words within length runes; it is widened by up to context runes on each
+// side and the result never exceeds length runes (plus ellipses). Without
+// a match the snippet is the start of the chunk.
+func makeSnippet(chunk, query string, length, context int) string {
+	if length <= 0 {
+		length = DefaultSnippetLength
+	}
+	if context < 0 {
+		context = 0
+	}
+
+	runes := []rune(chunk)
+	if len(runes) <= length {
+		return chunk
+	}
+
+	start, end, ok := bestMatchRegion(runes, queryWords(query), length)
+	if !ok {
+		return string(runes[:length]) + "..."
+	}
+
+	// Widen the region by the context window, then clamp to length
+	start -= context
+	end += context
+	if start < 0 {
+		start = 0
+	}
+	if end > len(runes) {
+		end = len(runes)
+	}
+	if end-start > length {
+		// Keep the region centred when the context does not fit
+		excess := end - start - length
+		start += excess / 2
+		end = start + length
+	}
+
+	snippet := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// queryWords returns the distinct lowercase words of a query worth matching
+func queryWords(query string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), isNotWordRune) {
+		if len([]rune(w)) < 3 || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// bestMatchRegion finds the window of at most maxLen runes containing the
+// most distinct query words and returns the span from its first to last match
+func bestMatchRegion(runes []rune, words []string, maxLen int) (int, int, bool) {
+	if len(words) == 0 {
+		return 0, 0, false
+	}
+
+	type match struct {
+		word       int
+		start, end int
+	}
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		// Case folding changed the length; fall back to matching as-is
+		lower = runes
+	}
+
+	var matches []match
+	for wi, w := range words {
+		wr := []rune(w)
+		for i := 0; i+len(wr) <= len(lower); i++ {
+			if string(lower[i:i+len(wr)]) == w {
+				matches = append(matches, match{wi, i, i + len(wr)})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return 0, 0, false
+	}
+
+	// Matches are gathered per word; order them by position
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+
+	bestStart, bestEnd, bestDistinct := 0, 0, 0
+	counts := make(map[int]int)
+	distinct := 0
+	left := 0
+	for right, m := range matches {
+		if counts[m.word] == 0 {
+			distinct++
+		}
+		counts[m.word]++
+		for left <= right && m.end-matches[left].start > maxLen {
+			counts[matches[left].word]--
+			if counts[matches[left].word] == 0 {
+				distinct--
+			}
+			left++
+		}
+		if left > right {
+			// The match alone is longer than maxLen
+			continue
+		}
+		if distinct > bestDistinct {
+			bestDistinct = distinct
+			bestStart = matches[left].start
+			bestEnd = matches[right].end
+		}
+	}
+
+	return bestStart, bestEnd, bestDistinct > 0
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeSnippet(t *testing.T) {
+	filler := strings.Repeat("lorem ipsum dolor sit amet ", 20)
+	chunk := filler + "the right to erasure applies without undue delay " + filler
+
+	snippet := makeSnippet(chunk, "erasure delay", 100, 20)
+	if !strings.Contains(snippet, "right to erasure applies without undue delay") {
+		t.Errorf("Snippet should contain the best matching region, got %q", snippet)
+	}
+	if !strings.HasPrefix(snippet, "...") || !strings.HasSuffix(snippet, "...") {
+		t.Errorf("Snippet from the middle of a chunk should have ellipses, got %q", snippet)
+	}
+	if n := len([]rune(strings.Trim(snippet, "."))); n > 100 {
+		t.Errorf("Snippet length %d exceeds limit", n)
+	}
+
+	// Without a match the snippet is the start of the chunk
+	snippet = makeSnippet(chunk, "portability", 50, 20)
+	if snippet != string([]rune(chunk)[:50])+"..." {
+		t.Errorf("Expected leading snippet, got %q", snippet)
+	}
+
+	// Short chunks are returned unchanged
+	if got := makeSnippet("short chunk", "chunk", 100, 10); got != "short chunk" {
+		t.Errorf("Expected unchanged chunk, got %q", got)
+	}
+}
+
+func TestMakeSnippetRuneSafe(t *testing.T) {
+	chunk := strings.Repeat("ü", 300)
+	snippet := makeSnippet(chunk, "", 10, 0)
+	if snippet != strings.Repeat("ü", 10)+"..." {
+		t.Errorf("Snippet should be cut on rune boundaries, got %q", snippet)
+	}
+}
+
+func TestMakeSnippetWordLongerThanLength(t *testing.T) {
+	chunk := "The data subject shall have the right to obtain from the controller confirmation"
+
+	// Every query word is longer than the snippet length
+	snippet := makeSnippet(chunk, "subject confirmation", 6, 10)
+	if snippet != string([]rune(chunk)[:6])+"..." {
+		t.Errorf("Expected leading snippet, got %q", snippet)
+	}
+
+	// A short word still matches when a long one does not fit
+	snippet = makeSnippet(chunk, "confirmation the", 6, 0)
+	if !strings.Contains(strings.ToLower(snippet), "the") {
+		t.Errorf("Snippet should contain the short match, got %q", snippet)
+	}
+
+	// Without case folding the words are matched as-is
+	snippet = makeSnippet("İstanbul: "+chunk, "* subject İstanbul", 6, 0)
+	if !strings.HasSuffix(snippet, "...") {
+		t.Errorf("Expected a shortened snippet, got %q", snippet)
+	}
+}
@@ -0,0 +1,223 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Spelling correction thresholds: a correction must share this share of
+// padded trigrams with the misspelled word, and words shorter than
+// minCorrectedLen are never corrected
+const (
+	minSpellSimilarity = 0.3
+	minCorrectedLen    = 4
+)
+
+var wordRe = regexp.MustCompile(`\p{L}+`)
+
+// vocabulary holds the words of the indexed chunks for spelling correction,
+// along with an index from padded trigrams to the words containing them
+type vocabulary struct {
+	signature string         // document count and highest ID when built
+	counts    map[string]int // word to number of chunks containing it
+	trigrams  map[string][]string
+}
+
+// CorrectQuery replaces query words that occur in no indexed chunk with the
+// closest word that does, such as "eraser" with "erasure". Candidates share
+// trigrams with the word and are at most one edit away, two for longer
+// words; the most frequent of the closest wins. Operators, words shorter
+// than four letters and terms of the synonyms and term map are left alone.
+// The query is returned unchanged when nothing needs correcting.
+func (db *DB) CorrectQuery(query string) (string, error) {
+	vocab, err := db.vocabulary()
+	if err != nil {
+		return "", err
+	}
+	known, err := db.expansionWords()
+	if err != nil {
+		return "", err
+	}
+
+	return wordRe.ReplaceAllStringFunc(query, func(word string) string {
+		lower := strings.ToLower(word)
+		if word == opAnd || word == opOr || word == opNot || utf8.RuneCountInString(word) < minCorrectedLen ||
+			vocab.counts[lower] > 0 || known[lower] {
+			return word
+		}
+		correction := vocab.correct(lower)
+		if correction == "" {
+			return word
+		}
+		if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+			c, size := utf8.DecodeRuneInString(correction)
+			correction = string(unicode.ToUpper(c)) + correction[size:]
+		}
+		return correction
+	}), nil
+}
+
+// correct returns the best known replacement for an unknown word, or ""
+func (v *vocabulary) correct(word string) string {
+	wordTrigrams := paddedTrigrams(word)
+	shared := make(map[string]int)
+	for _, t := range wordTrigrams {
+		for _, candidate := range v.trigrams[t] {
+			shared[candidate]++
+		}
+	}
+
+	maxEdits := 1
+	if utf8.RuneCountInString(word) > 5 {
+		maxEdits = 2
+	}
+	best, bestEdits := "", maxEdits+1
+	for candidate, n := range shared {
+		total := len(wordTrigrams) + len(paddedTrigrams(candidate)) - n
+		if float64(n)/float64(total) < minSpellSimilarity {
+			continue
+		}
+		edits := editDistance(word, candidate)
+		if edits > maxEdits {
+			continue
+		}
+		if edits < bestEdits || (edits == bestEdits && (v.counts[candidate] > v.counts[best] ||
+			v.counts[candidate] == v.counts[best] && candidate < best)) {
+			best, bestEdits = candidate, edits
+		}
+	}
+	return best
+}
+
+// vocabulary returns the vocabulary of the live chunks, rebuilding it when
+// documents were added or deleted since it was last built
+func (db *DB) vocabulary() (*vocabulary, error) {
+	var count, maxID int64
+	if err := db.conn.QueryRow("SELECT COUNT(*), COALESCE(MAX(id), 0) FROM documents WHERE deleted_at IS NULL").Scan(&count, &maxID); err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+	signature := fmt.Sprintf("%d/%d", count, maxID)
+
+	db.vocabMu.Lock()
+	defer db.vocabMu.Unlock()
+	if db.vocab != nil && db.vocab.signature == signature {
+		return db.vocab, nil
+	}
+
+	rows, err := db.conn.Query("SELECT chunk FROM documents WHERE deleted_at IS NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunks: %w", err)
+	}
+	defer rows.Close()
+
+	vocab := &vocabulary{signature: signature, counts: make(map[string]int), trigrams: make(map[string][]string)}
+	for rows.Next() {
+		var chunk string
+		if err := rows.Scan(&chunk); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		seen := make(map[string]bool)
+		for _, word := range wordRe.FindAllString(strings.ToLower(chunk), -1) {
+			if !seen[word] {
+				seen[word] = true
+				vocab.counts[word]++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	words := make([]string, 0, len(vocab.counts))
+	for word := range vocab.counts {
+		if utf8.RuneCountInString(word) >= minCorrectedLen-1 {
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	for _, word := range words {
+		for _, t := range paddedTrigrams(word) {
+			vocab.trigrams[t] = append(vocab.trigrams[t], word)
+		}
+	}
+	db.vocab = vocab
+	return vocab, nil
+}
+
+// expansionWords returns the words of the synonyms and the term map, which
+// are deliberate even when no chunk contains them
+func (db *DB) expansionWords() (map[string]bool, error) {
+	words := make(map[string]bool)
+	synonyms, err := db.synonyms()
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range synonyms {
+		for _, w := range wordRe.FindAllString(pair[0]+" "+pair[1], -1) {
+			words[w] = true
+		}
+	}
+
+	rows, err := db.conn.Query("SELECT term FROM term_map")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query term map: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, fmt.Errorf("failed to scan term: %w", err)
+		}
+		for _, w := range wordRe.FindAllString(term, -1) {
+			words[w] = true
+		}
+	}
+	return words, rows.Err()
+}
+
+// paddedTrigrams returns the distinct trigrams of a word padded with two
+// spaces in front and one behind, so that word starts weigh more
+func paddedTrigrams(word string) []string {
+	runes := []rune("  " + word + " ")
+	seen := make(map[string]bool, len(runes))
+	var trigrams []string
+	for i := 0; i+3 <= len(runes); i++ {
+		t := string(runes[i : i+3])
+		if !seen[t] {
+			seen[t] = true
+			trigrams = append(trigrams, t)
+		}
+	}
+	return trigrams
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters turning a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
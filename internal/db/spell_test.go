@@ -0,0 +1,67 @@
+package db
+
+import "testing"
+
+func TestCorrectQuery(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i, chunk := range []string{
+		"The data subject shall have the right to obtain the erasure of personal data.",
+		"Erasure shall be carried out without undue delay.",
+		"The controller shall keep records of processing activities.",
+	} {
+		if _, err := database.InsertChunk(chunk, i); err != nil {
+			t.Fatalf("Failed to insert chunk: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"right to eraser", "right to erasure"},
+		{"Eraser AND controler", "Erasure AND controller"},
+		{"\"right to erasure\" of personnal data", "\"right to erasure\" of personal data"},
+		{"recrods of processing", "records of processing"},
+		// Short words, unknown words far from any known one, synonyms
+		// and term map entries are kept
+		{"can we keep emails forever", "can we keep emails forever"},
+		{"ropa and dpia", "ropa and dpia"},
+	}
+	for _, tt := range tests {
+		got, err := database.CorrectQuery(tt.query)
+		if err != nil {
+			t.Fatalf("CorrectQuery failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("CorrectQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	// The vocabulary follows newly ingested chunks
+	if _, err := database.InsertChunk("Pseudonymisation reduces risks.", 3); err != nil {
+		t.Fatalf("Failed to insert chunk: %v", err)
+	}
+	if got, _ := database.CorrectQuery("pseudonymisaton"); got != "pseudonymisation" {
+		t.Errorf("Expected the new word to be known, got %q", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"eraser", "erasure", 2},
+		{"recrods", "records", 1},
+		{"data", "data", 0},
+		{"", "abc", 3},
+		{"löschung", "loschung", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Keys of the metadata table written at ingest
+const (
+	MetaIngestedAt     = "ingested_at"     // time of the last ingestion, RFC 3339
+	MetaChunkCount     = "chunk_count"     // chunks written by the last ingestion
+	MetaEmbedder       = "embedder"        // embedder of the last ingestion
+	MetaEmbeddingModel = "embedding_model" // model of that embedder, where it has one
+)
+
+// Stats describes what a database has indexed
+type Stats struct {
+	Collections    []Collection  `json:"collections"`
+	Chunks         int           `json:"chunks"`            // live chunks
+	DeletedChunks  int           `json:"deleted_chunks"`    // soft-deleted chunks awaiting purge
+	EmbeddedChunks int           `json:"embedded_chunks"`   // live chunks with an embedding
+	Trigrams       int           `json:"trigrams"`          // distinct trigrams in the keyword index
+	SizeBytes      int64         `json:"size_bytes"`        // size of the database file, excluding its write-ahead log
+	Dimensions     []int         `json:"dimensions"`        // embedding dimensions in use; more than one means mixed embedders
+	Embedder       string        `json:"embedder"`          // embedder of the last ingestion
+	EmbeddingModel string        `json:"embedding_model"`   // model of that embedder
+	IngestedAt     string        `json:"ingested_at"`       // time of the last ingestion
+	Sources        []SourceStats `json:"sources,omitempty"` // source documents, in source order
+}
+
+// SourceStats describes the chunks ingested from one source document
+type SourceStats struct {
+	Source     string     `json:"source"` // empty for chunks ingested without a source
+	Collection string     `json:"collection"`
+	Chunks     int        `json:"chunks"`
+	IngestedAt *time.Time `json:"ingested_at,omitempty"` // when the file was last ingested, if recorded
+}
+
+// Stats returns what the database has indexed: its collections, chunk,
+// embedding and trigram counts, its size on disk, the embedder of the last ingestion and the source
+// documents
+func (db *DB) Stats() (*Stats, error) {
+	collections, err := db.Collections()
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{Collections: collections, Dimensions: []int{}}
+
+	err = db.conn.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE d.deleted_at IS NULL),
+			COUNT(*) FILTER (WHERE d.deleted_at IS NOT NULL),
+			COUNT(e.doc_id) FILTER (WHERE d.deleted_at IS NULL)
+		FROM documents d LEFT JOIN embeddings e ON e.doc_id = d.id`).
+		Scan(&stats.Chunks, &stats.DeletedChunks, &stats.EmbeddedChunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunks: %w", err)
+	}
+
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM trigram_postings").Scan(&stats.Trigrams); err != nil {
+		return nil, fmt.Errorf("failed to count trigrams: %w", err)
+	}
+	if stats.SizeBytes, err = db.size(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT DISTINCT length(e.embedding) / 4 AS dimensions
+		FROM embeddings e JOIN documents d ON d.id = e.doc_id
+		WHERE d.deleted_at IS NULL
+		ORDER BY dimensions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query embedding dimensions: %w", err)
+	}
+	for rows.Next() {
+		var dimensions int
+		if err := rows.Scan(&dimensions); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan embedding dimensions: %w", err)
+		}
+		stats.Dimensions = append(stats.Dimensions, dimensions)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query embedding dimensions: %w", err)
+	}
+
+	for key, value := range map[string]*string{
+		MetaEmbedder:       &stats.Embedder,
+		MetaEmbeddingModel: &stats.EmbeddingModel,
+		MetaIngestedAt:     &stats.IngestedAt,
+	} {
+		if *value, err = db.GetMetadata(key); err != nil {
+			return nil, fmt.Errorf("failed to get metadata %s: %w", key, err)
+		}
+	}
+
+	if stats.Sources, err = db.sourceStats(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// size returns the size of the database file from its page count and size
+func (db *DB) size() (int64, error) {
+	var pages, pageSize int64
+	if err := db.conn.QueryRow("PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("failed to get page count: %w", err)
+	}
+	if err := db.conn.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to get page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// sourceStats counts the live chunks of each source and collection
+func (db *DB) sourceStats() ([]SourceStats, error) {
+	rows, err := db.conn.Query(`
+		SELECT d.source, d.collection, COUNT(*), f.ingested_at
+		FROM documents d LEFT JOIN source_files f ON f.source = d.source
+		WHERE d.deleted_at IS NULL
+		GROUP BY d.source, d.collection
+		ORDER BY d.source, d.collection`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []SourceStats
+	for rows.Next() {
+		var s SourceStats
+		var ingestedAt sql.NullTime
+		if err := rows.Scan(&s.Source, &s.Collection, &s.Chunks, &ingestedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan source: %w", err)
+		}
+		if ingestedAt.Valid {
+			s.IngestedAt = &ingestedAt.Time
+		}
+		sources = append(sources, s)
+	}
+	return sources, rows.Err()
+}
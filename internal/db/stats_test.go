@@ -0,0 +1,94 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	stats, err := database.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Chunks != 0 || stats.Trigrams != 0 || len(stats.Dimensions) != 0 || len(stats.Sources) != 0 {
+		t.Errorf("Expected empty stats for an empty database, got %+v", stats)
+	}
+
+	docs := []Document{
+		{Chunk: "Article 17 Right to erasure", Source: "gdpr.pdf"},
+		{Chunk: "Article 18 Right to restriction", Source: "gdpr.pdf"},
+		{Chunk: "Guidelines on consent", Source: "edpb.pdf", Collection: "edpb"},
+		{Chunk: "Withdrawn chunk", Source: "edpb.pdf", Collection: "edpb"},
+	}
+	var ids []int64
+	for i, d := range docs {
+		d.ChunkIndex = i
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := database.InsertEmbedding(ids[0], []float32{1, 0, 0}); err != nil {
+		t.Fatalf("InsertEmbedding failed: %v", err)
+	}
+	if err := database.InsertEmbedding(ids[2], []float32{1, 0, 0, 0}); err != nil {
+		t.Fatalf("InsertEmbedding failed: %v", err)
+	}
+	if err := database.InsertTrigrams(ids[0], GenerateTrigrams(docs[0].Chunk)); err != nil {
+		t.Fatalf("InsertTrigrams failed: %v", err)
+	}
+	if err := database.SoftDelete(ids[3]); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	before := time.Now().Add(-time.Second)
+	if err := database.SaveSourceFile(SourceFile{Source: "gdpr.pdf", Hash: "abc", Size: 3, ModTime: before}); err != nil {
+		t.Fatalf("SaveSourceFile failed: %v", err)
+	}
+	for key, value := range map[string]string{
+		MetaEmbedder:       "ollama",
+		MetaEmbeddingModel: "nomic-embed-text",
+		MetaIngestedAt:     "2026-03-01T12:00:00Z",
+	} {
+		if err := database.SetMetadata(key, value); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
+	}
+
+	stats, err = database.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Chunks != 3 || stats.DeletedChunks != 1 || stats.EmbeddedChunks != 2 {
+		t.Errorf("Expected 3 chunks, 1 deleted and 2 embedded, got %d, %d and %d", stats.Chunks, stats.DeletedChunks, stats.EmbeddedChunks)
+	}
+	if stats.Trigrams == 0 {
+		t.Error("Expected the trigrams of the chunks to be counted")
+	}
+	if stats.SizeBytes <= 0 {
+		t.Errorf("Expected a positive database size, got %d", stats.SizeBytes)
+	}
+	if len(stats.Dimensions) != 2 || stats.Dimensions[0] != 3 || stats.Dimensions[1] != 4 {
+		t.Errorf("Expected dimensions [3 4], got %v", stats.Dimensions)
+	}
+	if len(stats.Collections) != 2 {
+		t.Errorf("Expected 2 collections, got %v", stats.Collections)
+	}
+	if stats.Embedder != "ollama" || stats.EmbeddingModel != "nomic-embed-text" || stats.IngestedAt != "2026-03-01T12:00:00Z" {
+		t.Errorf("Expected the ingest metadata, got %q, %q and %q", stats.Embedder, stats.EmbeddingModel, stats.IngestedAt)
+	}
+
+	if len(stats.Sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %+v", stats.Sources)
+	}
+	edpb, gdpr := stats.Sources[0], stats.Sources[1]
+	if edpb.Source != "edpb.pdf" || edpb.Collection != "edpb" || edpb.Chunks != 1 || edpb.IngestedAt != nil {
+		t.Errorf("Unexpected source %+v", edpb)
+	}
+	if gdpr.Source != "gdpr.pdf" || gdpr.Chunks != 2 || gdpr.IngestedAt == nil || gdpr.IngestedAt.Before(before) {
+		t.Errorf("Unexpected source %+v", gdpr)
+	}
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// termArticleBoost is added to the keyword score of chunks of an article a
+// query term maps to, so that "SAR" finds Article 15 even where its text
+// shares few trigrams with the query
+const termArticleBoost = 0.2
+
+// AddTerm adds a practitioner term to the term map: queries containing term
+// are also searched for expansion, and chunks of the GDPR article are
+// boosted. Either expansion or article may be empty.
+func (db *DB) AddTerm(term, expansion, article string) error {
+	if article != "" {
+		number, ok := ArticleNumber(article)
+		if !ok {
+			return fmt.Errorf("invalid article %q", article)
+		}
+		article = number
+	}
+	_, err := db.conn.Exec(
+		"INSERT OR IGNORE INTO term_map (term, expansion, article) VALUES (?, ?, ?)",
+		strings.ToLower(strings.TrimSpace(term)), strings.ToLower(strings.TrimSpace(expansion)), article,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add term: %w", err)
+	}
+	return nil
+}
+
+// matchTerms returns the expansions and articles of the term map entries
+// whose term occurs in the query as whole words
+func (db *DB) matchTerms(query string) (expansions, articles []string, err error) {
+	rows, err := db.conn.Query("SELECT term, expansion, article FROM term_map")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query term map: %w", err)
+	}
+	defer rows.Close()
+
+	query = strings.ToLower(query)
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var term, expansion, article string
+		if err := rows.Scan(&term, &expansion, &article); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan term: %w", err)
+		}
+		if !containsTerm(query, term) {
+			continue
+		}
+		if expansion != "" && !seen["e:"+expansion] {
+			seen["e:"+expansion] = true
+			expansions = append(expansions, expansion)
+		}
+		if article != "" && !seen["a:"+article] {
+			seen["a:"+article] = true
+			articles = append(articles, article)
+		}
+	}
+	return expansions, articles, rows.Err()
+}
+
+// articleDocuments returns the IDs of the chunks of the given articles
+func (db *DB) articleDocuments(articles []string) ([]int64, error) {
+	if len(articles) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(articles))
+	args := make([]interface{}, len(articles))
+	for i, a := range articles {
+		placeholders[i] = "?"
+		args[i] = a
+	}
+
+	rows, err := db.conn.Query(
+		"SELECT id FROM documents WHERE deleted_at IS NULL AND json_extract(metadata, '$.article') IN ("+strings.Join(placeholders, ",")+")",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find article chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
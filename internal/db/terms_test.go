@@ -0,0 +1,62 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandQueryTermMap(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	expansions, articles, err := database.expandQuery("How fast must we answer a SAR?")
+	if err != nil {
+		t.Fatalf("expandQuery failed: %v", err)
+	}
+	if !reflect.DeepEqual(expansions, []string{"subject access request", "right of access"}) || !reflect.DeepEqual(articles, []string{"15"}) {
+		t.Errorf("Expected the SAR expansions and Article 15, got %v, %v", expansions, articles)
+	}
+
+	// The term map applies one way only
+	if expansions, articles, _ := database.expandQuery("right of access"); len(expansions) != 0 || len(articles) != 0 {
+		t.Errorf("Expected no expansion of the legal wording, got %v, %v", expansions, articles)
+	}
+
+	if err := database.AddTerm("Breach Log", "documentation of personal data breaches", "Art. 33"); err != nil {
+		t.Fatalf("AddTerm failed: %v", err)
+	}
+	if expansions, articles, _ := database.expandQuery("keeping a breach log"); !reflect.DeepEqual(expansions, []string{"documentation of personal data breaches"}) || !reflect.DeepEqual(articles, []string{"33"}) {
+		t.Errorf("Expected the custom term, got %v, %v", expansions, articles)
+	}
+	if err := database.AddTerm("logbook", "", "Article thirty"); err == nil {
+		t.Error("Expected an invalid article to be rejected")
+	}
+}
+
+func TestSearchTrigramsBoostsMappedArticles(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []Document{
+		{Chunk: "The controller shall provide a copy of the personal data undergoing processing.", Metadata: map[string]string{"article": "15"}},
+		{Chunk: "SAR templates and deadlines are discussed in the annual report.", Metadata: map[string]string{}},
+	} {
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	results, err := database.SearchTrigrams("SAR copy", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) == 0 || results[0].ID != ids[0] {
+		t.Errorf("Expected the Article 15 chunk first, got %+v", results)
+	}
+}
@@ -0,0 +1,81 @@
+package db
+
+import "fmt"
+
+// TOCChapter is a chapter of the regulation with its sections and the
+// articles outside any section
+type TOCChapter struct {
+	Chapter  string       `json:"chapter,omitempty"` // Roman numeral; empty for articles ingested without a chapter
+	Title    string       `json:"title,omitempty"`
+	Sections []TOCSection `json:"sections,omitempty"`
+	Articles []TOCArticle `json:"articles,omitempty"`
+}
+
+// TOCSection is a section of a chapter
+type TOCSection struct {
+	Section  string       `json:"section"`
+	Title    string       `json:"title,omitempty"`
+	Articles []TOCArticle `json:"articles"`
+}
+
+// TOCArticle is an article in the table of contents
+type TOCArticle struct {
+	Article string `json:"article"`
+	Title   string `json:"title,omitempty"`
+}
+
+// TableOfContents returns the structure of the regulation as ingested into
+// a collection: its chapters, sections and articles with their titles, in
+// article order. Each article is listed once, under the chapter and
+// section recorded for its chunks.
+func (db *DB) TableOfContents(collection string) ([]TOCChapter, error) {
+	rows, err := db.conn.Query(`
+		SELECT json_extract(metadata, '$.article') AS article,
+			COALESCE(MAX(json_extract(metadata, '$.article_title')), ''),
+			COALESCE(MAX(json_extract(metadata, '$.chapter')), ''),
+			COALESCE(MAX(json_extract(metadata, '$.chapter_title')), ''),
+			COALESCE(MAX(json_extract(metadata, '$.section')), ''),
+			COALESCE(MAX(json_extract(metadata, '$.section_title')), '')
+		FROM documents
+		WHERE deleted_at IS NULL AND collection = ? AND article IS NOT NULL AND article != ''
+		GROUP BY article
+		ORDER BY CAST(article AS INTEGER)`, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table of contents: %w", err)
+	}
+	defer rows.Close()
+
+	var chapters []TOCChapter
+	chapterIndex := make(map[string]int)
+	for rows.Next() {
+		var article TOCArticle
+		var chapter, chapterTitle, section, sectionTitle string
+		if err := rows.Scan(&article.Article, &article.Title, &chapter, &chapterTitle, &section, &sectionTitle); err != nil {
+			return nil, fmt.Errorf("failed to scan article: %w", err)
+		}
+
+		i, ok := chapterIndex[chapter]
+		if !ok {
+			i = len(chapters)
+			chapterIndex[chapter] = i
+			chapters = append(chapters, TOCChapter{Chapter: chapter})
+		}
+		c := &chapters[i]
+		if c.Title == "" {
+			c.Title = chapterTitle
+		}
+		if section == "" {
+			c.Articles = append(c.Articles, article)
+			continue
+		}
+		if n := len(c.Sections); n == 0 || c.Sections[n-1].Section != section {
+			c.Sections = append(c.Sections, TOCSection{Section: section})
+		}
+		s := &c.Sections[len(c.Sections)-1]
+		if s.Title == "" {
+			s.Title = sectionTitle
+		}
+		s.Articles = append(s.Articles, article)
+	}
+	return chapters, rows.Err()
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableOfContents(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chapterI := map[string]string{"chapter": "I", "chapter_title": "General provisions"}
+	chapterIII := map[string]string{"chapter": "III", "chapter_title": "Rights of the data subject"}
+	with := func(base map[string]string, extra ...string) map[string]string {
+		m := make(map[string]string)
+		for k, v := range base {
+			m[k] = v
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			m[extra[i]] = extra[i+1]
+		}
+		return m
+	}
+	for i, metadata := range []map[string]string{
+		with(chapterIII, "section", "3", "section_title", "Rectification and erasure", "article", "17", "article_title", "Right to erasure"),
+		with(chapterI, "article", "1", "article_title", "Subject-matter and objectives"),
+		with(chapterI, "article", "4", "article_title", "Definitions"),
+		with(chapterI, "article", "4", "paragraph", "2"),
+		with(chapterIII, "section", "3", "article", "16", "article_title", "Right to rectification"),
+		with(chapterIII, "section", "1", "section_title", "Transparency and modalities", "article", "12"),
+		{"recital": "26"},
+		{"article": "5", "article_title": "Principles relating to processing of personal data"},
+	} {
+		if _, err := database.InsertDocument(Document{Chunk: "text", ChunkIndex: i, Metadata: metadata}); err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+	}
+	if _, err := database.InsertDocument(Document{Chunk: "Text", Collection: "gdpr-de", Metadata: map[string]string{"article": "99"}}); err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+
+	toc, err := database.TableOfContents("")
+	if err != nil {
+		t.Fatalf("TableOfContents failed: %v", err)
+	}
+	want := []TOCChapter{
+		{Chapter: "I", Title: "General provisions", Articles: []TOCArticle{
+			{Article: "1", Title: "Subject-matter and objectives"},
+			{Article: "4", Title: "Definitions"},
+		}},
+		{Articles: []TOCArticle{{Article: "5", Title: "Principles relating to processing of personal data"}}},
+		{Chapter: "III", Title: "Rights of the data subject", Sections: []TOCSection{
+			{Section: "1", Title: "Transparency and modalities", Articles: []TOCArticle{{Article: "12"}}},
+			{Section: "3", Title: "Rectification and erasure", Articles: []TOCArticle{
+				{Article: "16", Title: "Right to rectification"},
+				{Article: "17", Title: "Right to erasure"},
+			}},
+		}},
+	}
+	if !reflect.DeepEqual(toc, want) {
+		t.Errorf("TableOfContents() = %+v, want %+v", toc, want)
+	}
+
+	if toc, _ := database.TableOfContents("gdpr-de"); len(toc) != 1 || toc[0].Articles[0].Article != "99" {
+		t.Errorf("Expected only the gdpr-de article, got %+v", toc)
+	}
+}
@@ -0,0 +1,130 @@
+package guidance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed children.json
+var childrenJSON []byte
+
+// Bounds of the age below which a child's consent to information society
+// services needs parental authorisation: 16 under Article 8(1), which
+// Member States may lower by law to no less than 13
+const (
+	DefaultDigitalConsentAge = 16
+	MinDigitalConsentAge     = 13
+)
+
+// adulthood is the age from which a data subject is no longer a child, as
+// under the UN Convention on the Rights of the Child
+const adulthood = 18
+
+// ChildrenRecitals are the recitals on the protection of children's
+// personal data
+var ChildrenRecitals = []string{"38", "58", "65", "71", "75"}
+
+// DigitalConsentAge is the age from which a Member State or EEA country
+// lets children consent to information society services themselves
+type DigitalConsentAge struct {
+	Country string   `json:"country"` // ISO 3166-1 alpha-2 code
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Age     int      `json:"age"`
+	Law     string   `json:"law"` // national provision setting the age, or that there is none
+	Notes   string   `json:"notes,omitempty"`
+}
+
+// DigitalConsentAges are the ages of digital consent of the EU Member
+// States and the EEA countries, in alphabetical order of their English
+// names within each group. National laws change; check the current law
+// before relying on an entry.
+var DigitalConsentAges = parseDigitalConsentAges(childrenJSON)
+
+func parseDigitalConsentAges(data []byte) []DigitalConsentAge {
+	var ages []DigitalConsentAge
+	if err := json.Unmarshal(data, &ages); err != nil {
+		panic("failed to parse ages of digital consent: " + err.Error())
+	}
+	return ages
+}
+
+// DigitalConsentAgeFor returns the age of digital consent of a country,
+// given by ISO code ("DE"), English name ("Germany") or alias
+func DigitalConsentAgeFor(country string) (DigitalConsentAge, bool) {
+	key := termKey(country)
+	for _, a := range DigitalConsentAges {
+		names := append([]string{a.Country, a.Name}, a.Aliases...)
+		for _, name := range names {
+			if termKey(name) == key {
+				return a, true
+			}
+		}
+	}
+	return DigitalConsentAge{}, false
+}
+
+// ChildRequirement is a rule for processing children's personal data
+type ChildRequirement struct {
+	Topic       string   `json:"topic"`
+	Requirement string   `json:"requirement"`
+	Provisions  []string `json:"provisions"`
+}
+
+// ChildRequirements are the rules of the GDPR for children's personal data
+var ChildRequirements = []ChildRequirement{
+	{"Parental consent", "Where consent is the legal basis for offering information society services directly to a child below the age of digital consent, processing is lawful only if and to the extent that consent is given or authorised by the holder of parental responsibility.", []string{"Art. 8(1) GDPR", "Art. 6(1)(a) GDPR"}},
+	{"Verification", "The controller must make reasonable efforts to verify that consent is given or authorised by the holder of parental responsibility, taking into consideration available technology.", []string{"Art. 8(2) GDPR"}},
+	{"Scope", "Article 8 applies only to consent to information society services offered directly to a child. Other legal bases, such as contract, remain available, and the consent of the holder of parental responsibility is not necessary for preventive or counselling services offered directly to a child.", []string{"Art. 8(1) GDPR", "Recital 38 GDPR"}},
+	{"Contract law", "Article 8 does not affect the general contract law of Member States, such as the rules on the validity, formation or effect of a contract in relation to a child.", []string{"Art. 8(3) GDPR"}},
+	{"Specific protection", "Children merit specific protection, in particular for marketing, creating personality or user profiles and collecting their data when using services offered directly to them.", []string{"Recital 38 GDPR"}},
+	{"Transparency", "Information and communication addressed to a child must be in clear and plain language that the child can easily understand.", []string{"Art. 12(1) GDPR", "Recital 58 GDPR"}},
+	{"Erasure", "The right to erasure applies in particular where consent was given as a child, not fully aware of the risks, even if the data subject is no longer a child.", []string{"Art. 17(1)(f) GDPR", "Recital 65 GDPR"}},
+	{"Automated decisions", "Decisions based solely on automated processing, including profiling, with legal or similarly significant effects should not concern a child.", []string{"Art. 22 GDPR", "Recital 71 GDPR"}},
+	{"Risk", "Processing the personal data of vulnerable natural persons, in particular children, is a factor of risk to be weighed in the measures taken and in deciding whether a DPIA is needed.", []string{"Art. 24 GDPR", "Art. 35 GDPR", "Recital 75 GDPR"}},
+	{"Legitimate interests", "Where the data subject is a child, their interests and fundamental rights weigh particularly heavily against the controller's legitimate interests.", []string{"Art. 6(1)(f) GDPR"}},
+}
+
+// ChildConsentAssessment says whether the consent of a child of a given age
+// to an information society service needs parental authorisation
+type ChildConsentAssessment struct {
+	Country                 string   `json:"country,omitempty"`
+	Age                     int      `json:"age"`
+	Threshold               int      `json:"threshold"` // age of digital consent applied
+	ParentalConsentRequired bool     `json:"parental_consent_required"`
+	Notes                   []string `json:"notes"`
+}
+
+// AssessChildConsent assesses the consent of a child of the given age in a
+// country, given as for DigitalConsentAgeFor. Without a country, the
+// Article 8(1) default of 16 applies.
+func AssessChildConsent(country string, age int) (ChildConsentAssessment, error) {
+	if age < 0 {
+		return ChildConsentAssessment{}, fmt.Errorf("age must not be negative, got %d", age)
+	}
+	assessment := ChildConsentAssessment{Age: age, Threshold: DefaultDigitalConsentAge}
+	if country != "" {
+		a, ok := DigitalConsentAgeFor(country)
+		if !ok {
+			return ChildConsentAssessment{}, fmt.Errorf("unknown EU or EEA country %q", country)
+		}
+		assessment.Country = a.Country
+		assessment.Threshold = a.Age
+		if a.Notes != "" {
+			assessment.Notes = append(assessment.Notes, a.Notes)
+		}
+	} else {
+		assessment.Notes = append(assessment.Notes, fmt.Sprintf("No country given: the Article 8(1) default of %d applies, but Member States may lower it to as low as %d. The law of the Member State where the child lives is generally taken to apply.", DefaultDigitalConsentAge, MinDigitalConsentAge))
+	}
+
+	switch {
+	case age < assessment.Threshold:
+		assessment.ParentalConsentRequired = true
+		assessment.Notes = append(assessment.Notes, "Consent must be given or authorised by the holder of parental responsibility, and the controller must make reasonable efforts to verify it (Art. 8(2) GDPR).")
+	case age < adulthood:
+		assessment.Notes = append(assessment.Notes, "The child can consent themselves, but still merits specific protection (Recital 38 GDPR).")
+	}
+	assessment.Notes = append(assessment.Notes, "This only concerns consent to information society services offered directly to a child; other legal bases and national contract law are unaffected (Art. 8(3) GDPR).")
+	return assessment, nil
+}
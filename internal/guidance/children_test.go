@@ -0,0 +1,71 @@
+package guidance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigitalConsentAges(t *testing.T) {
+	if len(DigitalConsentAges) != 30 {
+		t.Fatalf("Expected the 27 Member States and 3 EEA countries, got %d", len(DigitalConsentAges))
+	}
+	seen := make(map[string]bool)
+	for _, a := range DigitalConsentAges {
+		if seen[a.Country] || len(a.Country) != 2 || strings.ToUpper(a.Country) != a.Country {
+			t.Errorf("Invalid or duplicate country code %q", a.Country)
+		}
+		seen[a.Country] = true
+		if a.Age < MinDigitalConsentAge || a.Age > DefaultDigitalConsentAge {
+			t.Errorf("Age %d of %s is outside the range Article 8(1) allows", a.Age, a.Name)
+		}
+		if a.Name == "" || a.Law == "" {
+			t.Errorf("Expected a name and a law for %s", a.Country)
+		}
+	}
+}
+
+func TestDigitalConsentAgeFor(t *testing.T) {
+	tests := []struct {
+		country string
+		want    int
+	}{
+		{"DE", 16},
+		{"fr", 15},
+		{"Spain", 14},
+		{"czech republic", 15},
+		{"Norway", 13},
+	}
+	for _, tt := range tests {
+		if a, ok := DigitalConsentAgeFor(tt.country); !ok || a.Age != tt.want {
+			t.Errorf("DigitalConsentAgeFor(%q) = %d, %v, want %d", tt.country, a.Age, ok, tt.want)
+		}
+	}
+	for _, country := range []string{"US", "United Kingdom", ""} {
+		if a, ok := DigitalConsentAgeFor(country); ok {
+			t.Errorf("Expected no age for %q, got %+v", country, a)
+		}
+	}
+}
+
+func TestAssessChildConsent(t *testing.T) {
+	a, err := AssessChildConsent("ES", 13)
+	if err != nil || !a.ParentalConsentRequired || a.Threshold != 14 || a.Country != "ES" {
+		t.Errorf("Expected parental consent below 14 in Spain, got %+v, %v", a, err)
+	}
+	if a, _ := AssessChildConsent("Belgium", 13); a.ParentalConsentRequired {
+		t.Errorf("Expected a 13 year old to consent in Belgium, got %+v", a)
+	}
+	a, _ = AssessChildConsent("", 15)
+	if !a.ParentalConsentRequired || a.Threshold != DefaultDigitalConsentAge || !strings.Contains(a.Notes[0], "No country given") {
+		t.Errorf("Expected the default of 16 without a country, got %+v", a)
+	}
+	if a, _ := AssessChildConsent("France", 14); !strings.Contains(strings.Join(a.Notes, " "), "jointly") {
+		t.Errorf("Expected the French joint consent note, got %+v", a)
+	}
+	if _, err := AssessChildConsent("Atlantis", 10); err == nil {
+		t.Error("Expected an unknown country to be rejected")
+	}
+	if _, err := AssessChildConsent("DE", -1); err == nil {
+		t.Error("Expected a negative age to be rejected")
+	}
+}
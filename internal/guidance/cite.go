@@ -0,0 +1,127 @@
+package guidance
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Identifiers of the GDPR as published in the Official Journal
+const (
+	GDPRCELEX    = "32016R0679"
+	GDPRELI      = "http://data.europa.eu/eli/reg/2016/679/oj"
+	GDPRTitle    = "Regulation (EU) 2016/679 of the European Parliament and of the Council of 27 April 2016 on the protection of natural persons with regard to the processing of personal data and on the free movement of such data, and repealing Directive 95/46/EC (General Data Protection Regulation)"
+	GDPROJ       = "OJ L 119, 4.5.2016, p. 1"
+	eurLexTxtURL = "https://eur-lex.europa.eu/legal-content/%s/TXT/?uri=CELEX:" + GDPRCELEX
+)
+
+// Numbers of the provisions of the GDPR, bounding the references accepted
+const (
+	gdprArticles = 99
+	gdprRecitals = 173
+	gdprChapters = 11
+)
+
+var (
+	// "Art. 17(3)(b) GDPR", "Article 17(3)(b)", "17(3)" or "art 6 (1) (f)"
+	articleRefRe = regexp.MustCompile(`(?i)^(?:art(?:icle|\.)?\s*)?(\d{1,3})\s*(?:\(\s*(\d{1,2})\s*\))?\s*(?:\(\s*([a-z]|[ivx]{1,4})\s*\))?\s*(?:\(\s*([ivx]{1,4})\s*\))?(?:\s*(?:gdpr|of the gdpr))?$`)
+	// "Recital 26" or "Rec. 26 GDPR"
+	recitalRefRe = regexp.MustCompile(`(?i)^rec(?:ital|\.)?\s*\(?(\d{1,3})\)?(?:\s*gdpr)?$`)
+	// "Chapter V" or "Chapter 5"
+	chapterRefRe = regexp.MustCompile(`(?i)^ch(?:apter|\.)?\s*([ivx]{1,5}|\d{1,2})(?:\s*gdpr)?$`)
+)
+
+var romanChapters = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI"}
+
+// Reference locates a provision of the GDPR: an article, with its
+// paragraph, point and sub-point where given, a recital or a chapter
+type Reference struct {
+	Article   string `json:"article,omitempty"`
+	Paragraph string `json:"paragraph,omitempty"`
+	Point     string `json:"point,omitempty"`     // e.g. "b"
+	SubPoint  string `json:"sub_point,omitempty"` // e.g. "ii"
+	Recital   string `json:"recital,omitempty"`
+	Chapter   string `json:"chapter,omitempty"` // in Roman numerals
+}
+
+// ParseReference parses a reference to a provision of the GDPR, such as
+// "Art. 17(3)(b) GDPR", "6(1)(f)", "Recital 26" or "Chapter V"
+func ParseReference(s string) (Reference, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	if m := articleRefRe.FindStringSubmatch(s); m != nil {
+		if n, _ := strconv.Atoi(m[1]); n < 1 || n > gdprArticles {
+			return Reference{}, fmt.Errorf("the GDPR has no Article %s", m[1])
+		}
+		return Reference{Article: strings.TrimLeft(m[1], "0"), Paragraph: strings.TrimLeft(m[2], "0"), Point: strings.ToLower(m[3]), SubPoint: strings.ToLower(m[4])}, nil
+	}
+	if m := recitalRefRe.FindStringSubmatch(s); m != nil {
+		if n, _ := strconv.Atoi(m[1]); n < 1 || n > gdprRecitals {
+			return Reference{}, fmt.Errorf("the GDPR has no recital %s", m[1])
+		}
+		return Reference{Recital: strings.TrimLeft(m[1], "0")}, nil
+	}
+	if m := chapterRefRe.FindStringSubmatch(s); m != nil {
+		chapter := strings.ToUpper(m[1])
+		if n, err := strconv.Atoi(chapter); err == nil {
+			if n < 1 || n > gdprChapters {
+				return Reference{}, fmt.Errorf("the GDPR has no Chapter %s", m[1])
+			}
+			chapter = romanChapters[n-1]
+		}
+		for _, c := range romanChapters {
+			if c == chapter {
+				return Reference{Chapter: chapter}, nil
+			}
+		}
+		return Reference{}, fmt.Errorf("the GDPR has no Chapter %s", m[1])
+	}
+	return Reference{}, fmt.Errorf("cannot parse %q as a reference to an article, recital or chapter of the GDPR", s)
+}
+
+// Citation is a provision of the GDPR cited in the forms assistants and
+// readers can verify
+type Citation struct {
+	Reference
+	Short string `json:"short"` // e.g. "Art. 17(3)(b) GDPR"
+	Long  string `json:"long"`  // e.g. "Article 17(3)(b) of Regulation (EU) 2016/679"
+	Full  string `json:"full"`  // the long form with the full title of the regulation and its Official Journal reference
+	CELEX string `json:"celex"` // CELEX number of the regulation
+	ELI   string `json:"eli"`   // European Legislation Identifier of the regulation
+	URL   string `json:"url"`   // EUR-Lex page of the regulation in the language cited
+}
+
+// Cite formats the citations of a reference, with the EUR-Lex URL in the
+// given language, such as "de" ("en" by default)
+func Cite(ref Reference, language string) Citation {
+	var short, long string
+	switch {
+	case ref.Recital != "":
+		short = "Recital " + ref.Recital + " GDPR"
+		long = "Recital " + ref.Recital + " of Regulation (EU) 2016/679"
+	case ref.Chapter != "":
+		short = "Chapter " + ref.Chapter + " GDPR"
+		long = "Chapter " + ref.Chapter + " of Regulation (EU) 2016/679"
+	default:
+		subdivision := ref.Article
+		for _, part := range []string{ref.Paragraph, ref.Point, ref.SubPoint} {
+			if part != "" {
+				subdivision += "(" + part + ")"
+			}
+		}
+		short = "Art. " + subdivision + " GDPR"
+		long = "Article " + subdivision + " of Regulation (EU) 2016/679"
+	}
+	if language == "" {
+		language = "en"
+	}
+	return Citation{
+		Reference: ref,
+		Short:     short,
+		Long:      long,
+		Full:      long + strings.TrimPrefix(GDPRTitle, "Regulation (EU) 2016/679") + " (" + GDPROJ + ")",
+		CELEX:     GDPRCELEX,
+		ELI:       GDPRELI,
+		URL:       fmt.Sprintf(eurLexTxtURL, strings.ToUpper(language)),
+	}
+}
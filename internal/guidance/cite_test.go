@@ -0,0 +1,50 @@
+package guidance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	for in, want := range map[string]Reference{
+		"Art. 17(3)(b) GDPR": {Article: "17", Paragraph: "3", Point: "b"},
+		"Article 6 (1) (f)":  {Article: "6", Paragraph: "1", Point: "f"},
+		"art 9(2)":           {Article: "9", Paragraph: "2"},
+		"49(1)(a)(ii)":       {Article: "49", Paragraph: "1", Point: "a", SubPoint: "ii"},
+		"5":                  {Article: "5"},
+		"Recital 26":         {Recital: "26"},
+		"rec. 71 GDPR":       {Recital: "71"},
+		"Chapter V":          {Chapter: "V"},
+		"chapter 4":          {Chapter: "IV"},
+	} {
+		got, err := ParseReference(in)
+		if err != nil || got != want {
+			t.Errorf("ParseReference(%q) = %+v, %v, want %+v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "Article 100", "Recital 174", "Chapter XII", "Chapter 12", "Annex I", "Art. 17(3)(b)(c)(d)"} {
+		if got, err := ParseReference(in); err == nil {
+			t.Errorf("ParseReference(%q) = %+v, expected an error", in, got)
+		}
+	}
+}
+
+func TestCite(t *testing.T) {
+	c := Cite(Reference{Article: "17", Paragraph: "3", Point: "b"}, "de")
+	if c.Short != "Art. 17(3)(b) GDPR" || c.Long != "Article 17(3)(b) of Regulation (EU) 2016/679" {
+		t.Errorf("Unexpected citation %+v", c)
+	}
+	if !strings.HasPrefix(c.Full, "Article 17(3)(b) of Regulation (EU) 2016/679 of the European Parliament") || !strings.HasSuffix(c.Full, "(General Data Protection Regulation) (OJ L 119, 4.5.2016, p. 1)") {
+		t.Errorf("Unexpected full citation %q", c.Full)
+	}
+	if c.CELEX != "32016R0679" || c.ELI != "http://data.europa.eu/eli/reg/2016/679/oj" || c.URL != "https://eur-lex.europa.eu/legal-content/DE/TXT/?uri=CELEX:32016R0679" {
+		t.Errorf("Unexpected identifiers %+v", c)
+	}
+
+	if c := Cite(Reference{Recital: "26"}, ""); c.Short != "Recital 26 GDPR" || !strings.Contains(c.URL, "/EN/") {
+		t.Errorf("Unexpected recital citation %+v", c)
+	}
+	if c := Cite(Reference{Chapter: "V"}, ""); c.Long != "Chapter V of Regulation (EU) 2016/679" {
+		t.Errorf("Unexpected chapter citation %+v", c)
+	}
+}
@@ -0,0 +1,101 @@
+package guidance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+//go:embed crossregulation.json
+var crossRegulationJSON []byte
+
+// Regulations names the regulations GDPR provisions are mapped to
+var Regulations = map[string]string{
+	"ccpa": "California Consumer Privacy Act, as amended by the California Privacy Rights Act (Cal. Civ. Code § 1798.100 et seq.)",
+	"lgpd": "Lei Geral de Proteção de Dados Pessoais (Brazil, Lei nº 13.709/2018)",
+}
+
+// Provision is a provision of another regulation corresponding to the GDPR
+type Provision struct {
+	Citation string `json:"citation"`
+	Summary  string `json:"summary"`
+}
+
+// Correspondence maps a GDPR concept and its articles to the corresponding
+// provisions of the CCPA/CPRA and the LGPD. An empty list means the
+// regulation has no equivalent.
+type Correspondence struct {
+	ID      string      `json:"id"`
+	Concept string      `json:"concept"`
+	Terms   []string    `json:"terms"` // words the concept is known by across the regulations
+	GDPR    []string    `json:"gdpr"`  // GDPR articles
+	CCPA    []Provision `json:"ccpa"`
+	LGPD    []Provision `json:"lgpd"`
+	Notes   string      `json:"notes,omitempty"`
+}
+
+// Correspondences are the curated GDPR concepts mapped to the CCPA/CPRA and
+// the LGPD, in the order of their first GDPR article
+var Correspondences = parseCorrespondences(crossRegulationJSON)
+
+func parseCorrespondences(data []byte) []Correspondence {
+	var correspondences []Correspondence
+	if err := json.Unmarshal(data, &correspondences); err != nil {
+		panic("failed to parse cross-regulation mapping: " + err.Error())
+	}
+	return correspondences
+}
+
+// CorrespondencesForArticle returns the concepts a GDPR article, given by
+// its number, is mapped under
+func CorrespondencesForArticle(article string) []Correspondence {
+	var matches []Correspondence
+	for _, c := range Correspondences {
+		for _, a := range c.GDPR {
+			if a == article {
+				matches = append(matches, c)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// MatchCorrespondences returns the concepts matching a description in any
+// of the regulations' vocabularies ("right to delete", "encarregado",
+// "data breach"): exact matches of their ID, name or terms first, then
+// concepts whose terms occur in the description or contain it
+func MatchCorrespondences(concept string) []Correspondence {
+	key := termKey(concept)
+	if key == "" {
+		return nil
+	}
+	var exact, partial []Correspondence
+	for _, c := range Correspondences {
+		names := append([]string{c.ID, c.Concept}, c.Terms...)
+		found := false
+		for _, name := range names {
+			if termKey(name) == key {
+				exact = append(exact, c)
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		for _, name := range names {
+			name = termKey(name)
+			if strings.Contains(" "+key+" ", " "+name+" ") || strings.Contains(" "+name+" ", " "+key+" ") {
+				partial = append(partial, c)
+				break
+			}
+		}
+	}
+	return append(exact, partial...)
+}
+
+// termKey normalizes a term for matching, treating hyphens as spaces
+func termKey(term string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(term), "-", " ")), " ")
+}
@@ -0,0 +1,66 @@
+package guidance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func correspondenceIDs(correspondences []Correspondence) []string {
+	var ids []string
+	for _, c := range correspondences {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestCorrespondences(t *testing.T) {
+	if len(Correspondences) < 20 {
+		t.Fatalf("Expected the curated mapping, got %d concepts", len(Correspondences))
+	}
+	seen := make(map[string]bool)
+	for _, c := range Correspondences {
+		if seen[c.ID] {
+			t.Errorf("Duplicate concept %s", c.ID)
+		}
+		seen[c.ID] = true
+		if c.Concept == "" || len(c.GDPR) == 0 || len(c.Terms) == 0 {
+			t.Errorf("Expected a name, GDPR articles and terms for %s", c.ID)
+		}
+		// A regulation without an equivalent must say so in the notes
+		if (len(c.CCPA) == 0 || len(c.LGPD) == 0) && c.Notes == "" {
+			t.Errorf("Expected notes on the missing equivalent for %s", c.ID)
+		}
+	}
+}
+
+func TestCorrespondencesForArticle(t *testing.T) {
+	if got := correspondenceIDs(CorrespondencesForArticle("17")); !reflect.DeepEqual(got, []string{"erasure"}) {
+		t.Errorf("Expected erasure for Article 17, got %v", got)
+	}
+	if got := correspondenceIDs(CorrespondencesForArticle("12")); len(got) != 2 {
+		t.Errorf("Expected transparency and request deadlines for Article 12, got %v", got)
+	}
+	if got := CorrespondencesForArticle("99"); got != nil {
+		t.Errorf("Expected nothing for Article 99, got %v", correspondenceIDs(got))
+	}
+}
+
+func TestMatchCorrespondences(t *testing.T) {
+	tests := []struct {
+		concept string
+		want    []string
+	}{
+		{"Right to be forgotten", []string{"erasure"}},
+		{"encarregado", []string{"dpo"}},
+		{"deletion request", []string{"erasure"}},
+		{"opt-out", []string{"objection"}},
+		{"DPA", []string{"processors", "supervisory-authority"}},
+		{"weather", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := correspondenceIDs(MatchCorrespondences(tt.concept)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MatchCorrespondences(%q) = %v, want %v", tt.concept, got, tt.want)
+		}
+	}
+}
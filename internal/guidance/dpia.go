@@ -0,0 +1,146 @@
+package guidance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Criterion is one of the WP248 criteria of processing likely to result in
+// a high risk
+type Criterion struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// WP248Criteria are the nine criteria of the Article 29 Working Party
+// guidelines on DPIA (WP248 rev.01, endorsed by the EDPB). Processing
+// meeting two or more of them will in most cases require a DPIA.
+var WP248Criteria = []Criterion{
+	{"evaluation", "Evaluation or scoring", "Evaluating or scoring people, including profiling and predicting, especially from their performance at work, economic situation, health, personal preferences or interests, reliability or behaviour, location or movements (recitals 71 and 91)"},
+	{"automated-decision", "Automated decision-making with legal or similar significant effect", "Decisions taken about people by automated means that produce legal effects concerning them or similarly significantly affect them, such as exclusion or discrimination (Article 35(3)(a))"},
+	{"monitoring", "Systematic monitoring", "Processing used to observe, monitor or control people, including data collected through networks or a systematic monitoring of a publicly accessible area (Article 35(3)(c))"},
+	{"sensitive", "Sensitive data or data of a highly personal nature", "Special categories of personal data (Article 9), data relating to criminal convictions or offences (Article 10), and data such as communications, location or financial data whose misuse seriously affects people's daily lives"},
+	{"large-scale", "Data processed on a large scale", "Large numbers of data subjects, a large volume or range of data, a long duration or permanence of the processing, or a wide geographical extent (recital 91)"},
+	{"matching", "Matching or combining datasets", "Datasets from two or more processing operations, performed for different purposes or by different controllers, combined in a way that exceeds the reasonable expectations of the data subjects"},
+	{"vulnerable", "Data concerning vulnerable data subjects", "Children, employees, patients, the elderly, asylum seekers, mentally ill persons and others in an imbalanced relationship with the controller, who cannot easily consent to or oppose the processing (recital 75)"},
+	{"innovative", "Innovative use or applying new technological or organisational solutions", "New technologies such as fingerprint and face recognition or Internet of Things applications, whose personal and social consequences may be unknown (Article 35(1), recitals 89 and 91)"},
+	{"prevents-rights", "Processing that prevents data subjects from exercising a right or using a service or a contract", "Processing that aims at allowing, modifying or refusing access to a service or entry into a contract, such as screening customers against a credit reference database (Article 22, recital 91)"},
+}
+
+// DPIAThreshold is how many WP248 criteria processing has to meet before a
+// DPIA is in most cases required
+const DPIAThreshold = 2
+
+// DPIAInput describes the processing a DPIA template is prepared for. Empty
+// fields are left as placeholders.
+type DPIAInput struct {
+	Project     string   // name of the project or processing operation
+	Processing  string   // description of the processing
+	Criteria    []string // IDs of the WP248 criteria the processing is known to meet
+	ArticleText string   // text of Article 35, quoted in the template
+}
+
+// placeholder marks a field of a template for the user to fill in
+func placeholder(field string) string {
+	return "[" + field + "]"
+}
+
+// CriterionByID returns the WP248 criterion with the given ID
+func CriterionByID(id string) (Criterion, bool) {
+	for _, c := range WP248Criteria {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Criterion{}, false
+}
+
+// DPIATemplate renders a Markdown data protection impact assessment
+// template following the minimum content of Article 35(7), with a WP248
+// screening checklist and the text of Article 35 for reference
+func DPIATemplate(in DPIAInput) (string, error) {
+	met := make(map[string]bool, len(in.Criteria))
+	for _, id := range in.Criteria {
+		if _, ok := CriterionByID(id); !ok {
+			return "", fmt.Errorf("unknown WP248 criterion %q", id)
+		}
+		met[id] = true
+	}
+
+	project := in.Project
+	if project == "" {
+		project = placeholder("Name of the processing operation")
+	}
+	processing := in.Processing
+	if processing == "" {
+		processing = placeholder("Describe the processing: what personal data, about whom, from which sources, by which systems and who has access")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Data Protection Impact Assessment: %s\n\n", project)
+	sb.WriteString("Controller: " + placeholder("Name and contact details") + "  \n")
+	sb.WriteString("Data protection officer consulted (Art. 35(2) GDPR): " + placeholder("Name, date and advice") + "  \n")
+	sb.WriteString("Assessment date and version: " + placeholder("Date, version") + "\n\n")
+
+	sb.WriteString("## 1. Screening: is a DPIA required?\n\n")
+	fmt.Fprintf(&sb, "A DPIA is required where processing is likely to result in a high risk to the rights and freedoms of natural persons (Art. 35(1) GDPR). Processing meeting %d or more of the WP248 criteria below will in most cases require one.\n\n", DPIAThreshold)
+	for i, c := range WP248Criteria {
+		box := " "
+		if met[c.ID] {
+			box = "x"
+		}
+		fmt.Fprintf(&sb, "- [%s] **%d. %s**: %s\n", box, i+1, c.Name, c.Description)
+	}
+	sb.WriteString("\nA DPIA is always required for (Art. 35(3) GDPR):\n\n")
+	sb.WriteString("- [ ] (a) systematic and extensive evaluation of personal aspects based on automated processing, including profiling, on which decisions with legal or similarly significant effects are based\n")
+	sb.WriteString("- [ ] (b) processing on a large scale of special categories of data (Art. 9(1)) or of data relating to criminal convictions and offences (Art. 10)\n")
+	sb.WriteString("- [ ] (c) systematic monitoring of a publicly accessible area on a large scale\n\n")
+	sb.WriteString("Also check the list of processing operations requiring a DPIA published by the competent supervisory authority (Art. 35(4) GDPR).\n\n")
+	if len(in.Criteria) > 0 {
+		verdict := "below the threshold: document why no DPIA is needed, or carry one out if the processing is otherwise likely to be high risk"
+		if len(met) >= DPIAThreshold {
+			verdict = "a DPIA is likely required"
+		}
+		fmt.Fprintf(&sb, "Screening result: %d of %d criteria met, %s.\n\n", len(met), len(WP248Criteria), verdict)
+	}
+
+	sb.WriteString("## 2. Systematic description of the processing (Art. 35(7)(a) GDPR)\n\n")
+	sb.WriteString(processing + "\n\n")
+	sb.WriteString("- Nature of the processing: " + placeholder("Collection, storage, use, sharing and deletion") + "\n")
+	sb.WriteString("- Scope: " + placeholder("Categories and volume of data, number of data subjects, retention period, geographical area") + "\n")
+	sb.WriteString("- Context: " + placeholder("Relationship with the data subjects, their expectations, vulnerable groups, prior concerns") + "\n")
+	sb.WriteString("- Purposes: " + placeholder("What the processing is for, including the legitimate interests pursued where relevant") + "\n")
+	sb.WriteString("- Recipients and processors: " + placeholder("Internal and external recipients, processors (Art. 28), transfers outside the EEA (Chapter V)") + "\n\n")
+
+	sb.WriteString("## 3. Necessity and proportionality (Art. 35(7)(b) GDPR)\n\n")
+	sb.WriteString("- Lawful basis (Art. 6, and Art. 9 for special categories): " + placeholder("Basis and justification") + "\n")
+	sb.WriteString("- Purpose limitation and data minimisation (Art. 5(1)(b) and (c)): " + placeholder("Why each data item is needed") + "\n")
+	sb.WriteString("- Accuracy and storage limitation (Art. 5(1)(d) and (e)): " + placeholder("Quality measures, retention periods") + "\n")
+	sb.WriteString("- Information to data subjects (Art. 13 and 14): " + placeholder("How and when they are informed") + "\n")
+	sb.WriteString("- Data subject rights (Art. 15 to 22): " + placeholder("How requests are handled") + "\n")
+	sb.WriteString("- Views of data subjects or their representatives (Art. 35(9)): " + placeholder("Consultation carried out, or why not") + "\n\n")
+
+	sb.WriteString("## 4. Risks to the rights and freedoms of data subjects (Art. 35(7)(c) GDPR)\n\n")
+	sb.WriteString("| Risk (source, threat, impact on data subjects) | Likelihood | Severity | Overall risk |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	sb.WriteString("| " + placeholder("e.g. unauthorised access to records, leading to discrimination") + " | " + placeholder("Remote / possible / probable") + " | " + placeholder("Minimal / significant / severe") + " | " + placeholder("Low / medium / high") + " |\n\n")
+
+	sb.WriteString("## 5. Measures to address the risks (Art. 35(7)(d) GDPR)\n\n")
+	sb.WriteString("| Risk | Measures, safeguards and security mechanisms (Art. 25 and 32) | Residual risk | Approved by |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	sb.WriteString("| " + placeholder("Risk") + " | " + placeholder("e.g. pseudonymisation, encryption, access control, retention limits") + " | " + placeholder("Low / medium / high") + " | " + placeholder("Name, date") + " |\n\n")
+
+	sb.WriteString("## 6. Conclusion and sign-off\n\n")
+	sb.WriteString("- Residual risk: " + placeholder("Acceptable, or high") + ". Where a high risk remains despite the measures, consult the supervisory authority before processing (Art. 36(1) GDPR)\n")
+	sb.WriteString("- DPO advice and whether it was followed: " + placeholder("Summary") + "\n")
+	sb.WriteString("- Review date: " + placeholder("Date; review at least when the risk of the processing changes (Art. 35(11))") + "\n")
+
+	if in.ArticleText != "" {
+		sb.WriteString("\n## Annex: Article 35 GDPR\n\n")
+		for _, line := range strings.Split(strings.TrimSpace(in.ArticleText), "\n") {
+			sb.WriteString("> " + line + "\n")
+		}
+	}
+	return sb.String(), nil
+}
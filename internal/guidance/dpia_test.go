@@ -0,0 +1,58 @@
+package guidance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDPIATemplate(t *testing.T) {
+	template, err := DPIATemplate(DPIAInput{})
+	if err != nil {
+		t.Fatalf("DPIATemplate failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Data Protection Impact Assessment: [Name of the processing operation]",
+		"- [ ] **1. Evaluation or scoring**",
+		"- [ ] **9. Processing that prevents data subjects",
+		"(Art. 35(7)(a) GDPR)", "(Art. 35(7)(b) GDPR)", "(Art. 35(7)(c) GDPR)", "(Art. 35(7)(d) GDPR)",
+		"[Describe the processing:",
+	} {
+		if !strings.Contains(template, want) {
+			t.Errorf("Expected the template to contain %q", want)
+		}
+	}
+	if strings.Contains(template, "Screening result") || strings.Contains(template, "Annex: Article 35") {
+		t.Error("Expected no screening result or article text without input")
+	}
+
+	template, err = DPIATemplate(DPIAInput{
+		Project:     "Employee badge tracking",
+		Processing:  "Badge readers log when employees enter each building.",
+		Criteria:    []string{"monitoring", "vulnerable"},
+		ArticleText: "Article 35\n1. Where a type of processing",
+	})
+	if err != nil {
+		t.Fatalf("DPIATemplate failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Data Protection Impact Assessment: Employee badge tracking",
+		"Badge readers log when employees enter each building.",
+		"- [x] **3. Systematic monitoring**",
+		"- [x] **7. Data concerning vulnerable data subjects**",
+		"- [ ] **1. Evaluation or scoring**",
+		"Screening result: 2 of 9 criteria met, a DPIA is likely required.",
+		"> Article 35\n> 1. Where a type of processing\n",
+	} {
+		if !strings.Contains(template, want) {
+			t.Errorf("Expected the template to contain %q", want)
+		}
+	}
+
+	template, _ = DPIATemplate(DPIAInput{Criteria: []string{"large-scale"}})
+	if !strings.Contains(template, "1 of 9 criteria met, below the threshold") {
+		t.Error("Expected one criterion to fall below the threshold")
+	}
+	if _, err := DPIATemplate(DPIAInput{Criteria: []string{"biometric"}}); err == nil {
+		t.Error("Expected an unknown criterion to be rejected")
+	}
+}
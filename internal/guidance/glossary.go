@@ -0,0 +1,95 @@
+package guidance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+//go:embed glossary.json
+var glossaryJSON []byte
+
+// GlossaryEntry is a term used by practitioners that Article 4 does not
+// define, or defines without saying how it works in practice, with the
+// provisions it rests on
+type GlossaryEntry struct {
+	ID         string   `json:"id"`
+	Term       string   `json:"term"`
+	Aliases    []string `json:"aliases"` // abbreviations and other names, e.g. "DPIA"
+	Definition string   `json:"definition"`
+	Articles   []string `json:"articles"` // article references, e.g. "35" or "6(1)(f)"
+	Recitals   []string `json:"recitals"`
+	SeeAlso    []string `json:"see_also"` // IDs of related entries
+}
+
+// Glossary is the curated glossary, in alphabetical order of terms
+var Glossary = parseGlossary(glossaryJSON)
+
+func parseGlossary(data []byte) []GlossaryEntry {
+	var entries []GlossaryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		panic("failed to parse glossary: " + err.Error())
+	}
+	return entries
+}
+
+// GlossaryEntryByID returns the entry with the given ID
+func GlossaryEntryByID(id string) (GlossaryEntry, bool) {
+	for _, e := range Glossary {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return GlossaryEntry{}, false
+}
+
+// MatchGlossary returns the entries matching a term ("DPIA", "subject
+// access request", "privacy by design"): exact matches of their ID, term or
+// aliases first, then entries whose names occur in the term or contain it.
+// Ambiguous abbreviations such as "DPA" match every entry they stand for.
+func MatchGlossary(term string) []GlossaryEntry {
+	key := termKey(term)
+	if key == "" {
+		return nil
+	}
+	var exact, partial []GlossaryEntry
+	for _, e := range Glossary {
+		names := append([]string{e.ID, e.Term}, e.Aliases...)
+		found := false
+		for _, name := range names {
+			if termKey(name) == key {
+				exact = append(exact, e)
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		for _, name := range names {
+			name = termKey(name)
+			if strings.Contains(" "+key+" ", " "+name+" ") || strings.Contains(" "+name+" ", " "+key+" ") {
+				partial = append(partial, e)
+				break
+			}
+		}
+	}
+	return append(exact, partial...)
+}
+
+// Provisions returns the short citations of the articles and recitals the
+// entry rests on, e.g. "Art. 35 GDPR" and "Recital 84 GDPR"
+func (e GlossaryEntry) Provisions() []string {
+	provisions := make([]string, 0, len(e.Articles)+len(e.Recitals))
+	for _, a := range e.Articles {
+		ref, err := ParseReference(a)
+		if err != nil {
+			continue
+		}
+		provisions = append(provisions, Cite(ref, "").Short)
+	}
+	for _, r := range e.Recitals {
+		provisions = append(provisions, Cite(Reference{Recital: r}, "").Short)
+	}
+	return provisions
+}
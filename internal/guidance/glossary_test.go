@@ -0,0 +1,90 @@
+package guidance
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func glossaryIDs(entries []GlossaryEntry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestGlossary(t *testing.T) {
+	if len(Glossary) < 20 {
+		t.Fatalf("Expected the curated glossary, got %d entries", len(Glossary))
+	}
+	if !sort.SliceIsSorted(Glossary, func(i, j int) bool {
+		return strings.ToLower(Glossary[i].Term) < strings.ToLower(Glossary[j].Term)
+	}) {
+		t.Error("Expected the glossary in alphabetical order of terms")
+	}
+	seen := make(map[string]bool)
+	for _, e := range Glossary {
+		if seen[e.ID] {
+			t.Errorf("Duplicate entry %s", e.ID)
+		}
+		seen[e.ID] = true
+		if e.Term == "" || e.Definition == "" || len(e.Articles) == 0 {
+			t.Errorf("Expected a term, a definition and articles for %s", e.ID)
+		}
+		for _, a := range e.Articles {
+			if ref, err := ParseReference(a); err != nil || ref.Article == "" {
+				t.Errorf("Invalid article %q for %s: %v", a, e.ID, err)
+			}
+		}
+		for _, r := range e.Recitals {
+			if _, err := ParseReference("Recital " + r); err != nil {
+				t.Errorf("Invalid recital %q for %s: %v", r, e.ID, err)
+			}
+		}
+	}
+	for _, e := range Glossary {
+		for _, id := range e.SeeAlso {
+			if !seen[id] {
+				t.Errorf("Unknown see_also entry %s for %s", id, e.ID)
+			}
+		}
+	}
+}
+
+func TestMatchGlossary(t *testing.T) {
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"DPIA", []string{"dpia"}},
+		{"sar", []string{"dsar"}},
+		{"BCRs", []string{"bcr"}},
+		{"Privacy by Design", []string{"privacy-by-design"}},
+		{"one stop shop", []string{"one-stop-shop"}},
+		{"DPA", []string{"dpa-agreement", "supervisory-authority"}},
+		{"TIA for a transfer", []string{"transfer-impact-assessment"}},
+		{"weather", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := glossaryIDs(MatchGlossary(tt.term)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MatchGlossary(%q) = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestGlossaryEntryProvisions(t *testing.T) {
+	e, ok := GlossaryEntryByID("lia")
+	if !ok {
+		t.Fatal("Expected the legitimate interests assessment entry")
+	}
+	want := []string{"Art. 6(1)(f) GDPR", "Art. 21(1) GDPR", "Recital 47 GDPR", "Recital 48 GDPR", "Recital 49 GDPR"}
+	if got := e.Provisions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if _, ok := GlossaryEntryByID("personal-data"); ok {
+		t.Error("Expected no entry for a term defined in Article 4")
+	}
+}
@@ -0,0 +1,152 @@
+package guidance
+
+import (
+	"sort"
+	"strings"
+)
+
+// LawfulBasis is one of the six lawful bases for processing of Article 6(1)
+type LawfulBasis struct {
+	ID         string   `json:"id"`
+	Provision  string   `json:"provision"` // e.g. "Art. 6(1)(a) GDPR"
+	Name       string   `json:"name"`
+	Condition  string   `json:"condition"`  // the condition of Article 6(1), paraphrased
+	Conditions []string `json:"conditions"` // what relying on the basis requires
+	Articles   []string `json:"articles"`   // articles the basis is governed by
+	Recitals   []string `json:"recitals"`   // recitals explaining the basis
+
+	// cues are words and phrases of a scenario that suggest the basis
+	cues []string
+}
+
+// LawfulBases are the six bases of Article 6(1), in the order of its points
+var LawfulBases = []LawfulBasis{
+	{
+		ID:        "consent",
+		Provision: "Art. 6(1)(a) GDPR",
+		Name:      "Consent",
+		Condition: "The data subject has given consent to the processing of their personal data for one or more specific purposes",
+		Conditions: []string{
+			"Consent is freely given, specific, informed and an unambiguous indication of wishes by a statement or clear affirmative action (Art. 4(11))",
+			"The controller can demonstrate that consent was given (Art. 7(1))",
+			"A request for consent is clearly distinguishable from other matters, in clear and plain language (Art. 7(2))",
+			"Consent can be withdrawn at any time, as easily as it was given (Art. 7(3))",
+			"Performance of a contract is not made conditional on consent not necessary for it (Art. 7(4))",
+			"Children below the age of digital consent need parental authorisation for information society services (Art. 8)",
+		},
+		Articles: []string{"6", "7", "8"},
+		Recitals: []string{"32", "33", "38", "42", "43"},
+		cues:     []string{"consent", "opt-in", "opt in", "newsletter", "marketing email", "cookies", "tracking", "agree", "tick box", "checkbox", "permission"},
+	},
+	{
+		ID:        "contract",
+		Provision: "Art. 6(1)(b) GDPR",
+		Name:      "Contract",
+		Condition: "Processing is necessary for the performance of a contract to which the data subject is party, or to take steps at their request prior to entering into a contract",
+		Conditions: []string{
+			"The data subject is party to the contract, or the steps are taken at their request before entering into it",
+			"The processing is objectively necessary to perform the contract, not merely mentioned in its terms",
+		},
+		Articles: []string{"6"},
+		Recitals: []string{"44"},
+		cues:     []string{"contract", "order", "purchase", "delivery", "deliver", "subscription", "account", "customer", "payment", "invoice", "booking", "employment contract", "quote", "service agreement"},
+	},
+	{
+		ID:        "legal-obligation",
+		Provision: "Art. 6(1)(c) GDPR",
+		Name:      "Legal obligation",
+		Condition: "Processing is necessary for compliance with a legal obligation to which the controller is subject",
+		Conditions: []string{
+			"The obligation is laid down by Union or Member State law to which the controller is subject (Art. 6(3))",
+			"The law meets an objective of public interest and is proportionate to the legitimate aim pursued (Art. 6(3))",
+			"The processing is necessary to comply with the obligation",
+		},
+		Articles: []string{"6"},
+		Recitals: []string{"41", "45"},
+		cues:     []string{"legal obligation", "required by law", "law requires", "statutory", "tax", "accounting", "anti-money laundering", "aml", "kyc", "regulator", "court order", "retention obligation", "payroll", "social security"},
+	},
+	{
+		ID:        "vital-interests",
+		Provision: "Art. 6(1)(d) GDPR",
+		Name:      "Vital interests",
+		Condition: "Processing is necessary to protect the vital interests of the data subject or of another natural person",
+		Conditions: []string{
+			"The processing is necessary to protect someone's life or physical integrity",
+			"Processing for the vital interests of another person should in principle only take place where no other basis is available (recital 46)",
+		},
+		Articles: []string{"6"},
+		Recitals: []string{"46"},
+		cues:     []string{"emergency", "life-threatening", "life", "unconscious", "medical emergency", "epidemic", "disaster", "humanitarian", "vital"},
+	},
+	{
+		ID:        "public-task",
+		Provision: "Art. 6(1)(e) GDPR",
+		Name:      "Public task",
+		Condition: "Processing is necessary for the performance of a task carried out in the public interest or in the exercise of official authority vested in the controller",
+		Conditions: []string{
+			"The task or authority is laid down by Union or Member State law (Art. 6(3))",
+			"The processing is necessary for the task; data subjects may object to it (Art. 21(1))",
+		},
+		Articles: []string{"6"},
+		Recitals: []string{"41", "45"},
+		cues:     []string{"public authority", "public interest", "official authority", "government", "municipality", "council", "public body", "school", "police", "public health", "census"},
+	},
+	{
+		ID:        "legitimate-interests",
+		Provision: "Art. 6(1)(f) GDPR",
+		Name:      "Legitimate interests",
+		Condition: "Processing is necessary for the purposes of the legitimate interests pursued by the controller or a third party, except where overridden by the interests or fundamental rights and freedoms of the data subject",
+		Conditions: []string{
+			"The interest pursued is legitimate, and the processing is necessary for it",
+			"A balancing test shows the data subject's interests, rights and reasonable expectations do not override it, in particular where the data subject is a child",
+			"Not available to public authorities in the performance of their tasks (Art. 6(1), second subparagraph)",
+			"Data subjects may object to the processing (Art. 21(1)), and always to direct marketing (Art. 21(2))",
+		},
+		Articles: []string{"6", "21"},
+		Recitals: []string{"47", "48", "49"},
+		cues:     []string{"legitimate interest", "fraud", "security", "network security", "direct marketing", "analytics", "cctv", "intra-group", "group companies", "debt collection", "it security", "improve our service", "business interest"},
+	},
+}
+
+// LawfulBasisByID returns the lawful basis with the given ID
+func LawfulBasisByID(id string) (LawfulBasis, bool) {
+	for _, b := range LawfulBases {
+		if b.ID == id {
+			return b, true
+		}
+	}
+	return LawfulBasis{}, false
+}
+
+// BasisMatch is a lawful basis a scenario suggests, with the cues found
+type BasisMatch struct {
+	Basis string   `json:"basis"`
+	Cues  []string `json:"cues"`
+}
+
+// SuggestBases returns the lawful bases whose cues occur in a scenario, the
+// basis with the most cues first. It is a starting point for the analysis
+// rather than a legal assessment: which basis applies depends on facts a
+// description rarely states.
+func SuggestBases(scenario string) []BasisMatch {
+	text := " " + strings.Join(strings.FieldsFunc(strings.ToLower(scenario), func(r rune) bool {
+		return !strings.ContainsRune("abcdefghijklmnopqrstuvwxyz0123456789-", r)
+	}), " ") + " "
+
+	var matches []BasisMatch
+	for _, b := range LawfulBases {
+		var cues []string
+		for _, cue := range b.cues {
+			if strings.Contains(text, " "+cue+" ") || strings.Contains(text, " "+cue+"s ") {
+				cues = append(cues, cue)
+			}
+		}
+		if len(cues) > 0 {
+			matches = append(matches, BasisMatch{Basis: b.ID, Cues: cues})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return len(matches[i].Cues) > len(matches[j].Cues)
+	})
+	return matches
+}
@@ -0,0 +1,52 @@
+package guidance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLawfulBases(t *testing.T) {
+	if len(LawfulBases) != 6 {
+		t.Fatalf("Expected the six bases of Article 6(1), got %d", len(LawfulBases))
+	}
+	for i, b := range LawfulBases {
+		if want := "Art. 6(1)(" + string(rune('a'+i)) + ") GDPR"; b.Provision != want {
+			t.Errorf("Expected basis %d to be %s, got %s", i, want, b.Provision)
+		}
+		if len(b.Conditions) == 0 || len(b.Recitals) == 0 || b.Articles[0] != "6" {
+			t.Errorf("Expected conditions, recitals and Article 6 for %s", b.ID)
+		}
+	}
+	if b, ok := LawfulBasisByID("legitimate-interests"); !ok || b.Provision != "Art. 6(1)(f) GDPR" {
+		t.Errorf("Expected legitimate interests, got %+v", b)
+	}
+	if _, ok := LawfulBasisByID("necessity"); ok {
+		t.Error("Expected no basis for an unknown ID")
+	}
+}
+
+func TestSuggestBases(t *testing.T) {
+	tests := []struct {
+		scenario string
+		want     []BasisMatch
+	}{
+		{
+			"We send order confirmations and delivery updates to customers.",
+			[]BasisMatch{{Basis: "contract", Cues: []string{"order", "delivery", "customer"}}},
+		},
+		{
+			"Keeping invoices for tax purposes; we also use CCTV for security.",
+			[]BasisMatch{
+				{Basis: "legitimate-interests", Cues: []string{"security", "cctv"}},
+				{Basis: "contract", Cues: []string{"invoice"}},
+				{Basis: "legal-obligation", Cues: []string{"tax"}},
+			},
+		},
+		{"Storing the weather forecast", nil},
+	}
+	for _, tt := range tests {
+		if got := SuggestBases(tt.scenario); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestBases(%q) = %+v, want %+v", tt.scenario, got, tt.want)
+		}
+	}
+}
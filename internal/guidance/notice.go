@@ -0,0 +1,265 @@
+package guidance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sources of personal data, deciding which of Articles 13 and 14 a privacy
+// notice has to meet
+const (
+	SourceDirect   = "direct"   // collected from the data subject (Art. 13)
+	SourceIndirect = "indirect" // obtained from elsewhere (Art. 14)
+	SourceBoth     = "both"
+)
+
+// cueGroup is a part of a notice element, found where any of its cues is
+type cueGroup struct {
+	name string
+	cues []string
+}
+
+// NoticeElement is an item of information Articles 13 and 14 require a
+// privacy notice to give
+type NoticeElement struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Article13   string `json:"article_13,omitempty"` // e.g. "Art. 13(1)(a)"; empty where only Article 14 requires it
+	Article14   string `json:"article_14,omitempty"`
+	Description string `json:"description"`
+	Condition   string `json:"condition,omitempty"` // when the element is only required in some cases
+
+	// groups must all be found for the element to be present
+	groups []cueGroup
+}
+
+// NoticeElements are the elements of Articles 13 and 14, in the order of
+// their points
+var NoticeElements = []NoticeElement{
+	{
+		ID: "controller", Name: "Identity and contact details of the controller",
+		Article13: "Art. 13(1)(a)", Article14: "Art. 14(1)(a)",
+		Description: "The identity and the contact details of the controller and, where applicable, of the controller's representative",
+		groups: []cueGroup{
+			{"identity", []string{"controller", "we are", "who we are", "registered office", "company number", "operated by"}},
+			{"contact details", []string{"contact us", "contact details", "email", "e-mail", "write to", "address", "phone", "telephone"}},
+		},
+	},
+	{
+		ID: "dpo", Name: "Contact details of the data protection officer",
+		Article13: "Art. 13(1)(b)", Article14: "Art. 14(1)(b)",
+		Description: "The contact details of the data protection officer, where applicable",
+		Condition:   "The controller has designated a data protection officer (Art. 37)",
+		groups:      []cueGroup{{"data protection officer", []string{"data protection officer", "dpo"}}},
+	},
+	{
+		ID: "purposes", Name: "Purposes of the processing",
+		Article13: "Art. 13(1)(c)", Article14: "Art. 14(1)(c)",
+		Description: "The purposes of the processing for which the personal data are intended",
+		groups:      []cueGroup{{"purposes", []string{"purpose", "we use your", "we use the", "we process your", "use your personal", "used to", "in order to"}}},
+	},
+	{
+		ID: "legal-basis", Name: "Legal basis for the processing",
+		Article13: "Art. 13(1)(c)", Article14: "Art. 14(1)(c)",
+		Description: "The legal basis for the processing under Article 6, and Article 9 for special categories",
+		groups:      []cueGroup{{"legal basis", []string{"legal basis", "lawful basis", "legal ground", "lawful ground", "article 6", "art 6", "legitimate interest", "your consent", "performance of a contract", "legal obligation"}}},
+	},
+	{
+		ID: "legitimate-interests", Name: "Legitimate interests pursued",
+		Article13: "Art. 13(1)(d)", Article14: "Art. 14(2)(b)",
+		Description: "Where the processing is based on Article 6(1)(f), the legitimate interests pursued by the controller or a third party",
+		Condition:   "The processing is based on legitimate interests (Art. 6(1)(f))",
+		groups:      []cueGroup{{"legitimate interests", []string{"legitimate interest"}}},
+	},
+	{
+		ID: "categories", Name: "Categories of personal data",
+		Article14:   "Art. 14(1)(d)",
+		Description: "The categories of personal data concerned",
+		groups:      []cueGroup{{"categories of data", []string{"categories of personal data", "categories of data", "personal data we", "information we collect", "data we collect", "we collect", "your name", "email address"}}},
+	},
+	{
+		ID: "recipients", Name: "Recipients of the personal data",
+		Article13: "Art. 13(1)(e)", Article14: "Art. 14(1)(e)",
+		Description: "The recipients or categories of recipients of the personal data, if any",
+		groups:      []cueGroup{{"recipients", []string{"recipient", "share", "sharing", "disclose", "third part", "service provider", "processor", "partner"}}},
+	},
+	{
+		ID: "transfers", Name: "Transfers to third countries",
+		Article13: "Art. 13(1)(f)", Article14: "Art. 14(1)(f)",
+		Description: "Where applicable, the intention to transfer personal data to a third country or international organisation, the existence or absence of an adequacy decision, or a reference to the appropriate safeguards and how to obtain a copy of them",
+		Condition:   "Personal data are transferred outside the EEA (Chapter V)",
+		groups:      []cueGroup{{"transfers", []string{"transfer", "outside the eea", "outside the european", "third countr", "international organisation", "standard contractual clauses", "adequacy"}}},
+	},
+	{
+		ID: "retention", Name: "Retention period",
+		Article13: "Art. 13(2)(a)", Article14: "Art. 14(2)(a)",
+		Description: "The period for which the personal data will be stored or, if that is not possible, the criteria used to determine it",
+		groups:      []cueGroup{{"retention", []string{"retain", "retention", "keep your", "keep personal", "keep the", "stored for", "store your", "how long"}}},
+	},
+	{
+		ID: "rights", Name: "Data subject rights",
+		Article13: "Art. 13(2)(b)", Article14: "Art. 14(2)(c)",
+		Description: "The existence of the right to request access to and rectification or erasure of personal data, restriction of processing, to object to processing and to data portability",
+		groups: []cueGroup{
+			{"access", []string{"access to", "access your", "right of access", "subject access", "copy of your"}},
+			{"rectification", []string{"rectif", "correct", "inaccurate"}},
+			{"erasure", []string{"erasure", "erase", "delete", "deletion", "right to be forgotten"}},
+			{"restriction", []string{"restrict"}},
+			{"objection", []string{"object to", "right to object", "objection"}},
+			{"portability", []string{"portability", "portable", "machine-readable", "machine readable"}},
+		},
+	},
+	{
+		ID: "withdraw-consent", Name: "Right to withdraw consent",
+		Article13: "Art. 13(2)(c)", Article14: "Art. 14(2)(d)",
+		Description: "Where the processing is based on consent, the right to withdraw it at any time, without affecting the lawfulness of processing before its withdrawal",
+		Condition:   "The processing is based on consent (Art. 6(1)(a) or 9(2)(a))",
+		groups:      []cueGroup{{"withdrawal", []string{"withdraw", "revoke", "unsubscribe", "opt out", "opt-out"}}},
+	},
+	{
+		ID: "complaint", Name: "Right to lodge a complaint",
+		Article13: "Art. 13(2)(d)", Article14: "Art. 14(2)(e)",
+		Description: "The right to lodge a complaint with a supervisory authority",
+		groups:      []cueGroup{{"complaint", []string{"supervisory authority", "data protection authority", "lodge a complaint", "complain to", "complaint with", "information commissioner"}}},
+	},
+	{
+		ID: "requirement", Name: "Whether providing the data is required",
+		Article13:   "Art. 13(2)(e)",
+		Description: "Whether providing the personal data is a statutory or contractual requirement or a requirement to enter into a contract, whether the data subject is obliged to provide it, and the consequences of not providing it",
+		groups:      []cueGroup{{"requirement", []string{"obliged to provide", "required to provide", "need to provide", "not provide", "fail to provide", "failure to provide", "mandatory", "statutory requirement", "contractual requirement", "not able to"}}},
+	},
+	{
+		ID: "source", Name: "Source of the personal data",
+		Article14:   "Art. 14(2)(f)",
+		Description: "From which source the personal data originate and, if applicable, whether they came from publicly accessible sources",
+		groups:      []cueGroup{{"source", []string{"source", "obtained from", "obtain your", "we receive", "received from", "collected from", "publicly available", "publicly accessible", "provided to us by"}}},
+	},
+	{
+		ID: "automated-decisions", Name: "Automated decision-making",
+		Article13: "Art. 13(2)(f)", Article14: "Art. 14(2)(g)",
+		Description: "The existence of automated decision-making, including profiling, referred to in Article 22(1) and (4) and, at least in those cases, meaningful information about the logic involved and its significance and envisaged consequences",
+		Condition:   "Decisions are based solely on automated processing (Art. 22)",
+		groups:      []cueGroup{{"automated decision-making", []string{"automated decision", "automated individual decision", "profiling", "automated processing", "automated means"}}},
+	},
+}
+
+// NoticeFinding is a notice element with what was found of it
+type NoticeFinding struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Provision string   `json:"provision"`         // e.g. "Art. 13(1)(a) GDPR"
+	Found     []string `json:"found,omitempty"`   // parts of the element found
+	Missing   []string `json:"missing,omitempty"` // parts of the element not found
+	// Description and Condition are given for elements not found
+	Description string `json:"description,omitempty"`
+	Condition   string `json:"condition,omitempty"`
+}
+
+// NoticeAnalysis is the result of checking a privacy notice against
+// Articles 13 and 14
+type NoticeAnalysis struct {
+	Source     string          `json:"source"`
+	Articles   []string        `json:"articles"` // articles the notice was checked against
+	Present    []NoticeFinding `json:"present"`
+	Missing    []NoticeFinding `json:"missing"`    // required elements not found
+	Incomplete []NoticeFinding `json:"incomplete"` // elements found in part
+	// Conditional elements were not found but are only required in some
+	// cases, stated in their condition
+	Conditional []NoticeFinding `json:"conditional"`
+}
+
+// AnalyzeNotice checks the text of a privacy notice for the elements of
+// Article 13 (data collected from the data subject), Article 14 (data
+// obtained elsewhere) or both. It looks for the words notices usually
+// state an element in, so an element it finds may still be incomplete and
+// one it misses may be worded differently: the analysis points to what to
+// review rather than deciding compliance.
+func AnalyzeNotice(text, source string) (NoticeAnalysis, error) {
+	if source == "" {
+		source = SourceDirect
+	}
+	var articles []string
+	switch source {
+	case SourceDirect:
+		articles = []string{"13"}
+	case SourceIndirect:
+		articles = []string{"14"}
+	case SourceBoth:
+		articles = []string{"13", "14"}
+	default:
+		return NoticeAnalysis{}, fmt.Errorf("unknown source %q, expected %q, %q or %q", source, SourceDirect, SourceIndirect, SourceBoth)
+	}
+
+	normalized := normalizeNotice(text)
+	analysis := NoticeAnalysis{
+		Source:      source,
+		Articles:    articles,
+		Present:     []NoticeFinding{},
+		Missing:     []NoticeFinding{},
+		Incomplete:  []NoticeFinding{},
+		Conditional: []NoticeFinding{},
+	}
+	for _, e := range NoticeElements {
+		provision := e.provision(source)
+		if provision == "" {
+			continue
+		}
+		finding := NoticeFinding{ID: e.ID, Name: e.Name, Provision: provision}
+		for _, g := range e.groups {
+			if containsAnyCue(normalized, g.cues) {
+				finding.Found = append(finding.Found, g.name)
+			} else {
+				finding.Missing = append(finding.Missing, g.name)
+			}
+		}
+		switch {
+		case len(finding.Missing) == 0:
+			analysis.Present = append(analysis.Present, finding)
+		case len(finding.Found) > 0:
+			finding.Description = e.Description
+			analysis.Incomplete = append(analysis.Incomplete, finding)
+		case e.Condition != "":
+			finding.Description, finding.Condition = e.Description, e.Condition
+			analysis.Conditional = append(analysis.Conditional, finding)
+		default:
+			finding.Description = e.Description
+			analysis.Missing = append(analysis.Missing, finding)
+		}
+	}
+	return analysis, nil
+}
+
+// provision returns the points of Articles 13 and 14 requiring the element
+// for a source of data, or "" if neither does
+func (e NoticeElement) provision(source string) string {
+	var points []string
+	if e.Article13 != "" && source != SourceIndirect {
+		points = append(points, e.Article13)
+	}
+	if e.Article14 != "" && source != SourceDirect {
+		points = append(points, e.Article14)
+	}
+	if len(points) == 0 {
+		return ""
+	}
+	return strings.Join(points, " and ") + " GDPR"
+}
+
+// normalizeNotice lowercases text and reduces it to words separated by
+// single spaces, with a space at either end
+func normalizeNotice(text string) string {
+	return " " + strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !strings.ContainsRune("abcdefghijklmnopqrstuvwxyz0123456789-", r)
+	}), " ") + " "
+}
+
+// containsAnyCue reports whether normalized text contains a cue starting at
+// a word boundary; cues may end mid-word, as "rectif" does
+func containsAnyCue(normalized string, cues []string) bool {
+	for _, cue := range cues {
+		if strings.Contains(normalized, " "+cue) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package guidance
+
+import (
+	"testing"
+)
+
+const noticeFixture = `Privacy notice
+Acme Ltd is the controller of your personal data. Contact us at privacy@acme.example.
+We use your personal data to deliver your orders. Our legal basis is the performance of a contract (Art. 6(1)(b) GDPR).
+We share your address with our delivery partners.
+We keep your data for six years after your last order.
+You have the right of access to your data and to have inaccurate data corrected or deleted.
+You may lodge a complaint with a supervisory authority.`
+
+func findingIDs(findings []NoticeFinding) map[string]NoticeFinding {
+	ids := make(map[string]NoticeFinding, len(findings))
+	for _, f := range findings {
+		ids[f.ID] = f
+	}
+	return ids
+}
+
+func TestAnalyzeNotice(t *testing.T) {
+	analysis, err := AnalyzeNotice(noticeFixture, "")
+	if err != nil {
+		t.Fatalf("AnalyzeNotice failed: %v", err)
+	}
+	if analysis.Source != SourceDirect || len(analysis.Articles) != 1 || analysis.Articles[0] != "13" {
+		t.Errorf("Expected a check against Article 13, got %+v", analysis)
+	}
+
+	present := findingIDs(analysis.Present)
+	for _, id := range []string{"controller", "purposes", "legal-basis", "recipients", "retention", "complaint"} {
+		if _, ok := present[id]; !ok {
+			t.Errorf("Expected %s to be present, got %+v", id, analysis.Present)
+		}
+	}
+	if present["controller"].Provision != "Art. 13(1)(a) GDPR" {
+		t.Errorf("Unexpected provision %q", present["controller"].Provision)
+	}
+
+	missing := findingIDs(analysis.Missing)
+	if len(missing) != 1 || missing["requirement"].Provision != "Art. 13(2)(e) GDPR" || missing["requirement"].Description == "" {
+		t.Errorf("Expected only the requirement to provide data to be missing, got %+v", analysis.Missing)
+	}
+
+	incomplete := findingIDs(analysis.Incomplete)
+	rights, ok := incomplete["rights"]
+	if !ok || len(rights.Found) != 3 || len(rights.Missing) != 3 || rights.Missing[0] != "restriction" {
+		t.Errorf("Expected access, rectification and erasure to be the only rights found, got %+v", analysis.Incomplete)
+	}
+
+	conditional := findingIDs(analysis.Conditional)
+	for _, id := range []string{"dpo", "legitimate-interests", "transfers", "withdraw-consent", "automated-decisions"} {
+		if f, ok := conditional[id]; !ok || f.Condition == "" {
+			t.Errorf("Expected %s to be conditional, got %+v", id, analysis.Conditional)
+		}
+	}
+	for _, id := range []string{"categories", "source"} {
+		if _, ok := findingIDs(append(append(analysis.Present, analysis.Missing...), analysis.Conditional...))[id]; ok {
+			t.Errorf("Expected %s, required by Article 14 only, not to be checked", id)
+		}
+	}
+}
+
+func TestAnalyzeNoticeIndirect(t *testing.T) {
+	analysis, err := AnalyzeNotice(noticeFixture, SourceBoth)
+	if err != nil {
+		t.Fatalf("AnalyzeNotice failed: %v", err)
+	}
+	if len(analysis.Articles) != 2 {
+		t.Errorf("Expected a check against Articles 13 and 14, got %v", analysis.Articles)
+	}
+	present := findingIDs(analysis.Present)
+	if present["controller"].Provision != "Art. 13(1)(a) and Art. 14(1)(a) GDPR" {
+		t.Errorf("Unexpected provision %q", present["controller"].Provision)
+	}
+	missing := findingIDs(analysis.Missing)
+	if _, ok := missing["source"]; !ok {
+		t.Errorf("Expected the source of the data to be missing, got %+v", analysis.Missing)
+	}
+
+	analysis, _ = AnalyzeNotice(noticeFixture, SourceIndirect)
+	if _, ok := findingIDs(analysis.Missing)["requirement"]; ok {
+		t.Error("Expected the requirement to provide data, of Article 13 only, not to be checked")
+	}
+
+	if _, err := AnalyzeNotice(noticeFixture, "scraped"); err == nil {
+		t.Error("Expected an unknown source to be rejected")
+	}
+}
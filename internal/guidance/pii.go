@@ -0,0 +1,337 @@
+package guidance
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GDPR classifications of the personal data the PII detector finds
+const (
+	ClassPersonalData     = "personal data"
+	ClassSpecialCategory  = "special category"
+	ClassCriminalOffences = "criminal offence data"
+)
+
+// PIICategory is a category of personal data the detector looks for
+type PIICategory struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Classification string `json:"classification"` // ClassPersonalData, ClassSpecialCategory or ClassCriminalOffences
+	Provision      string `json:"provision"`      // e.g. "Art. 9(1) GDPR"
+}
+
+// piiDetector finds a category of personal data in text, as the matches of
+// a regular expression or of its submatch group, accepted by valid if set
+type piiDetector struct {
+	category PIICategory
+	re       *regexp.Regexp
+	group    int
+	valid    func(match string) bool
+}
+
+// termsRe matches any of terms as whole words, ignoring case
+func termsRe(terms ...string) *regexp.Regexp {
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// piiDetectors are tried in order, and a match overlapping one of an
+// earlier detector is dropped, so more specific patterns come first
+var piiDetectors = []piiDetector{
+	{
+		category: PIICategory{"email", "Email address", ClassPersonalData, "Art. 4(1) GDPR"},
+		re:       regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	},
+	{
+		category: PIICategory{"iban", "Bank account number (IBAN)", ClassPersonalData, "Art. 4(1) GDPR"},
+		re:       regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+		valid:    validIBAN,
+	},
+	{
+		category: PIICategory{"payment-card", "Payment card number", ClassPersonalData, "Art. 4(1) GDPR"},
+		re:       regexp.MustCompile(`\b\d{4}(?:[ -]?\d{4}){2}[ -]?\d{1,7}\b`),
+		valid:    validLuhn,
+	},
+	{
+		category: PIICategory{"national-id", "National identification number", ClassPersonalData, "Art. 4(1) and Art. 87 GDPR"},
+		// US social security numbers and UK national insurance numbers, or
+		// a number labelled as an ID or passport number
+		re:    regexp.MustCompile(`(?i)\b\d{3}-\d{2}-\d{4}\b|\b[A-CEGHJ-PR-TW-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b|\b(?:passport|id card|identity card|social security|national insurance|tax id|personal id)(?: number| no\.?)?\s*:?\s*[A-Z0-9][A-Z0-9 -]{4,18}[A-Z0-9]\b`),
+		valid: containsDigit,
+	},
+	{
+		category: PIICategory{"ip-address", "IP address", ClassPersonalData, "Art. 4(1) GDPR and recital 30"},
+		re:       regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|\b(?:[0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b`),
+		valid:    validIP,
+	},
+	{
+		category: PIICategory{"location", "Location data", ClassPersonalData, "Art. 4(1) GDPR"},
+		re:       regexp.MustCompile(`-?\b\d{1,2}\.\d{4,},\s*-?\d{1,3}\.\d{4,}\b`),
+	},
+	{
+		category: PIICategory{"date-of-birth", "Date of birth", ClassPersonalData, "Art. 4(1) GDPR"},
+		re:       regexp.MustCompile(`(?i)\b(?:date of birth|born on|born|d\.?o\.?b\.?)\s*:?\s*(\d{1,2}[./-]\d{1,2}[./-]\d{2,4}|\d{4}-\d{2}-\d{2}|\d{1,2} [A-Za-z]+ \d{4})`),
+		group:    1,
+	},
+	{
+		category: PIICategory{"phone", "Phone number", ClassPersonalData, "Art. 4(1) GDPR"},
+		re:       regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?)?\b\d{2,4}(?:[ .-]?\d{2,4}){2,4}\b`),
+		valid:    validPhone,
+	},
+	{
+		category: PIICategory{"postal-address", "Postal address", ClassPersonalData, "Art. 4(1) GDPR"},
+		re:       regexp.MustCompile(`\b\d{1,5},? (?:\p{Lu}\p{Ll}+ ){1,3}(?:Street|St|Road|Rd|Avenue|Ave|Lane|Ln|Boulevard|Blvd|Drive|Way|Place|Square|Court)\b`),
+	},
+	{
+		category: PIICategory{"name", "Person name", ClassPersonalData, "Art. 4(1) GDPR"},
+		// A name after a title, or labelled as one
+		re:    regexp.MustCompile(`\b(?:(?:Mr|Mrs|Ms|Miss|Mx|Dr|Prof)\.? ((?:\p{Lu}[\p{Ll}'-]+ ?){1,3})|(?i:name|patient|employee|customer)\s*:\s*((?:\p{Lu}[\p{Ll}'-]+ ?){1,4}))`),
+		group: -1,
+	},
+	{
+		category: PIICategory{"health", "Data concerning health", ClassSpecialCategory, "Art. 4(15) and Art. 9(1) GDPR"},
+		re: termsRe("diagnosis", "diagnosed", "diabetes", "diabetic", "cancer", "tumour", "tumor", "hiv", "asthma", "epilepsy",
+			"depression", "anxiety disorder", "schizophrenia", "bipolar", "mental health", "pregnant", "pregnancy", "miscarriage",
+			"disability", "disabled", "medication", "prescription", "prescribed", "therapy", "surgery", "hospitalised", "hospitalized",
+			"sick leave", "sick note", "medical record", "medical history", "blood pressure", "allergy", "vaccination", "covid-19", "illness", "patient"),
+	},
+	{
+		category: PIICategory{"racial-ethnic-origin", "Racial or ethnic origin", ClassSpecialCategory, "Art. 9(1) GDPR"},
+		re:       termsRe("racial origin", "ethnic origin", "ethnicity", "roma", "black african", "black caribbean", "asian british", "hispanic", "latino"),
+	},
+	{
+		category: PIICategory{"political-opinions", "Political opinions", ClassSpecialCategory, "Art. 9(1) GDPR"},
+		re:       termsRe("political opinion", "political opinions", "political views", "political party", "party member", "party membership", "voted for", "votes for"),
+	},
+	{
+		category: PIICategory{"religious-beliefs", "Religious or philosophical beliefs", ClassSpecialCategory, "Art. 9(1) GDPR"},
+		re:       termsRe("religion", "religious", "christian", "catholic", "protestant", "muslim", "islam", "jewish", "judaism", "hindu", "buddhist", "sikh", "atheist", "church", "mosque", "synagogue", "temple", "halal", "kosher"),
+	},
+	{
+		category: PIICategory{"trade-union", "Trade union membership", ClassSpecialCategory, "Art. 9(1) GDPR"},
+		re:       termsRe("trade union", "union member", "union membership", "union dues", "shop steward"),
+	},
+	{
+		category: PIICategory{"genetic", "Genetic data", ClassSpecialCategory, "Art. 4(13) and Art. 9(1) GDPR"},
+		re:       termsRe("genetic", "dna", "genome", "genotype", "brca1", "brca2", "hereditary"),
+	},
+	{
+		category: PIICategory{"biometric", "Biometric data", ClassSpecialCategory, "Art. 4(14) and Art. 9(1) GDPR"},
+		re:       termsRe("biometric", "biometrics", "fingerprint", "fingerprints", "facial recognition", "face recognition", "faceprint", "iris scan", "retina scan", "voiceprint"),
+	},
+	{
+		category: PIICategory{"sex-life", "Sex life or sexual orientation", ClassSpecialCategory, "Art. 9(1) GDPR"},
+		re:       termsRe("sexual orientation", "sex life", "sexual life", "gay", "lesbian", "bisexual", "homosexual", "heterosexual", "lgbt", "lgbtq"),
+	},
+	{
+		category: PIICategory{"criminal", "Criminal convictions and offences", ClassCriminalOffences, "Art. 10 GDPR"},
+		re:       termsRe("criminal record", "criminal records", "conviction", "convictions", "convicted", "arrested", "offence", "offences", "offender", "prison", "imprisoned", "probation", "police record", "dbs check"),
+	},
+}
+
+// PIICategories are the categories of personal data the detector looks for
+func PIICategories() []PIICategory {
+	categories := make([]PIICategory, len(piiDetectors))
+	for i, d := range piiDetectors {
+		categories[i] = d.category
+	}
+	return categories
+}
+
+// PIIFinding is a likely piece of personal data found in a text
+type PIIFinding struct {
+	Category       string `json:"category"`
+	Text           string `json:"text"`
+	Start          int    `json:"start"` // byte offsets of Text in the input
+	End            int    `json:"end"`
+	Classification string `json:"classification"`
+	Provision      string `json:"provision"`
+}
+
+// PIICategoryCount is a category of personal data found, with how often
+type PIICategoryCount struct {
+	PIICategory
+	Count int `json:"count"`
+}
+
+// PIIReport is the result of scanning a text for personal data
+type PIIReport struct {
+	Findings   []PIIFinding       `json:"findings"`
+	Categories []PIICategoryCount `json:"categories"`
+	// DPIACriteria are the WP248 criteria the findings point to, for
+	// dpia_template
+	DPIACriteria []string `json:"dpia_criteria,omitempty"`
+	Notes        []string `json:"notes,omitempty"`
+}
+
+// DetectPII scans text for likely personal data: identifiers such as names,
+// email addresses, phone numbers and ID numbers, and terms revealing the
+// special categories of Article 9(1) or criminal offence data of Article
+// 10. Findings are in order of position and do not overlap. The detector
+// works with patterns and word lists, so it misses personal data without a
+// telltale form, such as names without a title or label, and may flag
+// words used in another sense: its findings call for review.
+func DetectPII(text string) PIIReport {
+	var findings []PIIFinding
+	var taken [][2]int
+	overlaps := func(start, end int) bool {
+		for _, t := range taken {
+			if start < t[1] && t[0] < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, d := range piiDetectors {
+		for _, m := range d.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			switch {
+			case d.group > 0:
+				start, end = m[2*d.group], m[2*d.group+1]
+			case d.group < 0:
+				// The first submatch group that matched
+				for g := 1; 2*g < len(m); g++ {
+					if m[2*g] >= 0 {
+						start, end = m[2*g], m[2*g+1]
+						break
+					}
+				}
+			}
+			match := strings.TrimRightFunc(text[start:end], unicode.IsSpace)
+			end = start + len(match)
+			if match == "" || (d.valid != nil && !d.valid(match)) || overlaps(start, end) {
+				continue
+			}
+			taken = append(taken, [2]int{start, end})
+			findings = append(findings, PIIFinding{
+				Category:       d.category.ID,
+				Text:           match,
+				Start:          start,
+				End:            end,
+				Classification: d.category.Classification,
+				Provision:      d.category.Provision,
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+
+	report := PIIReport{Findings: findings, Categories: []PIICategoryCount{}}
+	if report.Findings == nil {
+		report.Findings = []PIIFinding{}
+	}
+	counts := make(map[string]int)
+	for _, f := range findings {
+		counts[f.Category]++
+	}
+	var special, criminal, financial, location bool
+	for _, d := range piiDetectors {
+		if counts[d.category.ID] == 0 {
+			continue
+		}
+		report.Categories = append(report.Categories, PIICategoryCount{d.category, counts[d.category.ID]})
+		switch {
+		case d.category.Classification == ClassSpecialCategory:
+			special = true
+		case d.category.Classification == ClassCriminalOffences:
+			criminal = true
+		case d.category.ID == "iban" || d.category.ID == "payment-card":
+			financial = true
+		case d.category.ID == "location":
+			location = true
+		}
+	}
+
+	if special {
+		report.Notes = append(report.Notes, "Special categories of personal data may only be processed under one of the conditions of Article 9(2), on top of a lawful basis of Article 6(1)")
+	}
+	if criminal {
+		report.Notes = append(report.Notes, "Personal data relating to criminal convictions and offences may only be processed under the control of official authority or when authorised by Union or Member State law (Article 10)")
+	}
+	if special || criminal || financial || location {
+		report.DPIACriteria = append(report.DPIACriteria, "sensitive")
+		report.Notes = append(report.Notes, "The data are sensitive or of a highly personal nature, one of the WP248 criteria for a DPIA (Article 35); processing them on a large scale makes a DPIA mandatory for special categories and criminal offence data (Article 35(3)(b))")
+	}
+	return report
+}
+
+// validIBAN reports whether an IBAN has valid check digits (ISO 13616)
+func validIBAN(iban string) bool {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
+
+// validLuhn reports whether a card number of 13 to 19 digits passes the
+// Luhn check
+func validLuhn(number string) bool {
+	var digits []int
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validIP reports whether an IPv4 address has octets of at most 255; IPv6
+// addresses are accepted as matched
+func validIP(ip string) bool {
+	if strings.Contains(ip, ":") {
+		return true
+	}
+	for _, octet := range strings.Split(ip, ".") {
+		if len(octet) > 3 || (len(octet) == 3 && octet > "255") {
+			return false
+		}
+	}
+	return true
+}
+
+// validPhone reports whether a number has as many digits as a phone number
+// with its area code, 9 to 15
+func validPhone(number string) bool {
+	digits := 0
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
+
+// containsDigit reports whether s contains a digit
+func containsDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789")
+}
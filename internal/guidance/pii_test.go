@@ -0,0 +1,72 @@
+package guidance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectPII(t *testing.T) {
+	text := "Patient: Jane Doe, born on 12/03/1985, email jane.doe@example.com, phone +44 20 7946 0958.\n" +
+		"Diagnosed with diabetes; she is a member of a trade union. IBAN GB82 WEST 1234 5698 7654 32, card 4111 1111 1111 1111.\n" +
+		"Logged in from 192.168.1.20 at 52.5200, 13.4050. Mr. John Smith lives at 221 Baker Street. Order 1234 5678 9012 3456."
+	report := DetectPII(text)
+
+	type found struct{ category, text string }
+	var got []found
+	for _, f := range report.Findings {
+		got = append(got, found{f.Category, f.Text})
+		if text[f.Start:f.End] != f.Text {
+			t.Errorf("Finding %+v does not match the input at its offsets", f)
+		}
+	}
+	want := []found{
+		{"health", "Patient"},
+		{"name", "Jane Doe"},
+		{"date-of-birth", "12/03/1985"},
+		{"email", "jane.doe@example.com"},
+		{"phone", "+44 20 7946 0958"},
+		{"health", "Diagnosed"},
+		{"health", "diabetes"},
+		{"trade-union", "trade union"},
+		{"iban", "GB82 WEST 1234 5698 7654 32"},
+		{"payment-card", "4111 1111 1111 1111"},
+		{"ip-address", "192.168.1.20"},
+		{"location", "52.5200, 13.4050"},
+		{"name", "John Smith"},
+		{"postal-address", "221 Baker Street"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectPII found\n%v\nwant\n%v", got, want)
+	}
+
+	if len(report.Categories) != 11 || report.Categories[0].ID != "email" || report.Categories[0].Count != 1 {
+		t.Errorf("Unexpected categories %+v", report.Categories)
+	}
+	for _, c := range report.Categories {
+		if c.ID == "health" && (c.Count != 3 || c.Classification != ClassSpecialCategory || c.Provision != "Art. 4(15) and Art. 9(1) GDPR") {
+			t.Errorf("Unexpected health category %+v", c)
+		}
+	}
+	if !reflect.DeepEqual(report.DPIACriteria, []string{"sensitive"}) || len(report.Notes) != 2 {
+		t.Errorf("Expected the sensitive DPIA criterion with notes, got %v, %v", report.DPIACriteria, report.Notes)
+	}
+}
+
+func TestDetectPIINone(t *testing.T) {
+	report := DetectPII("Article 5 sets out the principles relating to processing of personal data, adopted on 27 April 2016.")
+	if len(report.Findings) != 0 || len(report.Categories) != 0 || report.DPIACriteria != nil {
+		t.Errorf("Expected no personal data, got %+v", report)
+	}
+}
+
+func TestPIIValidators(t *testing.T) {
+	if !validIBAN("DE89 3704 0044 0532 0130 00") || validIBAN("DE88 3704 0044 0532 0130 00") {
+		t.Error("Expected the IBAN check digits to be verified")
+	}
+	if !validLuhn("4111-1111-1111-1111") || validLuhn("4111 1111 1111 1112") {
+		t.Error("Expected the Luhn check to be verified")
+	}
+	if !validIP("10.0.0.255") || validIP("10.0.0.256") {
+		t.Error("Expected IPv4 octets to be checked")
+	}
+}
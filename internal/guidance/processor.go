@@ -0,0 +1,158 @@
+package guidance
+
+// ProcessorClause is an element Article 28(3) requires the contract between
+// a controller and a processor to stipulate
+type ProcessorClause struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Provision   string   `json:"provision"` // e.g. "Art. 28(3)(a) GDPR"
+	Requirement string   `json:"requirement"`
+	Check       []string `json:"check"` // what a reviewer should look for in the contract
+
+	// groups must all be found for the clause to be present
+	groups []cueGroup
+}
+
+// ProcessorClauses are the mandatory elements of a data processing
+// agreement under Article 28(3), in the order of the article
+var ProcessorClauses = []ProcessorClause{
+	{
+		ID: "details", Name: "Details of the processing", Provision: "Art. 28(3) GDPR",
+		Requirement: "The contract sets out the subject-matter and duration of the processing, its nature and purpose, the type of personal data and categories of data subjects, and the obligations and rights of the controller",
+		Check:       []string{"Subject-matter and duration", "Nature and purpose", "Types of personal data", "Categories of data subjects", "Obligations and rights of the controller"},
+		groups: []cueGroup{
+			{"subject-matter and duration", []string{"subject-matter", "subject matter", "duration", "term of this", "term of the"}},
+			{"nature and purpose", []string{"nature and purpose", "nature of the processing", "purpose of the processing", "purposes of the processing"}},
+			{"types of personal data", []string{"types of personal data", "type of personal data", "categories of personal data", "personal data concerned"}},
+			{"categories of data subjects", []string{"categories of data subjects", "data subjects concerned", "category of data subjects"}},
+		},
+	},
+	{
+		ID: "instructions", Name: "Documented instructions", Provision: "Art. 28(3)(a) GDPR",
+		Requirement: "The processor processes the personal data only on documented instructions from the controller, including with regard to transfers to a third country or an international organisation, unless required to do so by Union or Member State law, in which case it informs the controller before processing unless that law prohibits it",
+		Check:       []string{"Processing only on documented instructions", "Instructions cover transfers to third countries", "Exception for processing required by law, with prior information of the controller"},
+		groups: []cueGroup{
+			{"documented instructions", []string{"documented instruction", "written instruction", "instructions of the controller", "instructions from the controller", "controller s instruction", "on the instruction", "in accordance with the instruction"}},
+			{"transfers", []string{"transfer", "third countr", "international organisation", "international organization", "outside the eea", "outside the european"}},
+		},
+	},
+	{
+		ID: "confidentiality", Name: "Confidentiality of personnel", Provision: "Art. 28(3)(b) GDPR",
+		Requirement: "Persons authorised to process the personal data have committed themselves to confidentiality or are under an appropriate statutory obligation of confidentiality",
+		Check:       []string{"Confidentiality commitments or statutory obligations of everyone processing the data"},
+		groups:      []cueGroup{{"confidentiality", []string{"confidential", "secrecy", "non-disclosure"}}},
+	},
+	{
+		ID: "security", Name: "Security of processing", Provision: "Art. 28(3)(c) GDPR",
+		Requirement: "The processor takes all measures required pursuant to Article 32",
+		Check:       []string{"Technical and organisational security measures meeting Article 32, usually listed in an annex"},
+		groups:      []cueGroup{{"security measures", []string{"article 32", "art 32", "technical and organisational measures", "technical and organizational measures", "security measures", "security of processing", "security of the processing"}}},
+	},
+	{
+		ID: "sub-processors", Name: "Sub-processors", Provision: "Art. 28(3)(d) GDPR",
+		Requirement: "The processor respects the conditions of Article 28(2) and (4) for engaging another processor: prior specific or general written authorisation of the controller, notice of intended changes with the opportunity to object, the same data protection obligations imposed on the sub-processor by contract, and the processor remaining fully liable for it",
+		Check:       []string{"Prior specific or general written authorisation", "Notice of changes and a right to object", "Same data protection obligations flowed down", "Processor remains fully liable for sub-processors"},
+		groups: []cueGroup{
+			{"authorisation", []string{"prior written authoris", "prior written authoriz", "prior specific", "general written authoris", "general written authoriz", "written consent", "prior consent", "prior authoris", "prior authoriz"}},
+			{"sub-processors", []string{"sub-processor", "subprocessor", "another processor", "sub-contractor", "subcontractor"}},
+			{"flow-down", []string{"same data protection obligations", "same obligations", "obligations no less protective", "no less protective", "equivalent obligations", "fully liable", "remain liable"}},
+		},
+	},
+	{
+		ID: "data-subject-rights", Name: "Assistance with data subject rights", Provision: "Art. 28(3)(e) GDPR",
+		Requirement: "Taking into account the nature of the processing, the processor assists the controller by appropriate technical and organisational measures, insofar as possible, in responding to requests to exercise the data subject's rights laid down in Chapter III",
+		Check:       []string{"Assistance with requests from data subjects, such as access and erasure"},
+		groups:      []cueGroup{{"data subject requests", []string{"data subject request", "requests from data subjects", "request from a data subject", "rights of data subjects", "data subject rights", "data subjects rights", "exercise of rights", "exercise their rights", "exercise of the data subject", "chapter iii"}}},
+	},
+	{
+		ID: "compliance-assistance", Name: "Assistance with security, breaches and DPIAs", Provision: "Art. 28(3)(f) GDPR",
+		Requirement: "The processor assists the controller in ensuring compliance with Articles 32 to 36, on security, personal data breach notification and communication, data protection impact assessments and prior consultation, taking into account the nature of processing and the information available to it",
+		Check:       []string{"Notifying the controller of personal data breaches without undue delay", "Assistance with data protection impact assessments and prior consultation"},
+		groups: []cueGroup{
+			{"personal data breaches", []string{"breach", "security incident"}},
+			{"impact assessments", []string{"impact assessment", "dpia", "prior consultation", "articles 32 to 36", "article 32 to 36"}},
+		},
+	},
+	{
+		ID: "deletion-return", Name: "Deletion or return at the end of the services", Provision: "Art. 28(3)(g) GDPR",
+		Requirement: "At the choice of the controller, the processor deletes or returns all the personal data after the end of the provision of services relating to processing, and deletes existing copies unless Union or Member State law requires their storage",
+		Check:       []string{"Deletion or return at the controller's choice", "Deletion of existing copies", "Triggered by the end of the services"},
+		groups: []cueGroup{
+			{"deletion or return", []string{"delete", "deletion", "return", "destroy", "destruction", "erase"}},
+			{"end of the services", []string{"end of the provision", "end of the services", "termination", "terminat", "expiry", "expiration", "cessation"}},
+		},
+	},
+	{
+		ID: "audits", Name: "Information and audits", Provision: "Art. 28(3)(h) GDPR",
+		Requirement: "The processor makes available to the controller all information necessary to demonstrate compliance with Article 28 and allows for and contributes to audits, including inspections, conducted by the controller or another auditor mandated by the controller",
+		Check:       []string{"All information necessary to demonstrate compliance", "Audits and inspections by the controller or a mandated auditor"},
+		groups: []cueGroup{
+			{"information", []string{"all information necessary", "information necessary to demonstrate", "demonstrate compliance", "make available"}},
+			{"audits", []string{"audit", "inspection"}},
+		},
+	},
+	{
+		ID: "infringing-instructions", Name: "Infringing instructions", Provision: "Art. 28(3) second subparagraph GDPR",
+		Requirement: "The processor immediately informs the controller if, in its opinion, an instruction infringes the GDPR or other Union or Member State data protection provisions",
+		Check:       []string{"Duty to inform the controller immediately of instructions that infringe data protection law"},
+		groups:      []cueGroup{{"infringing instructions", []string{"infringe", "unlawful instruction", "in breach of applicable", "violates applicable", "violate applicable"}}},
+	},
+}
+
+// ClauseFinding is a clause of Article 28(3) with what was found of it in a
+// contract
+type ClauseFinding struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Provision string   `json:"provision"`
+	Found     []string `json:"found,omitempty"`   // parts of the clause found
+	Missing   []string `json:"missing,omitempty"` // parts of the clause not found
+	// Requirement is given for clauses not found in full
+	Requirement string `json:"requirement,omitempty"`
+}
+
+// ContractAnalysis is the result of checking a contract against Article
+// 28(3)
+type ContractAnalysis struct {
+	Covered    int             `json:"covered"` // clauses present in full
+	Total      int             `json:"total"`
+	Present    []ClauseFinding `json:"present"`
+	Incomplete []ClauseFinding `json:"incomplete"` // clauses found in part
+	Missing    []ClauseFinding `json:"missing"`
+}
+
+// AnalyzeProcessorContract checks the text of a data processing agreement
+// for the clauses of Article 28(3). Like AnalyzeNotice, it looks for the
+// words such clauses are usually written in, so its findings point to what
+// to review rather than deciding compliance.
+func AnalyzeProcessorContract(text string) ContractAnalysis {
+	normalized := normalizeNotice(text)
+	analysis := ContractAnalysis{
+		Total:      len(ProcessorClauses),
+		Present:    []ClauseFinding{},
+		Incomplete: []ClauseFinding{},
+		Missing:    []ClauseFinding{},
+	}
+	for _, c := range ProcessorClauses {
+		finding := ClauseFinding{ID: c.ID, Name: c.Name, Provision: c.Provision}
+		for _, g := range c.groups {
+			if containsAnyCue(normalized, g.cues) {
+				finding.Found = append(finding.Found, g.name)
+			} else {
+				finding.Missing = append(finding.Missing, g.name)
+			}
+		}
+		switch {
+		case len(finding.Missing) == 0:
+			analysis.Present = append(analysis.Present, finding)
+			analysis.Covered++
+		case len(finding.Found) > 0:
+			finding.Requirement = c.Requirement
+			analysis.Incomplete = append(analysis.Incomplete, finding)
+		default:
+			finding.Requirement = c.Requirement
+			analysis.Missing = append(analysis.Missing, finding)
+		}
+	}
+	return analysis
+}
@@ -0,0 +1,79 @@
+package guidance
+
+import (
+	"testing"
+)
+
+const contractFixture = `Data Processing Agreement
+1. Subject-matter and duration. The Processor processes personal data for the duration of the Services Agreement.
+2. Nature and purpose of the processing: hosting of the Customer's CRM. Types of personal data: contact details. Categories of data subjects: customers.
+3. The Processor shall process personal data only on documented instructions from the Controller, including with regard to transfers to a third country.
+4. The Processor ensures that its staff are bound by confidentiality.
+5. The Processor implements the technical and organisational measures in Annex II.
+6. The Processor shall not engage a sub-processor without the prior written authorisation of the Controller.
+7. The Processor notifies the Controller of any personal data breach without undue delay.
+8. Upon termination of the Services, the Processor shall delete or return all personal data.
+9. The Processor makes available all information necessary to demonstrate compliance and allows for audits.`
+
+func clauseIDs(findings []ClauseFinding) map[string]ClauseFinding {
+	ids := make(map[string]ClauseFinding, len(findings))
+	for _, f := range findings {
+		ids[f.ID] = f
+	}
+	return ids
+}
+
+func TestProcessorClauses(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range ProcessorClauses {
+		if seen[c.ID] {
+			t.Errorf("Duplicate clause %s", c.ID)
+		}
+		seen[c.ID] = true
+		if c.Name == "" || c.Provision == "" || c.Requirement == "" || len(c.Check) == 0 || len(c.groups) == 0 {
+			t.Errorf("Incomplete clause %+v", c)
+		}
+	}
+	for _, point := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		found := false
+		for _, c := range ProcessorClauses {
+			found = found || c.Provision == "Art. 28(3)("+point+") GDPR"
+		}
+		if !found {
+			t.Errorf("Expected a clause for Article 28(3)(%s)", point)
+		}
+	}
+}
+
+func TestAnalyzeProcessorContract(t *testing.T) {
+	analysis := AnalyzeProcessorContract(contractFixture)
+	if analysis.Total != len(ProcessorClauses) || analysis.Covered != len(analysis.Present) {
+		t.Errorf("Unexpected counts %d/%d", analysis.Covered, analysis.Total)
+	}
+
+	present := clauseIDs(analysis.Present)
+	for _, id := range []string{"details", "instructions", "confidentiality", "security", "deletion-return", "audits"} {
+		if _, ok := present[id]; !ok {
+			t.Errorf("Expected %s to be present, got %+v", id, analysis.Present)
+		}
+	}
+
+	incomplete := clauseIDs(analysis.Incomplete)
+	if f, ok := incomplete["sub-processors"]; !ok || len(f.Missing) != 1 || f.Missing[0] != "flow-down" || f.Requirement == "" {
+		t.Errorf("Expected sub-processors to miss the flow-down, got %+v", analysis.Incomplete)
+	}
+	if f, ok := incomplete["compliance-assistance"]; !ok || f.Missing[0] != "impact assessments" {
+		t.Errorf("Expected assistance to miss impact assessments, got %+v", analysis.Incomplete)
+	}
+
+	missing := clauseIDs(analysis.Missing)
+	for _, id := range []string{"data-subject-rights", "infringing-instructions"} {
+		if _, ok := missing[id]; !ok {
+			t.Errorf("Expected %s to be missing, got %+v", id, analysis.Missing)
+		}
+	}
+
+	if empty := AnalyzeProcessorContract(""); empty.Covered != 0 || len(empty.Missing) != len(ProcessorClauses) {
+		t.Errorf("Expected every clause to be missing from an empty contract, got %+v", empty)
+	}
+}
@@ -0,0 +1,220 @@
+package guidance
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+)
+
+// Kinds of quiz questions
+const (
+	QuizArticleNumber = "article" // which article deals with a topic
+	QuizTopic         = "topic"   // what an article deals with
+	QuizBlank         = "blank"   // which figure completes a sentence of an article
+)
+
+// QuizOptions is how many answers each question offers
+const QuizOptions = 4
+
+// MinQuizArticles is how many articles questions are drawn from at least,
+// so every question has wrong answers to offer
+const MinQuizArticles = QuizOptions
+
+// QuizArticle is an article questions are drawn from
+type QuizArticle struct {
+	Number string
+	Title  string
+	Text   string
+}
+
+// QuizQuestion is a multiple-choice question with its answer and the
+// provision it is based on
+type QuizQuestion struct {
+	Kind        string   `json:"kind"`
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	Answer      int      `json:"answer"` // index of the correct option
+	Explanation string   `json:"explanation"`
+	Article     string   `json:"article"`
+	Citation    string   `json:"citation"` // e.g. "Art. 33(1) GDPR"
+}
+
+// quizFigures are the figures that can be blanked out of the text, with
+// plausible wrong answers of the same kind
+var quizFigures = []struct {
+	re      *regexp.Regexp
+	answers []string
+}{
+	{regexp.MustCompile(`\b(?:\d+|one|two|three|six)\s+(?:hours|days|weeks|months?|years?)\b`),
+		[]string{"24 hours", "48 hours", "72 hours", "one month", "two months", "three months", "six months", "13 years", "16 years"}},
+	{regexp.MustCompile(`\bEUR\s+\d{1,3}(?:\s\d{3})+`),
+		[]string{"EUR 5 000 000", "EUR 10 000 000", "EUR 20 000 000", "EUR 50 000 000"}},
+	{regexp.MustCompile(`\b\d+\s?%`),
+		[]string{"1 %", "2 %", "4 %", "10 %"}},
+}
+
+var (
+	// Numbered paragraph of an article, e.g. "1. The controller shall"
+	quizParagraphRe = regexp.MustCompile(`^(\d{1,2})\.\s`)
+	// End of a sentence
+	quizSentenceEndRe = regexp.MustCompile(`[.;:]\s`)
+)
+
+// quizSentenceLength bounds the sentences blanked out, long enough to be
+// meaningful and short enough to read as a question
+const (
+	minQuizSentence = 40
+	maxQuizSentence = 400
+)
+
+// GenerateQuiz draws count multiple-choice questions from randomly chosen
+// articles, each from a different article while there are enough. The
+// questions only depend on the articles and the random source, so a quiz
+// can be repeated with the same seed.
+func GenerateQuiz(articles []QuizArticle, count int, rng *rand.Rand) ([]QuizQuestion, error) {
+	var pool []QuizArticle
+	for _, a := range articles {
+		if a.Number != "" && a.Title != "" {
+			pool = append(pool, a)
+		}
+	}
+	if len(pool) < MinQuizArticles {
+		return nil, fmt.Errorf("need at least %d articles with titles, got %d", MinQuizArticles, len(pool))
+	}
+
+	order := rng.Perm(len(pool))
+	questions := make([]QuizQuestion, 0, count)
+	for i := 0; i < count; i++ {
+		article := pool[order[i%len(order)]]
+		kinds := []string{QuizBlank, QuizArticleNumber, QuizTopic}
+		kind := kinds[rng.Intn(len(kinds))]
+		if kind == QuizBlank {
+			if q, ok := blankQuestion(article, rng); ok {
+				questions = append(questions, q)
+				continue
+			}
+			kind = kinds[1+rng.Intn(2)]
+		}
+		questions = append(questions, titleQuestion(kind, article, pool, rng))
+	}
+	return questions, nil
+}
+
+// titleQuestion asks for the article dealing with a topic, or for the
+// topic of an article, with other articles as wrong answers
+func titleQuestion(kind string, article QuizArticle, pool []QuizArticle, rng *rand.Rand) QuizQuestion {
+	others := make([]QuizArticle, 0, len(pool)-1)
+	for _, a := range pool {
+		if a.Number != article.Number {
+			others = append(others, a)
+		}
+	}
+	rng.Shuffle(len(others), func(i, j int) { others[i], others[j] = others[j], others[i] })
+	choices := append([]QuizArticle{article}, others[:QuizOptions-1]...)
+
+	q := QuizQuestion{
+		Kind:        kind,
+		Explanation: fmt.Sprintf("Article %s GDPR is titled %q.", article.Number, article.Title),
+		Article:     article.Number,
+		Citation:    Cite(Reference{Article: article.Number}, "").Short,
+	}
+	options := make([]string, len(choices))
+	for i, c := range choices {
+		if kind == QuizTopic {
+			options[i] = c.Title
+		} else {
+			options[i] = "Article " + c.Number
+		}
+	}
+	if kind == QuizTopic {
+		q.Question = fmt.Sprintf("What does Article %s of the GDPR deal with?", article.Number)
+	} else {
+		q.Question = fmt.Sprintf("Which article of the GDPR deals with %q?", article.Title)
+	}
+	q.Options, q.Answer = shuffleOptions(options, rng)
+	return q
+}
+
+// blankQuestion blanks a figure, such as a time limit or a fine, out of a
+// sentence of the article, with figures of the same kind as wrong answers
+func blankQuestion(article QuizArticle, rng *rand.Rand) (QuizQuestion, bool) {
+	type candidate struct {
+		sentence, figure string
+		paragraph        string
+		answers          []string
+	}
+	var candidates []candidate
+	paragraph := ""
+	for _, line := range strings.Split(article.Text, "\n") {
+		line = strings.TrimSpace(line)
+		if m := quizParagraphRe.FindStringSubmatch(line); m != nil {
+			paragraph = m[1]
+		}
+		for _, f := range quizFigures {
+			for _, loc := range f.re.FindAllStringIndex(line, -1) {
+				sentence, start := sentenceAround(line, loc[0], loc[1])
+				if len(sentence) < minQuizSentence || len(sentence) > maxQuizSentence {
+					continue
+				}
+				figure := line[loc[0]:loc[1]]
+				blanked := sentence[:loc[0]-start] + "_____" + sentence[loc[1]-start:]
+				candidates = append(candidates, candidate{blanked, figure, paragraph, f.answers})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return QuizQuestion{}, false
+	}
+
+	c := candidates[rng.Intn(len(candidates))]
+	wrong := make([]string, 0, len(c.answers))
+	for _, a := range c.answers {
+		if !strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(c.figure), " ")) {
+			wrong = append(wrong, a)
+		}
+	}
+	rng.Shuffle(len(wrong), func(i, j int) { wrong[i], wrong[j] = wrong[j], wrong[i] })
+	options, answer := shuffleOptions(append([]string{c.figure}, wrong[:QuizOptions-1]...), rng)
+
+	ref := Reference{Article: article.Number, Paragraph: c.paragraph}
+	citation := Cite(ref, "").Short
+	return QuizQuestion{
+		Kind:        QuizBlank,
+		Question:    fmt.Sprintf("Complete Article %s GDPR (%s): %q", article.Number, article.Title, c.sentence),
+		Options:     options,
+		Answer:      answer,
+		Explanation: fmt.Sprintf("%s reads %q.", citation, c.figure),
+		Article:     article.Number,
+		Citation:    citation,
+	}, true
+}
+
+// sentenceAround returns the sentence of line containing the range from
+// start to end, and the offset it starts at
+func sentenceAround(line string, start, end int) (string, int) {
+	from := 0
+	for _, loc := range quizSentenceEndRe.FindAllStringIndex(line[:start], -1) {
+		from = loc[1]
+	}
+	to := len(line)
+	if loc := quizSentenceEndRe.FindStringIndex(line[end:]); loc != nil {
+		to = end + loc[0] + 1
+	}
+	return line[from:to], from
+}
+
+// shuffleOptions shuffles options whose first is the correct one, and
+// returns them with the correct one's new index
+func shuffleOptions(options []string, rng *rand.Rand) ([]string, int) {
+	order := rng.Perm(len(options))
+	shuffled := make([]string, len(options))
+	answer := 0
+	for i, j := range order {
+		shuffled[i] = options[j]
+		if j == 0 {
+			answer = i
+		}
+	}
+	return shuffled, answer
+}
@@ -0,0 +1,90 @@
+package guidance
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var quizArticles = []QuizArticle{
+	{"12", "Transparent information, communication and modalities for the exercise of the rights of the data subject", "3. The controller shall provide information on action taken on a request to the data subject without undue delay and in any event within one month of receipt of the request."},
+	{"17", "Right to erasure ('right to be forgotten')", "1. The data subject shall have the right to obtain from the controller the erasure of personal data concerning him or her without undue delay."},
+	{"33", "Notification of a personal data breach to the supervisory authority", "Article 33\n1. In the case of a personal data breach, the controller shall without undue delay and, where feasible, not later than 72 hours after having become aware of it, notify the personal data breach to the supervisory authority."},
+	{"37", "Designation of the data protection officer", "1. The controller and the processor shall designate a data protection officer in any case where the processing is carried out by a public authority."},
+	{"8", "", "Untitled articles are left out."},
+}
+
+func TestGenerateQuiz(t *testing.T) {
+	questions, err := GenerateQuiz(quizArticles, 8, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("GenerateQuiz failed: %v", err)
+	}
+	if len(questions) != 8 {
+		t.Fatalf("Expected 8 questions, got %d", len(questions))
+	}
+	articles := make(map[string]bool)
+	for i, q := range questions {
+		if i < 4 {
+			if articles[q.Article] {
+				t.Errorf("Expected the first questions to come from different articles, got %s twice", q.Article)
+			}
+			articles[q.Article] = true
+		}
+		if len(q.Options) != QuizOptions || q.Answer < 0 || q.Answer >= QuizOptions || q.Question == "" || q.Explanation == "" {
+			t.Fatalf("Malformed question %+v", q)
+		}
+		seen := make(map[string]bool)
+		for _, o := range q.Options {
+			if seen[o] {
+				t.Errorf("Duplicate option %q in %+v", o, q)
+			}
+			seen[o] = true
+		}
+		switch q.Kind {
+		case QuizArticleNumber:
+			if q.Options[q.Answer] != "Article "+q.Article {
+				t.Errorf("Expected Article %s to be the answer, got %+v", q.Article, q)
+			}
+		case QuizTopic:
+			if !strings.Contains(q.Question, "Article "+q.Article+" ") {
+				t.Errorf("Expected the question to name Article %s, got %+v", q.Article, q)
+			}
+		case QuizBlank:
+			if !strings.Contains(q.Question, "_____") || !strings.HasPrefix(q.Citation, "Art. "+q.Article+"(") {
+				t.Errorf("Expected a blank and a paragraph citation, got %+v", q)
+			}
+		}
+		if q.Article == "8" {
+			t.Errorf("Expected untitled articles to be left out, got %+v", q)
+		}
+	}
+
+	// The same seed gives the same quiz
+	again, _ := GenerateQuiz(quizArticles, 8, rand.New(rand.NewSource(1)))
+	if !reflect.DeepEqual(questions, again) {
+		t.Error("Expected the same quiz for the same seed")
+	}
+
+	if _, err := GenerateQuiz(quizArticles[:3], 1, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("Expected too few articles to be rejected")
+	}
+}
+
+func TestBlankQuestion(t *testing.T) {
+	q, ok := blankQuestion(quizArticles[2], rand.New(rand.NewSource(1)))
+	if !ok {
+		t.Fatal("Expected a question on the 72 hours")
+	}
+	if q.Options[q.Answer] != "72 hours" || q.Citation != "Art. 33(1) GDPR" {
+		t.Errorf("Expected 72 hours from Art. 33(1), got %+v", q)
+	}
+	want := `not later than _____ after having become aware of it, notify the personal data breach to the supervisory authority.`
+	if !strings.Contains(q.Question, want) || strings.Contains(q.Question, "1. In the case") {
+		t.Errorf("Expected the sentence with the blank, got %q", q.Question)
+	}
+
+	if _, ok := blankQuestion(quizArticles[1], rand.New(rand.NewSource(1))); ok {
+		t.Error("Expected no question from an article without figures")
+	}
+}
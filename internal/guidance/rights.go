@@ -0,0 +1,157 @@
+package guidance
+
+// Right is a right of the data subject under Chapter III
+type Right struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Article    string   `json:"article"`
+	Provision  string   `json:"provision"` // e.g. "Art. 15 GDPR"
+	Summary    string   `json:"summary"`
+	Deadline   string   `json:"deadline"`
+	Conditions []string `json:"conditions,omitempty"` // when the right applies, where it is conditional
+	Exceptions []string `json:"exceptions"`           // when the controller need not or must not comply
+	Recitals   []string `json:"recitals"`
+}
+
+// RightsDeadline is the time limit of Article 12(3) for acting on a request
+// under Articles 15 to 22
+const RightsDeadline = "Without undue delay and in any event within one month of receipt of the request, extendable by two further months where necessary given the complexity and number of requests; the data subject must be told of an extension, with the reasons, within the first month (Art. 12(3))"
+
+// commonExceptions apply to every right of Articles 15 to 22
+var commonExceptions = []string{
+	"Manifestly unfounded or excessive requests, in particular repetitive ones, may be refused or charged a reasonable fee; the controller bears the burden of proof (Art. 12(5))",
+	"The controller may ask for additional information where it has reasonable doubts about the requester's identity (Art. 12(6)), and need not comply where it cannot identify the data subject (Art. 11(2))",
+	"Union or Member State law may restrict the right where necessary for national security, crime prevention, other important public interests and similar objectives (Art. 23)",
+}
+
+// Rights are the rights of Articles 15 to 22, in article order
+var Rights = []Right{
+	{
+		ID:        "access",
+		Name:      "Right of access",
+		Article:   "15",
+		Provision: "Art. 15 GDPR",
+		Summary:   "Confirmation whether personal data are being processed and, where they are, access to the data and to information on the purposes, categories, recipients, retention period, the other rights, the source and any automated decision-making, and a copy of the data",
+		Deadline:  RightsDeadline,
+		Exceptions: append([]string{
+			"The right to obtain a copy must not adversely affect the rights and freedoms of others, such as trade secrets or other people's data (Art. 15(4))",
+			"Further copies may be charged a reasonable fee based on administrative costs (Art. 15(3))",
+		}, commonExceptions...),
+		Recitals: []string{"63", "64"},
+	},
+	{
+		ID:        "rectification",
+		Name:      "Right to rectification",
+		Article:   "16",
+		Provision: "Art. 16 GDPR",
+		Summary:   "Rectification of inaccurate personal data and completion of incomplete data, including by a supplementary statement; recipients must be notified (Art. 19)",
+		Deadline:  RightsDeadline,
+		Exceptions: append([]string{
+			"Only inaccurate or incomplete data must be rectified, having regard to the purposes of the processing",
+		}, commonExceptions...),
+		Recitals: []string{"65"},
+	},
+	{
+		ID:        "erasure",
+		Name:      "Right to erasure ('right to be forgotten')",
+		Article:   "17",
+		Provision: "Art. 17 GDPR",
+		Summary:   "Erasure of personal data on one of the grounds of Article 17(1); where the data were made public, reasonable steps to inform other controllers of the request (Art. 17(2)); recipients must be notified (Art. 19)",
+		Deadline:  RightsDeadline,
+		Conditions: []string{
+			"The data are no longer necessary for their purposes (Art. 17(1)(a))",
+			"Consent is withdrawn and there is no other legal ground (Art. 17(1)(b))",
+			"The data subject objects under Art. 21(1) and there are no overriding legitimate grounds, or objects to direct marketing under Art. 21(2) (Art. 17(1)(c))",
+			"The data have been unlawfully processed (Art. 17(1)(d))",
+			"Erasure is required by a legal obligation (Art. 17(1)(e))",
+			"The data were collected in relation to information society services offered to a child (Art. 17(1)(f))",
+		},
+		Exceptions: append([]string{
+			"Processing is necessary for exercising the right of freedom of expression and information (Art. 17(3)(a))",
+			"Processing is necessary for compliance with a legal obligation or for a task in the public interest or in the exercise of official authority (Art. 17(3)(b))",
+			"Processing is necessary for reasons of public interest in the area of public health (Art. 17(3)(c))",
+			"Processing is necessary for archiving in the public interest, scientific or historical research or statistics, where erasure would render impossible or seriously impair its objectives (Art. 17(3)(d))",
+			"Processing is necessary for the establishment, exercise or defence of legal claims (Art. 17(3)(e))",
+		}, commonExceptions...),
+		Recitals: []string{"65", "66"},
+	},
+	{
+		ID:        "restriction",
+		Name:      "Right to restriction of processing",
+		Article:   "18",
+		Provision: "Art. 18 GDPR",
+		Summary:   "Restriction of processing, so the data are only stored, while accuracy or an objection is checked or where the data subject prefers it to erasure; recipients must be notified (Art. 19) and the data subject informed before the restriction is lifted (Art. 18(3))",
+		Deadline:  RightsDeadline,
+		Conditions: []string{
+			"The accuracy of the data is contested, for the time needed to verify it (Art. 18(1)(a))",
+			"The processing is unlawful and the data subject opposes erasure (Art. 18(1)(b))",
+			"The controller no longer needs the data but the data subject needs them for legal claims (Art. 18(1)(c))",
+			"The data subject has objected under Art. 21(1), pending verification of the controller's grounds (Art. 18(1)(d))",
+		},
+		Exceptions: append([]string{
+			"Restricted data may still be processed with the data subject's consent, for legal claims, to protect the rights of another person, or for important public interest reasons (Art. 18(2))",
+		}, commonExceptions...),
+		Recitals: []string{"67"},
+	},
+	{
+		ID:        "portability",
+		Name:      "Right to data portability",
+		Article:   "20",
+		Provision: "Art. 20 GDPR",
+		Summary:   "Receiving the personal data the data subject provided in a structured, commonly used and machine-readable format, and having them transmitted directly to another controller where technically feasible",
+		Deadline:  RightsDeadline,
+		Conditions: []string{
+			"The processing is based on consent or on a contract (Art. 20(1)(a))",
+			"The processing is carried out by automated means (Art. 20(1)(b))",
+		},
+		Exceptions: append([]string{
+			"Does not apply to processing necessary for a task in the public interest or in the exercise of official authority (Art. 20(3))",
+			"Must not adversely affect the rights and freedoms of others (Art. 20(4))",
+		}, commonExceptions...),
+		Recitals: []string{"68"},
+	},
+	{
+		ID:        "objection",
+		Name:      "Right to object",
+		Article:   "21",
+		Provision: "Art. 21 GDPR",
+		Summary:   "Objecting to processing based on public task or legitimate interests, including profiling, on grounds relating to the data subject's particular situation, and to direct marketing at any time; the right must be explicitly brought to the data subject's attention at the latest at the first communication (Art. 21(4))",
+		Deadline:  RightsDeadline,
+		Conditions: []string{
+			"The processing is based on Art. 6(1)(e) or (f) (Art. 21(1))",
+			"The processing is for direct marketing, including related profiling: the objection is absolute (Art. 21(2) and (3))",
+			"The processing is for scientific or historical research or statistics (Art. 21(6))",
+		},
+		Exceptions: append([]string{
+			"The controller demonstrates compelling legitimate grounds overriding the data subject's interests, rights and freedoms, or needs the data for legal claims (Art. 21(1))",
+			"For research or statistics, the processing is necessary for a task carried out in the public interest (Art. 21(6))",
+		}, commonExceptions...),
+		Recitals: []string{"69", "70"},
+	},
+	{
+		ID:        "automated-decisions",
+		Name:      "Rights related to automated decision-making, including profiling",
+		Article:   "22",
+		Provision: "Art. 22 GDPR",
+		Summary:   "Not being subject to a decision based solely on automated processing, including profiling, which produces legal effects or similarly significantly affects the data subject",
+		Deadline:  RightsDeadline,
+		Exceptions: append([]string{
+			"The decision is necessary for entering into or performing a contract (Art. 22(2)(a))",
+			"The decision is authorised by Union or Member State law with suitable safeguards (Art. 22(2)(b))",
+			"The decision is based on explicit consent (Art. 22(2)(c))",
+			"Under (a) and (c) the controller must still safeguard at least the right to human intervention, to express one's point of view and to contest the decision (Art. 22(3))",
+			"Decisions based on special categories of data are only allowed with explicit consent or for substantial public interest, with suitable safeguards (Art. 22(4))",
+		}, commonExceptions...),
+		Recitals: []string{"71", "72"},
+	},
+}
+
+// RightByID returns the data subject right with the given ID
+func RightByID(id string) (Right, bool) {
+	for _, r := range Rights {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Right{}, false
+}
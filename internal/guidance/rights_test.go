@@ -0,0 +1,31 @@
+package guidance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRights(t *testing.T) {
+	want := []string{"15", "16", "17", "18", "20", "21", "22"}
+	if len(Rights) != len(want) {
+		t.Fatalf("Expected %d rights, got %d", len(want), len(Rights))
+	}
+	for i, r := range Rights {
+		if r.Article != want[i] || r.Provision != "Art. "+want[i]+" GDPR" {
+			t.Errorf("Expected right %d to be Article %s, got %s (%s)", i, want[i], r.Article, r.Provision)
+		}
+		if !strings.Contains(r.Deadline, "Art. 12(3)") || len(r.Exceptions) == 0 || len(r.Recitals) == 0 {
+			t.Errorf("Expected a deadline, exceptions and recitals for %s", r.ID)
+		}
+		// Every right is subject to Article 12(5) and Article 23
+		if last := r.Exceptions[len(r.Exceptions)-1]; !strings.Contains(last, "Art. 23") {
+			t.Errorf("Expected the Article 23 restrictions last for %s, got %s", r.ID, last)
+		}
+	}
+	if r, ok := RightByID("erasure"); !ok || !strings.Contains(r.Exceptions[0], "Art. 17(3)(a)") {
+		t.Errorf("Expected erasure with the exceptions of Article 17(3), got %+v", r)
+	}
+	if _, ok := RightByID("information"); ok {
+		t.Error("Expected no right for an unknown ID")
+	}
+}
@@ -0,0 +1,125 @@
+package guidance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Roles a record of processing activities can be kept in
+const (
+	RoleController = "controller"
+	RoleProcessor  = "processor"
+)
+
+// RecordField is an item of information a record of processing activities
+// has to contain
+type RecordField struct {
+	Name        string `json:"name"`
+	Provision   string `json:"provision"` // e.g. "Art. 30(1)(b)"
+	Description string `json:"description"`
+	Placeholder string `json:"-"`
+}
+
+// ControllerRecordFields are the contents of the controller's record of
+// Article 30(1). The first field describes the controller, the others each
+// processing activity.
+var ControllerRecordFields = []RecordField{
+	{"Controller", "Art. 30(1)(a)", "Name and contact details of the controller and, where applicable, the joint controller, the controller's representative and the data protection officer", "Name, address, email; joint controllers; EU representative (Art. 27); DPO (Art. 37)"},
+	{"Purposes", "Art. 30(1)(b)", "The purposes of the processing", "What the processing is for"},
+	{"Data subjects and personal data", "Art. 30(1)(c)", "A description of the categories of data subjects and of the categories of personal data", "e.g. customers, employees; contact details, payment data; special categories (Art. 9)"},
+	{"Recipients", "Art. 30(1)(d)", "The categories of recipients to whom the personal data have been or will be disclosed, including recipients in third countries or international organisations", "e.g. processors, group companies, public authorities"},
+	{"Transfers", "Art. 30(1)(e)", "Where applicable, transfers of personal data to a third country or an international organisation, including its identification and, for transfers under the second subparagraph of Article 49(1), the documentation of suitable safeguards", "Country or organisation; adequacy decision (Art. 45), safeguards (Art. 46) or derogation (Art. 49); none"},
+	{"Erasure time limits", "Art. 30(1)(f)", "Where possible, the envisaged time limits for erasure of the different categories of data", "Retention period per category of data"},
+	{"Security measures", "Art. 30(1)(g)", "Where possible, a general description of the technical and organisational security measures referred to in Article 32(1)", "e.g. encryption, access control, backups, staff training"},
+}
+
+// ProcessorRecordFields are the contents of the processor's record of
+// Article 30(2). The first field describes the processor, the others the
+// processing carried out on behalf of each controller.
+var ProcessorRecordFields = []RecordField{
+	{"Processor", "Art. 30(2)(a)", "Name and contact details of the processor or processors and, where applicable, the processor's representative and the data protection officer", "Name, address, email; EU representative (Art. 27); DPO (Art. 37)"},
+	{"Controller", "Art. 30(2)(a)", "Name and contact details of each controller on behalf of which the processor is acting and, where applicable, the controller's representative and data protection officer", "Name, address, email; representative; DPO"},
+	{"Categories of processing", "Art. 30(2)(b)", "The categories of processing carried out on behalf of each controller", "e.g. hosting, payroll, customer support"},
+	{"Transfers", "Art. 30(2)(c)", "Where applicable, transfers of personal data to a third country or an international organisation, including its identification and, for transfers under the second subparagraph of Article 49(1), the documentation of suitable safeguards", "Country or organisation; adequacy decision (Art. 45), safeguards (Art. 46) or derogation (Art. 49); none"},
+	{"Security measures", "Art. 30(2)(d)", "Where possible, a general description of the technical and organisational security measures referred to in Article 32(1)", "e.g. encryption, access control, backups, staff training"},
+}
+
+// RecordFields returns the fields of the record kept in a role
+func RecordFields(role string) ([]RecordField, error) {
+	switch role {
+	case RoleController:
+		return ControllerRecordFields, nil
+	case RoleProcessor:
+		return ProcessorRecordFields, nil
+	}
+	return nil, fmt.Errorf("unknown role %q, expected %q or %q", role, RoleController, RoleProcessor)
+}
+
+// RoPAInput describes the record a template is prepared for. Empty fields
+// are left as placeholders.
+type RoPAInput struct {
+	Role         string   // RoleController (default) or RoleProcessor
+	Organisation string   // name of the controller or processor keeping the record
+	Entries      []string // processing activities of a controller, or controllers a processor acts for; one section each
+	ArticleText  string   // text of Article 30, quoted in the template
+}
+
+// RoPATemplate renders a Markdown record of processing activities template
+// for Article 30, with each field cross-referenced to the paragraph and
+// point requiring it
+func RoPATemplate(in RoPAInput) (string, error) {
+	role := in.Role
+	if role == "" {
+		role = RoleController
+	}
+	fields, err := RecordFields(role)
+	if err != nil {
+		return "", err
+	}
+
+	organisation := in.Organisation
+	if organisation == "" {
+		organisation = placeholder("Name of the " + role)
+	}
+	entryKind := "Processing activity"
+	paragraph := "1"
+	if role == RoleProcessor {
+		entryKind = "Controller"
+		paragraph = "2"
+	}
+	entries := in.Entries
+	if len(entries) == 0 {
+		entries = []string{placeholder(entryKind)}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Record of Processing Activities (%s): %s\n\n", role, organisation)
+	fmt.Fprintf(&sb, "Each %s maintains a record of the processing activities under its responsibility containing the information of Article 30(%s) GDPR. ", role, paragraph)
+	sb.WriteString("The record is in writing, including in electronic form (Art. 30(3)), and is made available to the supervisory authority on request (Art. 30(4)).\n\n")
+	sb.WriteString("Last updated: " + placeholder("Date") + "\n\n")
+
+	fmt.Fprintf(&sb, "## %s (%s)\n\n", fields[0].Name, fields[0].Provision)
+	sb.WriteString(fields[0].Description + ".\n\n")
+	sb.WriteString(organisation + "  \n" + placeholder(fields[0].Placeholder) + "\n")
+
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "\n## %s: %s\n\n", entryKind, entry)
+		sb.WriteString("| Field | Provision | Entry |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, f := range fields[1:] {
+			fmt.Fprintf(&sb, "| **%s**: %s | %s | %s |\n", f.Name, f.Description, f.Provision, placeholder(f.Placeholder))
+		}
+	}
+
+	sb.WriteString("\n## Exemption (Art. 30(5) GDPR)\n\n")
+	sb.WriteString("Organisations employing fewer than 250 persons need not keep a record unless the processing is likely to result in a risk to the rights and freedoms of data subjects, is not occasional, or includes special categories of data (Art. 9(1)) or data relating to criminal convictions and offences (Art. 10). ")
+	sb.WriteString("Applies: " + placeholder("Yes or no, and why") + "\n")
+
+	if in.ArticleText != "" {
+		sb.WriteString("\n## Annex: Article 30 GDPR\n\n")
+		for _, line := range strings.Split(strings.TrimSpace(in.ArticleText), "\n") {
+			sb.WriteString("> " + line + "\n")
+		}
+	}
+	return sb.String(), nil
+}
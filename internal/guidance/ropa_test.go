@@ -0,0 +1,57 @@
+package guidance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoPATemplate(t *testing.T) {
+	template, err := RoPATemplate(RoPAInput{})
+	if err != nil {
+		t.Fatalf("RoPATemplate failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Record of Processing Activities (controller): [Name of the controller]",
+		"## Controller (Art. 30(1)(a))",
+		"## Processing activity: [Processing activity]",
+		"| **Purposes**: The purposes of the processing | Art. 30(1)(b) |",
+		"| Art. 30(1)(g) |",
+		"## Exemption (Art. 30(5) GDPR)",
+	} {
+		if !strings.Contains(template, want) {
+			t.Errorf("Expected the template to contain %q", want)
+		}
+	}
+	if strings.Contains(template, "Annex: Article 30") {
+		t.Error("Expected no article text without input")
+	}
+
+	template, err = RoPATemplate(RoPAInput{
+		Role:         RoleProcessor,
+		Organisation: "Acme Hosting",
+		Entries:      []string{"Shop GmbH", "Clinic SA"},
+		ArticleText:  "Article 30\n1. Each controller",
+	})
+	if err != nil {
+		t.Fatalf("RoPATemplate failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Record of Processing Activities (processor): Acme Hosting",
+		"## Processor (Art. 30(2)(a))",
+		"## Controller: Shop GmbH",
+		"## Controller: Clinic SA",
+		"| **Categories of processing**: The categories of processing carried out on behalf of each controller | Art. 30(2)(b) |",
+		"> Article 30\n> 1. Each controller\n",
+	} {
+		if !strings.Contains(template, want) {
+			t.Errorf("Expected the template to contain %q", want)
+		}
+	}
+	if strings.Contains(template, "Art. 30(1)") {
+		t.Error("Expected the processor template not to cite Article 30(1)")
+	}
+
+	if _, err := RoPATemplate(RoPAInput{Role: "processer"}); err == nil {
+		t.Error("Expected an unknown role to be rejected")
+	}
+}
@@ -0,0 +1,158 @@
+package guidance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SCCModule is one of the four modules of the standard contractual clauses
+// for transfers to third countries (Commission Implementing Decision (EU)
+// 2021/914), covering one combination of exporter and importer roles
+type SCCModule struct {
+	Number   int    `json:"number"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Exporter string `json:"exporter"` // RoleController or RoleProcessor
+	Importer string `json:"importer"`
+	Notes    string `json:"notes"`
+}
+
+// SCCModules are the modules of the clauses, in order
+var SCCModules = []SCCModule{
+	{1, "controller-to-controller", "Module One: Transfer controller to controller", RoleController, RoleController,
+		"The importer determines its own purposes and means. Clause 9 on sub-processors and Annex III do not apply."},
+	{2, "controller-to-processor", "Module Two: Transfer controller to processor", RoleController, RoleProcessor,
+		"The importer processes on the exporter's documented instructions; the clauses also meet the requirements of Article 28(3) and (4) for the processor contract."},
+	{3, "processor-to-processor", "Module Three: Transfer processor to processor", RoleProcessor, RoleProcessor,
+		"The importer is a sub-processor of an EU processor and follows the instructions of the controller, as passed on by the exporter; the clauses also meet the requirements of Article 28(4) for the sub-processor contract."},
+	{4, "processor-to-controller", "Module Four: Transfer processor to controller", RoleProcessor, RoleController,
+		"The exporter is an EU processor sending data back to the third-country controller it processes for. Clause 13 and Annexes I.C, II and III do not apply, and Clauses 14 and 15 only apply where the processor combines the data received from the controller with personal data it collected in the Union."},
+}
+
+// SCCClause is a clause or annex of the standard contractual clauses
+type SCCClause struct {
+	Clause  string `json:"clause"`            // e.g. "8" or "Annex II"
+	Title   string `json:"title"`             // e.g. "Data protection safeguards"
+	Section string `json:"section,omitempty"` // section in Roman numerals, e.g. "II"
+	Modules []int  `json:"modules"`           // modules the clause applies to
+}
+
+// allModules lists the modules of a clause applying to every module
+var allModules = []int{1, 2, 3, 4}
+
+// SCCClauses are the clauses and annexes of the standard contractual
+// clauses, in order
+var SCCClauses = []SCCClause{
+	{"1", "Purpose and scope", "I", allModules},
+	{"2", "Effect and invariability of the Clauses", "I", allModules},
+	{"3", "Third-party beneficiaries", "I", allModules},
+	{"4", "Interpretation", "I", allModules},
+	{"5", "Hierarchy", "I", allModules},
+	{"6", "Description of the transfer(s)", "I", allModules},
+	{"7", "Docking clause (optional)", "I", allModules},
+	{"8", "Data protection safeguards", "II", allModules},
+	{"9", "Use of sub-processors", "II", []int{2, 3}},
+	{"10", "Data subject rights", "II", allModules},
+	{"11", "Redress", "II", allModules},
+	{"12", "Liability", "II", allModules},
+	{"13", "Supervision", "II", []int{1, 2, 3}},
+	{"14", "Local laws and practices affecting compliance with the Clauses", "III", allModules},
+	{"15", "Obligations of the data importer in case of access by public authorities", "III", allModules},
+	{"16", "Non-compliance with the Clauses and termination", "IV", allModules},
+	{"17", "Governing law", "IV", allModules},
+	{"18", "Choice of forum and jurisdiction", "IV", allModules},
+	{"Annex I", "List of parties, description of the transfer and competent supervisory authority", "", allModules},
+	{"Annex II", "Technical and organisational measures, including to ensure the security of the data", "", []int{1, 2, 3}},
+	{"Annex III", "List of sub-processors", "", []int{2, 3}},
+}
+
+// SCCModuleByNumber returns the module with the given number, 1 to 4
+func SCCModuleByNumber(number int) (SCCModule, bool) {
+	if number < 1 || number > len(SCCModules) {
+		return SCCModule{}, false
+	}
+	return SCCModules[number-1], true
+}
+
+// SCCClauseByNumber returns the clause or annex with the given number, as
+// normalized by ingest.SCCClauseNumber: "8" or "Annex II"
+func SCCClauseByNumber(number string) (SCCClause, bool) {
+	for _, c := range SCCClauses {
+		if c.Clause == number {
+			return c, true
+		}
+	}
+	return SCCClause{}, false
+}
+
+// AppliesTo reports whether the clause applies to a module
+func (c SCCClause) AppliesTo(module int) bool {
+	for _, m := range c.Modules {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// SCCScenario describes a transfer for choosing the module of the clauses
+type SCCScenario struct {
+	Exporter string // RoleController or RoleProcessor
+	Importer string // RoleController or RoleProcessor
+	// ImporterSubjectToGDPR is set where the importer's processing is
+	// itself subject to the GDPR under Article 3(2)
+	ImporterSubjectToGDPR bool
+}
+
+// SCCSelection is the module of the clauses suited to a transfer, with
+// points to check before relying on it
+type SCCSelection struct {
+	Module *SCCModule `json:"module"` // nil where the clauses are not the right tool
+	Notes  []string   `json:"notes"`
+}
+
+// SelectSCCModule returns the module of the clauses matching the roles of
+// the exporter and importer of a transfer
+func SelectSCCModule(scenario SCCScenario) (SCCSelection, error) {
+	for _, role := range []string{scenario.Exporter, scenario.Importer} {
+		if role != RoleController && role != RoleProcessor {
+			return SCCSelection{}, fmt.Errorf("unknown role %q, expected %q or %q", role, RoleController, RoleProcessor)
+		}
+	}
+
+	if scenario.ImporterSubjectToGDPR {
+		return SCCSelection{Notes: []string{
+			"The clauses only cover importers whose processing is not subject to the GDPR (recital 7 and Article 1 of Decision (EU) 2021/914). An importer subject to the GDPR under Article 3(2) is bound by it directly; the Commission has announced separate clauses for this case.",
+			"Between a controller and a processor, an Article 28 contract is still required, for instance on the clauses of Decision (EU) 2021/915.",
+		}}, nil
+	}
+
+	var selected SCCModule
+	for _, m := range SCCModules {
+		if m.Exporter == scenario.Exporter && m.Importer == scenario.Importer {
+			selected = m
+		}
+	}
+	return SCCSelection{Module: &selected, Notes: []string{
+		selected.Notes,
+		"Carry out and document a transfer impact assessment of the laws and practices of the destination country, adopting supplementary measures where needed (Clause 14, Schrems II, C-311/18, EDPB Recommendations 01/2020).",
+		"No clauses are needed where the destination is covered by an adequacy decision (Article 45).",
+	}}, nil
+}
+
+// ParseSCCModule parses a module given by number ("2"), ID
+// ("controller-to-processor") or name ("Module Two")
+func ParseSCCModule(s string) (SCCModule, bool) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	s = strings.TrimPrefix(s, "module ")
+	if n, err := strconv.Atoi(s); err == nil {
+		return SCCModuleByNumber(n)
+	}
+	for i, word := range []string{"one", "two", "three", "four"} {
+		if s == word || s == SCCModules[i].ID {
+			return SCCModules[i], true
+		}
+	}
+	return SCCModule{}, false
+}
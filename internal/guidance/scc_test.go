@@ -0,0 +1,75 @@
+package guidance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSCCModules(t *testing.T) {
+	for i, m := range SCCModules {
+		if m.Number != i+1 {
+			t.Errorf("Expected module %d at position %d, got %d", i+1, i, m.Number)
+		}
+		if got, ok := SCCModuleByNumber(m.Number); !ok || got.ID != m.ID {
+			t.Errorf("SCCModuleByNumber(%d) = %+v, %v", m.Number, got, ok)
+		}
+	}
+	if _, ok := SCCModuleByNumber(5); ok {
+		t.Error("Expected no module 5")
+	}
+
+	for in, want := range map[string]int{
+		"2":                        2,
+		"Module Three":             3,
+		"four":                     4,
+		"controller-to-controller": 1,
+	} {
+		if m, ok := ParseSCCModule(in); !ok || m.Number != want {
+			t.Errorf("ParseSCCModule(%q) = %+v, %v, want module %d", in, m, ok, want)
+		}
+	}
+	if _, ok := ParseSCCModule("module five"); ok {
+		t.Error("Expected module five to be rejected")
+	}
+}
+
+func TestSCCClauses(t *testing.T) {
+	clause, ok := SCCClauseByNumber("9")
+	if !ok || clause.Title != "Use of sub-processors" || clause.AppliesTo(1) || !clause.AppliesTo(2) {
+		t.Errorf("Expected clause 9 to apply to modules 2 and 3 only, got %+v", clause)
+	}
+	if clause, ok := SCCClauseByNumber("Annex II"); !ok || clause.AppliesTo(4) {
+		t.Errorf("Expected Annex II not to apply to module 4, got %+v", clause)
+	}
+	if _, ok := SCCClauseByNumber("19"); ok {
+		t.Error("Expected no clause 19")
+	}
+}
+
+func TestSelectSCCModule(t *testing.T) {
+	for _, c := range []struct {
+		exporter, importer string
+		want               int
+	}{
+		{RoleController, RoleController, 1},
+		{RoleController, RoleProcessor, 2},
+		{RoleProcessor, RoleProcessor, 3},
+		{RoleProcessor, RoleController, 4},
+	} {
+		selection, err := SelectSCCModule(SCCScenario{Exporter: c.exporter, Importer: c.importer})
+		if err != nil {
+			t.Fatalf("SelectSCCModule failed: %v", err)
+		}
+		if selection.Module == nil || selection.Module.Number != c.want || len(selection.Notes) == 0 {
+			t.Errorf("%s to %s: expected module %d, got %+v", c.exporter, c.importer, c.want, selection)
+		}
+	}
+
+	selection, err := SelectSCCModule(SCCScenario{Exporter: RoleController, Importer: RoleProcessor, ImporterSubjectToGDPR: true})
+	if err != nil || selection.Module != nil || !strings.Contains(selection.Notes[0], "Article 3(2)") {
+		t.Errorf("Expected no module for an importer subject to the GDPR, got %+v, %v", selection, err)
+	}
+	if _, err := SelectSCCModule(SCCScenario{Exporter: "joint-controller", Importer: RoleProcessor}); err == nil {
+		t.Error("Expected an unknown role to be rejected")
+	}
+}
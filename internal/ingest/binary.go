@@ -0,0 +1,81 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrNotText is returned when a file meant to hold text, Markdown or XML
+// holds binary data instead
+var ErrNotText = errors.New("not a text file")
+
+const (
+	// binarySniffLen is how much of the content is inspected
+	binarySniffLen = 8000
+	// maxSuspiciousRatio is the share of control characters and invalid
+	// UTF-8 tolerated in text, leaving room for the odd Latin-1 accent
+	maxSuspiciousRatio = 0.1
+)
+
+// checkText returns an error wrapping ErrNotText when content looks binary:
+// it contains a NUL byte, or too many control characters, replacement
+// characters or invalid UTF-8 sequences. Only the start of the content is
+// inspected.
+func checkText(source string, content []byte) error {
+	sample := content
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+
+	runes, suspicious := 0, 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		switch {
+		case r == 0:
+			return notTextError(source, fmt.Sprintf("NUL byte at offset %d", i))
+		case r == utf8.RuneError && size == 3, r >= 0x80 && r < 0xa0:
+			// Replacement characters and C1 controls are what bytes a
+			// legacy encoding leaves undefined decode to
+			suspicious++
+		case r == utf8.RuneError && size == 1:
+			// A sequence cut off by the end of the sample is not suspicious
+			if i+utf8.UTFMax <= len(sample) || len(sample) == len(content) {
+				suspicious++
+			}
+		case r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f', r == 0x7f:
+			suspicious++
+		}
+		runes++
+		i += size
+	}
+
+	if runes > 0 && float64(suspicious)/float64(runes) > maxSuspiciousRatio {
+		return notTextError(source, fmt.Sprintf("%d of the first %d characters are control characters or invalid UTF-8", suspicious, runes))
+	}
+	return nil
+}
+
+// textContent transcodes content expected to be text to UTF-8 and checks
+// that it is text
+func (ing *Ingester) textContent(source string, content []byte) ([]byte, error) {
+	text, _, err := toUTF8(content, ing.config.Charset, ing.config.Language)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", displaySource(source), err)
+	}
+	if err := checkText(source, text); err != nil {
+		return nil, err
+	}
+	return text, nil
+}
+
+func displaySource(source string) string {
+	if source == "" || source == StdinSource {
+		return "input"
+	}
+	return source
+}
+
+func notTextError(source, reason string) error {
+	return fmt.Errorf("%s: %w (%s); supported formats are plain text, Markdown, PDF and Formex or Akoma Ntoso XML", displaySource(source), ErrNotText, reason)
+}
@@ -0,0 +1,92 @@
+package ingest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		text    bool
+	}{
+		{"plain", []byte("Article 17\nRight to erasure\tapplies.\r\n"), true},
+		{"utf8", []byte("Recht auf Löschung – Artikel 17 «Droit à l'effacement»"), true},
+		{"latin1", []byte("Recht auf L\xf6schung personenbezogener Daten nach Artikel 17"), true},
+		{"empty", nil, true},
+		{"nul", []byte("PK\x03\x04\x00\x00zip"), false},
+		{"control", []byte("\x01\x02\x03\x04\x05abc\x06\x07\x08\x0e\x0f"), false},
+		{"garbage", []byte("\xff\xfe\xfd\xfc\xfb\xfa\xf9\xf8 some text"), false},
+	}
+	for _, tt := range tests {
+		err := checkText("file.txt", tt.content)
+		if tt.text && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.text && !errors.Is(err, ErrNotText) {
+			t.Errorf("%s: expected ErrNotText, got %v", tt.name, err)
+		}
+	}
+
+	// A multi-byte character cut off at the end of the sample is fine
+	long := strings.Repeat("a", binarySniffLen-1) + "ö"
+	if err := checkText("file.txt", []byte(long)); err != nil {
+		t.Errorf("Unexpected error for a character split by the sample: %v", err)
+	}
+}
+
+func TestIngestFileRejectsBinary(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "gdpr.txt")
+	if err := os.WriteFile(path, []byte("GDPR\x00\x00\x00\x01binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewWithEmbedder(database, DefaultConfig(), &fakeEmbedder{}).IngestFile(path)
+	if !errors.Is(err, ErrNotText) {
+		t.Fatalf("Expected ErrNotText, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "NUL byte at offset 4") {
+		t.Errorf("Expected the error to say why, got %v", err)
+	}
+	if n := countChunks(t, database); n != 0 {
+		t.Errorf("Expected nothing to be ingested, got %d chunks", n)
+	}
+}
+
+func TestIngestDirSkipsBinary(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "art17.txt"), []byte("The data subject shall have the right to erasure."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "scan.md"), []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var rejected []string
+	config := DefaultConfig()
+	config.Progress = ProgressFunc(func(p Progress) {
+		if p.Stage == StageRejected {
+			rejected = append(rejected, filepath.Base(p.Source))
+		}
+	})
+	result, err := NewWithEmbedder(database, config, &fakeEmbedder{}).IngestDir(dir)
+	if err != nil {
+		t.Fatalf("IngestDir failed: %v", err)
+	}
+	if result != (DirResult{Added: 1, Rejected: 1}) {
+		t.Errorf("IngestDir = %+v, want 1 added and 1 rejected", result)
+	}
+	if len(rejected) != 1 || rejected[0] != "scan.md" {
+		t.Errorf("Expected a warning for scan.md, got %v", rejected)
+	}
+}
@@ -0,0 +1,110 @@
+package ingest
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CaseLawCollection is the collection judgments go into unless the
+// configuration names another
+const CaseLawCollection = "caselaw"
+
+// Metadata keys describing the judgment a chunk comes from
+const (
+	MetaCaseNumber        = "case_number"        // e.g. "C-311/18"
+	MetaCaseName          = "case_name"          // parties, e.g. "Data Protection Commissioner v Facebook Ireland Ltd"
+	MetaCaseAlias         = "case_alias"         // name the case is known by, e.g. "Schrems II"
+	MetaECLI              = "ecli"               // e.g. "ECLI:EU:C:2020:559"
+	MetaJudgmentDate      = "judgment_date"      // YYYY-MM-DD
+	MetaJudgmentParagraph = "judgment_paragraph" // numbered paragraph of the judgment, e.g. "134"
+)
+
+var (
+	// "Case C‑311/18", "Joined Cases C-293/12 and C-594/12" or "T-192/16";
+	// EUR-Lex writes a non-breaking hyphen
+	caseNumberRe = regexp.MustCompile(`\b([CT])\s?[-‑–]\s?(\d{1,4}/\d{2})\b`)
+	ecliRe       = regexp.MustCompile(`\bECLI:EU:[CTF]:\d{4}:\d+\b`)
+	// "JUDGMENT OF THE COURT (Grand Chamber)" followed by "16 July 2020"
+	judgmentDateRe = regexp.MustCompile(`(?i)judgment of the (?:general )?court[^\n]*\n\s*(\d{1,2} [A-Za-z]+ \d{4})`)
+	// "in the proceedings" followed by the parties on either side of "v"
+	partiesRe = regexp.MustCompile(`(?i)in the proceedings\s*\n\s*([^\n]+)\n(?:[^\n]*\n){0,6}?\s*v\s*\n\s*([^\n]+)`)
+	// "134      It follows that ...", the start of a numbered paragraph
+	judgmentParagraphRe = regexp.MustCompile(`^(\d{1,3})\.?\s+([\p{Lu}‘'"“(].*)$`)
+	// "16 July 2020 (*)", a date rather than a paragraph
+	dateLineRe = regexp.MustCompile(`^\d{1,2} (?:January|February|March|April|May|June|July|August|September|October|November|December) \d{4}\b`)
+)
+
+// CaseAliases are the names well-known CJEU data protection judgments are
+// cited by, by case number
+var CaseAliases = map[string]string{
+	"C-101/01": "Lindqvist",
+	"C-293/12": "Digital Rights Ireland",
+	"C-131/12": "Google Spain",
+	"C-362/14": "Schrems I",
+	"C-582/14": "Breyer",
+	"C-203/15": "Tele2 Sverige",
+	"C-210/16": "Wirtschaftsakademie Schleswig-Holstein",
+	"C-434/16": "Nowak",
+	"C-25/17":  "Jehovan todistajat",
+	"C-40/17":  "Fashion ID",
+	"C-136/17": "GC and Others",
+	"C-507/17": "Google v CNIL",
+	"C-623/17": "Privacy International",
+	"C-673/17": "Planet49",
+	"C-311/18": "Schrems II",
+	"C-511/18": "La Quadrature du Net",
+	"C-645/19": "Facebook Ireland and Others",
+	"C-252/21": "Meta Platforms v Bundeskartellamt",
+	"C-300/21": "Österreichische Post",
+	"C-340/21": "Natsionalna agentsia za prihodite",
+	"C-634/21": "SCHUFA Holding (Scoring)",
+	"C-683/21": "Nacionalinis visuomenės sveikatos centras",
+	"C-807/21": "Deutsche Wohnen",
+}
+
+// CaseNumber parses a case number such as "C-311/18", "Case C‑311/18" or
+// "c-311/18" into the form stored in chunk metadata
+func CaseNumber(s string) (string, bool) {
+	m := caseNumberRe.FindStringSubmatch(strings.ToUpper(s))
+	if m == nil {
+		return "", false
+	}
+	return m[1] + "-" + m[2], true
+}
+
+// judgmentMetadata returns the case number, name, ECLI and date found in
+// the text of a judgment, normally on its first page. The case number is
+// the first one mentioned, the lead case of joined cases.
+func judgmentMetadata(text string) map[string]string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	m := make(map[string]string)
+	if number, ok := CaseNumber(text); ok {
+		m[MetaCaseNumber] = number
+		if alias, ok := CaseAliases[number]; ok {
+			m[MetaCaseAlias] = alias
+		}
+	}
+	if ecli := ecliRe.FindString(text); ecli != "" {
+		m[MetaECLI] = ecli
+	}
+	if d := judgmentDateRe.FindStringSubmatch(text); d != nil {
+		if date, err := time.Parse("2 January 2006", d[1]); err == nil {
+			m[MetaJudgmentDate] = date.Format("2006-01-02")
+		}
+	}
+	if p := partiesRe.FindStringSubmatch(text); p != nil {
+		m[MetaCaseName] = strings.TrimRight(strings.TrimSpace(p[1]), ",") + " v " + strings.TrimRight(strings.TrimSpace(p[2]), ",")
+	}
+	return m
+}
+
+// judgmentParagraph returns the number of a line starting a numbered
+// paragraph of a judgment, or "" for other lines
+func judgmentParagraph(line string) string {
+	m := judgmentParagraphRe.FindStringSubmatch(line)
+	if m == nil || dateLineRe.MatchString(line) {
+		return ""
+	}
+	return m[1]
+}
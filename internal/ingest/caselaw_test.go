@@ -0,0 +1,107 @@
+package ingest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+const judgmentFixture = `JUDGMENT OF THE COURT (Grand Chamber)
+16 July 2020 (*)
+
+(Reference for a preliminary ruling — Protection of individuals with regard to the processing of personal data)
+
+In Case C‑311/18,
+
+REQUEST for a preliminary ruling under Article 267 TFEU from the High Court (Ireland), in the proceedings
+
+Data Protection Commissioner
+v
+Facebook Ireland Ltd,
+Maximillian Schrems,
+
+THE COURT (Grand Chamber),
+
+gives the following
+
+Judgment
+
+1        This request for a preliminary ruling concerns, in essence, the interpretation of Article 3(2), Articles 44 to 46 of the GDPR.
+2        The request has been made in proceedings between the Data Protection Commissioner and Facebook Ireland Ltd.
+134      It follows that standard data protection clauses adopted by the Commission may require supplementary measures.
+
+ECLI:EU:C:2020:559`
+
+func TestJudgmentMetadata(t *testing.T) {
+	want := map[string]string{
+		MetaCaseNumber:   "C-311/18",
+		MetaCaseAlias:    "Schrems II",
+		MetaCaseName:     "Data Protection Commissioner v Facebook Ireland Ltd",
+		MetaECLI:         "ECLI:EU:C:2020:559",
+		MetaJudgmentDate: "2020-07-16",
+	}
+	if got := judgmentMetadata(judgmentFixture); !reflect.DeepEqual(got, want) {
+		t.Errorf("judgmentMetadata = %v, want %v", got, want)
+	}
+	if got := judgmentMetadata("In Joined Cases C-293/12 and C-594/12"); got[MetaCaseNumber] != "C-293/12" || got[MetaCaseAlias] != "Digital Rights Ireland" {
+		t.Errorf("Expected the lead case of joined cases, got %v", got)
+	}
+}
+
+func TestCaseNumber(t *testing.T) {
+	for input, want := range map[string]string{
+		"C-311/18":      "C-311/18",
+		"Case C‑311/18": "C-311/18",
+		"c – 131/12":    "C-131/12",
+		"T-192/16":      "T-192/16",
+		"Schrems II":    "",
+	} {
+		if got, _ := CaseNumber(input); got != want {
+			t.Errorf("CaseNumber(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestJudgmentParagraph(t *testing.T) {
+	for line, want := range map[string]string{
+		"134      It follows that standard data protection clauses": "134",
+		"1.  In the present case, the referring court asks":         "1",
+		"16 July 2020 (*)":                      "",
+		"2018, the Commissioner brought action": "",
+		"Judgment":                              "",
+	} {
+		if got := judgmentParagraph(line); got != want {
+			t.Errorf("judgmentParagraph(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestIngestCaseLawProfile(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Profile = ProfileCaseLaw
+	config.ChunkSize = 200
+	config.ChunkOverlap = 0
+	if err := NewWithEmbedder(database, config, &fakeEmbedder{}).IngestText(judgmentFixture); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("supplementary measures", 1, db.SearchOptions{Collection: CaseLawCollection})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("SearchTrigrams = %+v, %v", results, err)
+	}
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil || doc == nil {
+		t.Fatalf("GetDocument = %v, %v", doc, err)
+	}
+	if doc.Metadata[MetaCaseNumber] != "C-311/18" || doc.Metadata[MetaJudgmentParagraph] != "134" {
+		t.Errorf("Unexpected metadata %v", doc.Metadata)
+	}
+	// The judgment cites Articles 44 to 46 but is not part of them
+	if _, ok := doc.Metadata[MetaArticle]; ok {
+		t.Errorf("Expected no article metadata, got %v", doc.Metadata)
+	}
+}
@@ -0,0 +1,124 @@
+package ingest
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// languageCharsets maps ISO 639-1 codes to the legacy 8-bit encoding older
+// texts in the language usually come in; other languages default to
+// Windows-1252, a superset of ISO-8859-1
+var languageCharsets = map[string]encoding.Encoding{
+	"cs": charmap.Windows1250,
+	"sk": charmap.Windows1250,
+	"pl": charmap.Windows1250,
+	"hu": charmap.Windows1250,
+	"sl": charmap.Windows1250,
+	"hr": charmap.Windows1250,
+	"ro": charmap.Windows1250,
+	"el": charmap.ISO8859_7,
+	"bg": charmap.Windows1251,
+	"lt": charmap.Windows1257,
+	"lv": charmap.Windows1257,
+	"et": charmap.Windows1257,
+	"mt": charmap.ISO8859_3,
+}
+
+// xmlEncodingRe matches the encoding in an XML declaration
+var xmlEncodingRe = regexp.MustCompile(`^(<\?xml[^>]*\bencoding=["'])([A-Za-z0-9._:-]+)(["'])`)
+
+// toUTF8 transcodes text input to UTF-8. A forced charset wins; otherwise a
+// byte order mark, UTF-16 without one, or the encoding declared by an XML
+// document is honoured. Valid UTF-8 is returned unchanged, and anything else
+// is decoded with the legacy encoding of lang. The name of the encoding
+// found is returned alongside.
+func toUTF8(content []byte, charset, lang string) ([]byte, string, error) {
+	if charset != "" {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, "", fmt.Errorf("unknown charset %q", charset)
+		}
+		return decodeWith(content, enc, charset)
+	}
+
+	switch {
+	case bytes.HasPrefix(content, []byte("\xef\xbb\xbf")):
+		return content[3:], "utf-8", nil
+	case bytes.HasPrefix(content, []byte("\xff\xfe")), bytes.HasPrefix(content, []byte("\xfe\xff")):
+		return decodeWith(content, unicode.UTF16(unicode.BigEndian, unicode.UseBOM), "utf-16")
+	}
+	if order, ok := utf16Order(content); ok {
+		return decodeWith(content, unicode.UTF16(order, unicode.IgnoreBOM), "utf-16")
+	}
+
+	if m := xmlEncodingRe.FindSubmatchIndex(content); m != nil {
+		name := strings.ToLower(string(content[m[4]:m[5]]))
+		if name != "utf-8" && name != "utf8" {
+			enc, err := htmlindex.Get(name)
+			if err != nil {
+				return nil, "", fmt.Errorf("unsupported XML encoding %q", name)
+			}
+			decoded, _, err := decodeWith(content, enc, name)
+			if err != nil {
+				return nil, "", err
+			}
+			// The declaration must match the transcoded content
+			return xmlEncodingRe.ReplaceAll(decoded, []byte("${1}UTF-8${3}")), name, nil
+		}
+	}
+
+	if utf8.Valid(content) {
+		return content, "utf-8", nil
+	}
+	if enc, ok := languageCharsets[strings.ToLower(lang)]; ok {
+		name, _ := htmlindex.Name(enc)
+		return decodeWith(content, enc, name)
+	}
+	return decodeWith(content, charmap.Windows1252, "windows-1252")
+}
+
+func decodeWith(content []byte, enc encoding.Encoding, name string) ([]byte, string, error) {
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return decoded, name, nil
+}
+
+// utf16Order recognizes UTF-16 without a byte order mark by the NUL high
+// bytes of Latin text: mostly NUL at odd offsets means little-endian, at
+// even offsets big-endian
+func utf16Order(content []byte) (unicode.Endianness, bool) {
+	sample := content
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	if len(sample) < 4 || len(sample)%2 != 0 {
+		return unicode.LittleEndian, false
+	}
+	var even, odd int
+	for i := 0; i < len(sample); i += 2 {
+		if sample[i] == 0 {
+			even++
+		}
+		if sample[i+1] == 0 {
+			odd++
+		}
+	}
+	pairs := len(sample) / 2
+	switch {
+	case odd*10 >= pairs*8 && even == 0:
+		return unicode.LittleEndian, true
+	case even*10 >= pairs*8 && odd == 0:
+		return unicode.BigEndian, true
+	}
+	return unicode.LittleEndian, false
+}
@@ -0,0 +1,80 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		charset  string
+		lang     string
+		want     string
+		encoding string
+	}{
+		{"utf-8", "Recht auf Löschung", "", "", "Recht auf Löschung", "utf-8"},
+		{"bom", "\xef\xbb\xbfArtikel 17", "", "", "Artikel 17", "utf-8"},
+		{"windows-1252", "Recht auf L\xf6schung \x96 \x84Artikel 17\x93", "", "", "Recht auf Löschung – „Artikel 17“", "windows-1252"},
+		{"latin-2 by language", "Prawo do usuni\xeacia danych \x9cwiadomie", "", "pl", "Prawo do usunięcia danych świadomie", "windows-1250"},
+		{"greek by language", "\xc4\xe9\xe1\xe3\xf1\xe1\xf6\xde", "", "el", "Διαγραφή", "iso-8859-7"},
+		{"utf-16le bom", "\xff\xfeA\x00r\x00t\x00.\x00", "", "", "Art.", "utf-16"},
+		{"utf-16be bom", "\xfe\xff\x00A\x00r\x00t\x00.", "", "", "Art.", "utf-16"},
+		{"utf-16le", "A\x00r\x00t\x00i\x00k\x00e\x00l\x00", "", "", "Artikel", "utf-16"},
+		{"forced", "Recht auf L\xf6schung", "iso-8859-1", "", "Recht auf Löschung", "iso-8859-1"},
+		{"xml declaration", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><p>L\xf6schung</p>", "", "",
+			"<?xml version=\"1.0\" encoding=\"UTF-8\"?><p>Löschung</p>", "iso-8859-1"},
+	}
+	for _, tt := range tests {
+		got, encoding, err := toUTF8([]byte(tt.content), tt.charset, tt.lang)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want || encoding != tt.encoding {
+			t.Errorf("%s: got %q as %s, want %q as %s", tt.name, got, encoding, tt.want, tt.encoding)
+		}
+	}
+
+	if _, _, err := toUTF8([]byte("text"), "klingon-8", ""); err == nil {
+		t.Error("Expected an unknown charset to be rejected")
+	}
+}
+
+func TestIngestTranscodesLegacyEncodings(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"art17.txt": "Artikel 17 \x96 Recht auf L\xf6schung personenbezogener Daten",
+		"art17.xml": "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+			"<ACT><ENACTING.TERMS><ARTICLE><TI.ART>Article 17</TI.ART><ALINEA>Droit \xe0 l'effacement des donn\xe9es</ALINEA></ARTICLE></ENACTING.TERMS></ACT>",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ing := NewWithEmbedder(database, DefaultConfig(), &fakeEmbedder{})
+	for name := range files {
+		if err := ing.IngestFile(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("IngestFile(%s) failed: %v", name, err)
+		}
+	}
+
+	for _, query := range []string{"Löschung", "données"} {
+		results, err := database.SearchTrigrams(query, 1, db.SearchOptions{})
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Score != 1 {
+			t.Errorf("Expected a full match for %q, got %+v", query, results)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// chatCompletion sends a system and a user message to an OpenAI compatible
+// chat completions API and returns the reply. Temperature 0 keeps replies
+// repeatable.
+func chatCompletion(endpoint EndpointConfig, system, user string) (string, error) {
+	jsonBody, err := json.Marshal(map[string]interface{}{
+		"model":       endpoint.Model,
+		"temperature": 0,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": user},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	header, value := endpoint.authHeader()
+	body, err := postEmbeddingRequest(strings.TrimRight(endpoint.URL, "/")+"/chat/completions", jsonBody, header, value)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("chat response has no choices")
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+// chatEndpoint fills in the default chat API and model, requiring an API key
+// for OpenAI
+func chatEndpoint(endpoint EndpointConfig, purpose string) (EndpointConfig, error) {
+	if endpoint.URL == "" {
+		endpoint.URL = DefaultChatURL
+	}
+	if endpoint.Model == "" {
+		endpoint.Model = DefaultChatModel
+	}
+	if endpoint.URL == DefaultChatURL && endpoint.AuthHeader == "" {
+		return endpoint, fmt.Errorf("%s with OpenAI needs an API key", purpose)
+	}
+	return endpoint, nil
+}
+
+// jsonObject returns the outermost JSON object in a chat reply, tolerating
+// text or a code fence around it
+func jsonObject(content string) ([]byte, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON in chat response: %q", content)
+	}
+	return []byte(content[start : end+1]), nil
+}
@@ -0,0 +1,65 @@
+package ingest
+
+import (
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// CheckEmbedder embeds a test query with the configured embedder and
+// reports whether it works and matches the embeddings stored in database:
+// queries embedded by a different model than the chunks find nothing
+// relevant by vector search.
+func CheckEmbedder(database *db.DB, config Config, embedder Embedder) ([]db.Problem, error) {
+	name := config.EmbedderName()
+	if embedder == nil {
+		var err error
+		if embedder, err = NewEmbedder(config.Embedder, config); err != nil {
+			return []db.Problem{{
+				Check:   db.CheckEmbeddings,
+				Message: fmt.Sprintf("The %s embedder cannot be created: %v", name, err),
+				Fix:     "Fix the embedder settings, or select another embedder with GDPR_MCP_EMBEDDER",
+			}}, nil
+		}
+	}
+
+	embedding, err := embedder.EmbedQuery("right to erasure")
+	if err != nil {
+		return []db.Problem{{
+			Check:   db.CheckEmbeddings,
+			Message: fmt.Sprintf("The %s embedder failed to embed a test query: %v", name, err),
+			Fix:     "Check that the embeddings service is running and reachable, and that its API key and model name are correct",
+		}}, nil
+	}
+
+	stats, err := database.Stats()
+	if err != nil {
+		return nil, err
+	}
+	var problems []db.Problem
+	for _, dimensions := range stats.Dimensions {
+		if dimensions != len(embedding) {
+			problems = append(problems, db.Problem{
+				Check:   db.CheckEmbeddings,
+				Message: fmt.Sprintf("The %s embedder produces %d-dimensional embeddings, but the index holds %d-dimensional ones", name, len(embedding), dimensions),
+				Fix:     "Configure the embedder the corpus was ingested with, or recompute the embeddings with reindex",
+			})
+		}
+	}
+	if len(problems) == 0 && stats.Embedder != "" && (stats.Embedder != name || stats.EmbeddingModel != config.EmbeddingModel()) {
+		problems = append(problems, db.Problem{
+			Check:   db.CheckEmbeddings,
+			Message: fmt.Sprintf("The corpus was embedded with %s, but queries are embedded with %s", embedderLabel(stats.Embedder, stats.EmbeddingModel), embedderLabel(name, config.EmbeddingModel())),
+			Fix:     "Configure the embedder the corpus was ingested with, or recompute the embeddings with reindex",
+		})
+	}
+	return problems, nil
+}
+
+// embedderLabel names an embedder with its model, if it has one
+func embedderLabel(name, model string) string {
+	if model == "" {
+		return name
+	}
+	return name + " (" + model + ")"
+}
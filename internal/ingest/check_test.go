@@ -0,0 +1,67 @@
+package ingest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// brokenEmbedder fails every request
+type brokenEmbedder struct{}
+
+func (brokenEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (brokenEmbedder) EmbedQuery(text string) ([]float32, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestCheckEmbedder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	problems, err := CheckEmbedder(database, config, nil)
+	if err != nil {
+		t.Fatalf("CheckEmbedder failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected the stub embedder to pass on an empty index, got %+v", problems)
+	}
+
+	problems, err = CheckEmbedder(database, config, brokenEmbedder{})
+	if err != nil {
+		t.Fatalf("CheckEmbedder failed: %v", err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "connection refused") {
+		t.Errorf("Expected the failed request to be reported, got %+v", problems)
+	}
+
+	// The fake embedder's 2 dimensions do not match the stored 3
+	id, err := database.InsertChunk("Right to erasure", 0)
+	if err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+	if err := database.InsertEmbedding(id, []float32{1, 0, 0}); err != nil {
+		t.Fatalf("InsertEmbedding failed: %v", err)
+	}
+	problems, err = CheckEmbedder(database, config, &fakeEmbedder{})
+	if err != nil {
+		t.Fatalf("CheckEmbedder failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Check != db.CheckEmbeddings || !strings.Contains(problems[0].Message, "2-dimensional") {
+		t.Errorf("Expected the dimension mismatch to be reported, got %+v", problems)
+	}
+
+	config.Embedder = "no-such-embedder"
+	problems, err = CheckEmbedder(database, config, nil)
+	if err != nil {
+		t.Fatalf("CheckEmbedder failed: %v", err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "cannot be created") {
+		t.Errorf("Expected the unknown embedder to be reported, got %+v", problems)
+	}
+}
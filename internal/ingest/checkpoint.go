@@ -0,0 +1,142 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// chunkFingerprint identifies the chunks of a source with their metadata
+// and where and how they are stored: the embedder and its model, the
+// collection, the profile and the language. A checkpoint is only resumed
+// when all of them are unchanged. The embedder comes first, before a colon,
+// so that re-embedding can update it.
+func (ing *Ingester) chunkFingerprint(collection string, chunks []sectionChunk) string {
+	return ing.fingerprint([]string{collection, ing.config.Profile, ing.config.Language}, chunks)
+}
+
+// inputFingerprint identifies the sections of a source before chunking,
+// along with the settings chunkFingerprint covers and those of the chunker.
+// It stands in for chunkFingerprint with chunkers that embed text, so that
+// a completed source is recognised without embedding it again.
+func (ing *Ingester) inputFingerprint(collection string, sections []Section) string {
+	settings := []string{
+		collection, ing.config.Profile, ing.config.Language,
+		ing.config.Strategy, ing.config.Tokenizer,
+		fmt.Sprint(ing.config.ChunkSize), fmt.Sprint(ing.config.ChunkOverlap), fmt.Sprint(ing.config.SemanticPercentile),
+	}
+	input := make([]sectionChunk, len(sections))
+	for i, section := range sections {
+		input[i] = sectionChunk{section.Text, section.Metadata}
+	}
+	return ing.fingerprint(settings, input)
+}
+
+// fingerprint hashes settings and the texts with their metadata, prefixed
+// by the embedder
+func (ing *Ingester) fingerprint(settings []string, texts []sectionChunk) string {
+	h := sha256.New()
+	for _, field := range settings {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	for _, c := range texts {
+		h.Write([]byte{0})
+		h.Write([]byte(c.text))
+		keys := make([]string, 0, len(c.metadata))
+		for key := range c.metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			h.Write([]byte{0})
+			h.Write([]byte(key + "=" + c.metadata[key]))
+		}
+	}
+	return embedderFingerprint(ing.config) + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// embedderFingerprint identifies the embedder of config and its model
+func embedderFingerprint(config Config) string {
+	sum := sha256.Sum256([]byte(config.EmbedderName() + "\x00" + config.EmbeddingModel()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// withEmbedder returns fingerprint with its embedder replaced by that of
+// config, or "" for a fingerprint without one
+func withEmbedder(fingerprint string, config Config) string {
+	_, chunks, ok := strings.Cut(fingerprint, ":")
+	if !ok {
+		return ""
+	}
+	return embedderFingerprint(config) + ":" + chunks
+}
+
+// checkpointed reports whether ingestion of source is checkpointed.
+// Standard input names no document, so each piped ingest is a new one.
+func checkpointed(source string) bool {
+	return source != "" && source != StdinSource
+}
+
+// completed returns the number of chunks of source if an earlier run with
+// the same fingerprint ingested all of them, and 0 otherwise
+func (ing *Ingester) completed(source, fingerprint string) (int, error) {
+	if !checkpointed(source) {
+		return 0, nil
+	}
+	cp, err := ing.db.GetCheckpoint(source)
+	if err != nil || cp == nil || cp.Fingerprint != fingerprint || !cp.Complete() {
+		return 0, err
+	}
+	return cp.TotalChunks, nil
+}
+
+// resume returns the index of the first chunk of source still to be
+// ingested, removing chunks stored after the last checkpoint of an
+// interrupted run. A run with a different fingerprint starts over and
+// removes the chunks of the previous one.
+func (ing *Ingester) resume(source, fingerprint string, total int) (int, error) {
+	if !checkpointed(source) {
+		return 0, nil
+	}
+
+	cp, err := ing.db.GetCheckpoint(source)
+	if err != nil {
+		return 0, err
+	}
+
+	first := 0
+	if cp != nil && cp.Fingerprint == fingerprint {
+		if cp.Complete() {
+			return total, nil
+		}
+		first = cp.NextChunk
+	}
+
+	// Chunks stored past the checkpoint belong to a batch that never
+	// completed; drop them rather than duplicate them. A changed source
+	// or embedder replaces all chunks of the previous run, complete or not.
+	if cp != nil && (!cp.Complete() || cp.Fingerprint != fingerprint) {
+		removed, err := ing.db.DeleteSourceChunks(source, first)
+		if err != nil {
+			return 0, err
+		}
+		if removed > 0 {
+			ing.report(StageCleaned, source, removed, total)
+		}
+	}
+
+	if err := ing.db.SaveCheckpoint(db.Checkpoint{
+		Source:      source,
+		Fingerprint: fingerprint,
+		NextChunk:   first,
+		TotalChunks: total,
+	}); err != nil {
+		return 0, err
+	}
+	return first, nil
+}
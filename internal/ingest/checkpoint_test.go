@@ -0,0 +1,231 @@
+package ingest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// failingEmbedder fails every call after the first ok calls
+type failingEmbedder struct {
+	fakeEmbedder
+	ok int
+}
+
+func (f *failingEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	if f.ok == 0 {
+		return nil, fmt.Errorf("embeddings API unavailable")
+	}
+	f.ok--
+	return f.fakeEmbedder.EmbedDocuments(texts)
+}
+
+func countChunks(t *testing.T, database *db.DB) int {
+	t.Helper()
+	n := 0
+	for id := int64(1); id < 100; id++ {
+		if doc, _ := database.GetDocument(id); doc != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestIngestResumesFromCheckpoint(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var sb strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&sb, "Article %d concerns the processing of personal data by controllers.\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "gdpr.txt")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	config.ChunkSize = 80
+	config.ChunkOverlap = 0
+	config.BatchSize = 3
+
+	// The API goes away after two batches
+	failing := &failingEmbedder{ok: 2}
+	if err := NewWithEmbedder(database, config, failing).IngestFile(path); err == nil {
+		t.Fatal("Expected the interrupted ingestion to fail")
+	}
+	if n := countChunks(t, database); n != 6 {
+		t.Fatalf("Expected 6 chunks stored before the failure, got %d", n)
+	}
+
+	source, _ := filepath.Abs(path)
+	cp, err := database.GetCheckpoint(source)
+	if err != nil || cp == nil {
+		t.Fatalf("GetCheckpoint = %v, %v", cp, err)
+	}
+	if cp.NextChunk != 6 || cp.TotalChunks != 10 {
+		t.Errorf("Unexpected checkpoint %+v", cp)
+	}
+
+	// A chunk written after the checkpoint by a batch that never finished
+	if _, err := database.InsertDocument(db.Document{Chunk: "partial", ChunkIndex: 6, Source: source}); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := &fakeEmbedder{}
+	if err := NewWithEmbedder(database, config, resumed).IngestFile(path); err != nil {
+		t.Fatalf("Resumed ingestion failed: %v", err)
+	}
+	if len(resumed.documents) != 4 || !strings.Contains(resumed.documents[0], "Article 7 ") {
+		t.Errorf("Expected only the last 4 chunks to be embedded, got %q", resumed.documents)
+	}
+	if n := countChunks(t, database); n != 10 {
+		t.Errorf("Expected 10 chunks without duplicates, got %d", n)
+	}
+
+	again := &fakeEmbedder{}
+	if err := NewWithEmbedder(database, config, again).IngestFile(path); err != nil {
+		t.Fatalf("Repeated ingestion failed: %v", err)
+	}
+	if len(again.documents) != 0 || countChunks(t, database) != 10 {
+		t.Errorf("Expected a completed source to be skipped, embedded %d chunks", len(again.documents))
+	}
+}
+
+func TestIngestRestartsChangedSource(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.ChunkSize = 40
+	config.ChunkOverlap = 0
+	config.BatchSize = 1
+
+	failing := &failingEmbedder{ok: 1}
+	sections := []Section{{Text: "Processing shall be lawful. Consent shall be freely given."}}
+	if err := NewWithEmbedder(database, config, failing).IngestSource("gdpr", sections); err == nil {
+		t.Fatal("Expected the interrupted ingestion to fail")
+	}
+
+	changed := []Section{{Text: "Processing shall be fair. Consent shall be informed. Data shall be minimised."}}
+	fake := &fakeEmbedder{}
+	if err := NewWithEmbedder(database, config, fake).IngestSource("gdpr", changed); err != nil {
+		t.Fatalf("IngestSource failed: %v", err)
+	}
+	if len(fake.documents) != 3 {
+		t.Errorf("Expected all 3 chunks of the changed source to be embedded, got %q", fake.documents)
+	}
+	if n := countChunks(t, database); n != 3 {
+		t.Errorf("Expected the partial chunks to be replaced, got %d chunks", n)
+	}
+}
+
+func TestIngestReplacesCompletedChangedSource(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "policy.txt")
+	if err := os.WriteFile(path, []byte("Processing shall be lawful."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ing := NewWithEmbedder(database, DefaultConfig(), &fakeEmbedder{})
+	if err := ing.IngestFile(path); err != nil {
+		t.Fatalf("IngestFile failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("Processing shall be fair."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ing.IngestFile(path); err != nil {
+		t.Fatalf("IngestFile failed: %v", err)
+	}
+	if n := countChunks(t, database); n != 1 {
+		t.Errorf("Expected the chunk of the edited file to replace the old one, got %d chunks", n)
+	}
+}
+
+func TestIngestRestartsOnChangedSettings(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "policy.txt")
+	if err := os.WriteFile(path, []byte("Processing shall be lawful."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ingest := func(config Config) *fakeEmbedder {
+		t.Helper()
+		fake := &fakeEmbedder{}
+		if err := NewWithEmbedder(database, config, fake).IngestFile(path); err != nil {
+			t.Fatalf("IngestFile failed: %v", err)
+		}
+		return fake
+	}
+
+	config := DefaultConfig()
+	config.Collection = "a"
+	ingest(config)
+
+	// The same file ingested into another collection moves there
+	config.Collection = "b"
+	if fake := ingest(config); len(fake.documents) != 1 {
+		t.Errorf("Expected the file to be ingested again into b, embedded %q", fake.documents)
+	}
+	collections, err := database.Collections()
+	if err != nil || len(collections) != 1 || collections[0].Name != "b" {
+		t.Errorf("Expected the chunk in collection b only, got %+v, %v", collections, err)
+	}
+
+	// A different embedding model embeds it again
+	config.Embedder = EmbedderOllama
+	config.OllamaModel = "all-minilm"
+	if fake := ingest(config); len(fake.documents) != 1 {
+		t.Errorf("Expected the file to be embedded with the new model, embedded %q", fake.documents)
+	}
+	if fake := ingest(config); len(fake.documents) != 0 {
+		t.Errorf("Expected unchanged settings to skip the file, embedded %q", fake.documents)
+	}
+	if n := countChunks(t, database); n != 1 {
+		t.Errorf("Expected 1 chunk, got %d", n)
+	}
+}
+
+func TestIngestSkipsCompletedSourceBeforeSemanticChunking(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "policy.txt")
+	text := "Retention periods are documented. The retention schedule is reviewed yearly. Every access request is logged. A request is answered within one month."
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	config.Strategy = StrategySemantic
+	ingest := func() *fakeEmbedder {
+		t.Helper()
+		fake := &fakeEmbedder{}
+		if err := NewWithEmbedder(database, config, fake).IngestFile(path); err != nil {
+			t.Fatalf("IngestFile failed: %v", err)
+		}
+		return fake
+	}
+
+	if fake := ingest(); len(fake.documents) == 0 {
+		t.Fatal("Expected the first run to embed the file")
+	}
+	if fake := ingest(); len(fake.documents) != 0 {
+		t.Errorf("Expected the completed file to be skipped before chunking, embedded %q", fake.documents)
+	}
+
+	// A different percentile may chunk the text differently
+	config.SemanticPercentile = 50
+	if fake := ingest(); len(fake.documents) == 0 {
+		t.Error("Expected changed chunking settings to ingest the file again")
+	}
+	if n := countChunks(t, database); n == 0 {
+		t.Error("Expected the file to keep its chunks")
+	}
+}
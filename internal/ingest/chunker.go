@@ -0,0 +1,373 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Chunker splits the text of a section into chunks. Chunks are substrings of
+// the text, in order and with surrounding whitespace trimmed, so the
+// provision each chunk starts in can be located. Chunking fails only when
+// it depends on an external service, such as an embeddings API.
+type Chunker interface {
+	Chunk(text string) ([]string, error)
+}
+
+// ChunkerFactory creates a Chunker from the ingestion configuration
+type ChunkerFactory func(config Config) (Chunker, error)
+
+// Chunking strategies selectable through Config.Strategy
+const (
+	// StrategyWindow splits text into overlapping windows, preferring to
+	// break after a sentence, then between words
+	StrategyWindow = "window"
+	// StrategySentence packs whole sentences into chunks
+	StrategySentence = "sentence"
+	// StrategyRecursive splits at paragraphs, then lines, sentences and
+	// words until the pieces fit, and packs them into chunks
+	StrategyRecursive = "recursive"
+	// StrategyStructure splits text at article and recital boundaries first,
+	// so no chunk ever spans two provisions
+	StrategyStructure = "structure"
+)
+
+var (
+	chunkersMu sync.RWMutex
+	chunkers   = map[string]ChunkerFactory{
+		StrategyWindow: newWindowChunker,
+		StrategySentence: func(config Config) (Chunker, error) {
+			return newSpanChunker(config, splitSentences, splitWords)
+		},
+		StrategyRecursive: func(config Config) (Chunker, error) {
+			return newSpanChunker(config, splitParagraphs, splitLines, splitSentences, splitWords)
+		},
+		StrategySemantic: newSemanticChunker,
+		StrategyStructure: func(config Config) (Chunker, error) {
+			inner, err := newWindowChunker(config)
+			if err != nil {
+				return nil, err
+			}
+			return structureChunker{inner}, nil
+		},
+	}
+)
+
+// RegisterChunker makes a chunking strategy available by name, replacing
+// any strategy registered under the same name
+func RegisterChunker(name string, factory ChunkerFactory) {
+	chunkersMu.Lock()
+	defer chunkersMu.Unlock()
+	chunkers[name] = factory
+}
+
+// NewChunker creates the chunker registered as name. An empty name selects
+// the window strategy.
+func NewChunker(name string, config Config) (Chunker, error) {
+	if name == "" {
+		name = StrategyWindow
+	}
+
+	chunkersMu.RLock()
+	factory, ok := chunkers[name]
+	chunkersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown chunking strategy %q (available: %v)", name, Chunkers())
+	}
+	return factory(config)
+}
+
+// Chunkers returns the names of the registered chunking strategies in
+// sorted order
+func Chunkers() []string {
+	chunkersMu.RLock()
+	defer chunkersMu.RUnlock()
+
+	names := make([]string, 0, len(chunkers))
+	for name := range chunkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newWindowChunker creates a window chunker measuring in tokens when a
+// tokenizer is configured and in characters otherwise
+func newWindowChunker(config Config) (Chunker, error) {
+	if config.Tokenizer != "" {
+		return newTokenChunker(config.Tokenizer, config.ChunkSize, config.ChunkOverlap)
+	}
+	if config.ChunkSize <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	return windowChunker{size: config.ChunkSize, overlap: config.ChunkOverlap}, nil
+}
+
+// windowChunker splits text into overlapping windows of characters
+type windowChunker struct {
+	size    int
+	overlap int
+}
+
+// Chunk splits text into overlapping chunks
+func (c windowChunker) Chunk(text string) ([]string, error) {
+	// Normalize whitespace
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var chunks []string
+	runes := []rune(text)
+	textLen := len(runes)
+
+	if textLen == 0 {
+		return chunks, nil
+	}
+
+	chunkSize := c.size
+	overlap := c.overlap
+
+	// offsets[i] is the byte offset of rune i, for sentence detection
+	offsets := make([]int, 0, textLen+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
+
+	for start := 0; start < textLen; {
+		end := start + chunkSize
+		if end > textLen {
+			end = textLen
+		}
+
+		// Try to break at sentence or word boundary
+		if end < textLen {
+			// Look for sentence boundary
+			for i := end; i > start+chunkSize/2; i-- {
+				if endsSentence(text, offsets[i+1]) {
+					end = i + 1
+					break
+				}
+			}
+			// Fall back to word boundary
+			if end == start+chunkSize && end < textLen {
+				for i := end; i > start+chunkSize/2; i-- {
+					if runes[i] == ' ' {
+						end = i
+						break
+					}
+				}
+			}
+		}
+
+		chunk := strings.TrimSpace(string(runes[start:end]))
+		if len(chunk) > 0 {
+			chunks = append(chunks, chunk)
+		}
+
+		// Move start position with overlap
+		start = end - overlap
+		if start < 0 {
+			start = 0
+		}
+		// Prevent infinite loop
+		if end >= textLen {
+			break
+		}
+	}
+
+	return chunks, nil
+}
+
+// structureChunker splits text at article and recital boundaries and
+// chunks each provision on its own
+type structureChunker struct {
+	inner Chunker
+}
+
+// Chunk splits text into chunks that never span two provisions
+func (c structureChunker) Chunk(text string) ([]string, error) {
+	var chunks []string
+	for _, section := range splitStructure(Section{Text: text}) {
+		pieces, err := c.inner.Chunk(section.Text)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, pieces...)
+	}
+	return chunks, nil
+}
+
+// span is a byte range of the text being chunked
+type span struct {
+	start, end int
+}
+
+// splitFunc divides a span of text at one kind of boundary
+type splitFunc func(text string, s span) []span
+
+var (
+	paragraphBreakRe = regexp.MustCompile(`\n[ \t]*\n`)
+	lineBreakRe      = regexp.MustCompile(`\n`)
+	wordBreakRe      = regexp.MustCompile(`\s+`)
+)
+
+func splitParagraphs(text string, s span) []span { return splitOn(text, s, paragraphBreakRe) }
+func splitLines(text string, s span) []span      { return splitOn(text, s, lineBreakRe) }
+func splitWords(text string, s span) []span      { return splitOn(text, s, wordBreakRe) }
+
+// splitOn splits a span at every match of re
+func splitOn(text string, s span, re *regexp.Regexp) []span {
+	var spans []span
+	start := s.start
+	for _, m := range re.FindAllStringIndex(text[s.start:s.end], -1) {
+		spans = appendTrimmed(spans, text, span{start, s.start + m[0]})
+		start = s.start + m[1]
+	}
+	return appendTrimmed(spans, text, span{start, s.end})
+}
+
+// splitSentences splits a span after every sentence
+func splitSentences(text string, s span) []span {
+	var spans []span
+	start := s.start
+	for i := s.start + 1; i < s.end; i++ {
+		if utf8.RuneStart(text[i]) && endsSentence(text, i) {
+			spans = appendTrimmed(spans, text, span{start, i})
+			start = i
+		}
+	}
+	return appendTrimmed(spans, text, span{start, s.end})
+}
+
+// appendTrimmed appends s without surrounding whitespace, unless empty
+func appendTrimmed(spans []span, text string, s span) []span {
+	for s.start < s.end {
+		r, size := utf8.DecodeRuneInString(text[s.start:s.end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		s.start += size
+	}
+	for s.end > s.start {
+		r, size := utf8.DecodeLastRuneInString(text[s.start:s.end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		s.end -= size
+	}
+	if s.start < s.end {
+		spans = append(spans, s)
+	}
+	return spans
+}
+
+// spanChunker splits text at the coarsest boundaries that make every piece
+// fit a chunk, then packs consecutive pieces into chunks of at most size,
+// overlapping by whole pieces of at most overlap
+type spanChunker struct {
+	splits  []splitFunc // boundaries to try, coarsest first
+	size    int
+	overlap int
+	measure func(text string) int
+}
+
+func newSpanChunker(config Config, splits ...splitFunc) (Chunker, error) {
+	if config.ChunkSize <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	c := &spanChunker{
+		splits:  splits,
+		size:    config.ChunkSize,
+		overlap: config.ChunkOverlap,
+		measure: utf8.RuneCountInString,
+	}
+	if config.Tokenizer != "" {
+		enc, err := getEncoding(config.Tokenizer)
+		if err != nil {
+			return nil, err
+		}
+		c.measure = func(text string) int { return len(enc.EncodeOrdinary(text)) }
+	}
+	return c, nil
+}
+
+// Chunk splits text into pieces and packs them into chunks
+func (c *spanChunker) Chunk(text string) ([]string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	root := appendTrimmed(nil, text, span{0, len(text)})
+	if len(root) == 0 {
+		return nil, nil
+	}
+	return c.pack(text, c.split(text, root[0], 0)), nil
+}
+
+// split divides s at the boundaries from level on until every piece fits
+func (c *spanChunker) split(text string, s span, level int) []span {
+	if c.measure(text[s.start:s.end]) <= c.size {
+		return []span{s}
+	}
+	if level == len(c.splits) {
+		return c.cut(text, s)
+	}
+	parts := c.splits[level](text, s)
+	if len(parts) <= 1 {
+		return c.split(text, s, level+1)
+	}
+	var pieces []span
+	for _, p := range parts {
+		pieces = append(pieces, c.split(text, p, level+1)...)
+	}
+	return pieces
+}
+
+// cut divides a span without any boundary, such as a very long word, into
+// the longest pieces that fit
+func (c *spanChunker) cut(text string, s span) []span {
+	var pieces []span
+	start := s.start
+	for start < s.end {
+		end := start
+		for end < s.end {
+			_, size := utf8.DecodeRuneInString(text[end:s.end])
+			if end > start && c.measure(text[start:end+size]) > c.size {
+				break
+			}
+			end += size
+		}
+		pieces = append(pieces, span{start, end})
+		start = end
+	}
+	return pieces
+}
+
+// pack joins consecutive pieces into chunks. Each chunk after the first
+// repeats the trailing pieces of the previous one that fit in overlap, and
+// always adds at least one new piece.
+func (c *spanChunker) pack(text string, pieces []span) []string {
+	fits := func(first, last int, limit int) bool {
+		return c.measure(text[pieces[first].start:pieces[last].end]) <= limit
+	}
+
+	var chunks []string
+	for first := 0; first < len(pieces); {
+		last := first
+		for last+1 < len(pieces) && fits(first, last+1, c.size) {
+			last++
+		}
+		chunks = append(chunks, text[pieces[first].start:pieces[last].end])
+		if last == len(pieces)-1 {
+			break
+		}
+
+		next := last + 1
+		for next > first+1 && c.overlap > 0 && fits(next-1, last, c.overlap) && fits(next-1, last+1, c.size) {
+			next--
+		}
+		first = next
+	}
+	return chunks
+}
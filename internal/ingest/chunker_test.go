@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const testPolicyText = `Data retention policy
+
+Personal data is kept no longer than necessary. Customer records are deleted six years after the end of the contract. Backups are overwritten within ninety days.
+
+Access requests are answered within one month. The data protection officer keeps a log of every request.`
+
+func TestSentenceChunker(t *testing.T) {
+	config := DefaultConfig()
+	config.Strategy = StrategySentence
+	config.ChunkSize = 120
+	config.ChunkOverlap = 60
+	chunks := mustChunk(t, newTestChunker(t, config), testPolicyText)
+
+	if len(chunks) < 3 {
+		t.Fatalf("Expected several chunks, got %q", chunks)
+	}
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > config.ChunkSize {
+			t.Errorf("Chunk %d has %d characters, more than %d", i, n, config.ChunkSize)
+		}
+		if !strings.Contains(testPolicyText, chunk) {
+			t.Errorf("Chunk %d is not a substring of the text: %q", i, chunk)
+		}
+		last := chunk[len(chunk)-1]
+		if i > 0 && last != '.' {
+			t.Errorf("Chunk %d does not end with a whole sentence: %q", i, chunk)
+		}
+	}
+	// Overlap repeats the last sentence of the previous chunk when it fits
+	if !strings.HasSuffix(chunks[0], "Personal data is kept no longer than necessary.") ||
+		!strings.HasPrefix(chunks[1], "Personal data is kept no longer than necessary.") {
+		t.Errorf("Expected whole-sentence overlap, got %q", chunks)
+	}
+}
+
+func TestRecursiveChunker(t *testing.T) {
+	config := DefaultConfig()
+	config.Strategy = StrategyRecursive
+	config.ChunkSize = 200
+	config.ChunkOverlap = 0
+	chunks := mustChunk(t, newTestChunker(t, config), testPolicyText)
+
+	// Paragraphs that fit are kept whole and packed together
+	want := []string{
+		"Data retention policy\n\nPersonal data is kept no longer than necessary. Customer records are deleted six years after the end of the contract. Backups are overwritten within ninety days.",
+		"Access requests are answered within one month. The data protection officer keeps a log of every request.",
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("Expected %d chunks, got %q", len(want), chunks)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Errorf("Chunk %d = %q, want %q", i, chunks[i], want[i])
+		}
+	}
+}
+
+func TestRecursiveChunkerCutsLongWords(t *testing.T) {
+	config := DefaultConfig()
+	config.Strategy = StrategyRecursive
+	config.ChunkSize = 10
+	config.ChunkOverlap = 0
+	chunks := mustChunk(t, newTestChunker(t, config), strings.Repeat("ä", 25))
+
+	if len(chunks) != 3 || chunks[0] != strings.Repeat("ä", 10) || chunks[2] != strings.Repeat("ä", 5) {
+		t.Errorf("Expected the word cut into 10, 10 and 5 characters, got %q", chunks)
+	}
+}
+
+func TestStructureChunker(t *testing.T) {
+	config := DefaultConfig()
+	config.Strategy = StrategyStructure
+	config.ChunkSize = 2000
+	chunks := mustChunk(t, newTestChunker(t, config), testRegulationText)
+
+	for _, chunk := range chunks {
+		if strings.Contains(chunk, "Article 4") && strings.Contains(chunk, "Article 17") {
+			t.Errorf("Chunk spans several articles: %q", chunk)
+		}
+	}
+}
+
+type lineChunker struct{}
+
+func (lineChunker) Chunk(text string) ([]string, error) {
+	return strings.Split(strings.TrimSpace(text), "\n"), nil
+}
+
+func TestRegisterChunker(t *testing.T) {
+	RegisterChunker("lines", func(Config) (Chunker, error) { return lineChunker{}, nil })
+
+	config := DefaultConfig()
+	config.Strategy = "lines"
+	if chunks := mustChunk(t, newTestChunker(t, config), "Article 1\nArticle 2"); len(chunks) != 2 {
+		t.Errorf("Expected the registered chunker to be used, got %q", chunks)
+	}
+
+	if _, err := NewChunker("paragraphs", config); err == nil || !strings.Contains(err.Error(), "available") {
+		t.Errorf("Expected an unknown strategy to list the available ones, got %v", err)
+	}
+}
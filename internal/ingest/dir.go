@@ -0,0 +1,168 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// dirExtensions are the file extensions picked up when ingesting a directory
+var dirExtensions = map[string]bool{
+	".txt":      true,
+	".md":       true,
+	".markdown": true,
+	".pdf":      true,
+	".xml":      true,
+	".fmx":      true,
+	".akn":      true,
+}
+
+// DirResult counts what an IngestDir run did with each file
+type DirResult struct {
+	Added     int // new files ingested
+	Updated   int // modified files re-ingested
+	Unchanged int // files skipped because they have not changed
+	Removed   int // deleted files whose chunks were removed
+	Rejected  int // binary files skipped, with the chunks of earlier versions removed
+}
+
+// IngestDir ingests the supported files under dir. Files are tracked by
+// content hash and modification time, so a repeated run only processes new
+// or modified files and removes the chunks of files deleted since. Binary
+// files are skipped with a StageRejected warning rather than failing the run.
+func (ing *Ingester) IngestDir(dir string) (DirResult, error) {
+	var result DirResult
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	seen := map[string]bool{}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !dirExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		seen[path] = true
+		return ing.ingestTrackedFile(path, &result)
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to ingest directory: %w", err)
+	}
+
+	files, err := ing.db.SourceFiles()
+	if err != nil {
+		return result, err
+	}
+	prefix := root + string(filepath.Separator)
+	for _, f := range files {
+		if seen[f.Source] || !strings.HasPrefix(f.Source, prefix) {
+			continue
+		}
+		removed, err := ing.db.RemoveSource(f.Source)
+		if err != nil {
+			return result, err
+		}
+		ing.report(StageRemoved, f.Source, removed, removed)
+		result.Removed++
+	}
+
+	return result, nil
+}
+
+// ingestTrackedFile ingests path unless its recorded size, modification time
+// or content hash show it is unchanged
+func (ing *Ingester) ingestTrackedFile(path string, result *DirResult) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	prev, err := ing.db.GetSourceFile(path)
+	if err != nil {
+		return err
+	}
+	if prev != nil && prev.Size == info.Size() && prev.ModTime.Equal(info.ModTime()) {
+		ing.report(StageSkipped, path, 0, 0)
+		result.Unchanged++
+		return nil
+	}
+
+	// A tracked file that can no longer be ingested loses its old chunks,
+	// which would otherwise be kept as if the file were unchanged
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if err := ing.dropTrackedFile(path, prev); err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".pdf" {
+		if _, err := ing.textContent(path, content); errors.Is(err, ErrNotText) {
+			if err := ing.dropTrackedFile(path, prev); err != nil {
+				return err
+			}
+			ing.report(StageRejected, path, 0, 0)
+			result.Rejected++
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	sum := sha256.Sum256(content)
+	record := db.SourceFile{
+		Source:  path,
+		Hash:    hex.EncodeToString(sum[:]),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+
+	switch {
+	case prev != nil && prev.Hash == record.Hash:
+		// Touched but not modified
+		ing.report(StageSkipped, path, 0, 0)
+		result.Unchanged++
+		return ing.db.SaveSourceFile(record)
+	case prev != nil:
+		// Drop the chunks of the old version. The file record goes too, so
+		// an interrupted re-ingestion resumes from its checkpoint next time
+		// instead of starting over.
+		if _, err := ing.db.RemoveSource(path); err != nil {
+			return err
+		}
+		result.Updated++
+	default:
+		result.Added++
+	}
+
+	if err := ing.ingestContent(path, ext, content); err != nil {
+		return fmt.Errorf("failed to ingest %s: %w", path, err)
+	}
+	return ing.db.SaveSourceFile(record)
+}
+
+// dropTrackedFile removes the chunks and file record of path if it was
+// ingested before
+func (ing *Ingester) dropTrackedFile(path string, prev *db.SourceFile) error {
+	if prev == nil {
+		return nil
+	}
+	removed, err := ing.db.RemoveSource(path)
+	if err != nil {
+		return err
+	}
+	ing.report(StageRemoved, path, removed, removed)
+	return nil
+}
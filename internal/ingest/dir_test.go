@@ -0,0 +1,131 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIngestDirIncremental(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("art6.txt", "Processing shall be lawful only if the data subject has given consent.")
+	write("nested/art17.md", "# Article 17\n\nThe data subject shall have the right to erasure.")
+	write("notes.json", `{"ignored": true}`)
+	art5 := write("art5.txt", "Personal data shall be processed lawfully, fairly and transparently.")
+
+	fake := &fakeEmbedder{}
+	ing := NewWithEmbedder(database, DefaultConfig(), fake)
+
+	result, err := ing.IngestDir(dir)
+	if err != nil {
+		t.Fatalf("IngestDir failed: %v", err)
+	}
+	if result != (DirResult{Added: 3}) {
+		t.Errorf("First run = %+v, want 3 added", result)
+	}
+	if n := countChunks(t, database); n != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", n)
+	}
+
+	// Nothing changed: no file is read or embedded again
+	embedded := len(fake.documents)
+	result, err = ing.IngestDir(dir)
+	if err != nil {
+		t.Fatalf("IngestDir failed: %v", err)
+	}
+	if result != (DirResult{Unchanged: 3}) || len(fake.documents) != embedded {
+		t.Errorf("Second run = %+v with %d new embeddings, want 3 unchanged", result, len(fake.documents)-embedded)
+	}
+
+	// Touched without modification
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(art5, later, later); err != nil {
+		t.Fatal(err)
+	}
+	// Modified, deleted and added files
+	write("art6.txt", "Processing shall be lawful only if and to the extent that a legal basis applies.")
+	if err := os.Remove(filepath.Join(dir, "nested", "art17.md")); err != nil {
+		t.Fatal(err)
+	}
+	write("art7.txt", "The controller shall be able to demonstrate that the data subject has consented.")
+
+	var removed []Progress
+	config := DefaultConfig()
+	config.Progress = ProgressFunc(func(p Progress) {
+		if p.Stage == StageRemoved {
+			removed = append(removed, p)
+		}
+	})
+	result, err = NewWithEmbedder(database, config, fake).IngestDir(dir)
+	if err != nil {
+		t.Fatalf("IngestDir failed: %v", err)
+	}
+	if want := (DirResult{Added: 1, Updated: 1, Unchanged: 1, Removed: 1}); result != want {
+		t.Errorf("Third run = %+v, want %+v", result, want)
+	}
+	if len(removed) != 1 || removed[0].Done != 1 || filepath.Base(removed[0].Source) != "art17.md" {
+		t.Errorf("Expected the deleted file to be reported, got %+v", removed)
+	}
+	if n := countChunks(t, database); n != 3 {
+		t.Errorf("Expected 3 chunks after the update, got %d", n)
+	}
+
+	files, err := database.SourceFiles()
+	if err != nil {
+		t.Fatalf("SourceFiles failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Errorf("Expected 3 tracked files, got %+v", files)
+	}
+}
+
+func TestIngestDirDropsFileTurnedBinary(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "art6.txt")
+	if err := os.WriteFile(path, []byte("Processing shall be lawful only if the data subject has given consent."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ing := NewWithEmbedder(database, DefaultConfig(), &fakeEmbedder{})
+	if _, err := ing.IngestDir(dir); err != nil {
+		t.Fatalf("IngestDir failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte{0x7f, 'E', 'L', 'F', 0, 0, 0, 1, 2, 3}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ing.IngestDir(dir)
+	if err != nil {
+		t.Fatalf("IngestDir failed: %v", err)
+	}
+	if result != (DirResult{Rejected: 1}) {
+		t.Errorf("Run = %+v, want 1 rejected", result)
+	}
+	if n := countChunks(t, database); n != 0 {
+		t.Errorf("Expected the chunks of the old version to be removed, got %d", n)
+	}
+	if prev, err := database.GetSourceFile(path); err != nil || prev != nil {
+		t.Errorf("Expected the file record to be removed, got %+v (%v)", prev, err)
+	}
+}
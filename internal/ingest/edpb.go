@@ -0,0 +1,195 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ingestion profiles selectable through Config.Profile. A profile knows the
+// structure of one kind of document and tags its chunks accordingly.
+const (
+	// ProfileRegulation (the default) tags chunks with the chapter, article,
+	// paragraph or recital of the regulation they start in
+	ProfileRegulation = "regulation"
+	// ProfileEDPB tags chunks with the EDPB or WP29 guideline they belong
+	// to and the numbered section and paragraph they start in
+	ProfileEDPB = "edpb"
+	// ProfileEnforcement reads a dataset of enforcement decisions and tags
+	// each decision's chunks with its authority, country, fine and the
+	// articles found violated
+	ProfileEnforcement = "enforcement"
+	// ProfileCaseLaw tags the chunks of a CJEU judgment with its case
+	// number, name, ECLI and date and the numbered paragraph they start in
+	ProfileCaseLaw = "caselaw"
+	// ProfileSCC tags the chunks of the 2021 standard contractual clauses
+	// with the clause or annex and the modules they belong to
+	ProfileSCC = "scc"
+)
+
+// EDPBCollection is the collection EDPB documents go into unless the
+// configuration names another
+const EDPBCollection = "edpb"
+
+// Metadata keys describing the EDPB document a chunk comes from
+const (
+	MetaGuideline             = "guideline"               // e.g. "Guidelines 05/2020" or "WP248 rev.01"
+	MetaGuidelineSection      = "guideline_section"       // e.g. "3.1.1"
+	MetaGuidelineSectionTitle = "guideline_section_title" // e.g. "Imbalance of power"
+	MetaGuidelineParagraph    = "guideline_paragraph"     // e.g. "13"
+)
+
+var (
+	// "Guidelines 05/2020", "Recommendations 01/2020" or "Statement 03/2021"
+	// of the EDPB, or "WP 248 rev.01" of the Article 29 Working Party
+	guidelineIDRe = regexp.MustCompile(`\b(?:(Guidelines|Recommendations|Statement|Opinion)\s+(\d{1,2}/\d{4})|WP\s?(\d{3})(?:\s?(rev\.?\s?\d+))?)\b`)
+	// "3.1.1 Imbalance of power", "2. SCOPE" or "## 4 Conclusion"
+	edpbHeadingRe = regexp.MustCompile(`^#*\s*(\d{1,2}(?:\.\d{1,2})*)\.?\s+(\S.*)$`)
+	// "13. Recital 43 clearly indicates", the start of a numbered paragraph
+	edpbParagraphRe = regexp.MustCompile(`^(\d{1,3})\.\s+(\S.*)$`)
+	// Table of contents entries end in dot leaders or a tabbed-out page number
+	tocEntryRe = regexp.MustCompile(`(?:\.{3,}|…)\s*\d*$|(?:\t|\s{2,})\d+$`)
+)
+
+// maxEDPBHeadingLen bounds the length of a numbered line taken as a section
+// heading rather than a numbered paragraph
+const maxEDPBHeadingLen = 100
+
+// validProfile reports whether profile names a known ingestion profile
+func validProfile(profile string) error {
+	switch profile {
+	case "", ProfileRegulation, ProfileEDPB, ProfileEnforcement, ProfileCaseLaw, ProfileSCC:
+		return nil
+	default:
+		return fmt.Errorf("unknown ingestion profile %q", profile)
+	}
+}
+
+// splitEDPB splits an EDPB guideline, recommendation or statement at its
+// numbered section headings. Each resulting section is tagged with the
+// document ID found in the text (its first "Guidelines NN/YYYY" mention,
+// normally on the title page) and the number and title of its section, on
+// top of the metadata of the original section.
+func splitEDPB(section Section) []Section {
+	text := strings.ReplaceAll(section.Text, "\r\n", "\n")
+	guideline := GuidelineID(text)
+
+	var sections []Section
+	var current []string
+	var number, title string
+
+	flush := func() {
+		body := strings.TrimSpace(strings.Join(current, "\n"))
+		current = nil
+		if body == "" {
+			return
+		}
+		m := make(map[string]string, len(section.Metadata)+3)
+		for k, v := range section.Metadata {
+			m[k] = v
+		}
+		if guideline != "" {
+			m[MetaGuideline] = guideline
+		}
+		if number != "" {
+			m[MetaGuidelineSection] = number
+			m[MetaGuidelineSectionTitle] = title
+		}
+		sections = append(sections, Section{Text: body, Metadata: m})
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if m := edpbHeadingRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil && isEDPBHeading(m[2]) {
+			flush()
+			number, title = m[1], strings.TrimSpace(m[2])
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return sections
+}
+
+// GuidelineID returns the ID of the first EDPB or WP29 document mentioned
+// in text, normally its own on the title page: "Guidelines 05/2020" or
+// "WP248 rev.01". It returns "" if there is none.
+func GuidelineID(text string) string {
+	m := guidelineIDRe.FindStringSubmatch(text)
+	switch {
+	case m == nil:
+		return ""
+	case m[1] != "":
+		return m[1] + " " + m[2]
+	case m[4] != "":
+		return "WP" + m[3] + " rev." + strings.TrimLeft(m[4][len("rev"):], ". ")
+	default:
+		return "WP" + m[3]
+	}
+}
+
+// chunkParagraphs returns, for each chunk of a section, the number of the
+// paragraph in effect where the chunk starts, as recognized by paragraph
+// in the lines of the text, or, for a chunk starting before the first
+// paragraph, such as at a heading, the first paragraph in the chunk.
+// Chunks must be in order and be substrings of the text, as produced by the
+// chunkers.
+func chunkParagraphs(text string, chunks []string, paragraph func(line string) string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.SplitAfter(text, "\n")
+
+	paragraphs := make([]string, len(chunks))
+	current := ""
+	line, lineStart := 0, 0
+	from := 0
+	for i, chunk := range chunks {
+		offset := from
+		if idx := strings.Index(text[from:], chunk); idx >= 0 {
+			offset = from + idx
+			from = offset + 1
+		}
+		// Apply every line starting at or before the chunk
+		for line < len(lines) && lineStart <= offset {
+			if number := paragraph(strings.TrimSpace(lines[line])); number != "" {
+				current = number
+			}
+			lineStart += len(lines[line])
+			line++
+		}
+		paragraphs[i] = current
+		// Look ahead at the whole lines starting within the chunk, as its
+		// end may cut a paragraph's first line short
+		for next, start := line, lineStart; paragraphs[i] == "" && next < len(lines) && start < offset+len(chunk); next++ {
+			paragraphs[i] = paragraph(strings.TrimSpace(lines[next]))
+			start += len(lines[next])
+		}
+	}
+	return paragraphs
+}
+
+// guidelineParagraph returns the number of a line starting a numbered
+// paragraph of a guideline, or "" for other lines such as headings and
+// table of contents entries
+func guidelineParagraph(line string) string {
+	m := edpbParagraphRe.FindStringSubmatch(line)
+	if m == nil || isEDPBHeading(m[2]) || tocEntryRe.MatchString(m[2]) {
+		return ""
+	}
+	return m[1]
+}
+
+// isEDPBHeading reports whether the text after a section number is a
+// heading title rather than the start of a numbered paragraph or a table of
+// contents entry
+func isEDPBHeading(title string) bool {
+	title = strings.TrimSpace(title)
+	if title == "" || len(title) > maxEDPBHeadingLen || tocEntryRe.MatchString(title) {
+		return false
+	}
+	if strings.ContainsAny(title[len(title)-1:], ".:;,") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(title)
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,149 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+const edpbFixture = `Guidelines 05/2020 on consent under Regulation 2016/679
+Version 1.1
+Adopted on 4 May 2020
+
+Table of contents
+1 Introduction .......... 4
+2 Consent in Article 4(11) of the GDPR .......... 5
+
+1 INTRODUCTION
+1. These Guidelines provide a thorough analysis of the notion of consent in Regulation 2016/679.
+2. Article 7 GDPR sets out the conditions for valid consent.
+
+3.1.1 Imbalance of power
+13. Recital 43 clearly indicates that it is unlikely that public authorities can rely on consent.
+(1) Controllers should assess whether consent is freely given.
+
+3.2 Interplay with Article 6
+14. Consent is one of the six lawful bases.`
+
+func TestSplitEDPB(t *testing.T) {
+	sections := splitEDPB(Section{Text: edpbFixture, Metadata: map[string]string{"page": "1"}})
+
+	want := []struct {
+		number, title, starts string
+	}{
+		{"", "", "Guidelines 05/2020"},
+		{"1", "INTRODUCTION", "1 INTRODUCTION"},
+		{"3.1.1", "Imbalance of power", "3.1.1 Imbalance"},
+		{"3.2", "Interplay with Article 6", "3.2 Interplay"},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("Expected %d sections, got %d: %+v", len(want), len(sections), sections)
+	}
+	for i, w := range want {
+		s := sections[i]
+		if s.Metadata[MetaGuideline] != "Guidelines 05/2020" {
+			t.Errorf("Section %d: guideline = %q", i, s.Metadata[MetaGuideline])
+		}
+		if s.Metadata[MetaGuidelineSection] != w.number || s.Metadata[MetaGuidelineSectionTitle] != w.title {
+			t.Errorf("Section %d: section = %q %q, want %q %q", i,
+				s.Metadata[MetaGuidelineSection], s.Metadata[MetaGuidelineSectionTitle], w.number, w.title)
+		}
+		if !strings.HasPrefix(s.Text, w.starts) {
+			t.Errorf("Section %d starts with %q, want %q", i, s.Text, w.starts)
+		}
+		if s.Metadata["page"] != "1" {
+			t.Errorf("Section %d lost the section metadata: %v", i, s.Metadata)
+		}
+	}
+}
+
+func TestIsEDPBHeading(t *testing.T) {
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"INTRODUCTION", true},
+		{"Elements of valid consent", true},
+		{"Interplay with Article 6", true},
+		{"Introduction .......... 4", false},
+		{"Introduction\t4", false},
+		{"These Guidelines provide an analysis.", false},
+		{"the controller must:", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isEDPBHeading(tt.title); got != tt.want {
+			t.Errorf("isEDPBHeading(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestGuidelineID(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"Guidelines 05/2020 on consent under Regulation 2016/679", "Guidelines 05/2020"},
+		{"Recommendations 01/2020 on supplementary measures", "Recommendations 01/2020"},
+		{"ARTICLE 29 DATA PROTECTION WORKING PARTY\n17/EN\nWP 248 rev.01", "WP248 rev.01"},
+		{"Adopted on 13 December 2016 - WP243 rev 01", "WP243 rev.01"},
+		{"Opinion of the WP29 (WP260)", "WP260"},
+		{"The Article 29 Working Party (WP29) was replaced by the EDPB", ""},
+	}
+	for _, tt := range tests {
+		if got := GuidelineID(tt.text); got != tt.want {
+			t.Errorf("GuidelineID(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGuidelineParagraphs(t *testing.T) {
+	text := "3.1.1 Imbalance of power\n13. Recital 43 clearly indicates that it is unlikely.\nThe controller must assess it.\n14. Consent is one of the six lawful bases.\n15. Withdrawal must be possible."
+	chunks := []string{
+		"3.1.1 Imbalance of power\n13. Recital 43 clearly indicates",
+		"The controller must assess it.",
+		"lawful bases.\n15. Withdrawal must be possible.",
+	}
+	if got, want := chunkParagraphs(text, chunks, guidelineParagraph), []string{"13", "13", "14"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("chunkParagraphs = %v, want %v", got, want)
+	}
+	// Table of contents entries and headings are not paragraphs
+	for _, line := range []string{"1. Introduction .......... 4", "2. SCOPE", "3.1 Scope"} {
+		if got := guidelineParagraph(line); got != "" {
+			t.Errorf("guidelineParagraph(%q) = %q, want none", line, got)
+		}
+	}
+}
+
+func TestIngestEDPBProfile(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Profile = ProfileEDPB
+	if err := NewWithEmbedder(database, config, &fakeEmbedder{}).IngestText(edpbFixture); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("public authorities", 1, db.SearchOptions{Collection: EDPBCollection})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("SearchTrigrams = %+v, %v", results, err)
+	}
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil || doc == nil {
+		t.Fatalf("GetDocument = %v, %v", doc, err)
+	}
+	if doc.Metadata[MetaGuideline] != "Guidelines 05/2020" || doc.Metadata[MetaGuidelineSection] != "3.1.1" || doc.Metadata[MetaGuidelineParagraph] != "13" {
+		t.Errorf("Unexpected metadata %v", doc.Metadata)
+	}
+	// Numbered guideline paragraphs are not regulation provisions
+	if _, ok := doc.Metadata[MetaRecital]; ok {
+		t.Errorf("Expected no recital metadata, got %v", doc.Metadata)
+	}
+
+	config.Profile = "policy"
+	if err := NewWithEmbedder(database, config, &fakeEmbedder{}).IngestText(edpbFixture); err == nil {
+		t.Error("Expected an unknown profile to be rejected")
+	}
+}
@@ -0,0 +1,189 @@
+package ingest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Embedder turns text into embedding vectors. Documents and queries are
+// embedded separately because some models treat them differently.
+type Embedder interface {
+	EmbedDocuments(texts []string) ([][]float32, error)
+	EmbedQuery(text string) ([]float32, error)
+}
+
+// EmbedderFactory creates an Embedder from the ingestion configuration
+type EmbedderFactory func(config Config) (Embedder, error)
+
+// Names of the built-in embedders
+const (
+	EmbedderStub     = "stub"
+	EmbedderOpenAI   = "openai"
+	EmbedderOllama   = "ollama"
+	EmbedderONNX     = "onnx"
+	EmbedderEndpoint = "endpoint"
+)
+
+var (
+	embeddersMu sync.RWMutex
+	embedders   = map[string]EmbedderFactory{
+		EmbedderStub: func(Config) (Embedder, error) {
+			return perTextEmbedder(func(text string) ([]float32, error) {
+				return stubEmbedding(text), nil
+			}), nil
+		},
+		EmbedderOpenAI: func(config Config) (Embedder, error) {
+			if config.OpenAIKey == "" {
+				return nil, fmt.Errorf("OpenAI embeddings need an API key (set OPENAI_API_KEY)")
+			}
+			return newBatchEmbedder(config.BatchSize, withRetry(config.MaxRetries, func(texts []string) ([][]float32, error) {
+				return openAIEmbeddings(texts, config.OpenAIKey, config.OpenAIModel)
+			})), nil
+		},
+		EmbedderOllama: func(config Config) (Embedder, error) {
+			return newBatchEmbedder(config.BatchSize, withRetry(config.MaxRetries, func(texts []string) ([][]float32, error) {
+				return ollamaEmbeddings(texts, config.OllamaURL, config.OllamaModel)
+			})), nil
+		},
+		EmbedderONNX: func(config Config) (Embedder, error) {
+			if !ONNXAvailable {
+				return nil, fmt.Errorf("ONNX embeddings are not available: rebuild with -tags onnx")
+			}
+			if err := checkONNXModelDir(config.ONNXModelDir); err != nil {
+				return nil, err
+			}
+			return perTextEmbedder(func(text string) ([]float32, error) {
+				return onnxEmbedding(text, config.ONNXModelDir, config.ONNXRuntime)
+			}), nil
+		},
+		EmbedderEndpoint: func(config Config) (Embedder, error) {
+			if config.Endpoint.URL == "" {
+				return nil, fmt.Errorf("no embeddings endpoint URL configured")
+			}
+			return newBatchEmbedder(config.BatchSize, withRetry(config.MaxRetries, func(texts []string) ([][]float32, error) {
+				return endpointEmbeddings(texts, config.Endpoint)
+			})), nil
+		},
+	}
+)
+
+// RegisterEmbedder makes an embedder available by name, replacing any
+// embedder registered under the same name
+func RegisterEmbedder(name string, factory EmbedderFactory) {
+	embeddersMu.Lock()
+	defer embeddersMu.Unlock()
+	embedders[name] = factory
+}
+
+// NewEmbedder creates the embedder registered as name. An empty name
+// selects the stub embedder.
+func NewEmbedder(name string, config Config) (Embedder, error) {
+	if name == "" {
+		name = EmbedderStub
+	}
+
+	embeddersMu.RLock()
+	factory, ok := embedders[name]
+	embeddersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown embedder %q (available: %v)", name, Embedders())
+	}
+	return factory(config)
+}
+
+// EmbedderName returns the name of the configured embedder, EmbedderStub
+// when unset
+func (c Config) EmbedderName() string {
+	if c.Embedder == "" {
+		return EmbedderStub
+	}
+	return c.Embedder
+}
+
+// EmbeddingModel returns the model the configured embedder embeds with, or
+// "" for embedders without one, such as the stub
+func (c Config) EmbeddingModel() string {
+	switch c.EmbedderName() {
+	case EmbedderOpenAI:
+		return c.OpenAIModel
+	case EmbedderOllama:
+		return c.OllamaModel
+	case EmbedderONNX:
+		return c.ONNXModelDir
+	case EmbedderEndpoint:
+		return c.Endpoint.Model
+	}
+	return ""
+}
+
+// Embedders returns the names of the registered embedders in sorted order
+func Embedders() []string {
+	embeddersMu.RLock()
+	defer embeddersMu.RUnlock()
+
+	names := make([]string, 0, len(embedders))
+	for name := range embedders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// perTextEmbedder adapts a function embedding one text at a time, used
+// alike for documents and queries
+type perTextEmbedder func(text string) ([]float32, error)
+
+func (f perTextEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embedding, err := f(text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings[i] = embedding
+	}
+	return embeddings, nil
+}
+
+func (f perTextEmbedder) EmbedQuery(text string) ([]float32, error) {
+	return f(text)
+}
+
+// batchEmbedder adapts a function embedding several texts per request,
+// splitting documents into batches of at most size texts
+type batchEmbedder struct {
+	embed func(texts []string) ([][]float32, error)
+	size  int
+}
+
+func newBatchEmbedder(size int, embed func(texts []string) ([][]float32, error)) batchEmbedder {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	return batchEmbedder{embed: embed, size: size}
+}
+
+func (b batchEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += b.size {
+		end := start + b.size
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batch, err := b.embed(texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+func (b batchEmbedder) EmbedQuery(text string) ([]float32, error) {
+	embeddings, err := b.embed([]string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
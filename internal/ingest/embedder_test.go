@@ -0,0 +1,187 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// fakeEmbedder returns fixed embeddings and records what it was asked
+type fakeEmbedder struct {
+	documents []string
+	queries   []string
+}
+
+func (f *fakeEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	f.documents = append(f.documents, texts...)
+	embeddings := make([][]float32, len(texts))
+	for i := range texts {
+		embeddings[i] = []float32{1, 0}
+	}
+	return embeddings, nil
+}
+
+func (f *fakeEmbedder) EmbedQuery(text string) ([]float32, error) {
+	f.queries = append(f.queries, text)
+	return []float32{0, 1}, nil
+}
+
+func TestEmbedderRegistry(t *testing.T) {
+	fake := &fakeEmbedder{}
+	RegisterEmbedder("fake", func(Config) (Embedder, error) { return fake, nil })
+
+	embedder, err := NewEmbedder("fake", DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewEmbedder failed: %v", err)
+	}
+	if embedder != fake {
+		t.Error("Expected the registered embedder")
+	}
+
+	found := false
+	for _, name := range Embedders() {
+		found = found || name == "fake"
+	}
+	if !found {
+		t.Errorf("Registered embedder missing from %v", Embedders())
+	}
+
+	if _, err := NewEmbedder("no-such-embedder", DefaultConfig()); err == nil {
+		t.Error("Expected error for unknown embedder")
+	}
+
+	if e, err := NewEmbedder("", DefaultConfig()); err != nil || e == nil {
+		t.Errorf("Expected the stub embedder for an empty name, got %v, %v", e, err)
+	}
+}
+
+func TestEmbedderConfigErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.OpenAIKey = ""
+	if _, err := NewEmbedder(EmbedderOpenAI, config); err == nil {
+		t.Error("Expected error for OpenAI without an API key")
+	}
+	if _, err := NewEmbedder(EmbedderEndpoint, config); err == nil {
+		t.Error("Expected error for an endpoint without URL")
+	}
+	config.ONNXModelDir = t.TempDir()
+	if _, err := NewEmbedder(EmbedderONNX, config); err == nil {
+		t.Error("Expected error for ONNX without model files")
+	}
+}
+
+func TestConfigEmbeddingModel(t *testing.T) {
+	config := DefaultConfig()
+	config.Embedder = ""
+	if config.EmbedderName() != EmbedderStub || config.EmbeddingModel() != "" {
+		t.Errorf("Expected the stub without a model, got %q and %q", config.EmbedderName(), config.EmbeddingModel())
+	}
+	config.Embedder = EmbedderOllama
+	config.OllamaModel = "nomic-embed-text"
+	if config.EmbeddingModel() != "nomic-embed-text" {
+		t.Errorf("Expected the Ollama model, got %q", config.EmbeddingModel())
+	}
+	config.Embedder = EmbedderEndpoint
+	config.Endpoint.Model = "bge-m3"
+	if config.EmbeddingModel() != "bge-m3" {
+		t.Errorf("Expected the endpoint model, got %q", config.EmbeddingModel())
+	}
+}
+
+func TestIngestWithEmbedder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	fake := &fakeEmbedder{}
+	ingester := NewWithEmbedder(database, DefaultConfig(), fake)
+	if err := ingester.IngestText("Consent must be freely given."); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	if len(fake.documents) != 1 {
+		t.Fatalf("Expected 1 embedded document, got %d", len(fake.documents))
+	}
+	results, err := database.SearchVectors([]float32{1, 0}, 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchVectors failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Score < 0.999 {
+		t.Errorf("Expected the fake embedding to be stored, got %+v", results)
+	}
+}
+
+func TestIngestUnknownEmbedder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Embedder = "no-such-embedder"
+	if err := New(database, config).IngestText("text"); err == nil {
+		t.Fatal("Expected error for unknown embedder")
+	}
+}
+
+func TestBatchEmbedder(t *testing.T) {
+	var batches [][]string
+	embedder := newBatchEmbedder(2, func(texts []string) ([][]float32, error) {
+		batches = append(batches, texts)
+		embeddings := make([][]float32, len(texts))
+		for i, text := range texts {
+			embeddings[i] = []float32{float32(len(text))}
+		}
+		return embeddings, nil
+	})
+
+	embeddings, err := embedder.EmbedDocuments([]string{"a", "bb", "ccc", "dddd", "eeeee"})
+	if err != nil {
+		t.Fatalf("EmbedDocuments failed: %v", err)
+	}
+	if len(batches) != 3 || len(batches[2]) != 1 {
+		t.Errorf("Expected batches of 2, 2 and 1, got %v", batches)
+	}
+	for i, e := range embeddings {
+		if e[0] != float32(i+1) {
+			t.Errorf("Embedding %d out of order: %v", i, e)
+		}
+	}
+
+	query, err := embedder.EmbedQuery("query")
+	if err != nil || query[0] != 5 {
+		t.Errorf("EmbedQuery = %v, %v", query, err)
+	}
+}
+
+func TestIngestBatchesEmbeddings(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	requests := 0
+	RegisterEmbedder("counting", func(config Config) (Embedder, error) {
+		return newBatchEmbedder(config.BatchSize, func(texts []string) ([][]float32, error) {
+			requests++
+			return make([][]float32, len(texts)), nil
+		}), nil
+	})
+
+	config := DefaultConfig()
+	config.Embedder = "counting"
+	config.ChunkSize = 100
+	config.ChunkOverlap = 0
+	config.BatchSize = 4
+
+	text := ""
+	for i := 0; i < 10; i++ {
+		text += "The controller shall document every personal data breach without undue delay. "
+	}
+	if err := New(database, config).IngestText(text); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	count, _ := database.GetMetadata("chunk_count")
+	if count != "10" {
+		t.Fatalf("Expected 10 chunks, got %s", count)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 10 chunks to take 3 requests with batch size 4, got %d", requests)
+	}
+}
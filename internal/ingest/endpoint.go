@@ -0,0 +1,77 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Embedding APIs an endpoint can speak
+const (
+	// EndpointOpenAI is the OpenAI embeddings API, also served by vLLM,
+	// LocalAI, LiteLLM and Text Embeddings Inference under /v1
+	EndpointOpenAI = "openai"
+	// EndpointTEI is the native Hugging Face Text Embeddings Inference API
+	EndpointTEI = "tei"
+)
+
+// EndpointConfig describes a self-hosted embeddings server
+type EndpointConfig struct {
+	URL        string // base URL, e.g. http://localhost:8080/v1 for EndpointOpenAI
+	Model      string // model name sent with OpenAI-style requests
+	API        string // EndpointOpenAI (default) or EndpointTEI
+	AuthHeader string // "Name: value", or a bare token sent as "Authorization: Bearer <token>"
+}
+
+// authHeader splits the configured auth header into name and value
+func (c EndpointConfig) authHeader() (string, string) {
+	auth := strings.TrimSpace(c.AuthHeader)
+	if auth == "" {
+		return "", ""
+	}
+	if name, value, ok := strings.Cut(auth, ":"); ok && !strings.ContainsAny(name, " \t") {
+		return strings.TrimSpace(name), strings.TrimSpace(value)
+	}
+	return "Authorization", "Bearer " + auth
+}
+
+// endpointEmbeddings embeds texts with the configured embeddings server in
+// one request
+func endpointEmbeddings(texts []string, config EndpointConfig) ([][]float32, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("no embeddings endpoint URL configured")
+	}
+	base := strings.TrimRight(config.URL, "/")
+	header, value := config.authHeader()
+
+	switch config.API {
+	case "", EndpointOpenAI:
+		return openAICompatibleEmbeddings(texts, base+"/embeddings", config.Model, header, value)
+	case EndpointTEI:
+		return teiEmbeddings(texts, base+"/embed", header, value)
+	default:
+		return nil, fmt.Errorf("unknown embeddings API %q", config.API)
+	}
+}
+
+// teiEmbeddings calls the embed route of a Text Embeddings Inference server
+func teiEmbeddings(texts []string, url, authHeader, authValue string) ([][]float32, error) {
+	jsonBody, err := json.Marshal(map[string]interface{}{"inputs": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	body, err := postEmbeddingRequest(url, jsonBody, authHeader, authValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]float32
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(result) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings in response, got %d", len(texts), len(result))
+	}
+	return result, nil
+}
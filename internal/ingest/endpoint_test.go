@@ -0,0 +1,84 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEndpointEmbedding(t *testing.T) {
+	var gotAuth, gotKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.Header.Get("X-Api-Key")
+
+		var req struct {
+			Model  string   `json:"model"`
+			Input  []string `json:"input"`
+			Inputs []string `json:"inputs"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		switch r.URL.Path {
+		case "/v1/embeddings":
+			if req.Model != "bge-m3" || len(req.Input) != 2 {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			// Answer out of order; the index field restores it
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"index": 1, "embedding": []float64{0.75, 0}},
+					{"index": 0, "embedding": []float64{0.5, 0.25}},
+				},
+			})
+		case "/embed":
+			if len(req.Inputs) != 1 {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode([][]float32{{1, 2, 3}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	embeddings, err := endpointEmbeddings([]string{"consent", "erasure"}, EndpointConfig{
+		URL:        srv.URL + "/v1/",
+		Model:      "bge-m3",
+		AuthHeader: "secret-token",
+	})
+	if err != nil {
+		t.Fatalf("OpenAI-compatible endpoint failed: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 0.5 || embeddings[1][0] != 0.75 {
+		t.Errorf("Unexpected embeddings: %v", embeddings)
+	}
+	if gotAuth != "Bearer secret-token" {
+		t.Errorf("Expected bare token to be sent as bearer auth, got %q", gotAuth)
+	}
+
+	embeddings, err = endpointEmbeddings([]string{"consent"}, EndpointConfig{
+		URL:        srv.URL,
+		API:        EndpointTEI,
+		AuthHeader: "X-API-Key: abc",
+	})
+	if err != nil {
+		t.Fatalf("TEI endpoint failed: %v", err)
+	}
+	if len(embeddings) != 1 || embeddings[0][2] != 3 {
+		t.Errorf("Unexpected embeddings: %v", embeddings)
+	}
+	if gotKey != "abc" || gotAuth != "" {
+		t.Errorf("Expected custom auth header, got X-API-Key=%q Authorization=%q", gotKey, gotAuth)
+	}
+
+	if _, err := endpointEmbeddings([]string{"consent"}, EndpointConfig{URL: srv.URL, API: "grpc"}); err == nil {
+		t.Error("Expected error for unknown API")
+	}
+	if _, err := endpointEmbeddings([]string{"consent"}, EndpointConfig{}); err == nil {
+		t.Error("Expected error without URL")
+	}
+}
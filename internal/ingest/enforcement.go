@@ -0,0 +1,165 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// EnforcementCollection is the collection enforcement decisions go into
+// unless the configuration names another
+const EnforcementCollection = "enforcement"
+
+// Metadata keys describing the enforcement decision a chunk comes from
+const (
+	MetaDecision         = "decision"          // reference of the decision, e.g. "DPC IN-18-5-5"
+	MetaAuthority        = "authority"         // e.g. "Data Protection Commission"
+	MetaCountry          = "country"           // ISO 3166-1 alpha-2 code of the authority, e.g. "IE"
+	MetaDecisionDate     = "decision_date"     // YYYY-MM-DD
+	MetaController       = "controller"        // the fined controller or processor
+	MetaFine             = "fine"              // amount of the fine, e.g. "1200000000"
+	MetaCurrency         = "currency"          // ISO 4217 code, e.g. "EUR"
+	MetaViolatedArticles = "violated_articles" // article numbers found violated, comma-separated, e.g. "5,6,13"
+	MetaDecisionURL      = "decision_url"
+)
+
+// articlePrefixRe matches "Art." or "Article" before an article number
+var articlePrefixRe = regexp.MustCompile(`(?i)^art(?:icle|\.)?\s*`)
+
+// Decision is one record of an enforcement dataset, read as JSON Lines or
+// a JSON array
+type Decision struct {
+	ID         string   `json:"id"`
+	Authority  string   `json:"authority"`
+	Country    string   `json:"country"`
+	Date       string   `json:"date"`
+	Controller string   `json:"controller"`
+	Fine       float64  `json:"fine"`
+	Currency   string   `json:"currency"`
+	Articles   []string `json:"articles"` // provisions found violated, e.g. "5(1)(a)" or "Art. 13"
+	Summary    string   `json:"summary"`
+	URL        string   `json:"url"`
+}
+
+// parseDecisions reads an enforcement dataset into one section per
+// decision: a heading stating who fined whom, when, how much and for which
+// articles, followed by the summary, tagged with the decision's metadata
+func parseDecisions(content []byte) ([]Section, error) {
+	var decisions []Decision
+	if trimmed := bytes.TrimLeft(content, "\ufeff \t\r\n"); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &decisions); err != nil {
+			return nil, fmt.Errorf("failed to parse decisions: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var d Decision
+			if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+				return nil, fmt.Errorf("line %d: invalid decision: %w", line, err)
+			}
+			decisions = append(decisions, d)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read decisions: %w", err)
+		}
+	}
+
+	sections := make([]Section, 0, len(decisions))
+	for i, d := range decisions {
+		if d.Authority == "" || d.Summary == "" {
+			return nil, fmt.Errorf("decision %d: authority and summary are required", i+1)
+		}
+		sections = append(sections, Section{Text: decisionText(d), Metadata: decisionMetadata(d)})
+	}
+	return sections, nil
+}
+
+// decisionText renders a decision as text to chunk and embed
+func decisionText(d Decision) string {
+	var sb strings.Builder
+	sb.WriteString(d.Authority)
+	if d.Country != "" {
+		sb.WriteString(" (" + strings.ToUpper(d.Country) + ")")
+	}
+	if d.Date != "" {
+		sb.WriteString(", " + d.Date)
+	}
+	if d.Controller != "" {
+		sb.WriteString(": " + d.Controller)
+	}
+	if d.Fine > 0 {
+		sb.WriteString(", fine of " + formatAmount(d.Fine) + " " + strings.ToUpper(d.Currency))
+	}
+	if len(d.Articles) > 0 {
+		articles := make([]string, len(d.Articles))
+		for i, a := range d.Articles {
+			articles[i] = "Art. " + articlePrefixRe.ReplaceAllString(strings.TrimSpace(a), "")
+		}
+		sb.WriteString(", for infringing " + strings.Join(articles, ", ") + " GDPR")
+	}
+	sb.WriteString("\n\n" + strings.TrimSpace(d.Summary))
+	return sb.String()
+}
+
+// decisionMetadata returns the metadata of a decision's chunks
+func decisionMetadata(d Decision) map[string]string {
+	m := map[string]string{MetaAuthority: d.Authority}
+	set := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	set(MetaDecision, d.ID)
+	set(MetaCountry, strings.ToUpper(d.Country))
+	set(MetaDecisionDate, d.Date)
+	set(MetaController, d.Controller)
+	set(MetaCurrency, strings.ToUpper(d.Currency))
+	set(MetaDecisionURL, d.URL)
+	if d.Fine > 0 {
+		m[MetaFine] = strconv.FormatFloat(d.Fine, 'f', -1, 64)
+	}
+
+	var articles []string
+	seen := make(map[string]bool)
+	for _, a := range d.Articles {
+		// "5(1)(f)" is recorded as Article 5
+		if i := strings.IndexByte(a, '('); i >= 0 {
+			a = a[:i]
+		}
+		if number, ok := db.ArticleNumber(a); ok && !seen[number] {
+			seen[number] = true
+			articles = append(articles, number)
+		}
+	}
+	set(MetaViolatedArticles, strings.Join(articles, ","))
+	return m
+}
+
+// formatAmount writes an amount with thousands separators, e.g. 1,200,000
+func formatAmount(amount float64) string {
+	digits := strconv.FormatFloat(amount, 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+	var sb strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(r)
+	}
+	if fraction != "" {
+		sb.WriteString("." + fraction)
+	}
+	return sb.String()
+}
@@ -0,0 +1,115 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+const decisionsFixture = `{"id":"DPC IN-18-5-5","authority":"Data Protection Commission","country":"ie","date":"2023-05-12","controller":"Meta Platforms Ireland Limited","fine":1200000000,"currency":"eur","articles":["46(1)"],"summary":"Transfers of personal data to the United States on the basis of standard contractual clauses without measures addressing the risks.","url":"https://example.org/dpc"}
+
+{"authority":"CNIL","country":"FR","date":"2019-01-21","controller":"Google LLC","fine":50000000,"currency":"EUR","articles":["Art. 6","Article 12","13","13(1)"],"summary":"Lack of transparency and of a valid legal basis for ads personalisation."}
+`
+
+func TestParseDecisions(t *testing.T) {
+	sections, err := parseDecisions([]byte(decisionsFixture))
+	if err != nil {
+		t.Fatalf("parseDecisions failed: %v", err)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 decisions, got %d", len(sections))
+	}
+
+	want := map[string]string{
+		MetaDecision:         "DPC IN-18-5-5",
+		MetaAuthority:        "Data Protection Commission",
+		MetaCountry:          "IE",
+		MetaDecisionDate:     "2023-05-12",
+		MetaController:       "Meta Platforms Ireland Limited",
+		MetaFine:             "1200000000",
+		MetaCurrency:         "EUR",
+		MetaViolatedArticles: "46",
+		MetaDecisionURL:      "https://example.org/dpc",
+	}
+	if !reflect.DeepEqual(sections[0].Metadata, want) {
+		t.Errorf("Metadata = %v, want %v", sections[0].Metadata, want)
+	}
+	heading := "Data Protection Commission (IE), 2023-05-12: Meta Platforms Ireland Limited, fine of 1,200,000,000 EUR, for infringing Art. 46(1) GDPR"
+	if !strings.HasPrefix(sections[0].Text, heading+"\n\nTransfers of personal data") {
+		t.Errorf("Text = %q", sections[0].Text)
+	}
+
+	if got := sections[1].Metadata[MetaViolatedArticles]; got != "6,12,13" {
+		t.Errorf("Expected articles 6, 12 and 13, got %q", got)
+	}
+	if !strings.Contains(sections[1].Text, "for infringing Art. 6, Art. 12, Art. 13, Art. 13(1) GDPR") {
+		t.Errorf("Text = %q", sections[1].Text)
+	}
+
+	array := "[" + strings.Join(strings.Split(strings.TrimSpace(decisionsFixture), "\n\n"), ",") + "]"
+	if sections, err := parseDecisions([]byte(array)); err != nil || len(sections) != 2 {
+		t.Errorf("Expected a JSON array to parse, got %d sections, %v", len(sections), err)
+	}
+	if _, err := parseDecisions([]byte(`{"authority":"CNIL"}`)); err == nil {
+		t.Error("Expected a decision without summary to be rejected")
+	}
+	if _, err := parseDecisions([]byte("not json")); err == nil {
+		t.Error("Expected invalid JSON to be rejected")
+	}
+}
+
+func TestEnforcementDataset(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "enforcement.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read the dataset: %v", err)
+	}
+	sections, err := parseDecisions(content)
+	if err != nil {
+		t.Fatalf("parseDecisions failed: %v", err)
+	}
+	for i, s := range sections {
+		m := s.Metadata
+		if len(m[MetaCountry]) != 2 || len(m[MetaDecisionDate]) != len("2006-01-02") || m[MetaFine] == "" || m[MetaViolatedArticles] == "" {
+			t.Errorf("Decision %d is incomplete: %v", i+1, m)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for amount, want := range map[float64]string{0: "0", 950: "950", 1000: "1,000", 35258708: "35,258,708", 1234.5: "1,234.5"} {
+		if got := formatAmount(amount); got != want {
+			t.Errorf("formatAmount(%v) = %q, want %q", amount, got, want)
+		}
+	}
+}
+
+func TestIngestEnforcementProfile(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Profile = ProfileEnforcement
+	if err := NewWithEmbedder(database, config, &fakeEmbedder{}).IngestReader("decisions.jsonl", strings.NewReader(decisionsFixture)); err != nil {
+		t.Fatalf("IngestReader failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("legal basis", 10, db.SearchOptions{Collection: EnforcementCollection, ViolatedArticle: "6", Country: "FR"})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("SearchTrigrams = %+v, %v", results, err)
+	}
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil || doc == nil {
+		t.Fatalf("GetDocument = %v, %v", doc, err)
+	}
+	if doc.Metadata[MetaController] != "Google LLC" {
+		t.Errorf("Unexpected metadata %v", doc.Metadata)
+	}
+	// "Article 12" in the heading is not the provision the chunk belongs to
+	if _, ok := doc.Metadata[MetaArticle]; ok {
+		t.Errorf("Expected no article metadata, got %v", doc.Metadata)
+	}
+}
@@ -0,0 +1,177 @@
+package ingest
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GDPRConsolidatedCELEX is the CELEX number of the consolidated GDPR text
+const GDPRConsolidatedCELEX = "02016R0679-20160504"
+
+// DefaultEURLexURL is the base URL of the EUR-Lex portal
+const DefaultEURLexURL = "https://eur-lex.europa.eu"
+
+// EURLexLanguages lists the official EU languages EUR-Lex publishes in
+var EURLexLanguages = []string{
+	"BG", "CS", "DA", "DE", "EL", "EN", "ES", "ET", "FI", "FR", "GA", "HR",
+	"HU", "IT", "LT", "LV", "MT", "NL", "PL", "PT", "RO", "SK", "SL", "SV",
+}
+
+// EURLexDownloader fetches legal texts from EUR-Lex as plain text and
+// caches them on disk so repeated ingests work offline
+type EURLexDownloader struct {
+	BaseURL  string
+	CacheDir string
+	Client   *http.Client
+}
+
+// NewEURLexDownloader creates a downloader caching into cacheDir. An empty
+// cacheDir uses the user cache directory.
+func NewEURLexDownloader(cacheDir string) (*EURLexDownloader, error) {
+	if cacheDir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+		cacheDir = filepath.Join(base, "gdpr-mcp", "eurlex")
+	}
+	return &EURLexDownloader{
+		BaseURL:  DefaultEURLexURL,
+		CacheDir: cacheDir,
+		Client:   &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Fetch returns the path of a cached plain-text copy of the document with
+// the given CELEX number in lang, downloading it on first use
+func (d *EURLexDownloader) Fetch(celex, lang string) (string, error) {
+	lang = strings.ToUpper(lang)
+	if !IsEURLexLanguage(lang) {
+		return "", fmt.Errorf("unsupported EUR-Lex language %q", lang)
+	}
+
+	path := filepath.Join(d.CacheDir, fmt.Sprintf("%s_%s.txt", celex, lang))
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		return path, nil
+	}
+
+	url := fmt.Sprintf("%s/legal-content/%s/TXT/HTML/?uri=CELEX:%s", strings.TrimRight(d.BaseURL, "/"), lang, celex)
+	resp, err := d.Client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("EUR-Lex returned status %d for %s", resp.StatusCode, url)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	text := htmlToText(string(body))
+	if text == "" {
+		return "", fmt.Errorf("EUR-Lex returned no text for %s", url)
+	}
+
+	if err := os.MkdirAll(d.CacheDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// Write atomically so an interrupted download never poisons the cache
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	return path, nil
+}
+
+// EURLexCollection names the collection holding the GDPR text in lang
+func EURLexCollection(lang string) string {
+	return "gdpr-" + strings.ToLower(lang)
+}
+
+// IngestEURLex downloads (or reuses the cached copy of) the consolidated
+// GDPR in lang and ingests it with every chunk tagged as lang. Unless the
+// configuration names a collection, the chunks go into the
+// EURLexCollection of lang.
+func (ing *Ingester) IngestEURLex(d *EURLexDownloader, lang string) error {
+	path, err := d.Fetch(GDPRConsolidatedCELEX, lang)
+	if err != nil {
+		return err
+	}
+
+	tagged := *ing
+	tagged.config.Language = strings.ToLower(lang)
+	if tagged.config.Collection == "" {
+		tagged.config.Collection = EURLexCollection(lang)
+	}
+	err = tagged.IngestFile(path)
+	// Keep the embedder if ingestion created it
+	ing.embedder = tagged.embedder
+	return err
+}
+
+// IngestEURLexLanguages ingests several language versions of the GDPR in
+// one run, each into its own collection as IngestEURLex does
+func (ing *Ingester) IngestEURLexLanguages(d *EURLexDownloader, langs []string) error {
+	for _, lang := range langs {
+		if !IsEURLexLanguage(strings.ToUpper(lang)) {
+			return fmt.Errorf("unsupported EUR-Lex language %q", lang)
+		}
+	}
+	for _, lang := range langs {
+		if err := ing.IngestEURLex(d, lang); err != nil {
+			return fmt.Errorf("failed to ingest %s version: %w", strings.ToUpper(lang), err)
+		}
+	}
+	return nil
+}
+
+// IsEURLexLanguage reports whether lang, in upper case, is one of
+// EURLexLanguages
+func IsEURLexLanguage(lang string) bool {
+	for _, l := range EURLexLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	htmlSkipRe  = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)>`)
+	htmlBlockRe = regexp.MustCompile(`(?i)<(br|/?p|/?div|/?tr|/?table|/?h[1-6]|/?li)\b[^>]*>`)
+	htmlCellRe  = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTagRe   = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankRunRe  = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText converts an EUR-Lex HTML page to plain text, keeping one line
+// per block element so article headings stay on their own lines
+func htmlToText(page string) string {
+	page = htmlSkipRe.ReplaceAllString(page, "")
+	page = htmlBlockRe.ReplaceAllString(page, "\n")
+	page = htmlCellRe.ReplaceAllString(page, " ")
+	page = htmlTagRe.ReplaceAllString(page, "")
+	page = html.UnescapeString(page)
+
+	lines := strings.Split(page, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	text := blankRunRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,162 @@
+package ingest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+const eurlexPage = `<html><head><title>EUR-Lex</title><style>p{}</style></head><body>
+<p class="ti-art">Article&nbsp;17</p>
+<p class="sti-art">Right to erasure (&lsquo;right to be forgotten&rsquo;)</p>
+<p>1. The data subject shall have the right to obtain from the controller the erasure of personal data.</p>
+<script>var tracking = true;</script>
+</body></html>`
+
+func TestHTMLToText(t *testing.T) {
+	text := htmlToText(eurlexPage)
+
+	expected := "Article 17\n\nRight to erasure (‘right to be forgotten’)\n\n1. The data subject shall have the right to obtain from the controller the erasure of personal data."
+	if text != expected {
+		t.Errorf("htmlToText() =\n%q\nwant\n%q", text, expected)
+	}
+}
+
+func TestEURLexFetchCaches(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/legal-content/DE/TXT/HTML/" || r.URL.Query().Get("uri") != "CELEX:"+GDPRConsolidatedCELEX {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(eurlexPage))
+	}))
+	defer srv.Close()
+
+	d, err := NewEURLexDownloader(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewEURLexDownloader failed: %v", err)
+	}
+	d.BaseURL = srv.URL
+
+	path, err := d.Fetch(GDPRConsolidatedCELEX, "de")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read cache file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "Article 17") {
+		t.Errorf("Unexpected cached content: %q", content)
+	}
+
+	// Second fetch is served from the cache
+	if _, err := d.Fetch(GDPRConsolidatedCELEX, "DE"); err != nil {
+		t.Fatalf("Fetch (cached) failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+
+	if _, err := d.Fetch(GDPRConsolidatedCELEX, "xx"); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+}
+
+func TestIngestEURLex(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(eurlexPage))
+	}))
+	defer srv.Close()
+
+	d, err := NewEURLexDownloader(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewEURLexDownloader failed: %v", err)
+	}
+	d.BaseURL = srv.URL
+
+	ingester := New(database, DefaultConfig())
+	if err := ingester.IngestEURLex(d, "en"); err != nil {
+		t.Fatalf("IngestEURLex failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("right to be forgotten", 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected downloaded text to be searchable, got %d results", len(results))
+	}
+}
+
+func TestIngestEURLexLanguages(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	pages := map[string]string{
+		"DE": "<p>Artikel 17</p><p>Recht auf Löschung („Recht auf Vergessenwerden“)</p>",
+		"FR": "<p>Article 17</p><p>Droit à l'effacement («droit à l'oubli»)</p>",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := strings.Split(r.URL.Path, "/")[2]
+		w.Write([]byte(pages[lang]))
+	}))
+	defer srv.Close()
+
+	d, err := NewEURLexDownloader(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewEURLexDownloader failed: %v", err)
+	}
+	d.BaseURL = srv.URL
+
+	ingester := New(database, DefaultConfig())
+	if err := ingester.IngestEURLexLanguages(d, []string{"xx"}); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+	if err := ingester.IngestEURLexLanguages(d, []string{"de", "FR"}); err != nil {
+		t.Fatalf("IngestEURLexLanguages failed: %v", err)
+	}
+
+	collections, err := database.Collections()
+	if err != nil {
+		t.Fatalf("Collections failed: %v", err)
+	}
+	if len(collections) != 2 || collections[0].Name != "gdpr-de" || collections[0].Language != "de" ||
+		collections[1].Name != "gdpr-fr" || collections[1].Language != "fr" {
+		t.Errorf("Expected a collection per language, got %+v", collections)
+	}
+
+	results, err := database.SearchTrigrams("effacement", 5, db.SearchOptions{Collection: "gdpr-de"})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected the French text outside the German collection, got %+v", results)
+	}
+}
+
+func TestEURLexFetchHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	d, err := NewEURLexDownloader(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewEURLexDownloader failed: %v", err)
+	}
+	d.BaseURL = srv.URL
+
+	if _, err := d.Fetch(GDPRConsolidatedCELEX, "EN"); err == nil {
+		t.Error("Expected error for failed download")
+	}
+}
@@ -0,0 +1,88 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// EvalCase is a question with the articles that answer it
+type EvalCase struct {
+	Query    string   `json:"query"`
+	Articles []string `json:"articles"` // article numbers, e.g. "17"
+}
+
+// EvalResult measures how well an embedder retrieves the answers to a set
+// of EvalCases by vector search alone
+type EvalResult struct {
+	Cases  int     `json:"cases"`
+	K      int     `json:"k"`
+	Recall float64 `json:"recall"` // share of cases with a chunk of an answering article in the top K
+	MRR    float64 `json:"mrr"`    // mean reciprocal rank of the first such chunk, 0 when not in the top K
+}
+
+// EvaluateEmbedder embeds docs and the queries of cases with embedder and
+// ranks the docs for each query by cosine similarity. A doc answers a case
+// when its article metadata is one of the case's articles. Comparing the
+// results of two embedders on the same docs and cases shows which retrieves
+// better on the corpus at hand.
+func EvaluateEmbedder(embedder Embedder, docs []db.Document, cases []EvalCase, k int) (*EvalResult, error) {
+	if len(docs) == 0 || len(cases) == 0 {
+		return nil, errors.New("evaluation needs documents and cases")
+	}
+	if k <= 0 {
+		k = 10
+	}
+
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Chunk
+	}
+	embeddings, err := embedder.EmbedDocuments(texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed documents: %w", err)
+	}
+	if len(embeddings) != len(docs) {
+		return nil, fmt.Errorf("got %d embeddings for %d documents", len(embeddings), len(docs))
+	}
+
+	result := &EvalResult{Cases: len(cases), K: k}
+	type scored struct {
+		index int
+		score float64
+	}
+	for _, c := range cases {
+		query, err := embedder.EmbedQuery(c.Query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query %q: %w", c.Query, err)
+		}
+		ranking := make([]scored, len(docs))
+		for i := range docs {
+			ranking[i] = scored{i, db.CosineSimilarity(query, embeddings[i])}
+		}
+		sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].score > ranking[j].score })
+
+		answers := make(map[string]bool)
+		for _, a := range c.Articles {
+			if number, ok := db.ArticleNumber(a); ok {
+				a = number
+			}
+			answers[a] = true
+		}
+		for rank, r := range ranking {
+			if rank >= k {
+				break
+			}
+			if answers[docs[r.index].Metadata["article"]] {
+				result.Recall++
+				result.MRR += 1 / float64(rank+1)
+				break
+			}
+		}
+	}
+	result.Recall /= float64(len(cases))
+	result.MRR /= float64(len(cases))
+	return result, nil
+}
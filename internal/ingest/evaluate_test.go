@@ -0,0 +1,67 @@
+package ingest
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// keywordEmbedder embeds a text as the presence of a few words, so that
+// similarity follows shared vocabulary
+type keywordEmbedder struct{ words []string }
+
+func (e keywordEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i], _ = e.EmbedQuery(text)
+	}
+	return embeddings, nil
+}
+
+func (e keywordEmbedder) EmbedQuery(text string) ([]float32, error) {
+	v := make([]float32, len(e.words))
+	for i, w := range e.words {
+		if strings.Contains(strings.ToLower(text), w) {
+			v[i] = 1
+		}
+	}
+	return v, nil
+}
+
+func TestEvaluateEmbedder(t *testing.T) {
+	docs := []db.Document{
+		{Chunk: "Right to erasure ('right to be forgotten')", Metadata: map[string]string{"article": "17"}},
+		{Chunk: "Right to data portability", Metadata: map[string]string{"article": "20"}},
+		{Chunk: "Notification of a personal data breach", Metadata: map[string]string{"article": "33"}},
+	}
+	cases := []EvalCase{
+		{Query: "erasure of my account", Articles: []string{"Article 17"}},
+		{Query: "breach notification", Articles: []string{"33"}},
+	}
+
+	good := keywordEmbedder{words: []string{"erasure", "portability", "breach"}}
+	result, err := EvaluateEmbedder(good, docs, cases, 1)
+	if err != nil {
+		t.Fatalf("EvaluateEmbedder failed: %v", err)
+	}
+	if result.Cases != 2 || result.Recall != 1 || result.MRR != 1 {
+		t.Errorf("Expected every answer ranked first, got %+v", result)
+	}
+
+	// An embedder blind to "breach" still finds erasure, and ranks the
+	// breach chunk no better than the others
+	blind := keywordEmbedder{words: []string{"erasure", "portability"}}
+	result, err = EvaluateEmbedder(blind, docs, cases, 3)
+	if err != nil {
+		t.Fatalf("EvaluateEmbedder failed: %v", err)
+	}
+	if result.Recall != 1 || math.Abs(result.MRR-(1+1.0/3)/2) > 1e-9 {
+		t.Errorf("Expected recall 1 and MRR 2/3, got %+v", result)
+	}
+
+	if _, err := EvaluateEmbedder(good, nil, cases, 1); err == nil {
+		t.Error("Expected an error without documents")
+	}
+}
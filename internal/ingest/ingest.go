@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,28 +16,56 @@ import (
 
 // Config holds ingestion configuration
 type Config struct {
-	ChunkSize    int
-	ChunkOverlap int
-	UseOpenAI    bool
-	OpenAIKey    string
-	OpenAIModel  string
+	ChunkSize          int
+	ChunkOverlap       int
+	Tokenizer          string // tiktoken encoding or model name; when set, ChunkSize and ChunkOverlap count tokens instead of runes
+	Embedder           string // registered embedder name, EmbedderStub by default
+	BatchSize          int    // chunks embedded per request
+	MaxRetries         int    // retries of rate-limited, failed or timed out embedding requests
+	OpenAIKey          string
+	OpenAIModel        string
+	OllamaURL          string
+	OllamaModel        string
+	ONNXModelDir       string           // directory with model.onnx and vocab.txt
+	ONNXRuntime        string           // path to the ONNX Runtime shared library; empty uses the system loader
+	Endpoint           EndpointConfig   // self-hosted OpenAI-compatible or TEI embeddings server
+	Language           string           // language tag for all chunks; empty detects per chunk
+	Charset            string           // encoding of text input, e.g. "windows-1252"; empty detects it
+	Collection         string           // collection for all chunks, e.g. one language version of the regulation
+	Strategy           string           // registered chunking strategy, StrategyWindow by default
+	SemanticPercentile float64          // distance percentile starting a new chunk with StrategySemantic; 0 uses DefaultSemanticPercentile
+	Profile            string           // ProfileRegulation (default), ProfileEDPB, ProfileEnforcement, ProfileCaseLaw or ProfileSCC
+	OCRCommand         string           // external OCR engine run on PDF pages without text, with {file} and {page} placeholders; empty disables OCR
+	OCRTimeout         time.Duration    // limit per page for OCRCommand; 0 uses DefaultOCRTimeout
+	Progress           ProgressReporter // receives progress events; nil discards them
 }
 
+// DefaultBatchSize is the number of chunks embedded per request
+const DefaultBatchSize = 64
+
 // DefaultConfig returns default ingestion configuration
 func DefaultConfig() Config {
 	return Config{
 		ChunkSize:    1000,
 		ChunkOverlap: 100,
-		UseOpenAI:    false,
+		Strategy:     StrategyWindow,
+		Embedder:     EmbedderStub,
+		BatchSize:    DefaultBatchSize,
+		MaxRetries:   DefaultMaxRetries,
 		OpenAIKey:    os.Getenv("OPENAI_API_KEY"),
 		OpenAIModel:  "text-embedding-3-small",
+		OllamaURL:    ollamaURLFromEnv(),
+		OllamaModel:  DefaultOllamaModel,
+		ONNXModelDir: DefaultONNXModelDir(),
 	}
 }
 
 // Ingester handles document ingestion
 type Ingester struct {
-	db     *db.DB
-	config Config
+	db       *db.DB
+	config   Config
+	embedder Embedder // created from config.Embedder on first use when nil
+	chunker  Chunker  // created from config.Strategy on first use when nil
 }
 
 // New creates a new Ingester
@@ -47,139 +76,386 @@ func New(database *db.DB, config Config) *Ingester {
 	}
 }
 
-// IngestFile ingests a text file into the database
+// NewWithEmbedder creates an Ingester using the given embedder instead of
+// the one named in config
+func NewWithEmbedder(database *db.DB, config Config, embedder Embedder) *Ingester {
+	return &Ingester{
+		db:       database,
+		config:   config,
+		embedder: embedder,
+	}
+}
+
+// Section is a span of source text with metadata inherited by its chunks
+type Section struct {
+	Text     string
+	Metadata map[string]string
+}
+
+// sectionChunk is a chunk of a section carrying the section metadata
+type sectionChunk struct {
+	text     string
+	metadata map[string]string
+}
+
+// StdinSource is the file name that makes IngestFile read standard input
+const StdinSource = "-"
+
+// IngestFile ingests a text, Markdown, PDF or legal XML (Formex or Akoma
+// Ntoso) file into the database. A path of StdinSource reads standard input;
+// a directory is ingested incrementally with IngestDir.
 func (ing *Ingester) IngestFile(filePath string) error {
+	if filePath == StdinSource {
+		return ing.IngestReader(StdinSource, os.Stdin)
+	}
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		_, err := ing.IngestDir(filePath)
+		return err
+	}
+
+	source := filePath
+	if abs, err := filepath.Abs(filePath); err == nil {
+		source = abs
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext == ".pdf" {
+		sections, err := ing.extractPDF(filePath)
+		if err != nil {
+			return err
+		}
+		return ing.IngestSource(source, sections)
+	}
+
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
+	return ing.ingestContent(source, ext, content)
+}
 
-	return ing.IngestText(string(content))
+// IngestReader ingests content read from r under the given source name.
+// There is no file extension to go by, so the format is detected from the
+// content itself.
+func (ing *Ingester) IngestReader(source string, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	return ing.ingestContent(source, sniffFormat(content), content)
+}
+
+// ingestContent parses content in the format implied by a file extension.
+// Content expected to be text is transcoded to UTF-8, or rejected when it
+// looks binary.
+func (ing *Ingester) ingestContent(source, ext string, content []byte) error {
+	if ing.config.Profile == ProfileEnforcement {
+		sections, err := parseDecisions(content)
+		if err != nil {
+			return err
+		}
+		return ing.IngestSource(source, sections)
+	}
+	if ext != ".pdf" {
+		text, err := ing.textContent(source, content)
+		if err != nil {
+			return err
+		}
+		content = text
+	}
+
+	switch ext {
+	case ".pdf":
+		ocr, cleanup, err := ing.ocrBytes(content)
+		if err != nil {
+			return err
+		}
+		sections, err := parsePDF(bytes.NewReader(content), int64(len(content)), ocr)
+		cleanup()
+		if err != nil {
+			return err
+		}
+		return ing.IngestSource(source, sections)
+	case ".md", ".markdown":
+		return ing.IngestSource(source, splitMarkdown(string(content)))
+	case ".xml", ".fmx", ".akn":
+		sections, err := parseLegalXML(bytes.NewReader(content))
+		if err != nil {
+			return err
+		}
+		return ing.IngestSource(source, sections)
+	default:
+		return ing.IngestSource(source, []Section{{Text: string(content)}})
+	}
+}
+
+// sniffFormat guesses the file extension of content without a name:
+// PDF and XML by their signature, Markdown by an ATX heading on the
+// first non-blank line
+func sniffFormat(content []byte) string {
+	trimmed := bytes.TrimLeft(content, "\ufeff \t\r\n")
+	switch {
+	case bytes.HasPrefix(content, []byte("%PDF-")):
+		return ".pdf"
+	case bytes.HasPrefix(trimmed, []byte("<?xml")), bytes.HasPrefix(trimmed, []byte("<akomaNtoso")):
+		return ".xml"
+	case bytes.HasPrefix(trimmed, []byte("# ")), bytes.HasPrefix(trimmed, []byte("## ")):
+		return ".md"
+	default:
+		return ".txt"
+	}
 }
 
 // IngestText ingests text content into the database
 func (ing *Ingester) IngestText(content string) error {
-	// Split into chunks
-	chunks := ing.chunkText(content)
+	return ing.IngestSections([]Section{{Text: content}})
+}
 
-	fmt.Printf("Ingesting %d chunks...\n", len(chunks))
+// IngestSections chunks each section separately and ingests the chunks,
+// copying the section metadata onto every chunk
+func (ing *Ingester) IngestSections(sections []Section) error {
+	return ing.IngestSource("", sections)
+}
 
-	for i, chunk := range chunks {
-		// Insert chunk
-		docID, err := ing.db.InsertChunk(chunk, i)
-		if err != nil {
-			return fmt.Errorf("failed to insert chunk %d: %w", i, err)
+// IngestSource ingests the sections of a named source such as a file path,
+// checkpointing after every batch. Ingesting the same chunks again resumes
+// where an interrupted run stopped, or does nothing once it has completed.
+// An empty source disables checkpointing.
+func (ing *Ingester) IngestSource(source string, sections []Section) error {
+	if err := validProfile(ing.config.Profile); err != nil {
+		return err
+	}
+
+	switch ing.config.Profile {
+	case ProfileEDPB:
+		var split []Section
+		for _, section := range sections {
+			split = append(split, splitEDPB(section)...)
+		}
+		sections = split
+	case ProfileSCC:
+		var split []Section
+		for _, section := range sections {
+			split = append(split, splitSCC(section)...)
+		}
+		sections = split
+	case ProfileCaseLaw:
+		// The case is identified on the first page, but every chunk of the
+		// judgment is tagged with it
+		texts := make([]string, len(sections))
+		for i, section := range sections {
+			texts[i] = section.Text
+		}
+		judgment := judgmentMetadata(strings.Join(texts, "\n"))
+		tagged := make([]Section, len(sections))
+		for i, section := range sections {
+			tagged[i] = Section{Text: section.Text, Metadata: mergeMetadata(section.Metadata, judgment)}
 		}
+		sections = tagged
+	}
 
-		// Generate and insert trigrams
-		trigrams := db.GenerateTrigrams(chunk)
-		if err := ing.db.InsertTrigrams(docID, trigrams); err != nil {
-			return fmt.Errorf("failed to insert trigrams for chunk %d: %w", i, err)
+	collection := ing.config.Collection
+	if collection == "" {
+		switch ing.config.Profile {
+		case ProfileEDPB:
+			collection = EDPBCollection
+		case ProfileEnforcement:
+			collection = EnforcementCollection
+		case ProfileCaseLaw:
+			collection = CaseLawCollection
+		case ProfileSCC:
+			collection = SCCCollection
 		}
+	}
 
-		// Generate and insert embedding
-		embedding, err := ing.generateEmbedding(chunk)
+	if ing.embedder == nil {
+		embedder, err := NewEmbedder(ing.config.Embedder, ing.config)
+		if err != nil {
+			return err
+		}
+		ing.embedder = embedder
+	}
+	if ing.chunker == nil {
+		chunker, err := NewChunker(ing.config.Strategy, ing.config)
 		if err != nil {
-			fmt.Printf("Warning: failed to generate embedding for chunk %d: %v\n", i, err)
-			// Use stub embedding if real embedding fails
-			embedding = stubEmbedding(chunk)
+			return err
+		}
+		if c, ok := chunker.(embeddingChunker); ok {
+			c.setEmbedder(ing.embedder)
 		}
+		ing.chunker = chunker
+	}
 
-		if err := ing.db.InsertEmbedding(docID, embedding); err != nil {
-			return fmt.Errorf("failed to insert embedding for chunk %d: %w", i, err)
+	// Chunking semantically embeds the text, so a completed source is
+	// recognised from its sections instead of its chunks
+	var fingerprint string
+	if _, ok := ing.chunker.(embeddingChunker); ok {
+		fingerprint = ing.inputFingerprint(collection, sections)
+		total, err := ing.completed(source, fingerprint)
+		if err != nil {
+			return err
 		}
+		if total > 0 {
+			ing.report(StageSkipped, source, total, total)
+			return nil
+		}
+	}
 
-		if (i+1)%10 == 0 {
-			fmt.Printf("Processed %d/%d chunks\n", i+1, len(chunks))
+	// Split into chunks, tagging regulation chunks with the provision they
+	// start in. Guidelines and decisions only cite provisions, so their
+	// numbered paragraphs must not be mistaken for those of an article.
+	regulation := ing.config.Profile == "" || ing.config.Profile == ProfileRegulation
+	var chunks []sectionChunk
+	for _, section := range sections {
+		pieces, err := ing.chunker.Chunk(section.Text)
+		if err != nil {
+			return fmt.Errorf("failed to chunk text: %w", err)
+		}
+		if !regulation {
+			var paragraphs []string
+			var paragraphKey string
+			switch ing.config.Profile {
+			case ProfileEDPB:
+				paragraphs, paragraphKey = chunkParagraphs(section.Text, pieces, guidelineParagraph), MetaGuidelineParagraph
+			case ProfileCaseLaw:
+				paragraphs, paragraphKey = chunkParagraphs(section.Text, pieces, judgmentParagraph), MetaJudgmentParagraph
+			}
+			for i, chunk := range pieces {
+				metadata := section.Metadata
+				if paragraphs != nil && paragraphs[i] != "" {
+					metadata = mergeMetadata(metadata, map[string]string{paragraphKey: paragraphs[i]})
+				}
+				chunks = append(chunks, sectionChunk{chunk, metadata})
+			}
+			continue
+		}
+		detected := provisionMetadata(section.Text, pieces)
+		for i, chunk := range pieces {
+			chunks = append(chunks, sectionChunk{chunk, classifyProvision(mergeMetadata(section.Metadata, detected[i]))})
 		}
 	}
 
-	// Store metadata
-	if err := ing.db.SetMetadata("ingested_at", time.Now().Format(time.RFC3339)); err != nil {
-		return fmt.Errorf("failed to set metadata: %w", err)
+	if fingerprint == "" {
+		fingerprint = ing.chunkFingerprint(collection, chunks)
 	}
-	if err := ing.db.SetMetadata("chunk_count", fmt.Sprintf("%d", len(chunks))); err != nil {
-		return fmt.Errorf("failed to set metadata: %w", err)
+	first, err := ing.resume(source, fingerprint, len(chunks))
+	if err != nil {
+		return err
+	}
+	if first >= len(chunks) && len(chunks) > 0 {
+		ing.report(StageSkipped, source, len(chunks), len(chunks))
+		return nil
+	}
+	if first > 0 {
+		ing.report(StageResumed, source, first, len(chunks))
 	}
 
-	fmt.Printf("Successfully ingested %d chunks\n", len(chunks))
-	return nil
-}
-
-// chunkText splits text into overlapping chunks
-func (ing *Ingester) chunkText(text string) []string {
-	// Normalize whitespace
-	text = strings.TrimSpace(text)
-	text = strings.ReplaceAll(text, "\r\n", "\n")
-
-	var chunks []string
-	runes := []rune(text)
-	textLen := len(runes)
+	ing.report(StageStarted, source, first, len(chunks))
 
-	if textLen == 0 {
-		return chunks
+	batchSize := ing.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
 	}
 
-	chunkSize := ing.config.ChunkSize
-	overlap := ing.config.ChunkOverlap
+	for start := first; start < len(chunks); start += batchSize {
+		end := start + batchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		batch := chunks[start:end]
 
-	for start := 0; start < textLen; {
-		end := start + chunkSize
-		if end > textLen {
-			end = textLen
+		// Generate embeddings for the whole batch in one request
+		texts := make([]string, len(batch))
+		for j, c := range batch {
+			texts[j] = c.text
+		}
+		began := time.Now()
+		embeddings, err := ing.embedder.EmbedDocuments(texts)
+		embedTime := time.Since(began)
+		if err != nil {
+			return fmt.Errorf("failed to generate embeddings for chunks %d-%d: %w", start, end-1, err)
+		}
+		if len(embeddings) != len(texts) {
+			return fmt.Errorf("got %d embeddings for %d chunks", len(embeddings), len(texts))
 		}
 
-		// Try to break at sentence or word boundary
-		if end < textLen {
-			// Look for sentence boundary
-			for i := end; i > start+chunkSize/2; i-- {
-				if runes[i] == '.' || runes[i] == '\n' {
-					end = i + 1
-					break
-				}
+		trigrams := make(map[int64][]string, len(batch))
+		for j, c := range batch {
+			i := start + j
+			chunk := c.text
+
+			// Insert chunk
+			language := ing.config.Language
+			if language == "" {
+				language = DetectLanguage(chunk)
 			}
-			// Fall back to word boundary
-			if end == start+chunkSize && end < textLen {
-				for i := end; i > start+chunkSize/2; i-- {
-					if runes[i] == ' ' {
-						end = i
-						break
-					}
-				}
+			docID, err := ing.db.InsertDocument(db.Document{
+				Chunk:      chunk,
+				ChunkIndex: i,
+				Language:   language,
+				Metadata:   c.metadata,
+				Source:     source,
+				Collection: collection,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to insert chunk %d: %w", i, err)
+			}
+
+			trigrams[docID] = db.LanguageTrigrams(chunk, language)
+			if err := ing.db.InsertKeyphrases(docID, db.ExtractKeyphrases(chunk)); err != nil {
+				return fmt.Errorf("failed to insert keyphrases for chunk %d: %w", i, err)
+			}
+
+			if err := ing.db.InsertEmbedding(docID, embeddings[j]); err != nil {
+				return fmt.Errorf("failed to insert embedding for chunk %d: %w", i, err)
 			}
 		}
 
-		chunk := strings.TrimSpace(string(runes[start:end]))
-		if len(chunk) > 0 {
-			chunks = append(chunks, chunk)
+		// Index the batch's trigrams together, writing each posting list once
+		if err := ing.db.InsertTrigramsBatch(trigrams); err != nil {
+			return fmt.Errorf("failed to insert trigrams for chunks %d-%d: %w", start, end-1, err)
 		}
 
-		// Move start position with overlap
-		start = end - overlap
-		if start < 0 {
-			start = 0
+		if checkpointed(source) {
+			if err := ing.db.SaveCheckpoint(db.Checkpoint{
+				Source:      source,
+				Fingerprint: fingerprint,
+				NextChunk:   end,
+				TotalChunks: len(chunks),
+			}); err != nil {
+				return err
+			}
 		}
-		// Prevent infinite loop
-		if end >= textLen {
-			break
+		ing.reportBatch(source, end, len(chunks), embedTime)
+	}
+
+	// Store metadata
+	for key, value := range map[string]string{
+		db.MetaIngestedAt:     time.Now().Format(time.RFC3339),
+		db.MetaChunkCount:     fmt.Sprintf("%d", len(chunks)),
+		db.MetaEmbedder:       ing.config.EmbedderName(),
+		db.MetaEmbeddingModel: ing.config.EmbeddingModel(),
+	} {
+		if err := ing.db.SetMetadata(key, value); err != nil {
+			return fmt.Errorf("failed to set metadata: %w", err)
 		}
 	}
 
-	return chunks
+	ing.report(StageFinished, source, len(chunks), len(chunks))
+	return nil
 }
 
-// generateEmbedding generates an embedding for the text
-func (ing *Ingester) generateEmbedding(text string) ([]float32, error) {
-	if ing.config.UseOpenAI && ing.config.OpenAIKey != "" {
-		return openAIEmbedding(text, ing.config.OpenAIKey, ing.config.OpenAIModel)
-	}
-	return stubEmbedding(text), nil
+// openAIEmbeddings calls OpenAI embeddings API
+func openAIEmbeddings(texts []string, apiKey, model string) ([][]float32, error) {
+	return openAICompatibleEmbeddings(texts, "https://api.openai.com/v1/embeddings", model, "Authorization", "Bearer "+apiKey)
 }
 
-// openAIEmbedding calls OpenAI embeddings API
-func openAIEmbedding(text, apiKey, model string) ([]float32, error) {
+// openAICompatibleEmbeddings calls an embeddings API speaking the OpenAI
+// request and response format at url, embedding all texts in one request
+// and sending the given auth header if set
+func openAICompatibleEmbeddings(texts []string, url, model, authHeader, authValue string) ([][]float32, error) {
 	reqBody := map[string]interface{}{
-		"input": text,
+		"input": texts,
 		"model": model,
 	}
 
@@ -188,15 +464,56 @@ func openAIEmbedding(text, apiKey, model string) ([]float32, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/embeddings", bytes.NewReader(jsonBody))
+	body, err := postEmbeddingRequest(url, jsonBody, authHeader, authValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings in response, got %d", len(texts), len(result.Data))
+	}
+
+	// Results carry the index of their input; convert float64 to float32
+	embeddings := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) || embeddings[d.Index] != nil {
+			return nil, fmt.Errorf("invalid embedding index %d in response", d.Index)
+		}
+		embedding := make([]float32, len(d.Embedding))
+		for i, v := range d.Embedding {
+			embedding[i] = float32(v)
+		}
+		embeddings[d.Index] = embedding
+	}
+
+	return embeddings, nil
+}
+
+// postEmbeddingRequest posts a JSON request body to an embeddings API and
+// returns the response body
+func postEmbeddingRequest(url string, jsonBody []byte, authHeader, authValue string) ([]byte, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if authHeader != "" {
+		req.Header.Set(authHeader, authValue)
+	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 60 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
@@ -209,30 +526,14 @@ func openAIEmbedding(text, apiKey, model string) ([]float32, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
-	}
-
-	var result struct {
-		Data []struct {
-			Embedding []float64 `json:"embedding"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("no embedding in response")
-	}
-
-	// Convert float64 to float32
-	embedding := make([]float32, len(result.Data[0].Embedding))
-	for i, v := range result.Data[0].Embedding {
-		embedding[i] = float32(v)
+		return nil, &apiError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Body:       string(body),
+		}
 	}
 
-	return embedding, nil
+	return body, nil
 }
 
 // stubEmbedding generates a simple hash-based embedding for offline use
@@ -263,11 +564,3 @@ func stubEmbedding(text string) []float32 {
 
 	return embedding
 }
-
-// EmbedQuery generates an embedding for a search query
-func EmbedQuery(query string, useOpenAI bool, apiKey, model string) ([]float32, error) {
-	if useOpenAI && apiKey != "" {
-		return openAIEmbedding(query, apiKey, model)
-	}
-	return stubEmbedding(query), nil
-}
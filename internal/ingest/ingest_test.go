@@ -3,6 +3,7 @@ package ingest
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jc/gdpr-mcp/internal/db"
@@ -37,17 +38,34 @@ func setupTestDB(t *testing.T) (*db.DB, func()) {
 	return database, cleanup
 }
 
-func TestChunking(t *testing.T) {
-	database, cleanup := setupTestDB(t)
-	defer cleanup()
+// newTestChunker creates the chunker config selects
+func newTestChunker(t *testing.T, config Config) Chunker {
+	t.Helper()
+	chunker, err := NewChunker(config.Strategy, config)
+	if err != nil {
+		t.Fatalf("NewChunker failed: %v", err)
+	}
+	return chunker
+}
+
+// mustChunk chunks text, failing the test on error
+func mustChunk(t *testing.T, chunker Chunker, text string) []string {
+	t.Helper()
+	chunks, err := chunker.Chunk(text)
+	if err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	return chunks
+}
 
+func TestChunking(t *testing.T) {
 	config := Config{
 		ChunkSize:    100,
 		ChunkOverlap: 20,
-		UseOpenAI:    false,
+		Embedder:     EmbedderStub,
 	}
 
-	ingester := New(database, config)
+	chunker := newTestChunker(t, config)
 
 	// Create test text
 	text := "This is sentence one. This is sentence two. This is sentence three. " +
@@ -55,7 +73,7 @@ func TestChunking(t *testing.T) {
 		"This is sentence seven. This is sentence eight. This is sentence nine. " +
 		"This is sentence ten. This is the final sentence."
 
-	chunks := ingester.chunkText(text)
+	chunks := mustChunk(t, chunker, text)
 
 	if len(chunks) == 0 {
 		t.Error("Expected at least one chunk")
@@ -82,32 +100,26 @@ func TestChunking(t *testing.T) {
 }
 
 func TestChunkingEmptyText(t *testing.T) {
-	database, cleanup := setupTestDB(t)
-	defer cleanup()
-
 	config := DefaultConfig()
-	ingester := New(database, config)
+	chunker := newTestChunker(t, config)
 
-	chunks := ingester.chunkText("")
+	chunks := mustChunk(t, chunker, "")
 	if len(chunks) != 0 {
 		t.Errorf("Expected no chunks for empty text, got %d", len(chunks))
 	}
 }
 
 func TestChunkingShortText(t *testing.T) {
-	database, cleanup := setupTestDB(t)
-	defer cleanup()
-
 	config := Config{
 		ChunkSize:    1000,
 		ChunkOverlap: 100,
-		UseOpenAI:    false,
+		Embedder:     EmbedderStub,
 	}
 
-	ingester := New(database, config)
+	chunker := newTestChunker(t, config)
 
 	text := "Short text."
-	chunks := ingester.chunkText(text)
+	chunks := mustChunk(t, chunker, text)
 
 	if len(chunks) != 1 {
 		t.Errorf("Expected 1 chunk for short text, got %d", len(chunks))
@@ -125,7 +137,7 @@ func TestIngestText(t *testing.T) {
 	config := Config{
 		ChunkSize:    200,
 		ChunkOverlap: 50,
-		UseOpenAI:    false,
+		Embedder:     EmbedderStub,
 	}
 
 	ingester := New(database, config)
@@ -155,15 +167,27 @@ and, where that is the case, access to the personal data and the following infor
 	if count == "" || count == "0" {
 		t.Error("Expected positive chunk count in metadata")
 	}
+	if embedder, _ := database.GetMetadata(db.MetaEmbedder); embedder != EmbedderStub {
+		t.Errorf("Expected embedder %q in metadata, got %q", EmbedderStub, embedder)
+	}
 
 	// Verify we can search the content
-	results, err := database.SearchTrigrams("data subject", 10)
+	results, err := database.SearchTrigrams("data subject", 10, db.SearchOptions{})
 	if err != nil {
 		t.Fatalf("SearchTrigrams failed: %v", err)
 	}
 
 	if len(results) == 0 {
-		t.Error("Expected search results after ingestion")
+		t.Fatal("Expected search results after ingestion")
+	}
+
+	// Keyphrases are stored with each chunk
+	phrases, err := database.Keyphrases(results[0].ID)
+	if err != nil {
+		t.Fatalf("Keyphrases failed: %v", err)
+	}
+	if len(phrases) == 0 {
+		t.Error("Expected keyphrases stored at ingestion")
 	}
 }
 
@@ -190,7 +214,7 @@ have the obligation to erase personal data without undue delay.`
 	tmpFile.Close()
 
 	config := DefaultConfig()
-	config.UseOpenAI = false
+	config.Embedder = EmbedderStub
 	config.ChunkSize = 200
 
 	ingester := New(database, config)
@@ -201,7 +225,7 @@ have the obligation to erase personal data without undue delay.`
 	}
 
 	// Verify we can find the content
-	results, err := database.SearchTrigrams("erasure", 10)
+	results, err := database.SearchTrigrams("erasure", 10, db.SearchOptions{})
 	if err != nil {
 		t.Fatalf("SearchTrigrams failed: %v", err)
 	}
@@ -211,6 +235,63 @@ have the obligation to erase personal data without undue delay.`
 	}
 }
 
+func TestIngestReader(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ingester := New(database, DefaultConfig())
+	if err := ingester.IngestReader(StdinSource, strings.NewReader(testFormex)); err != nil {
+		t.Fatalf("IngestReader failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("withdraws consent", 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	doc, _ := database.GetDocument(results[0].ID)
+	if doc.Source != StdinSource || doc.Metadata[MetaArticle] != "17" {
+		t.Errorf("Expected piped XML to be parsed as XML, got %+v", doc)
+	}
+}
+
+func TestIngestReaderKeepsEarlierInput(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Documents piped in one after another are all kept
+	ingester := NewWithEmbedder(database, DefaultConfig(), &fakeEmbedder{})
+	for _, text := range []string{"Processing shall be lawful.", "Consent shall be freely given."} {
+		if err := ingester.IngestReader(StdinSource, strings.NewReader(text)); err != nil {
+			t.Fatalf("IngestReader failed: %v", err)
+		}
+	}
+	if n := countChunks(t, database); n != 2 {
+		t.Errorf("Expected the chunks of both inputs, got %d chunks", n)
+	}
+}
+
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"%PDF-1.4\n", ".pdf"},
+		{"\ufeff<?xml version=\"1.0\"?><ACT/>", ".xml"},
+		{"\n<akomaNtoso></akomaNtoso>", ".xml"},
+		{"# Chapter I\n\nGeneral provisions", ".md"},
+		{"Article 1\nSubject-matter and objectives", ".txt"},
+		{"#hashtag", ".txt"},
+	}
+	for _, tt := range tests {
+		if got := sniffFormat([]byte(tt.content)); got != tt.want {
+			t.Errorf("sniffFormat(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
 func TestStubEmbedding(t *testing.T) {
 	text := "Test embedding generation"
 	embedding := stubEmbedding(text)
@@ -260,7 +341,11 @@ func TestEmbedQuery(t *testing.T) {
 	query := "right of access"
 
 	// Test stub embedding
-	embedding, err := EmbedQuery(query, false, "", "")
+	embedder, err := NewEmbedder(EmbedderStub, DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewEmbedder failed: %v", err)
+	}
+	embedding, err := embedder.EmbedQuery(query)
 	if err != nil {
 		t.Fatalf("EmbedQuery failed: %v", err)
 	}
@@ -281,11 +366,39 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected ChunkOverlap 100, got %d", config.ChunkOverlap)
 	}
 
-	if config.UseOpenAI != false {
-		t.Error("Expected UseOpenAI to be false by default")
+	if config.Embedder != EmbedderStub {
+		t.Errorf("Expected the stub embedder by default, got %q", config.Embedder)
 	}
 
 	if config.OpenAIModel != "text-embedding-3-small" {
 		t.Errorf("Expected OpenAIModel 'text-embedding-3-small', got %s", config.OpenAIModel)
 	}
 }
+
+func TestIngestTextTagsLanguage(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ingester := New(database, Config{ChunkSize: 1000, ChunkOverlap: 100})
+
+	text := "Die betroffene Person hat das Recht, von dem Verantwortlichen zu verlangen, dass sie betreffende personenbezogene Daten unverzüglich gelöscht werden."
+	if err := ingester.IngestText(text); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("personenbezogene", 10, db.SearchOptions{Language: "de"})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected German chunk to be tagged 'de', got %d results", len(results))
+	}
+
+	results, err = database.SearchTrigrams("personenbezogene", 10, db.SearchOptions{Language: "en"})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no English results, got %d", len(results))
+	}
+}
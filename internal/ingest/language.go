@@ -0,0 +1,119 @@
+package ingest
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords holds very frequent function words for each supported language.
+// Counting them is enough to tell the official GDPR languages we care about
+// apart without shipping a statistical model.
+var stopwords = map[string][]string{
+	"en": {"the", "of", "and", "to", "in", "is", "that", "for", "be", "shall", "which", "with", "or", "by", "this"},
+	"de": {"der", "die", "und", "des", "den", "von", "zu", "das", "mit", "sich", "ist", "im", "dem", "nicht", "eine"},
+	"fr": {"de", "la", "le", "et", "les", "des", "du", "en", "un", "une", "est", "pour", "que", "qui", "dans"},
+	"es": {"de", "la", "que", "el", "en", "los", "del", "las", "por", "un", "una", "para", "con", "se", "al"},
+	"it": {"di", "il", "che", "la", "e", "per", "del", "della", "dei", "un", "una", "sono", "le", "nel", "alla"},
+	"nl": {"de", "het", "van", "een", "en", "in", "is", "dat", "op", "te", "voor", "met", "zijn", "niet", "worden"},
+	"pt": {"de", "que", "o", "a", "do", "da", "em", "os", "para", "dos", "das", "um", "uma", "com", "no"},
+	"da": {"og", "at", "er", "til", "af", "den", "det", "med", "som", "på", "ikke", "eller", "skal", "kan", "denne"},
+	"sv": {"och", "att", "det", "som", "på", "är", "av", "för", "med", "till", "den", "inte", "om", "eller", "ska"},
+	"pl": {"w", "na", "z", "do", "się", "nie", "że", "jest", "oraz", "przez", "lub", "dla", "od", "być", "danych"},
+	"cs": {"a", "v", "se", "na", "je", "že", "z", "o", "do", "pro", "nebo", "jako", "by", "s", "podle"},
+	"ro": {"și", "la", "în", "cu", "pe", "care", "nu", "din", "se", "un", "o", "pentru", "sau", "este", "sunt"},
+	"hu": {"a", "az", "és", "hogy", "nem", "egy", "is", "van", "vagy", "meg", "csak", "által", "kell", "ha", "amely"},
+	"fi": {"ja", "on", "ei", "että", "se", "tai", "joka", "sekä", "kun", "ovat", "voi", "jos", "mukaan", "tämän", "myös"},
+}
+
+// Languages identified by their script alone: Greek is the only official
+// EU language written in Greek script, Bulgarian the only one in Cyrillic
+var scriptLanguages = []struct {
+	script *unicode.RangeTable
+	lang   string
+}{
+	{unicode.Greek, "el"},
+	{unicode.Cyrillic, "bg"},
+}
+
+// minLanguageHits is the minimum number of stopword hits needed before a
+// language is reported; shorter texts are left untagged
+const minLanguageHits = 3
+
+var stopwordSets = buildStopwordSets()
+
+func buildStopwordSets() map[string]map[string]bool {
+	sets := make(map[string]map[string]bool, len(stopwords))
+	for lang, words := range stopwords {
+		set := make(map[string]bool, len(words))
+		for _, w := range words {
+			set[w] = true
+		}
+		sets[lang] = set
+	}
+	return sets
+}
+
+// DetectLanguage guesses the ISO 639-1 language code of text from stopword
+// frequencies. It returns "" when the text is too short to decide.
+func DetectLanguage(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	counts := make(map[string]int)
+	for _, w := range words {
+		for lang, set := range stopwordSets {
+			if set[w] {
+				counts[lang]++
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for lang, count := range counts {
+		// Break ties alphabetically so detection is deterministic
+		if count > bestCount || (count == bestCount && lang < best) {
+			best, bestCount = lang, count
+		}
+	}
+
+	if bestCount < minLanguageHits {
+		return ""
+	}
+	return best
+}
+
+// minScriptLetters is the minimum number of letters needed before a
+// language is inferred from its script
+const minScriptLetters = 20
+
+// detectScript returns the language of text written mostly in a script
+// used by a single EU language
+func detectScript(text string) string {
+	letters := 0
+	counts := make([]int, len(scriptLanguages))
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for i, sl := range scriptLanguages {
+			if unicode.Is(sl.script, r) {
+				counts[i]++
+			}
+		}
+	}
+	if letters < minScriptLetters {
+		return ""
+	}
+	for i, sl := range scriptLanguages {
+		if counts[i]*2 > letters {
+			return sl.lang
+		}
+	}
+	return ""
+}
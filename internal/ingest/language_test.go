@@ -0,0 +1,60 @@
+package ingest
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "english",
+			text:     "The data subject shall have the right to obtain from the controller the erasure of personal data.",
+			expected: "en",
+		},
+		{
+			name:     "german",
+			text:     "Die betroffene Person hat das Recht, von dem Verantwortlichen zu verlangen, dass sie betreffende personenbezogene Daten unverzüglich gelöscht werden, und der Verantwortliche ist verpflichtet, personenbezogene Daten unverzüglich zu löschen.",
+			expected: "de",
+		},
+		{
+			name:     "french",
+			text:     "La personne concernée a le droit d'obtenir du responsable du traitement l'effacement, dans les meilleurs délais, de données à caractère personnel la concernant et le responsable du traitement a l'obligation d'effacer ces données.",
+			expected: "fr",
+		},
+		{
+			name:     "polish",
+			text:     "Dane osobowe są przetwarzane w sposób zgodny z prawem, rzetelny i przejrzysty dla osoby, której dane dotyczą, oraz zbierane w konkretnych celach i nie są przetwarzane dalej w sposób niezgodny z tymi celami.",
+			expected: "pl",
+		},
+		{
+			name:     "swedish",
+			text:     "Den registrerade ska ha rätt att av den personuppgiftsansvarige utan onödigt dröjsmål få sina personuppgifter raderade och den personuppgiftsansvarige ska vara skyldig att utan onödigt dröjsmål radera personuppgifter.",
+			expected: "sv",
+		},
+		{
+			name:     "greek script",
+			text:     "Το υποκείμενο των δεδομένων έχει το δικαίωμα να ζητήσει από τον υπεύθυνο επεξεργασίας τη διαγραφή δεδομένων.",
+			expected: "el",
+		},
+		{
+			name:     "cyrillic script",
+			text:     "Субектът на данните има право да поиска от администратора изтриване на свързаните с него лични данни.",
+			expected: "bg",
+		},
+		{
+			name:     "too short",
+			text:     "Article 17",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.expected {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package ingest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Metadata keys describing the legal structure of a chunk
+const (
+	MetaChapter      = "chapter"
+	MetaChapterTitle = "chapter_title"
+	MetaSection      = "section"
+	MetaSectionTitle = "section_title"
+	MetaArticle      = "article"
+	MetaArticleTitle = "article_title"
+	MetaParagraph    = "paragraph"
+	MetaRecital      = "recital"
+	MetaAnnex        = "annex"
+
+	// MetaProvisionType classifies a chunk as a recital, an article or an
+	// annex, since recitals and operative articles carry different legal
+	// weight
+	MetaProvisionType = "provision_type"
+)
+
+// Provision types stored under MetaProvisionType
+const (
+	ProvisionRecital = "recital"
+	ProvisionArticle = "article"
+	ProvisionAnnex   = "annex"
+)
+
+// xmlNode is a minimal DOM node; text nodes have an empty Name
+type xmlNode struct {
+	Name     string
+	Text     string
+	Children []*xmlNode
+}
+
+// child returns the first direct child with the given name
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// parseXMLTree reads an XML document into a tree of xmlNodes, dropping
+// namespaces and attributes
+func parseXMLTree(r io.Reader) (*xmlNode, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.Children = append(parent.Children, &xmlNode{Text: string(t)})
+		}
+	}
+
+	for _, c := range root.Children {
+		if c.Name != "" {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("empty XML document")
+}
+
+// xmlBlockElements start a new line when rendering text
+var xmlBlockElements = map[string]bool{
+	// Formex
+	"P": true, "ALINEA": true, "ITEM": true, "NP": true, "TI.ART": true, "STI.ART": true, "PARAG": true,
+	// Akoma Ntoso
+	"p": true, "intro": true, "point": true, "paragraph": true, "heading": true, "wrapUp": true,
+}
+
+// lineBreakReplacer flattens line breaks in character data, which are only
+// source formatting
+var lineBreakReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+// xmlNumberElements hold paragraph and point labels such as "1." or "(a)"
+var xmlNumberElements = map[string]bool{
+	"NO.PARAG": true, "NO.P": true, "num": true,
+}
+
+// xmlText renders the text content of a node, one line per block element.
+// Labels stay on the same line as the text they number.
+func xmlText(n *xmlNode) string {
+	var sb strings.Builder
+	afterLabel := false
+	var walk func(*xmlNode)
+	walk = func(n *xmlNode) {
+		if n.Name == "" {
+			sb.WriteString(lineBreakReplacer.Replace(n.Text))
+			if strings.TrimSpace(n.Text) != "" {
+				afterLabel = false
+			}
+			return
+		}
+		if xmlBlockElements[n.Name] && !afterLabel {
+			sb.WriteString("\n")
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+		if xmlNumberElements[n.Name] {
+			sb.WriteString(" ")
+			afterLabel = true
+		}
+	}
+	walk(n)
+
+	var lines []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	divisionRe = regexp.MustCompile(`(?i)^(chapter|section)\s+([0-9IVXLC]+)`)
+	numberRe   = regexp.MustCompile(`[0-9]+`)
+)
+
+// parseLegalXML parses a Formex 4 or Akoma Ntoso document into sections:
+// one per recital and one per article paragraph, tagged with their chapter,
+// section, article, paragraph or recital number
+func parseLegalXML(r io.Reader) ([]Section, error) {
+	root, err := parseXMLTree(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &legalXMLParser{context: map[string]string{}}
+	if root.Name == "akomaNtoso" {
+		p.walkAkomaNtoso(root)
+	} else {
+		p.walkFormex(root)
+	}
+
+	if len(p.sections) == 0 {
+		return nil, fmt.Errorf("no recitals or articles found in %s document", root.Name)
+	}
+	return p.sections, nil
+}
+
+type legalXMLParser struct {
+	sections []Section
+	context  map[string]string // enclosing chapter and section, with their titles
+}
+
+// withDivision records a chapter or section heading and title for nested
+// provisions and returns a function restoring the previous context
+func (p *legalXMLParser) withDivision(heading, title string) func() {
+	saved := make(map[string]string, len(p.context))
+	for k, v := range p.context {
+		saved[k] = v
+	}
+	if m := divisionRe.FindStringSubmatch(strings.TrimSpace(heading)); m != nil {
+		key, titleKey := MetaChapter, MetaChapterTitle
+		if strings.EqualFold(m[1], "section") {
+			key, titleKey = MetaSection, MetaSectionTitle
+		} else {
+			delete(p.context, MetaSection)
+			delete(p.context, MetaSectionTitle)
+		}
+		p.context[key] = strings.ToUpper(m[2])
+		delete(p.context, titleKey)
+		if title = strings.TrimSpace(title); title != "" {
+			p.context[titleKey] = title
+		}
+	}
+	return func() { p.context = saved }
+}
+
+func (p *legalXMLParser) add(text string, metadata map[string]string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	for k, v := range p.context {
+		if _, ok := metadata[k]; !ok {
+			metadata[k] = v
+		}
+	}
+	p.sections = append(p.sections, Section{Text: text, Metadata: metadata})
+}
+
+func (p *legalXMLParser) walkFormex(n *xmlNode) {
+	switch n.Name {
+	case "CONSID":
+		num := ""
+		if np := n.child("NP"); np != nil {
+			if no := np.child("NO.P"); no != nil {
+				num = numberRe.FindString(xmlText(no))
+			}
+		}
+		p.add(xmlText(n), map[string]string{MetaRecital: num})
+		return
+	case "DIVISION":
+		heading, subtitle := "", ""
+		if title := n.child("TITLE"); title != nil {
+			if ti := title.child("TI"); ti != nil {
+				heading = xmlText(ti)
+			}
+			if sti := title.child("STI"); sti != nil {
+				subtitle = xmlText(sti)
+			}
+		}
+		defer p.withDivision(heading, subtitle)()
+	case "ARTICLE":
+		p.addArticle(n, "TI.ART", "STI.ART", "PARAG", "NO.PARAG")
+		return
+	case "ANNEX":
+		heading := ""
+		if title := n.child("TITLE"); title != nil {
+			heading = xmlText(title)
+		}
+		p.add(xmlText(n), map[string]string{MetaAnnex: annexNumber(heading)})
+		return
+	}
+	for _, c := range n.Children {
+		p.walkFormex(c)
+	}
+}
+
+func (p *legalXMLParser) walkAkomaNtoso(n *xmlNode) {
+	switch n.Name {
+	case "recital":
+		num := ""
+		if no := n.child("num"); no != nil {
+			num = numberRe.FindString(xmlText(no))
+		}
+		p.add(xmlText(n), map[string]string{MetaRecital: num})
+		return
+	case "chapter", "section":
+		heading, title := "", ""
+		if num := n.child("num"); num != nil {
+			heading = xmlText(num)
+		}
+		if h := n.child("heading"); h != nil {
+			title = xmlText(h)
+		}
+		defer p.withDivision(heading, title)()
+	case "article":
+		p.addArticle(n, "num", "heading", "paragraph", "num")
+		return
+	case "attachment", "annex":
+		heading := ""
+		if num := n.child("num"); num != nil {
+			heading = xmlText(num)
+		}
+		p.add(xmlText(n), map[string]string{MetaAnnex: annexNumber(heading)})
+		return
+	}
+	for _, c := range n.Children {
+		p.walkAkomaNtoso(c)
+	}
+}
+
+// addArticle adds one section per paragraph of an article (or one for the
+// whole article when it has no numbered paragraphs). The article heading
+// is kept with the first section so the paragraphs read in order.
+func (p *legalXMLParser) addArticle(n *xmlNode, numTag, titleTag, paragTag, paragNumTag string) {
+	heading := ""
+	article := ""
+	if num := n.child(numTag); num != nil {
+		heading = xmlText(num)
+		article = numberRe.FindString(heading)
+	}
+	title := ""
+	if t := n.child(titleTag); t != nil {
+		title = xmlText(t)
+		heading += "\n" + title
+	}
+
+	meta := func(paragraph string) map[string]string {
+		m := map[string]string{MetaArticle: article}
+		if title != "" {
+			m[MetaArticleTitle] = title
+		}
+		if paragraph != "" {
+			m[MetaParagraph] = paragraph
+		}
+		return m
+	}
+
+	var paragraphs []*xmlNode
+	for _, c := range n.Children {
+		if c.Name == paragTag {
+			paragraphs = append(paragraphs, c)
+		}
+	}
+
+	if len(paragraphs) == 0 {
+		var body []string
+		for _, c := range n.Children {
+			if c.Name != numTag && c.Name != titleTag {
+				if text := xmlText(c); text != "" {
+					body = append(body, text)
+				}
+			}
+		}
+		p.add(strings.TrimSpace(heading+"\n"+strings.Join(body, "\n")), meta(""))
+		return
+	}
+
+	for i, para := range paragraphs {
+		num := ""
+		if no := para.child(paragNumTag); no != nil {
+			num = numberRe.FindString(xmlText(no))
+		}
+		text := xmlText(para)
+		if i == 0 {
+			text = heading + "\n" + text
+		}
+		p.add(strings.TrimSpace(text), meta(num))
+	}
+}
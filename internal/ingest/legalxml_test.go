@@ -0,0 +1,221 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+const testFormex = `<?xml version="1.0" encoding="UTF-8"?>
+<ACT xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <PREAMBLE>
+    <GR.CONSID>
+      <CONSID><NP><NO.P>(1)</NO.P><TXT>The protection of natural persons is a fundamental right.</TXT></NP></CONSID>
+      <CONSID><NP><NO.P>(2)</NO.P><TXT>The principles should respect their fundamental rights.</TXT></NP></CONSID>
+    </GR.CONSID>
+  </PREAMBLE>
+  <ENACTING.TERMS>
+    <DIVISION>
+      <TITLE><TI><P>CHAPTER I</P></TI><STI><P>General provisions</P></STI></TITLE>
+      <ARTICLE IDENTIFIER="001">
+        <TI.ART>Article 1</TI.ART>
+        <STI.ART>Subject-matter and objectives</STI.ART>
+        <PARAG IDENTIFIER="001.001"><NO.PARAG>1.</NO.PARAG><ALINEA>This Regulation lays down rules.</ALINEA></PARAG>
+        <PARAG IDENTIFIER="001.002"><NO.PARAG>2.</NO.PARAG><ALINEA>This Regulation protects fundamental rights.</ALINEA></PARAG>
+      </ARTICLE>
+    </DIVISION>
+    <DIVISION>
+      <TITLE><TI><P>CHAPTER III</P></TI><STI><P>Rights of the data subject</P></STI></TITLE>
+      <DIVISION>
+        <TITLE><TI><P>Section 3</P></TI><STI><P>Rectification and erasure</P></STI></TITLE>
+        <ARTICLE IDENTIFIER="017">
+          <TI.ART>Article 17</TI.ART>
+          <STI.ART>Right to erasure (‘right to be forgotten’)</STI.ART>
+          <PARAG IDENTIFIER="017.001">
+            <NO.PARAG>1.</NO.PARAG>
+            <ALINEA>
+              <P>The data subject shall have the right to obtain erasure where:</P>
+              <LIST TYPE="alpha">
+                <ITEM><NP><NO.P>(a)</NO.P><TXT>the personal data are no longer necessary;</TXT></NP></ITEM>
+                <ITEM><NP><NO.P>(b)</NO.P><TXT>the data subject withdraws consent.</TXT></NP></ITEM>
+              </LIST>
+            </ALINEA>
+          </PARAG>
+        </ARTICLE>
+      </DIVISION>
+    </DIVISION>
+    <DIVISION>
+      <TITLE><TI><P>CHAPTER XI</P></TI><STI><P>Final provisions</P></STI></TITLE>
+      <ARTICLE IDENTIFIER="099">
+        <TI.ART>Article 99</TI.ART>
+        <STI.ART>Entry into force and application</STI.ART>
+        <ALINEA>It shall apply from 25 May 2018.</ALINEA>
+      </ARTICLE>
+    </DIVISION>
+  </ENACTING.TERMS>
+</ACT>`
+
+const testAkomaNtoso = `<?xml version="1.0" encoding="UTF-8"?>
+<akomaNtoso xmlns="http://docs.oasis-open.org/legaldocml/ns/akn/3.0">
+  <act>
+    <preamble>
+      <recitals>
+        <recital eId="rec_1"><num>(1)</num><p>The protection of natural persons is a fundamental right.</p></recital>
+      </recitals>
+    </preamble>
+    <body>
+      <chapter eId="chp_III">
+        <num>CHAPTER III</num>
+        <heading>Rights of the data subject</heading>
+        <section eId="chp_III__sec_3">
+          <num>Section 3</num>
+          <article eId="art_17">
+            <num>Article 17</num>
+            <heading>Right to erasure</heading>
+            <paragraph eId="art_17__para_1">
+              <num>1.</num>
+              <list>
+                <intro><p>The data subject shall have the right to obtain erasure where:</p></intro>
+                <point eId="art_17__para_1__point_a"><num>(a)</num><content><p>the personal data are no longer necessary;</p></content></point>
+              </list>
+            </paragraph>
+            <paragraph eId="art_17__para_2">
+              <num>2.</num>
+              <content><p>Where the controller has made the personal data public, it shall inform other controllers.</p></content>
+            </paragraph>
+          </article>
+        </section>
+      </chapter>
+    </body>
+  </act>
+</akomaNtoso>`
+
+func TestParseFormex(t *testing.T) {
+	sections, err := parseLegalXML(strings.NewReader(testFormex))
+	if err != nil {
+		t.Fatalf("parseLegalXML failed: %v", err)
+	}
+
+	expected := []map[string]string{
+		{MetaRecital: "1"},
+		{MetaRecital: "2"},
+		{MetaChapter: "I", MetaChapterTitle: "General provisions", MetaArticle: "1", MetaArticleTitle: "Subject-matter and objectives", MetaParagraph: "1"},
+		{MetaChapter: "I", MetaChapterTitle: "General provisions", MetaArticle: "1", MetaArticleTitle: "Subject-matter and objectives", MetaParagraph: "2"},
+		{MetaChapter: "III", MetaChapterTitle: "Rights of the data subject", MetaSection: "3", MetaSectionTitle: "Rectification and erasure", MetaArticle: "17", MetaArticleTitle: "Right to erasure (‘right to be forgotten’)", MetaParagraph: "1"},
+		{MetaChapter: "XI", MetaChapterTitle: "Final provisions", MetaArticle: "99", MetaArticleTitle: "Entry into force and application"},
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("Expected %d sections, got %d: %+v", len(expected), len(sections), sections)
+	}
+	for i, want := range expected {
+		got := sections[i].Metadata
+		if len(got) != len(want) {
+			t.Errorf("Section %d metadata = %v, want %v", i, got, want)
+			continue
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("Section %d %s = %q, want %q", i, k, got[k], v)
+			}
+		}
+	}
+
+	if sections[0].Text != "(1) The protection of natural persons is a fundamental right." {
+		t.Errorf("Unexpected recital text: %q", sections[0].Text)
+	}
+	if !strings.HasPrefix(sections[2].Text, "Article 1\nSubject-matter and objectives\n1. This Regulation") {
+		t.Errorf("First paragraph should carry the article heading: %q", sections[2].Text)
+	}
+	if strings.HasPrefix(sections[3].Text, "Article 1") {
+		t.Errorf("Later paragraphs should not repeat the heading: %q", sections[3].Text)
+	}
+
+	want := "(a) the personal data are no longer necessary;\n(b) the data subject withdraws consent."
+	if !strings.HasSuffix(sections[4].Text, want) {
+		t.Errorf("Points should keep their labels on one line: %q", sections[4].Text)
+	}
+}
+
+func TestParseAkomaNtoso(t *testing.T) {
+	sections, err := parseLegalXML(strings.NewReader(testAkomaNtoso))
+	if err != nil {
+		t.Fatalf("parseLegalXML failed: %v", err)
+	}
+	if len(sections) != 3 {
+		t.Fatalf("Expected 3 sections, got %d: %+v", len(sections), sections)
+	}
+
+	if sections[0].Metadata[MetaRecital] != "1" {
+		t.Errorf("Unexpected recital metadata: %v", sections[0].Metadata)
+	}
+	for i, para := range []string{"1", "2"} {
+		meta := sections[i+1].Metadata
+		if meta[MetaArticle] != "17" || meta[MetaParagraph] != para || meta[MetaChapter] != "III" || meta[MetaSection] != "3" ||
+			meta[MetaChapterTitle] != "Rights of the data subject" {
+			t.Errorf("Unexpected article metadata: %v", meta)
+		}
+	}
+
+	want := "Article 17\nRight to erasure\n1. The data subject shall have the right to obtain erasure where:\n(a) the personal data are no longer necessary;"
+	if sections[1].Text != want {
+		t.Errorf("Unexpected paragraph text:\n%s", sections[1].Text)
+	}
+}
+
+func TestParseFormexAnnex(t *testing.T) {
+	doc := `<ACT><ENACTING.TERMS><ARTICLE><TI.ART>Article 1</TI.ART><ALINEA>See the Annex.</ALINEA></ARTICLE></ENACTING.TERMS>` +
+		`<ANNEX><TITLE><TI><P>ANNEX II</P></TI></TITLE><CONTENTS><P>Standard contractual clauses</P></CONTENTS></ANNEX></ACT>`
+	sections, err := parseLegalXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("parseLegalXML failed: %v", err)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %+v", sections)
+	}
+	if sections[1].Metadata[MetaAnnex] != "II" || !strings.Contains(sections[1].Text, "Standard contractual clauses") {
+		t.Errorf("Unexpected annex section %+v", sections[1])
+	}
+}
+
+func TestParseLegalXMLRejectsUnknownDocuments(t *testing.T) {
+	if _, err := parseLegalXML(strings.NewReader("<note><body>hello</body></note>")); err == nil {
+		t.Error("Expected error for XML without recitals or articles")
+	}
+	if _, err := parseLegalXML(strings.NewReader("")); err == nil {
+		t.Error("Expected error for empty input")
+	}
+}
+
+func TestIngestFormexFile(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "gdpr.xml")
+	if err := os.WriteFile(path, []byte(testFormex), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	ingester := New(database, DefaultConfig())
+	if err := ingester.IngestFile(path); err != nil {
+		t.Fatalf("IngestFile failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("withdraws consent", 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc.Metadata[MetaArticle] != "17" || doc.Metadata[MetaParagraph] != "1" {
+		t.Errorf("Unexpected metadata: %v", doc.Metadata)
+	}
+}
@@ -0,0 +1,189 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RerankerLLM asks a chat model to judge the relevance of each candidate
+const RerankerLLM = "llm"
+
+// Defaults of the LLM reranker
+const (
+	DefaultChatURL   = "https://api.openai.com/v1"
+	DefaultChatModel = "gpt-4o-mini"
+
+	// DefaultLLMRerankCandidates caps how many candidates are sent to the
+	// chat model per search, bounding latency and cost
+	DefaultLLMRerankCandidates = 10
+	// DefaultLLMRerankCacheSize is how many judgments are kept in memory
+	DefaultLLMRerankCacheSize = 1024
+)
+
+// llmPassageChars is how much of each candidate the chat model reads
+const llmPassageChars = 1200
+
+const llmRerankPrompt = `You judge search results for questions about the GDPR and related data protection law.
+Rate how relevant each numbered passage is to the query, from 0 (irrelevant) to 10 (directly answers it).
+Judge by legal substance, not by shared words. Reply with JSON only, in the form
+{"scores": [{"id": 1, "score": 7}, {"id": 2, "score": 0}]}
+with one entry per passage.`
+
+// llmReranker scores candidates with a chat model through an OpenAI
+// compatible chat completions API. Only the first maxCandidates documents are
+// judged; the rest score 0 and so stay behind them. Judgments are cached by
+// query and passage.
+type llmReranker struct {
+	endpoint      EndpointConfig
+	maxCandidates int
+	cacheSize     int
+
+	mu    sync.Mutex
+	cache map[string]float64
+	order []string // cache keys, oldest first
+}
+
+func newLLMReranker(config RerankerConfig) (*llmReranker, error) {
+	endpoint, err := chatEndpoint(config.Endpoint, "LLM reranking")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &llmReranker{
+		endpoint:      endpoint,
+		maxCandidates: config.MaxCandidates,
+		cacheSize:     config.CacheSize,
+		cache:         make(map[string]float64),
+	}
+	if r.maxCandidates <= 0 {
+		r.maxCandidates = DefaultLLMRerankCandidates
+	}
+	if r.cacheSize <= 0 {
+		r.cacheSize = DefaultLLMRerankCacheSize
+	}
+	return r, nil
+}
+
+// Rerank scores documents by the chat model's relevance judgments, scaled
+// to 0..1
+func (r *llmReranker) Rerank(query string, documents []string) ([]float64, error) {
+	scores := make([]float64, len(documents))
+	judged := documents
+	if len(judged) > r.maxCandidates {
+		judged = judged[:r.maxCandidates]
+	}
+
+	// Only passages not judged before for this query are sent
+	keys := make([]string, len(judged))
+	var pending []int
+	r.mu.Lock()
+	for i, document := range judged {
+		keys[i] = r.cacheKey(query, passage(document))
+		if score, ok := r.cache[keys[i]]; ok {
+			scores[i] = score
+		} else {
+			pending = append(pending, i)
+		}
+	}
+	r.mu.Unlock()
+	if len(pending) == 0 {
+		return scores, nil
+	}
+
+	passages := make([]string, len(pending))
+	for j, i := range pending {
+		passages[j] = passage(judged[i])
+	}
+	judgments, err := r.judge(query, passages)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for j, i := range pending {
+		scores[i] = judgments[j]
+		r.remember(keys[i], judgments[j])
+	}
+	return scores, nil
+}
+
+// judge asks the chat model to rate passages and returns their scores
+func (r *llmReranker) judge(query string, passages []string) ([]float64, error) {
+	var user strings.Builder
+	fmt.Fprintf(&user, "Query: %s\n", query)
+	for i, p := range passages {
+		fmt.Fprintf(&user, "\n[%d]\n%s\n", i+1, p)
+	}
+
+	content, err := chatCompletion(r.endpoint, llmRerankPrompt, user.String())
+	if err != nil {
+		return nil, err
+	}
+	return parseJudgments(content, len(passages))
+}
+
+// parseJudgments reads the scores from the model's reply, tolerating text
+// or a code fence around the JSON. Passages the model skipped score 0.
+func parseJudgments(content string, n int) ([]float64, error) {
+	object, err := jsonObject(content)
+	if err != nil {
+		return nil, err
+	}
+	var reply struct {
+		Scores []struct {
+			ID    int     `json:"id"`
+			Score float64 `json:"score"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal(object, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse relevance judgments: %w", err)
+	}
+
+	scores := make([]float64, n)
+	for _, s := range reply.Scores {
+		if s.ID < 1 || s.ID > n {
+			continue
+		}
+		score := s.Score / 10
+		if score < 0 {
+			score = 0
+		}
+		if score > 1 {
+			score = 1
+		}
+		scores[s.ID-1] = score
+	}
+	return scores, nil
+}
+
+// passage trims a candidate to the part the chat model reads
+func passage(document string) string {
+	document = strings.TrimSpace(document)
+	if runes := []rune(document); len(runes) > llmPassageChars {
+		return string(runes[:llmPassageChars]) + "…"
+	}
+	return document
+}
+
+func (r *llmReranker) cacheKey(query, passage string) string {
+	sum := sha256.Sum256([]byte(r.endpoint.Model + "\x00" + strings.ToLower(strings.TrimSpace(query)) + "\x00" + passage))
+	return hex.EncodeToString(sum[:])
+}
+
+// remember caches a judgment, evicting the oldest ones beyond the cache size.
+// The caller holds r.mu.
+func (r *llmReranker) remember(key string, score float64) {
+	if _, ok := r.cache[key]; !ok {
+		r.order = append(r.order, key)
+	}
+	r.cache[key] = score
+	for len(r.order) > r.cacheSize {
+		delete(r.cache, r.order[0])
+		r.order = r.order[1:]
+	}
+}
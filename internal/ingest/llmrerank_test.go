@@ -0,0 +1,114 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLLMReranker(t *testing.T) {
+	var requests int
+	var lastPrompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		requests++
+		var req struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		lastPrompt = req.Messages[len(req.Messages)-1].Content
+
+		// Rate passages mentioning erasure highly, in a code fence
+		var scores []string
+		for i, part := range strings.Split(lastPrompt, "\n[")[1:] {
+			score := 1
+			if strings.Contains(strings.ToLower(part), "erasure") {
+				score = 9
+			}
+			scores = append(scores, fmt.Sprintf(`{"id": %d, "score": %d}`, i+1, score))
+		}
+		content := "```json\n{\"scores\": [" + strings.Join(scores, ", ") + "]}\n```"
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	defer srv.Close()
+
+	reranker, err := NewReranker(RerankerLLM, RerankerConfig{
+		Endpoint:      EndpointConfig{URL: srv.URL + "/v1", AuthHeader: "key"},
+		MaxCandidates: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewReranker failed: %v", err)
+	}
+
+	documents := []string{"Right of access", "Right to erasure", "Data portability", "Erasure without undue delay"}
+	scores, err := reranker.Rerank("when must data be deleted?", documents)
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	// The fourth candidate is beyond the cap and never sent
+	if !reflect.DeepEqual(scores, []float64{0.1, 0.9, 0.1, 0}) {
+		t.Errorf("Unexpected scores %v", scores)
+	}
+	if strings.Contains(lastPrompt, "undue delay") || !strings.Contains(lastPrompt, "Query: when must data be deleted?") {
+		t.Errorf("Unexpected prompt %q", lastPrompt)
+	}
+
+	// Repeated passages come from the cache; only the new one is sent
+	scores, err = reranker.Rerank("when must data be deleted?", []string{"Right to erasure", "Erasure on request", "Right of access"})
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if !reflect.DeepEqual(scores, []float64{0.9, 0.9, 0.1}) || requests != 2 {
+		t.Errorf("Expected one more request, got %d requests and scores %v", requests, scores)
+	}
+	if strings.Contains(lastPrompt, "Right of access") {
+		t.Errorf("Expected cached passages not to be sent again, got %q", lastPrompt)
+	}
+	if _, err := reranker.Rerank("when must data be deleted?", documents[:2]); err != nil || requests != 2 {
+		t.Errorf("Expected a fully cached rerank without request, got %d requests, %v", requests, err)
+	}
+}
+
+func TestParseJudgments(t *testing.T) {
+	scores, err := parseJudgments(`Sure! {"scores": [{"id": 2, "score": 12}, {"id": 1, "score": -3}, {"id": 7, "score": 5}]}`, 3)
+	if err != nil {
+		t.Fatalf("parseJudgments failed: %v", err)
+	}
+	if !reflect.DeepEqual(scores, []float64{0, 1, 0}) {
+		t.Errorf("Expected clamped scores and skipped passages at 0, got %v", scores)
+	}
+	if _, err := parseJudgments("I cannot help with that.", 2); err == nil {
+		t.Error("Expected an error for a reply without JSON")
+	}
+}
+
+func TestLLMRerankerNeedsKey(t *testing.T) {
+	if _, err := NewReranker(RerankerLLM, RerankerConfig{}); err == nil {
+		t.Error("Expected OpenAI reranking without an API key to be rejected")
+	}
+}
+
+func TestLLMRerankerCacheEviction(t *testing.T) {
+	r, err := newLLMReranker(RerankerConfig{Endpoint: EndpointConfig{URL: "http://localhost"}, CacheSize: 2})
+	if err != nil {
+		t.Fatalf("newLLMReranker failed: %v", err)
+	}
+	r.remember("a", 0.1)
+	r.remember("b", 0.2)
+	r.remember("c", 0.3)
+	if _, ok := r.cache["a"]; ok || len(r.cache) != 2 {
+		t.Errorf("Expected the oldest judgment to be evicted, got %v", r.cache)
+	}
+}
@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"strings"
+)
+
+// headingPathSeparator joins the titles of nested Markdown headings
+const headingPathSeparator = " > "
+
+// splitMarkdown splits a Markdown document into one section per heading.
+// Each section keeps its heading line and is tagged with the heading path
+// (e.g. "Guidelines > Consent > Withdrawal") in the "heading_path" metadata
+// key. Headings inside fenced code blocks are ignored.
+func splitMarkdown(content string) []Section {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	var sections []Section
+	var path []string // titles of the currently open headings, by level
+	var body []string
+	inFence := false
+	fence := ""
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(body, "\n"))
+		body = body[:0]
+		if text == "" {
+			return
+		}
+		section := Section{Text: text}
+		if p := strings.Join(nonEmpty(path), headingPathSeparator); p != "" {
+			section.Metadata = map[string]string{"heading_path": p}
+		}
+		sections = append(sections, section)
+	}
+
+	openHeading := func(level int, title string) {
+		flush()
+		for len(path) < level {
+			path = append(path, "")
+		}
+		path = append(path[:level-1], title)
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+			} else if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			body = append(body, line)
+			continue
+		}
+		if inFence {
+			body = append(body, line)
+			continue
+		}
+
+		if level, title, ok := atxHeading(line); ok {
+			openHeading(level, title)
+			body = append(body, line)
+			continue
+		}
+
+		// Setext headings underline a single line of text
+		prevBlank := len(body) == 0 || strings.TrimSpace(body[len(body)-1]) == ""
+		if trimmed != "" && prevBlank && i+1 < len(lines) {
+			if level := setextLevel(lines[i+1]); level > 0 {
+				openHeading(level, trimmed)
+				body = append(body, line, lines[i+1])
+				i++
+				continue
+			}
+		}
+
+		body = append(body, line)
+	}
+	flush()
+
+	return sections
+}
+
+// atxHeading parses "## Title" style headings
+func atxHeading(line string) (int, string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return 0, "", false
+	}
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	rest := trimmed[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#"))
+	return level, title, true
+}
+
+// setextLevel returns 1 for "===" and 2 for "---" underlines, 0 otherwise
+func setextLevel(line string) int {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) < 3 {
+		return 0
+	}
+	switch {
+	case strings.Trim(trimmed, "=") == "":
+		return 1
+	case strings.Trim(trimmed, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+// fenceMarker returns the fence characters opening or closing a code block
+func fenceMarker(trimmed string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+func nonEmpty(items []string) []string {
+	var out []string
+	for _, item := range items {
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
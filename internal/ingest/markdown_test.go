@@ -0,0 +1,114 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestSplitMarkdown(t *testing.T) {
+	content := `Preamble text.
+
+# Guidelines on consent
+
+Intro paragraph.
+
+## Withdrawal
+
+Consent can be withdrawn at any time.
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+## Children
+
+Parental consent.
+
+Annex
+=====
+
+Setext section.
+`
+
+	sections := splitMarkdown(content)
+
+	expected := []struct {
+		path  string
+		first string
+	}{
+		{"", "Preamble text."},
+		{"Guidelines on consent", "# Guidelines on consent"},
+		{"Guidelines on consent > Withdrawal", "## Withdrawal"},
+		{"Guidelines on consent > Children", "## Children"},
+		{"Annex", "Annex"},
+	}
+
+	if len(sections) != len(expected) {
+		t.Fatalf("Expected %d sections, got %d: %+v", len(expected), len(sections), sections)
+	}
+	for i, e := range expected {
+		if got := sections[i].Metadata["heading_path"]; got != e.path {
+			t.Errorf("Section %d heading_path = %q, want %q", i, got, e.path)
+		}
+		if got := sections[i].Text[:len(e.first)]; got != e.first {
+			t.Errorf("Section %d starts with %q, want %q", i, got, e.first)
+		}
+	}
+}
+
+func TestAtxHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		title string
+		ok    bool
+	}{
+		{"# Title", 1, "Title", true},
+		{"### Closed ###", 3, "Closed", true},
+		{"#NoSpace", 0, "", false},
+		{"####### Too deep", 0, "", false},
+		{"    # Indented code", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		level, title, ok := atxHeading(tt.line)
+		if level != tt.level || title != tt.title || ok != tt.ok {
+			t.Errorf("atxHeading(%q) = %d, %q, %v", tt.line, level, title, ok)
+		}
+	}
+}
+
+func TestIngestMarkdownFile(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "policy.md")
+	content := "# Retention policy\n\n## Email\n\nEmails are deleted after two years.\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	ingester := New(database, DefaultConfig())
+	if err := ingester.IngestFile(path); err != nil {
+		t.Fatalf("IngestFile failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("emails deleted", 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc.Metadata["heading_path"] != "Retention policy > Email" {
+		t.Errorf("Unexpected heading path: %v", doc.Metadata)
+	}
+}
@@ -0,0 +1,158 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetaOCR marks chunks of PDF pages whose text was recognized by OCR
+const MetaOCR = "ocr"
+
+// DefaultOCRTimeout bounds the OCR of a single page
+const DefaultOCRTimeout = 2 * time.Minute
+
+// pageOCR returns the text of a 1-based PDF page recognized by OCR
+type pageOCR func(page int) (string, error)
+
+// ocrCommand returns an OCR function running the configured external engine
+// on the PDF at path, or nil when OCR is disabled. The command is split into
+// arguments like a shell would, without running one; "{file}" and "{page}"
+// in the arguments are replaced by the PDF path and page number, and the
+// engine writes the recognized text to standard output. A pipeline such as
+// pdftoppm into tesseract needs "sh -c" or a wrapper script.
+func (ing *Ingester) ocrCommand(path string) (pageOCR, error) {
+	if ing.config.OCRCommand == "" {
+		return nil, nil
+	}
+	args, err := splitCommand(ing.config.OCRCommand)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCR command: %w", err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("invalid OCR command: empty")
+	}
+	timeout := ing.config.OCRTimeout
+	if timeout <= 0 {
+		timeout = DefaultOCRTimeout
+	}
+
+	return func(page int) (string, error) {
+		argv := make([]string, len(args))
+		for i, arg := range args {
+			arg = strings.ReplaceAll(arg, "{file}", path)
+			argv[i] = strings.ReplaceAll(arg, "{page}", strconv.Itoa(page))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("OCR of page %d timed out after %s", page, timeout)
+			}
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("OCR of page %d failed: %w: %s", page, err, msg)
+			}
+			return "", fmt.Errorf("OCR of page %d failed: %w", page, err)
+		}
+
+		text, _, err := toUTF8(stdout.Bytes(), "", ing.config.Language)
+		if err != nil {
+			return "", fmt.Errorf("OCR of page %d: %w", page, err)
+		}
+		return string(text), nil
+	}, nil
+}
+
+// ocrBytes is like ocrCommand for a PDF held in memory. The engine needs a
+// file, so the PDF is written to a temporary one; the returned cleanup
+// function removes it.
+func (ing *Ingester) ocrBytes(content []byte) (pageOCR, func(), error) {
+	if ing.config.OCRCommand == "" {
+		return nil, func() {}, nil
+	}
+
+	f, err := os.CreateTemp("", "gdpr-mcp-*.pdf")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	ocr, err := ing.ocrCommand(f.Name())
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return ocr, cleanup, nil
+}
+
+// splitCommand splits a command line into arguments at unquoted whitespace.
+// Single quotes keep everything literally; inside double quotes a backslash
+// escapes a double quote or another backslash.
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				arg.WriteRune(runes[i])
+			default:
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
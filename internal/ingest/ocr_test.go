@@ -0,0 +1,142 @@
+package ingest
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+	}{
+		{"tesseract {file} stdout", []string{"tesseract", "{file}", "stdout"}},
+		{"  ocr   --lang deu  ", []string{"ocr", "--lang", "deu"}},
+		{`sh -c 'pdftoppm -f {page} -l {page} {file} | tesseract - -'`, []string{"sh", "-c", "pdftoppm -f {page} -l {page} {file} | tesseract - -"}},
+		{`ocr "My Scans/{file}" x""y`, []string{"ocr", "My Scans/{file}", "xy"}},
+		{`echo "say \"hi\" \\ \n" ''`, []string{"echo", `say "hi" \ \n`, ""}},
+	}
+	for _, tt := range tests {
+		args, err := splitCommand(tt.command)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%q: expected %q, got %q", tt.command, tt.args, args)
+		}
+	}
+
+	if _, err := splitCommand(`ocr 'unterminated`); err == nil {
+		t.Error("Expected an error for an unterminated quote")
+	}
+}
+
+func TestParsePDFOCR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.pdf")
+	writeTestPDF(t, path, []string{"Right of access", "", ""})
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pages []int
+	ocr := func(page int) (string, error) {
+		pages = append(pages, page)
+		if page == 3 {
+			return "  \n", nil
+		}
+		return "Scanned decision of the supervisory authority", nil
+	}
+	sections, err := parsePDF(bytes.NewReader(content), int64(len(content)), ocr)
+	if err != nil {
+		t.Fatalf("parsePDF failed: %v", err)
+	}
+
+	// Only pages without text are recognized, and blank scans still skipped
+	if !reflect.DeepEqual(pages, []int{2, 3}) {
+		t.Errorf("Expected OCR of pages 2 and 3, got %v", pages)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections))
+	}
+	if sections[0].Metadata[MetaOCR] != "" {
+		t.Errorf("Extracted page should not be marked as OCR: %v", sections[0].Metadata)
+	}
+	if sections[1].Text != "Scanned decision of the supervisory authority" || sections[1].Metadata["page"] != "2" || sections[1].Metadata[MetaOCR] != "true" {
+		t.Errorf("Unexpected OCR section: %+v", sections[1])
+	}
+}
+
+func TestIngestPDFWithOCRCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "scan.pdf")
+	writeTestPDF(t, path, []string{"", ""})
+
+	// Without an OCR command a scanned PDF cannot be ingested
+	if err := New(database, DefaultConfig()).IngestFile(path); err == nil || !strings.Contains(err.Error(), "OCR") {
+		t.Fatalf("Expected an error suggesting OCR, got %v", err)
+	}
+
+	config := DefaultConfig()
+	config.OCRCommand = `sh -c 'echo "Fine imposed on page {page} of $0"' {file}`
+	if err := New(database, config).IngestFile(path); err != nil {
+		t.Fatalf("IngestFile failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("Fine imposed", 5, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		doc, err := database.GetDocument(r.ID)
+		if err != nil {
+			t.Fatalf("GetDocument failed: %v", err)
+		}
+		want := "Fine imposed on page " + doc.Metadata["page"] + " of " + path
+		if doc.Chunk != want || doc.Metadata[MetaOCR] != "true" {
+			t.Errorf("Expected %q marked as OCR, got %q %v", want, doc.Chunk, doc.Metadata)
+		}
+	}
+}
+
+func TestOCRCommandFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	path := filepath.Join(t.TempDir(), "scan.pdf")
+	writeTestPDF(t, path, []string{""})
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	config.OCRCommand = `sh -c 'echo "no language data" >&2; exit 1'`
+	ing := New(nil, config)
+	ocr, cleanup, err := ing.ocrBytes(content)
+	if err != nil {
+		t.Fatalf("ocrBytes failed: %v", err)
+	}
+	defer cleanup()
+
+	_, err = parsePDF(bytes.NewReader(content), int64(len(content)), ocr)
+	if err == nil || !strings.Contains(err.Error(), "OCR of page 1 failed") || !strings.Contains(err.Error(), "no language data") {
+		t.Errorf("Expected the OCR failure with its stderr, got %v", err)
+	}
+}
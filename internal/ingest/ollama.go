@@ -0,0 +1,56 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultOllamaURL is where a local Ollama server listens by default
+const DefaultOllamaURL = "http://localhost:11434"
+
+// DefaultOllamaModel is the embedding model pulled with `ollama pull nomic-embed-text`
+const DefaultOllamaModel = "nomic-embed-text"
+
+// ollamaURLFromEnv returns the Ollama server URL, honouring OLLAMA_HOST as
+// the ollama CLI does
+func ollamaURLFromEnv() string {
+	host := os.Getenv("OLLAMA_HOST")
+	if host == "" {
+		return DefaultOllamaURL
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return host
+}
+
+// ollamaEmbeddings calls the embed endpoint of an Ollama server, embedding
+// all texts in one request
+func ollamaEmbeddings(texts []string, baseURL, model string) ([][]float32, error) {
+	jsonBody, err := json.Marshal(map[string]interface{}{
+		"model": model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	body, err := postEmbeddingRequest(strings.TrimRight(baseURL, "/")+"/api/embed", jsonBody, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("Ollama: %w", err)
+	}
+
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings in response, got %d", len(texts), len(result.Embeddings))
+	}
+
+	return result.Embeddings, nil
+}
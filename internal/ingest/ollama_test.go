@@ -0,0 +1,92 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func newOllamaServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Model != DefaultOllamaModel {
+			http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+			return
+		}
+		embeddings := make([][]float32, len(req.Input))
+		for i, input := range req.Input {
+			embeddings[i] = []float32{float32(len(input)), 1, 0}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":      req.Model,
+			"embeddings": embeddings,
+		})
+	}))
+}
+
+func TestOllamaEmbedding(t *testing.T) {
+	srv := newOllamaServer(t)
+	defer srv.Close()
+
+	embeddings, err := ollamaEmbeddings([]string{"consent", "erasure!"}, srv.URL, DefaultOllamaModel)
+	if err != nil {
+		t.Fatalf("ollamaEmbeddings failed: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 7 || embeddings[1][0] != 8 {
+		t.Errorf("Unexpected embeddings: %v", embeddings)
+	}
+
+	if _, err := ollamaEmbeddings([]string{"consent"}, srv.URL, "missing-model"); err == nil {
+		t.Error("Expected error for unknown model")
+	}
+}
+
+func TestOllamaURLFromEnv(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "")
+	if got := ollamaURLFromEnv(); got != DefaultOllamaURL {
+		t.Errorf("Expected default URL, got %q", got)
+	}
+
+	t.Setenv("OLLAMA_HOST", "10.0.0.5:11434")
+	if got := ollamaURLFromEnv(); got != "http://10.0.0.5:11434" {
+		t.Errorf("Unexpected URL %q", got)
+	}
+}
+
+func TestIngestWithOllama(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := newOllamaServer(t)
+	defer srv.Close()
+
+	config := DefaultConfig()
+	config.Embedder = EmbedderOllama
+	config.OllamaURL = srv.URL
+	ingester := New(database, config)
+	if err := ingester.IngestText("Consent must be freely given."); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	results, err := database.SearchVectors([]float32{29, 1, 0}, 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchVectors failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Score < 0.999 {
+		t.Errorf("Expected the Ollama embedding to be stored, got %+v", results)
+	}
+}
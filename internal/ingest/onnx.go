@@ -0,0 +1,37 @@
+package ingest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Files expected in an ONNX model directory, as exported from a
+// sentence-transformers model such as all-MiniLM-L6-v2
+const (
+	ONNXModelFile = "model.onnx"
+	ONNXVocabFile = "vocab.txt"
+)
+
+// onnxMaxTokens is the input length sentence-transformer models are trained with
+const onnxMaxTokens = 256
+
+// DefaultONNXModelDir returns the directory the all-MiniLM-L6-v2 ONNX model
+// is looked up in when none is configured
+func DefaultONNXModelDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join("models", "all-MiniLM-L6-v2")
+	}
+	return filepath.Join(base, "gdpr-mcp", "models", "all-MiniLM-L6-v2")
+}
+
+// checkONNXModelDir verifies the model and vocabulary files are present
+func checkONNXModelDir(modelDir string) error {
+	for _, name := range []string{ONNXModelFile, ONNXVocabFile} {
+		if _, err := os.Stat(filepath.Join(modelDir, name)); err != nil {
+			return fmt.Errorf("ONNX model directory %s is missing %s: %w", modelDir, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !onnx
+
+package ingest
+
+import "fmt"
+
+// ONNXAvailable reports whether the binary was built with ONNX support
+const ONNXAvailable = false
+
+// onnxEmbedding is unavailable unless built with the onnx tag, which
+// needs cgo and the ONNX Runtime shared library
+func onnxEmbedding(text, modelDir, runtimePath string) ([]float32, error) {
+	return nil, fmt.Errorf("ONNX embeddings are not available: rebuild with -tags onnx")
+}
+
+// onnxRerankScore is unavailable unless built with the onnx tag
+func onnxRerankScore(query, document, modelDir, runtimePath string) (float64, error) {
+	return 0, fmt.Errorf("ONNX reranking is not available: rebuild with -tags onnx")
+}
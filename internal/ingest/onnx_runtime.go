@@ -0,0 +1,215 @@
+//go:build onnx
+
+package ingest
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ONNXAvailable reports whether the binary was built with ONNX support
+const ONNXAvailable = true
+
+// onnxModel is a loaded sentence-transformer model and its tokenizer
+type onnxModel struct {
+	mu         sync.Mutex // sessions are not safe for concurrent runs
+	session    *ort.DynamicAdvancedSession
+	tokenizer  *wordPieceTokenizer
+	inputNames []string
+}
+
+var (
+	onnxInitOnce sync.Once
+	onnxInitErr  error
+	onnxModelsMu sync.Mutex
+	onnxModels   = make(map[string]*onnxModel)
+)
+
+// initONNXRuntime loads the ONNX Runtime shared library once per process.
+// An empty runtimePath lets the system loader find it.
+func initONNXRuntime(runtimePath string) error {
+	onnxInitOnce.Do(func() {
+		if runtimePath != "" {
+			ort.SetSharedLibraryPath(runtimePath)
+		}
+		if err := ort.InitializeEnvironment(); err != nil {
+			onnxInitErr = fmt.Errorf("failed to initialize ONNX Runtime: %w", err)
+		}
+	})
+	return onnxInitErr
+}
+
+// loadONNXModel returns the model in modelDir, loading it on first use
+func loadONNXModel(modelDir, runtimePath string) (*onnxModel, error) {
+	onnxModelsMu.Lock()
+	defer onnxModelsMu.Unlock()
+
+	if m, ok := onnxModels[modelDir]; ok {
+		return m, nil
+	}
+	if err := checkONNXModelDir(modelDir); err != nil {
+		return nil, err
+	}
+	if err := initONNXRuntime(runtimePath); err != nil {
+		return nil, err
+	}
+
+	tokenizer, err := loadWordPiece(filepath.Join(modelDir, ONNXVocabFile))
+	if err != nil {
+		return nil, err
+	}
+
+	modelPath := filepath.Join(modelDir, ONNXModelFile)
+	inputs, outputs, err := ort.GetInputOutputInfo(modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect ONNX model: %w", err)
+	}
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("ONNX model has no outputs")
+	}
+
+	// Some exports drop token_type_ids; feed only what the model declares
+	var inputNames []string
+	for _, in := range inputs {
+		switch in.Name {
+		case "input_ids", "attention_mask", "token_type_ids":
+			inputNames = append(inputNames, in.Name)
+		default:
+			return nil, fmt.Errorf("unsupported ONNX model input %q", in.Name)
+		}
+	}
+
+	session, err := ort.NewDynamicAdvancedSession(modelPath, inputNames, []string{outputs[0].Name}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ONNX model: %w", err)
+	}
+
+	m := &onnxModel{session: session, tokenizer: tokenizer, inputNames: inputNames}
+	onnxModels[modelDir] = m
+	return m, nil
+}
+
+// onnxEmbedding embeds text with the sentence-transformer in modelDir:
+// the token embeddings are mean-pooled over the attention mask and
+// L2-normalized, as sentence-transformers does
+func onnxEmbedding(text, modelDir, runtimePath string) ([]float32, error) {
+	m, err := loadONNXModel(modelDir, runtimePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, mask := m.tokenizer.encode(text, onnxMaxTokens)
+	data, shape, err := m.run(ids, nil, mask)
+	if err != nil {
+		return nil, err
+	}
+	return poolEmbedding(data, shape, mask)
+}
+
+// onnxRerankScore scores a document against a query with the cross-encoder
+// in modelDir
+func onnxRerankScore(query, document, modelDir, runtimePath string) (float64, error) {
+	m, err := loadONNXModel(modelDir, runtimePath)
+	if err != nil {
+		return 0, err
+	}
+
+	ids, typeIDs, mask := m.tokenizer.encodePair(query, document, crossEncoderMaxTokens)
+	data, _, err := m.run(ids, typeIDs, mask)
+	if err != nil {
+		return 0, err
+	}
+	return relevanceFromLogits(data)
+}
+
+// run feeds one tokenized input to the model and returns a copy of its
+// output with the output shape. Nil typeIDs are all zero.
+func (m *onnxModel) run(ids, typeIDs, mask []int64) ([]float32, ort.Shape, error) {
+	shape := ort.NewShape(1, int64(len(ids)))
+	if typeIDs == nil {
+		typeIDs = make([]int64, len(ids))
+	}
+
+	var inputs []ort.Value
+	defer func() {
+		for _, in := range inputs {
+			in.Destroy()
+		}
+	}()
+	for _, name := range m.inputNames {
+		data := ids
+		switch name {
+		case "attention_mask":
+			data = mask
+		case "token_type_ids":
+			data = typeIDs
+		}
+		tensor, err := ort.NewTensor(shape, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create %s tensor: %w", name, err)
+		}
+		inputs = append(inputs, tensor)
+	}
+
+	outputs := []ort.Value{nil}
+	m.mu.Lock()
+	err := m.session.Run(inputs, outputs)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to run ONNX model: %w", err)
+	}
+	defer outputs[0].Destroy()
+
+	output, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected ONNX output type")
+	}
+	return append([]float32(nil), output.GetData()...), output.GetShape(), nil
+}
+
+// poolEmbedding turns model output into a normalized sentence embedding.
+// Token embeddings [1, tokens, dim] are mean-pooled over the attention
+// mask; an already pooled [1, dim] output is used as is.
+func poolEmbedding(data []float32, shape ort.Shape, mask []int64) ([]float32, error) {
+	var embedding []float32
+	switch len(shape) {
+	case 2:
+		embedding = append(embedding, data...)
+	case 3:
+		tokens, dim := int(shape[1]), int(shape[2])
+		embedding = make([]float32, dim)
+		var count float32
+		for t := 0; t < tokens && t < len(mask); t++ {
+			if mask[t] == 0 {
+				continue
+			}
+			count++
+			for d := 0; d < dim; d++ {
+				embedding[d] += data[t*dim+d]
+			}
+		}
+		if count > 0 {
+			for d := range embedding {
+				embedding[d] /= count
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected ONNX output shape %v", shape)
+	}
+
+	var norm float64
+	for _, v := range embedding {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range embedding {
+			embedding[i] *= scale
+		}
+	}
+	return embedding, nil
+}
@@ -0,0 +1,40 @@
+//go:build onnx
+
+package ingest
+
+import (
+	"math"
+	"testing"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+func TestPoolEmbedding(t *testing.T) {
+	// Two real tokens and one padding token with 2-dim embeddings
+	data := []float32{1, 0, 3, 0, 100, 100}
+	embedding, err := poolEmbedding(data, ort.NewShape(1, 3, 2), []int64{1, 1, 0})
+	if err != nil {
+		t.Fatalf("poolEmbedding failed: %v", err)
+	}
+	if len(embedding) != 2 || math.Abs(float64(embedding[0])-1) > 1e-6 || embedding[1] != 0 {
+		t.Errorf("Expected the masked mean normalized to [1 0], got %v", embedding)
+	}
+
+	embedding, err = poolEmbedding([]float32{3, 4}, ort.NewShape(1, 2), nil)
+	if err != nil {
+		t.Fatalf("poolEmbedding failed: %v", err)
+	}
+	if math.Abs(float64(embedding[0])-0.6) > 1e-6 || math.Abs(float64(embedding[1])-0.8) > 1e-6 {
+		t.Errorf("Expected a pooled output to be normalized, got %v", embedding)
+	}
+
+	if _, err := poolEmbedding(data, ort.NewShape(6), nil); err == nil {
+		t.Error("Expected error for unexpected output shape")
+	}
+}
+
+func TestONNXEmbeddingMissingModel(t *testing.T) {
+	if _, err := onnxEmbedding("consent", t.TempDir(), ""); err == nil {
+		t.Error("Expected error for a model directory without model files")
+	}
+}
@@ -0,0 +1,87 @@
+package ingest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// extractPDF reads a PDF file and returns one section per page with text,
+// tagged with its 1-based page number in the "page" metadata key. Pages
+// without extractable text, such as scans, are run through OCR when an OCR
+// command is configured.
+func (ing *Ingester) extractPDF(filePath string) ([]Section, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	ocr, err := ing.ocrCommand(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parsePDF(f, info.Size(), ocr)
+}
+
+// parsePDF extracts the pages of a PDF of the given size like extractPDF.
+// Pages without text are passed to ocr, unless it is nil, and their
+// sections are tagged with MetaOCR.
+func parsePDF(r io.ReaderAt, size int64, ocr pageOCR) ([]Section, error) {
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	var sections []Section
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract text from page %d: %w", i, err)
+		}
+		metadata := map[string]string{"page": strconv.Itoa(i)}
+		if strings.TrimSpace(text) == "" {
+			if ocr == nil {
+				continue
+			}
+			if text, err = ocr(i); err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			metadata[MetaOCR] = "true"
+		}
+
+		sections = append(sections, Section{Text: text, Metadata: metadata})
+	}
+
+	if len(sections) == 0 {
+		if ocr == nil {
+			return nil, fmt.Errorf("no extractable text in PDF; scanned documents need an OCR command")
+		}
+		return nil, fmt.Errorf("no extractable text in PDF, even with OCR")
+	}
+	return sections, nil
+}
@@ -0,0 +1,113 @@
+package ingest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// writeTestPDF writes a minimal PDF with one line of Helvetica text per page
+func writeTestPDF(t *testing.T, path string, pages []string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var offsets []int
+	addObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-3 are the catalog, page tree and font; pages follow
+	kids := ""
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", 4+2*i)
+	}
+	addObject("<< /Type /Catalog /Pages 2 0 R >>")
+	addObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(pages)))
+	addObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	for i, text := range pages {
+		addObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i))
+		stream := ""
+		if text != "" {
+			stream = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		}
+		addObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+}
+
+func TestExtractPDF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guidelines.pdf")
+	writeTestPDF(t, path, []string{"Right of access", "", "Right to erasure"})
+
+	sections, err := New(nil, DefaultConfig()).extractPDF(path)
+	if err != nil {
+		t.Fatalf("extractPDF failed: %v", err)
+	}
+
+	// The blank page is skipped but numbering follows the document
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections))
+	}
+	if sections[0].Text != "Right of access" || sections[0].Metadata["page"] != "1" {
+		t.Errorf("Unexpected first section: %+v", sections[0])
+	}
+	if sections[1].Text != "Right to erasure" || sections[1].Metadata["page"] != "3" {
+		t.Errorf("Unexpected second section: %+v", sections[1])
+	}
+}
+
+func TestIngestPDFStoresPageNumbers(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "decision.pdf")
+	writeTestPDF(t, path, []string{"Right of access", "Right to erasure"})
+
+	ingester := New(database, DefaultConfig())
+	if err := ingester.IngestFile(path); err != nil {
+		t.Fatalf("IngestFile failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("erasure", 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc.Metadata["page"] != "2" {
+		t.Errorf("Expected page 2 metadata, got %v", doc.Metadata)
+	}
+}
+
+func TestExtractPDFInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	if err := os.WriteFile(path, []byte("not a pdf"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := New(nil, DefaultConfig()).extractPDF(path); err == nil {
+		t.Error("Expected error for invalid PDF")
+	}
+}
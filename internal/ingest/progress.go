@@ -0,0 +1,166 @@
+package ingest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ProgressStage identifies what a Progress event reports
+type ProgressStage string
+
+// Stages of an ingestion, in the order they are reported
+const (
+	StageSkipped  ProgressStage = "skipped"  // the source was already fully ingested
+	StageCleaned  ProgressStage = "cleaned"  // Done chunks left by an interrupted run were removed
+	StageResumed  ProgressStage = "resumed"  // Done of Total chunks were stored by an earlier run
+	StageStarted  ProgressStage = "started"  // Total chunks are about to be ingested
+	StageChunks   ProgressStage = "chunks"   // Done of Total chunks are stored
+	StageFinished ProgressStage = "finished" // all Total chunks are stored
+	StageRemoved  ProgressStage = "removed"  // Done chunks of a deleted file were removed
+	StageRejected ProgressStage = "rejected" // a file in a directory is not text and was skipped
+)
+
+// Progress is an ingestion progress event
+type Progress struct {
+	Stage  ProgressStage
+	Source string // empty for content ingested without a source name
+	Done   int
+	Total  int
+
+	// EmbedTime is how long embedding the batch reported by a StageChunks
+	// event took
+	EmbedTime time.Duration
+}
+
+// ProgressReporter receives progress events during ingestion
+type ProgressReporter interface {
+	Report(p Progress)
+}
+
+// ProgressFunc adapts a function to a ProgressReporter
+type ProgressFunc func(p Progress)
+
+// Report calls f(p)
+func (f ProgressFunc) Report(p Progress) {
+	f(p)
+}
+
+// ChannelProgress returns a reporter sending every event to ch. Sends
+// block, so the receiver must keep up or use a buffered channel.
+func ChannelProgress(ch chan<- Progress) ProgressReporter {
+	return ProgressFunc(func(p Progress) { ch <- p })
+}
+
+// TextProgress returns a reporter writing one human-readable line per
+// event to w
+func TextProgress(w io.Writer) ProgressReporter {
+	return ProgressFunc(func(p Progress) {
+		switch p.Stage {
+		case StageSkipped:
+			fmt.Fprintf(w, "%s already ingested, skipping\n", p.Source)
+		case StageCleaned:
+			fmt.Fprintf(w, "Removed %d chunks left by an interrupted ingestion of %s\n", p.Done, p.Source)
+		case StageResumed:
+			fmt.Fprintf(w, "Resuming %s at chunk %d/%d\n", p.Source, p.Done+1, p.Total)
+		case StageStarted:
+			fmt.Fprintf(w, "Ingesting %d chunks...\n", p.Total)
+		case StageChunks:
+			fmt.Fprintf(w, "Processed %d/%d chunks\n", p.Done, p.Total)
+		case StageFinished:
+			fmt.Fprintf(w, "Successfully ingested %d chunks\n", p.Total)
+		case StageRemoved:
+			fmt.Fprintf(w, "%s was deleted, removed %d chunks\n", p.Source, p.Done)
+		case StageRejected:
+			fmt.Fprintf(w, "Warning: skipping %s, not a text file\n", p.Source)
+		}
+	})
+}
+
+// progressBarWidth is the number of cells of the bar drawn by ProgressBar
+const progressBarWidth = 30
+
+// progressBar redraws a single terminal line per event, see ProgressBar
+type progressBar struct {
+	w   io.Writer
+	now func() time.Time
+
+	start     time.Time // when the current source started
+	startDone int       // chunks already stored when it started, e.g. when resuming
+	embedTime time.Duration
+	batches   int
+	lineWidth int // length of the line last drawn, to blank leftovers
+}
+
+// ProgressBar returns a reporter drawing a progress bar on w, redrawn in
+// place with a carriage return: the chunks stored, the rate of ingestion,
+// the mean time of an embedding request and the estimated time left.
+// Events other than progress are written on lines of their own as by
+// TextProgress. It suits terminals; use TextProgress for logs.
+func ProgressBar(w io.Writer) ProgressReporter {
+	return &progressBar{w: w, now: time.Now}
+}
+
+// Report draws the event
+func (b *progressBar) Report(p Progress) {
+	switch p.Stage {
+	case StageStarted:
+		b.start, b.startDone, b.embedTime, b.batches = b.now(), p.Done, 0, 0
+		b.draw(p)
+	case StageChunks:
+		b.embedTime += p.EmbedTime
+		b.batches++
+		b.draw(p)
+	case StageFinished:
+		b.draw(p)
+		fmt.Fprintln(b.w)
+		b.lineWidth = 0
+	default:
+		TextProgress(b.w).Report(p)
+	}
+}
+
+// draw redraws the bar for p
+func (b *progressBar) draw(p Progress) {
+	filled := progressBarWidth
+	if p.Total > 0 {
+		filled = progressBarWidth * p.Done / p.Total
+	}
+	line := fmt.Sprintf("[%s%s] %d/%d chunks", strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), p.Done, p.Total)
+
+	elapsed := b.now().Sub(b.start)
+	if done := p.Done - b.startDone; done > 0 && elapsed > 0 {
+		rate := float64(done) / elapsed.Seconds()
+		line += fmt.Sprintf("  %.1f chunks/s", rate)
+		if b.batches > 0 {
+			line += fmt.Sprintf("  embed %s", (b.embedTime / time.Duration(b.batches)).Round(time.Millisecond))
+		}
+		if left := p.Total - p.Done; left > 0 {
+			eta := time.Duration(float64(left) / rate * float64(time.Second))
+			line += fmt.Sprintf("  ETA %s", eta.Round(time.Second))
+		}
+	}
+
+	pad := ""
+	if n := len(line); n < b.lineWidth {
+		pad = strings.Repeat(" ", b.lineWidth-n)
+	}
+	b.lineWidth = len(line)
+	fmt.Fprintf(b.w, "\r%s%s", line, pad)
+}
+
+// report passes an event to the configured reporter, if any
+func (ing *Ingester) report(stage ProgressStage, source string, done, total int) {
+	if ing.config.Progress != nil {
+		ing.config.Progress.Report(Progress{Stage: stage, Source: source, Done: done, Total: total})
+	}
+}
+
+// reportBatch reports that a batch was stored, its embeddings having taken
+// embedTime
+func (ing *Ingester) reportBatch(source string, done, total int, embedTime time.Duration) {
+	if ing.config.Progress != nil {
+		ing.config.Progress.Report(Progress{Stage: StageChunks, Source: source, Done: done, Total: total, EmbedTime: embedTime})
+	}
+}
@@ -0,0 +1,112 @@
+package ingest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIngestReportsProgress(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var events []Progress
+	config := DefaultConfig()
+	config.ChunkSize = 40
+	config.ChunkOverlap = 0
+	config.BatchSize = 2
+	config.Progress = ProgressFunc(func(p Progress) { events = append(events, p) })
+
+	text := "Processing shall be lawful. Consent shall be freely given. Data shall be minimised."
+	if err := New(database, config).IngestSource("gdpr", []Section{{Text: text}}); err != nil {
+		t.Fatalf("IngestSource failed: %v", err)
+	}
+
+	want := []Progress{
+		{Stage: StageStarted, Source: "gdpr", Done: 0, Total: 3},
+		{Stage: StageChunks, Source: "gdpr", Done: 2, Total: 3},
+		{Stage: StageChunks, Source: "gdpr", Done: 3, Total: 3},
+		{Stage: StageFinished, Source: "gdpr", Done: 3, Total: 3},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, events)
+	}
+	for i := range want {
+		// Batches report how long their embeddings took
+		if events[i].Stage == StageChunks && events[i].EmbedTime <= 0 {
+			t.Errorf("Event %d has no embedding time: %+v", i, events[i])
+		}
+		events[i].EmbedTime = 0
+		if events[i] != want[i] {
+			t.Errorf("Event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+
+	events = nil
+	if err := New(database, config).IngestSource("gdpr", []Section{{Text: text}}); err != nil {
+		t.Fatalf("IngestSource failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Stage != StageSkipped {
+		t.Errorf("Expected a single skipped event, got %v", events)
+	}
+}
+
+func TestChannelProgress(t *testing.T) {
+	ch := make(chan Progress, 1)
+	ChannelProgress(ch).Report(Progress{Stage: StageStarted, Total: 5})
+	if p := <-ch; p.Stage != StageStarted || p.Total != 5 {
+		t.Errorf("Unexpected event %+v", p)
+	}
+}
+
+func TestTextProgress(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := TextProgress(&buf)
+	reporter.Report(Progress{Stage: StageResumed, Source: "gdpr.txt", Done: 6, Total: 10})
+	reporter.Report(Progress{Stage: StageChunks, Done: 8, Total: 10})
+	reporter.Report(Progress{Stage: StageFinished, Done: 10, Total: 10})
+
+	want := "Resuming gdpr.txt at chunk 7/10\nProcessed 8/10 chunks\nSuccessfully ingested 10 chunks\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected output:\n%s", got)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	clock := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	bar := &progressBar{w: &buf, now: func() time.Time { return clock }}
+
+	bar.Report(Progress{Stage: StageStarted, Source: "gdpr.txt", Total: 100})
+	clock = clock.Add(10 * time.Second)
+	bar.Report(Progress{Stage: StageChunks, Source: "gdpr.txt", Done: 50, Total: 100, EmbedTime: 300 * time.Millisecond})
+
+	lines := strings.Split(buf.String(), "\r")
+	last := lines[len(lines)-1]
+	want := "[===============               ] 50/100 chunks  5.0 chunks/s  embed 300ms  ETA 10s"
+	if last != want {
+		t.Errorf("Expected %q, got %q", want, last)
+	}
+
+	clock = clock.Add(10 * time.Second)
+	bar.Report(Progress{Stage: StageFinished, Source: "gdpr.txt", Done: 100, Total: 100})
+	if !strings.HasSuffix(strings.TrimRight(buf.String(), " \n"), "100/100 chunks  5.0 chunks/s  embed 300ms") || !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("Expected the finished bar on its own line, got %q", buf.String())
+	}
+
+	// Other events are printed as text, and a shorter line blanks the rest
+	// of the previous one
+	buf.Reset()
+	bar.Report(Progress{Stage: StageRejected, Source: "logo.png"})
+	if buf.String() != "Warning: skipping logo.png, not a text file\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+	bar.Report(Progress{Stage: StageChunks, Done: 1, Total: 2})
+	bar.lineWidth = 100
+	buf.Reset()
+	bar.Report(Progress{Stage: StageChunks, Done: 2, Total: 2})
+	if got := strings.TrimPrefix(buf.String(), "\r"); len(got) != 100 {
+		t.Errorf("Expected the line padded to the previous width, got %q", got)
+	}
+}
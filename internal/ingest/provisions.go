@@ -0,0 +1,205 @@
+package ingest
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// paragraphRe matches a numbered article paragraph such as "2. Where..."
+	paragraphRe = regexp.MustCompile(`^(\d+)\.(?:\s|$)`)
+	// "ANNEX", "Annex II" or "## ANNEX 1"
+	annexHeadingRe = regexp.MustCompile(`^#*\s*(?:ANNEX|Annex)(?:\s+([IVXLC]+|\d+))?\s*$`)
+)
+
+// provisionTracker follows the chapter, section, article, paragraph and
+// recital that a line of regulation text belongs to
+type provisionTracker struct {
+	meta        map[string]string
+	seenArticle bool
+}
+
+func newProvisionTracker() *provisionTracker {
+	return &provisionTracker{meta: make(map[string]string)}
+}
+
+// advance updates the tracked provision with line i
+func (t *provisionTracker) advance(lines []string, i int) {
+	line := strings.TrimSpace(lines[i])
+
+	if m := divisionRe.FindStringSubmatch(line); m != nil {
+		key, titleKey := MetaChapter, MetaChapterTitle
+		if strings.EqualFold(m[1], "section") {
+			key, titleKey = MetaSection, MetaSectionTitle
+		} else {
+			t.clear(MetaSection, MetaSectionTitle)
+		}
+		t.meta[key] = strings.ToUpper(m[2])
+		t.clear(titleKey, MetaArticle, MetaArticleTitle, MetaParagraph, MetaRecital, MetaAnnex)
+		if title := divisionTitle(line[len(m[0]):], lines, i+1); title != "" {
+			t.meta[titleKey] = title
+		}
+		return
+	}
+
+	// Annexes follow the operative part and belong to no chapter
+	if m := annexHeadingRe.FindStringSubmatch(line); m != nil {
+		t.seenArticle = true
+		t.clear(MetaChapter, MetaChapterTitle, MetaSection, MetaSectionTitle, MetaArticle, MetaArticleTitle, MetaParagraph, MetaRecital)
+		t.meta[MetaAnnex] = annexNumber(line)
+		return
+	}
+
+	if m := articleHeadingRe.FindStringSubmatch(line); m != nil {
+		t.seenArticle = true
+		t.clear(MetaArticleTitle, MetaParagraph, MetaRecital, MetaAnnex)
+		t.meta[MetaArticle] = m[1]
+		title := strings.TrimSpace(m[2])
+		if title == "" {
+			title = articleTitle(lines, i+1)
+		}
+		if title != "" {
+			t.meta[MetaArticleTitle] = title
+		}
+		return
+	}
+
+	m := recitalHeadingRe.FindStringSubmatch(line)
+	if m == nil && !t.seenArticle {
+		m = recitalNumberRe.FindStringSubmatch(line)
+	}
+	if m != nil {
+		t.clear(MetaArticle, MetaArticleTitle, MetaParagraph, MetaAnnex)
+		t.meta[MetaRecital] = m[1]
+		return
+	}
+
+	// "HAVE ADOPTED THIS REGULATION:" ends the last recital
+	if !t.seenArticle && t.meta[MetaRecital] != "" && isUpperHeading(line) {
+		t.clear(MetaRecital)
+		return
+	}
+
+	if t.meta[MetaArticle] != "" {
+		if m := paragraphRe.FindStringSubmatch(line); m != nil {
+			t.meta[MetaParagraph] = m[1]
+		}
+	}
+}
+
+// divisionTitle returns the title of a chapter or section: the rest of its
+// heading line after a dash or colon ("CHAPTER I – General provisions"), or
+// the next line when that looks like a title
+func divisionTitle(rest string, lines []string, next int) string {
+	if rest != "" && !unicode.IsSpace(rune(rest[0])) && !strings.ContainsRune("-–—:", []rune(rest)[0]) {
+		return "" // "CHAPTER Introduction" is no division heading
+	}
+	if title := strings.TrimSpace(strings.TrimLeft(rest, " \t-–—:")); title != "" {
+		return title
+	}
+	for ; next < len(lines); next++ {
+		line := strings.TrimSpace(lines[next])
+		if line == "" {
+			continue
+		}
+		if divisionRe.MatchString(line) || annexHeadingRe.MatchString(line) || recitalHeadingRe.MatchString(line) {
+			return ""
+		}
+		return articleTitle(lines, next)
+	}
+	return ""
+}
+
+func (t *provisionTracker) clear(keys ...string) {
+	for _, k := range keys {
+		delete(t.meta, k)
+	}
+}
+
+// provisionMetadata returns, for each chunk of text, the chapter, section,
+// article (with its title), paragraph or recital in effect where the chunk
+// starts. Chunks must be in order and be substrings of the text with
+// whitespace trimmed, as produced by the chunkers.
+func provisionMetadata(text string, chunks []string) []map[string]string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	lines := strings.Split(text, "\n")
+
+	tracker := newProvisionTracker()
+	metas := make([]map[string]string, len(chunks))
+	line, lineStart := 0, 0
+	from := 0
+	for i, chunk := range chunks {
+		offset := from
+		if idx := strings.Index(text[from:], chunk); idx >= 0 {
+			offset = from + idx
+			from = offset + 1
+		}
+
+		// Apply every line starting at or before the chunk, including the
+		// line the chunk starts on
+		for line < len(lines) && lineStart <= offset {
+			tracker.advance(lines, line)
+			lineStart += len(lines[line]) + 1
+			line++
+		}
+
+		if len(tracker.meta) > 0 {
+			metas[i] = make(map[string]string, len(tracker.meta))
+			for k, v := range tracker.meta {
+				metas[i][k] = v
+			}
+		}
+	}
+	return metas
+}
+
+// mergeMetadata returns the section metadata with detected keys added where
+// the section does not set them already
+func mergeMetadata(section, detected map[string]string) map[string]string {
+	if len(detected) == 0 {
+		return section
+	}
+	merged := make(map[string]string, len(section)+len(detected))
+	for k, v := range detected {
+		merged[k] = v
+	}
+	for k, v := range section {
+		merged[k] = v
+	}
+	return merged
+}
+
+// annexNumber returns the numeral of an annex heading such as "ANNEX II",
+// or "I" for the only annex of a text headed just "ANNEX"
+func annexNumber(heading string) string {
+	if m := annexHeadingRe.FindStringSubmatch(strings.TrimSpace(heading)); m != nil && m[1] != "" {
+		return m[1]
+	}
+	return "I"
+}
+
+// classifyProvision adds the provision type implied by the structural
+// metadata of a chunk. Chunks outside any recital, article or annex, such as
+// the title of the regulation, are left unclassified.
+func classifyProvision(meta map[string]string) map[string]string {
+	var kind string
+	switch {
+	case meta[MetaProvisionType] != "":
+		return meta
+	case meta[MetaAnnex] != "":
+		kind = ProvisionAnnex
+	case meta[MetaArticle] != "":
+		kind = ProvisionArticle
+	case meta[MetaRecital] != "":
+		kind = ProvisionRecital
+	default:
+		return meta
+	}
+	classified := make(map[string]string, len(meta)+1)
+	for k, v := range meta {
+		classified[k] = v
+	}
+	classified[MetaProvisionType] = kind
+	return classified
+}
@@ -0,0 +1,192 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestProvisionMetadata(t *testing.T) {
+	lines := strings.Split(testRegulationText, "\n")
+	chunks := []string{
+		lines[0],                    // title, before any provision
+		lines[3],                    // recital 2
+		lines[4],                    // HAVE ADOPTED
+		lines[9] + "\n" + lines[10], // paragraphs 1 and 2 of Article 1
+		"protects fundamental rights.\nArticle 4", // starts mid-paragraph 2
+		lines[14], // definition (1) of Article 4, not a recital
+		lines[17], // body of Article 17
+	}
+
+	metas := provisionMetadata(testRegulationText, chunks)
+
+	want := []map[string]string{
+		nil,
+		{MetaRecital: "2"},
+		nil,
+		{MetaChapter: "I", MetaChapterTitle: "General provisions", MetaArticle: "1", MetaArticleTitle: "Subject-matter and objectives", MetaParagraph: "1"},
+		{MetaChapter: "I", MetaChapterTitle: "General provisions", MetaArticle: "1", MetaArticleTitle: "Subject-matter and objectives", MetaParagraph: "2"},
+		{MetaChapter: "I", MetaChapterTitle: "General provisions", MetaArticle: "4", MetaArticleTitle: "Definitions"},
+		{MetaChapter: "I", MetaChapterTitle: "General provisions", MetaArticle: "17", MetaArticleTitle: "Right to erasure"},
+	}
+	for i := range want {
+		if len(metas[i]) != len(want[i]) {
+			t.Errorf("Chunk %d: got %v, want %v", i, metas[i], want[i])
+			continue
+		}
+		for k, v := range want[i] {
+			if metas[i][k] != v {
+				t.Errorf("Chunk %d: got %v, want %v", i, metas[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestProvisionMetadataRecitalHeadings(t *testing.T) {
+	text := "Article 99\nEntry into force\nIt shall apply from 25 May 2018.\nRecital 26\nNot applicable to anonymous data."
+	metas := provisionMetadata(text, []string{"It shall apply from 25 May 2018.", "Not applicable to anonymous data."})
+
+	if metas[0][MetaArticle] != "99" || metas[0][MetaRecital] != "" {
+		t.Errorf("Expected Article 99, got %v", metas[0])
+	}
+	if metas[1][MetaRecital] != "26" || metas[1][MetaArticle] != "" {
+		t.Errorf("Expected recital 26 after its heading, got %v", metas[1])
+	}
+}
+
+func TestProvisionMetadataEmptyHeadingLine(t *testing.T) {
+	// A line of bare # marks after a heading is skipped when looking for its title
+	metas := provisionMetadata("Article 5\n#\nBody of the article.", []string{"Body of the article."})
+	if metas[0][MetaArticle] != "5" || metas[0][MetaArticleTitle] != "" {
+		t.Errorf("Expected untitled Article 5, got %v", metas[0])
+	}
+
+	metas = provisionMetadata("Article 5\n#\nPrinciples", []string{"Principles"})
+	if metas[0][MetaArticleTitle] != "Principles" {
+		t.Errorf("Expected the title after the # line, got %v", metas[0])
+	}
+}
+
+func TestMergeMetadata(t *testing.T) {
+	section := map[string]string{MetaArticle: "17", "page": "3"}
+	merged := mergeMetadata(section, map[string]string{MetaArticle: "16", MetaParagraph: "2"})
+	if merged[MetaArticle] != "17" || merged[MetaParagraph] != "2" || merged["page"] != "3" {
+		t.Errorf("Expected section metadata to win over detected metadata, got %v", merged)
+	}
+	if section[MetaParagraph] != "" {
+		t.Error("Expected the section metadata not to be modified")
+	}
+}
+
+func TestIngestDetectsProvisions(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.ChunkSize = 120
+	config.ChunkOverlap = 0
+	if err := New(database, config).IngestText(testRegulationText); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("obtain erasure", 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	doc, _ := database.GetDocument(results[0].ID)
+	if doc.Metadata[MetaArticle] == "" || doc.Metadata[MetaChapter] != "I" {
+		t.Errorf("Expected article and chapter metadata on a window chunk, got %v", doc.Metadata)
+	}
+}
+
+func TestProvisionMetadataAnnex(t *testing.T) {
+	text := "CHAPTER XI\nArticle 99\nIt shall apply from 25 May 2018.\nANNEX II\nList of processing operations."
+	metas := provisionMetadata(text, []string{"It shall apply from 25 May 2018.", "List of processing operations."})
+
+	if metas[1][MetaAnnex] != "II" || metas[1][MetaArticle] != "" || metas[1][MetaChapter] != "" {
+		t.Errorf("Expected annex II outside any chapter or article, got %v", metas[1])
+	}
+}
+
+func TestClassifyProvision(t *testing.T) {
+	tests := []struct {
+		meta map[string]string
+		want string
+	}{
+		{map[string]string{MetaRecital: "26"}, ProvisionRecital},
+		{map[string]string{MetaChapter: "III", MetaArticle: "17", MetaParagraph: "1"}, ProvisionArticle},
+		{map[string]string{MetaAnnex: "I"}, ProvisionAnnex},
+		{map[string]string{MetaChapter: "III"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := classifyProvision(tt.meta)[MetaProvisionType]; got != tt.want {
+			t.Errorf("classifyProvision(%v) = %q, want %q", tt.meta, got, tt.want)
+		}
+	}
+}
+
+func TestIngestProvisionTypeFilter(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Strategy = StrategyStructure
+	if err := New(database, config).IngestText(testRegulationText); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	for _, kind := range []string{ProvisionRecital, ProvisionArticle} {
+		results, err := database.SearchTrigrams("fundamental rights", 10, db.SearchOptions{ProvisionType: kind})
+		if err != nil {
+			t.Fatalf("SearchTrigrams failed: %v", err)
+		}
+		if len(results) == 0 {
+			t.Errorf("Expected %s results", kind)
+		}
+		for _, r := range results {
+			doc, _ := database.GetDocument(r.ID)
+			if doc.Metadata[MetaProvisionType] != kind {
+				t.Errorf("Filter %s returned %v", kind, doc.Metadata)
+			}
+		}
+	}
+}
+
+func TestDivisionTitle(t *testing.T) {
+	tests := []struct {
+		text string
+		want map[string]string
+	}{
+		{"CHAPTER II – Principles\nArticle 5", map[string]string{MetaChapter: "II", MetaChapterTitle: "Principles"}},
+		{"CHAPTER III\n\nSection 1\nTransparency and modalities", map[string]string{MetaChapter: "III", MetaSection: "1", MetaSectionTitle: "Transparency and modalities"}},
+		{"Section 2: Information and access\nArticle 13", map[string]string{MetaSection: "2", MetaSectionTitle: "Information and access"}},
+		{"CHAPTER IV\n1. The controller shall implement measures.", map[string]string{MetaChapter: "IV"}},
+		{"Section 2\n##\nArticle 13", map[string]string{MetaSection: "2"}},
+	}
+	for _, tt := range tests {
+		lines := strings.Split(tt.text, "\n")
+		tracker := newProvisionTracker()
+		for i := range lines {
+			if strings.HasPrefix(lines[i], "Article") || strings.HasPrefix(lines[i], "1.") {
+				break
+			}
+			tracker.advance(lines, i)
+		}
+		if len(tracker.meta) != len(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.text, tracker.meta, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if tracker.meta[k] != v {
+				t.Errorf("%q: got %v, want %v", tt.text, tracker.meta, tt.want)
+				break
+			}
+		}
+	}
+}
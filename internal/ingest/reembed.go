@@ -0,0 +1,137 @@
+package ingest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// Reembed recomputes the embeddings of the stored chunks of a collection,
+// or of every collection when collection is empty, with the configured
+// embedder. Chunks keep their IDs, text and metadata, so switching embedder
+// does not require the source files. The embedder is recorded for the whole
+// database, so switching it for one collection while others hold chunks is
+// refused. It returns the number of chunks embedded.
+func (ing *Ingester) Reembed(collection string) (int, error) {
+	if collection != "" {
+		if err := ing.checkReembedCollection(collection); err != nil {
+			return 0, err
+		}
+	}
+	if ing.embedder == nil {
+		embedder, err := NewEmbedder(ing.config.Embedder, ing.config)
+		if err != nil {
+			return 0, err
+		}
+		ing.embedder = embedder
+	}
+
+	docs, err := ing.db.Documents(db.DocumentFilter{Collection: collection})
+	if err != nil {
+		return 0, err
+	}
+	ing.report(StageStarted, collection, 0, len(docs))
+
+	batchSize := ing.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	for start := 0; start < len(docs); start += batchSize {
+		end := start + batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		batch := docs[start:end]
+
+		texts := make([]string, len(batch))
+		for j, doc := range batch {
+			texts[j] = doc.Chunk
+		}
+		began := time.Now()
+		embeddings, err := ing.embedder.EmbedDocuments(texts)
+		embedTime := time.Since(began)
+		if err != nil {
+			return start, fmt.Errorf("failed to generate embeddings for chunks %d-%d: %w", batch[0].ID, batch[len(batch)-1].ID, err)
+		}
+		if len(embeddings) != len(texts) {
+			return start, fmt.Errorf("got %d embeddings for %d chunks", len(embeddings), len(texts))
+		}
+		for j, doc := range batch {
+			if err := ing.db.InsertEmbedding(doc.ID, embeddings[j]); err != nil {
+				return start + j, fmt.Errorf("failed to insert embedding for chunk %d: %w", doc.ID, err)
+			}
+		}
+		ing.reportBatch(collection, end, len(docs), embedTime)
+	}
+
+	for key, value := range map[string]string{
+		db.MetaIngestedAt:     time.Now().Format(time.RFC3339),
+		db.MetaEmbedder:       ing.config.EmbedderName(),
+		db.MetaEmbeddingModel: ing.config.EmbeddingModel(),
+	} {
+		if err := ing.db.SetMetadata(key, value); err != nil {
+			return len(docs), fmt.Errorf("failed to set metadata: %w", err)
+		}
+	}
+
+	if err := ing.updateCheckpoints(docs); err != nil {
+		return len(docs), err
+	}
+
+	ing.report(StageFinished, collection, len(docs), len(docs))
+	return len(docs), nil
+}
+
+// checkReembedCollection refuses to re-embed one collection with an
+// embedder other than the recorded one while other collections have chunks,
+// which would leave them embedded with another model than their queries
+func (ing *Ingester) checkReembedCollection(collection string) error {
+	embedder, err := ing.db.GetMetadata(db.MetaEmbedder)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	model, err := ing.db.GetMetadata(db.MetaEmbeddingModel)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if embedder == "" || (embedder == ing.config.EmbedderName() && model == ing.config.EmbeddingModel()) {
+		return nil
+	}
+
+	collections, err := ing.db.Collections()
+	if err != nil {
+		return err
+	}
+	for _, c := range collections {
+		if c.Name != collection {
+			return fmt.Errorf("collection %q is embedded with %s like the rest of the database; re-embed every collection to switch to %s",
+				c.Name, embedderLabel(embedder, model), embedderLabel(ing.config.EmbedderName(), ing.config.EmbeddingModel()))
+		}
+	}
+	return nil
+}
+
+// updateCheckpoints records the configured embedder in the checkpoints of
+// the sources of docs, so that ingesting them again with it skips them
+func (ing *Ingester) updateCheckpoints(docs []db.Document) error {
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		if !checkpointed(doc.Source) || seen[doc.Source] {
+			continue
+		}
+		seen[doc.Source] = true
+		cp, err := ing.db.GetCheckpoint(doc.Source)
+		if err != nil {
+			return err
+		}
+		if cp == nil {
+			continue
+		}
+		cp.Fingerprint = withEmbedder(cp.Fingerprint, ing.config)
+		if err := ing.db.SaveCheckpoint(*cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestReembed(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, doc := range []db.Document{
+		{Chunk: "Article 17 Right to erasure", Collection: "gdpr-en"},
+		{Chunk: "Article 18 Right to restriction", Collection: "gdpr-en", ChunkIndex: 1},
+		{Chunk: "Guidelines on consent", Collection: "edpb"},
+	} {
+		if _, err := database.InsertDocument(doc); err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+	}
+
+	fake := &fakeEmbedder{}
+	config := DefaultConfig()
+	config.BatchSize = 1
+	var events []Progress
+	config.Progress = ProgressFunc(func(p Progress) { events = append(events, p) })
+	ing := NewWithEmbedder(database, config, fake)
+
+	n, err := ing.Reembed("gdpr-en")
+	if err != nil {
+		t.Fatalf("Reembed failed: %v", err)
+	}
+	if n != 2 || len(fake.documents) != 2 {
+		t.Errorf("Expected the 2 chunks of the collection embedded, got %d (%v)", n, fake.documents)
+	}
+	if len(events) != 4 || events[0].Stage != StageStarted || events[3].Stage != StageFinished {
+		t.Errorf("Expected started, two batches and finished, got %+v", events)
+	}
+
+	stats, err := database.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.EmbeddedChunks != 2 || len(stats.Dimensions) != 1 || stats.Dimensions[0] != 2 {
+		t.Errorf("Expected 2 chunks with 2-dimensional embeddings, got %+v", stats)
+	}
+	if stats.Embedder != EmbedderStub {
+		t.Errorf("Expected the embedder to be recorded, got %q", stats.Embedder)
+	}
+}
+
+func TestReembedCollectionKeepsOneEmbedder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	ingestFile := func(name, text string, config Config, collection string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		config.Collection = collection
+		if err := NewWithEmbedder(database, config, &fakeEmbedder{}).IngestFile(path); err != nil {
+			t.Fatalf("IngestFile failed: %v", err)
+		}
+		return path
+	}
+	policy := ingestFile("policy.txt", "Processing shall be lawful.", DefaultConfig(), "a")
+
+	ollama := DefaultConfig()
+	ollama.Embedder = EmbedderOllama
+	ollama.OllamaModel = "all-minilm"
+
+	// With a single collection, switching its embedder switches the database's
+	if _, err := NewWithEmbedder(database, ollama, &fakeEmbedder{}).Reembed("a"); err != nil {
+		t.Fatalf("Reembed failed: %v", err)
+	}
+	if embedder, _ := database.GetMetadata(db.MetaEmbedder); embedder != EmbedderOllama {
+		t.Errorf("Expected the new embedder to be recorded, got %q", embedder)
+	}
+
+	// The checkpoint follows, so the file is not embedded again
+	ollama.Collection = "a"
+	fake := &fakeEmbedder{}
+	if err := NewWithEmbedder(database, ollama, fake).IngestFile(policy); err != nil {
+		t.Fatalf("IngestFile failed: %v", err)
+	}
+	if len(fake.documents) != 0 {
+		t.Errorf("Expected the re-embedded file to be skipped, embedded %q", fake.documents)
+	}
+
+	// Another collection embedded with the same model blocks a switch of one
+	ingestFile("consent.txt", "Consent shall be freely given.", ollama, "b")
+	if _, err := NewWithEmbedder(database, DefaultConfig(), &fakeEmbedder{}).Reembed("a"); err == nil {
+		t.Error("Expected re-embedding one of two collections with another embedder to be refused")
+	}
+	if _, err := NewWithEmbedder(database, DefaultConfig(), &fakeEmbedder{}).Reembed(""); err != nil {
+		t.Errorf("Expected every collection to be re-embedded, got %v", err)
+	}
+}
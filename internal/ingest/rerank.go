@@ -0,0 +1,226 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// Names of the built-in rerankers
+const (
+	RerankerONNX     = "onnx"
+	RerankerEndpoint = "endpoint"
+)
+
+// Reranking APIs an endpoint can speak
+const (
+	// RerankCohere is the rerank API of Cohere, also served by Jina,
+	// Voyage, vLLM and Infinity
+	RerankCohere = "cohere"
+	// RerankTEI is the rerank route of Hugging Face Text Embeddings Inference
+	RerankTEI = "tei"
+)
+
+// crossEncoderMaxTokens is the input length of BERT-based cross-encoders
+const crossEncoderMaxTokens = 512
+
+// RerankerConfig configures a reranker
+type RerankerConfig struct {
+	ONNXModelDir string // directory with a cross-encoder's model.onnx and vocab.txt
+	ONNXRuntime  string // path to the ONNX Runtime shared library; empty uses the system loader
+
+	// Endpoint is the hosted reranking API, with API RerankCohere (default)
+	// or RerankTEI, or the OpenAI-compatible chat API for RerankerLLM
+	Endpoint EndpointConfig
+
+	MaxCandidates int // RerankerLLM: candidates judged per search; 0 uses DefaultLLMRerankCandidates
+	CacheSize     int // RerankerLLM: judgments kept in memory; 0 uses DefaultLLMRerankCacheSize
+}
+
+// DefaultRerankerConfig returns the default reranker configuration
+func DefaultRerankerConfig() RerankerConfig {
+	return RerankerConfig{ONNXModelDir: DefaultRerankerModelDir()}
+}
+
+// DefaultRerankerModelDir returns the directory the ms-marco-MiniLM-L-6-v2
+// cross-encoder is looked up in when none is configured
+func DefaultRerankerModelDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join("models", "ms-marco-MiniLM-L-6-v2")
+	}
+	return filepath.Join(base, "gdpr-mcp", "models", "ms-marco-MiniLM-L-6-v2")
+}
+
+// RerankerFactory creates a reranker from its configuration
+type RerankerFactory func(config RerankerConfig) (db.Reranker, error)
+
+var (
+	rerankersMu sync.RWMutex
+	rerankers   = map[string]RerankerFactory{
+		RerankerONNX: func(config RerankerConfig) (db.Reranker, error) {
+			if !ONNXAvailable {
+				return nil, fmt.Errorf("ONNX reranking is not available: rebuild with -tags onnx")
+			}
+			if err := checkONNXModelDir(config.ONNXModelDir); err != nil {
+				return nil, err
+			}
+			return perDocumentReranker(func(query, document string) (float64, error) {
+				return onnxRerankScore(query, document, config.ONNXModelDir, config.ONNXRuntime)
+			}), nil
+		},
+		RerankerEndpoint: func(config RerankerConfig) (db.Reranker, error) {
+			if config.Endpoint.URL == "" {
+				return nil, fmt.Errorf("no reranking endpoint URL configured")
+			}
+			return endpointReranker(config.Endpoint), nil
+		},
+		RerankerLLM: func(config RerankerConfig) (db.Reranker, error) {
+			return newLLMReranker(config)
+		},
+	}
+)
+
+// RegisterReranker makes a reranker available by name, replacing any
+// reranker registered under the same name
+func RegisterReranker(name string, factory RerankerFactory) {
+	rerankersMu.Lock()
+	defer rerankersMu.Unlock()
+	rerankers[name] = factory
+}
+
+// NewReranker creates the reranker registered as name
+func NewReranker(name string, config RerankerConfig) (db.Reranker, error) {
+	rerankersMu.RLock()
+	factory, ok := rerankers[name]
+	rerankersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown reranker %q (available: %v)", name, Rerankers())
+	}
+	return factory(config)
+}
+
+// Rerankers returns the names of the registered rerankers in sorted order
+func Rerankers() []string {
+	rerankersMu.RLock()
+	defer rerankersMu.RUnlock()
+
+	names := make([]string, 0, len(rerankers))
+	for name := range rerankers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// perDocumentReranker adapts a function scoring one document at a time
+type perDocumentReranker func(query, document string) (float64, error)
+
+func (f perDocumentReranker) Rerank(query string, documents []string) ([]float64, error) {
+	scores := make([]float64, len(documents))
+	for i, document := range documents {
+		score, err := f(query, document)
+		if err != nil {
+			return nil, err
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
+
+// relevanceFromLogits turns cross-encoder output into a relevance between 0
+// and 1: the sigmoid of a single logit, or the softmax probability of the
+// relevant class when the model outputs two
+func relevanceFromLogits(logits []float32) (float64, error) {
+	switch len(logits) {
+	case 1:
+		return 1 / (1 + math.Exp(-float64(logits[0]))), nil
+	case 2:
+		return 1 / (1 + math.Exp(float64(logits[0])-float64(logits[1]))), nil
+	default:
+		return 0, fmt.Errorf("unexpected cross-encoder output of %d values", len(logits))
+	}
+}
+
+// endpointReranker scores documents with a hosted reranking API in one
+// request. Reranking runs while a client waits for search results, so
+// failures are not retried.
+type endpointReranker EndpointConfig
+
+func (e endpointReranker) Rerank(query string, documents []string) ([]float64, error) {
+	config := EndpointConfig(e)
+	base := strings.TrimRight(config.URL, "/")
+	header, value := config.authHeader()
+
+	var request interface{}
+	switch config.API {
+	case "", RerankCohere:
+		request = map[string]interface{}{
+			"model":     config.Model,
+			"query":     query,
+			"documents": documents,
+			"top_n":     len(documents),
+		}
+	case RerankTEI:
+		request = map[string]interface{}{"query": query, "texts": documents}
+	default:
+		return nil, fmt.Errorf("unknown reranking API %q", config.API)
+	}
+
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	body, err := postEmbeddingRequest(base+"/rerank", jsonBody, header, value)
+	if err != nil {
+		return nil, err
+	}
+
+	type ranked struct {
+		Index          int      `json:"index"`
+		Score          *float64 `json:"score"`
+		RelevanceScore *float64 `json:"relevance_score"`
+	}
+	var results []ranked
+	if config.API == RerankTEI {
+		err = json.Unmarshal(body, &results)
+	} else {
+		var response struct {
+			Results []ranked `json:"results"`
+		}
+		err = json.Unmarshal(body, &response)
+		results = response.Results
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// Results come sorted by relevance; the index restores document order
+	scores := make([]float64, len(documents))
+	seen := make([]bool, len(documents))
+	for _, r := range results {
+		if r.Index < 0 || r.Index >= len(documents) || seen[r.Index] {
+			return nil, fmt.Errorf("invalid document index %d in response", r.Index)
+		}
+		switch {
+		case r.RelevanceScore != nil:
+			scores[r.Index] = *r.RelevanceScore
+		case r.Score != nil:
+			scores[r.Index] = *r.Score
+		default:
+			return nil, fmt.Errorf("no score for document %d in response", r.Index)
+		}
+		seen[r.Index] = true
+	}
+	if len(results) != len(documents) {
+		return nil, fmt.Errorf("expected %d scores in response, got %d", len(documents), len(results))
+	}
+	return scores, nil
+}
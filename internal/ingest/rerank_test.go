@@ -0,0 +1,104 @@
+package ingest
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEndpointReranker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model     string   `json:"model"`
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+			Texts     []string `json:"texts"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		// Both APIs answer sorted by relevance
+		switch r.URL.Path {
+		case "/v1/rerank":
+			if req.Model != "rerank-multilingual-v3.0" || req.Query != "erasure" || len(req.Documents) != 2 {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"results": []map[string]interface{}{
+					{"index": 1, "relevance_score": 0.9},
+					{"index": 0, "relevance_score": 0.2},
+				},
+			})
+		case "/rerank":
+			if len(req.Texts) != 2 {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"index": 0, "score": 0.7},
+				{"index": 1, "score": 0.1},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	documents := []string{"Right of access", "Right to erasure"}
+
+	reranker, err := NewReranker(RerankerEndpoint, RerankerConfig{Endpoint: EndpointConfig{URL: srv.URL + "/v1", Model: "rerank-multilingual-v3.0"}})
+	if err != nil {
+		t.Fatalf("NewReranker failed: %v", err)
+	}
+	scores, err := reranker.Rerank("erasure", documents)
+	if err != nil {
+		t.Fatalf("Cohere-style rerank failed: %v", err)
+	}
+	if !reflect.DeepEqual(scores, []float64{0.2, 0.9}) {
+		t.Errorf("Expected scores in document order, got %v", scores)
+	}
+
+	reranker, err = NewReranker(RerankerEndpoint, RerankerConfig{Endpoint: EndpointConfig{URL: srv.URL, API: RerankTEI}})
+	if err != nil {
+		t.Fatalf("NewReranker failed: %v", err)
+	}
+	scores, err = reranker.Rerank("erasure", documents)
+	if err != nil {
+		t.Fatalf("TEI rerank failed: %v", err)
+	}
+	if !reflect.DeepEqual(scores, []float64{0.7, 0.1}) {
+		t.Errorf("Unexpected TEI scores %v", scores)
+	}
+
+	reranker, _ = NewReranker(RerankerEndpoint, RerankerConfig{Endpoint: EndpointConfig{URL: srv.URL + "/missing"}})
+	if _, err := reranker.Rerank("erasure", documents); err == nil {
+		t.Error("Expected an error for a failing endpoint")
+	}
+}
+
+func TestNewRerankerErrors(t *testing.T) {
+	if _, err := NewReranker("colbert", RerankerConfig{}); err == nil {
+		t.Error("Expected an unknown reranker to be rejected")
+	}
+	if _, err := NewReranker(RerankerEndpoint, RerankerConfig{}); err == nil {
+		t.Error("Expected an endpoint reranker without URL to be rejected")
+	}
+	if _, err := NewReranker(RerankerONNX, RerankerConfig{ONNXModelDir: t.TempDir()}); err == nil {
+		t.Error("Expected an ONNX reranker without model files to be rejected")
+	}
+}
+
+func TestRelevanceFromLogits(t *testing.T) {
+	if score, err := relevanceFromLogits([]float32{0}); err != nil || score != 0.5 {
+		t.Errorf("relevanceFromLogits([0]) = %v, %v", score, err)
+	}
+	if score, _ := relevanceFromLogits([]float32{-2, 2}); math.Abs(score-1/(1+math.Exp(-4))) > 1e-9 {
+		t.Errorf("Expected the softmax of the relevant class, got %v", score)
+	}
+	if _, err := relevanceFromLogits([]float32{1, 2, 3}); err == nil {
+		t.Error("Expected an error for three logits")
+	}
+}
@@ -0,0 +1,105 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxRetries is how often a failed embedding request is retried
+const DefaultMaxRetries = 5
+
+// Backoff between retries doubles from retryBaseDelay up to retryMaxDelay
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = time.Minute
+)
+
+// sleep is replaced in tests to avoid waiting out backoff delays
+var sleep = time.Sleep
+
+// apiError is a non-200 response from an embeddings API
+type apiError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header; zero when absent
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// temporary reports whether the request may succeed when retried
+func (e *apiError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// retryable reports whether err is a rate limit, server error or timeout
+// worth retrying
+func retryable(err error) bool {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.temporary()
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && urlErr.Timeout()
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an
+// HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// backoff returns the delay before retry attempt (counting from 0)
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 0; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// withRetry wraps an embedding function so that rate limits, server errors
+// and timeouts are retried up to maxRetries times with exponential backoff,
+// waiting as long as the server asks via Retry-After
+func withRetry(maxRetries int, embed func(texts []string) ([][]float32, error)) func(texts []string) ([][]float32, error) {
+	return func(texts []string) ([][]float32, error) {
+		for attempt := 0; ; attempt++ {
+			embeddings, err := embed(texts)
+			if err == nil || !retryable(err) {
+				return embeddings, err
+			}
+			if attempt >= maxRetries {
+				return nil, fmt.Errorf("giving up after %d retries: %w", attempt, err)
+			}
+
+			delay := backoff(attempt)
+			var apiErr *apiError
+			if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+				delay = apiErr.RetryAfter
+			}
+			sleep(delay)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recordSleeps replaces sleep for the duration of a test and returns the
+// delays it was asked to wait
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() { sleep = time.Sleep })
+	return &delays
+}
+
+func TestWithRetryRateLimit(t *testing.T) {
+	delays := recordSleeps(t)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case 2:
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"index": 0, "embedding": []float64{1, 0}}},
+			})
+		}
+	}))
+	defer srv.Close()
+
+	embed := withRetry(DefaultMaxRetries, func(texts []string) ([][]float32, error) {
+		return endpointEmbeddings(texts, EndpointConfig{URL: srv.URL})
+	})
+	embeddings, err := embed([]string{"consent"})
+	if err != nil {
+		t.Fatalf("Expected retries to succeed, got %v", err)
+	}
+	if len(embeddings) != 1 || requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+	if len(*delays) != 2 || (*delays)[0] != 7*time.Second || (*delays)[1] != 2*time.Second {
+		t.Errorf("Expected Retry-After then backoff, got %v", *delays)
+	}
+}
+
+func TestWithRetryGivesUp(t *testing.T) {
+	delays := recordSleeps(t)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	embed := withRetry(3, func(texts []string) ([][]float32, error) {
+		return endpointEmbeddings(texts, EndpointConfig{URL: srv.URL})
+	})
+	if _, err := embed([]string{"consent"}); err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if requests != 4 {
+		t.Errorf("Expected 1 request plus 3 retries, got %d", requests)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, d := range want {
+		if i >= len(*delays) || (*delays)[i] != d {
+			t.Fatalf("Expected backoff %v, got %v", want, *delays)
+		}
+	}
+}
+
+func TestWithRetrySkipsClientErrors(t *testing.T) {
+	delays := recordSleeps(t)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	embed := withRetry(DefaultMaxRetries, func(texts []string) ([][]float32, error) {
+		return endpointEmbeddings(texts, EndpointConfig{URL: srv.URL})
+	})
+	if _, err := embed([]string{"consent"}); err == nil {
+		t.Fatal("Expected error for 401")
+	}
+	if requests != 1 || len(*delays) != 0 {
+		t.Errorf("Expected no retries for 401, got %d requests", requests)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 25, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-1", 0},
+		{"Sat, 25 May 2024 12:00:10 GMT", 10 * time.Second},
+		{"Sat, 25 May 2024 11:59:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if backoff(0) != time.Second || backoff(3) != 8*time.Second {
+		t.Errorf("Unexpected backoff: %v, %v", backoff(0), backoff(3))
+	}
+	if backoff(20) != retryMaxDelay {
+		t.Errorf("Expected backoff capped at %v, got %v", retryMaxDelay, backoff(20))
+	}
+}
+
+func TestIngestEmbeddingFailure(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	recordSleeps(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	config := DefaultConfig()
+	config.Embedder = EmbedderEndpoint
+	config.Endpoint.URL = srv.URL
+	config.MaxRetries = 1
+	if err := New(database, config).IngestText("Consent must be freely given."); err == nil {
+		t.Fatal("Expected ingestion to fail instead of storing stub embeddings")
+	}
+
+	if doc, _ := database.GetDocument(1); doc != nil {
+		t.Errorf("Expected no documents stored, got %+v", doc)
+	}
+}
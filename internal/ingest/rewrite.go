@@ -0,0 +1,219 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Names of the built-in query rewriters
+const (
+	RewriterTemplate = "template"
+	RewriterLLM      = "llm"
+)
+
+// MaxRewrites caps the reformulations searched per query, as each one is a
+// further search
+const MaxRewrites = 5
+
+// QueryRewriter reformulates a question into up to n alternative search
+// queries, so that searching all of them finds passages worded differently
+// from the question
+type QueryRewriter interface {
+	Rewrite(query string, n int) ([]string, error)
+}
+
+// RewriterConfig configures a query rewriter
+type RewriterConfig struct {
+	// Endpoint is the OpenAI-compatible chat API for RewriterLLM
+	Endpoint EndpointConfig
+}
+
+// RewriterFactory creates a query rewriter from its configuration
+type RewriterFactory func(config RewriterConfig) (QueryRewriter, error)
+
+var (
+	rewritersMu sync.RWMutex
+	rewriters   = map[string]RewriterFactory{
+		RewriterTemplate: func(RewriterConfig) (QueryRewriter, error) {
+			return TemplateRewriter{}, nil
+		},
+		RewriterLLM: func(config RewriterConfig) (QueryRewriter, error) {
+			endpoint, err := chatEndpoint(config.Endpoint, "LLM query rewriting")
+			if err != nil {
+				return nil, err
+			}
+			return llmRewriter(endpoint), nil
+		},
+	}
+)
+
+// RegisterRewriter makes a query rewriter available by name, replacing any
+// rewriter registered under the same name
+func RegisterRewriter(name string, factory RewriterFactory) {
+	rewritersMu.Lock()
+	defer rewritersMu.Unlock()
+	rewriters[name] = factory
+}
+
+// NewRewriter creates the query rewriter registered as name
+func NewRewriter(name string, config RewriterConfig) (QueryRewriter, error) {
+	rewritersMu.RLock()
+	factory, ok := rewriters[name]
+	rewritersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown query rewriter %q (available: %v)", name, Rewriters())
+	}
+	return factory(config)
+}
+
+// Rewriters returns the names of the registered query rewriters in sorted
+// order
+func Rewriters() []string {
+	rewritersMu.RLock()
+	defer rewritersMu.RUnlock()
+
+	names := make([]string, 0, len(rewriters))
+	for name := range rewriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// questionWords are dropped from questions to leave their keywords
+var questionWords = map[string]bool{
+	"a": true, "about": true, "allowed": true, "am": true, "an": true, "and": true, "are": true,
+	"as": true, "at": true, "be": true, "can": true, "could": true, "do": true, "does": true,
+	"for": true, "from": true, "have": true, "how": true, "i": true, "if": true, "in": true,
+	"is": true, "it": true, "may": true, "me": true, "must": true, "my": true, "of": true,
+	"on": true, "or": true, "our": true, "should": true, "that": true, "the": true, "their": true,
+	"there": true, "this": true, "to": true, "we": true, "what": true, "when": true, "where": true,
+	"which": true, "who": true, "why": true, "will": true, "with": true, "would": true, "you": true,
+	"your": true,
+}
+
+// rewriteConcepts maps everyday wording to the GDPR concept it is about
+var rewriteConcepts = []struct {
+	cues    []string
+	concept string
+}{
+	{[]string{"forever", "how long", "retain", "retention", "keep", "store", "archive"}, "storage limitation retention period"},
+	{[]string{"delete", "remove", "erase", "forget"}, "right to erasure"},
+	{[]string{"allowed", "can we", "may we", "legal", "lawful", "permitted"}, "lawfulness of processing legal basis"},
+	{[]string{"share", "sell", "disclose", "send", "pass on"}, "disclosure to recipients"},
+	{[]string{"tell", "inform", "notice", "transparent"}, "information to be provided to the data subject"},
+	{[]string{"kid", "kids", "child", "children", "minor", "minors"}, "consent of a child"},
+	{[]string{"hack", "hacked", "leak", "leaked", "lost", "stolen"}, "personal data breach notification"},
+	{[]string{"abroad", "outside the eu", "cloud", "transfer"}, "transfers to third countries"},
+	{[]string{"camera", "cctv", "track", "tracking", "monitor", "monitoring"}, "systematic monitoring"},
+	{[]string{"health", "medical", "religion", "biometric", "ethnic"}, "special categories of personal data"},
+	{[]string{"marketing", "newsletter", "advertising", "ads"}, "direct marketing right to object"},
+	{[]string{"employee", "employees", "staff", "hr"}, "processing in the context of employment"},
+	{[]string{"vendor", "supplier", "outsource", "contractor"}, "processor contract"},
+	{[]string{"fine", "fines", "penalty", "sanction"}, "administrative fines"},
+	{[]string{"copy", "see my data", "what data"}, "right of access"},
+	{[]string{"email", "emails", "phone number", "address", "ip address"}, "personal data"},
+}
+
+// TemplateRewriter reformulates questions without a model: the question's
+// keywords alone, then the GDPR concepts its wording points to, each with
+// the keywords that did not point to it
+type TemplateRewriter struct{}
+
+// Rewrite returns up to n reformulations of query
+func (TemplateRewriter) Rewrite(query string, n int) ([]string, error) {
+	lower := strings.ToLower(query)
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	var keywords []string
+	for _, w := range words {
+		if !questionWords[w] {
+			keywords = append(keywords, w)
+		}
+	}
+
+	var rewrites []string
+	seen := map[string]bool{strings.Join(words, " "): true}
+	add := func(rewrite string) {
+		rewrite = strings.Join(strings.Fields(rewrite), " ")
+		if rewrite != "" && !seen[rewrite] && len(rewrites) < n {
+			seen[rewrite] = true
+			rewrites = append(rewrites, rewrite)
+		}
+	}
+
+	add(strings.Join(keywords, " "))
+	for _, c := range rewriteConcepts {
+		var cued []string
+		for _, cue := range c.cues {
+			if containsWords(lower, cue) {
+				cued = append(cued, strings.Fields(cue)...)
+			}
+		}
+		if len(cued) == 0 {
+			continue
+		}
+		rest := []string{c.concept}
+		for _, k := range keywords {
+			if !contains(cued, k) {
+				rest = append(rest, k)
+			}
+		}
+		add(strings.Join(rest, " "))
+	}
+	return rewrites, nil
+}
+
+// containsWords reports whether phrase occurs in text as whole words
+func containsWords(text, phrase string) bool {
+	return regexp.MustCompile(`(^|[^\pL])` + regexp.QuoteMeta(phrase) + `($|[^\pL])`).MatchString(text)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+const llmRewritePrompt = `You turn questions about the GDPR and related data protection law into search queries.
+Write the requested number of different queries that would find the relevant provisions,
+using the legal terms of the regulation (e.g. "storage limitation" for keeping data too long).
+Reply with JSON only, in the form {"queries": ["...", "..."]}.`
+
+// llmRewriter asks a chat model for reformulations through an OpenAI
+// compatible chat completions API
+type llmRewriter EndpointConfig
+
+func (r llmRewriter) Rewrite(query string, n int) ([]string, error) {
+	content, err := chatCompletion(EndpointConfig(r), llmRewritePrompt, fmt.Sprintf("Number of queries: %d\nQuestion: %s", n, query))
+	if err != nil {
+		return nil, err
+	}
+	object, err := jsonObject(content)
+	if err != nil {
+		return nil, err
+	}
+	var reply struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.Unmarshal(object, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse query rewrites: %w", err)
+	}
+
+	var rewrites []string
+	for _, q := range reply.Queries {
+		if q = strings.TrimSpace(q); q != "" && !strings.EqualFold(q, query) && len(rewrites) < n {
+			rewrites = append(rewrites, q)
+		}
+	}
+	return rewrites, nil
+}
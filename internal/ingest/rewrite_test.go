@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateRewriter(t *testing.T) {
+	rewrites, err := TemplateRewriter{}.Rewrite("Can we keep emails forever?", MaxRewrites)
+	if err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+	want := []string{
+		"keep emails forever",
+		"storage limitation retention period emails",
+		"lawfulness of processing legal basis keep emails forever",
+		"personal data keep forever",
+	}
+	if !reflect.DeepEqual(rewrites, want) {
+		t.Errorf("Rewrite = %q, want %q", rewrites, want)
+	}
+
+	if rewrites, _ := (TemplateRewriter{}).Rewrite("Can we keep emails forever?", 2); len(rewrites) != 2 {
+		t.Errorf("Expected at most 2 rewrites, got %q", rewrites)
+	}
+
+	// Cues match whole words only
+	if rewrites, _ := (TemplateRewriter{}).Rewrite("storekeeper duties", 5); !reflect.DeepEqual(rewrites, []string(nil)) {
+		t.Errorf("Expected no rewrites, got %q", rewrites)
+	}
+}
+
+func TestLLMRewriter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/chat/completions" || !strings.Contains(req.Messages[1].Content, "Number of queries: 2") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		content := `Here you go: {"queries": ["storage limitation", "Can we keep emails forever?", "retention period of e-mails", "third"]}`
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": content}}},
+		})
+	}))
+	defer srv.Close()
+
+	rewriter, err := NewRewriter(RewriterLLM, RewriterConfig{Endpoint: EndpointConfig{URL: srv.URL}})
+	if err != nil {
+		t.Fatalf("NewRewriter failed: %v", err)
+	}
+	rewrites, err := rewriter.Rewrite("Can we keep emails forever?", 2)
+	if err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+	// The question itself is dropped and the count capped
+	if want := []string{"storage limitation", "retention period of e-mails"}; !reflect.DeepEqual(rewrites, want) {
+		t.Errorf("Rewrite = %q, want %q", rewrites, want)
+	}
+
+	if _, err := NewRewriter(RewriterLLM, RewriterConfig{}); err == nil || !strings.Contains(err.Error(), "API key") {
+		t.Errorf("Expected OpenAI without a key to be rejected, got %v", err)
+	}
+	if _, err := NewRewriter("oracle", RewriterConfig{}); err == nil {
+		t.Error("Expected an unknown rewriter to be rejected")
+	}
+}
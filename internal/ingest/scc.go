@@ -0,0 +1,165 @@
+package ingest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SCCCollection is the collection the standard contractual clauses go into
+// unless the configuration names another
+const SCCCollection = "scc"
+
+// Metadata keys describing the part of the standard contractual clauses a
+// chunk comes from
+const (
+	MetaSCCSection     = "scc_section"      // section of the clauses in Roman numerals, e.g. "II"
+	MetaSCCClause      = "scc_clause"       // clause number, e.g. "8", or annex, e.g. "Annex II"
+	MetaSCCClauseTitle = "scc_clause_title" // e.g. "Data protection safeguards"
+	MetaSCCModules     = "scc_modules"      // comma-separated modules the text is specific to, e.g. "2,3"; absent where it applies to all
+)
+
+// maxSCCTitleLen bounds the length of the line after a clause heading taken
+// as its title
+const maxSCCTitleLen = 120
+
+var (
+	// "SECTION II" or "SECTION II – OBLIGATIONS OF THE PARTIES"
+	sccSectionRe = regexp.MustCompile(`^SECTION\s+(I{1,3}|IV)\b`)
+	// "Clause 8" or "Clause 7 – Optional", alone on its line; the title
+	// follows on the next one
+	sccClauseRe = regexp.MustCompile(`^Clause\s+(\d{1,2})(?:\s*[-–—]\s*Optional)?$`)
+	// "ANNEX II", alone on its line
+	sccAnnexRe = regexp.MustCompile(`^ANNEX\s+(I{1,3})$`)
+	// "MODULE TWO: Transfer controller to processor"
+	sccModuleRe = regexp.MustCompile(`^MODULE\s+(ONE|TWO|THREE|FOUR)\b`)
+	// "Clause 8", "clause 8.5", "8" or "Annex II", as the tools accept them
+	sccClauseNumberRe = regexp.MustCompile(`(?i)^(?:clause\s*)?(\d{1,2})(?:\.\d+)?$`)
+	sccAnnexNumberRe  = regexp.MustCompile(`(?i)^annex\s*(I{1,3}|[123])$`)
+)
+
+var sccModuleNumbers = map[string]string{"ONE": "1", "TWO": "2", "THREE": "3", "FOUR": "4"}
+
+// splitSCC splits the standard contractual clauses of Commission
+// Implementing Decision (EU) 2021/914 at their clauses, annexes and module
+// headings. Each resulting section is tagged with its section, clause and
+// clause title, and, for text following "MODULE ..." headings, the modules
+// it is specific to.
+func splitSCC(section Section) []Section {
+	lines := strings.Split(strings.ReplaceAll(section.Text, "\r\n", "\n"), "\n")
+
+	var sections []Section
+	var current []string
+	var sccSection, clause, title string
+	var modules []string
+	inModuleHeadings := false
+
+	flush := func() {
+		body := strings.TrimSpace(strings.Join(current, "\n"))
+		current = nil
+		if body == "" {
+			return
+		}
+		m := make(map[string]string, len(section.Metadata)+4)
+		for k, v := range section.Metadata {
+			m[k] = v
+		}
+		if sccSection != "" {
+			m[MetaSCCSection] = sccSection
+		}
+		if clause != "" {
+			m[MetaSCCClause] = clause
+			if title != "" {
+				m[MetaSCCClauseTitle] = title
+			}
+		}
+		if len(modules) > 0 {
+			m[MetaSCCModules] = strings.Join(modules, ",")
+		}
+		sections = append(sections, Section{Text: body, Metadata: m})
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			current = append(current, line)
+			continue
+		}
+		if m := sccModuleRe.FindStringSubmatch(trimmed); m != nil {
+			// Consecutive module headings introduce text common to all of them
+			if !inModuleHeadings {
+				flush()
+				modules = nil
+				inModuleHeadings = true
+			}
+			modules = append(modules, sccModuleNumbers[m[1]])
+			current = append(current, line)
+			continue
+		}
+		inModuleHeadings = false
+
+		m := sccSectionRe.FindStringSubmatch(trimmed)
+		if m != nil {
+			flush()
+			sccSection, clause, title, modules = m[1], "", "", nil
+		} else if m = sccClauseRe.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			clause, title, modules = m[1], nextTitle(lines[i+1:]), nil
+		} else if m = sccAnnexRe.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			sccSection, clause, title, modules = "", "Annex "+m[1], nextTitle(lines[i+1:]), nil
+		} else if trimmed == "APPENDIX" {
+			// The explanatory note on the annexes belongs to no clause
+			flush()
+			sccSection, clause, title, modules = "", "", "", nil
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return sections
+}
+
+// nextTitle returns the first non-empty line of lines if it reads as a
+// heading title rather than the start of the text
+func nextTitle(lines []string) string {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > maxSCCTitleLen || strings.HasSuffix(line, ".") || sccModuleRe.MatchString(line) {
+			return ""
+		}
+		// "A. LIST OF PARTIES" titles an annex by its first part
+		return line
+	}
+	return ""
+}
+
+// SCCClauseNumber normalizes a reference to a clause or annex of the
+// standard contractual clauses, such as "Clause 14", "8.5" or "annex 2", to
+// the form recorded in the metadata: "14", "8" or "Annex II"
+func SCCClauseNumber(s string) (string, bool) {
+	s = strings.Join(strings.Fields(s), " ")
+	if m := sccClauseNumberRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > 18 {
+			return "", false
+		}
+		return strconv.Itoa(n), true
+	}
+	if m := sccAnnexNumberRe.FindStringSubmatch(s); m != nil {
+		numeral := strings.ToUpper(m[1])
+		switch numeral {
+		case "1":
+			numeral = "I"
+		case "2":
+			numeral = "II"
+		case "3":
+			numeral = "III"
+		}
+		return "Annex " + numeral, true
+	}
+	return "", false
+}
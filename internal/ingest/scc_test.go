@@ -0,0 +1,126 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+const sccFixture = `ANNEX
+STANDARD CONTRACTUAL CLAUSES
+SECTION I
+Clause 1
+Purpose and scope
+(a) The purpose of these standard contractual clauses is to ensure compliance with the requirements of Regulation (EU) 2016/679.
+Clause 7 – Optional
+Docking clause
+(a) An entity that is not a Party to these Clauses may accede to these Clauses.
+SECTION II – OBLIGATIONS OF THE PARTIES
+Clause 8
+Data protection safeguards
+The data exporter warrants that it has used reasonable efforts to determine that the data importer is able to satisfy its obligations.
+
+MODULE ONE: Transfer controller to controller
+8.1 Purpose limitation
+The data importer shall process the personal data only for the specific purpose(s) of the transfer.
+MODULE TWO: Transfer controller to processor
+MODULE THREE: Transfer processor to processor
+8.1 Instructions
+The data importer shall process the personal data only on documented instructions from the data exporter.
+Clause 9
+Use of sub-processors
+MODULE TWO: Transfer controller to processor
+(a) OPTION 1: SPECIFIC PRIOR AUTHORISATION The data importer shall not sub-contract any of its processing activities.
+APPENDIX
+EXPLANATORY NOTE:
+It must be possible to clearly distinguish the information applicable to each transfer or category of transfers.
+ANNEX I
+A. LIST OF PARTIES
+Data exporter(s): Name, address, contact person.
+`
+
+func TestSplitSCC(t *testing.T) {
+	sections := splitSCC(Section{Text: sccFixture, Metadata: map[string]string{"source": "scc.txt"}})
+	type part struct{ section, clause, title, modules string }
+	var got []part
+	for _, s := range sections {
+		if s.Metadata["source"] != "scc.txt" {
+			t.Errorf("Expected the section metadata to be kept, got %v", s.Metadata)
+		}
+		got = append(got, part{s.Metadata[MetaSCCSection], s.Metadata[MetaSCCClause], s.Metadata[MetaSCCClauseTitle], s.Metadata[MetaSCCModules]})
+	}
+	want := []part{
+		{"", "", "", ""},
+		{"I", "", "", ""},
+		{"I", "1", "Purpose and scope", ""},
+		{"I", "7", "Docking clause", ""},
+		{"II", "", "", ""},
+		{"II", "8", "Data protection safeguards", ""},
+		{"II", "8", "Data protection safeguards", "1"},
+		{"II", "8", "Data protection safeguards", "2,3"},
+		{"II", "9", "Use of sub-processors", ""},
+		{"II", "9", "Use of sub-processors", "2"},
+		{"", "", "", ""},
+		{"", "Annex I", "A. LIST OF PARTIES", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitSCC = %+v, want %+v", got, want)
+	}
+}
+
+func TestSCCClauseNumber(t *testing.T) {
+	for in, want := range map[string]string{
+		"8":          "8",
+		"Clause 14":  "14",
+		"clause 8.5": "8",
+		"Annex II":   "Annex II",
+		"annex 3":    "Annex III",
+		"ANNEX i":    "Annex I",
+	} {
+		if got, ok := SCCClauseNumber(in); !ok || got != want {
+			t.Errorf("SCCClauseNumber(%q) = %q, %v, want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "19", "Clause 0", "Annex IV", "Article 46"} {
+		if got, ok := SCCClauseNumber(in); ok {
+			t.Errorf("SCCClauseNumber(%q) = %q, expected no clause", in, got)
+		}
+	}
+}
+
+func TestIngestSCCProfile(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Profile = ProfileSCC
+	config.ChunkSize = 200
+	config.ChunkOverlap = 0
+	if err := NewWithEmbedder(database, config, &fakeEmbedder{}).IngestText(sccFixture); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	clause, err := database.Clause("8", "1", SCCCollection)
+	if err != nil || clause == nil {
+		t.Fatalf("Clause = %+v, %v", clause, err)
+	}
+	if clause.Title != "Data protection safeguards" || !strings.Contains(clause.Text, "reasonable efforts") ||
+		!strings.Contains(clause.Text, "8.1 Purpose limitation") || strings.Contains(clause.Text, "documented instructions") {
+		t.Errorf("Expected the common text and Module One of clause 8, got %+v", clause)
+	}
+
+	results, err := database.SearchTrigrams("documented instructions", 1, db.SearchOptions{Collection: SCCCollection})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("SearchTrigrams = %+v, %v", results, err)
+	}
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil || doc == nil {
+		t.Fatalf("GetDocument = %v, %v", doc, err)
+	}
+	// The clauses cite Regulation (EU) 2016/679 but are not part of it
+	if _, ok := doc.Metadata[MetaArticle]; ok {
+		t.Errorf("Expected no article metadata, got %v", doc.Metadata)
+	}
+}
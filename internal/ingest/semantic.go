@@ -0,0 +1,123 @@
+package ingest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// StrategySemantic groups sentences into chunks, starting a new chunk where
+// the embeddings of neighbouring sentences drift furthest apart
+const StrategySemantic = "semantic"
+
+// DefaultSemanticPercentile is the percentile of the distances between
+// neighbouring sentences above which a new chunk starts
+const DefaultSemanticPercentile = 95
+
+// embeddingChunker is a Chunker that embeds text while chunking, using the
+// embedder of the ingestion
+type embeddingChunker interface {
+	setEmbedder(e Embedder)
+}
+
+// semanticChunker breaks text at topic shifts. Each sentence is embedded
+// together with its neighbours to smooth out short sentences, and a chunk
+// ends after every sentence whose distance to the next one exceeds the
+// configured percentile of all distances. Groups of sentences too large for
+// a chunk are packed into several.
+type semanticChunker struct {
+	embedder   Embedder
+	sentences  *spanChunker
+	percentile float64
+}
+
+func newSemanticChunker(config Config) (Chunker, error) {
+	percentile := config.SemanticPercentile
+	if percentile == 0 {
+		percentile = DefaultSemanticPercentile
+	}
+	if percentile < 0 || percentile > 100 {
+		return nil, fmt.Errorf("semantic percentile must be between 0 and 100, got %v", percentile)
+	}
+	sentences, err := newSpanChunker(config, splitSentences, splitWords)
+	if err != nil {
+		return nil, err
+	}
+	return &semanticChunker{sentences: sentences.(*spanChunker), percentile: percentile}, nil
+}
+
+func (c *semanticChunker) setEmbedder(e Embedder) {
+	c.embedder = e
+}
+
+// Chunk splits text into topically coherent chunks
+func (c *semanticChunker) Chunk(text string) ([]string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	root := appendTrimmed(nil, text, span{0, len(text)})
+	if len(root) == 0 {
+		return nil, nil
+	}
+	sentences := splitSentences(text, root[0])
+	if len(sentences) < 3 {
+		return c.sentences.Chunk(text)
+	}
+	if c.embedder == nil {
+		return nil, fmt.Errorf("semantic chunking needs an embedder")
+	}
+
+	n := len(sentences)
+	windows := make([]string, n)
+	for i := range sentences {
+		lo, hi := i-1, i+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi >= n {
+			hi = n - 1
+		}
+		windows[i] = text[sentences[lo].start:sentences[hi].end]
+	}
+	embeddings, err := c.embedder.EmbedDocuments(windows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed sentences: %w", err)
+	}
+	if len(embeddings) != n {
+		return nil, fmt.Errorf("got %d embeddings for %d sentences", len(embeddings), n)
+	}
+
+	distances := make([]float64, n-1)
+	for i := range distances {
+		distances[i] = 1 - db.CosineSimilarity(embeddings[i], embeddings[i+1])
+	}
+	threshold := percentileOf(distances, c.percentile)
+
+	var chunks []string
+	first := 0
+	for i := 0; i < n; i++ {
+		if i < n-1 && distances[i] <= threshold {
+			continue
+		}
+		var pieces []span
+		for _, s := range sentences[first : i+1] {
+			pieces = append(pieces, c.sentences.split(text, s, 0)...)
+		}
+		chunks = append(chunks, c.sentences.pack(text, pieces)...)
+		first = i + 1
+	}
+	return chunks, nil
+}
+
+// percentileOf returns the p-th percentile of values, interpolating
+// between the closest ranks
+func percentileOf(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(rank)
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
@@ -0,0 +1,86 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+)
+
+// topicEmbedder embeds text as its counts of two topic words
+type topicEmbedder struct {
+	fakeEmbedder
+}
+
+func (*topicEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = []float32{float32(strings.Count(text, "retention")), float32(strings.Count(text, "request"))}
+	}
+	return embeddings, nil
+}
+
+func TestSemanticChunker(t *testing.T) {
+	retention := "Retention periods are documented. The retention schedule is reviewed yearly. Each retention rule names an owner."
+	requests := "Every access request is logged. A request is answered within one month. Refusing a request needs reasons."
+
+	config := DefaultConfig()
+	config.Strategy = StrategySemantic
+	chunker := newTestChunker(t, config)
+	chunker.(embeddingChunker).setEmbedder(&topicEmbedder{})
+
+	chunks := mustChunk(t, chunker, retention+" "+requests)
+	if len(chunks) != 2 || chunks[0] != retention || chunks[1] != requests {
+		t.Errorf("Expected a break at the topic shift, got %q", chunks)
+	}
+
+	// Groups larger than a chunk are packed into several
+	config.ChunkSize = 80
+	config.ChunkOverlap = 0
+	chunker = newTestChunker(t, config)
+	chunker.(embeddingChunker).setEmbedder(&topicEmbedder{})
+	for _, chunk := range mustChunk(t, chunker, retention+" "+requests) {
+		if strings.Contains(chunk, "retention") && strings.Contains(chunk, "request") {
+			t.Errorf("Chunk spans the topic shift: %q", chunk)
+		}
+		if len(chunk) > 80 {
+			t.Errorf("Chunk longer than 80 characters: %q", chunk)
+		}
+	}
+}
+
+func TestSemanticChunkerNeedsEmbedder(t *testing.T) {
+	config := DefaultConfig()
+	config.Strategy = StrategySemantic
+	if _, err := newTestChunker(t, config).Chunk("One. Two. Three. Four."); err == nil {
+		t.Error("Expected an error without an embedder")
+	}
+
+	config.SemanticPercentile = 120
+	if _, err := NewChunker(StrategySemantic, config); err == nil {
+		t.Error("Expected an out-of-range percentile to be rejected")
+	}
+}
+
+func TestIngestSemanticChunking(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Strategy = StrategySemantic
+	text := "Retention periods are documented. The retention schedule is reviewed yearly. " +
+		"Every access request is logged. A request is answered within one month."
+	if err := NewWithEmbedder(database, config, &topicEmbedder{}).IngestText(text); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+	if n := countChunks(t, database); n != 2 {
+		t.Errorf("Expected 2 chunks, got %d", n)
+	}
+}
+
+func TestPercentileOf(t *testing.T) {
+	values := []float64{0.4, 0, 0.1, 0.2, 0.3}
+	for _, tt := range []struct{ p, want float64 }{{0, 0}, {50, 0.2}, {100, 0.4}, {95, 0.38}} {
+		if got := percentileOf(values, tt.p); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("percentileOf(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
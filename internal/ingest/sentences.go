@@ -0,0 +1,80 @@
+package ingest
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// abbreviations end in a full stop without ending the sentence. Legal texts
+// cite provisions as "Art. 6 para. 1 lit. a", so those are covered as well
+// as the common English ones.
+var abbreviations = map[string]bool{
+	"art": true, "arts": true, "para": true, "paras": true, "subpara": true,
+	"lit": true, "no": true, "nos": true, "p": true, "pp": true, "sec": true,
+	"ch": true, "cf": true, "e.g": true, "i.e": true, "etc": true, "viz": true,
+	"vs": true, "incl": true, "approx": true, "ca": true, "op": true, "cit": true,
+	"ibid": true, "vol": true, "ed": true, "eds": true, "al": true, "fig": true,
+	"ref": true, "reg": true, "dir": true, "oj": true, "mr": true, "mrs": true,
+	"ms": true, "dr": true, "prof": true, "inc": true, "ltd": true, "co": true,
+	"corp": true, "u.s": true, "n.b": true,
+}
+
+// closingPunctuation may follow a sentence terminator
+const closingPunctuation = `)]"'’”»`
+
+// endsSentence reports whether a sentence (or a line) ends right before
+// byte offset i of text. A full stop after an abbreviation, an initial or a
+// paragraph number such as "1." does not end a sentence, nor does one
+// followed by a lower-case word.
+func endsSentence(text string, i int) bool {
+	if i <= 0 || i > len(text) {
+		return false
+	}
+	before := text[:i]
+	if strings.HasSuffix(before, "\n") {
+		return true
+	}
+	if i < len(text) {
+		next, _ := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsSpace(next) {
+			return false
+		}
+	}
+	before = strings.TrimRight(before, closingPunctuation)
+	last, _ := utf8.DecodeLastRuneInString(before)
+	switch last {
+	case '!', '?', ';':
+		return true
+	case '.':
+	default:
+		return false
+	}
+
+	// The word carrying the full stop, e.g. "Art", "e.g" or "1"
+	lineStart := strings.LastIndex(before, "\n") + 1
+	wordStart := 0
+	if j := strings.LastIndexFunc(before, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '('
+	}); j >= 0 {
+		_, size := utf8.DecodeRuneInString(before[j:])
+		wordStart = j + size
+	}
+	word := strings.ToLower(strings.TrimSuffix(before[wordStart:], "."))
+
+	if abbreviations[word] {
+		return false
+	}
+	if utf8.RuneCountInString(word) == 1 && unicode.IsLetter([]rune(word)[0]) {
+		return false
+	}
+	if wordStart == lineStart && word != "" && strings.Trim(word, "0123456789") == "" {
+		return false
+	}
+
+	rest := strings.TrimLeftFunc(text[i:], unicode.IsSpace)
+	if next, _ := utf8.DecodeRuneInString(rest); unicode.IsLower(next) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEndsSentence(t *testing.T) {
+	tests := []struct {
+		text string // "|" marks the position tested
+		want bool
+	}{
+		{"Processing shall be lawful.| Consent is required.", true},
+		{"Is consent required?| Yes.", true},
+		{"the data are no longer necessary;| the data subject", true},
+		{"Line one|\nLine two", false},
+		{"Line one\n|Line two", true},
+		{"(see the Commission's guidance.)| Next", true},
+		{"Pursuant to Art.| 6 para. 1", false},
+		{"Art. 6 para.| 1 lit. a", false},
+		{"special categories, e.g.| health data", false},
+		{"special categories, i.e.| Health data", false},
+		{"published in OJ L 119, p.| 1", false},
+		{"J.| Smith wrote", false},
+		{"1.| This Regulation lays down rules", false},
+		{"It applies from 2018.| The Board", true},
+		{"records, logs, etc.| and backups", false},
+		{"The end.|", true},
+		{"No break.|here", false},
+	}
+
+	for _, tt := range tests {
+		i := strings.Index(tt.text, "|")
+		text := tt.text[:i] + tt.text[i+1:]
+		if got := endsSentence(text, i); got != tt.want {
+			t.Errorf("endsSentence(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestChunkTextSkipsAbbreviations(t *testing.T) {
+	config := DefaultConfig()
+	config.ChunkSize = 100
+	config.ChunkOverlap = 0
+	chunker := newTestChunker(t, config)
+
+	text := "Processing needs a legal basis under Article 6 GDPR. " +
+		"Controllers keep records as set out in Art. 30 para. 1 GDPR for every processing operation."
+	chunks := mustChunk(t, chunker, text)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %d", len(chunks))
+	}
+	if chunks[0] != "Processing needs a legal basis under Article 6 GDPR." {
+		t.Errorf("First chunk should end at the sentence, got %q", chunks[0])
+	}
+}
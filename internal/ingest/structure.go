@@ -0,0 +1,121 @@
+package ingest
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// "Article 17", "## Article 17" or "Article 17 – Right to erasure"
+	articleHeadingRe = regexp.MustCompile(`^#*\s*Article\s+(\d+)\s*(?:[-–—:]\s*(.+))?$`)
+	// "Recital 26", "Recital (26)" or, within the preamble, "(26) Text..."
+	recitalHeadingRe = regexp.MustCompile(`^#*\s*Recital\s+\(?(\d+)\)?\s*$`)
+	recitalNumberRe  = regexp.MustCompile(`^\((\d+)\)(?:\s|$)`)
+)
+
+// maxArticleTitleLen bounds the line after an article heading that is taken
+// as its title
+const maxArticleTitleLen = 120
+
+// splitStructure splits a section at "Article N" and recital boundaries.
+// Each resulting section carries the article number (and title when known)
+// or the recital number on top of the metadata of the original section.
+// Numbered paragraphs such as "(1) 'personal data' means" are only treated
+// as recitals before the first article.
+func splitStructure(section Section) []Section {
+	lines := strings.Split(strings.ReplaceAll(section.Text, "\r\n", "\n"), "\n")
+
+	var sections []Section
+	var current []string
+	var meta map[string]string
+	seenArticle := false
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(current, "\n"))
+		if text != "" {
+			m := make(map[string]string, len(section.Metadata)+len(meta))
+			for k, v := range section.Metadata {
+				m[k] = v
+			}
+			for k, v := range meta {
+				m[k] = v
+			}
+			sections = append(sections, Section{Text: text, Metadata: m})
+		}
+		current = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		if m := articleHeadingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			seenArticle = true
+			meta = map[string]string{MetaArticle: m[1]}
+			title := strings.TrimSpace(m[2])
+			if title == "" {
+				title = articleTitle(lines, i+1)
+			}
+			if title != "" {
+				meta[MetaArticleTitle] = title
+			}
+			current = append(current, lines[i])
+			continue
+		}
+
+		// Chapter and section headings belong to neither neighbouring
+		// provision, nor does "HAVE ADOPTED THIS REGULATION:" after the recitals
+		if divisionRe.MatchString(line) || (!seenArticle && meta != nil && isUpperHeading(line)) {
+			flush()
+			meta = nil
+		}
+
+		if !seenArticle {
+			m := recitalHeadingRe.FindStringSubmatch(line)
+			if m == nil {
+				m = recitalNumberRe.FindStringSubmatch(line)
+			}
+			if m != nil {
+				flush()
+				meta = map[string]string{MetaRecital: m[1]}
+			}
+		}
+
+		current = append(current, lines[i])
+	}
+	flush()
+
+	return sections
+}
+
+// articleTitle returns the line following an article heading when it looks
+// like a title rather than the first paragraph
+func articleTitle(lines []string, i int) string {
+	for ; i < len(lines); i++ {
+		line := strings.TrimLeft(strings.TrimSpace(lines[i]), "# ")
+		if line == "" {
+			continue
+		}
+		if len(line) > maxArticleTitleLen || strings.HasSuffix(line, ".") || strings.HasSuffix(line, ":") ||
+			articleHeadingRe.MatchString(line) || (line[0] >= '0' && line[0] <= '9') || line[0] == '(' {
+			return ""
+		}
+		return line
+	}
+	return ""
+}
+
+// isUpperHeading reports whether line has letters and all of them are upper case
+func isUpperHeading(line string) bool {
+	hasLetter := false
+	for _, r := range line {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
@@ -0,0 +1,110 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+const testRegulationText = `REGULATION (EU) 2016/679 OF THE EUROPEAN PARLIAMENT AND OF THE COUNCIL
+Whereas:
+(1) The protection of natural persons in relation to the processing of personal data is a fundamental right.
+(2) The principles of the protection should respect their fundamental rights.
+HAVE ADOPTED THIS REGULATION:
+CHAPTER I
+General provisions
+Article 1
+Subject-matter and objectives
+1. This Regulation lays down rules.
+2. This Regulation protects fundamental rights.
+Article 4
+Definitions
+For the purposes of this Regulation:
+(1) 'personal data' means any information relating to an identified person;
+(2) 'processing' means any operation performed on personal data;
+## Article 17 – Right to erasure
+The data subject shall have the right to obtain erasure.`
+
+func TestSplitStructure(t *testing.T) {
+	sections := splitStructure(Section{Text: testRegulationText, Metadata: map[string]string{"source": "gdpr"}})
+
+	expected := []struct {
+		meta  map[string]string
+		first string
+	}{
+		{map[string]string{}, "REGULATION (EU) 2016/679"},
+		{map[string]string{MetaRecital: "1"}, "(1) The protection"},
+		{map[string]string{MetaRecital: "2"}, "(2) The principles"},
+		{map[string]string{}, "HAVE ADOPTED THIS REGULATION:"},
+		{map[string]string{}, "CHAPTER I"},
+		{map[string]string{MetaArticle: "1", MetaArticleTitle: "Subject-matter and objectives"}, "Article 1"},
+		{map[string]string{MetaArticle: "4", MetaArticleTitle: "Definitions"}, "Article 4"},
+		{map[string]string{MetaArticle: "17", MetaArticleTitle: "Right to erasure"}, "## Article 17"},
+	}
+
+	if len(sections) != len(expected) {
+		for _, s := range sections {
+			t.Logf("%v %q", s.Metadata, s.Text)
+		}
+		t.Fatalf("Expected %d sections, got %d", len(expected), len(sections))
+	}
+	for i, e := range expected {
+		s := sections[i]
+		if !strings.HasPrefix(s.Text, e.first) {
+			t.Errorf("Section %d starts with %q, want %q", i, s.Text, e.first)
+		}
+		if s.Metadata["source"] != "gdpr" {
+			t.Errorf("Section %d lost the source metadata: %v", i, s.Metadata)
+		}
+		for _, key := range []string{MetaArticle, MetaArticleTitle, MetaRecital} {
+			if s.Metadata[key] != e.meta[key] {
+				t.Errorf("Section %d %s = %q, want %q", i, key, s.Metadata[key], e.meta[key])
+			}
+		}
+	}
+
+	// Definitions numbered (1), (2) inside Article 4 are not recitals
+	if !strings.Contains(sections[6].Text, "'processing' means") {
+		t.Errorf("Article 4 was split at its numbered definitions: %q", sections[6].Text)
+	}
+}
+
+func TestStructureChunkingNeverCrossesArticles(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Strategy = StrategyStructure
+	config.ChunkSize = 2000
+	ingester := New(database, config)
+	if err := ingester.IngestText(testRegulationText); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	results, err := database.SearchTrigrams("right to obtain erasure", 1, db.SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchTrigrams failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	doc, err := database.GetDocument(results[0].ID)
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc.Metadata[MetaArticle] != "17" {
+		t.Errorf("Unexpected metadata: %v", doc.Metadata)
+	}
+	if strings.Contains(doc.Chunk, "Definitions") {
+		t.Errorf("Chunk spans several articles: %q", doc.Chunk)
+	}
+}
+
+func TestSplitStructureEmptyHeadingLine(t *testing.T) {
+	sections := splitStructure(Section{Text: "Article 5\n#\nBody of the article."})
+	if len(sections) != 1 || sections[0].Metadata[MetaArticle] != "5" || sections[0].Metadata[MetaArticleTitle] != "" {
+		t.Errorf("Expected one untitled Article 5 section, got %+v", sections)
+	}
+}
@@ -0,0 +1,48 @@
+package ingest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Summarizer summarizes provisions of the regulation from their text
+type Summarizer interface {
+	Summarize(title, text string) (string, error)
+}
+
+// SummarizePrompt instructs a chat model to summarize provisions from the
+// text given alone, so every statement of the summary can be traced back to
+// it
+const SummarizePrompt = `You summarize provisions of the GDPR for data protection practitioners.
+Use only the text you are given: do not add obligations, exceptions, case law or guidance it does not contain,
+and do not fill gaps from memory. Refer to articles and paragraphs by their numbers, e.g. "Art. 17(3)".
+Write a short overview followed by bullet points of the key rules, conditions and exceptions.
+If the text is incomplete or cut off, say so instead of guessing.`
+
+// SummaryRequest is the message asking for a summary of a provision
+func SummaryRequest(title, text string) string {
+	return fmt.Sprintf("Summarize %s from the following text.\n\n%s", title, text)
+}
+
+// NewLLMSummarizer creates a summarizer asking a chat model through an
+// OpenAI compatible chat completions API
+func NewLLMSummarizer(endpoint EndpointConfig) (Summarizer, error) {
+	endpoint, err := chatEndpoint(endpoint, "LLM summarization")
+	if err != nil {
+		return nil, err
+	}
+	return llmSummarizer(endpoint), nil
+}
+
+type llmSummarizer EndpointConfig
+
+func (s llmSummarizer) Summarize(title, text string) (string, error) {
+	summary, err := chatCompletion(EndpointConfig(s), SummarizePrompt, SummaryRequest(title, text))
+	if err != nil {
+		return "", err
+	}
+	if summary = strings.TrimSpace(summary); summary == "" {
+		return "", fmt.Errorf("chat response has no summary")
+	}
+	return summary, nil
+}
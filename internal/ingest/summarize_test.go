@@ -0,0 +1,45 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLLMSummarizer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) != 2 || req.Messages[0].Content != SummarizePrompt || !strings.Contains(req.Messages[1].Content, "Article 17 - Right to erasure") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": "  Art. 17(1) grants erasure.\n"}}},
+		})
+	}))
+	defer srv.Close()
+
+	summarizer, err := NewLLMSummarizer(EndpointConfig{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewLLMSummarizer failed: %v", err)
+	}
+	summary, err := summarizer.Summarize("Article 17 - Right to erasure", "1. The data subject shall have the right to obtain erasure.")
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if summary != "Art. 17(1) grants erasure." {
+		t.Errorf("Expected the trimmed summary, got %q", summary)
+	}
+
+	if _, err := NewLLMSummarizer(EndpointConfig{}); err == nil || !strings.Contains(err.Error(), "API key") {
+		t.Errorf("Expected OpenAI without a key to be rejected, got %v", err)
+	}
+}
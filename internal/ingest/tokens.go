@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// DefaultTokenizer is the tiktoken encoding used by OpenAI embedding models
+const DefaultTokenizer = "cl100k_base"
+
+var setBpeLoader sync.Once
+
+// getEncoding returns the tiktoken encoding with the given name (such as
+// "cl100k_base") or the encoding of the given model (such as "gpt-4o").
+// The vocabularies are embedded, so no download is needed.
+func getEncoding(name string) (*tiktoken.Tiktoken, error) {
+	setBpeLoader.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	})
+
+	if enc, err := tiktoken.GetEncoding(name); err == nil {
+		return enc, nil
+	}
+	enc, err := tiktoken.EncodingForModel(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown tokenizer %q", name)
+	}
+	return enc, nil
+}
+
+// CountTokens returns the number of tokens text encodes to with the given
+// tiktoken encoding or model name
+func CountTokens(tokenizer, text string) (int, error) {
+	enc, err := getEncoding(tokenizer)
+	if err != nil {
+		return 0, err
+	}
+	return len(enc.EncodeOrdinary(text)), nil
+}
+
+// tokenChunker splits text into overlapping windows measured in tokens
+type tokenChunker struct {
+	enc     *tiktoken.Tiktoken
+	size    int
+	overlap int
+}
+
+func newTokenChunker(tokenizer string, size, overlap int) (*tokenChunker, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	enc, err := getEncoding(tokenizer)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenChunker{enc: enc, size: size, overlap: overlap}, nil
+}
+
+// Chunk splits text into chunks of at most size tokens overlapping by
+// overlap tokens, preferring to break after a sentence, then between words.
+// Chunks are cut from the original text, so they never split a character.
+func (c *tokenChunker) Chunk(text string) ([]string, error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil, nil
+	}
+
+	// offsets[i] is the byte offset at which token i starts
+	tokens := c.enc.EncodeOrdinary(text)
+	offsets := make([]int, len(tokens)+1)
+	for i, t := range tokens {
+		offsets[i+1] = offsets[i] + len(c.enc.Decode([]int{t}))
+	}
+	n := len(tokens)
+
+	// boundary reports whether a chunk may end before token i
+	boundary := func(i int) bool {
+		return i == n || utf8.RuneStart(text[offsets[i]])
+	}
+
+	var chunks []string
+	for start := 0; start < n; {
+		end := start + c.size
+		if end > n {
+			end = n
+		}
+
+		if end < n {
+			// Look for a sentence boundary, then a word boundary
+			found := false
+			for i := end; i > start+c.size/2; i-- {
+				if boundary(i) && endsSentence(text, offsets[i]) {
+					end, found = i, true
+					break
+				}
+			}
+			if !found {
+				for i := end; i > start+c.size/2; i-- {
+					if text[offsets[i]] == ' ' {
+						end, found = i, true
+						break
+					}
+				}
+			}
+			for !found && end > start+1 && !boundary(end) {
+				end--
+			}
+		}
+
+		// Trimming can change how the chunk edges tokenize; shrink the chunk
+		// until it fits the limit on its own
+		chunk := strings.TrimSpace(text[offsets[start]:offsets[end]])
+		for end > start+1 && len(c.enc.EncodeOrdinary(chunk)) > c.size {
+			end--
+			for end > start+1 && !boundary(end) {
+				end--
+			}
+			chunk = strings.TrimSpace(text[offsets[start]:offsets[end]])
+		}
+		if chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end >= n {
+			break
+		}
+
+		// Move start position with overlap, always making progress. The
+		// overlap starts at a sentence, or else a word, so it tokenizes the
+		// same way again.
+		next := end - c.overlap
+		if next <= start {
+			next = end
+		}
+		start = end
+		for i := next; i < end; i++ {
+			if !boundary(i) || !isSpaceByte(text[offsets[i]]) {
+				continue
+			}
+			if start == end {
+				start = i
+			}
+			if endsSentence(text, offsets[i]) {
+				start = i
+				break
+			}
+		}
+	}
+
+	return chunks, nil
+}
+
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t'
+}
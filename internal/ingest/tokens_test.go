@@ -0,0 +1,95 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountTokens(t *testing.T) {
+	n, err := CountTokens(DefaultTokenizer, "hello world")
+	if err != nil {
+		t.Fatalf("CountTokens failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 tokens, got %d", n)
+	}
+
+	// Model names resolve to their encoding
+	if _, err := CountTokens("gpt-4o", "hello"); err != nil {
+		t.Errorf("CountTokens with model name failed: %v", err)
+	}
+	if _, err := CountTokens("no-such-encoding", "hello"); err == nil {
+		t.Error("Expected error for unknown tokenizer")
+	}
+}
+
+func TestTokenChunker(t *testing.T) {
+	chunker, err := newTokenChunker(DefaultTokenizer, 50, 10)
+	if err != nil {
+		t.Fatalf("newTokenChunker failed: %v", err)
+	}
+
+	text := strings.Repeat("The controller shall implement appropriate technical measures. ", 20)
+	chunks := mustChunk(t, chunker, text)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %d", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		n, _ := CountTokens(DefaultTokenizer, chunk)
+		if n > 50 {
+			t.Errorf("Chunk %d has %d tokens, limit is 50", i, n)
+		}
+		if i < len(chunks)-1 && !strings.HasSuffix(chunk, ".") {
+			t.Errorf("Chunk %d does not end at a sentence: %q", i, chunk)
+		}
+	}
+
+	// Consecutive chunks overlap
+	if !strings.Contains(chunks[0], chunks[1][:20]) {
+		t.Errorf("Expected chunks to overlap:\n%q\n%q", chunks[0], chunks[1])
+	}
+}
+
+func TestTokenChunkerKeepsCharactersWhole(t *testing.T) {
+	chunker, err := newTokenChunker(DefaultTokenizer, 7, 2)
+	if err != nil {
+		t.Fatalf("newTokenChunker failed: %v", err)
+	}
+
+	text := strings.Repeat("Datenschutz-Grundverordnung für Betroffene – Löschung ", 10)
+	for _, chunk := range mustChunk(t, chunker, text) {
+		if strings.ContainsRune(chunk, '�') {
+			t.Errorf("Chunk splits a character: %q", chunk)
+		}
+	}
+}
+
+func TestIngestWithTokenizer(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	config := DefaultConfig()
+	config.Tokenizer = DefaultTokenizer
+	config.ChunkSize = 30
+	config.ChunkOverlap = 5
+	ingester := New(database, config)
+
+	text := strings.Repeat("Personal data shall be processed lawfully, fairly and transparently. ", 10)
+	if err := ingester.IngestText(text); err != nil {
+		t.Fatalf("IngestText failed: %v", err)
+	}
+
+	count, err := database.GetMetadata("chunk_count")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if count == "1" || count == "" {
+		t.Errorf("Expected the text to be split by tokens, got chunk_count %q", count)
+	}
+
+	config.Tokenizer = "bogus"
+	if err := New(database, config).IngestText(text); err == nil {
+		t.Error("Expected error for unknown tokenizer")
+	}
+}
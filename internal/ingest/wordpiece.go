@@ -0,0 +1,201 @@
+package ingest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxWordPieceChars is the longest word WordPiece splits; longer words
+// become [UNK] as in the reference BERT tokenizer
+const maxWordPieceChars = 100
+
+// wordPieceTokenizer is the uncased BERT WordPiece tokenizer used by
+// sentence-transformer models such as all-MiniLM-L6-v2
+type wordPieceTokenizer struct {
+	vocab map[string]int64
+	unkID int64
+	clsID int64
+	sepID int64
+}
+
+// loadWordPiece reads a vocab.txt file with one token per line, the line
+// number being the token ID
+func loadWordPiece(path string) (*wordPieceTokenizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vocabulary: %w", err)
+	}
+	defer f.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		tokens = append(tokens, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vocabulary: %w", err)
+	}
+	return newWordPiece(tokens)
+}
+
+// newWordPiece creates a tokenizer from a vocabulary in ID order
+func newWordPiece(tokens []string) (*wordPieceTokenizer, error) {
+	t := &wordPieceTokenizer{vocab: make(map[string]int64, len(tokens))}
+	for i, token := range tokens {
+		t.vocab[token] = int64(i)
+	}
+
+	for _, special := range []struct {
+		token string
+		id    *int64
+	}{{"[UNK]", &t.unkID}, {"[CLS]", &t.clsID}, {"[SEP]", &t.sepID}} {
+		id, ok := t.vocab[special.token]
+		if !ok {
+			return nil, fmt.Errorf("vocabulary has no %s token", special.token)
+		}
+		*special.id = id
+	}
+	return t, nil
+}
+
+// encode tokenizes text into model input IDs wrapped in [CLS] and [SEP],
+// truncated to maxLen tokens, along with the matching attention mask
+func (t *wordPieceTokenizer) encode(text string, maxLen int) (ids, mask []int64) {
+	ids = append(ids, t.clsID)
+	for _, token := range t.tokenize(text) {
+		if len(ids) >= maxLen-1 {
+			break
+		}
+		ids = append(ids, t.vocab[token])
+	}
+	ids = append(ids, t.sepID)
+
+	mask = make([]int64, len(ids))
+	for i := range mask {
+		mask[i] = 1
+	}
+	return ids, mask
+}
+
+// encodePair tokenizes a query and a document for a cross-encoder as
+// [CLS] query [SEP] document [SEP], with token type 0 for the query part and
+// 1 for the document. The document is truncated first to fit maxLen tokens;
+// the query keeps at least half of them.
+func (t *wordPieceTokenizer) encodePair(query, document string, maxLen int) (ids, typeIDs, mask []int64) {
+	q, d := t.tokenize(query), t.tokenize(document)
+	room := maxLen - 3
+	if room < 0 {
+		room = 0
+	}
+	if len(q)+len(d) > room {
+		keep := room - len(d)
+		if keep < room-room/2 {
+			keep = room - room/2
+		}
+		if len(q) > keep {
+			q = q[:keep]
+		}
+		if len(d) > room-len(q) {
+			d = d[:room-len(q)]
+		}
+	}
+
+	ids = append(ids, t.clsID)
+	for _, token := range q {
+		ids = append(ids, t.vocab[token])
+	}
+	ids = append(ids, t.sepID)
+	queryLen := len(ids)
+	for _, token := range d {
+		ids = append(ids, t.vocab[token])
+	}
+	ids = append(ids, t.sepID)
+
+	typeIDs = make([]int64, len(ids))
+	mask = make([]int64, len(ids))
+	for i := range ids {
+		if i >= queryLen {
+			typeIDs[i] = 1
+		}
+		mask[i] = 1
+	}
+	return ids, typeIDs, mask
+}
+
+// tokenize splits text into vocabulary tokens: lower-cased, accents
+// stripped, split at whitespace and punctuation, then into the longest
+// matching word pieces
+func (t *wordPieceTokenizer) tokenize(text string) []string {
+	var tokens []string
+	for _, word := range basicTokenize(text) {
+		tokens = append(tokens, t.wordPieces(word)...)
+	}
+	return tokens
+}
+
+// wordPieces greedily splits a word into the longest vocabulary entries,
+// marking continuations with "##"
+func (t *wordPieceTokenizer) wordPieces(word string) []string {
+	runes := []rune(word)
+	if len(runes) > maxWordPieceChars {
+		return []string{"[UNK]"}
+	}
+
+	var pieces []string
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		piece := ""
+		for ; end > start; end-- {
+			candidate := string(runes[start:end])
+			if start > 0 {
+				candidate = "##" + candidate
+			}
+			if _, ok := t.vocab[candidate]; ok {
+				piece = candidate
+				break
+			}
+		}
+		if piece == "" {
+			return []string{"[UNK]"}
+		}
+		pieces = append(pieces, piece)
+		start = end
+	}
+	return pieces
+}
+
+// basicTokenize lower-cases text, strips accents and splits it into words
+// and single punctuation marks. CJK ideographs are split one per token.
+func basicTokenize(text string) []string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		switch {
+		case r == 0 || r == unicode.ReplacementChar || (unicode.IsControl(r) && !unicode.IsSpace(r)):
+			continue
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent left by NFD decomposition
+			continue
+		case isBertPunct(r) || unicode.Is(unicode.Han, r):
+			sb.WriteRune(' ')
+			sb.WriteRune(r)
+			sb.WriteRune(' ')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return strings.Fields(sb.String())
+}
+
+// isBertPunct matches BERT's notion of punctuation: all ASCII symbols plus
+// the Unicode punctuation categories
+func isBertPunct(r rune) bool {
+	if (r >= 33 && r <= 47) || (r >= 58 && r <= 64) || (r >= 91 && r <= 96) || (r >= 123 && r <= 126) {
+		return true
+	}
+	return unicode.IsPunct(r)
+}
@@ -0,0 +1,102 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testVocab = []string{
+	"[PAD]", "[UNK]", "[CLS]", "[SEP]", "the", "data", "subject", "'", "s",
+	"right", "to", "era", "##sure", ",", "(", ")", "art", ".", "17", "gdpr",
+	"protection", "privacy", "uber", "ca", "##fe",
+}
+
+func TestWordPieceTokenize(t *testing.T) {
+	tok, err := newWordPiece(testVocab)
+	if err != nil {
+		t.Fatalf("newWordPiece failed: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The data subject's right to erasure", []string{"the", "data", "subject", "'", "s", "right", "to", "era", "##sure"}},
+		{"Art. 17 (GDPR)", []string{"art", ".", "17", "(", "gdpr", ")"}},
+		{"Über  Café", []string{"uber", "ca", "##fe"}},
+		{"pseudonymisation", []string{"[UNK]"}},
+		{"  \t\n", nil},
+	}
+
+	for _, tt := range tests {
+		if got := tok.tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWordPieceEncode(t *testing.T) {
+	tok, err := newWordPiece(testVocab)
+	if err != nil {
+		t.Fatalf("newWordPiece failed: %v", err)
+	}
+
+	ids, mask := tok.encode("right to erasure", 16)
+	if want := []int64{2, 9, 10, 11, 12, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("encode() ids = %v, want %v", ids, want)
+	}
+	if len(mask) != len(ids) {
+		t.Errorf("Mask length %d does not match %d ids", len(mask), len(ids))
+	}
+
+	// Truncation keeps [CLS] and [SEP]
+	ids, _ = tok.encode(strings.Repeat("data ", 50), 8)
+	if len(ids) != 8 || ids[0] != 2 || ids[7] != 3 {
+		t.Errorf("Unexpected truncated ids: %v", ids)
+	}
+}
+
+func TestWordPieceEncodePair(t *testing.T) {
+	tok, err := newWordPiece(testVocab)
+	if err != nil {
+		t.Fatalf("newWordPiece failed: %v", err)
+	}
+
+	ids, typeIDs, mask := tok.encodePair("erasure", "the data subject", 16)
+	if want := []int64{2, 11, 12, 3, 4, 5, 6, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("encodePair() ids = %v, want %v", ids, want)
+	}
+	if want := []int64{0, 0, 0, 0, 1, 1, 1, 1}; !reflect.DeepEqual(typeIDs, want) {
+		t.Errorf("encodePair() type ids = %v, want %v", typeIDs, want)
+	}
+	if len(mask) != len(ids) {
+		t.Errorf("Mask length %d does not match %d ids", len(mask), len(ids))
+	}
+
+	// The document is truncated before the query
+	ids, _, _ = tok.encodePair("right to erasure", strings.Repeat("data ", 50), 10)
+	if want := []int64{2, 9, 10, 11, 12, 3, 5, 5, 5, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Unexpected truncated ids: %v", ids)
+	}
+}
+
+func TestLoadWordPiece(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vocab.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(testVocab, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write vocabulary: %v", err)
+	}
+	tok, err := loadWordPiece(path)
+	if err != nil {
+		t.Fatalf("loadWordPiece failed: %v", err)
+	}
+	if tok.vocab["gdpr"] != 19 {
+		t.Errorf("Unexpected ID for gdpr: %d", tok.vocab["gdpr"])
+	}
+
+	if _, err := newWordPiece([]string{"a", "b"}); err == nil {
+		t.Error("Expected error for vocabulary without special tokens")
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// Units gdpr_browse lists the regulation in
+const (
+	browseChunks   = "chunk"
+	browseArticles = "article"
+)
+
+// maxBrowseLimit caps the chunks or articles of a gdpr_browse page
+const maxBrowseLimit = 50
+
+var browseTool = MCPTool{
+	Name:        "gdpr_browse",
+	Description: "Read the regulation in order, one page at a time: list its chunks in reading order, or its articles with their full text in article order, optionally of one chapter. Follow next_cursor to read on. Use it to read a chapter through rather than finding passages by search.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{browseChunks, browseArticles},
+				"description": "List chunks in reading order, or whole articles in article order (default: chunk)",
+			},
+			"chapter": map[string]interface{}{
+				"type":        "string",
+				"description": "Only list the chapter given as a Roman numeral or number, e.g. \"III\" or \"3\" (default: the whole collection)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of chunks or articles per page (default: 10, max: %d)", maxBrowseLimit),
+			},
+			"cursor": map[string]interface{}{
+				"type":        "string",
+				"description": "next_cursor returned by a previous call, to get its next page; all other arguments must be the same",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection to browse (default: the default collection)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleBrowseTool(id interface{}, args json.RawMessage) {
+	var browseArgs struct {
+		Unit       string `json:"unit"`
+		Chapter    string `json:"chapter"`
+		Limit      int    `json:"limit"`
+		Cursor     string `json:"cursor"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &browseArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	switch browseArgs.Unit {
+	case "":
+		browseArgs.Unit = browseChunks
+	case browseChunks, browseArticles:
+	default:
+		s.writeToolError(id, fmt.Sprintf("Invalid unit %q: must be %q or %q", browseArgs.Unit, browseChunks, browseArticles))
+		return
+	}
+	if browseArgs.Limit <= 0 {
+		browseArgs.Limit = 10
+	}
+	if browseArgs.Limit > maxBrowseLimit {
+		browseArgs.Limit = maxBrowseLimit
+	}
+	chapter := ""
+	if browseArgs.Chapter != "" {
+		var ok bool
+		if chapter, ok = db.ChapterNumber(browseArgs.Chapter); !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid chapter %q", browseArgs.Chapter))
+			return
+		}
+	}
+
+	fingerprint, err := searchFingerprint(args)
+	if err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	var offset int
+	if browseArgs.Cursor != "" {
+		cursor, err := decodeCursor(browseArgs.Cursor, fingerprint)
+		if err != nil {
+			s.writeToolError(id, "Invalid cursor: "+err.Error())
+			return
+		}
+		offset = cursor.Offset
+	}
+
+	output := struct {
+		Unit       string             `json:"unit"`
+		Chapter    string             `json:"chapter,omitempty"`
+		Total      int                `json:"total"`
+		Offset     int                `json:"offset"`
+		Chunks     []db.BrowseChunk   `json:"chunks,omitempty"`
+		Articles   []db.ArticleResult `json:"articles,omitempty"`
+		NextCursor string             `json:"next_cursor,omitempty"`
+	}{Unit: browseArgs.Unit, Chapter: chapter, Offset: offset}
+
+	s.chaos.delayDB()
+	if browseArgs.Unit == browseChunks {
+		output.Chunks, output.Total, err = s.db.Browse(browseArgs.Collection, chapter, offset, browseArgs.Limit)
+		if err != nil {
+			s.writeToolError(id, "Failed to browse chunks: "+err.Error())
+			return
+		}
+		if end := offset + len(output.Chunks); end < output.Total {
+			output.NextCursor = searchCursor{Offset: end, Search: fingerprint}.encode()
+		}
+		s.writeToolJSON(id, output)
+		return
+	}
+
+	toc, err := s.db.TableOfContents(browseArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get table of contents: "+err.Error())
+		return
+	}
+	var articles []db.TOCArticle
+	for _, c := range toc {
+		if chapter == "" || c.Chapter == chapter {
+			articles = append(articles, chapterArticles(c)...)
+		}
+	}
+	output.Total = len(articles)
+	start, end, next := page(len(articles), offset, browseArgs.Limit, fingerprint)
+	output.NextCursor = next
+	output.Articles = []db.ArticleResult{}
+	for _, a := range articles[start:end] {
+		article, err := s.db.Article(a.Article, browseArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get article: "+err.Error())
+			return
+		}
+		if article != nil {
+			output.Articles = append(output.Articles, *article)
+		}
+	}
+	s.writeToolJSON(id, output)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerBrowseTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	for i, d := range []db.Document{
+		{Chunk: "Article 1 - Subject-matter and objectives", Metadata: map[string]string{"chapter": "I", "article": "1", "article_title": "Subject-matter and objectives"}},
+		{Chunk: "Article 2 - Material scope", Metadata: map[string]string{"chapter": "I", "article": "2", "article_title": "Material scope"}},
+		{Chunk: "Article 12 - Transparent information", Metadata: map[string]string{"chapter": "III", "article": "12", "article_title": "Transparent information"}},
+	} {
+		d.ChunkIndex = 10 + i
+		if _, err := database.InsertDocument(d); err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+	}
+	srv := New(database, Config{})
+
+	type browsePage struct {
+		Total      int                `json:"total"`
+		Chunks     []db.BrowseChunk   `json:"chunks"`
+		Articles   []db.ArticleResult `json:"articles"`
+		NextCursor string             `json:"next_cursor"`
+	}
+	browse := func(args string) browsePage {
+		t.Helper()
+		var p browsePage
+		text, isError := callTool(t, srv, "gdpr_browse", args)
+		if isError || json.Unmarshal([]byte(text), &p) != nil {
+			t.Fatalf("gdpr_browse %s failed: %s", args, text)
+		}
+		return p
+	}
+
+	// Read all chunks in pages of four
+	var texts []string
+	cursor := ""
+	for pages := 0; pages < 3; pages++ {
+		p := browse(`{"limit":4,"cursor":"` + cursor + `"}`)
+		if p.Total != 6 {
+			t.Fatalf("Expected 6 chunks, got %d", p.Total)
+		}
+		for _, c := range p.Chunks {
+			texts = append(texts, c.Text)
+		}
+		if cursor = p.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(texts) != 6 || !strings.HasPrefix(texts[3], "Article 1 ") || !strings.HasPrefix(texts[5], "Article 12 ") {
+		t.Errorf("Expected the 6 chunks in reading order, got %q", texts)
+	}
+
+	p := browse(`{"unit":"article","chapter":"1","limit":1}`)
+	if p.Total != 2 || len(p.Articles) != 1 || p.Articles[0].Article != "1" || p.NextCursor == "" {
+		t.Errorf("Expected the first of the 2 articles of Chapter I, got %+v", p)
+	}
+	p = browse(`{"unit":"article","chapter":"1","limit":1,"cursor":"` + p.NextCursor + `"}`)
+	if len(p.Articles) != 1 || p.Articles[0].Title != "Material scope" || p.NextCursor != "" {
+		t.Errorf("Expected the last article of Chapter I, got %+v", p)
+	}
+
+	for _, args := range []string{`{"unit":"recital"}`, `{"chapter":"first"}`, `{"limit":1,"cursor":"` + p.NextCursor + `x"}`} {
+		if text, isError := callTool(t, srv, "gdpr_browse", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the cache of query embeddings and search results
+const (
+	DefaultCacheTTL  = 5 * time.Minute
+	DefaultCacheSize = 256
+)
+
+// queryCache remembers values for a while, dropping the least recently used
+// entries beyond its size. It is safe for concurrent use.
+type queryCache struct {
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// newQueryCache returns a cache keeping up to size entries for ttl, or nil
+// when ttl is negative, which caches nothing
+func newQueryCache(ttl time.Duration, size int) *queryCache {
+	if ttl < 0 {
+		return nil
+	}
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &queryCache{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the value cached under key, if it has not expired
+func (c *queryCache) get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return entry.value, true
+}
+
+// put caches value under key for the cache's TTL
+func (c *queryCache) put(key string, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// normalizeQuery returns the form of a query used as cache key: case and
+// runs of whitespace do not matter, except that operators stay in capitals
+func normalizeQuery(query string) string {
+	words := strings.Fields(query)
+	for i, w := range words {
+		switch strings.Trim(w, "()") {
+		case "AND", "OR", "NOT":
+		default:
+			words[i] = strings.ToLower(w)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestQueryCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newQueryCache(time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.put("a", 1)
+	c.put("b", 2)
+	if v, ok := c.get("a"); !ok || v != 1 {
+		t.Fatalf("Expected a cached, got %v, %v", v, ok)
+	}
+	// b is now the least recently used and makes room for c
+	c.put("c", 3)
+	if _, ok := c.get("b"); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("Expected a recently used entry to be kept")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get("a"); ok {
+		t.Error("Expected entries to expire after the TTL")
+	}
+
+	disabled := newQueryCache(-1, 0)
+	disabled.put("a", 1)
+	if _, ok := disabled.get("a"); ok {
+		t.Error("Expected a negative TTL to disable caching")
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := map[string]string{
+		"  Right  of\tAccess ":           "right of access",
+		"consent AND (Children OR kids)": "consent AND (children OR kids)",
+		"terms and conditions":           "terms and conditions",
+	}
+	for query, want := range tests {
+		if got := normalizeQuery(query); got != want {
+			t.Errorf("normalizeQuery(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+// countingEmbedder counts the query embeddings it computes
+type countingEmbedder struct {
+	queryEmbedder
+	calls *int
+}
+
+func (c countingEmbedder) EmbedQuery(text string) ([]float32, error) {
+	*c.calls++
+	return c.queryEmbedder.EmbedQuery(text)
+}
+
+func TestServerSearchCache(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	search := func(srv *Server, query string) []db.SearchResult {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":20,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"` + query + `","spellcheck":false}}}`
+		resp := captureServerOutput(t, srv, request)
+		text := resp["result"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		var results []db.SearchResult
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results
+	}
+
+	calls := 0
+	cached := New(database, Config{Embedder: countingEmbedder{queryEmbedder{0.7, 0.7, 0.1}, &calls}})
+	uncached := New(database, Config{Embedder: queryEmbedder{0.7, 0.7, 0.1}, CacheTTL: -1})
+	before := len(search(cached, "objection"))
+	search(uncached, "objection")
+
+	// A chunk added since is only found by a server that searches again
+	docID, err := database.InsertChunk("Article 21 - Right to object. Objection to processing.", 3)
+	if err != nil {
+		t.Fatalf("Failed to insert chunk: %v", err)
+	}
+	if err := database.InsertTrigrams(docID, db.GenerateTrigrams("Article 21 - Right to object. Objection to processing.")); err != nil {
+		t.Fatalf("Failed to insert trigrams: %v", err)
+	}
+
+	if got := len(search(cached, "  Objection ")); got != before {
+		t.Errorf("Expected the cached %d results for the same normalized query, got %d", before, got)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the query to be embedded once, got %d", calls)
+	}
+	if got := len(search(uncached, "objection")); got != before+1 {
+		t.Errorf("Expected %d results without caching, got %d", before+1, got)
+	}
+}
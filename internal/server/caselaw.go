@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+var caseLawTool = MCPTool{
+	Name:        "case_law",
+	Description: "Search CJEU data protection judgments ingested with the caselaw profile, such as Schrems II or Google Spain. Each hit reports the case number, name, ECLI, date and the numbered paragraph of the judgment it comes from, for citation.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Search query",
+			},
+			"case": map[string]interface{}{
+				"type":        "string",
+				"description": "Only search this judgment, by case number (e.g. \"C-311/18\") or part of its name (e.g. \"Schrems\")",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Max results (default: 10, max: %d)", maxSearchLimit),
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the judgments were ingested into (default: " + ingest.CaseLawCollection + ")",
+			},
+		},
+		Required: []string{"query"},
+	},
+}
+
+// caseLawResult is a chunk of a judgment matching a search
+type caseLawResult struct {
+	ID         int64   `json:"id"`
+	Score      float64 `json:"score"`
+	Snippet    string  `json:"snippet"`
+	CaseNumber string  `json:"case_number,omitempty"`
+	CaseName   string  `json:"case_name,omitempty"`
+	CaseAlias  string  `json:"case_alias,omitempty"`
+	ECLI       string  `json:"ecli,omitempty"`
+	Date       string  `json:"date,omitempty"`
+	Paragraph  string  `json:"paragraph,omitempty"`
+	Citation   string  `json:"citation,omitempty"` // e.g. "C-311/18 (Schrems II), ECLI:EU:C:2020:559, para. 134"
+}
+
+func (s *Server) handleCaseLawTool(id interface{}, args json.RawMessage) {
+	var caseArgs struct {
+		Query      string `json:"query"`
+		Case       string `json:"case"`
+		Limit      int    `json:"limit"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &caseArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if caseArgs.Query == "" {
+		s.writeToolError(id, "Query is required")
+		return
+	}
+	if caseArgs.Limit <= 0 {
+		caseArgs.Limit = 10
+	}
+	if caseArgs.Limit > maxSearchLimit {
+		caseArgs.Limit = maxSearchLimit
+	}
+	if caseArgs.Collection == "" {
+		caseArgs.Collection = ingest.CaseLawCollection
+	}
+	caseFilter := strings.TrimSpace(caseArgs.Case)
+	if number, ok := ingest.CaseNumber(caseFilter); ok {
+		caseFilter = number
+	}
+
+	results, err := s.search(caseArgs.Query, caseArgs.Limit, db.SearchOptions{Collection: caseArgs.Collection, Case: caseFilter})
+	if err != nil {
+		s.writeToolError(id, "Search failed: "+err.Error())
+		return
+	}
+	results = db.NormalizeScores(results)
+
+	hits := make([]caseLawResult, 0, len(results))
+	for _, r := range results {
+		doc, err := s.db.GetDocument(r.ID)
+		if err != nil {
+			s.writeToolError(id, "Failed to get document: "+err.Error())
+			return
+		}
+		if doc == nil {
+			continue
+		}
+		m := doc.Metadata
+		hit := caseLawResult{
+			ID:         r.ID,
+			Score:      r.Score,
+			Snippet:    r.Snippet,
+			CaseNumber: m[ingest.MetaCaseNumber],
+			CaseName:   m[ingest.MetaCaseName],
+			CaseAlias:  m[ingest.MetaCaseAlias],
+			ECLI:       m[ingest.MetaECLI],
+			Date:       m[ingest.MetaJudgmentDate],
+			Paragraph:  m[ingest.MetaJudgmentParagraph],
+		}
+		var citation []string
+		if hit.CaseNumber != "" {
+			if hit.CaseAlias != "" {
+				citation = append(citation, hit.CaseNumber+" ("+hit.CaseAlias+")")
+			} else {
+				citation = append(citation, hit.CaseNumber)
+			}
+		}
+		if hit.ECLI != "" {
+			citation = append(citation, hit.ECLI)
+		}
+		if hit.Paragraph != "" {
+			citation = append(citation, "para. "+hit.Paragraph)
+		}
+		hit.Citation = strings.Join(citation, ", ")
+		hits = append(hits, hit)
+	}
+	s.writeToolJSON(id, hits)
+}
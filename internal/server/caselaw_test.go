@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+func TestServerCaseLawTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []struct {
+		chunk    string
+		metadata map[string]string
+	}{
+		{
+			"134 It follows that standard data protection clauses may require supplementary measures to ensure an adequate level of protection.",
+			map[string]string{ingest.MetaCaseNumber: "C-311/18", ingest.MetaCaseAlias: "Schrems II", ingest.MetaECLI: "ECLI:EU:C:2020:559", ingest.MetaJudgmentDate: "2020-07-16", ingest.MetaJudgmentParagraph: "134"},
+		},
+		{
+			"The operator of a search engine is responsible for the processing of personal data appearing on web pages, including an adequate level of protection.",
+			map[string]string{ingest.MetaCaseNumber: "C-131/12", ingest.MetaCaseName: "Google Spain SL v AEPD"},
+		},
+	} {
+		docID, err := database.InsertDocument(db.Document{Chunk: d.chunk, Metadata: d.metadata, Collection: ingest.CaseLawCollection})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, db.GenerateTrigrams(d.chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+		ids = append(ids, docID)
+	}
+	srv := New(database, Config{})
+
+	var hits []caseLawResult
+	text, isError := callTool(t, srv, "case_law", `{"query":"supplementary measures adequate level of protection"}`)
+	if isError || json.Unmarshal([]byte(text), &hits) != nil {
+		t.Fatalf("case_law failed: %s", text)
+	}
+	if len(hits) != 2 || hits[0].ID != ids[0] {
+		t.Fatalf("Expected both judgments, Schrems II first, got %s", text)
+	}
+	if hits[0].Date != "2020-07-16" || hits[0].Citation != "C-311/18 (Schrems II), ECLI:EU:C:2020:559, para. 134" {
+		t.Errorf("Unexpected hit %+v", hits[0])
+	}
+	if hits[1].CaseName != "Google Spain SL v AEPD" || hits[1].Citation != "C-131/12" {
+		t.Errorf("Unexpected hit %+v", hits[1])
+	}
+
+	for _, c := range []string{"Case C‑131/12", "google"} {
+		hits = nil
+		text, _ = callTool(t, srv, "case_law", `{"query":"adequate level of protection","case":"`+c+`"}`)
+		if json.Unmarshal([]byte(text), &hits) != nil || len(hits) != 1 || hits[0].ID != ids[1] {
+			t.Errorf("Expected Google Spain alone for %q, got %s", c, text)
+		}
+	}
+
+	if text, isError := callTool(t, srv, "case_law", `{}`); !isError {
+		t.Errorf("Expected a missing query to fail, got %s", text)
+	}
+}
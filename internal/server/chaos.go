@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosEnvVar is the (undocumented) environment variable holding a chaos
+// spec, read by New when Config.Chaos injects nothing
+const ChaosEnvVar = "GDPR_MCP_CHAOS"
+
+// errChaosEmbedding is returned by injected embedding failures
+var errChaosEmbedding = errors.New("chaos: injected embedding failure")
+
+// ChaosConfig configures fault injection for resilience testing.
+// Rates are probabilities between 0 and 1; a zero config injects nothing.
+type ChaosConfig struct {
+	DBDelay       time.Duration // delay added before database calls
+	DBDelayRate   float64       // probability of delaying a database call
+	EmbedFailRate float64       // probability of failing a query embedding
+	TruncateRate  float64       // probability of truncating a response line
+	Seed          int64         // random seed (0 uses the current time)
+}
+
+// Enabled reports whether any fault is configured
+func (c ChaosConfig) Enabled() bool {
+	return c.DBDelayRate > 0 || c.EmbedFailRate > 0 || c.TruncateRate > 0
+}
+
+// ParseChaos parses a comma-separated chaos spec such as
+// "db_delay=500ms,db_delay_rate=0.2,embed_fail_rate=0.1,truncate_rate=0.05"
+func ParseChaos(spec string) (ChaosConfig, error) {
+	var c ChaosConfig
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return c, nil
+	}
+
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return c, fmt.Errorf("invalid chaos setting %q: expected key=value", field)
+		}
+
+		var err error
+		switch key {
+		case "db_delay":
+			c.DBDelay, err = time.ParseDuration(value)
+		case "db_delay_rate":
+			c.DBDelayRate, err = parseRate(value)
+		case "embed_fail_rate":
+			c.EmbedFailRate, err = parseRate(value)
+		case "truncate_rate":
+			c.TruncateRate, err = parseRate(value)
+		case "seed":
+			c.Seed, err = strconv.ParseInt(value, 10, 64)
+		default:
+			return c, fmt.Errorf("unknown chaos setting %q", key)
+		}
+		if err != nil {
+			return c, fmt.Errorf("invalid chaos setting %q: %w", key, err)
+		}
+	}
+
+	if c.DBDelayRate > 0 && c.DBDelay == 0 {
+		c.DBDelay = time.Second
+	}
+
+	return c, nil
+}
+
+func parseRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate %v out of range [0, 1]", rate)
+	}
+	return rate, nil
+}
+
+// chaos injects the faults described by a ChaosConfig.
+// A nil *chaos is valid and never injects anything.
+type chaos struct {
+	config ChaosConfig
+	mu     sync.Mutex
+	rng    *rand.Rand
+}
+
+func newChaos(config ChaosConfig) *chaos {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &chaos{
+		config: config,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// roll reports whether a fault with the given rate should fire
+func (c *chaos) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < rate
+}
+
+// delayDB sleeps before a database call when a slow-db fault fires
+func (c *chaos) delayDB() {
+	if c != nil && c.roll(c.config.DBDelayRate) {
+		time.Sleep(c.config.DBDelay)
+	}
+}
+
+// embeddingError returns an error when an embedding fault fires
+func (c *chaos) embeddingError() error {
+	if c != nil && c.roll(c.config.EmbedFailRate) {
+		return errChaosEmbedding
+	}
+	return nil
+}
+
+// truncate cuts a response in half when a truncation fault fires
+func (c *chaos) truncate(data []byte) []byte {
+	if c != nil && c.roll(c.config.TruncateRate) {
+		return data[:len(data)/2]
+	}
+	return data
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestParseChaos(t *testing.T) {
+	config, err := ParseChaos("db_delay=50ms, db_delay_rate=0.5,embed_fail_rate=1,truncate_rate=0.25,seed=7")
+	if err != nil {
+		t.Fatalf("ParseChaos failed: %v", err)
+	}
+
+	expected := ChaosConfig{
+		DBDelay:       50 * time.Millisecond,
+		DBDelayRate:   0.5,
+		EmbedFailRate: 1,
+		TruncateRate:  0.25,
+		Seed:          7,
+	}
+	if config != expected {
+		t.Errorf("ParseChaos = %+v, want %+v", config, expected)
+	}
+
+	empty, err := ParseChaos("")
+	if err != nil {
+		t.Fatalf("ParseChaos(\"\") failed: %v", err)
+	}
+	if empty.Enabled() {
+		t.Error("Empty spec should not enable chaos")
+	}
+
+	for _, spec := range []string{"truncate_rate=2", "bogus=1", "db_delay", "db_delay=fast"} {
+		if _, err := ParseChaos(spec); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestChaosFaults(t *testing.T) {
+	var disabled *chaos
+	if err := disabled.embeddingError(); err != nil {
+		t.Errorf("Nil chaos should not inject errors, got %v", err)
+	}
+	if got := disabled.truncate([]byte("abcd")); string(got) != "abcd" {
+		t.Errorf("Nil chaos should not truncate, got %q", got)
+	}
+
+	always := newChaos(ChaosConfig{EmbedFailRate: 1, TruncateRate: 1, Seed: 1})
+	if err := always.embeddingError(); err == nil {
+		t.Error("Expected injected embedding error")
+	}
+	if got := always.truncate([]byte("abcd")); string(got) != "ab" {
+		t.Errorf("Expected truncated response, got %q", got)
+	}
+}
+
+func TestServerSearchToolSurvivesEmbeddingFaults(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{Chaos: ChaosConfig{EmbedFailRate: 1, Seed: 1}})
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"right of access"}}}`
+	resp := captureServerOutput(t, srv, request)
+
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected result object, got %T", resp["result"])
+	}
+	if isError, ok := result["isError"].(bool); ok && isError {
+		t.Errorf("Search should fall back to keyword results, got error: %v", result["content"])
+	}
+}
+
+func TestNewReadsChaosEnv(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	logger := log.New(io.Discard, "", 0)
+
+	t.Setenv(ChaosEnvVar, "embed_fail_rate=1,seed=1")
+	if srv := New(database, Config{Logger: logger}); srv.chaos == nil || srv.config.Chaos.EmbedFailRate != 1 {
+		t.Errorf("Expected chaos from %s, got %+v", ChaosEnvVar, srv.config.Chaos)
+	}
+
+	// An explicit config takes precedence over the environment
+	if srv := New(database, Config{Logger: logger, Chaos: ChaosConfig{TruncateRate: 0.5}}); srv.config.Chaos.EmbedFailRate != 0 {
+		t.Errorf("Expected the configured chaos, got %+v", srv.config.Chaos)
+	}
+
+	t.Setenv(ChaosEnvVar, "embed_fail_rate")
+	if srv := New(database, Config{Logger: logger}); srv.chaos != nil {
+		t.Errorf("Expected an invalid spec to be ignored, got %+v", srv.config.Chaos)
+	}
+}
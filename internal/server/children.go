@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var childrenTool = MCPTool{
+	Name:        "children_data",
+	Description: "Get the GDPR rules for children's personal data: Article 8 and its recitals, the age of digital consent in each EU and EEA country, the parental consent requirements, and whether a child of a given age needs parental consent",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"country": map[string]interface{}{
+				"type":        "string",
+				"description": "EU or EEA country by ISO code or English name, e.g. \"DE\" or \"Spain\" (default: every country)",
+			},
+			"age": map[string]interface{}{
+				"type":        "integer",
+				"description": "Age of the child, to assess whether consent to an information society service needs parental authorisation",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+// childrenOutput is the output of children_data
+type childrenOutput struct {
+	Assessment   *guidance.ChildConsentAssessment `json:"assessment,omitempty"`
+	Ages         []guidance.DigitalConsentAge     `json:"ages"`
+	Requirements []guidance.ChildRequirement      `json:"requirements"`
+	Article      *db.ArticleResult                `json:"article,omitempty"`  // Article 8, where ingested
+	Recitals     []*db.Recital                    `json:"recitals,omitempty"` // the recitals on children, where ingested
+	Notes        []string                         `json:"notes,omitempty"`
+}
+
+func (s *Server) handleChildrenTool(id interface{}, args json.RawMessage) {
+	var childrenArgs struct {
+		Country    string `json:"country"`
+		Age        *int   `json:"age"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &childrenArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	output := childrenOutput{Ages: guidance.DigitalConsentAges, Requirements: guidance.ChildRequirements}
+	if childrenArgs.Country != "" {
+		age, ok := guidance.DigitalConsentAgeFor(childrenArgs.Country)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Unknown EU or EEA country %q; give an ISO code such as \"DE\" or an English name", childrenArgs.Country))
+			return
+		}
+		output.Ages = []guidance.DigitalConsentAge{age}
+	}
+	if childrenArgs.Age != nil {
+		assessment, err := guidance.AssessChildConsent(childrenArgs.Country, *childrenArgs.Age)
+		if err != nil {
+			s.writeToolError(id, err.Error())
+			return
+		}
+		output.Assessment = &assessment
+	}
+
+	s.chaos.delayDB()
+	article, err := s.db.Article("8", childrenArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get Article 8: "+err.Error())
+		return
+	}
+	output.Article = article
+	for _, number := range guidance.ChildrenRecitals {
+		recital, err := s.db.Recital(number, childrenArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get recital: "+err.Error())
+			return
+		}
+		if recital != nil {
+			output.Recitals = append(output.Recitals, recital)
+		}
+	}
+	if article == nil || len(output.Recitals) < len(guidance.ChildrenRecitals) {
+		output.Notes = append(output.Notes, "Article 8 or some of the recitals on children were not found in the index, so their text is not included; ingest the GDPR text to add it")
+	}
+	output.Notes = append(output.Notes, "National ages of digital consent change; check the current national law before relying on them")
+
+	s.writeToolJSON(id, output)
+}
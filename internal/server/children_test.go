@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerChildrenTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	srv := New(database, Config{})
+
+	var output childrenOutput
+	text, isError := callTool(t, srv, "children_data", `{}`)
+	if isError || json.Unmarshal([]byte(text), &output) != nil {
+		t.Fatalf("children_data failed: %s", text)
+	}
+	if len(output.Ages) != len(guidance.DigitalConsentAges) || len(output.Requirements) == 0 || output.Article != nil || output.Assessment != nil {
+		t.Errorf("Expected every country and no article text, got %s", text)
+	}
+	if !strings.Contains(strings.Join(output.Notes, " "), "not found in the index") {
+		t.Errorf("Expected a note on the missing text, got %v", output.Notes)
+	}
+
+	for i, d := range []struct {
+		chunk    string
+		metadata map[string]string
+	}{
+		{"1. Where point (a) of Article 6(1) applies, in relation to the offer of information society services directly to a child", map[string]string{"article": "8", "article_title": "Conditions applicable to child's consent in relation to information society services"}},
+		{"(38) Children merit specific protection with regard to their personal data.", map[string]string{"recital": "38"}},
+	} {
+		if _, err := database.InsertDocument(db.Document{Chunk: d.chunk, ChunkIndex: i, Metadata: d.metadata}); err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
+
+	output = childrenOutput{}
+	text, isError = callTool(t, srv, "children_data", `{"country":"Spain","age":13}`)
+	if isError || json.Unmarshal([]byte(text), &output) != nil {
+		t.Fatalf("children_data failed: %s", text)
+	}
+	if len(output.Ages) != 1 || output.Ages[0].Country != "ES" || output.Assessment == nil || !output.Assessment.ParentalConsentRequired || output.Assessment.Threshold != 14 {
+		t.Errorf("Expected parental consent below 14 in Spain, got %s", text)
+	}
+	if output.Article == nil || output.Article.Article != "8" || len(output.Recitals) != 1 || output.Recitals[0].Number != "38" {
+		t.Errorf("Expected Article 8 and Recital 38, got %s", text)
+	}
+
+	for _, args := range []string{`{"country":"Atlantis"}`, `{"age":-2}`} {
+		if text, isError := callTool(t, srv, "children_data", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+var citeTool = MCPTool{
+	Name:        "gdpr_cite",
+	Description: "Turn a chunk ID from another tool, or a reference such as \"17(3)(b)\" or \"Recital 26\", into verifiable citations of the GDPR: the short form (\"Art. 17(3)(b) GDPR\"), the long and full Official Journal forms, the CELEX number, the ELI URI and the EUR-Lex URL. Give exactly one of chunk_id and reference.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"chunk_id": map[string]interface{}{
+				"type":        "integer",
+				"description": "ID of a chunk of the regulation returned by another tool; cited at the article paragraph, recital or chapter it starts in",
+			},
+			"reference": map[string]interface{}{
+				"type":        "string",
+				"description": "Provision to cite, e.g. \"Art. 17(3)(b)\", \"6(1)(f)\", \"Recital 26\" or \"Chapter V\"",
+			},
+			"language": map[string]interface{}{
+				"type":        "string",
+				"description": "Language of the EUR-Lex URL, e.g. \"de\" (default: the chunk's language, or en)",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection to look the provision up in, to report its title and chunks (default: the chunk's collection, or the default collection)",
+			},
+		},
+	},
+}
+
+// citeResult is a citation with the provision's title and chunks as
+// ingested, so it can be checked against the text
+type citeResult struct {
+	guidance.Citation
+	Title    string  `json:"title,omitempty"`
+	ChunkID  int64   `json:"chunk_id,omitempty"`
+	ChunkIDs []int64 `json:"chunk_ids,omitempty"` // chunks of the cited article or recital in the index
+}
+
+func (s *Server) handleCiteTool(id interface{}, args json.RawMessage) {
+	var citeArgs struct {
+		ChunkID    int64  `json:"chunk_id"`
+		Reference  string `json:"reference"`
+		Language   string `json:"language"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &citeArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	var ref guidance.Reference
+	language, collection := citeArgs.Language, citeArgs.Collection
+	switch {
+	case (citeArgs.ChunkID != 0) == (citeArgs.Reference != ""):
+		s.writeToolError(id, "Give exactly one of chunk_id and reference")
+		return
+	case citeArgs.ChunkID != 0:
+		s.chaos.delayDB()
+		doc, err := s.db.GetDocument(citeArgs.ChunkID)
+		if err != nil {
+			s.writeToolError(id, "Failed to get document: "+err.Error())
+			return
+		}
+		if doc == nil {
+			s.writeToolError(id, fmt.Sprintf("Chunk %d not found", citeArgs.ChunkID))
+			return
+		}
+		ref = guidance.Reference{
+			Article:   doc.Metadata[ingest.MetaArticle],
+			Paragraph: doc.Metadata[ingest.MetaParagraph],
+			Recital:   doc.Metadata[ingest.MetaRecital],
+			Chapter:   doc.Metadata[ingest.MetaChapter],
+		}
+		switch {
+		case ref.Article != "":
+			ref.Recital, ref.Chapter = "", ""
+		case ref.Recital != "":
+			ref.Paragraph, ref.Chapter = "", ""
+		case ref.Chapter == "":
+			s.writeToolError(id, fmt.Sprintf("Chunk %d is not part of an article, recital or chapter of the regulation", citeArgs.ChunkID))
+			return
+		}
+		if language == "" {
+			language = doc.Language
+		}
+		if collection == "" {
+			collection = doc.Collection
+		}
+	default:
+		var err error
+		if ref, err = guidance.ParseReference(citeArgs.Reference); err != nil {
+			s.writeToolError(id, err.Error())
+			return
+		}
+	}
+	if !ingest.IsEURLexLanguage(strings.ToUpper(language)) {
+		if citeArgs.Language != "" {
+			s.writeToolError(id, fmt.Sprintf("Unsupported language %q, expected an official EU language such as en or de", citeArgs.Language))
+			return
+		}
+		language = "en"
+	}
+
+	result := citeResult{Citation: guidance.Cite(ref, strings.ToLower(language)), ChunkID: citeArgs.ChunkID}
+	switch {
+	case ref.Article != "":
+		article, err := s.db.Article(ref.Article, collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get article: "+err.Error())
+			return
+		}
+		if article != nil {
+			result.Title, result.ChunkIDs = article.Title, article.ChunkIDs
+		}
+	case ref.Recital != "":
+		recital, err := s.db.Recital(ref.Recital, collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get recital: "+err.Error())
+			return
+		}
+		if recital != nil {
+			result.ChunkIDs = recital.ChunkIDs
+		}
+	}
+	s.writeToolJSON(id, result)
+}
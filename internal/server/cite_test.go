@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerCiteTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chunkID, err := database.InsertDocument(db.Document{
+		Chunk:      "3. Paragraphs 1 and 2 shall not apply to the extent that processing is necessary:",
+		Language:   "de",
+		Collection: "gdpr-de",
+		Metadata:   map[string]string{"article": "17", "article_title": "Recht auf Löschung", "paragraph": "3", "chapter": "III"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert document: %v", err)
+	}
+	srv := New(database, Config{})
+
+	var result citeResult
+	text, isError := callTool(t, srv, "gdpr_cite", fmt.Sprintf(`{"chunk_id":%d}`, chunkID))
+	if isError || json.Unmarshal([]byte(text), &result) != nil {
+		t.Fatalf("gdpr_cite failed: %s", text)
+	}
+	if result.Short != "Art. 17(3) GDPR" || result.Chapter != "" || result.Title != "Recht auf Löschung" || len(result.ChunkIDs) != 1 ||
+		result.URL != "https://eur-lex.europa.eu/legal-content/DE/TXT/?uri=CELEX:32016R0679" {
+		t.Errorf("Unexpected citation of the chunk %s", text)
+	}
+
+	result = citeResult{}
+	text, isError = callTool(t, srv, "gdpr_cite", `{"reference":"Art. 17(3)(b)","collection":"gdpr-de","language":"fr"}`)
+	if isError || json.Unmarshal([]byte(text), &result) != nil {
+		t.Fatalf("gdpr_cite failed: %s", text)
+	}
+	if result.Short != "Art. 17(3)(b) GDPR" || result.Long != "Article 17(3)(b) of Regulation (EU) 2016/679" || result.CELEX != "32016R0679" ||
+		result.ChunkIDs[0] != chunkID || result.URL != "https://eur-lex.europa.eu/legal-content/FR/TXT/?uri=CELEX:32016R0679" {
+		t.Errorf("Unexpected citation of the reference %s", text)
+	}
+
+	result = citeResult{}
+	text, _ = callTool(t, srv, "gdpr_cite", `{"reference":"Recital 26"}`)
+	if json.Unmarshal([]byte(text), &result) != nil || result.Short != "Recital 26 GDPR" || result.ChunkIDs != nil {
+		t.Errorf("Expected recital 26 cited without chunks, got %s", text)
+	}
+
+	for _, args := range []string{`{}`, `{"chunk_id":1,"reference":"17"}`, `{"chunk_id":1}`, `{"chunk_id":9999}`, `{"reference":"Article 120"}`, `{"reference":"17","language":"xx"}`} {
+		if text, isError := callTool(t, srv, "gdpr_cite", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ClientServerName is the name the server is registered under in MCP host
+// configurations
+const ClientServerName = "gdpr"
+
+// clientServer is an entry of the mcpServers object of an MCP host
+// configuration such as claude_desktop_config.json
+type clientServer struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+}
+
+// ClientConfig returns the configuration snippet MCP hosts such as Claude
+// Desktop need to launch the server: command is the path of the gdpr-mcp
+// binary and env the environment it must run with, e.g. GDPR_MCP_DB and the
+// embedder settings the corpus was ingested with
+func ClientConfig(command string, env map[string]string) ([]byte, error) {
+	if env == nil {
+		env = map[string]string{}
+	}
+	config := map[string]map[string]clientServer{
+		"mcpServers": {
+			ClientServerName: {Command: command, Args: []string{"start"}, Env: env},
+		},
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal client config: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestClientConfig(t *testing.T) {
+	data, err := ClientConfig("/usr/local/bin/gdpr-mcp", map[string]string{"GDPR_MCP_DB": "/data/gdpr.db"})
+	if err != nil {
+		t.Fatalf("ClientConfig failed: %v", err)
+	}
+
+	var config struct {
+		MCPServers map[string]struct {
+			Command string            `json:"command"`
+			Args    []string          `json:"args"`
+			Env     map[string]string `json:"env"`
+		} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Invalid config: %v\n%s", err, data)
+	}
+	server, ok := config.MCPServers[ClientServerName]
+	if !ok {
+		t.Fatalf("Expected a %q server, got %s", ClientServerName, data)
+	}
+	if server.Command != "/usr/local/bin/gdpr-mcp" || len(server.Args) != 1 || server.Args[0] != "start" {
+		t.Errorf("Unexpected command %q %v", server.Command, server.Args)
+	}
+	if server.Env["GDPR_MCP_DB"] != "/data/gdpr.db" {
+		t.Errorf("Expected the environment to be passed, got %v", server.Env)
+	}
+
+	// Hosts expect an env object even when there is nothing to set
+	data, err = ClientConfig("gdpr-mcp", nil)
+	if err != nil {
+		t.Fatalf("ClientConfig failed: %v", err)
+	}
+	var empty struct {
+		MCPServers map[string]struct {
+			Env map[string]string `json:"env"`
+		} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &empty); err != nil || empty.MCPServers[ClientServerName].Env == nil {
+		t.Errorf("Expected an empty env object, got %s", data)
+	}
+}
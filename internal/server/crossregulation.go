@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var crossRegulationTool = MCPTool{
+	Name:        "cross_regulation",
+	Description: "Map a GDPR article or concept to the corresponding provisions of the CCPA/CPRA (California) and the LGPD (Brazil), with notes on where they differ. Without arguments, list the mapped concepts.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"article": map[string]interface{}{
+				"type":        "string",
+				"description": "GDPR article number, e.g. \"17\" or \"Art. 17\"",
+			},
+			"concept": map[string]interface{}{
+				"type":        "string",
+				"description": "Concept in the vocabulary of any of the regulations, e.g. \"right to delete\", \"encarregado\" or \"data breach\"",
+			},
+		},
+	},
+}
+
+func (s *Server) handleCrossRegulationTool(id interface{}, args json.RawMessage) {
+	var crossArgs struct {
+		Article string `json:"article"`
+		Concept string `json:"concept"`
+	}
+	if err := json.Unmarshal(args, &crossArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	var correspondences []guidance.Correspondence
+	switch {
+	case crossArgs.Article != "" && crossArgs.Concept != "":
+		s.writeToolError(id, "article cannot be combined with concept")
+		return
+	case crossArgs.Article != "":
+		article, ok := db.ArticleNumber(crossArgs.Article)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid article %q", crossArgs.Article))
+			return
+		}
+		if correspondences = guidance.CorrespondencesForArticle(article); len(correspondences) == 0 {
+			s.writeToolError(id, fmt.Sprintf("Article %s is not mapped; omit the arguments to list the mapped concepts", article))
+			return
+		}
+	case crossArgs.Concept != "":
+		if correspondences = guidance.MatchCorrespondences(crossArgs.Concept); len(correspondences) == 0 {
+			s.writeToolError(id, fmt.Sprintf("No mapped concept matches %q; omit the arguments to list the mapped concepts", crossArgs.Concept))
+			return
+		}
+	default:
+		type concept struct {
+			ID      string   `json:"id"`
+			Concept string   `json:"concept"`
+			GDPR    []string `json:"gdpr"`
+		}
+		concepts := make([]concept, len(guidance.Correspondences))
+		for i, c := range guidance.Correspondences {
+			concepts[i] = concept{c.ID, c.Concept, c.GDPR}
+		}
+		s.writeToolJSON(id, concepts)
+		return
+	}
+
+	s.writeToolJSON(id, struct {
+		Regulations     map[string]string         `json:"regulations"`
+		Correspondences []guidance.Correspondence `json:"correspondences"`
+	}{guidance.Regulations, correspondences})
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerCrossRegulationTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	srv := New(database, Config{})
+
+	var concepts []struct {
+		ID string `json:"id"`
+	}
+	text, isError := callTool(t, srv, "cross_regulation", `{}`)
+	if isError || json.Unmarshal([]byte(text), &concepts) != nil || len(concepts) != len(guidance.Correspondences) {
+		t.Fatalf("Expected the mapped concepts, got %s", text)
+	}
+
+	var output struct {
+		Regulations     map[string]string         `json:"regulations"`
+		Correspondences []guidance.Correspondence `json:"correspondences"`
+	}
+	text, isError = callTool(t, srv, "cross_regulation", `{"article":"Art. 17"}`)
+	if isError || json.Unmarshal([]byte(text), &output) != nil {
+		t.Fatalf("cross_regulation failed: %s", text)
+	}
+	if len(output.Correspondences) != 1 || output.Correspondences[0].ID != "erasure" || len(output.Regulations) != 2 {
+		t.Fatalf("Expected erasure, got %s", text)
+	}
+	if c := output.Correspondences[0]; len(c.CCPA) == 0 || c.CCPA[0].Citation != "Cal. Civ. Code § 1798.105" || len(c.LGPD) == 0 {
+		t.Errorf("Expected the CCPA right to delete and the LGPD provisions, got %+v", c)
+	}
+
+	output.Correspondences = nil
+	text, _ = callTool(t, srv, "cross_regulation", `{"concept":"encarregado"}`)
+	if json.Unmarshal([]byte(text), &output) != nil || len(output.Correspondences) != 1 || output.Correspondences[0].ID != "dpo" {
+		t.Errorf("Expected the DPO, got %s", text)
+	}
+
+	for _, args := range []string{`{"article":"17","concept":"erasure"}`, `{"article":"1"}`, `{"concept":"weather"}`} {
+		if text, isError := callTool(t, srv, "cross_regulation", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
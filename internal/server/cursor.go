@@ -0,0 +1,78 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// searchPages is how many pages of limit results a search ranks up front,
+// so that following pages come from the same ranking
+const searchPages = 5
+
+// errCursorMismatch is returned for a cursor of another search
+var errCursorMismatch = errors.New("cursor belongs to a search with different arguments")
+
+// searchCursor is the position of the next page of a search. It is handed
+// to clients base64-encoded as an opaque token.
+type searchCursor struct {
+	Offset int    `json:"o"`
+	Search string `json:"s"` // fingerprint of the search arguments
+}
+
+// searchFingerprint identifies a search by its arguments other than the
+// cursor, so a cursor cannot be used with another search
+func searchFingerprint(args json.RawMessage) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(args, &fields); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	delete(fields, "cursor")
+	// Maps marshal with sorted keys, so equal arguments hash equally
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// encode returns the cursor as an opaque token
+func (c searchCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor token of the search with the given
+// fingerprint
+func decodeCursor(token, fingerprint string) (searchCursor, error) {
+	var c searchCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+	if c.Offset < 0 {
+		return c, fmt.Errorf("failed to decode cursor: negative offset %d", c.Offset)
+	}
+	if c.Search != fingerprint {
+		return c, errCursorMismatch
+	}
+	return c, nil
+}
+
+// page returns the bounds of the page of n out of total items starting at
+// offset, and the cursor of the next page or "" when there is none
+func page(total, offset, n int, fingerprint string) (start, end int, next string) {
+	start = min(offset, total)
+	end = min(start+n, total)
+	if end < total {
+		next = searchCursor{Offset: end, Search: fingerprint}.encode()
+	}
+	return start, end, next
+}
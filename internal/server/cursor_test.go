@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestSearchFingerprint(t *testing.T) {
+	a, err := searchFingerprint(json.RawMessage(`{"query":"erasure","limit":5}`))
+	if err != nil {
+		t.Fatalf("searchFingerprint failed: %v", err)
+	}
+	b, _ := searchFingerprint(json.RawMessage(`{"limit":5, "query":"erasure", "cursor":"abc"}`))
+	if a != b {
+		t.Errorf("Expected argument order and the cursor not to matter, got %s and %s", a, b)
+	}
+	if c, _ := searchFingerprint(json.RawMessage(`{"query":"erasure","limit":6}`)); c == a {
+		t.Error("Expected different arguments to give a different fingerprint")
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	token := searchCursor{Offset: 10, Search: "abc"}.encode()
+	c, err := decodeCursor(token, "abc")
+	if err != nil || c.Offset != 10 {
+		t.Errorf("decodeCursor = %+v, %v; want offset 10", c, err)
+	}
+	if _, err := decodeCursor(token, "def"); err != errCursorMismatch {
+		t.Errorf("Expected a cursor of another search to be rejected, got %v", err)
+	}
+	if _, err := decodeCursor("not a cursor!", "abc"); err == nil {
+		t.Error("Expected a malformed cursor to be rejected")
+	}
+}
+
+func TestPage(t *testing.T) {
+	if start, end, next := page(5, 0, 2, "abc"); start != 0 || end != 2 || next == "" {
+		t.Errorf("page(5, 0, 2) = %d, %d, %q; want 0, 2 and a cursor", start, end, next)
+	}
+	if start, end, next := page(5, 4, 2, "abc"); start != 4 || end != 5 || next != "" {
+		t.Errorf("page(5, 4, 2) = %d, %d, %q; want the last item and no cursor", start, end, next)
+	}
+	if start, end, _ := page(5, 9, 2, "abc"); start != 5 || end != 5 {
+		t.Errorf("page(5, 9, 2) = %d, %d; want an empty page", start, end)
+	}
+}
+
+func TestServerSearchPagination(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	srv := New(database, Config{})
+
+	search := func(args string) ([]db.SearchResult, string, bool) {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":21,"method":"tools/call","params":{"name":"gdpr_search","arguments":` + args + `}}`
+		resp := captureServerOutput(t, srv, request)
+		result := resp["result"].(map[string]interface{})
+		content := result["content"].([]interface{})
+		if isError, _ := result["isError"].(bool); isError {
+			return nil, "", true
+		}
+		var results []db.SearchResult
+		if err := json.Unmarshal([]byte(content[0].(map[string]interface{})["text"].(string)), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		var cursor struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		for _, c := range content[1:] {
+			json.Unmarshal([]byte(c.(map[string]interface{})["text"].(string)), &cursor)
+		}
+		return results, cursor.NextCursor, false
+	}
+
+	all, next, _ := search(`{"query":"data subject right","limit":10}`)
+	if len(all) != 3 || next != "" {
+		t.Fatalf("Expected all 3 chunks on one page without a cursor, got %+v, %q", all, next)
+	}
+
+	// Pages of one result follow the same ranking
+	var paged []db.SearchResult
+	cursor := ""
+	for i := 0; i < len(all); i++ {
+		args := `{"query":"data subject right","limit":1`
+		if cursor != "" {
+			args += `,"cursor":"` + cursor + `"`
+		}
+		results, next, isError := search(args + `}`)
+		if isError || len(results) != 1 {
+			t.Fatalf("Expected page %d to have one result, got %+v", i+1, results)
+		}
+		paged = append(paged, results...)
+		if (next == "") != (i == len(all)-1) {
+			t.Fatalf("Expected a next cursor on every page but the last, got %q on page %d", next, i+1)
+		}
+		cursor = next
+	}
+	seen := make(map[int64]bool)
+	for _, r := range paged {
+		seen[r.ID] = true
+	}
+	if len(seen) != len(all) {
+		t.Errorf("Expected every result exactly once across pages, got %+v", paged)
+	}
+
+	// A cursor only fits the search it came from
+	_, next, _ = search(`{"query":"data subject right","limit":1}`)
+	if _, _, isError := search(`{"query":"erasure","limit":1,"cursor":"` + next + `"}`); !isError {
+		t.Error("Expected a cursor of another search to be rejected")
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+var definitionsTool = MCPTool{
+	Name:        "gdpr_definitions",
+	Description: "Get the Article 4 definition of a GDPR term such as \"controller\", \"profiling\" or \"personal data breach\". Misspelt and partial terms are matched; omit the term to list every defined term.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"term": map[string]interface{}{
+				"type":        "string",
+				"description": "Term to define, e.g. \"controller\"; close spellings and parts of a term also match",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleDefinitionsTool(id interface{}, args json.RawMessage) {
+	var definitionsArgs struct {
+		Term       string `json:"term"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &definitionsArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	s.chaos.delayDB()
+	definitions, err := s.db.Definitions(definitionsArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get definitions: "+err.Error())
+		return
+	}
+	if len(definitions) == 0 {
+		s.writeToolError(id, "No Article 4 definitions found; ingest the GDPR text first")
+		return
+	}
+
+	if definitionsArgs.Term == "" {
+		type term struct {
+			Point string `json:"point"`
+			Term  string `json:"term"`
+		}
+		terms := make([]term, len(definitions))
+		for i, d := range definitions {
+			terms[i] = term{d.Point, d.Term}
+		}
+		s.writeToolJSON(id, terms)
+		return
+	}
+
+	matches := db.MatchDefinitions(definitions, definitionsArgs.Term)
+	if len(matches) == 0 {
+		s.writeToolError(id, fmt.Sprintf("No Article 4 definition matches %q; omit the term to list every defined term", definitionsArgs.Term))
+		return
+	}
+	s.writeToolJSON(id, matches)
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerDefinitionsTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	if text, isError := callTool(t, srv, "gdpr_definitions", `{"term":"controller"}`); !isError {
+		t.Errorf("Expected an error without Article 4, got %s", text)
+	}
+
+	article4 := "Article 4 Definitions\n(7) 'controller' means the natural or legal person which determines the purposes and means of the processing;\n" +
+		"(8) 'processor' means a natural or legal person which processes personal data on behalf of the controller;"
+	if _, err := database.InsertDocument(db.Document{Chunk: article4, Source: "gdpr.txt", Metadata: map[string]string{"article": "4"}}); err != nil {
+		t.Fatalf("Failed to insert document: %v", err)
+	}
+
+	text, isError := callTool(t, srv, "gdpr_definitions", `{"term":"controler"}`)
+	if isError {
+		t.Fatalf("gdpr_definitions failed: %s", text)
+	}
+	var definitions []db.Definition
+	if err := json.Unmarshal([]byte(text), &definitions); err != nil {
+		t.Fatalf("Failed to parse definitions: %v", err)
+	}
+	if len(definitions) != 1 || definitions[0].Term != "controller" || definitions[0].Point != "7" {
+		t.Errorf("Expected the controller definition, got %+v", definitions)
+	}
+
+	text, _ = callTool(t, srv, "gdpr_definitions", `{}`)
+	if text != `[{"point":"7","term":"controller"},{"point":"8","term":"processor"}]` {
+		t.Errorf("Expected every defined term listed, got %s", text)
+	}
+
+	if text, isError := callTool(t, srv, "gdpr_definitions", `{"term":"cookie"}`); !isError {
+		t.Errorf("Expected an unknown term to fail, got %s", text)
+	}
+}
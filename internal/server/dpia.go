@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var dpiaTool = MCPTool{
+	Name:        "dpia_template",
+	Description: "Get a Markdown data protection impact assessment (Article 35) template: a screening checklist of the nine WP248 high-risk criteria, sections for the minimum content of Article 35(7) with placeholders, and the text of Article 35",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"project": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the project or processing operation",
+			},
+			"processing": map[string]interface{}{
+				"type":        "string",
+				"description": "Description of the processing to fill into the template; left as a placeholder when omitted",
+			},
+			"criteria": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string", "enum": criterionIDs()},
+				"description": "WP248 criteria the processing is known to meet, ticked in the screening checklist with a verdict on whether a DPIA is likely required",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+// criterionIDs lists the IDs of the WP248 criteria
+func criterionIDs() []string {
+	ids := make([]string, len(guidance.WP248Criteria))
+	for i, c := range guidance.WP248Criteria {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func (s *Server) handleDPIATool(id interface{}, args json.RawMessage) {
+	var dpiaArgs struct {
+		Project    string   `json:"project"`
+		Processing string   `json:"processing"`
+		Criteria   []string `json:"criteria"`
+		Collection string   `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &dpiaArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	s.chaos.delayDB()
+	article, err := s.db.Article("35", dpiaArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get Article 35: "+err.Error())
+		return
+	}
+	in := guidance.DPIAInput{
+		Project:    dpiaArgs.Project,
+		Processing: dpiaArgs.Processing,
+		Criteria:   dpiaArgs.Criteria,
+	}
+	var notes []string
+	if article != nil {
+		in.ArticleText = article.Text
+	} else {
+		notes = append(notes, "Article 35 was not found in the index, so its text is not included; ingest the GDPR text to add it")
+	}
+
+	template, err := guidance.DPIATemplate(in)
+	if err != nil {
+		s.writeToolError(id, err.Error())
+		return
+	}
+	s.writeToolResult(id, template, notes...)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerDPIATool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	request := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"dpia_template","arguments":{"project":"CCTV"}}}`
+	resp := captureServerOutput(t, srv, request)
+	content := resp["result"].(map[string]interface{})["content"].([]interface{})
+	if len(content) != 2 || !strings.Contains(content[1].(map[string]interface{})["text"].(string), "Article 35 was not found") {
+		t.Errorf("Expected a note that Article 35 is missing, got %+v", content)
+	}
+
+	if _, err := database.InsertDocument(db.Document{
+		Chunk:    "Article 35 Data protection impact assessment\n1. Where a type of processing is likely to result in a high risk",
+		Metadata: map[string]string{"article": "35"},
+	}); err != nil {
+		t.Fatalf("Failed to insert document: %v", err)
+	}
+	text, isError := callTool(t, srv, "dpia_template", `{"project":"CCTV","criteria":["monitoring","large-scale"]}`)
+	if isError {
+		t.Fatalf("dpia_template failed: %s", text)
+	}
+	if !strings.Contains(text, "# Data Protection Impact Assessment: CCTV") || !strings.Contains(text, "a DPIA is likely required") ||
+		!strings.Contains(text, "> 1. Where a type of processing is likely to result in a high risk") {
+		t.Errorf("Expected the template with the screening result and Article 35, got %s", text)
+	}
+
+	if text, isError := callTool(t, srv, "dpia_template", `{"criteria":["unknown"]}`); !isError {
+		t.Errorf("Expected an unknown criterion to fail, got %s", text)
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+var edpbTool = MCPTool{
+	Name:        "edpb_guidelines_search",
+	Description: "Search EDPB and Article 29 Working Party guidelines, recommendations and opinions ingested with the edpb profile. Each hit reports the document (e.g. \"Guidelines 05/2020\" or \"WP248 rev.01\"), section and numbered paragraph it comes from, for citation.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Search query",
+			},
+			"guideline": map[string]interface{}{
+				"type":        "string",
+				"description": "Only search this document, e.g. \"Guidelines 05/2020\" or \"WP248\" for every revision",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Max results (default: 10, max: %d)", maxSearchLimit),
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the guidelines were ingested into (default: " + ingest.EDPBCollection + ")",
+			},
+		},
+		Required: []string{"query"},
+	},
+}
+
+// guidelineResult is a chunk of a guideline matching a search
+type guidelineResult struct {
+	ID           int64   `json:"id"`
+	Score        float64 `json:"score"`
+	Snippet      string  `json:"snippet"`
+	Guideline    string  `json:"guideline,omitempty"`
+	Section      string  `json:"section,omitempty"`
+	SectionTitle string  `json:"section_title,omitempty"`
+	Paragraph    string  `json:"paragraph,omitempty"`
+	Citation     string  `json:"citation,omitempty"` // e.g. "Guidelines 05/2020, section 3.1.1, para. 13"
+}
+
+func (s *Server) handleEDPBTool(id interface{}, args json.RawMessage) {
+	var edpbArgs struct {
+		Query      string `json:"query"`
+		Guideline  string `json:"guideline"`
+		Limit      int    `json:"limit"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &edpbArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if edpbArgs.Query == "" {
+		s.writeToolError(id, "Query is required")
+		return
+	}
+	if edpbArgs.Limit <= 0 {
+		edpbArgs.Limit = 10
+	}
+	if edpbArgs.Limit > maxSearchLimit {
+		edpbArgs.Limit = maxSearchLimit
+	}
+	if edpbArgs.Collection == "" {
+		edpbArgs.Collection = ingest.EDPBCollection
+	}
+	guideline := ingest.GuidelineID(edpbArgs.Guideline)
+	if guideline == "" {
+		guideline = strings.TrimSpace(edpbArgs.Guideline)
+	}
+
+	results, err := s.search(edpbArgs.Query, edpbArgs.Limit, db.SearchOptions{Collection: edpbArgs.Collection, Guideline: guideline})
+	if err != nil {
+		s.writeToolError(id, "Search failed: "+err.Error())
+		return
+	}
+	results = db.NormalizeScores(results)
+
+	hits := make([]guidelineResult, 0, len(results))
+	for _, r := range results {
+		doc, err := s.db.GetDocument(r.ID)
+		if err != nil {
+			s.writeToolError(id, "Failed to get document: "+err.Error())
+			return
+		}
+		if doc == nil {
+			continue
+		}
+		hit := guidelineResult{
+			ID:           r.ID,
+			Score:        r.Score,
+			Snippet:      r.Snippet,
+			Guideline:    doc.Metadata[ingest.MetaGuideline],
+			Section:      doc.Metadata[ingest.MetaGuidelineSection],
+			SectionTitle: doc.Metadata[ingest.MetaGuidelineSectionTitle],
+			Paragraph:    doc.Metadata[ingest.MetaGuidelineParagraph],
+		}
+		var citation []string
+		for _, part := range []struct{ prefix, value string }{
+			{"", hit.Guideline}, {"section ", hit.Section}, {"para. ", hit.Paragraph},
+		} {
+			if part.value != "" {
+				citation = append(citation, part.prefix+part.value)
+			}
+		}
+		hit.Citation = strings.Join(citation, ", ")
+		hits = append(hits, hit)
+	}
+	s.writeToolJSON(id, hits)
+}
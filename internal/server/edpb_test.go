@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+func TestServerEDPBTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []struct {
+		chunk    string
+		metadata map[string]string
+	}{
+		{
+			"13. Recital 43 clearly indicates that it is unlikely that public authorities can rely on consent.",
+			map[string]string{ingest.MetaGuideline: "Guidelines 05/2020", ingest.MetaGuidelineSection: "3.1.1", ingest.MetaGuidelineSectionTitle: "Imbalance of power", ingest.MetaGuidelineParagraph: "13"},
+		},
+		{
+			"Processing by public authorities likely to result in a high risk requires a DPIA.",
+			map[string]string{ingest.MetaGuideline: "WP248 rev.01", ingest.MetaGuidelineSection: "III"},
+		},
+	} {
+		docID, err := database.InsertDocument(db.Document{Chunk: d.chunk, Metadata: d.metadata, Collection: ingest.EDPBCollection})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, db.GenerateTrigrams(d.chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+		ids = append(ids, docID)
+	}
+	srv := New(database, Config{})
+
+	var hits []guidelineResult
+	text, isError := callTool(t, srv, "edpb_guidelines_search", `{"query":"public authorities consent"}`)
+	if isError || json.Unmarshal([]byte(text), &hits) != nil {
+		t.Fatalf("edpb_guidelines_search failed: %s", text)
+	}
+	if len(hits) != 2 || hits[0].ID != ids[0] {
+		t.Fatalf("Expected both chunks, the consent guidelines first, got %s", text)
+	}
+	if hits[0].Paragraph != "13" || hits[0].SectionTitle != "Imbalance of power" || hits[0].Citation != "Guidelines 05/2020, section 3.1.1, para. 13" {
+		t.Errorf("Unexpected hit %+v", hits[0])
+	}
+
+	hits = nil
+	text, _ = callTool(t, srv, "edpb_guidelines_search", `{"query":"public authorities","guideline":"WP 248"}`)
+	if json.Unmarshal([]byte(text), &hits) != nil || len(hits) != 1 || hits[0].ID != ids[1] || hits[0].Citation != "WP248 rev.01, section III" {
+		t.Errorf("Expected the DPIA guidelines alone, got %s", text)
+	}
+
+	if text, isError := callTool(t, srv, "edpb_guidelines_search", `{}`); !isError {
+		t.Errorf("Expected a missing query to fail, got %s", text)
+	}
+}
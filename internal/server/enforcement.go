@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+var enforcementTool = MCPTool{
+	Name:        "enforcement_decisions",
+	Description: "Search published GDPR enforcement decisions and fines ingested with the enforcement profile, optionally only those finding a given article violated or taken by an authority of a given country. Returns the authority, country, date, fined controller, amount, violated articles and a snippet of the summary.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "What the decisions should be about, e.g. \"children's data on social media\" or \"insufficient security measures\"",
+			},
+			"article": map[string]interface{}{
+				"type":        "string",
+				"description": "Only decisions finding this GDPR article violated, e.g. \"32\" or \"Art. 32\"",
+			},
+			"country": map[string]interface{}{
+				"type":        "string",
+				"description": "Only decisions of an authority of this country, as an ISO 3166-1 alpha-2 code, e.g. \"IE\"",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Max decisions (default: 10, max: %d)", maxSearchLimit),
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the decisions were ingested into (default: " + ingest.EnforcementCollection + ")",
+			},
+		},
+		Required: []string{"query"},
+	},
+}
+
+// decisionResult is an enforcement decision matching a search
+type decisionResult struct {
+	Decision   string   `json:"decision,omitempty"`
+	Authority  string   `json:"authority"`
+	Country    string   `json:"country,omitempty"`
+	Date       string   `json:"date,omitempty"`
+	Controller string   `json:"controller,omitempty"`
+	Fine       float64  `json:"fine,omitempty"`
+	Currency   string   `json:"currency,omitempty"`
+	Articles   []string `json:"articles,omitempty"` // articles found violated
+	URL        string   `json:"url,omitempty"`
+	ID         int64    `json:"id"` // best matching chunk
+	Score      float64  `json:"score"`
+	Snippet    string   `json:"snippet"`
+}
+
+func (s *Server) handleEnforcementTool(id interface{}, args json.RawMessage) {
+	var enforcementArgs struct {
+		Query      string `json:"query"`
+		Article    string `json:"article"`
+		Country    string `json:"country"`
+		Limit      int    `json:"limit"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &enforcementArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if enforcementArgs.Query == "" {
+		s.writeToolError(id, "Query is required")
+		return
+	}
+	if enforcementArgs.Limit <= 0 {
+		enforcementArgs.Limit = 10
+	}
+	if enforcementArgs.Limit > maxSearchLimit {
+		enforcementArgs.Limit = maxSearchLimit
+	}
+	if enforcementArgs.Collection == "" {
+		enforcementArgs.Collection = ingest.EnforcementCollection
+	}
+	opts := db.SearchOptions{Collection: enforcementArgs.Collection, Country: enforcementArgs.Country}
+	if enforcementArgs.Article != "" {
+		article, ok := db.ArticleNumber(enforcementArgs.Article)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid article %q", enforcementArgs.Article))
+			return
+		}
+		opts.ViolatedArticle = article
+	}
+
+	results, err := s.search(enforcementArgs.Query, enforcementArgs.Limit, opts)
+	if err != nil {
+		s.writeToolError(id, "Search failed: "+err.Error())
+		return
+	}
+	results = db.NormalizeScores(results)
+
+	// A long summary spans several chunks; report each decision once, at
+	// its best matching chunk
+	decisions := make([]decisionResult, 0, len(results))
+	seen := make(map[string]bool)
+	for _, r := range results {
+		doc, err := s.db.GetDocument(r.ID)
+		if err != nil {
+			s.writeToolError(id, "Failed to get document: "+err.Error())
+			return
+		}
+		if doc == nil {
+			continue
+		}
+		m := doc.Metadata
+		key := m[ingest.MetaDecision]
+		if key == "" {
+			key = m[ingest.MetaAuthority] + "\x00" + m[ingest.MetaController] + "\x00" + m[ingest.MetaDecisionDate]
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		d := decisionResult{
+			Decision:   m[ingest.MetaDecision],
+			Authority:  m[ingest.MetaAuthority],
+			Country:    m[ingest.MetaCountry],
+			Date:       m[ingest.MetaDecisionDate],
+			Controller: m[ingest.MetaController],
+			Currency:   m[ingest.MetaCurrency],
+			URL:        m[ingest.MetaDecisionURL],
+			ID:         r.ID,
+			Score:      r.Score,
+			Snippet:    r.Snippet,
+		}
+		d.Fine, _ = strconv.ParseFloat(m[ingest.MetaFine], 64)
+		if articles := m[ingest.MetaViolatedArticles]; articles != "" {
+			d.Articles = strings.Split(articles, ",")
+		}
+		decisions = append(decisions, d)
+	}
+
+	s.writeToolJSON(id, decisions)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+func TestServerEnforcementTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []struct {
+		chunk    string
+		metadata map[string]string
+	}{
+		{
+			"Data Protection Commission (IE): Meta Platforms Ireland Limited, fine for infringing Art. 46(1) GDPR. Transfers of personal data to the United States.",
+			map[string]string{ingest.MetaDecision: "IN-18-5-5", ingest.MetaAuthority: "Data Protection Commission", ingest.MetaCountry: "IE", ingest.MetaFine: "1200000000", ingest.MetaCurrency: "EUR", ingest.MetaViolatedArticles: "46"},
+		},
+		{
+			"Second part of the summary: transfers of personal data continued after the judgment.",
+			map[string]string{ingest.MetaDecision: "IN-18-5-5", ingest.MetaAuthority: "Data Protection Commission", ingest.MetaCountry: "IE", ingest.MetaViolatedArticles: "46"},
+		},
+		{
+			"CNIL (FR): Google LLC, fine for infringing Art. 6, Art. 13 GDPR. Transfers of personal data were not at issue.",
+			map[string]string{ingest.MetaAuthority: "CNIL", ingest.MetaCountry: "FR", ingest.MetaViolatedArticles: "6,13"},
+		},
+	} {
+		docID, err := database.InsertDocument(db.Document{Chunk: d.chunk, Metadata: d.metadata, Collection: ingest.EnforcementCollection})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, db.GenerateTrigrams(d.chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+		ids = append(ids, docID)
+	}
+	srv := New(database, Config{})
+
+	var decisions []decisionResult
+	text, isError := callTool(t, srv, "enforcement_decisions", `{"query":"transfers of personal data"}`)
+	if isError || json.Unmarshal([]byte(text), &decisions) != nil {
+		t.Fatalf("enforcement_decisions failed: %s", text)
+	}
+	// The two chunks of the Irish decision are reported once
+	if len(decisions) != 2 {
+		t.Fatalf("Expected 2 decisions, got %s", text)
+	}
+	if d := decisions[0]; d.Decision != "IN-18-5-5" || d.Fine != 1200000000 || d.Currency != "EUR" || len(d.Articles) != 1 || d.Articles[0] != "46" {
+		t.Errorf("Expected the Irish decision first, got %+v", d)
+	}
+
+	decisions = nil
+	text, _ = callTool(t, srv, "enforcement_decisions", `{"query":"transfers of personal data","article":"Art. 13","country":"fr"}`)
+	if json.Unmarshal([]byte(text), &decisions) != nil || len(decisions) != 1 || decisions[0].ID != ids[2] {
+		t.Errorf("Expected the French decision alone, got %s", text)
+	}
+
+	for _, args := range []string{`{}`, `{"query":"fines","article":"first"}`} {
+		if text, isError := callTool(t, srv, "enforcement_decisions", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
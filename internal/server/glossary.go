@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var glossaryTool = MCPTool{
+	Name:        "gdpr_glossary",
+	Description: "Look up a practitioner term the GDPR does not define in Article 4, such as DPIA, SAR, BCR or privacy by design, with the articles and recitals it rests on. Use gdpr_definitions for the terms Article 4 defines. Without a term, list the glossary.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"term": map[string]interface{}{
+				"type":        "string",
+				"description": "Term or abbreviation to look up, e.g. \"DPIA\", \"subject access request\" or \"one-stop-shop\"",
+			},
+		},
+	},
+}
+
+// glossaryResult is a glossary entry with the short citations of its
+// provisions
+type glossaryResult struct {
+	guidance.GlossaryEntry
+	Provisions []string `json:"provisions"`
+}
+
+func (s *Server) handleGlossaryTool(id interface{}, args json.RawMessage) {
+	var glossaryArgs struct {
+		Term string `json:"term"`
+	}
+	if err := json.Unmarshal(args, &glossaryArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	if glossaryArgs.Term == "" {
+		type term struct {
+			ID      string   `json:"id"`
+			Term    string   `json:"term"`
+			Aliases []string `json:"aliases"`
+		}
+		terms := make([]term, len(guidance.Glossary))
+		for i, e := range guidance.Glossary {
+			terms[i] = term{e.ID, e.Term, e.Aliases}
+		}
+		s.writeToolJSON(id, terms)
+		return
+	}
+
+	entries := guidance.MatchGlossary(glossaryArgs.Term)
+	if len(entries) == 0 {
+		s.writeToolError(id, fmt.Sprintf("No glossary entry matches %q; try gdpr_definitions for the terms defined in Article 4, or omit the term to list the glossary", glossaryArgs.Term))
+		return
+	}
+	results := make([]glossaryResult, len(entries))
+	for i, e := range entries {
+		results[i] = glossaryResult{e, e.Provisions()}
+	}
+	s.writeToolJSON(id, results)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerGlossaryTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	srv := New(database, Config{})
+
+	var terms []struct {
+		ID   string `json:"id"`
+		Term string `json:"term"`
+	}
+	text, isError := callTool(t, srv, "gdpr_glossary", `{}`)
+	if isError || json.Unmarshal([]byte(text), &terms) != nil || len(terms) != len(guidance.Glossary) {
+		t.Fatalf("Expected the glossary, got %s", text)
+	}
+
+	var results []glossaryResult
+	text, isError = callTool(t, srv, "gdpr_glossary", `{"term":"DPIA"}`)
+	if isError || json.Unmarshal([]byte(text), &results) != nil {
+		t.Fatalf("gdpr_glossary failed: %s", text)
+	}
+	if len(results) != 1 || results[0].ID != "dpia" || results[0].Definition == "" {
+		t.Fatalf("Expected the DPIA, got %s", text)
+	}
+	if p := results[0].Provisions; len(p) == 0 || p[0] != "Art. 35 GDPR" {
+		t.Errorf("Expected Article 35 first, got %v", p)
+	}
+
+	if text, isError := callTool(t, srv, "gdpr_glossary", `{"term":"personal data"}`); !isError {
+		t.Errorf("Expected an Article 4 term to fail, got %s", text)
+	}
+}
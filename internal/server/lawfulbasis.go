@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var lawfulBasisTool = MCPTool{
+	Name:        "lawful_basis",
+	Description: "List the six lawful bases of Article 6(1) with their conditions. Given a scenario, also suggest bases from cues in its wording and retrieve the most relevant chunks of the articles and recitals on lawful bases.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"scenario": map[string]interface{}{
+				"type":        "string",
+				"description": "Free-text description of the processing, e.g. \"sending order confirmations and delivery updates to online shoppers\"",
+			},
+			"basis": map[string]interface{}{
+				"type":        "string",
+				"enum":        lawfulBasisIDs(),
+				"description": "Only return this basis, and only retrieve from its articles and recitals",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Max article chunks and max recital chunks retrieved for a scenario (default: 5, max: %d)", maxSearchLimit),
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+// lawfulBasisIDs lists the IDs of the lawful bases
+func lawfulBasisIDs() []string {
+	ids := make([]string, len(guidance.LawfulBases))
+	for i, b := range guidance.LawfulBases {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+func (s *Server) handleLawfulBasisTool(id interface{}, args json.RawMessage) {
+	var basisArgs struct {
+		Scenario   string `json:"scenario"`
+		Basis      string `json:"basis"`
+		Limit      int    `json:"limit"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &basisArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if basisArgs.Limit <= 0 {
+		basisArgs.Limit = 5
+	}
+	if basisArgs.Limit > maxSearchLimit {
+		basisArgs.Limit = maxSearchLimit
+	}
+
+	bases := guidance.LawfulBases
+	if basisArgs.Basis != "" {
+		basis, ok := guidance.LawfulBasisByID(basisArgs.Basis)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Unknown basis %q", basisArgs.Basis))
+			return
+		}
+		bases = []guidance.LawfulBasis{basis}
+	}
+
+	output := struct {
+		Bases     []guidance.LawfulBasis `json:"bases"`
+		Suggested []guidance.BasisMatch  `json:"suggested,omitempty"`
+		Articles  []db.SearchResult      `json:"articles,omitempty"`
+		Recitals  []db.SearchResult      `json:"recitals,omitempty"`
+	}{Bases: bases}
+
+	if basisArgs.Scenario != "" {
+		if basisArgs.Basis == "" {
+			output.Suggested = guidance.SuggestBases(basisArgs.Scenario)
+		}
+		var articles, recitals []string
+		seen := make(map[string]bool)
+		for _, b := range bases {
+			for _, a := range b.Articles {
+				if !seen["a"+a] {
+					seen["a"+a] = true
+					articles = append(articles, a)
+				}
+			}
+			for _, r := range b.Recitals {
+				if !seen["r"+r] {
+					seen["r"+r] = true
+					recitals = append(recitals, r)
+				}
+			}
+		}
+
+		var err error
+		opts := db.SearchOptions{Collection: basisArgs.Collection, Articles: articles}
+		if output.Articles, err = s.search(basisArgs.Scenario, basisArgs.Limit, opts); err != nil {
+			s.writeToolError(id, "Search failed: "+err.Error())
+			return
+		}
+		opts = db.SearchOptions{Collection: basisArgs.Collection, Recitals: recitals}
+		if output.Recitals, err = s.search(basisArgs.Scenario, basisArgs.Limit, opts); err != nil {
+			s.writeToolError(id, "Search failed: "+err.Error())
+			return
+		}
+		output.Articles = db.NormalizeScores(output.Articles)
+		output.Recitals = db.NormalizeScores(output.Recitals)
+	}
+
+	s.writeToolJSON(id, output)
+}
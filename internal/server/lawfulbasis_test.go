@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerLawfulBasisTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []struct {
+		chunk    string
+		metadata map[string]string
+	}{
+		{"1. Processing shall be lawful only if (b) processing is necessary for the performance of a contract", map[string]string{"article": "6"}},
+		{"(44) Processing should be lawful where it is necessary in the context of a contract or the intention to enter into a contract.", map[string]string{"recital": "44"}},
+		{"(26) The principles of data protection should apply to any information concerning a contract partner.", map[string]string{"recital": "26"}},
+	} {
+		docID, err := database.InsertDocument(db.Document{Chunk: d.chunk, Metadata: d.metadata})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, db.GenerateTrigrams(d.chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+		ids = append(ids, docID)
+	}
+	srv := New(database, Config{})
+
+	var output struct {
+		Bases     []guidance.LawfulBasis `json:"bases"`
+		Suggested []guidance.BasisMatch  `json:"suggested"`
+		Articles  []db.SearchResult      `json:"articles"`
+		Recitals  []db.SearchResult      `json:"recitals"`
+	}
+	text, isError := callTool(t, srv, "lawful_basis", `{}`)
+	if isError || json.Unmarshal([]byte(text), &output) != nil || len(output.Bases) != 6 || output.Articles != nil {
+		t.Fatalf("Expected the six bases alone, got %s", text)
+	}
+
+	output.Bases = nil
+	text, isError = callTool(t, srv, "lawful_basis", `{"scenario":"processing customer data necessary for the contract of sale"}`)
+	if isError {
+		t.Fatalf("lawful_basis failed: %s", text)
+	}
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if len(output.Suggested) == 0 || output.Suggested[0].Basis != "contract" {
+		t.Errorf("Expected contract suggested, got %+v", output.Suggested)
+	}
+	if len(output.Articles) != 1 || output.Articles[0].ID != ids[0] {
+		t.Errorf("Expected the Article 6 chunk, got %+v", output.Articles)
+	}
+	// Recital 26 is not about lawful bases
+	if len(output.Recitals) != 1 || output.Recitals[0].ID != ids[1] {
+		t.Errorf("Expected recital 44 alone, got %+v", output.Recitals)
+	}
+
+	text, _ = callTool(t, srv, "lawful_basis", `{"basis":"consent"}`)
+	output.Bases = nil
+	if json.Unmarshal([]byte(text), &output) != nil || len(output.Bases) != 1 || output.Bases[0].ID != "consent" {
+		t.Errorf("Expected consent alone, got %s", text)
+	}
+	if text, isError := callTool(t, srv, "lawful_basis", `{"basis":"necessity"}`); !isError {
+		t.Errorf("Expected an unknown basis to fail, got %s", text)
+	}
+}
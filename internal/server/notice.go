@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var noticeTool = MCPTool{
+	Name:        "privacy_notice_analyzer",
+	Description: "Check the text of a privacy notice against the information Articles 13 and 14 require it to give, and report which elements appear present, incomplete or missing, each with the point of Article 13 or 14 requiring it. The check looks for the usual wording of each element, so its findings are pointers for review rather than a compliance verdict.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"text": map[string]interface{}{
+				"type":        "string",
+				"description": "Full text of the privacy notice",
+			},
+			"source": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{guidance.SourceDirect, guidance.SourceIndirect, guidance.SourceBoth},
+				"description": "Whether the notice covers data collected from the data subject (direct, Article 13), obtained elsewhere (indirect, Article 14) or both (default: direct)",
+			},
+		},
+		Required: []string{"text"},
+	},
+}
+
+func (s *Server) handleNoticeTool(id interface{}, args json.RawMessage) {
+	var noticeArgs struct {
+		Text   string `json:"text"`
+		Source string `json:"source"`
+	}
+	if err := json.Unmarshal(args, &noticeArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(noticeArgs.Text) == "" {
+		s.writeToolError(id, "Text is required")
+		return
+	}
+
+	analysis, err := guidance.AnalyzeNotice(noticeArgs.Text, noticeArgs.Source)
+	if err != nil {
+		s.writeToolError(id, err.Error())
+		return
+	}
+	s.writeToolJSON(id, analysis)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerNoticeTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	notice := "Acme Ltd is the controller. Contact us at privacy@acme.example. We use your data to deliver orders, on the legal basis of a contract."
+	args, _ := json.Marshal(map[string]string{"text": notice, "source": "indirect"})
+	text, isError := callTool(t, srv, "privacy_notice_analyzer", string(args))
+	var analysis guidance.NoticeAnalysis
+	if isError || json.Unmarshal([]byte(text), &analysis) != nil {
+		t.Fatalf("privacy_notice_analyzer failed: %s", text)
+	}
+	if analysis.Source != "indirect" || len(analysis.Present) == 0 || len(analysis.Missing) == 0 {
+		t.Fatalf("Expected present and missing elements, got %s", text)
+	}
+	var source bool
+	for _, f := range analysis.Missing {
+		if f.ID == "source" && f.Provision == "Art. 14(2)(f) GDPR" {
+			source = true
+		}
+	}
+	if !source {
+		t.Errorf("Expected the source of the data to be missing, got %+v", analysis.Missing)
+	}
+
+	for _, args := range []string{`{"text":" "}`, `{"text":"notice","source":"scraped"}`} {
+		if text, isError := callTool(t, srv, "privacy_notice_analyzer", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var piiTool = MCPTool{
+	Name:        "pii_detector",
+	Description: "Scan text for likely personal data, such as names, email addresses, phone numbers, IBANs, ID numbers and IP addresses, and for terms revealing special categories of data (health, ethnic origin, religion, biometrics and the others of Article 9(1)) or criminal offence data (Article 10). Each finding is classified under the GDPR with the provision defining it, and the WP248 DPIA criteria the findings point to are listed. Detection is pattern-based, so review the findings.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"text": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to scan, such as a data sample, form or processing description",
+			},
+		},
+		Required: []string{"text"},
+	},
+}
+
+func (s *Server) handlePIITool(id interface{}, args json.RawMessage) {
+	var piiArgs struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(args, &piiArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(piiArgs.Text) == "" {
+		s.writeToolError(id, "Text is required")
+		return
+	}
+	s.writeToolJSON(id, guidance.DetectPII(piiArgs.Text))
+}
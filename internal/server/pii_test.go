@@ -0,0 +1,28 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerPIITool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	text, isError := callTool(t, srv, "pii_detector", `{"text":"Contact Dr. Ana Silva at ana@example.org about her HIV treatment."}`)
+	var report guidance.PIIReport
+	if isError || json.Unmarshal([]byte(text), &report) != nil {
+		t.Fatalf("pii_detector failed: %s", text)
+	}
+	if len(report.Findings) != 3 || report.Findings[0].Text != "Ana Silva" || report.Findings[1].Category != "email" ||
+		report.Findings[2].Classification != guidance.ClassSpecialCategory || len(report.DPIACriteria) != 1 {
+		t.Errorf("Expected a name, an email address and health data, got %s", text)
+	}
+
+	if text, isError := callTool(t, srv, "pii_detector", `{"text":""}`); !isError {
+		t.Errorf("Expected empty text to fail, got %s", text)
+	}
+}
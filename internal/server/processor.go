@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var processorTool = MCPTool{
+	Name:        "processor_obligations",
+	Description: "Get the clauses Article 28(3) requires in a contract between a controller and a processor as a checklist, or check the text of a data processing agreement against it and report which clauses appear present, incomplete or missing. The check looks for the usual wording of each clause, so its findings are pointers for review rather than a compliance verdict.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"text": map[string]interface{}{
+				"type":        "string",
+				"description": "Text of the data processing agreement or vendor contract to check (default: return the checklist)",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into, for the text of Article 28 (default: the default collection)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleProcessorTool(id interface{}, args json.RawMessage) {
+	var processorArgs struct {
+		Text       string `json:"text"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &processorArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	if strings.TrimSpace(processorArgs.Text) != "" {
+		s.writeToolJSON(id, guidance.AnalyzeProcessorContract(processorArgs.Text))
+		return
+	}
+
+	s.chaos.delayDB()
+	article, err := s.db.Article("28", processorArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get Article 28: "+err.Error())
+		return
+	}
+	output := struct {
+		Clauses []guidance.ProcessorClause `json:"clauses"`
+		Article *db.ArticleResult          `json:"article,omitempty"`
+		Notes   []string                   `json:"notes,omitempty"`
+	}{Clauses: guidance.ProcessorClauses, Article: article}
+	if article == nil {
+		output.Notes = append(output.Notes, "Article 28 was not found in the index, so its text is not included; ingest the GDPR text to add it")
+	}
+	s.writeToolJSON(id, output)
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerProcessorTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	srv := New(database, Config{})
+
+	var checklist struct {
+		Clauses []guidance.ProcessorClause `json:"clauses"`
+		Notes   []string                   `json:"notes"`
+	}
+	text, isError := callTool(t, srv, "processor_obligations", `{}`)
+	if isError || json.Unmarshal([]byte(text), &checklist) != nil {
+		t.Fatalf("processor_obligations failed: %s", text)
+	}
+	if len(checklist.Clauses) != len(guidance.ProcessorClauses) || checklist.Clauses[1].Provision != "Art. 28(3)(a) GDPR" || len(checklist.Notes) != 1 {
+		t.Errorf("Expected the checklist and a note on the missing article, got %s", text)
+	}
+
+	var analysis guidance.ContractAnalysis
+	text, isError = callTool(t, srv, "processor_obligations", `{"text":"The Processor shall keep the personal data confidential."}`)
+	if isError || json.Unmarshal([]byte(text), &analysis) != nil {
+		t.Fatalf("processor_obligations failed: %s", text)
+	}
+	if analysis.Covered != 1 || analysis.Present[0].ID != "confidentiality" || analysis.Total != len(guidance.ProcessorClauses) {
+		t.Errorf("Expected confidentiality alone, got %s", text)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+// Bounds of the questions per gdpr_quiz call
+const (
+	defaultQuizQuestions = 5
+	maxQuizQuestions     = 20
+)
+
+var quizTool = MCPTool{
+	Name:        "gdpr_quiz",
+	Description: "Generate multiple-choice questions for compliance training from randomly chosen GDPR articles, each with the correct answer, an explanation and the citation of the provision it is based on",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"count": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of questions (default: %d, at most %d)", defaultQuizQuestions, maxQuizQuestions),
+			},
+			"chapter": map[string]interface{}{
+				"type":        "string",
+				"description": "Only ask about the articles of this chapter, e.g. \"III\" or \"3\" for the rights of the data subject",
+			},
+			"seed": map[string]interface{}{
+				"type":        "integer",
+				"description": "Random seed returned by an earlier quiz, to ask the same questions again (default: a new quiz)",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleQuizTool(id interface{}, args json.RawMessage) {
+	var quizArgs struct {
+		Count      int    `json:"count"`
+		Chapter    string `json:"chapter"`
+		Seed       *int64 `json:"seed"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &quizArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if quizArgs.Count == 0 {
+		quizArgs.Count = defaultQuizQuestions
+	}
+	if quizArgs.Count < 1 || quizArgs.Count > maxQuizQuestions {
+		s.writeToolError(id, fmt.Sprintf("count must be between 1 and %d", maxQuizQuestions))
+		return
+	}
+	chapter := ""
+	if quizArgs.Chapter != "" {
+		var ok bool
+		if chapter, ok = db.ChapterNumber(quizArgs.Chapter); !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid chapter %q", quizArgs.Chapter))
+			return
+		}
+	}
+	seed := time.Now().UnixNano()
+	if quizArgs.Seed != nil {
+		seed = *quizArgs.Seed
+	}
+
+	s.chaos.delayDB()
+	toc, err := s.db.TableOfContents(quizArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get table of contents: "+err.Error())
+		return
+	}
+	var articles []guidance.QuizArticle
+	for _, c := range toc {
+		if chapter != "" && c.Chapter != chapter {
+			continue
+		}
+		for _, a := range chapterArticles(c) {
+			article, err := s.db.Article(a.Article, quizArgs.Collection)
+			if err != nil {
+				s.writeToolError(id, "Failed to get article: "+err.Error())
+				return
+			}
+			if article != nil {
+				articles = append(articles, guidance.QuizArticle{Number: article.Article, Title: article.Title, Text: article.Text})
+			}
+		}
+	}
+
+	questions, err := guidance.GenerateQuiz(articles, quizArgs.Count, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		s.writeToolError(id, "Cannot generate a quiz: "+err.Error()+"; ingest the GDPR text first")
+		return
+	}
+	s.writeToolJSON(id, struct {
+		Seed      int64                   `json:"seed"`
+		Questions []guidance.QuizQuestion `json:"questions"`
+	}{seed, questions})
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+func TestServerQuizTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	srv := New(database, Config{})
+
+	if text, isError := callTool(t, srv, "gdpr_quiz", `{}`); !isError {
+		t.Errorf("Expected an error without articles, got %s", text)
+	}
+
+	for i, a := range []struct{ chapter, article, title, text string }{
+		{"III", "15", "Right of access by the data subject", "1. The data subject shall have the right to obtain confirmation."},
+		{"III", "17", "Right to erasure ('right to be forgotten')", "1. The data subject shall have the right to obtain erasure."},
+		{"III", "20", "Right to data portability", "1. The data subject shall have the right to receive the personal data."},
+		{"III", "21", "Right to object", "1. The data subject shall have the right to object."},
+		{"IV", "33", "Notification of a personal data breach to the supervisory authority", "1. In the case of a personal data breach, the controller shall notify it not later than 72 hours after having become aware of it."},
+	} {
+		metadata := map[string]string{"chapter": a.chapter, "article": a.article, "article_title": a.title}
+		if _, err := database.InsertDocument(db.Document{Chunk: a.text, ChunkIndex: i, Metadata: metadata}); err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
+
+	var quiz struct {
+		Seed      int64                   `json:"seed"`
+		Questions []guidance.QuizQuestion `json:"questions"`
+	}
+	text, isError := callTool(t, srv, "gdpr_quiz", `{"count":3,"seed":42}`)
+	if isError || json.Unmarshal([]byte(text), &quiz) != nil {
+		t.Fatalf("gdpr_quiz failed: %s", text)
+	}
+	if quiz.Seed != 42 || len(quiz.Questions) != 3 {
+		t.Fatalf("Expected 3 questions for seed 42, got %s", text)
+	}
+	if again, _ := callTool(t, srv, "gdpr_quiz", `{"count":3,"seed":42}`); again != text {
+		t.Errorf("Expected the same quiz for the same seed, got %s and %s", text, again)
+	}
+
+	quiz.Questions = nil
+	text, _ = callTool(t, srv, "gdpr_quiz", `{"count":8,"chapter":"3"}`)
+	if json.Unmarshal([]byte(text), &quiz) != nil || len(quiz.Questions) != 8 {
+		t.Fatalf("Expected 8 questions on Chapter III, got %s", text)
+	}
+	articles := make(map[string]bool)
+	for _, q := range quiz.Questions {
+		articles[q.Article] = true
+	}
+	if want := map[string]bool{"15": true, "17": true, "20": true, "21": true}; !reflect.DeepEqual(articles, want) {
+		t.Errorf("Expected questions on the articles of Chapter III, got %v", articles)
+	}
+
+	for _, args := range []string{`{"count":21}`, `{"count":-1}`, `{"chapter":"first"}`, `{"chapter":"IV"}`} {
+		if text, isError := callTool(t, srv, "gdpr_quiz", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
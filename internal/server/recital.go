@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+var recitalTool = MCPTool{
+	Name:        "gdpr_recital",
+	Description: "Get a GDPR recital by number, or the recitals that explain an article. Recitals are not binding but are where the regulation's interpretive context lives.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"number": map[string]interface{}{
+				"type":        "string",
+				"description": "Recital to return, e.g. \"26\" or \"Recital 26\"",
+			},
+			"article": map[string]interface{}{
+				"type":        "string",
+				"description": "Return the recitals associated with this article instead, e.g. \"17\" or \"Art. 17\"",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleRecitalTool(id interface{}, args json.RawMessage) {
+	var recitalArgs struct {
+		Number     string `json:"number"`
+		Article    string `json:"article"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &recitalArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	switch {
+	case recitalArgs.Number == "" && recitalArgs.Article == "":
+		s.writeToolError(id, "number or article is required")
+		return
+	case recitalArgs.Number != "" && recitalArgs.Article != "":
+		s.writeToolError(id, "number cannot be combined with article")
+		return
+	}
+
+	s.chaos.delayDB()
+	if recitalArgs.Number != "" {
+		number, ok := db.RecitalNumber(recitalArgs.Number)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid recital %q", recitalArgs.Number))
+			return
+		}
+		recital, err := s.db.Recital(number, recitalArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get recital: "+err.Error())
+			return
+		}
+		if recital == nil {
+			s.writeToolError(id, fmt.Sprintf("Recital %s not found", number))
+			return
+		}
+		s.writeToolJSON(id, recital)
+		return
+	}
+
+	article, ok := db.ArticleNumber(recitalArgs.Article)
+	if !ok {
+		s.writeToolError(id, fmt.Sprintf("Invalid article %q", recitalArgs.Article))
+		return
+	}
+	numbers, err := s.db.ArticleRecitals(article)
+	if err != nil {
+		s.writeToolError(id, "Failed to get recitals: "+err.Error())
+		return
+	}
+	result := struct {
+		Article  string       `json:"article"`
+		Recitals []db.Recital `json:"recitals"`
+		Missing  []string     `json:"missing,omitempty"` // associated recitals that were not ingested
+	}{Article: article, Recitals: []db.Recital{}}
+	for _, number := range numbers {
+		recital, err := s.db.Recital(number, recitalArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get recital: "+err.Error())
+			return
+		}
+		if recital == nil {
+			result.Missing = append(result.Missing, number)
+			continue
+		}
+		result.Recitals = append(result.Recitals, *recital)
+	}
+	s.writeToolJSON(id, result)
+}
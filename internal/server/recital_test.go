@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerRecitalTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i, d := range []struct {
+		chunk   string
+		recital string
+	}{
+		{"(65) A data subject should have the right to have personal data concerning him or her rectified and a 'right to be forgotten'.", "65"},
+		{"(66) To strengthen the right to be forgotten in the online environment, the right to erasure should also be extended.", "66"},
+	} {
+		if _, err := database.InsertDocument(db.Document{Chunk: d.chunk, ChunkIndex: i, Source: "gdpr.txt", Metadata: map[string]string{"recital": d.recital}}); err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
+	srv := New(database, Config{})
+
+	text, isError := callTool(t, srv, "gdpr_recital", `{"number":"Recital 65"}`)
+	if isError {
+		t.Fatalf("gdpr_recital failed: %s", text)
+	}
+	var recital db.Recital
+	if err := json.Unmarshal([]byte(text), &recital); err != nil {
+		t.Fatalf("Failed to parse recital: %v", err)
+	}
+	if recital.Number != "65" || !strings.HasPrefix(recital.Text, "(65)") || len(recital.ChunkIDs) != 1 {
+		t.Errorf("Expected recital 65, got %+v", recital)
+	}
+
+	text, isError = callTool(t, srv, "gdpr_recital", `{"article":"Art. 17"}`)
+	if isError {
+		t.Fatalf("gdpr_recital failed: %s", text)
+	}
+	var related struct {
+		Article  string       `json:"article"`
+		Recitals []db.Recital `json:"recitals"`
+		Missing  []string     `json:"missing"`
+	}
+	if err := json.Unmarshal([]byte(text), &related); err != nil {
+		t.Fatalf("Failed to parse recitals: %v", err)
+	}
+	if related.Article != "17" || len(related.Recitals) != 2 || related.Recitals[0].Number != "65" || related.Recitals[1].Number != "66" {
+		t.Errorf("Expected recitals 65 and 66 for Article 17, got %+v", related)
+	}
+
+	// Recitals the table names but that were not ingested are reported
+	text, _ = callTool(t, srv, "gdpr_recital", `{"article":"20"}`)
+	if !strings.Contains(text, `"missing":["68"]`) {
+		t.Errorf("Expected recital 68 reported missing, got %s", text)
+	}
+
+	for _, args := range []string{`{}`, `{"number":"65","article":"17"}`, `{"number":"sixty"}`, `{"number":"99"}`} {
+		if text, isError := callTool(t, srv, "gdpr_recital", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+var relatedTool = MCPTool{
+	Name:        "gdpr_related",
+	Description: "Find chunks semantically similar to a chunk or a whole article (\"more like this\"), for exploring connected provisions, recitals and guidance",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"id": map[string]interface{}{
+				"type":        "integer",
+				"description": "Chunk ID, e.g. from gdpr_search",
+			},
+			"article": map[string]interface{}{
+				"type":        "string",
+				"description": "Find chunks similar to this whole article instead, e.g. \"17\" or \"Art. 17\"; its own chunks are left out",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Only return chunks from this collection; also the collection the article is looked up in (default: all collections, and the default collection for the article)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Max results (default: 10, max: %d)", maxSearchLimit),
+			},
+		},
+	},
+}
+
+func (s *Server) handleRelatedTool(id interface{}, args json.RawMessage) {
+	var relatedArgs struct {
+		ID         int64  `json:"id"`
+		Article    string `json:"article"`
+		Collection string `json:"collection"`
+		Limit      int    `json:"limit"`
+	}
+	if err := json.Unmarshal(args, &relatedArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	switch {
+	case relatedArgs.ID <= 0 && relatedArgs.Article == "":
+		s.writeToolError(id, "id or article is required")
+		return
+	case relatedArgs.ID > 0 && relatedArgs.Article != "":
+		s.writeToolError(id, "id cannot be combined with article")
+		return
+	}
+	if relatedArgs.Limit <= 0 {
+		relatedArgs.Limit = 10
+	}
+	if relatedArgs.Limit > maxSearchLimit {
+		relatedArgs.Limit = maxSearchLimit
+	}
+
+	s.chaos.delayDB()
+	var ids []int64
+	if relatedArgs.ID > 0 {
+		doc, err := s.db.GetDocument(relatedArgs.ID)
+		if err != nil {
+			s.writeToolError(id, "Failed to get document: "+err.Error())
+			return
+		}
+		if doc == nil || doc.DeletedAt != nil {
+			s.writeToolError(id, "Document not found")
+			return
+		}
+		ids = []int64{doc.ID}
+	} else {
+		article, ok := db.ArticleNumber(relatedArgs.Article)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid article %q", relatedArgs.Article))
+			return
+		}
+		var err error
+		if ids, err = s.db.ArticleChunkIDs(article, relatedArgs.Collection); err != nil {
+			s.writeToolError(id, "Failed to get article: "+err.Error())
+			return
+		}
+		if len(ids) == 0 {
+			s.writeToolError(id, fmt.Sprintf("Article %s not found", article))
+			return
+		}
+	}
+
+	results, err := s.db.Related(ids, relatedArgs.Limit, db.SearchOptions{Collection: relatedArgs.Collection})
+	if errors.Is(err, db.ErrNoEmbedding) {
+		s.writeToolError(id, "No embedding stored for this chunk; re-ingest with an embedder to find related chunks")
+		return
+	}
+	if err != nil {
+		s.writeToolError(id, "Failed to find related chunks: "+err.Error())
+		return
+	}
+	if results == nil {
+		results = []db.SearchResult{}
+	}
+	s.writeToolJSON(id, results)
+}
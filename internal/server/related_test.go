@@ -0,0 +1,48 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerRelatedTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+
+	// The seeded Articles 15, 17 and 20 have IDs 1 to 3; Article 17 is
+	// closer to Article 15 than Article 20 is
+	text, isError := callTool(t, srv, "gdpr_related", `{"id":1,"limit":1}`)
+	if isError {
+		t.Fatalf("gdpr_related failed: %s", text)
+	}
+	var results []db.SearchResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 2 {
+		t.Errorf("Expected Article 17 related to Article 15, got %+v", results)
+	}
+
+	docID, err := database.InsertDocument(db.Document{Chunk: "Article 21 Right to object", Metadata: map[string]string{"article": "21"}})
+	if err != nil {
+		t.Fatalf("Failed to insert document: %v", err)
+	}
+	if err := database.InsertEmbedding(docID, []float32{0.7, 0.7, 0.1}); err != nil {
+		t.Fatalf("Failed to insert embedding: %v", err)
+	}
+	text, isError = callTool(t, srv, "gdpr_related", `{"article":"Art. 21"}`)
+	results = nil
+	if isError || json.Unmarshal([]byte(text), &results) != nil || len(results) != 3 || results[0].ID != 3 {
+		t.Errorf("Expected Article 20 most related to Article 21, got %s", text)
+	}
+
+	for _, args := range []string{`{}`, `{"id":1,"article":"21"}`, `{"id":99}`, `{"article":"22"}`, `{"article":"twenty"}`} {
+		if text, isError := callTool(t, srv, "gdpr_related", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
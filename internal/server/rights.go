@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var rightsTool = MCPTool{
+	Name:        "data_subject_rights",
+	Description: "Get the rights of the data subject (access, rectification, erasure, restriction, portability, objection, automated decisions): for each, its article and text, the Article 12(3) deadline for responding, when it applies, and its exceptions",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"right": map[string]interface{}{
+				"type":        "string",
+				"enum":        rightIDs(),
+				"description": "Only return this right (default: all rights)",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+// rightIDs lists the IDs of the data subject rights
+func rightIDs() []string {
+	ids := make([]string, len(guidance.Rights))
+	for i, r := range guidance.Rights {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+// rightResult is a data subject right with the text of its article
+type rightResult struct {
+	guidance.Right
+	ChunkIDs []int64 `json:"chunk_ids,omitempty"`
+	Text     string  `json:"text,omitempty"`
+}
+
+func (s *Server) handleRightsTool(id interface{}, args json.RawMessage) {
+	var rightsArgs struct {
+		Right      string `json:"right"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &rightsArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	rights := guidance.Rights
+	if rightsArgs.Right != "" {
+		right, ok := guidance.RightByID(rightsArgs.Right)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Unknown right %q", rightsArgs.Right))
+			return
+		}
+		rights = []guidance.Right{right}
+	}
+
+	output := struct {
+		Rights  []rightResult `json:"rights"`
+		Missing []string      `json:"missing,omitempty"` // articles not found in the index
+	}{Rights: make([]rightResult, 0, len(rights))}
+
+	s.chaos.delayDB()
+	for _, r := range rights {
+		article, err := s.db.Article(r.Article, rightsArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, fmt.Sprintf("Failed to get Article %s: %v", r.Article, err))
+			return
+		}
+		result := rightResult{Right: r}
+		if article != nil {
+			result.ChunkIDs = article.ChunkIDs
+			result.Text = article.Text
+		} else {
+			output.Missing = append(output.Missing, r.Article)
+		}
+		output.Rights = append(output.Rights, result)
+	}
+
+	s.writeToolJSON(id, output)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerRightsTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	docID, err := database.InsertDocument(db.Document{
+		Chunk:    "Article 17 Right to erasure ('right to be forgotten')\n1. The data subject shall have the right to obtain from the controller the erasure of personal data",
+		Metadata: map[string]string{"article": "17"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert document: %v", err)
+	}
+	srv := New(database, Config{})
+
+	var output struct {
+		Rights []struct {
+			ID         string   `json:"id"`
+			Article    string   `json:"article"`
+			Deadline   string   `json:"deadline"`
+			Exceptions []string `json:"exceptions"`
+			ChunkIDs   []int64  `json:"chunk_ids"`
+			Text       string   `json:"text"`
+		} `json:"rights"`
+		Missing []string `json:"missing"`
+	}
+	text, isError := callTool(t, srv, "data_subject_rights", `{}`)
+	if isError || json.Unmarshal([]byte(text), &output) != nil {
+		t.Fatalf("data_subject_rights failed: %s", text)
+	}
+	if len(output.Rights) != 7 || len(output.Missing) != 6 {
+		t.Errorf("Expected seven rights with six articles missing, got %d rights and missing %v", len(output.Rights), output.Missing)
+	}
+
+	output.Missing = nil
+	text, _ = callTool(t, srv, "data_subject_rights", `{"right":"erasure"}`)
+	if json.Unmarshal([]byte(text), &output) != nil || len(output.Rights) != 1 {
+		t.Fatalf("Expected erasure alone, got %s", text)
+	}
+	r := output.Rights[0]
+	if r.Article != "17" || r.Deadline == "" || len(r.Exceptions) == 0 || len(output.Missing) != 0 {
+		t.Errorf("Expected Article 17 with its deadline and exceptions, got %s", text)
+	}
+	if len(r.ChunkIDs) != 1 || r.ChunkIDs[0] != docID || r.Text == "" {
+		t.Errorf("Expected the text of Article 17, got %s", text)
+	}
+
+	if text, isError := callTool(t, srv, "data_subject_rights", `{"right":"information"}`); !isError {
+		t.Errorf("Expected an unknown right to fail, got %s", text)
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/jc/gdpr-mcp/internal/guidance"
+)
+
+var ropaTool = MCPTool{
+	Name:        "ropa_template",
+	Description: "Get a Markdown record of processing activities (Article 30) template for a controller or a processor, with every required field cross-referenced to the point of Article 30(1) or 30(2) requiring it, a section per processing activity or controller, and the text of Article 30",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"role": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{guidance.RoleController, guidance.RoleProcessor},
+				"description": "Whether the record is kept by a controller (Art. 30(1)) or a processor (Art. 30(2)) (default: controller)",
+			},
+			"organisation": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the controller or processor keeping the record",
+			},
+			"entries": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Processing activities of a controller, or controllers a processor acts for, each given a section of the record",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleRoPATool(id interface{}, args json.RawMessage) {
+	var ropaArgs struct {
+		Role         string   `json:"role"`
+		Organisation string   `json:"organisation"`
+		Entries      []string `json:"entries"`
+		Collection   string   `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &ropaArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	s.chaos.delayDB()
+	article, err := s.db.Article("30", ropaArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get Article 30: "+err.Error())
+		return
+	}
+	in := guidance.RoPAInput{
+		Role:         ropaArgs.Role,
+		Organisation: ropaArgs.Organisation,
+		Entries:      ropaArgs.Entries,
+	}
+	var notes []string
+	if article != nil {
+		in.ArticleText = article.Text
+	} else {
+		notes = append(notes, "Article 30 was not found in the index, so its text is not included; ingest the GDPR text to add it")
+	}
+
+	template, err := guidance.RoPATemplate(in)
+	if err != nil {
+		s.writeToolError(id, err.Error())
+		return
+	}
+	s.writeToolResult(id, template, notes...)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerRoPATool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	request := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"ropa_template","arguments":{}}}`
+	resp := captureServerOutput(t, srv, request)
+	content := resp["result"].(map[string]interface{})["content"].([]interface{})
+	if len(content) != 2 || !strings.Contains(content[1].(map[string]interface{})["text"].(string), "Article 30 was not found") {
+		t.Errorf("Expected a note that Article 30 is missing, got %+v", content)
+	}
+
+	if _, err := database.InsertDocument(db.Document{
+		Chunk:    "Article 30 Records of processing activities\n1. Each controller and, where applicable, the controller's representative, shall maintain a record",
+		Metadata: map[string]string{"article": "30"},
+	}); err != nil {
+		t.Fatalf("Failed to insert document: %v", err)
+	}
+	text, isError := callTool(t, srv, "ropa_template", `{"role":"processor","organisation":"Acme Hosting","entries":["Shop GmbH"]}`)
+	if isError {
+		t.Fatalf("ropa_template failed: %s", text)
+	}
+	if !strings.Contains(text, "# Record of Processing Activities (processor): Acme Hosting") || !strings.Contains(text, "## Controller: Shop GmbH") ||
+		!strings.Contains(text, "> 1. Each controller and, where applicable") {
+		t.Errorf("Expected the processor template with Article 30, got %s", text)
+	}
+
+	if text, isError := callTool(t, srv, "ropa_template", `{"role":"joint-controller"}`); !isError {
+		t.Errorf("Expected an unknown role to fail, got %s", text)
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/guidance"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+var sccTool = MCPTool{
+	Name:        "scc_reference",
+	Description: "Look up the 2021 standard contractual clauses for transfers to third countries (Decision (EU) 2021/914): get a clause or annex by number, limited to the text of one module; choose the module from the roles of the exporter and importer; or search the clauses. Without arguments, list the modules and clauses.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"clause": map[string]interface{}{
+				"type":        "string",
+				"description": "Clause or annex, e.g. \"8\", \"Clause 14\" or \"Annex II\"",
+			},
+			"module": map[string]interface{}{
+				"type":        "string",
+				"description": "Module, e.g. \"2\", \"Module Two\" or \"controller-to-processor\"; leaves out text specific to other modules",
+			},
+			"exporter": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{guidance.RoleController, guidance.RoleProcessor},
+				"description": "Role of the data exporter, to choose the module together with importer",
+			},
+			"importer": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{guidance.RoleController, guidance.RoleProcessor},
+				"description": "Role of the data importer in the third country",
+			},
+			"importer_subject_to_gdpr": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Whether the importer's processing is subject to the GDPR under Article 3(2), in which case the clauses do not apply",
+			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Search the clauses, within the module if one is given or chosen",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Max search results (default: 10, max: %d)", maxSearchLimit),
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the clauses were ingested into (default: " + ingest.SCCCollection + ")",
+			},
+		},
+	},
+}
+
+// sccClauseResult is a clause with its text as ingested
+type sccClauseResult struct {
+	guidance.SCCClause
+	Applies  *bool   `json:"applies,omitempty"` // whether the clause applies to the module asked for
+	ChunkIDs []int64 `json:"chunk_ids,omitempty"`
+	Text     string  `json:"text,omitempty"`
+	Note     string  `json:"note,omitempty"`
+}
+
+// sccResult is a chunk of the clauses matching a search
+type sccResult struct {
+	ID          int64   `json:"id"`
+	Score       float64 `json:"score"`
+	Snippet     string  `json:"snippet"`
+	Clause      string  `json:"clause,omitempty"`
+	ClauseTitle string  `json:"clause_title,omitempty"`
+	Modules     string  `json:"modules,omitempty"` // modules the text is specific to, e.g. "2,3"
+}
+
+func (s *Server) handleSCCTool(id interface{}, args json.RawMessage) {
+	var sccArgs struct {
+		Clause                string `json:"clause"`
+		Module                string `json:"module"`
+		Exporter              string `json:"exporter"`
+		Importer              string `json:"importer"`
+		ImporterSubjectToGDPR bool   `json:"importer_subject_to_gdpr"`
+		Query                 string `json:"query"`
+		Limit                 int    `json:"limit"`
+		Collection            string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &sccArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	if sccArgs.Limit <= 0 {
+		sccArgs.Limit = 10
+	}
+	if sccArgs.Limit > maxSearchLimit {
+		sccArgs.Limit = maxSearchLimit
+	}
+	if sccArgs.Collection == "" {
+		sccArgs.Collection = ingest.SCCCollection
+	}
+
+	var output struct {
+		Selection *guidance.SCCSelection `json:"selection,omitempty"`
+		Module    *guidance.SCCModule    `json:"module,omitempty"`
+		Clause    *sccClauseResult       `json:"clause,omitempty"`
+		Results   []sccResult            `json:"results,omitempty"`
+		Modules   []guidance.SCCModule   `json:"modules,omitempty"`
+		Clauses   []guidance.SCCClause   `json:"clauses,omitempty"`
+	}
+
+	if sccArgs.Module != "" {
+		module, ok := guidance.ParseSCCModule(sccArgs.Module)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid module %q, expected 1 to 4", sccArgs.Module))
+			return
+		}
+		output.Module = &module
+	}
+	if sccArgs.Exporter != "" || sccArgs.Importer != "" {
+		if sccArgs.Module != "" {
+			s.writeToolError(id, "module cannot be combined with exporter and importer, which choose it")
+			return
+		}
+		selection, err := guidance.SelectSCCModule(guidance.SCCScenario{
+			Exporter:              sccArgs.Exporter,
+			Importer:              sccArgs.Importer,
+			ImporterSubjectToGDPR: sccArgs.ImporterSubjectToGDPR,
+		})
+		if err != nil {
+			s.writeToolError(id, "exporter and importer are both required: "+err.Error())
+			return
+		}
+		output.Selection = &selection
+		output.Module = selection.Module
+	}
+	module := ""
+	if output.Module != nil {
+		module = strconv.Itoa(output.Module.Number)
+	}
+
+	if sccArgs.Clause != "" {
+		number, ok := ingest.SCCClauseNumber(sccArgs.Clause)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid clause %q, expected 1 to 18 or Annex I to III", sccArgs.Clause))
+			return
+		}
+		info, _ := guidance.SCCClauseByNumber(number)
+		result := &sccClauseResult{SCCClause: info}
+		if output.Module != nil {
+			applies := info.AppliesTo(output.Module.Number)
+			result.Applies = &applies
+		}
+
+		s.chaos.delayDB()
+		clause, err := s.db.Clause(number, module, sccArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get clause: "+err.Error())
+			return
+		}
+		if clause != nil {
+			result.ChunkIDs, result.Text = clause.ChunkIDs, clause.Text
+		} else {
+			result.Note = "The clause was not found in the index; ingest the standard contractual clauses with the scc profile to add its text"
+		}
+		output.Clause = result
+	}
+
+	if sccArgs.Query != "" {
+		results, err := s.search(sccArgs.Query, sccArgs.Limit, db.SearchOptions{Collection: sccArgs.Collection, SCCModule: module})
+		if err != nil {
+			s.writeToolError(id, "Search failed: "+err.Error())
+			return
+		}
+		results = db.NormalizeScores(results)
+		output.Results = make([]sccResult, 0, len(results))
+		for _, r := range results {
+			doc, err := s.db.GetDocument(r.ID)
+			if err != nil {
+				s.writeToolError(id, "Failed to get document: "+err.Error())
+				return
+			}
+			if doc == nil {
+				continue
+			}
+			output.Results = append(output.Results, sccResult{
+				ID:          r.ID,
+				Score:       r.Score,
+				Snippet:     r.Snippet,
+				Clause:      doc.Metadata[ingest.MetaSCCClause],
+				ClauseTitle: doc.Metadata[ingest.MetaSCCClauseTitle],
+				Modules:     doc.Metadata[ingest.MetaSCCModules],
+			})
+		}
+	}
+
+	if output.Selection == nil && output.Module == nil && output.Clause == nil && sccArgs.Query == "" {
+		output.Modules, output.Clauses = guidance.SCCModules, guidance.SCCClauses
+	}
+	s.writeToolJSON(id, output)
+}
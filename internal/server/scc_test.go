@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+func TestServerSCCTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for i, d := range []struct {
+		chunk    string
+		metadata map[string]string
+	}{
+		{"Clause 8\nData protection safeguards\nThe data exporter warrants that it has used reasonable efforts.", map[string]string{ingest.MetaSCCClause: "8", ingest.MetaSCCClauseTitle: "Data protection safeguards"}},
+		{"MODULE ONE: Transfer controller to controller\n8.1 Purpose limitation: the data importer shall process the personal data only for the specific purposes of the transfer.", map[string]string{ingest.MetaSCCClause: "8", ingest.MetaSCCModules: "1"}},
+		{"MODULE TWO: Transfer controller to processor\n8.1 Instructions: the data importer shall process the personal data only on documented instructions.", map[string]string{ingest.MetaSCCClause: "8", ingest.MetaSCCModules: "2"}},
+	} {
+		docID, err := database.InsertDocument(db.Document{Chunk: d.chunk, ChunkIndex: i, Metadata: d.metadata, Collection: ingest.SCCCollection})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, db.GenerateTrigrams(d.chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+		ids = append(ids, docID)
+	}
+	srv := New(database, Config{})
+
+	type output struct {
+		Selection *struct {
+			Module *struct{ Number int } `json:"module"`
+			Notes  []string              `json:"notes"`
+		} `json:"selection"`
+		Module *struct{ Number int } `json:"module"`
+		Clause *struct {
+			Clause   string  `json:"clause"`
+			Title    string  `json:"title"`
+			Applies  *bool   `json:"applies"`
+			ChunkIDs []int64 `json:"chunk_ids"`
+			Text     string  `json:"text"`
+			Note     string  `json:"note"`
+		} `json:"clause"`
+		Results []sccResult       `json:"results"`
+		Modules []json.RawMessage `json:"modules"`
+		Clauses []json.RawMessage `json:"clauses"`
+	}
+	call := func(args string) output {
+		t.Helper()
+		text, isError := callTool(t, srv, "scc_reference", args)
+		var out output
+		if isError || json.Unmarshal([]byte(text), &out) != nil {
+			t.Fatalf("scc_reference %s failed: %s", args, text)
+		}
+		return out
+	}
+
+	if out := call(`{}`); len(out.Modules) != 4 || len(out.Clauses) != 21 {
+		t.Errorf("Expected the modules and clauses to be listed, got %+v", out)
+	}
+
+	out := call(`{"exporter":"controller","importer":"processor","clause":"Clause 8"}`)
+	if out.Selection == nil || out.Module == nil || out.Module.Number != 2 || len(out.Selection.Notes) == 0 {
+		t.Fatalf("Expected Module Two to be chosen, got %+v", out)
+	}
+	if out.Clause == nil || out.Clause.Title != "Data protection safeguards" || out.Clause.Applies == nil || !*out.Clause.Applies ||
+		len(out.Clause.ChunkIDs) != 2 || !strings.Contains(out.Clause.Text, "documented instructions") || strings.Contains(out.Clause.Text, "Purpose limitation") {
+		t.Errorf("Expected the common text and Module Two of clause 8, got %+v", out.Clause)
+	}
+
+	out = call(`{"module":"1","clause":"9"}`)
+	if out.Clause == nil || out.Clause.Applies == nil || *out.Clause.Applies || !strings.Contains(out.Clause.Note, "not found") {
+		t.Errorf("Expected clause 9 not to apply to Module One and not to be ingested, got %+v", out.Clause)
+	}
+
+	out = call(`{"query":"process the personal data only","module":"Module One"}`)
+	if len(out.Results) == 0 || out.Results[0].ID != ids[1] || out.Results[0].Clause != "8" || out.Results[0].Modules != "1" {
+		t.Fatalf("Expected the Module One chunk first, got %+v", out.Results)
+	}
+	for _, r := range out.Results {
+		if r.ID == ids[2] {
+			t.Errorf("Expected no Module Two text in the results, got %+v", out.Results)
+		}
+	}
+
+	for _, args := range []string{`{"clause":"Article 46"}`, `{"module":"5"}`, `{"exporter":"controller"}`, `{"module":"2","exporter":"controller","importer":"processor"}`} {
+		if text, isError := callTool(t, srv, "scc_reference", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
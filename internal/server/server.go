@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"time"
 
 	"github.com/jc/gdpr-mcp/internal/db"
 	"github.com/jc/gdpr-mcp/internal/ingest"
+	"github.com/jc/gdpr-mcp/internal/version"
 )
 
 // JSON-RPC 2.0 structures with proper serialization
@@ -42,6 +45,12 @@ type MCPInitializeResult struct {
 	ServerInfo      MCPImplementation     `json:"serverInfo"`
 }
 
+// MCPClientCapabilities are the capabilities a client declares in
+// initialize; only the ones the server makes use of are decoded
+type MCPClientCapabilities struct {
+	Sampling *struct{} `json:"sampling,omitempty"`
+}
+
 type MCPServerCapabilities struct {
 	Tools *MCPToolsCapability `json:"tools,omitempty"`
 }
@@ -90,32 +99,134 @@ type JSONSchema struct {
 // Server config
 
 type Config struct {
-	DBPath      string
-	UseOpenAI   bool
-	OpenAIKey   string
-	OpenAIModel string
+	DBPath   string
+	Embedder ingest.Embedder // embeds queries; nil uses the stub embedder
+	Fusion   db.Fusion       // default fusion of keyword and vector rankings, overridable per gdpr_search call
+	Reranker db.Reranker     // re-scores the top search candidates, e.g. with a cross-encoder; nil disables reranking
+
+	// RerankCandidates is how many fused results are reranked; 0 uses
+	// DefaultRerankCandidates
+	RerankCandidates int
+
+	// Rewriter reformulates questions for multi-query search; nil uses
+	// the template rewriter. Rewrites is how many reformulations are
+	// searched by default, 0 searching the question alone.
+	Rewriter ingest.QueryRewriter
+	Rewrites int
+
+	// Summarizer backs the gdpr_summarize tool; when nil, the tool is only
+	// offered to clients supporting MCP sampling, which summarize with
+	// their own model
+	Summarizer ingest.Summarizer
+
+	// Diversity is the default strength of MMR diversification of
+	// gdpr_search results, between 0 (off) and 1
+	Diversity float64
+
+	// CacheTTL is how long query embeddings and search results are reused
+	// for repeated searches; 0 uses DefaultCacheTTL and a negative value
+	// disables caching. CacheSize caps the entries of each cache; 0 uses
+	// DefaultCacheSize.
+	CacheTTL  time.Duration
+	CacheSize int
+
+	Chaos  ChaosConfig
+	Logger *log.Logger // diagnostics; nil logs to stderr
 }
 
+// DefaultRerankCandidates is how many fused results are reranked by default
+const DefaultRerankCandidates = 20
+
+// maxSearchLimit caps the results a search tool returns, keeping the
+// candidate pools derived from it bounded
+const maxSearchLimit = 100
+
+// diversifyPoolFactor is how many candidates per result diversification
+// picks from
+const diversifyPoolFactor = 3
+
+// Retrieval modes of gdpr_search: matching chunks, or the whole articles
+// they belong to
+const (
+	retrievalChunk   = "chunk"
+	retrievalArticle = "article"
+)
+
+// articlePoolFactor is how many chunks are searched per result in article
+// retrieval, as several chunks of one article collapse into one result
+const articlePoolFactor = 3
+
 // Server handles MCP requests
 type Server struct {
-	db     *db.DB
-	config Config
+	db       *db.DB
+	config   Config
+	embedder ingest.Embedder
+	chaos    *chaos
+	logger   *log.Logger
+
+	embeddings *queryCache // query embeddings by normalized query text
+	searches   *queryCache // hybrid search results by normalized query and options
+
+	out io.Writer // protocol stream while Run is serving; stdout otherwise
+
+	in       *bufio.Reader // protocol stream while Run is serving
+	pending  [][]byte      // messages read while waiting for a response to a request of the server
+	requests int           // requests sent to the client, numbering their IDs
+	sampling bool          // the client supports sampling/createMessage
 }
 
 // New creates a new MCP server
 func New(database *db.DB, config Config) *Server {
-	return &Server{
-		db:     database,
-		config: config,
+	srv := &Server{
+		db:       database,
+		config:   config,
+		embedder: config.Embedder,
+		logger:   config.Logger,
+
+		embeddings: newQueryCache(config.CacheTTL, config.CacheSize),
+		searches:   newQueryCache(config.CacheTTL, config.CacheSize),
+	}
+	if srv.logger == nil {
+		srv.logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	if srv.embedder == nil {
+		// The stub embedder cannot fail to initialize
+		srv.embedder, _ = ingest.NewEmbedder(ingest.EmbedderStub, ingest.Config{})
+	}
+	if !srv.config.Chaos.Enabled() {
+		// Fault injection can also be switched on from the environment
+		chaos, err := ParseChaos(os.Getenv(ChaosEnvVar))
+		if err != nil {
+			srv.logger.Printf("Warning: ignoring %s: %v", ChaosEnvVar, err)
+		} else {
+			srv.config.Chaos = chaos
+		}
+	}
+	if srv.config.Chaos.Enabled() {
+		srv.chaos = newChaos(srv.config.Chaos)
+		srv.logger.Printf("Warning: chaos mode enabled (%+v)", srv.config.Chaos)
 	}
+	return srv
 }
 
-// Run starts the JSON-RPC server on stdin/stdout
+// Run starts the JSON-RPC server on stdin/stdout. Stdout carries nothing
+// but protocol messages: while serving, os.Stdout is pointed at stderr so
+// that stray prints (from ingestion, libraries or debugging) cannot corrupt
+// the JSON-RPC stream.
 func (s *Server) Run() error {
-	reader := bufio.NewReader(os.Stdin)
+	stdout := os.Stdout
+	s.out = stdout
+	os.Stdout = os.Stderr
+	defer func() {
+		os.Stdout = stdout
+		s.out = nil
+	}()
+
+	s.in = bufio.NewReader(os.Stdin)
+	defer func() { s.in = nil }()
 
 	for {
-		line, err := reader.ReadBytes('\n')
+		line, err := s.next()
 		if err != nil {
 			if err == io.EOF {
 				return nil
@@ -147,6 +258,56 @@ func (s *Server) Run() error {
 	}
 }
 
+// next returns the next message to handle: those read while waiting for a
+// response to a request of the server first, then the input
+func (s *Server) next() ([]byte, error) {
+	if len(s.pending) > 0 {
+		line := s.pending[0]
+		s.pending = s.pending[1:]
+		return line, nil
+	}
+	return s.in.ReadBytes('\n')
+}
+
+// request sends a request to the client and waits for its response.
+// Messages arriving in the meantime are queued and handled once the current
+// message has been answered.
+func (s *Server) request(method string, params interface{}) (json.RawMessage, error) {
+	if s.in == nil {
+		return nil, fmt.Errorf("no client connected")
+	}
+	s.requests++
+	id := fmt.Sprintf("gdpr-mcp-%d", s.requests)
+	s.writeJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+
+	for {
+		line, err := s.in.ReadBytes('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response to %s: %w", method, err)
+		}
+		var resp struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Result json.RawMessage `json:"result"`
+			Error  *JSONRPCError   `json:"error"`
+		}
+		var respID string
+		if json.Unmarshal(line, &resp) != nil || resp.Method != "" || json.Unmarshal(resp.ID, &respID) != nil || respID != id {
+			s.pending = append(s.pending, line)
+			continue
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("client rejected %s: %s (%d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	}
+}
+
 func (s *Server) handleRequest(method string, id interface{}, params json.RawMessage) {
 	switch method {
 	case "initialize":
@@ -168,9 +329,35 @@ func (s *Server) handleRequest(method string, id interface{}, params json.RawMes
 	}
 }
 
+// ProtocolVersions are the MCP protocol versions the server speaks, latest
+// first
+var ProtocolVersions = []string{"2024-11-05"}
+
+// negotiateProtocolVersion returns the version requested by the client if
+// the server speaks it, and the latest version the server speaks otherwise,
+// leaving the client to disconnect if it cannot use that
+func negotiateProtocolVersion(requested string) string {
+	for _, v := range ProtocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return ProtocolVersions[0]
+}
+
 func (s *Server) handleInitialize(id interface{}, params json.RawMessage) {
+	var initParams struct {
+		ProtocolVersion string                `json:"protocolVersion"`
+		Capabilities    MCPClientCapabilities `json:"capabilities"`
+	}
+	// Clients sending no or malformed parameters are served without the
+	// optional capabilities
+	if len(params) > 0 && json.Unmarshal(params, &initParams) == nil {
+		s.sampling = initParams.Capabilities.Sampling != nil
+	}
+
 	result := MCPInitializeResult{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: negotiateProtocolVersion(initParams.ProtocolVersion),
 		Capabilities: MCPServerCapabilities{
 			Tools: &MCPToolsCapability{
 				ListChanged: false,
@@ -178,7 +365,7 @@ func (s *Server) handleInitialize(id interface{}, params json.RawMessage) {
 		},
 		ServerInfo: MCPImplementation{
 			Name:    "gdpr-mcp",
-			Version: "1.0.0",
+			Version: version.Get().Version,
 		},
 	}
 
@@ -195,11 +382,121 @@ func (s *Server) handleToolsList(id interface{}) {
 				Properties: map[string]interface{}{
 					"query": map[string]interface{}{
 						"type":        "string",
-						"description": "Search query string",
+						"description": "Search query string; phrases in double quotes, e.g. \"right to erasure\", must occur in every result, and AND, OR, NOT and parentheses filter results, e.g. consent AND children NOT marketing",
 					},
 					"limit": map[string]interface{}{
 						"type":        "integer",
-						"description": "Maximum number of results (default: 10)",
+						"description": fmt.Sprintf("Maximum number of results (default: 10, max: %d)", maxSearchLimit),
+					},
+					"rewrites": map[string]interface{}{
+						"type":        "integer",
+						"description": "Also search up to this many reformulations of the question and fuse the results, improving recall for vague questions like \"can we keep emails forever?\" (0-5, default: 0 or the server setting; ignored for queries with quoted phrases or AND, OR, NOT)",
+					},
+					"spellcheck": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Correct misspelled query words that occur in no document, e.g. \"eraser\" to \"erasure\", and report the corrected query (default: true)",
+					},
+					"snippet_length": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum snippet length in characters (default: 200)",
+					},
+					"snippet_context": map[string]interface{}{
+						"type":        "integer",
+						"description": "Characters of context kept before and after the best matching region (default: 80)",
+					},
+					"highlight": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Add the character offsets of the matched query words in each snippet as highlights (default: false)",
+					},
+					"highlight_pre": map[string]interface{}{
+						"type":        "string",
+						"description": "Marker inserted before each matched query word in the snippet instead of returning offsets, e.g. \"<em>\"",
+					},
+					"highlight_post": map[string]interface{}{
+						"type":        "string",
+						"description": "Marker inserted after each matched query word in the snippet, e.g. \"</em>\"",
+					},
+					"cursor": map[string]interface{}{
+						"type":        "string",
+						"description": "next_cursor returned by a previous search, to get its next page of results; all other arguments must be the same",
+					},
+					"retrieval": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{retrievalChunk, retrievalArticle},
+						"description": "\"chunk\" returns matching chunks with snippets; \"article\" returns the complete text of each article the matching chunks belong to, once, with the IDs of the chunks that matched (default: chunk)",
+					},
+					"explain": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Add an \"explain\" object to each result breaking its score down for tuning relevance: the rankings it was found in, trigram matches and keyword rank, vector similarity and rank, each ranking's contribution and the fused score (default: false)",
+					},
+					"lang": map[string]interface{}{
+						"type":        "string",
+						"description": "Only return chunks in this language (ISO 639-1 code, e.g. \"en\", \"de\")",
+					},
+					"collection": map[string]interface{}{
+						"type":        "string",
+						"description": "Only return chunks from this collection, e.g. \"gdpr-de\" for the German authentic text",
+					},
+					"collections": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Search these collections at once, e.g. [\"gdpr-en\", \"edpb\"] for the regulation and the EDPB guidelines; each result names its collection",
+					},
+					"collection_weights": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "number"},
+						"description":          "Multiply the scores of a collection's results by its weight, e.g. {\"edpb\": 0.5} to rank guidelines below the regulation (default: 1 for every collection)",
+					},
+					"provision_type": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{ingest.ProvisionRecital, ingest.ProvisionArticle, ingest.ProvisionAnnex},
+						"description": "Only return recitals, operative articles or annexes; recitals explain but are not binding",
+					},
+					"exclude_provision_type": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string", "enum": []string{ingest.ProvisionRecital, ingest.ProvisionArticle, ingest.ProvisionAnnex}},
+						"description": "Leave out these provision types, e.g. [\"recital\"] for operative text only, while keeping guidelines, case law and other sources without a provision type",
+					},
+					"article": map[string]interface{}{
+						"type":        "string",
+						"description": "Only return chunks of this article, e.g. \"17\" or \"Article 17\"",
+					},
+					"chapter": map[string]interface{}{
+						"type":        "string",
+						"description": "Only return chunks of this chapter, e.g. \"IV\" or \"4\" for the controller and processor obligations",
+					},
+					"recital": map[string]interface{}{
+						"type":        "string",
+						"description": "Only return this recital, e.g. \"26\"",
+					},
+					"fusion": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{db.FusionRRF, db.FusionLinear},
+						"description": "How keyword and vector results are combined: \"rrf\" by rank, or \"linear\" by normalized score, which keeps how far apart results score (default: rrf)",
+					},
+					"alpha": map[string]interface{}{
+						"type":        "number",
+						"description": "Share of the vector ranking between 0 (keywords only) and 1 (vectors only); an alternative to keyword_weight and vector_weight",
+					},
+					"rerank": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Re-score the top candidates with the configured cross-encoder for more precise ranking (default: true when a reranker is configured)",
+					},
+					"diversity": map[string]interface{}{
+						"type":        "number",
+						"description": "Between 0 and 1: how strongly results overlapping earlier ones are pushed down, so a broad question is not answered by several chunks of the same article (default: the server setting, normally 0 for off; 0.3 is a good start)",
+					},
+					"rrf_k": map[string]interface{}{
+						"type":        "number",
+						"description": "Reciprocal rank fusion constant; larger values flatten the difference between ranks (default: 60)",
+					},
+					"keyword_weight": map[string]interface{}{
+						"type":        "number",
+						"description": "Weight of the keyword (trigram) ranking in the fusion; raise it to favour exact legal terminology (default: 1)",
+					},
+					"vector_weight": map[string]interface{}{
+						"type":        "number",
+						"description": "Weight of the vector ranking in the fusion; raise it to favour semantic similarity (default: 1)",
 					},
 				},
 				Required: []string{"query"},
@@ -219,6 +516,31 @@ func (s *Server) handleToolsList(id interface{}) {
 				Required: []string{"id"},
 			},
 		},
+		recitalTool,
+		definitionsTool,
+		tocTool,
+		relatedTool,
+		dpiaTool,
+		lawfulBasisTool,
+		rightsTool,
+		crossRegulationTool,
+		enforcementTool,
+		edpbTool,
+		caseLawTool,
+		ropaTool,
+		sccTool,
+		noticeTool,
+		piiTool,
+		citeTool,
+		glossaryTool,
+		quizTool,
+		childrenTool,
+		processorTool,
+		statsTool,
+		browseTool,
+	}
+	if s.summarizer() != nil {
+		tools = append(tools, summarizeTool)
 	}
 
 	s.writeResult(id, MCPToolsListResult{Tools: tools})
@@ -236,6 +558,52 @@ func (s *Server) handleToolsCall(id interface{}, params json.RawMessage) {
 		s.handleSearchTool(id, toolParams.Arguments)
 	case "gdpr_get":
 		s.handleGetTool(id, toolParams.Arguments)
+	case "gdpr_recital":
+		s.handleRecitalTool(id, toolParams.Arguments)
+	case "gdpr_definitions":
+		s.handleDefinitionsTool(id, toolParams.Arguments)
+	case "gdpr_toc":
+		s.handleTOCTool(id, toolParams.Arguments)
+	case "gdpr_related":
+		s.handleRelatedTool(id, toolParams.Arguments)
+	case "dpia_template":
+		s.handleDPIATool(id, toolParams.Arguments)
+	case "lawful_basis":
+		s.handleLawfulBasisTool(id, toolParams.Arguments)
+	case "data_subject_rights":
+		s.handleRightsTool(id, toolParams.Arguments)
+	case "cross_regulation":
+		s.handleCrossRegulationTool(id, toolParams.Arguments)
+	case "enforcement_decisions":
+		s.handleEnforcementTool(id, toolParams.Arguments)
+	case "edpb_guidelines_search":
+		s.handleEDPBTool(id, toolParams.Arguments)
+	case "case_law":
+		s.handleCaseLawTool(id, toolParams.Arguments)
+	case "ropa_template":
+		s.handleRoPATool(id, toolParams.Arguments)
+	case "scc_reference":
+		s.handleSCCTool(id, toolParams.Arguments)
+	case "privacy_notice_analyzer":
+		s.handleNoticeTool(id, toolParams.Arguments)
+	case "pii_detector":
+		s.handlePIITool(id, toolParams.Arguments)
+	case "gdpr_cite":
+		s.handleCiteTool(id, toolParams.Arguments)
+	case "gdpr_glossary":
+		s.handleGlossaryTool(id, toolParams.Arguments)
+	case "gdpr_quiz":
+		s.handleQuizTool(id, toolParams.Arguments)
+	case "children_data":
+		s.handleChildrenTool(id, toolParams.Arguments)
+	case "processor_obligations":
+		s.handleProcessorTool(id, toolParams.Arguments)
+	case "gdpr_stats":
+		s.handleStatsTool(id, toolParams.Arguments)
+	case "gdpr_browse":
+		s.handleBrowseTool(id, toolParams.Arguments)
+	case "gdpr_summarize":
+		s.handleSummarizeTool(id, toolParams.Arguments)
 	default:
 		s.writeError(id, -32602, "Unknown tool", toolParams.Name)
 	}
@@ -243,8 +611,41 @@ func (s *Server) handleToolsCall(id interface{}, params json.RawMessage) {
 
 func (s *Server) handleSearchTool(id interface{}, args json.RawMessage) {
 	var searchArgs struct {
-		Query string `json:"query"`
-		Limit int    `json:"limit"`
+		Query  string `json:"query"`
+		Limit  int    `json:"limit"`
+		Cursor string `json:"cursor"`
+		Lang   string `json:"lang"`
+
+		Spellcheck *bool `json:"spellcheck"`
+		Rewrites   *int  `json:"rewrites"`
+
+		Collection           string             `json:"collection"`
+		Collections          []string           `json:"collections"`
+		CollectionWeights    map[string]float64 `json:"collection_weights"`
+		ProvisionType        string             `json:"provision_type"`
+		ExcludeProvisionType []string           `json:"exclude_provision_type"`
+		Article              string             `json:"article"`
+		Chapter              string             `json:"chapter"`
+		Recital              string             `json:"recital"`
+
+		SnippetLength  int `json:"snippet_length"`
+		SnippetContext int `json:"snippet_context"`
+
+		Highlight     bool   `json:"highlight"`
+		HighlightPre  string `json:"highlight_pre"`
+		HighlightPost string `json:"highlight_post"`
+
+		Retrieval string `json:"retrieval"`
+		Explain   bool   `json:"explain"`
+
+		Rerank    *bool    `json:"rerank"`
+		Diversity *float64 `json:"diversity"`
+
+		Fusion        string   `json:"fusion"`
+		Alpha         *float64 `json:"alpha"`
+		RRFK          *float64 `json:"rrf_k"`
+		KeywordWeight *float64 `json:"keyword_weight"`
+		VectorWeight  *float64 `json:"vector_weight"`
 	}
 
 	if err := json.Unmarshal(args, &searchArgs); err != nil {
@@ -260,37 +661,364 @@ func (s *Server) handleSearchTool(id interface{}, args json.RawMessage) {
 	if searchArgs.Limit <= 0 {
 		searchArgs.Limit = 10
 	}
+	if searchArgs.Limit > maxSearchLimit {
+		searchArgs.Limit = maxSearchLimit
+	}
+
+	fingerprint, err := searchFingerprint(args)
+	if err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+	var offset int
+	if searchArgs.Cursor != "" {
+		cursor, err := decodeCursor(searchArgs.Cursor, fingerprint)
+		if err != nil {
+			s.writeToolError(id, "Invalid cursor: "+err.Error())
+			return
+		}
+		offset = cursor.Offset
+	}
+
+	if searchArgs.Collection != "" && len(searchArgs.Collections) > 0 {
+		s.writeToolError(id, "collection cannot be combined with collections")
+		return
+	}
+	for collection, weight := range searchArgs.CollectionWeights {
+		if weight < 0 {
+			s.writeToolError(id, fmt.Sprintf("Weight of collection %q must not be negative", collection))
+			return
+		}
+	}
+
+	for _, t := range append([]string{searchArgs.ProvisionType}, searchArgs.ExcludeProvisionType...) {
+		switch t {
+		case "", ingest.ProvisionRecital, ingest.ProvisionArticle, ingest.ProvisionAnnex:
+		default:
+			s.writeToolError(id, "provision_type and exclude_provision_type must be recital, article or annex")
+			return
+		}
+	}
+	for _, filter := range []struct {
+		name  string
+		value *string
+		parse func(string) (string, bool)
+	}{
+		{"article", &searchArgs.Article, db.ArticleNumber},
+		{"chapter", &searchArgs.Chapter, db.ChapterNumber},
+		{"recital", &searchArgs.Recital, db.RecitalNumber},
+	} {
+		if *filter.value == "" {
+			continue
+		}
+		number, ok := filter.parse(*filter.value)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid %s %q", filter.name, *filter.value))
+			return
+		}
+		*filter.value = number
+	}
 
-	// Generate query embedding for hybrid search
-	var queryEmbedding []float32
-	if s.config.UseOpenAI && s.config.OpenAIKey != "" {
+	fusion := s.config.Fusion.WithDefaults()
+	if searchArgs.RRFK != nil {
+		fusion.K = *searchArgs.RRFK
+	}
+	if searchArgs.KeywordWeight != nil {
+		fusion.KeywordWeight = *searchArgs.KeywordWeight
+	}
+	if searchArgs.VectorWeight != nil {
+		fusion.VectorWeight = *searchArgs.VectorWeight
+	}
+	if searchArgs.Alpha != nil {
+		if searchArgs.KeywordWeight != nil || searchArgs.VectorWeight != nil {
+			s.writeToolError(id, "alpha cannot be combined with keyword_weight or vector_weight")
+			return
+		}
 		var err error
-		queryEmbedding, err = ingest.EmbedQuery(
-			searchArgs.Query,
-			true,
-			s.config.OpenAIKey,
-			s.config.OpenAIModel,
-		)
+		if fusion, err = fusion.WithAlpha(*searchArgs.Alpha); err != nil {
+			s.writeToolError(id, "Invalid fusion parameters: "+err.Error())
+			return
+		}
+	}
+	if searchArgs.Fusion != "" {
+		fusion.Method = searchArgs.Fusion
+	}
+	if err := fusion.Validate(); err != nil {
+		s.writeToolError(id, "Invalid fusion parameters: "+err.Error())
+		return
+	}
+	if fusion.KeywordWeight == 0 && fusion.VectorWeight == 0 {
+		s.writeToolError(id, "keyword_weight and vector_weight must not both be 0")
+		return
+	}
+
+	// Search for the corrected query, telling the client what was searched
+	var notes []string
+	if searchArgs.Spellcheck == nil || *searchArgs.Spellcheck {
+		corrected, err := s.db.CorrectQuery(searchArgs.Query)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to generate query embedding: %v\n", err)
+			s.writeToolError(id, "Search failed: "+err.Error())
+			return
+		}
+		if corrected != searchArgs.Query {
+			notes = append(notes, fmt.Sprintf("Showing results for %q instead of %q; search again with spellcheck set to false to search as typed.", corrected, searchArgs.Query))
+			searchArgs.Query = corrected
 		}
-	} else {
-		queryEmbedding, _ = ingest.EmbedQuery(searchArgs.Query, false, "", "")
 	}
 
-	results, err := s.db.HybridSearch(searchArgs.Query, queryEmbedding, searchArgs.Limit)
-	if err != nil {
-		s.writeToolError(id, "Search failed: "+err.Error())
+	switch searchArgs.Retrieval {
+	case "", retrievalChunk, retrievalArticle:
+	default:
+		s.writeToolError(id, "retrieval must be chunk or article")
+		return
+	}
+
+	if searchArgs.SnippetLength < 0 || searchArgs.SnippetContext < 0 {
+		s.writeToolError(id, "snippet_length and snippet_context must not be negative")
+		return
+	}
+
+	opts := db.SearchOptions{
+		Language:              searchArgs.Lang,
+		Collection:            searchArgs.Collection,
+		Collections:           searchArgs.Collections,
+		CollectionWeights:     searchArgs.CollectionWeights,
+		ProvisionType:         searchArgs.ProvisionType,
+		ExcludeProvisionTypes: searchArgs.ExcludeProvisionType,
+		Article:               searchArgs.Article,
+		Chapter:               searchArgs.Chapter,
+		Recital:               searchArgs.Recital,
+		SnippetLength:         searchArgs.SnippetLength,
+		SnippetContext:        searchArgs.SnippetContext,
+		Fusion:                fusion,
+		Highlight:             searchArgs.Highlight,
+		HighlightPre:          searchArgs.HighlightPre,
+		HighlightPost:         searchArgs.HighlightPost,
+		Explain:               searchArgs.Explain,
+	}
+	diversity := s.config.Diversity
+	if searchArgs.Diversity != nil {
+		diversity = *searchArgs.Diversity
+	}
+	if diversity < 0 || diversity > 1 {
+		s.writeToolError(id, "diversity must be between 0 and 1")
+		return
+	}
+
+	rerank := s.config.Reranker != nil
+	if searchArgs.Rerank != nil {
+		if *searchArgs.Rerank && !rerank {
+			s.writeToolError(id, "No reranker is configured")
+			return
+		}
+		rerank = *searchArgs.Rerank
+	}
+
+	// Rank results for several pages, so that every page comes from the
+	// same ranking. Rerank and diversify a pool of candidates larger than
+	// that, and collect more chunks when they are merged into articles.
+	limit := searchArgs.Limit * searchPages
+	if searchArgs.Retrieval == retrievalArticle {
+		limit *= articlePoolFactor
+	}
+	candidates := limit
+	if diversity > 0 {
+		candidates = limit * diversifyPoolFactor
+	}
+	if rerank {
+		if pool := s.rerankCandidates(); pool > candidates {
+			candidates = pool
+		}
+	}
+
+	rewrites := s.config.Rewrites
+	if searchArgs.Rewrites != nil {
+		rewrites = *searchArgs.Rewrites
+	}
+	if rewrites < 0 || rewrites > ingest.MaxRewrites {
+		s.writeToolError(id, fmt.Sprintf("rewrites must be between 0 and %d", ingest.MaxRewrites))
 		return
 	}
 
-	resultJSON, err := json.Marshal(results)
+	// Pages of a search are cut from its final ranking, which is reused
+	// until it expires rather than searched, reranked and diversified again
+	key := "ranked\x00" + fingerprint
+	var ranked *rankedSearch
+	if cached, ok := s.searches.get(key); ok {
+		ranked = cached.(*rankedSearch)
+	} else {
+		var complete bool
+		ranked, complete, err = s.rankSearch(searchArgs.Query, rewrites, limit, candidates, rerank, diversity, searchArgs.Retrieval == retrievalArticle, opts)
+		if err != nil {
+			s.writeToolError(id, "Search failed: "+err.Error())
+			return
+		}
+		if complete {
+			s.searches.put(key, ranked)
+		}
+	}
+
+	var output interface{}
+	var next string
+	if searchArgs.Retrieval == retrievalArticle {
+		var start, end int
+		start, end, next = page(len(ranked.articles), offset, searchArgs.Limit, fingerprint)
+		output = ranked.articles[start:end]
+	} else {
+		var start, end int
+		start, end, next = page(len(ranked.results), offset, searchArgs.Limit, fingerprint)
+		output = ranked.results[start:end]
+	}
+	if next != "" {
+		cursorJSON, err := json.Marshal(map[string]string{"next_cursor": next})
+		if err != nil {
+			s.writeToolError(id, "Failed to marshal results: "+err.Error())
+			return
+		}
+		notes = append(notes, string(cursorJSON))
+	}
+
+	resultJSON, err := json.Marshal(output)
 	if err != nil {
 		s.writeToolError(id, "Failed to marshal results: "+err.Error())
 		return
 	}
 
-	s.writeToolResult(id, string(resultJSON))
+	s.writeToolResult(id, string(resultJSON), notes...)
+}
+
+// rankedSearch is the final ranking of a gdpr_search: its results and, in
+// article retrieval, the articles they belong to
+type rankedSearch struct {
+	results  []db.SearchResult
+	articles []db.ArticleResult
+}
+
+// rankSearch ranks up to limit results for query and up to rewrites of its
+// reformulations from pools of candidates each. The top candidates are
+// reranked and the rest follow in fused order. It reports whether every
+// search embedded its query, so that the ranking may be reused.
+func (s *Server) rankSearch(query string, rewrites, limit, candidates int, rerank bool, diversity float64, articles bool, opts db.SearchOptions) (*rankedSearch, bool, error) {
+	// Search reformulations of the question too and fuse their results,
+	// unless the query asks for exact phrases or boolean matches
+	queries := []string{query}
+	if rewrites > 0 && !db.HasSearchSyntax(query) {
+		reformulations, err := s.rewriter().Rewrite(query, rewrites)
+		if err != nil {
+			s.logger.Printf("Warning: failed to rewrite query: %v", err)
+		}
+		queries = append(queries, reformulations...)
+	}
+
+	complete := true
+	lists := make([][]db.SearchResult, 0, len(queries))
+	for _, q := range queries {
+		list, embedded, err := s.searchOnce(q, candidates, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		complete = complete && embedded
+		lists = append(lists, list)
+	}
+	results := lists[0]
+	if len(lists) > 1 {
+		results = db.FuseQueries(lists)
+	}
+
+	if rerank {
+		reranked, err := db.RerankTop(s.config.Reranker, db.QueryText(query), results, s.rerankCandidates())
+		if err != nil {
+			s.logger.Printf("Warning: %v; returning results in fused order", err)
+		} else {
+			results = reranked
+		}
+	}
+	if diversity > 0 {
+		diversified, err := s.db.Diversify(results, limit, diversity)
+		if err != nil {
+			return nil, false, err
+		}
+		results = diversified
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	ranked := &rankedSearch{results: db.NormalizeScores(results)}
+	if articles {
+		var err error
+		if ranked.articles, err = s.db.ParentArticles(ranked.results); err != nil {
+			return nil, false, err
+		}
+	}
+	return ranked, complete, nil
+}
+
+// search runs a hybrid search, reusing the results of the same search made
+// recently. Results of a search without a query embedding are not reused,
+// so a failed embedding is retried next time.
+func (s *Server) search(query string, limit int, opts db.SearchOptions) ([]db.SearchResult, error) {
+	results, _, err := s.searchOnce(query, limit, opts)
+	return results, err
+}
+
+// searchOnce is search, also reporting whether the query was embedded or
+// the results are those of an earlier search that embedded it
+func (s *Server) searchOnce(query string, limit int, opts db.SearchOptions) ([]db.SearchResult, bool, error) {
+	key := fmt.Sprintf("%s\x00%d\x00%+v", normalizeQuery(query), limit, opts)
+	if cached, ok := s.searches.get(key); ok {
+		return cached.([]db.SearchResult), true, nil
+	}
+
+	queryEmbedding := s.embedQuery(query)
+	s.chaos.delayDB()
+	results, err := s.db.HybridSearch(query, queryEmbedding, limit, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	if queryEmbedding != nil {
+		s.searches.put(key, results)
+	}
+	return results, queryEmbedding != nil, nil
+}
+
+// embedQuery embeds a query for hybrid search, returning nil to search by
+// keywords alone when embedding fails. Embeddings are reused for the same
+// query text.
+func (s *Server) embedQuery(query string) []float32 {
+	text := db.QueryText(query)
+	key := normalizeQuery(text)
+	if cached, ok := s.embeddings.get(key); ok {
+		return cached.([]float32)
+	}
+
+	queryEmbedding, err := s.embedder.EmbedQuery(text)
+	if err != nil {
+		s.logger.Printf("Warning: failed to generate query embedding: %v", err)
+		return nil
+	}
+	if err := s.chaos.embeddingError(); err != nil {
+		s.logger.Printf("Warning: failed to generate query embedding: %v", err)
+		return nil
+	}
+	s.embeddings.put(key, queryEmbedding)
+	return queryEmbedding
+}
+
+// rewriter returns the configured query rewriter, or the template rewriter
+func (s *Server) rewriter() ingest.QueryRewriter {
+	if s.config.Rewriter != nil {
+		return s.config.Rewriter
+	}
+	return ingest.TemplateRewriter{}
+}
+
+// rerankCandidates returns how many fused results are reranked
+func (s *Server) rerankCandidates() int {
+	if s.config.RerankCandidates > 0 {
+		return s.config.RerankCandidates
+	}
+	return DefaultRerankCandidates
 }
 
 func (s *Server) handleGetTool(id interface{}, args json.RawMessage) {
@@ -308,6 +1036,7 @@ func (s *Server) handleGetTool(id interface{}, args json.RawMessage) {
 		return
 	}
 
+	s.chaos.delayDB()
 	doc, err := s.db.GetDocument(getArgs.ID)
 	if err != nil {
 		s.writeToolError(id, "Failed to get document: "+err.Error())
@@ -323,15 +1052,19 @@ func (s *Server) handleGetTool(id interface{}, args json.RawMessage) {
 		"id":          doc.ID,
 		"chunk":       doc.Chunk,
 		"chunk_index": doc.ChunkIndex,
+		"language":    doc.Language,
 	}
-
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		s.writeToolError(id, "Failed to marshal result: "+err.Error())
-		return
+	if doc.Collection != "" {
+		result["collection"] = doc.Collection
+	}
+	if len(doc.Metadata) > 0 {
+		result["metadata"] = doc.Metadata
+	}
+	if doc.DeletedAt != nil {
+		result["deleted_at"] = doc.DeletedAt.Format(time.RFC3339)
 	}
 
-	s.writeToolResult(id, string(resultJSON))
+	s.writeToolJSON(id, result)
 }
 
 func (s *Server) handlePing(id interface{}) {
@@ -366,15 +1099,30 @@ func (s *Server) writeError(id interface{}, code int, message string, data inter
 	s.writeJSON(resp)
 }
 
-func (s *Server) writeToolResult(id interface{}, text string) {
+// writeToolResult writes a tool's output, followed by notes about it as
+// further text content
+func (s *Server) writeToolResult(id interface{}, text string, notes ...string) {
 	result := MCPCallToolResult{
 		Content: []MCPContent{
 			{Type: "text", Text: text},
 		},
 	}
+	for _, note := range notes {
+		result.Content = append(result.Content, MCPContent{Type: "text", Text: note})
+	}
 	s.writeResult(id, result)
 }
 
+// writeToolJSON writes a tool's output as JSON
+func (s *Server) writeToolJSON(id interface{}, result interface{}) {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		s.writeToolError(id, "Failed to marshal result: "+err.Error())
+		return
+	}
+	s.writeToolResult(id, string(resultJSON))
+}
+
 func (s *Server) writeToolError(id interface{}, message string) {
 	result := MCPCallToolResult{
 		Content: []MCPContent{
@@ -388,8 +1136,12 @@ func (s *Server) writeToolError(id interface{}, message string) {
 func (s *Server) writeJSON(v interface{}) {
 	data, err := json.Marshal(v)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to marshal response: %v\n", err)
+		s.logger.Printf("Failed to marshal response: %v", err)
 		return
 	}
-	fmt.Fprintln(os.Stdout, string(data))
+	out := s.out
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintln(out, string(s.chaos.truncate(data)))
 }
@@ -3,13 +3,16 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/version"
 )
 
 func setupTestDB(t *testing.T) (*db.DB, func()) {
@@ -134,6 +137,22 @@ func captureServerOutput(t *testing.T, srv *Server, request string) map[string]i
 	return resp
 }
 
+// callTool calls a tool with JSON arguments and returns the text of its
+// first content item and whether the call failed
+func callTool(t *testing.T, srv *Server, name, args string) (string, bool) {
+	t.Helper()
+
+	request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, name, args)
+	resp := captureServerOutput(t, srv, request)
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a tool result, got %+v", resp)
+	}
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	isError, _ := result["isError"].(bool)
+	return text, isError
+}
+
 func TestServerInitialize(t *testing.T) {
 	database, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -172,6 +191,29 @@ func TestServerInitialize(t *testing.T) {
 	if serverInfo["name"] != "gdpr-mcp" {
 		t.Errorf("Expected server name 'gdpr-mcp', got %s", serverInfo["name"])
 	}
+	if serverInfo["version"] != version.Version {
+		t.Errorf("Expected server version %q, got %v", version.Version, serverInfo["version"])
+	}
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("Expected the requested protocol version, got %v", result["protocolVersion"])
+	}
+}
+
+func TestServerInitializeUnsupportedProtocolVersion(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	request := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01","capabilities":{}}}`
+	resp := captureServerOutput(t, srv, request)
+
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected result object, got %v", resp)
+	}
+	if result["protocolVersion"] != ProtocolVersions[0] {
+		t.Errorf("Expected the latest supported version %q, got %v", ProtocolVersions[0], result["protocolVersion"])
+	}
 }
 
 func TestServerInitializedNotification(t *testing.T) {
@@ -216,8 +258,9 @@ func TestServerToolsList(t *testing.T) {
 		t.Fatalf("Expected tools array, got %T", result["tools"])
 	}
 
-	if len(tools) != 2 {
-		t.Errorf("Expected 2 tools, got %d", len(tools))
+	expected := []string{"gdpr_search", "gdpr_get", "gdpr_recital", "gdpr_definitions", "gdpr_toc", "gdpr_related", "dpia_template", "lawful_basis", "data_subject_rights", "cross_regulation", "enforcement_decisions", "edpb_guidelines_search", "case_law", "ropa_template", "scc_reference", "privacy_notice_analyzer", "pii_detector", "gdpr_cite", "gdpr_glossary", "gdpr_quiz", "children_data", "processor_obligations", "gdpr_stats", "gdpr_browse"}
+	if len(tools) != len(expected) {
+		t.Errorf("Expected %d tools, got %d", len(expected), len(tools))
 	}
 
 	toolNames := make(map[string]bool)
@@ -226,12 +269,10 @@ func TestServerToolsList(t *testing.T) {
 		toolNames[toolMap["name"].(string)] = true
 	}
 
-	if !toolNames["gdpr_search"] {
-		t.Error("Expected 'gdpr_search' tool")
-	}
-
-	if !toolNames["gdpr_get"] {
-		t.Error("Expected 'gdpr_get' tool")
+	for _, name := range expected {
+		if !toolNames[name] {
+			t.Errorf("Expected %q tool", name)
+		}
 	}
 }
 
@@ -484,3 +525,749 @@ func TestToolInputSchemaFormat(t *testing.T) {
 		}
 	}
 }
+
+// queryEmbedder embeds every query as the same fixed vector
+type queryEmbedder []float32
+
+func (q queryEmbedder) EmbedDocuments(texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (q queryEmbedder) EmbedQuery(string) ([]float32, error) {
+	return q, nil
+}
+
+func TestServerSearchUsesEmbedder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Matches the portability article's embedding; the query has no trigram hits
+	srv := New(database, Config{Embedder: queryEmbedder{0.7, 0.7, 0.1}})
+
+	request := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"zzzz","limit":1}}}`
+	resp := captureServerOutput(t, srv, request)
+
+	result := resp["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+
+	var results []db.SearchResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if len(results) != 1 || !strings.Contains(results[0].Snippet, "portability") {
+		t.Errorf("Expected the configured embedder to drive vector search, got %+v", results)
+	}
+}
+
+// noisyEmbedder prints to stdout as a careless dependency might
+type noisyEmbedder struct{ queryEmbedder }
+
+func (n noisyEmbedder) EmbedQuery(text string) ([]float32, error) {
+	fmt.Println("loading embedding model...")
+	return n.queryEmbedder.EmbedQuery(text)
+}
+
+func TestServerRunKeepsStdoutForProtocol(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var logs bytes.Buffer
+	srv := New(database, Config{
+		Embedder: noisyEmbedder{queryEmbedder{1, 0.5, 0}},
+		Logger:   log.New(&logs, "", 0),
+	})
+
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+
+	oldStdin, oldStdout, oldStderr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = inR, outW, errW
+	defer func() { os.Stdin, os.Stdout, os.Stderr = oldStdin, oldStdout, oldStderr }()
+
+	inW.WriteString(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"access"}}}` + "\n")
+	inW.WriteString(`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n")
+	inW.Close()
+
+	if err := srv.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if os.Stdout != outW {
+		t.Error("Expected Run to restore os.Stdout")
+	}
+	outW.Close()
+	errW.Close()
+
+	stdout, _ := io.ReadAll(outR)
+	stderr, _ := io.ReadAll(errR)
+
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 protocol messages on stdout, got:\n%s", stdout)
+	}
+	for _, line := range lines {
+		var resp map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Errorf("Non-JSON-RPC output on stdout: %q", line)
+		}
+	}
+	if !strings.Contains(string(stderr), "loading embedding model") {
+		t.Errorf("Expected stray output to go to stderr, got %q", stderr)
+	}
+}
+
+func TestServerSearchCollection(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	chunk := "Artikel 17 - Recht auf Löschung. Die betroffene Person hat das Recht auf Löschung."
+	docID, err := database.InsertDocument(db.Document{Chunk: chunk, Language: "de", Collection: "gdpr-de"})
+	if err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+	if err := database.InsertTrigrams(docID, db.GenerateTrigrams(chunk)); err != nil {
+		t.Fatalf("InsertTrigrams failed: %v", err)
+	}
+
+	srv := New(database, Config{})
+	request := `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"Artikel 17","collection":"gdpr-de"}}}`
+	resp := captureServerOutput(t, srv, request)
+
+	result := resp["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+
+	var results []db.SearchResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != docID {
+		t.Errorf("Expected only the German chunk, got %+v", results)
+	}
+
+	request = fmt.Sprintf(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"gdpr_get","arguments":{"id":%d}}}`, docID)
+	resp = captureServerOutput(t, srv, request)
+	result = resp["result"].(map[string]interface{})
+	text = result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if !strings.Contains(text, `"collection":"gdpr-de"`) {
+		t.Errorf("Expected gdpr_get to report the collection, got %s", text)
+	}
+}
+
+func TestServerSearchCollections(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, d := range []db.Document{
+		{Chunk: "Right to erasure in the regulation", Collection: "gdpr"},
+		{Chunk: "Right to erasure in the guidelines", Collection: "edpb"},
+		{Chunk: "Right to erasure in national law", Collection: "national"},
+	} {
+		docID, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, db.GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+	}
+
+	srv := New(database, Config{})
+	search := func(args string) ([]db.SearchResult, bool) {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":22,"method":"tools/call","params":{"name":"gdpr_search","arguments":` + args + `}}`
+		resp := captureServerOutput(t, srv, request)
+		result := resp["result"].(map[string]interface{})
+		if isError, _ := result["isError"].(bool); isError {
+			return nil, true
+		}
+		var results []db.SearchResult
+		text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results, false
+	}
+
+	for _, weight := range []string{"0.1", "10"} {
+		results, _ := search(`{"query":"right to erasure","vector_weight":0,"collections":["gdpr","edpb"],"collection_weights":{"edpb":` + weight + `}}`)
+		if len(results) != 2 {
+			t.Fatalf("Expected results from both collections, got %+v", results)
+		}
+		if want := map[string]string{"0.1": "gdpr", "10": "edpb"}[weight]; results[0].Collection != want {
+			t.Errorf("Expected %s first with edpb weighted %s, got %+v", want, weight, results)
+		}
+	}
+
+	if _, isError := search(`{"query":"erasure","collection":"gdpr","collections":["edpb"]}`); !isError {
+		t.Error("Expected collection and collections together to be rejected")
+	}
+	if _, isError := search(`{"query":"erasure","collection_weights":{"edpb":-1}}`); !isError {
+		t.Error("Expected a negative collection weight to be rejected")
+	}
+}
+
+func TestServerSearchProvisionType(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []db.Document{
+		{Chunk: "(26) The principles of data protection should not apply to anonymous information.", Metadata: map[string]string{"provision_type": "recital"}},
+		{Chunk: "Article 4 (1) 'personal data' means any information relating to an identified person; data protection applies.", Metadata: map[string]string{"provision_type": "article"}},
+	} {
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, db.GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	srv := New(database, Config{})
+	request := `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"data protection","provision_type":"recital"}}}`
+	resp := captureServerOutput(t, srv, request)
+	result := resp["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+
+	var results []db.SearchResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != ids[0] {
+		t.Errorf("Expected only the recital, got %+v", results)
+	}
+
+	// Excluding recitals keeps the article and the chunks without a type
+	request = `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"data protection","exclude_provision_type":["recital"]}}}`
+	resp = captureServerOutput(t, srv, request)
+	result = resp["result"].(map[string]interface{})
+	text = result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	results = nil
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	foundArticle := false
+	for _, r := range results {
+		if r.ID == ids[0] {
+			t.Errorf("Expected the recital to be excluded, got %+v", results)
+		}
+		foundArticle = foundArticle || r.ID == ids[1]
+	}
+	if !foundArticle || len(results) < 2 {
+		t.Errorf("Expected the article and untyped chunks, got %+v", results)
+	}
+
+	request = `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"data protection","provision_type":"chapter"}}}`
+	resp = captureServerOutput(t, srv, request)
+	result = resp["result"].(map[string]interface{})
+	if isError, _ := result["isError"].(bool); !isError {
+		t.Errorf("Expected an unknown provision_type to be rejected, got %v", result)
+	}
+}
+
+func TestServerSearchFusionArguments(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{Embedder: queryEmbedder{1, 0.5, 0}, Fusion: db.Fusion{K: 10}})
+	request := `{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"portability","keyword_weight":0}}}`
+	resp := captureServerOutput(t, srv, request)
+	result := resp["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+
+	var results []db.SearchResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	// Ranked by the embedding alone, with the configured k
+	if len(results) != 3 || results[0].RawScore != 1.0/11 {
+		t.Errorf("Expected all chunks ranked by vector with k=10, got %+v", results)
+	}
+
+	request = `{"jsonrpc":"2.0","id":9,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"portability","fusion":"linear","alpha":1}}}`
+	resp = captureServerOutput(t, srv, request)
+	result = resp["result"].(map[string]interface{})
+	text = result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	results = nil
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	// The most similar embedding normalizes to 1, the least to 0
+	if len(results) != 3 || results[0].RawScore != 1 || results[2].RawScore != 0 {
+		t.Errorf("Expected normalized vector scores, got %+v", results)
+	}
+
+	for _, args := range []string{
+		`{"query":"portability","rrf_k":-5}`,
+		`{"query":"portability","fusion":"borda"}`,
+		`{"query":"portability","alpha":2}`,
+		`{"query":"portability","alpha":0.5,"keyword_weight":1}`,
+	} {
+		request = `{"jsonrpc":"2.0","id":10,"method":"tools/call","params":{"name":"gdpr_search","arguments":` + args + `}}`
+		resp = captureServerOutput(t, srv, request)
+		result = resp["result"].(map[string]interface{})
+		if isError, _ := result["isError"].(bool); !isError {
+			t.Errorf("Expected %s to be rejected, got %v", args, result)
+		}
+	}
+}
+
+// reverseReranker scores documents in reverse order of their position
+type reverseReranker struct {
+	calls *int
+	err   error
+}
+
+func (r reverseReranker) Rerank(query string, documents []string) ([]float64, error) {
+	*r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	scores := make([]float64, len(documents))
+	for i := range documents {
+		scores[i] = float64(i)
+	}
+	return scores, nil
+}
+
+func TestServerSearchRerank(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	search := func(srv *Server, args string) ([]db.SearchResult, bool) {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":11,"method":"tools/call","params":{"name":"gdpr_search","arguments":` + args + `}}`
+		resp := captureServerOutput(t, srv, request)
+		result := resp["result"].(map[string]interface{})
+		text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		if isError, _ := result["isError"].(bool); isError {
+			return nil, true
+		}
+		var results []db.SearchResult
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results, false
+	}
+
+	plain, _ := search(New(database, Config{}), `{"query":"data subject right"}`)
+	if len(plain) != 3 {
+		t.Fatalf("Expected 3 results, got %+v", plain)
+	}
+
+	var calls int
+	srv := New(database, Config{Reranker: reverseReranker{calls: &calls}, RerankCandidates: 3})
+
+	// The whole candidate pool is reranked before the limit applies
+	reranked, _ := search(srv, `{"query":"data subject right","limit":1}`)
+	if calls != 1 || len(reranked) != 1 || reranked[0].ID != plain[2].ID || reranked[0].RawScore != 2 {
+		t.Errorf("Expected the last fused candidate first, got %+v", reranked)
+	}
+
+	if results, _ := search(srv, `{"query":"data subject right","rerank":false}`); calls != 1 || results[0].ID != plain[0].ID {
+		t.Errorf("Expected rerank=false to keep the fused order, got %+v", results)
+	}
+
+	if _, isError := search(New(database, Config{}), `{"query":"data subject right","rerank":true}`); !isError {
+		t.Error("Expected rerank=true without a reranker to be rejected")
+	}
+
+	var logs bytes.Buffer
+	failing := New(database, Config{Reranker: reverseReranker{calls: &calls, err: fmt.Errorf("endpoint down")}, Logger: log.New(&logs, "", 0)})
+	if results, _ := search(failing, `{"query":"data subject right"}`); len(results) != 3 || results[0].ID != plain[0].ID {
+		t.Errorf("Expected a failing reranker to fall back to the fused order, got %+v", results)
+	}
+	if !strings.Contains(logs.String(), "endpoint down") {
+		t.Errorf("Expected the reranker failure to be logged, got %q", logs.String())
+	}
+}
+
+func TestServerSearchRerankTopCandidates(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var calls int
+	var pairs []int
+	reranker := pairsReranker{reverseReranker{calls: &calls}, &pairs}
+	srv := New(database, Config{Reranker: reranker, RerankCandidates: 2})
+
+	request := func(args string) []interface{} {
+		t.Helper()
+		resp := captureServerOutput(t, srv, `{"jsonrpc":"2.0","id":13,"method":"tools/call","params":{"name":"gdpr_search","arguments":`+args+`}}`)
+		return resp["result"].(map[string]interface{})["content"].([]interface{})
+	}
+
+	// Only the top candidates are reranked; the rest follow them
+	content := request(`{"query":"data subject right","limit":1}`)
+	if calls != 1 || len(pairs) != 1 || pairs[0] != 2 {
+		t.Fatalf("Expected one rerank of 2 candidates, got %d calls of %v", calls, pairs)
+	}
+	var cursor struct {
+		NextCursor string `json:"next_cursor"`
+	}
+	if len(content) < 2 || json.Unmarshal([]byte(content[len(content)-1].(map[string]interface{})["text"].(string)), &cursor) != nil || cursor.NextCursor == "" {
+		t.Fatalf("Expected a next cursor, got %+v", content)
+	}
+
+	// Further pages are cut from the cached ranking
+	request(`{"query":"data subject right","limit":1,"cursor":"` + cursor.NextCursor + `"}`)
+	if calls != 1 {
+		t.Errorf("Expected the next page not to rerank again, got %d calls", calls)
+	}
+}
+
+// pairsReranker records how many documents each call reranks
+type pairsReranker struct {
+	reverseReranker
+	pairs *[]int
+}
+
+func (r pairsReranker) Rerank(query string, documents []string) ([]float64, error) {
+	*r.pairs = append(*r.pairs, len(documents))
+	return r.reverseReranker.Rerank(query, documents)
+}
+
+func TestServerSearchDiversity(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// A near duplicate of the top keyword result, which diversification
+	// pushes down; the searches below rank by keywords only
+	results, err := database.HybridSearch("right to erasure", nil, 1, db.SearchOptions{Fusion: db.Fusion{KeywordWeight: 1}})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("HybridSearch = %+v, %v", results, err)
+	}
+	top, err := database.GetDocument(results[0].ID)
+	if err != nil || top == nil {
+		t.Fatalf("GetDocument = %+v, %v", top, err)
+	}
+	duplicate, err := database.InsertChunk(top.Chunk+" Erasure.", 99)
+	if err != nil {
+		t.Fatalf("Failed to insert chunk: %v", err)
+	}
+	if err := database.InsertTrigrams(duplicate, db.GenerateTrigrams(top.Chunk+" Erasure.")); err != nil {
+		t.Fatalf("Failed to insert trigrams: %v", err)
+	}
+
+	search := func(srv *Server, args string) []db.SearchResult {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":12,"method":"tools/call","params":{"name":"gdpr_search","arguments":` + args + `}}`
+		resp := captureServerOutput(t, srv, request)
+		result := resp["result"].(map[string]interface{})
+		text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		if isError, _ := result["isError"].(bool); isError {
+			t.Fatalf("Search failed: %s", text)
+		}
+		var results []db.SearchResult
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results
+	}
+	ids := func(results []db.SearchResult) map[int64]bool {
+		set := make(map[int64]bool)
+		for _, r := range results {
+			set[r.ID] = true
+		}
+		return set
+	}
+
+	plain := search(New(database, Config{}), `{"query":"right to erasure","limit":2,"vector_weight":0}`)
+	if got := ids(plain); len(plain) != 2 || !got[top.ID] || !got[duplicate] {
+		t.Fatalf("Expected the article and its duplicate first, got %+v", plain)
+	}
+
+	diverse := search(New(database, Config{}), `{"query":"right to erasure","limit":2,"vector_weight":0,"diversity":0.7}`)
+	if got := ids(diverse); len(diverse) != 2 || got[top.ID] == got[duplicate] {
+		t.Errorf("Expected only one of the duplicates with diversity, got %+v", diverse)
+	}
+
+	// The configured default applies unless the call overrides it
+	srv := New(database, Config{Diversity: 0.7})
+	if got := ids(search(srv, `{"query":"right to erasure","limit":2,"vector_weight":0}`)); got[top.ID] == got[duplicate] {
+		t.Errorf("Expected the configured diversity to apply, got %v", got)
+	}
+	if got := ids(search(srv, `{"query":"right to erasure","limit":2,"vector_weight":0,"diversity":0}`)); !got[top.ID] || !got[duplicate] {
+		t.Errorf("Expected diversity 0 to turn diversification off, got %v", got)
+	}
+}
+
+func TestServerSearchProvisionFilters(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var ids []int64
+	for _, d := range []db.Document{
+		{Chunk: "(82) The controller should maintain records of processing activities.", Metadata: map[string]string{"provision_type": "recital", "recital": "82"}},
+		{Chunk: "Each controller shall maintain a record of processing activities under its responsibility.", Metadata: map[string]string{"provision_type": "article", "chapter": "IV", "article": "30"}},
+		{Chunk: "The data subject shall have the right to obtain records of processing of personal data.", Metadata: map[string]string{"provision_type": "article", "chapter": "III", "article": "15"}},
+	} {
+		id, err := database.InsertDocument(d)
+		if err != nil {
+			t.Fatalf("InsertDocument failed: %v", err)
+		}
+		if err := database.InsertTrigrams(id, db.GenerateTrigrams(d.Chunk)); err != nil {
+			t.Fatalf("InsertTrigrams failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	srv := New(database, Config{})
+	search := func(filter string) (int64, bool) {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":13,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"records of processing",` + filter + `}}}`
+		resp := captureServerOutput(t, srv, request)
+		result := resp["result"].(map[string]interface{})
+		if isError, _ := result["isError"].(bool); isError {
+			return 0, true
+		}
+		var results []db.SearchResult
+		text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected one result for %s, got %+v", filter, results)
+		}
+		return results[0].ID, false
+	}
+
+	for filter, want := range map[string]int64{
+		`"chapter":"IV"`:         ids[1],
+		`"chapter":"4"`:          ids[1],
+		`"article":"Article 15"`: ids[2],
+		`"recital":"(82)"`:       ids[0],
+	} {
+		if got, _ := search(filter); got != want {
+			t.Errorf("Expected %s to return document %d, got %d", filter, want, got)
+		}
+	}
+	if _, isError := search(`"chapter":"Chapter Q"`); !isError {
+		t.Error("Expected an invalid chapter to be rejected")
+	}
+}
+
+func TestServerSearchSpellcheck(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	request := `{"jsonrpc":"2.0","id":14,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"right to eraser"}}}`
+	resp := captureServerOutput(t, srv, request)
+	content := resp["result"].(map[string]interface{})["content"].([]interface{})
+	if len(content) != 2 {
+		t.Fatalf("Expected results and a correction note, got %v", content)
+	}
+	if note := content[1].(map[string]interface{})["text"].(string); !strings.Contains(note, `"right to erasure"`) {
+		t.Errorf("Expected the corrected query in the note, got %q", note)
+	}
+
+	request = `{"jsonrpc":"2.0","id":15,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"right to eraser","spellcheck":false}}}`
+	resp = captureServerOutput(t, srv, request)
+	if content := resp["result"].(map[string]interface{})["content"].([]interface{}); len(content) != 1 {
+		t.Errorf("Expected no correction with spellcheck off, got %v", content)
+	}
+}
+
+// fixedRewriter reformulates every question into the same queries
+type fixedRewriter []string
+
+func (f fixedRewriter) Rewrite(query string, n int) ([]string, error) {
+	if len(f) > n {
+		return f[:n], nil
+	}
+	return f, nil
+}
+
+func TestServerSearchRewrites(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	search := func(srv *Server, args string) ([]db.SearchResult, bool) {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":16,"method":"tools/call","params":{"name":"gdpr_search","arguments":` + args + `}}`
+		resp := captureServerOutput(t, srv, request)
+		result := resp["result"].(map[string]interface{})
+		if isError, _ := result["isError"].(bool); isError {
+			return nil, true
+		}
+		var results []db.SearchResult
+		text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results, false
+	}
+
+	// The question alone finds nothing by keywords; its reformulation does
+	srv := New(database, Config{Rewriter: fixedRewriter{"data portability"}})
+	args := `{"query":"zzz qqq","keyword_weight":1,"vector_weight":0`
+	if results, _ := search(srv, args+`}`); len(results) != 0 {
+		t.Fatalf("Expected no results without rewrites, got %+v", results)
+	}
+	results, _ := search(srv, args+`,"rewrites":1}`)
+	if len(results) == 0 || !strings.Contains(results[0].Snippet, "portability") {
+		t.Errorf("Expected the reformulation's results, got %+v", results)
+	}
+
+	// Queries with search syntax are searched as written
+	if results, _ := search(srv, `{"query":"\"zzz qqq\"","rewrites":1}`); len(results) != 0 {
+		t.Errorf("Expected a quoted query not to be rewritten, got %+v", results)
+	}
+	if _, isError := search(srv, `{"query":"zzz","rewrites":6}`); !isError {
+		t.Error("Expected too many rewrites to be rejected")
+	}
+
+	// The configured default applies without the argument
+	srv = New(database, Config{Rewriter: fixedRewriter{"data portability"}, Rewrites: 2})
+	if results, _ := search(srv, args+`}`); len(results) == 0 {
+		t.Error("Expected the configured rewrites to apply")
+	}
+}
+
+func TestServerSearchExplain(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{Embedder: queryEmbedder{0.7, 0.7, 0.1}})
+	search := func(args string) []db.SearchResult {
+		t.Helper()
+		request := `{"jsonrpc":"2.0","id":17,"method":"tools/call","params":{"name":"gdpr_search","arguments":` + args + `}}`
+		resp := captureServerOutput(t, srv, request)
+		result := resp["result"].(map[string]interface{})
+		text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		if isError, _ := result["isError"].(bool); isError {
+			t.Fatalf("Search failed: %s", text)
+		}
+		var results []db.SearchResult
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to parse results: %v", err)
+		}
+		return results
+	}
+
+	results := search(`{"query":"data portability","limit":1,"explain":true}`)
+	if len(results) != 1 || results[0].Explain == nil {
+		t.Fatalf("Expected an explained result, got %+v", results)
+	}
+	x := results[0].Explain
+	if len(x.Paths) != 2 || x.Keyword == nil || x.Vector == nil || x.Keyword.TrigramMatches == 0 {
+		t.Errorf("Expected the result explained by both rankings, got %+v", x)
+	}
+	if x.Fusion != db.FusionRRF || x.FusedScore != results[0].RawScore {
+		t.Errorf("Expected the fused score %v, got %+v", results[0].RawScore, x)
+	}
+
+	if results := search(`{"query":"data portability","limit":1}`); len(results) != 1 || results[0].Explain != nil {
+		t.Errorf("Expected no explanation by default, got %+v", results)
+	}
+}
+
+func TestServerSearchArticleRetrieval(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	article21 := map[string]string{"article": "21", "article_title": "Right to object"}
+	for i, chunk := range []string{
+		"Article 21 Right to object. 1. The data subject shall have the right to object to processing.",
+		"3. Where the data subject objects to processing for direct marketing purposes, the right to object applies.",
+	} {
+		docID, err := database.InsertDocument(db.Document{Chunk: chunk, ChunkIndex: i, Source: "gdpr.txt", Metadata: article21})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+		if err := database.InsertTrigrams(docID, db.GenerateTrigrams(chunk)); err != nil {
+			t.Fatalf("Failed to insert trigrams: %v", err)
+		}
+	}
+
+	srv := New(database, Config{})
+	request := `{"jsonrpc":"2.0","id":18,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"right to object","limit":1,"vector_weight":0,"retrieval":"article"}}}`
+	resp := captureServerOutput(t, srv, request)
+	result := resp["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if isError, _ := result["isError"].(bool); isError {
+		t.Fatalf("Search failed: %s", text)
+	}
+
+	var articles []db.ArticleResult
+	if err := json.Unmarshal([]byte(text), &articles); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Article != "21" || len(articles[0].ChunkIDs) != 2 ||
+		!strings.Contains(articles[0].Text, "1. The data subject") || !strings.Contains(articles[0].Text, "3. Where") {
+		t.Errorf("Expected Article 21 once with its full text, got %+v", articles)
+	}
+
+	request = `{"jsonrpc":"2.0","id":19,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"right to object","retrieval":"paragraph"}}}`
+	resp = captureServerOutput(t, srv, request)
+	if isError, _ := resp["result"].(map[string]interface{})["isError"].(bool); !isError {
+		t.Error("Expected an unknown retrieval mode to be rejected")
+	}
+}
+
+func TestServerSearchNormalizedScores(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{Embedder: queryEmbedder{1.0, 0.5, 0.0}})
+	request := `{"jsonrpc":"2.0","id":23,"method":"tools/call","params":{"name":"gdpr_search","arguments":{"query":"right of access"}}}`
+	resp := captureServerOutput(t, srv, request)
+	text := resp["result"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+
+	var results []db.SearchResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Failed to parse results: %v", err)
+	}
+	if len(results) == 0 || !strings.Contains(text, `"raw_score"`) {
+		t.Fatalf("Expected results with raw scores, got %s", text)
+	}
+	for i, r := range results {
+		if r.Score < 0 || r.Score > 1 {
+			t.Errorf("Expected scores between 0 and 1, got %+v", r)
+		}
+		if i > 0 && r.RawScore > results[i-1].RawScore {
+			t.Errorf("Expected results in raw score order, got %+v", results)
+		}
+	}
+	// The article matching every query trigram and the query embedding
+	if results[0].Score < 0.99 {
+		t.Errorf("Expected the exact match to score close to 1, got %+v", results[0])
+	}
+}
+
+func TestServerSearchLimitClamped(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+
+	// Limits that would overflow the candidate pools are capped
+	for _, call := range []struct{ tool, args string }{
+		{"gdpr_search", `{"query":"right to erasure","limit":1000000000000000000,"retrieval":"article","diversity":0.5}`},
+		{"enforcement_decisions", `{"query":"consent","limit":1000000000000000000}`},
+		{"edpb_guidelines_search", `{"query":"consent","limit":1000000000000000000}`},
+		{"case_law", `{"query":"consent","limit":1000000000000000000}`},
+	} {
+		if text, isError := callTool(t, srv, call.tool, call.args); isError {
+			t.Errorf("%s failed: %s", call.tool, text)
+		}
+	}
+
+	text, _ := callTool(t, srv, "gdpr_search", `{"query":"right to erasure","limit":1000}`)
+	var results []db.SearchResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil || len(results) == 0 || len(results) > maxSearchLimit {
+		t.Errorf("Expected at most %d results, got %d (%v)", maxSearchLimit, len(results), err)
+	}
+}
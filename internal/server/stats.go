@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+var statsTool = MCPTool{
+	Name:        "gdpr_stats",
+	Description: "Get statistics on what the server has indexed: its collections, chunk, embedding and trigram counts, its size on disk, the embedder and model of the last ingestion, when it ran, and the source documents with their chunk counts. Use it to check the corpus covers a question before relying on search results.",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"sources": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Whether to list the source documents with their chunk counts (default: true)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleStatsTool(id interface{}, args json.RawMessage) {
+	var statsArgs struct {
+		Sources *bool `json:"sources"`
+	}
+	if err := json.Unmarshal(args, &statsArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	s.chaos.delayDB()
+	stats, err := s.db.Stats()
+	if err != nil {
+		s.writeToolError(id, "Failed to get statistics: "+err.Error())
+		return
+	}
+	if statsArgs.Sources != nil && !*statsArgs.Sources {
+		stats.Sources = nil
+	}
+	output := struct {
+		*db.Stats
+		Notes []string `json:"notes,omitempty"`
+	}{Stats: stats}
+	switch {
+	case stats.Chunks == 0:
+		output.Notes = append(output.Notes, "The index is empty; ingest the GDPR text before searching")
+	case stats.EmbeddedChunks == 0:
+		output.Notes = append(output.Notes, "No chunk has an embedding, so searches match keywords only")
+	case stats.EmbeddedChunks < stats.Chunks:
+		output.Notes = append(output.Notes, fmt.Sprintf("%d of %d chunks have no embedding and are only found by keyword", stats.Chunks-stats.EmbeddedChunks, stats.Chunks))
+	}
+	if len(stats.Dimensions) > 1 {
+		output.Notes = append(output.Notes, fmt.Sprintf("Embeddings of %v dimensions are mixed, so chunks were embedded by different models; re-ingest with a single embedder", stats.Dimensions))
+	}
+	s.writeToolJSON(id, output)
+}
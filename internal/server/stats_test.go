@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerStatsTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	if err := database.SetMetadata(db.MetaEmbedder, "stub"); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	if _, err := database.InsertDocument(db.Document{Chunk: "Article 99 - Entry into force", ChunkIndex: 3}); err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+	srv := New(database, Config{})
+
+	var stats struct {
+		db.Stats
+		Notes []string `json:"notes"`
+	}
+	text, isError := callTool(t, srv, "gdpr_stats", `{}`)
+	if isError || json.Unmarshal([]byte(text), &stats) != nil {
+		t.Fatalf("gdpr_stats failed: %s", text)
+	}
+	if stats.Chunks != 4 || stats.EmbeddedChunks != 3 || len(stats.Dimensions) != 1 || stats.Dimensions[0] != 3 {
+		t.Errorf("Expected 4 chunks, 3 of them embedded in 3 dimensions, got %s", text)
+	}
+	if stats.Embedder != "stub" {
+		t.Errorf("Expected the embedder of the last ingestion, got %q", stats.Embedder)
+	}
+	if len(stats.Notes) != 1 {
+		t.Errorf("Expected a note on the chunk without an embedding, got %v", stats.Notes)
+	}
+	if len(stats.Sources) != 1 {
+		t.Errorf("Expected the chunks without a source, got %+v", stats.Sources)
+	}
+
+	text, _ = callTool(t, srv, "gdpr_stats", `{"sources":false}`)
+	stats.Sources = nil
+	if json.Unmarshal([]byte(text), &stats) != nil || stats.Sources != nil {
+		t.Errorf("Expected no sources, got %s", text)
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+	"github.com/jc/gdpr-mcp/internal/ingest"
+)
+
+// maxSummaryChars caps the text of a chapter sent for summarization; the
+// articles beyond it are left out and reported
+const maxSummaryChars = 60000
+
+// summaryMaxTokens caps the length of summaries written through sampling
+const summaryMaxTokens = 1024
+
+var summarizeTool = MCPTool{
+	Name:        "gdpr_summarize",
+	Description: "Summarize a GDPR article or chapter from its ingested text alone, returning the IDs of the chunks the summary is based on for verification",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"article": map[string]interface{}{
+				"type":        "string",
+				"description": "Article to summarize, e.g. \"17\" or \"Art. 17\"",
+			},
+			"chapter": map[string]interface{}{
+				"type":        "string",
+				"description": "Chapter to summarize instead, e.g. \"III\", \"3\" or \"Chapter III\"",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+// summaryResult is a summary with the provisions and chunks it is based on
+type summaryResult struct {
+	Article         string   `json:"article,omitempty"`
+	Chapter         string   `json:"chapter,omitempty"`
+	Title           string   `json:"title"`
+	Summary         string   `json:"summary"`
+	Articles        []string `json:"articles"` // articles whose text was summarized
+	ChunkIDs        []int64  `json:"chunk_ids"`
+	OmittedArticles []string `json:"omitted_articles,omitempty"` // articles of a chapter left out for length
+}
+
+// summarizer returns the configured summarizer or, for clients supporting
+// sampling, one asking the client's model; nil if neither is available
+func (s *Server) summarizer() ingest.Summarizer {
+	if s.config.Summarizer != nil {
+		return s.config.Summarizer
+	}
+	if s.sampling && s.in != nil {
+		return samplingSummarizer{s}
+	}
+	return nil
+}
+
+// samplingSummarizer asks the client's model for summaries through MCP
+// sampling
+type samplingSummarizer struct {
+	s *Server
+}
+
+func (ss samplingSummarizer) Summarize(title, text string) (string, error) {
+	result, err := ss.s.request("sampling/createMessage", map[string]interface{}{
+		"messages": []map[string]interface{}{{
+			"role":    "user",
+			"content": MCPContent{Type: "text", Text: ingest.SummaryRequest(title, text)},
+		}},
+		"systemPrompt":   ingest.SummarizePrompt,
+		"includeContext": "none",
+		"temperature":    0,
+		"maxTokens":      summaryMaxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	var message struct {
+		Content MCPContent `json:"content"`
+	}
+	if err := json.Unmarshal(result, &message); err != nil {
+		return "", fmt.Errorf("failed to parse sampling result: %w", err)
+	}
+	if message.Content.Type != "text" || strings.TrimSpace(message.Content.Text) == "" {
+		return "", fmt.Errorf("sampling result has no text")
+	}
+	return strings.TrimSpace(message.Content.Text), nil
+}
+
+func (s *Server) handleSummarizeTool(id interface{}, args json.RawMessage) {
+	var summarizeArgs struct {
+		Article    string `json:"article"`
+		Chapter    string `json:"chapter"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &summarizeArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	summarizer := s.summarizer()
+	if summarizer == nil {
+		s.writeToolError(id, "Summarization is not available: configure an LLM provider or use a client that supports sampling")
+		return
+	}
+
+	var result summaryResult
+	var text strings.Builder
+	switch {
+	case summarizeArgs.Article == "" && summarizeArgs.Chapter == "":
+		s.writeToolError(id, "article or chapter is required")
+		return
+	case summarizeArgs.Article != "" && summarizeArgs.Chapter != "":
+		s.writeToolError(id, "article cannot be combined with chapter")
+		return
+	case summarizeArgs.Article != "":
+		number, ok := db.ArticleNumber(summarizeArgs.Article)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid article %q", summarizeArgs.Article))
+			return
+		}
+		s.chaos.delayDB()
+		article, err := s.db.Article(number, summarizeArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get article: "+err.Error())
+			return
+		}
+		if article == nil {
+			s.writeToolError(id, fmt.Sprintf("Article %s not found; ingest the GDPR text first", number))
+			return
+		}
+		result.Article = number
+		result.Title = articleHeading(article)
+		result.Articles = []string{number}
+		result.ChunkIDs = article.ChunkIDs
+		text.WriteString(article.Text)
+	default:
+		number, ok := db.ChapterNumber(summarizeArgs.Chapter)
+		if !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid chapter %q", summarizeArgs.Chapter))
+			return
+		}
+		s.chaos.delayDB()
+		toc, err := s.db.TableOfContents(summarizeArgs.Collection)
+		if err != nil {
+			s.writeToolError(id, "Failed to get table of contents: "+err.Error())
+			return
+		}
+		var chapter *db.TOCChapter
+		for i := range toc {
+			if toc[i].Chapter == number {
+				chapter = &toc[i]
+			}
+		}
+		if chapter == nil {
+			s.writeToolError(id, fmt.Sprintf("Chapter %s not found; ingest the GDPR text first", number))
+			return
+		}
+		result.Chapter = number
+		result.Title = "Chapter " + number
+		if chapter.Title != "" {
+			result.Title += " - " + chapter.Title
+		}
+
+		for _, a := range chapterArticles(*chapter) {
+			article, err := s.db.Article(a.Article, summarizeArgs.Collection)
+			if err != nil {
+				s.writeToolError(id, "Failed to get article: "+err.Error())
+				return
+			}
+			if article == nil {
+				continue
+			}
+			section := articleHeading(article) + "\n\n" + article.Text + "\n\n"
+			if len(result.OmittedArticles) > 0 || (text.Len() > 0 && text.Len()+len(section) > maxSummaryChars) {
+				result.OmittedArticles = append(result.OmittedArticles, a.Article)
+				continue
+			}
+			text.WriteString(section)
+			result.Articles = append(result.Articles, a.Article)
+			result.ChunkIDs = append(result.ChunkIDs, article.ChunkIDs...)
+		}
+	}
+
+	summary, err := summarizer.Summarize(result.Title, strings.TrimSpace(text.String()))
+	if err != nil {
+		s.writeToolError(id, "Failed to summarize: "+err.Error())
+		return
+	}
+	result.Summary = summary
+	s.writeToolJSON(id, result)
+}
+
+// articleHeading names an article with its title, e.g. "Article 17 - Right
+// to erasure ('right to be forgotten')"
+func articleHeading(article *db.ArticleResult) string {
+	if article.Title == "" {
+		return "Article " + article.Article
+	}
+	return "Article " + article.Article + " - " + article.Title
+}
+
+// chapterArticles returns the articles of a chapter, in and outside its
+// sections, in article order
+func chapterArticles(chapter db.TOCChapter) []db.TOCArticle {
+	articles := append([]db.TOCArticle(nil), chapter.Articles...)
+	for _, section := range chapter.Sections {
+		articles = append(articles, section.Articles...)
+	}
+	sort.SliceStable(articles, func(i, j int) bool {
+		a, _ := strconv.Atoi(articles[i].Article)
+		b, _ := strconv.Atoi(articles[j].Article)
+		return a < b
+	})
+	return articles
+}
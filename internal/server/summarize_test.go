@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+// fakeSummarizer records what it is asked to summarize
+type fakeSummarizer struct {
+	title, text string
+}
+
+func (f *fakeSummarizer) Summarize(title, text string) (string, error) {
+	f.title, f.text = title, text
+	return "Summary of " + title, nil
+}
+
+func insertSummaryArticles(t *testing.T, database *db.DB) []int64 {
+	t.Helper()
+	var ids []int64
+	for i, d := range []struct {
+		chunk    string
+		metadata map[string]string
+	}{
+		{"1. The data subject shall have the right to obtain erasure.", map[string]string{"chapter": "III", "chapter_title": "Rights of the data subject", "section": "3", "article": "17", "article_title": "Right to erasure"}},
+		{"2. Where the controller has made the personal data public.", map[string]string{"chapter": "III", "chapter_title": "Rights of the data subject", "section": "3", "article": "17", "article_title": "Right to erasure"}},
+		{"1. The data subject shall have the right of access.", map[string]string{"chapter": "III", "chapter_title": "Rights of the data subject", "section": "2", "article": "15", "article_title": "Right of access by the data subject"}},
+		{strings.Repeat("Long text. ", maxSummaryChars/10), map[string]string{"chapter": "III", "chapter_title": "Rights of the data subject", "section": "3", "article": "18", "article_title": "Right to restriction of processing"}},
+	} {
+		id, err := database.InsertDocument(db.Document{Chunk: d.chunk, ChunkIndex: i, Metadata: d.metadata})
+		if err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestServerSummarizeTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	ids := insertSummaryArticles(t, database)
+
+	if text, isError := callTool(t, New(database, Config{}), "gdpr_summarize", `{"article":"17"}`); !isError || !strings.Contains(text, "not available") {
+		t.Errorf("Expected summarization to be unavailable without a summarizer, got %s", text)
+	}
+
+	summarizer := &fakeSummarizer{}
+	srv := New(database, Config{Summarizer: summarizer})
+	var result summaryResult
+	text, isError := callTool(t, srv, "gdpr_summarize", `{"article":"Art. 17"}`)
+	if isError || json.Unmarshal([]byte(text), &result) != nil {
+		t.Fatalf("gdpr_summarize failed: %s", text)
+	}
+	if result.Title != "Article 17 - Right to erasure" || result.Summary != "Summary of Article 17 - Right to erasure" || !reflect.DeepEqual(result.ChunkIDs, ids[:2]) {
+		t.Errorf("Unexpected summary %+v", result)
+	}
+	if !strings.Contains(summarizer.text, "obtain erasure") || !strings.Contains(summarizer.text, "made the personal data public") {
+		t.Errorf("Expected the article text to be summarized, got %q", summarizer.text)
+	}
+
+	result = summaryResult{}
+	text, isError = callTool(t, srv, "gdpr_summarize", `{"chapter":"3"}`)
+	if isError || json.Unmarshal([]byte(text), &result) != nil {
+		t.Fatalf("gdpr_summarize failed: %s", text)
+	}
+	if result.Title != "Chapter III - Rights of the data subject" || !reflect.DeepEqual(result.Articles, []string{"15", "17"}) || !reflect.DeepEqual(result.OmittedArticles, []string{"18"}) {
+		t.Errorf("Expected Articles 15 and 17 with 18 left out for length, got %+v", result)
+	}
+	if want := []int64{ids[2], ids[0], ids[1]}; !reflect.DeepEqual(result.ChunkIDs, want) {
+		t.Errorf("Expected chunks %v, got %v", want, result.ChunkIDs)
+	}
+	if !strings.HasPrefix(summarizer.text, "Article 15 - Right of access") {
+		t.Errorf("Expected the chapter text to start with Article 15, got %q", summarizer.text)
+	}
+
+	for _, args := range []string{`{}`, `{"article":"17","chapter":"III"}`, `{"article":"first"}`, `{"article":"99"}`, `{"chapter":"XI"}`} {
+		if text, isError := callTool(t, srv, "gdpr_summarize", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
+
+func TestServerSummarizeSampling(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+	ids := insertSummaryArticles(t, database)
+
+	// The client pings the server before answering the sampling request
+	var out bytes.Buffer
+	srv := New(database, Config{})
+	srv.out = &out
+	srv.in = bufio.NewReader(strings.NewReader(
+		`{"jsonrpc":"2.0","id":7,"method":"ping"}` + "\n" +
+			`{"jsonrpc":"2.0","id":"gdpr-mcp-1","result":{"role":"assistant","content":{"type":"text","text":"Art. 17 grants erasure."},"model":"client-model"}}` + "\n"))
+
+	srv.handleRequest("tools/list", 1, nil)
+	if strings.Contains(out.String(), "gdpr_summarize") {
+		t.Fatal("Expected gdpr_summarize to be hidden from clients without sampling")
+	}
+	srv.handleRequest("initialize", 2, json.RawMessage(`{"protocolVersion":"2024-11-05","capabilities":{"sampling":{}}}`))
+	out.Reset()
+	srv.handleRequest("tools/list", 3, nil)
+	if !strings.Contains(out.String(), "gdpr_summarize") {
+		t.Fatal("Expected gdpr_summarize to be offered to clients with sampling")
+	}
+
+	out.Reset()
+	srv.handleRequest("tools/call", 4, json.RawMessage(`{"name":"gdpr_summarize","arguments":{"article":"17"}}`))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a sampling request and the tool result, got %q", lines)
+	}
+
+	var request struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+		Params struct {
+			SystemPrompt string `json:"systemPrompt"`
+			Messages     []struct {
+				Content MCPContent `json:"content"`
+			} `json:"messages"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &request); err != nil || request.Method != "sampling/createMessage" || request.ID != "gdpr-mcp-1" {
+		t.Fatalf("Expected a sampling request, got %s", lines[0])
+	}
+	if len(request.Params.Messages) != 1 || !strings.Contains(request.Params.Messages[0].Content.Text, "obtain erasure") || request.Params.SystemPrompt == "" {
+		t.Errorf("Expected the article text and the grounding prompt, got %s", lines[0])
+	}
+
+	var response struct {
+		Result MCPCallToolResult `json:"result"`
+	}
+	var result summaryResult
+	if err := json.Unmarshal([]byte(lines[1]), &response); err != nil || response.Result.IsError || json.Unmarshal([]byte(response.Result.Content[0].Text), &result) != nil {
+		t.Fatalf("gdpr_summarize failed: %s", lines[1])
+	}
+	if result.Summary != "Art. 17 grants erasure." || !reflect.DeepEqual(result.ChunkIDs, ids[:2]) {
+		t.Errorf("Unexpected summary %+v", result)
+	}
+
+	// The ping is handled once the tool call has been answered
+	if line, err := srv.next(); err != nil || !strings.Contains(string(line), `"ping"`) {
+		t.Errorf("Expected the ping to be queued, got %q, %v", line, err)
+	}
+}
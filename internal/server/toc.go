@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+var tocTool = MCPTool{
+	Name:        "gdpr_toc",
+	Description: "Get the structure of the GDPR: its chapters and sections with the numbers and titles of their articles, to navigate before drilling into articles with gdpr_search or gdpr_get",
+	InputSchema: JSONSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"chapter": map[string]interface{}{
+				"type":        "string",
+				"description": "Only return this chapter, e.g. \"III\", \"3\" or \"Chapter III\"",
+			},
+			"collection": map[string]interface{}{
+				"type":        "string",
+				"description": "Collection the regulation was ingested into (default: the default collection)",
+			},
+		},
+	},
+}
+
+func (s *Server) handleTOCTool(id interface{}, args json.RawMessage) {
+	var tocArgs struct {
+		Chapter    string `json:"chapter"`
+		Collection string `json:"collection"`
+	}
+	if err := json.Unmarshal(args, &tocArgs); err != nil {
+		s.writeToolError(id, "Invalid arguments: "+err.Error())
+		return
+	}
+
+	chapter := ""
+	if tocArgs.Chapter != "" {
+		var ok bool
+		if chapter, ok = db.ChapterNumber(tocArgs.Chapter); !ok {
+			s.writeToolError(id, fmt.Sprintf("Invalid chapter %q", tocArgs.Chapter))
+			return
+		}
+	}
+
+	s.chaos.delayDB()
+	toc, err := s.db.TableOfContents(tocArgs.Collection)
+	if err != nil {
+		s.writeToolError(id, "Failed to get table of contents: "+err.Error())
+		return
+	}
+	if len(toc) == 0 {
+		s.writeToolError(id, "No articles found; ingest the GDPR text first")
+		return
+	}
+
+	if chapter != "" {
+		for _, c := range toc {
+			if c.Chapter == chapter {
+				s.writeToolJSON(id, []db.TOCChapter{c})
+				return
+			}
+		}
+		s.writeToolError(id, fmt.Sprintf("Chapter %s not found", chapter))
+		return
+	}
+	s.writeToolJSON(id, toc)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jc/gdpr-mcp/internal/db"
+)
+
+func TestServerTOCTool(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	srv := New(database, Config{})
+	if text, isError := callTool(t, srv, "gdpr_toc", `{}`); !isError {
+		t.Errorf("Expected an error without articles, got %s", text)
+	}
+
+	for i, metadata := range []map[string]string{
+		{"chapter": "I", "chapter_title": "General provisions", "article": "1", "article_title": "Subject-matter and objectives"},
+		{"chapter": "III", "chapter_title": "Rights of the data subject", "section": "3", "article": "17", "article_title": "Right to erasure"},
+	} {
+		if _, err := database.InsertDocument(db.Document{Chunk: "text", ChunkIndex: i, Metadata: metadata}); err != nil {
+			t.Fatalf("Failed to insert document: %v", err)
+		}
+	}
+
+	text, isError := callTool(t, srv, "gdpr_toc", `{}`)
+	if isError {
+		t.Fatalf("gdpr_toc failed: %s", text)
+	}
+	var toc []db.TOCChapter
+	if err := json.Unmarshal([]byte(text), &toc); err != nil {
+		t.Fatalf("Failed to parse table of contents: %v", err)
+	}
+	if len(toc) != 2 || toc[0].Title != "General provisions" || toc[1].Sections[0].Articles[0].Title != "Right to erasure" {
+		t.Errorf("Unexpected table of contents %+v", toc)
+	}
+
+	text, _ = callTool(t, srv, "gdpr_toc", `{"chapter":"3"}`)
+	toc = nil
+	if err := json.Unmarshal([]byte(text), &toc); err != nil || len(toc) != 1 || toc[0].Chapter != "III" {
+		t.Errorf("Expected chapter III alone, got %s", text)
+	}
+
+	for _, args := range []string{`{"chapter":"II"}`, `{"chapter":"first"}`} {
+		if text, isError := callTool(t, srv, "gdpr_toc", args); !isError {
+			t.Errorf("Expected %s to fail, got %s", args, text)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package version holds the build metadata of gdpr-mcp. Release builds set
+// it at link time, e.g.
+//
+//	go build -ldflags "-X github.com/jc/gdpr-mcp/internal/version.Version=1.2.0" ./cmd/gdpr-mcp
+//
+// Otherwise the commit and its time are taken from the version control
+// information Go embeds in binaries built from a checkout.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags "-X github.com/jc/gdpr-mcp/internal/version.<Name>=<value>"
+var (
+	Version = "dev" // release version
+	Commit  = ""    // git commit the binary was built from
+	Date    = ""    // build date, RFC 3339
+)
+
+// Info is the build metadata of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a checkout with uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build metadata, completing what was not set at link time
+// from the embedded version control information
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	vcs := make(map[string]string)
+	for _, s := range build.Settings {
+		vcs[s.Key] = s.Value
+	}
+	if info.Commit == "" && vcs["vcs.revision"] != "" {
+		info.Commit = vcs["vcs.revision"]
+		info.Modified = vcs["vcs.modified"] == "true"
+	}
+	if info.Date == "" {
+		info.Date = vcs["vcs.time"]
+	}
+	return info
+}
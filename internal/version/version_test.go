@@ -0,0 +1,19 @@
+package version
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, Date = v, c, d }(Version, Commit, Date)
+
+	Version, Commit, Date = "1.2.0", "abc123", "2026-10-01T12:00:00Z"
+	info := Get()
+	if info.Version != "1.2.0" || info.Commit != "abc123" || info.Date != "2026-10-01T12:00:00Z" {
+		t.Errorf("Expected the link-time metadata, got %+v", info)
+	}
+	if info.Modified {
+		t.Error("Expected a commit set at link time not to be marked modified")
+	}
+	if info.GoVersion == "" {
+		t.Error("Expected the Go version")
+	}
+}